@@ -4,7 +4,9 @@
 package exceptionsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/exceptionsconnector"
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/confmap/xconfmap"
 )
@@ -19,6 +21,19 @@ type Exemplars struct {
 	Enabled bool `mapstructure:"enabled"`
 }
 
+// Fingerprint defines the configuration for the exception fingerprints.
+type Fingerprint struct {
+	// Enabled adds the exception.fingerprint dimension to the metrics and attribute to the logs, computed
+	// from the exception type and its normalized stack frames, and tracks when each fingerprint was first
+	// and last seen.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxFrames is the number of the top stack frames used to compute the fingerprint, 0 uses all of them.
+	MaxFrames int `mapstructure:"max_frames"`
+	// GroupTTL is the duration after which a fingerprint that wasn't seen is forgotten, and reported as new
+	// again when it's seen next.
+	GroupTTL time.Duration `mapstructure:"group_ttl"`
+}
+
 // Config defines the configuration options for exceptionsconnector
 type Config struct {
 	// Dimensions defines the list of additional dimensions on top of the provided:
@@ -31,25 +46,36 @@ type Config struct {
 	Dimensions []Dimension `mapstructure:"dimensions"`
 	// Exemplars defines the configuration for exemplars.
 	Exemplars Exemplars `mapstructure:"exemplars"`
+	// Fingerprint defines the configuration for the exception fingerprints.
+	Fingerprint Fingerprint `mapstructure:"fingerprint"`
 }
 
 var _ xconfmap.Validator = (*Config)(nil)
 
 // Validate checks if the connector configuration is valid
 func (c Config) Validate() error {
-	err := validateDimensions(c.Dimensions)
+	err := validateDimensions(c.Dimensions, c.Fingerprint.Enabled)
 	if err != nil {
 		return err
 	}
+	if c.Fingerprint.MaxFrames < 0 {
+		return errors.New("fingerprint max_frames must not be negative")
+	}
+	if c.Fingerprint.Enabled && c.Fingerprint.GroupTTL <= 0 {
+		return errors.New("fingerprint group_ttl must be positive")
+	}
 	return nil
 }
 
 // validateDimensions checks duplicates for reserved dimensions and additional dimensions.
-func validateDimensions(dimensions []Dimension) error {
+func validateDimensions(dimensions []Dimension, fingerprintEnabled bool) error {
 	labelNames := make(map[string]struct{})
 	for _, key := range []string{serviceNameKey, spanKindKey, spanNameKey, statusCodeKey} {
 		labelNames[key] = struct{}{}
 	}
+	if fingerprintEnabled {
+		labelNames[exceptionFingerprintKey] = struct{}{}
+	}
 
 	for _, key := range dimensions {
 		if _, ok := labelNames[key.Name]; ok {
@@ -6,6 +6,7 @@ package exceptionsconnector
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -40,6 +41,23 @@ func TestLoadConfig(t *testing.T) {
 				Exemplars: Exemplars{
 					Enabled: false,
 				},
+				Fingerprint: Fingerprint{
+					MaxFrames: 10,
+					GroupTTL:  24 * time.Hour,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "fingerprint"),
+			expected: &Config{
+				Dimensions: []Dimension{
+					{Name: exceptionTypeKey},
+				},
+				Fingerprint: Fingerprint{
+					Enabled:   true,
+					MaxFrames: 5,
+					GroupTTL:  time.Hour,
+				},
 			},
 		},
 	}
@@ -61,9 +79,10 @@ func TestLoadConfig(t *testing.T) {
 
 func TestValidateDimensions(t *testing.T) {
 	for _, tc := range []struct {
-		name        string
-		dimensions  []Dimension
-		expectedErr string
+		name               string
+		dimensions         []Dimension
+		fingerprintEnabled bool
+		expectedErr        string
 	}{
 		{
 			name:       "no additional dimensions",
@@ -91,9 +110,17 @@ func TestValidateDimensions(t *testing.T) {
 			},
 			expectedErr: "duplicate dimension name \"service_name\"",
 		},
+		{
+			name: "duplicate dimension with fingerprint",
+			dimensions: []Dimension{
+				{Name: "exception.fingerprint"},
+			},
+			fingerprintEnabled: true,
+			expectedErr:        "duplicate dimension name \"exception.fingerprint\"",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateDimensions(tc.dimensions)
+			err := validateDimensions(tc.dimensions, tc.fingerprintEnabled)
 			if tc.expectedErr != "" {
 				assert.EqualError(t, err, tc.expectedErr)
 			} else {
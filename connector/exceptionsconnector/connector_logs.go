@@ -5,6 +5,7 @@ package exceptionsconnector // import "github.com/open-telemetry/opentelemetry-c
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
@@ -29,6 +30,9 @@ type logsConnector struct {
 	component.ShutdownFunc
 
 	logger *zap.Logger
+
+	// groups tracks the exception fingerprints when they're enabled.
+	groups *exceptionGroups
 }
 
 func newLogsConnector(logger *zap.Logger, config component.Config) *logsConnector {
@@ -38,6 +42,7 @@ func newLogsConnector(logger *zap.Logger, config component.Config) *logsConnecto
 		logger:     logger,
 		config:     *cfg,
 		dimensions: newDimensions(cfg.Dimensions),
+		groups:     newExceptionGroups(cfg.Fingerprint.GroupTTL),
 	}
 }
 
@@ -122,5 +127,14 @@ func (c *logsConnector) attrToLogRecord(sl plog.ScopeLogs, serviceName string, s
 	// Add stacktrace to the log record.
 	attrVal, _ := pdatautil.GetAttributeValue(exceptionStacktraceKey, eventAttrs)
 	logRecord.Attributes().PutStr(exceptionStacktraceKey, attrVal)
+
+	// Add the fingerprint and when it was first seen to the log record.
+	if c.config.Fingerprint.Enabled {
+		excType, fp := eventFingerprint(eventAttrs, c.config.Fingerprint.MaxFrames)
+		group, isNew := c.groups.observe(serviceName, excType, fp, event.Timestamp())
+		logRecord.Attributes().PutStr(exceptionFingerprintKey, fp)
+		logRecord.Attributes().PutBool(exceptionFingerprintNewKey, isNew)
+		logRecord.Attributes().PutStr(exceptionFingerprintFirstSeenKey, group.firstSeen.AsTime().Format(time.RFC3339Nano))
+	}
 	return logRecord
 }
@@ -40,6 +40,9 @@ type metricsConnector struct {
 
 	exceptions map[string]*exception
 
+	// groups tracks the exception fingerprints when they're enabled.
+	groups *exceptionGroups
+
 	logger *zap.Logger
 
 	// The starting time of the data points.
@@ -62,6 +65,7 @@ func newMetricsConnector(logger *zap.Logger, config component.Config) *metricsCo
 		keyBuf:         bytes.NewBuffer(make([]byte, 0, 1024)),
 		startTimestamp: pcommon.NewTimestampFromTime(time.Now()),
 		exceptions:     make(map[string]*exception),
+		groups:         newExceptionGroups(cfg.Fingerprint.GroupTTL),
 	}
 }
 
@@ -94,9 +98,16 @@ func (c *metricsConnector) ConsumeTraces(ctx context.Context, traces ptrace.Trac
 
 						c.keyBuf.Reset()
 						buildKey(c.keyBuf, serviceName, span, c.dimensions, eventAttrs, resourceAttr)
-						key := c.keyBuf.String()
 
 						attrs := buildDimensionKVs(c.dimensions, serviceName, span, eventAttrs, resourceAttr)
+						if c.config.Fingerprint.Enabled {
+							excType, fp := eventFingerprint(eventAttrs, c.config.Fingerprint.MaxFrames)
+							concatDimensionValue(c.keyBuf, fp, true)
+							attrs.PutStr(exceptionFingerprintKey, fp)
+							c.groups.observe(serviceName, excType, fp, event.Timestamp())
+						}
+						key := c.keyBuf.String()
+
 						exc := c.addException(key, attrs)
 						c.addExemplar(exc, span.TraceID(), span.SpanID())
 					}
@@ -117,6 +128,9 @@ func (c *metricsConnector) exportMetrics(ctx context.Context) error {
 		c.lock.Unlock()
 		return err
 	}
+	if c.config.Fingerprint.Enabled {
+		c.collectExceptionGroups(ilm)
+	}
 	c.lock.Unlock()
 
 	if err := c.metricsConsumer.ConsumeMetrics(ctx, m); err != nil {
@@ -152,6 +166,38 @@ func (c *metricsConnector) collectExceptions(ilm pmetric.ScopeMetrics) error {
 	return nil
 }
 
+// collectExceptionGroups writes when the exceptions of each fingerprint were first and last seen into the
+// metrics object, as unix timestamps in seconds.
+func (c *metricsConnector) collectExceptionGroups(ilm pmetric.ScopeMetrics) {
+	groups := c.groups.all()
+
+	mFirstSeen := ilm.Metrics().AppendEmpty()
+	mFirstSeen.SetName("exceptions.fingerprint.first_seen")
+	mFirstSeen.SetUnit("s")
+	firstSeenDps := mFirstSeen.SetEmptyGauge().DataPoints()
+	firstSeenDps.EnsureCapacity(len(groups))
+
+	mLastSeen := ilm.Metrics().AppendEmpty()
+	mLastSeen.SetName("exceptions.fingerprint.last_seen")
+	mLastSeen.SetUnit("s")
+	lastSeenDps := mLastSeen.SetEmptyGauge().DataPoints()
+	lastSeenDps.EnsureCapacity(len(groups))
+
+	timestamp := pcommon.NewTimestampFromTime(time.Now())
+	for _, group := range groups {
+		setExceptionGroupDataPoint(firstSeenDps.AppendEmpty(), group, group.firstSeen, timestamp)
+		setExceptionGroupDataPoint(lastSeenDps.AppendEmpty(), group, group.lastSeen, timestamp)
+	}
+}
+
+func setExceptionGroupDataPoint(dp pmetric.NumberDataPoint, group exceptionGroup, seen pcommon.Timestamp, timestamp pcommon.Timestamp) {
+	dp.SetTimestamp(timestamp)
+	dp.SetDoubleValue(float64(seen) / float64(time.Second))
+	dp.Attributes().PutStr(serviceNameKey, group.serviceName)
+	dp.Attributes().PutStr(exceptionTypeKey, group.exceptionType)
+	dp.Attributes().PutStr(exceptionFingerprintKey, group.fingerprint)
+}
+
 func (c *metricsConnector) addException(excKey string, attrs pcommon.Map) *exception {
 	exc, ok := c.exceptions[excKey]
 	if !ok {
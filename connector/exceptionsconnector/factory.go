@@ -7,6 +7,7 @@ package exceptionsconnector // import "github.com/open-telemetry/opentelemetry-c
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/connector"
@@ -31,6 +32,10 @@ func createDefaultConfig() component.Config {
 			{Name: exceptionTypeKey},
 			{Name: exceptionMessageKey},
 		},
+		Fingerprint: Fingerprint{
+			MaxFrames: 10,
+			GroupTTL:  24 * time.Hour,
+		},
 	}
 }
 
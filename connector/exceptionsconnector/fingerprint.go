@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package exceptionsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/exceptionsconnector"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
+)
+
+const (
+	exceptionFingerprintKey          = "exception.fingerprint"
+	exceptionFingerprintNewKey       = "exception.fingerprint.new"
+	exceptionFingerprintFirstSeenKey = "exception.fingerprint.first_seen"
+)
+
+var (
+	// Frames of Java, .NET and JavaScript stack traces.
+	atFrameRegex = regexp.MustCompile(`^at\s`)
+	// Frames of Python tracebacks.
+	pythonFrameRegex = regexp.MustCompile(`^File\s+"`)
+	// File lines of Go stack traces.
+	goFrameRegex = regexp.MustCompile(`\.go:\d+`)
+
+	// frameReplacements normalize the parts of the frames that change between builds, runs or instances
+	// of the same code. The order matters, the generated class suffixes are replaced before the numbers.
+	frameReplacements = []struct {
+		regex       *regexp.Regexp
+		replacement string
+	}{
+		// Java lambdas, e.g. Foo$$Lambda$123/0x0000000800c0b000
+		{regexp.MustCompile(`\$\$Lambda(\$\d+)?(/0x[0-9a-fA-F]+)?`), "$$$$Lambda"},
+		// Java bytecode generated classes, e.g. Foo$$EnhancerBySpringCGLIB$$1a2b3c4d
+		{regexp.MustCompile(`(\$\$[A-Za-z]+)\$\$[0-9a-fA-F]+`), "$1"},
+		// Java proxies and reflection accessors, e.g. $Proxy12 or GeneratedMethodAccessor34
+		{regexp.MustCompile(`(\$Proxy|GeneratedMethodAccessor|GeneratedConstructorAccessor)\d+`), "$1"},
+		// Java anonymous classes, e.g. Foo$1
+		{regexp.MustCompile(`\$\d+`), "$$"},
+		// .NET compiler generated state machines and closures, e.g. <Run>d__12 or <>c__DisplayClass3_0
+		{regexp.MustCompile(`(d__|c__DisplayClass)[\d_]+`), "$1"},
+		// Memory addresses and offsets, e.g. +0x1d
+		{regexp.MustCompile(`\+?0x[0-9a-fA-F]+`), ""},
+		// Line and column numbers, e.g. Foo.java:42 or app.js:10:5
+		{regexp.MustCompile(`:\d+(:\d+)?`), ""},
+		// Python line numbers, e.g. line 42
+		{regexp.MustCompile(`\bline \d+`), "line"},
+	}
+
+	// Variable parts of the messages, used when the exception has no stack trace.
+	messageIDRegex = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|0x[0-9a-fA-F]+|\d+`)
+)
+
+// fingerprint computes a stable fingerprint of an exception from its type and its normalized stack frames,
+// so the exceptions thrown by the same code share the same fingerprint, whatever their message. Only the top
+// maxFrames frames are used, unless maxFrames is 0. The normalized message is used when the exception has no
+// stack trace.
+func fingerprint(excType, message, stacktrace string, maxFrames int) string {
+	h := sha256.New()
+	h.Write([]byte(excType))
+
+	frames := normalizeFrames(stacktrace, maxFrames)
+	if len(frames) == 0 {
+		h.Write([]byte{0})
+		h.Write([]byte(messageIDRegex.ReplaceAllString(message, "*")))
+	}
+	for _, frame := range frames {
+		h.Write([]byte{0})
+		h.Write([]byte(frame))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// eventFingerprint returns the type and the fingerprint of the exception of the event.
+func eventFingerprint(eventAttrs pcommon.Map, maxFrames int) (excType, fp string) {
+	excType, _ = pdatautil.GetAttributeValue(exceptionTypeKey, eventAttrs)
+	message, _ := pdatautil.GetAttributeValue(exceptionMessageKey, eventAttrs)
+	stacktrace, _ := pdatautil.GetAttributeValue(exceptionStacktraceKey, eventAttrs)
+	return excType, fingerprint(excType, message, stacktrace, maxFrames)
+}
+
+// normalizeFrames extracts the frames of the stack trace, stripping the addresses, the line numbers and the
+// suffixes of the generated classes.
+func normalizeFrames(stacktrace string, maxFrames int) []string {
+	var frames []string
+	for _, line := range strings.Split(stacktrace, "\n") {
+		line = strings.TrimSpace(line)
+		if !atFrameRegex.MatchString(line) && !pythonFrameRegex.MatchString(line) && !goFrameRegex.MatchString(line) {
+			continue
+		}
+		for _, r := range frameReplacements {
+			line = r.regex.ReplaceAllString(line, r.replacement)
+		}
+		frames = append(frames, strings.TrimSpace(line))
+		if maxFrames > 0 && len(frames) == maxFrames {
+			break
+		}
+	}
+	return frames
+}
+
+// exceptionGroup tracks when the exceptions of a fingerprint were seen.
+type exceptionGroup struct {
+	serviceName   string
+	exceptionType string
+	fingerprint   string
+	firstSeen     pcommon.Timestamp
+	lastSeen      pcommon.Timestamp
+	// seenAt is the time of the last observation, used to forget the group.
+	seenAt time.Time
+}
+
+// exceptionGroups tracks the exception groups of each service. A group that wasn't seen during the TTL is
+// forgotten, and is reported as new again when it's seen next.
+type exceptionGroups struct {
+	lock      sync.Mutex
+	groups    map[string]*exceptionGroup
+	ttl       time.Duration
+	lastPurge time.Time
+}
+
+func newExceptionGroups(ttl time.Duration) *exceptionGroups {
+	return &exceptionGroups{
+		groups:    make(map[string]*exceptionGroup),
+		ttl:       ttl,
+		lastPurge: time.Now(),
+	}
+}
+
+// observe records an exception of the group seen at the given time, and returns a copy of the group
+// along with whether it's a new group.
+func (g *exceptionGroups) observe(serviceName, excType, fp string, ts pcommon.Timestamp) (exceptionGroup, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	now := time.Now()
+	if ts == 0 {
+		ts = pcommon.NewTimestampFromTime(now)
+	}
+	g.purge(now)
+
+	key := serviceName + metricKeySeparator + fp
+	group, ok := g.groups[key]
+	if !ok {
+		group = &exceptionGroup{
+			serviceName:   serviceName,
+			exceptionType: excType,
+			fingerprint:   fp,
+			firstSeen:     ts,
+			lastSeen:      ts,
+		}
+		g.groups[key] = group
+	}
+	group.firstSeen = min(group.firstSeen, ts)
+	group.lastSeen = max(group.lastSeen, ts)
+	group.seenAt = now
+	return *group, !ok
+}
+
+// all returns a copy of the groups.
+func (g *exceptionGroups) all() []exceptionGroup {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.purge(time.Now())
+	groups := make([]exceptionGroup, 0, len(g.groups))
+	for _, group := range g.groups {
+		groups = append(groups, *group)
+	}
+	return groups
+}
+
+// purge forgets the groups that weren't seen during the TTL, at most once per minute.
+func (g *exceptionGroups) purge(now time.Time) {
+	if now.Sub(g.lastPurge) < time.Minute {
+		return
+	}
+	g.lastPurge = now
+	for key, group := range g.groups {
+		if now.Sub(group.seenAt) > g.ttl {
+			delete(g.groups, key)
+		}
+	}
+}
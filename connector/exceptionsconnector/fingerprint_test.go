@@ -0,0 +1,261 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package exceptionsconnector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.27.0"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFingerprint(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		excType     string
+		message     string
+		stacktrace  string
+		otherType   string
+		otherMsg    string
+		otherTrace  string
+		sameGroup   bool
+		firstFrames []string
+	}{
+		{
+			name:    "java line numbers and messages",
+			excType: "java.lang.IllegalStateException",
+			message: "order 1234 not found",
+			stacktrace: `java.lang.IllegalStateException: order 1234 not found
+	at com.example.OrderService.find(OrderService.java:42)
+	at com.example.OrderController.get(OrderController.java:17)
+	... 12 more`,
+			otherMsg: "order 5678 not found",
+			otherTrace: `java.lang.IllegalStateException: order 5678 not found
+	at com.example.OrderService.find(OrderService.java:44)
+	at com.example.OrderController.get(OrderController.java:19)
+	... 3 more`,
+			sameGroup: true,
+		},
+		{
+			name:    "java generated classes",
+			excType: "java.lang.NullPointerException",
+			stacktrace: `java.lang.NullPointerException
+	at com.example.Service$$EnhancerBySpringCGLIB$$1a2b3c4d.run(<generated>)
+	at com.example.Service$$Lambda$123/0x0000000800c0b000.apply(Unknown Source)
+	at com.example.Service$1.call(Service.java:10)
+	at jdk.proxy2.$Proxy12.call(Unknown Source)`,
+			otherTrace: `java.lang.NullPointerException
+	at com.example.Service$$EnhancerBySpringCGLIB$$9f8e7d6c.run(<generated>)
+	at com.example.Service$$Lambda$456/0x0000000800d0c000.apply(Unknown Source)
+	at com.example.Service$2.call(Service.java:12)
+	at jdk.proxy2.$Proxy34.call(Unknown Source)`,
+			sameGroup: true,
+			firstFrames: []string{
+				"at com.example.Service$$EnhancerBySpringCGLIB.run(<generated>)",
+				"at com.example.Service$$Lambda.apply(Unknown Source)",
+				"at com.example.Service$.call(Service.java)",
+				"at jdk.proxy2.$Proxy.call(Unknown Source)",
+			},
+		},
+		{
+			name:    "python line numbers",
+			excType: "ValueError",
+			stacktrace: `Traceback (most recent call last):
+  File "/app/main.py", line 12, in handle
+    parse(value)
+ValueError: invalid literal 42`,
+			otherTrace: `Traceback (most recent call last):
+  File "/app/main.py", line 15, in handle
+    parse(value)
+ValueError: invalid literal 43`,
+			sameGroup:   true,
+			firstFrames: []string{`File "/app/main.py", line, in handle`},
+		},
+		{
+			name:    "go addresses",
+			excType: "*errors.errorString",
+			stacktrace: `goroutine 1 [running]:
+main.handle(0xc000012345)
+	/app/main.go:12 +0x1d
+main.main()
+	/app/main.go:20 +0x25`,
+			otherTrace: `goroutine 7 [running]:
+main.handle(0xc000067890)
+	/app/main.go:14 +0x2f
+main.main()
+	/app/main.go:22 +0x31`,
+			sameGroup:   true,
+			firstFrames: []string{"/app/main.go", "/app/main.go"},
+		},
+		{
+			name:    "different frames",
+			excType: "java.lang.IllegalStateException",
+			stacktrace: `java.lang.IllegalStateException
+	at com.example.OrderService.find(OrderService.java:42)`,
+			otherTrace: `java.lang.IllegalStateException
+	at com.example.UserService.find(UserService.java:42)`,
+		},
+		{
+			name:       "different types",
+			excType:    "java.lang.IllegalStateException",
+			otherType:  "java.lang.IllegalArgumentException",
+			stacktrace: "at com.example.OrderService.find(OrderService.java:42)",
+		},
+		{
+			name:      "messages without stack trace",
+			excType:   "Exception",
+			message:   "user 6ba7b810-9dad-11d1-80b4-00c04fd430c8 failed after 3 retries",
+			otherMsg:  "user 6ba7b811-9dad-11d1-80b4-00c04fd430c8 failed after 5 retries",
+			sameGroup: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			otherType := tc.excType
+			if tc.otherType != "" {
+				otherType = tc.otherType
+			}
+			otherTrace := tc.stacktrace
+			if tc.otherTrace != "" {
+				otherTrace = tc.otherTrace
+			}
+
+			fp := fingerprint(tc.excType, tc.message, tc.stacktrace, 0)
+			assert.Len(t, fp, 16)
+			if tc.sameGroup {
+				assert.Equal(t, fp, fingerprint(otherType, tc.otherMsg, otherTrace, 0))
+			} else {
+				assert.NotEqual(t, fp, fingerprint(otherType, tc.otherMsg, otherTrace, 0))
+			}
+			if tc.firstFrames != nil {
+				assert.Equal(t, tc.firstFrames, normalizeFrames(tc.stacktrace, 0))
+			}
+		})
+	}
+}
+
+func TestFingerprintMaxFrames(t *testing.T) {
+	stacktrace := `java.lang.IllegalStateException
+	at com.example.OrderService.find(OrderService.java:42)
+	at com.example.OrderController.get(OrderController.java:17)`
+	otherStacktrace := `java.lang.IllegalStateException
+	at com.example.OrderService.find(OrderService.java:42)
+	at com.example.AdminController.get(AdminController.java:17)`
+
+	assert.Len(t, normalizeFrames(stacktrace, 1), 1)
+	assert.Equal(t, fingerprint("Exception", "", stacktrace, 1), fingerprint("Exception", "", otherStacktrace, 1))
+	assert.NotEqual(t, fingerprint("Exception", "", stacktrace, 2), fingerprint("Exception", "", otherStacktrace, 2))
+}
+
+func TestExceptionGroups(t *testing.T) {
+	groups := newExceptionGroups(time.Hour)
+	first := pcommon.Timestamp(2 * time.Second)
+	last := pcommon.Timestamp(5 * time.Second)
+
+	group, isNew := groups.observe("service-a", "Exception", "fp", last)
+	assert.True(t, isNew)
+	assert.Equal(t, last, group.firstSeen)
+
+	// Out of order exceptions update the first seen time
+	group, isNew = groups.observe("service-a", "Exception", "fp", first)
+	assert.False(t, isNew)
+	assert.Equal(t, first, group.firstSeen)
+	assert.Equal(t, last, group.lastSeen)
+
+	// The same fingerprint is a different group in another service
+	_, isNew = groups.observe("service-b", "Exception", "fp", first)
+	assert.True(t, isNew)
+	assert.Len(t, groups.all(), 2)
+
+	// The groups that weren't seen during the TTL are forgotten
+	groups.groups["service-a"+metricKeySeparator+"fp"].seenAt = time.Now().Add(-2 * time.Hour)
+	groups.lastPurge = time.Now().Add(-2 * time.Minute)
+	require.Len(t, groups.all(), 1)
+	_, isNew = groups.observe("service-a", "Exception", "fp", last)
+	assert.True(t, isNew)
+}
+
+func TestConnectorFingerprint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Dimensions = []Dimension{{Name: exceptionTypeKey}}
+	cfg.Fingerprint.Enabled = true
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "service-a")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("get-order")
+	for i, line := range []int{42, 44} {
+		e := span.Events().AppendEmpty()
+		e.SetName(eventNameExc)
+		e.SetTimestamp(pcommon.Timestamp((i + 1) * int(time.Second)))
+		e.Attributes().PutStr(exceptionTypeKey, "java.lang.IllegalStateException")
+		e.Attributes().PutStr(exceptionMessageKey, "order not found")
+		e.Attributes().PutStr(exceptionStacktraceKey, fmt.Sprintf("java.lang.IllegalStateException\n\tat com.example.OrderService.find(OrderService.java:%d)", line))
+	}
+	fp := fingerprint("java.lang.IllegalStateException", "", "at com.example.OrderService.find(OrderService.java)", 0)
+
+	t.Run("metrics", func(t *testing.T) {
+		msink := new(consumertest.MetricsSink)
+		c := newMetricsConnector(zaptest.NewLogger(t), cfg)
+		c.metricsConsumer = msink
+		require.NoError(t, c.ConsumeTraces(context.Background(), traces))
+
+		require.Len(t, msink.AllMetrics(), 1)
+		metrics := msink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		require.Equal(t, 3, metrics.Len())
+
+		// Both exceptions are grouped in the same data point
+		exceptions := metrics.At(0).Sum().DataPoints()
+		require.Equal(t, 1, exceptions.Len())
+		assert.Equal(t, int64(2), exceptions.At(0).IntValue())
+		assertStrAttr(t, exceptions.At(0).Attributes(), exceptionFingerprintKey, fp)
+
+		for i, expected := range map[int]float64{1: 1, 2: 2} {
+			m := metrics.At(i)
+			require.Equal(t, pmetric.MetricTypeGauge, m.Type())
+			dps := m.Gauge().DataPoints()
+			require.Equal(t, 1, dps.Len())
+			assert.Equal(t, expected, dps.At(0).DoubleValue())
+			assertStrAttr(t, dps.At(0).Attributes(), serviceNameKey, "service-a")
+			assertStrAttr(t, dps.At(0).Attributes(), exceptionTypeKey, "java.lang.IllegalStateException")
+			assertStrAttr(t, dps.At(0).Attributes(), exceptionFingerprintKey, fp)
+		}
+		assert.Equal(t, "exceptions.fingerprint.first_seen", metrics.At(1).Name())
+		assert.Equal(t, "exceptions.fingerprint.last_seen", metrics.At(2).Name())
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		lsink := new(consumertest.LogsSink)
+		c := newLogsConnector(zaptest.NewLogger(t), cfg)
+		c.logsConsumer = lsink
+		require.NoError(t, c.ConsumeTraces(context.Background(), traces))
+
+		require.Len(t, lsink.AllLogs(), 1)
+		records := lsink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		require.Equal(t, 2, records.Len())
+		for i, isNew := range []bool{true, false} {
+			attrs := records.At(i).Attributes()
+			assertStrAttr(t, attrs, exceptionFingerprintKey, fp)
+			assertStrAttr(t, attrs, exceptionFingerprintFirstSeenKey, time.Unix(1, 0).UTC().Format(time.RFC3339Nano))
+			v, ok := attrs.Get(exceptionFingerprintNewKey)
+			require.True(t, ok)
+			assert.Equal(t, isNew, v.Bool())
+		}
+	})
+}
+
+func assertStrAttr(t *testing.T, attrs pcommon.Map, key, expected string) {
+	v, ok := attrs.Get(key)
+	require.True(t, ok, key)
+	assert.Equal(t, expected, v.Str())
+}
@@ -5,14 +5,23 @@ package failoverconnector // import "github.com/open-telemetry/opentelemetry-col
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pipeline"
 )
 
 var (
 	errNoPipelinePriority    = errors.New("No pipelines are defined in the priority list")
 	errInvalidRetryIntervals = errors.New("Retry interval must be positive")
+
+	errInvalidSaturationThreshold = errors.New("Queue saturation threshold must be greater than 0 and at most 1")
+	errInvalidSaturationInterval  = errors.New("Queue saturation interval must be positive")
+	errNoSaturationEndpoint       = errors.New("Queue saturation endpoint must be set")
+	errInvalidSaturationEndpoint  = errors.New("Queue saturation endpoint must be an http or https URL")
+	errInvalidSaturationTimeout   = errors.New("Queue saturation timeout must be positive and at most the interval")
 )
 
 type Config struct {
@@ -33,6 +42,36 @@ type Config struct {
 	// MaxRetry is the maximum retries per level, once this limit is hit for a level, even if the next pipeline level fails,
 	// it will not try to recover the level that exceeded the maximum retries
 	MaxRetries int `mapstructure:"max_retries"` // **Deprecated**
+
+	// StatusSource is the optional ID of an extension aggregating the status events of the components, such as the
+	// healthcheckv2 extension. A level is then considered unhealthy as soon as one of its pipelines reports an error
+	// status, without waiting for the pipeline to return an error, and is probed again once the pipelines recover
+	StatusSource *component.ID `mapstructure:"status_source"`
+
+	// QueueSaturation configures the failover on the saturation of the sending queues of the exporters
+	QueueSaturation QueueSaturationConfig `mapstructure:"queue_saturation"`
+}
+
+// QueueSaturationConfig configures how the size of the sending queues of the exporters is read from the internal
+// telemetry of the collector. A level is considered unhealthy while the queue of one of the exporters of its pipelines
+// is saturated, and is probed again once the queues drain
+type QueueSaturationConfig struct {
+	// Endpoint is the URL of the Prometheus endpoint of the internal telemetry of the collector, exposing the
+	// otelcol_exporter_queue_size and otelcol_exporter_queue_capacity metrics
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Exporters are the exporters of each pipeline of the priority levels whose sending queue is watched,
+	// by pipeline ID. The saturation isn't watched when no exporter is listed
+	Exporters map[string][]component.ID `mapstructure:"exporters"`
+
+	// Threshold is the ratio of the size of a queue to its capacity from which the queue is saturated
+	Threshold float64 `mapstructure:"threshold"`
+
+	// Interval is the frequency at which the size of the queues is read
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout is the time limit of a read of the size of the queues, the endpoint being local
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 // Validate needs to ensure RetryInterval > # elements in PriorityList * RetryGap
@@ -43,5 +82,43 @@ func (c *Config) Validate() error {
 	if c.RetryInterval <= 0 {
 		return errInvalidRetryIntervals
 	}
+	return c.QueueSaturation.validate(c.PipelinePriority)
+}
+
+func (c *QueueSaturationConfig) validate(priority [][]pipeline.ID) error {
+	if len(c.Exporters) == 0 {
+		return nil
+	}
+	if c.Endpoint == "" {
+		return errNoSaturationEndpoint
+	}
+	if u, err := url.Parse(c.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidSaturationEndpoint
+	}
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return errInvalidSaturationThreshold
+	}
+	if c.Interval <= 0 {
+		return errInvalidSaturationInterval
+	}
+	if c.Timeout <= 0 || c.Timeout > c.Interval {
+		return errInvalidSaturationTimeout
+	}
+	for key := range c.Exporters {
+		if !hasPipeline(priority, key) {
+			return fmt.Errorf("Queue saturation exporters are set for %q, which isn't a pipeline of the priority levels", key)
+		}
+	}
 	return nil
 }
+
+func hasPipeline(priority [][]pipeline.ID, id string) bool {
+	for _, pipelines := range priority {
+		for _, pipelineID := range pipelines {
+			if pipelineID.String() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
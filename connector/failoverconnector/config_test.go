@@ -4,6 +4,7 @@
 package failoverconnector
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
@@ -19,6 +20,7 @@ import (
 )
 
 func TestLoadConfig(t *testing.T) {
+	defaultQueueSaturation := createDefaultConfig().(*Config).QueueSaturation
 	testcases := []struct {
 		id       component.ID
 		expected *Config
@@ -31,7 +33,8 @@ func TestLoadConfig(t *testing.T) {
 						pipeline.NewIDWithName(pipeline.SignalTraces, ""),
 					},
 				},
-				RetryInterval: 10 * time.Minute,
+				RetryInterval:   10 * time.Minute,
+				QueueSaturation: defaultQueueSaturation,
 			},
 		},
 		{
@@ -52,7 +55,47 @@ func TestLoadConfig(t *testing.T) {
 						pipeline.NewIDWithName(pipeline.SignalTraces, "fourth"),
 					},
 				},
-				RetryInterval: 5 * time.Minute,
+				RetryInterval:   5 * time.Minute,
+				QueueSaturation: defaultQueueSaturation,
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "status_source"),
+			expected: &Config{
+				PipelinePriority: [][]pipeline.ID{
+					{
+						pipeline.NewIDWithName(pipeline.SignalTraces, "first"),
+					},
+					{
+						pipeline.NewIDWithName(pipeline.SignalTraces, "second"),
+					},
+				},
+				RetryInterval:   10 * time.Minute,
+				StatusSource:    &statusSourceID,
+				QueueSaturation: defaultQueueSaturation,
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "queue_saturation"),
+			expected: &Config{
+				PipelinePriority: [][]pipeline.ID{
+					{
+						pipeline.NewIDWithName(pipeline.SignalTraces, "first"),
+					},
+					{
+						pipeline.NewIDWithName(pipeline.SignalTraces, "second"),
+					},
+				},
+				RetryInterval: 10 * time.Minute,
+				QueueSaturation: QueueSaturationConfig{
+					Endpoint: "http://localhost:8888/metrics",
+					Exporters: map[string][]component.ID{
+						"traces/first": {component.MustNewIDWithName("otlp", "first")},
+					},
+					Threshold: 0.9,
+					Interval:  10 * time.Second,
+					Timeout:   time.Second,
+				},
 			},
 		},
 	}
@@ -91,6 +134,26 @@ func TestValidateConfig(t *testing.T) {
 			id:   component.NewIDWithName(metadata.Type, "invalid"),
 			err:  errInvalidRetryIntervals,
 		},
+		{
+			name: "invalid queue saturation threshold",
+			id:   component.NewIDWithName(metadata.Type, "invalid_saturation_threshold"),
+			err:  errInvalidSaturationThreshold,
+		},
+		{
+			name: "invalid queue saturation endpoint",
+			id:   component.NewIDWithName(metadata.Type, "invalid_saturation_endpoint"),
+			err:  errInvalidSaturationEndpoint,
+		},
+		{
+			name: "invalid queue saturation timeout",
+			id:   component.NewIDWithName(metadata.Type, "invalid_saturation_timeout"),
+			err:  errInvalidSaturationTimeout,
+		},
+		{
+			name: "queue saturation exporters of an unknown pipeline",
+			id:   component.NewIDWithName(metadata.Type, "invalid_saturation_pipeline"),
+			err:  errors.New(`Queue saturation exporters are set for "traces/second", which isn't a pipeline of the priority levels`),
+		},
 	}
 
 	for _, tc := range testcases {
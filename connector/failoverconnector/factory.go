@@ -29,6 +29,12 @@ func createDefaultConfig() component.Config {
 		RetryInterval: 10 * time.Minute,
 		RetryGap:      0,
 		MaxRetries:    0,
+		QueueSaturation: QueueSaturationConfig{
+			Endpoint:  "http://localhost:8888/metrics",
+			Threshold: 0.8,
+			Interval:  10 * time.Second,
+			Timeout:   time.Second,
+		},
 	}
 }
 
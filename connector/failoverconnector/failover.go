@@ -5,10 +5,12 @@ package failoverconnector // import "github.com/open-telemetry/opentelemetry-col
 
 import (
 	"errors"
+	"sync"
 
 	"go.opentelemetry.io/collector/pipeline"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector/internal/state"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
 )
 
 var (
@@ -27,6 +29,11 @@ type baseFailoverRouter[C any] struct {
 	errTryLock  *state.TryLock
 	notifyRetry chan struct{}
 	done        chan struct{}
+
+	healthLock     sync.Mutex
+	pipelineHealth [][]pipelineHealth
+	unsubscribes   []status.UnsubscribeFunc
+	watchers       sync.WaitGroup
 }
 
 // getCurrentConsumer returns the consumer for the current healthy level
@@ -51,34 +58,42 @@ func (f *baseFailoverRouter[C]) reportConsumerError(idx int) {
 
 func (f *baseFailoverRouter[C]) Shutdown() {
 	close(f.done)
+	for _, unsubscribe := range f.unsubscribes {
+		unsubscribe()
+	}
+	f.watchers.Wait()
 }
 
 func newBaseFailoverRouter[C any](provider consumerProvider[C], cfg *Config) (*baseFailoverRouter[C], error) {
 	done := make(chan struct{})
 	notifyRetry := make(chan struct{}, 1)
 	pSConstants := state.PSConstants{
+		Levels:        len(cfg.PipelinePriority),
 		RetryInterval: cfg.RetryInterval,
 		RetryGap:      cfg.RetryGap,
 		MaxRetries:    cfg.MaxRetries,
 	}
 
 	consumers := make([]C, 0)
+	health := make([][]pipelineHealth, 0, len(cfg.PipelinePriority))
 	for _, pipelines := range cfg.PipelinePriority {
 		baseConsumer, err := provider(pipelines...)
 		if err != nil {
 			return nil, errConsumer
 		}
 		consumers = append(consumers, baseConsumer)
+		health = append(health, make([]pipelineHealth, len(pipelines)))
 	}
 
 	selector := state.NewPipelineSelector(notifyRetry, done, pSConstants)
 	return &baseFailoverRouter[C]{
-		consumers:   consumers,
-		cfg:         cfg,
-		pS:          selector,
-		errTryLock:  state.NewTryLock(),
-		done:        done,
-		notifyRetry: notifyRetry,
+		consumers:      consumers,
+		cfg:            cfg,
+		pS:             selector,
+		errTryLock:     state.NewTryLock(),
+		done:           done,
+		notifyRetry:    notifyRetry,
+		pipelineHealth: health,
 	}, nil
 }
 
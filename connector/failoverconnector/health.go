@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package failoverconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
+)
+
+// statusSource is implemented by the extensions aggregating the status events of the components,
+// such as the healthcheckv2 extension.
+type statusSource interface {
+	Subscribe(scope status.Scope, verbosity status.Verbosity) (<-chan *status.AggregateStatus, status.UnsubscribeFunc)
+}
+
+// startHealthWatch subscribes to the status of the pipelines of every level, so the connector fails over
+// from a level as soon as one of its pipelines reports an error status, and probes the level again
+// once all its pipelines recover.
+func (f *baseFailoverRouter[C]) startHealthWatch(host component.Host) error {
+	if f.cfg.StatusSource == nil {
+		return nil
+	}
+
+	ext, ok := host.GetExtensions()[*f.cfg.StatusSource]
+	if !ok {
+		return fmt.Errorf("status source extension %q not found", f.cfg.StatusSource)
+	}
+	source, ok := ext.(statusSource)
+	if !ok {
+		return fmt.Errorf("extension %q doesn't provide the status of the components", f.cfg.StatusSource)
+	}
+
+	for level, pipelines := range f.cfg.PipelinePriority {
+		for i, pipelineID := range pipelines {
+			statusCh, unsubscribe := source.Subscribe(status.Scope(pipelineID.String()), status.Concise)
+			f.unsubscribes = append(f.unsubscribes, unsubscribe)
+			f.watchers.Add(1)
+			go f.watchPipelineHealth(level, i, statusCh)
+		}
+	}
+	return nil
+}
+
+func (f *baseFailoverRouter[C]) watchPipelineHealth(level, i int, statusCh <-chan *status.AggregateStatus) {
+	defer f.watchers.Done()
+	for {
+		select {
+		case st, ok := <-statusCh:
+			if !ok {
+				return
+			}
+			f.setPipelineHealth(level, i, isHealthy(st))
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// pipelineHealth is the health of a pipeline, as reported by the status source and by the size of the sending
+// queues of its exporters
+type pipelineHealth struct {
+	statusError bool
+	saturated   bool
+}
+
+func (h pipelineHealth) healthy() bool {
+	return !h.statusError && !h.saturated
+}
+
+// setPipelineHealth records the health reported by the status source for a pipeline
+func (f *baseFailoverRouter[C]) setPipelineHealth(level, i int, healthy bool) {
+	f.updatePipelineHealth(level, i, func(h *pipelineHealth) {
+		h.statusError = !healthy
+	})
+}
+
+// setPipelineSaturated records whether the sending queue of one of the exporters of a pipeline is saturated
+func (f *baseFailoverRouter[C]) setPipelineSaturated(level, i int, saturated bool) {
+	f.updatePipelineHealth(level, i, func(h *pipelineHealth) {
+		h.saturated = saturated
+	})
+}
+
+// updatePipelineHealth updates the health of a pipeline, a level is healthy when all its pipelines are healthy.
+// The pipeline selector is only notified when the health of the level changes
+func (f *baseFailoverRouter[C]) updatePipelineHealth(level, i int, update func(*pipelineHealth)) {
+	f.healthLock.Lock()
+	wasHealthy := f.isLevelHealthy(level)
+	update(&f.pipelineHealth[level][i])
+	levelHealthy := f.isLevelHealthy(level)
+	failover := levelHealthy != wasHealthy && f.pS.SetLevelHealth(level, levelHealthy)
+	f.healthLock.Unlock()
+
+	if failover {
+		f.reportConsumerError(level)
+	}
+}
+
+func (f *baseFailoverRouter[C]) isLevelHealthy(level int) bool {
+	for _, h := range f.pipelineHealth[level] {
+		if !h.healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// isHealthy returns false if the status is an error, the pipelines that didn't report
+// their status yet are considered healthy
+func isHealthy(st *status.AggregateStatus) bool {
+	if st == nil || st.Event == nil {
+		return true
+	}
+	switch st.Status() {
+	case componentstatus.StatusRecoverableError, componentstatus.StatusPermanentError, componentstatus.StatusFatalError:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package failoverconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
+)
+
+var statusSourceID = component.MustNewID("healthcheckv2")
+
+type statusSourceExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*status.Aggregator
+}
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// channelStatusSource provides the status of the components through a channel which is never closed
+type channelStatusSource struct {
+	component.StartFunc
+	component.ShutdownFunc
+	statusCh chan *status.AggregateStatus
+}
+
+func (s *channelStatusSource) Subscribe(status.Scope, status.Verbosity) (<-chan *status.AggregateStatus, status.UnsubscribeFunc) {
+	return s.statusCh, func() {}
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *hostWithExtensions) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestStatusSourceFailover(t *testing.T) {
+	var sinkFirst, sinkSecond consumertest.TracesSink
+	tracesFirst := pipeline.NewIDWithName(pipeline.SignalTraces, "traces/first")
+	tracesSecond := pipeline.NewIDWithName(pipeline.SignalTraces, "traces/second")
+
+	cfg := &Config{
+		PipelinePriority: [][]pipeline.ID{{tracesFirst}, {tracesSecond}},
+		RetryInterval:    time.Hour,
+		StatusSource:     &statusSourceID,
+	}
+
+	router := connector.NewTracesRouter(map[pipeline.ID]consumer.Traces{
+		tracesFirst:  &sinkFirst,
+		tracesSecond: &sinkSecond,
+	})
+
+	conn, err := NewFactory().CreateTracesToTraces(context.Background(),
+		connectortest.NewNopSettings(metadata.Type), cfg, router.(consumer.Traces))
+	require.NoError(t, err)
+
+	aggregator := status.NewAggregator(status.PriorityPermanent)
+	host := &hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{statusSourceID: &statusSourceExtension{Aggregator: aggregator}},
+	}
+	require.NoError(t, conn.Start(context.Background(), host))
+
+	failoverConnector := conn.(*tracesFailover)
+	tr := sampleTrace()
+
+	defer func() {
+		assert.NoError(t, failoverConnector.Shutdown(context.Background()))
+		aggregator.Close()
+	}()
+
+	exporterID := componentstatus.NewInstanceID(component.MustNewID("otlp"), component.KindExporter).WithPipelines(tracesFirst)
+
+	// The connector fails over as soon as the exporter reports an error, before the pipeline returns one
+	aggregator.RecordStatus(exporterID, componentstatus.NewRecoverableErrorEvent(assert.AnError))
+	require.Eventually(t, func() bool {
+		return failoverConnector.failover.TestGetCurrentConsumerIndex() == 1
+	}, 3*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, conn.ConsumeTraces(context.Background(), tr))
+	assert.Equal(t, 0, sinkFirst.SpanCount())
+	assert.Equal(t, 1, sinkSecond.SpanCount())
+
+	// The recovered level is probed with the next data, without waiting for the retry interval
+	aggregator.RecordStatus(exporterID, componentstatus.NewEvent(componentstatus.StatusOK))
+	require.Eventually(t, func() bool {
+		return consumeTracesAndCheckStable(failoverConnector, 0, tr)
+	}, 3*time.Second, 5*time.Millisecond)
+	assert.Positive(t, sinkFirst.SpanCount())
+}
+
+func TestStatusSourceNotFound(t *testing.T) {
+	tracesFirst := pipeline.NewIDWithName(pipeline.SignalTraces, "traces/first")
+	cfg := &Config{
+		PipelinePriority: [][]pipeline.ID{{tracesFirst}},
+		RetryInterval:    time.Hour,
+		StatusSource:     &statusSourceID,
+	}
+
+	router := connector.NewTracesRouter(map[pipeline.ID]consumer.Traces{
+		tracesFirst: &consumertest.TracesSink{},
+	})
+
+	conn, err := NewFactory().CreateTracesToTraces(context.Background(),
+		connectortest.NewNopSettings(metadata.Type), cfg, router.(consumer.Traces))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, conn.Shutdown(context.Background()))
+	}()
+
+	err = conn.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err, `status source extension "healthcheckv2" not found`)
+
+	// The extension doesn't aggregate the status of the components
+	host := &hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{statusSourceID: &nopExtension{}},
+	}
+	err = conn.Start(context.Background(), host)
+	assert.EqualError(t, err, `extension "healthcheckv2" doesn't provide the status of the components`)
+}
+
+func TestStatusSourceShutdown(t *testing.T) {
+	tracesFirst := pipeline.NewIDWithName(pipeline.SignalTraces, "traces/first")
+	cfg := &Config{
+		PipelinePriority: [][]pipeline.ID{{tracesFirst}},
+		RetryInterval:    time.Hour,
+		StatusSource:     &statusSourceID,
+	}
+
+	router := connector.NewTracesRouter(map[pipeline.ID]consumer.Traces{
+		tracesFirst: &consumertest.TracesSink{},
+	})
+
+	conn, err := NewFactory().CreateTracesToTraces(context.Background(),
+		connectortest.NewNopSettings(metadata.Type), cfg, router.(consumer.Traces))
+	require.NoError(t, err)
+
+	source := &channelStatusSource{statusCh: make(chan *status.AggregateStatus)}
+	host := &hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{statusSourceID: source},
+	}
+	require.NoError(t, conn.Start(context.Background(), host))
+	source.statusCh <- &status.AggregateStatus{}
+
+	// The status is no longer watched once the connector is shut down, although the channel isn't closed
+	require.NoError(t, conn.Shutdown(context.Background()))
+	select {
+	case source.statusCh <- &status.AggregateStatus{}:
+		assert.Fail(t, "the status is still watched after the shutdown")
+	default:
+	}
+}
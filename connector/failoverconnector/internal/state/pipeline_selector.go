@@ -10,7 +10,9 @@ import (
 )
 
 type PipelineSelector struct {
-	currentPipeline   int
+	currentPipeline int
+	// unhealthy tracks the levels reported unhealthy by the status source or with saturated sending queues
+	unhealthy         map[int]bool
 	constants         PSConstants
 	lock              sync.RWMutex
 	retryEnabledToken chan struct{}
@@ -22,18 +24,53 @@ type PipelineSelector struct {
 
 // HandleError is called when an error is returned on a healthy pipeline
 func (p *PipelineSelector) HandleError(idx int) {
-	if idx != p.currentPipeline {
+	if idx != p.CurrentPipeline() {
 		return
 	}
 	p.NextStableLevel()
 	p.TryEnableRetry()
 }
 
-// NextStableLevel increments the level to the next in the priority list
+// NextStableLevel increments the level to the next in the priority list, skipping the levels
+// reported unhealthy unless they are the last ones
 func (p *PipelineSelector) NextStableLevel() {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	p.currentPipeline++
+	for p.unhealthy[p.currentPipeline] && p.currentPipeline < p.constants.Levels-1 {
+		p.currentPipeline++
+	}
+}
+
+// SetLevelHealth records the health of a level reported by the status source or by the saturation of the
+// sending queues of its exporters. When a level above the current one recovers, the retry is notified so
+// the level is probed with the next data. It returns true when the current level is unhealthy and the
+// connector should fail over.
+func (p *PipelineSelector) SetLevelHealth(idx int, healthy bool) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	wasUnhealthy := p.unhealthy[idx]
+	if healthy {
+		delete(p.unhealthy, idx)
+	} else {
+		p.unhealthy[idx] = true
+	}
+
+	if healthy && wasUnhealthy && idx < p.currentPipeline {
+		select {
+		case p.retryChan <- struct{}{}:
+		default:
+		}
+	}
+	return !healthy && idx == p.currentPipeline
+}
+
+// IsLevelHealthy returns false if the level is reported unhealthy
+func (p *PipelineSelector) IsLevelHealthy(idx int) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return !p.unhealthy[idx]
 }
 
 // TryEnableRetry checks if a retry is already in effect and if not starts the retry goroutine
@@ -100,6 +137,7 @@ func NewPipelineSelector(retryChan chan<- struct{}, done chan struct{}, consts P
 
 	ps := &PipelineSelector{
 		currentPipeline:   0,
+		unhealthy:         make(map[int]bool),
 		constants:         consts,
 		retryEnabledToken: retryEnabledToken,
 		retryChan:         retryChan,
@@ -73,3 +73,33 @@ func TestCurrentPipelineWithReset(t *testing.T) {
 		return idx == 0
 	}, 3*time.Second, 5*time.Millisecond)
 }
+
+func TestSetLevelHealth(t *testing.T) {
+	done := make(chan struct{})
+	retryChan := make(chan struct{}, 1)
+	constants := PSConstants{
+		Levels:        3,
+		RetryInterval: time.Hour,
+	}
+	pS := NewPipelineSelector(retryChan, done, constants)
+
+	defer func() {
+		close(done)
+	}()
+
+	// An unhealthy level below the current one is skipped when failing over
+	require.False(t, pS.SetLevelHealth(1, false))
+	require.False(t, pS.IsLevelHealthy(1))
+	require.True(t, pS.SetLevelHealth(0, false))
+	pS.HandleError(0)
+	require.Equal(t, 2, pS.CurrentPipeline())
+
+	// The last level is used even if it's unhealthy
+	require.True(t, pS.SetLevelHealth(2, false))
+	require.Equal(t, 2, pS.CurrentPipeline())
+
+	// A recovered level above the current one is probed with the next data
+	require.False(t, pS.SetLevelHealth(1, true))
+	require.True(t, pS.IsLevelHealthy(1))
+	require.Len(t, retryChan, 1)
+}
@@ -10,6 +10,8 @@ import (
 )
 
 type PSConstants struct {
+	// Levels is the number of pipeline levels
+	Levels        int
 	RetryInterval time.Duration
 	RetryGap      time.Duration
 	MaxRetries    int
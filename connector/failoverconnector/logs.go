@@ -59,6 +59,10 @@ func (f *logsRouter) consumeByHealthyPipeline(ctx context.Context, ld plog.Logs)
 func (f *logsRouter) sampleRetryConsumers(ctx context.Context, ld plog.Logs) bool {
 	stableIndex := f.pS.CurrentPipeline()
 	for i := 0; i < stableIndex; i++ {
+		// the levels reported unhealthy by the status source are probed once they recover
+		if !f.pS.IsLevelHealthy(i) {
+			continue
+		}
 		consumer := f.getConsumerAtIndex(i)
 		err := consumer.ConsumeLogs(ctx, ld)
 		if err == nil {
@@ -70,7 +74,6 @@ func (f *logsRouter) sampleRetryConsumers(ctx context.Context, ld plog.Logs) boo
 }
 
 type logsFailover struct {
-	component.StartFunc
 	component.ShutdownFunc
 
 	config   *Config
@@ -87,6 +90,15 @@ func (f *logsFailover) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
 	return f.failover.Consume(ctx, ld)
 }
 
+// Start subscribes to the status of the pipelines when a status source is configured, and watches the
+// saturation of the sending queues of the exporters when they are configured
+func (f *logsFailover) Start(ctx context.Context, host component.Host) error {
+	if err := f.failover.startHealthWatch(host); err != nil {
+		return err
+	}
+	return f.failover.startSaturationWatch(ctx, f.logger)
+}
+
 func (f *logsFailover) Shutdown(_ context.Context) error {
 	if f.failover != nil {
 		f.failover.Shutdown()
@@ -59,6 +59,10 @@ func (f *metricsRouter) consumeByHealthyPipeline(ctx context.Context, md pmetric
 func (f *metricsRouter) sampleRetryConsumers(ctx context.Context, md pmetric.Metrics) bool {
 	stableIndex := f.pS.CurrentPipeline()
 	for i := 0; i < stableIndex; i++ {
+		// the levels reported unhealthy by the status source are probed once they recover
+		if !f.pS.IsLevelHealthy(i) {
+			continue
+		}
 		consumer := f.getConsumerAtIndex(i)
 		err := consumer.ConsumeMetrics(ctx, md)
 		if err == nil {
@@ -70,7 +74,6 @@ func (f *metricsRouter) sampleRetryConsumers(ctx context.Context, md pmetric.Met
 }
 
 type metricsFailover struct {
-	component.StartFunc
 	component.ShutdownFunc
 
 	config   *Config
@@ -87,6 +90,15 @@ func (f *metricsFailover) ConsumeMetrics(ctx context.Context, md pmetric.Metrics
 	return f.failover.Consume(ctx, md)
 }
 
+// Start subscribes to the status of the pipelines when a status source is configured, and watches the
+// saturation of the sending queues of the exporters when they are configured
+func (f *metricsFailover) Start(ctx context.Context, host component.Host) error {
+	if err := f.failover.startHealthWatch(host); err != nil {
+		return err
+	}
+	return f.failover.startSaturationWatch(ctx, f.logger)
+}
+
 func (f *metricsFailover) Shutdown(_ context.Context) error {
 	if f.failover != nil {
 		f.failover.Shutdown()
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package failoverconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+)
+
+const (
+	queueSizeMetric     = "otelcol_exporter_queue_size"
+	queueCapacityMetric = "otelcol_exporter_queue_capacity"
+)
+
+// queueKey identifies the sending queue of an exporter for a data type, the data type is empty
+// when the internal telemetry doesn't report it
+type queueKey struct {
+	exporter string
+	dataType string
+}
+
+// startSaturationWatch reads the size of the sending queues of the exporters periodically, so the connector
+// fails over from a level as soon as the queue of one of its exporters is saturated, before the exporter
+// rejects the data, and probes the level again once the queues drain. It fails when the size of the queues
+// can't be read, as the Prometheus endpoint of the internal telemetry is likely not configured.
+func (f *baseFailoverRouter[C]) startSaturationWatch(ctx context.Context, logger *zap.Logger) error {
+	cfg := f.cfg.QueueSaturation
+	if len(cfg.Exporters) == 0 {
+		return nil
+	}
+
+	client := &http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		Timeout:   cfg.Timeout,
+	}
+	if _, err := readQueueRatios(ctx, client, cfg.Endpoint); err != nil {
+		client.CloseIdleConnections()
+		return fmt.Errorf("failed to read the size of the sending queues from %q, the Prometheus endpoint of the internal telemetry must be enabled: %w", cfg.Endpoint, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.watchers.Add(2)
+	go func() {
+		defer f.watchers.Done()
+		defer client.CloseIdleConnections()
+
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			f.checkSaturation(ctx, client, logger)
+			select {
+			case <-ticker.C:
+			case <-f.done:
+				return
+			}
+		}
+	}()
+	// The pending read is canceled on shutdown
+	go func() {
+		defer f.watchers.Done()
+		<-f.done
+		cancel()
+	}()
+	return nil
+}
+
+// checkSaturation records the saturation of every pipeline with watched exporters. The saturation is left
+// unchanged when the size of the queues can't be read.
+func (f *baseFailoverRouter[C]) checkSaturation(ctx context.Context, client *http.Client, logger *zap.Logger) {
+	cfg := f.cfg.QueueSaturation
+	ratios, err := readQueueRatios(ctx, client, cfg.Endpoint)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn("Failed to read the size of the sending queues", zap.String("endpoint", cfg.Endpoint), zap.Error(err))
+		}
+		return
+	}
+
+	for level, pipelines := range f.cfg.PipelinePriority {
+		for i, pipelineID := range pipelines {
+			exporters, ok := cfg.Exporters[pipelineID.String()]
+			if !ok {
+				continue
+			}
+			saturated := false
+			for _, exporterID := range exporters {
+				ratio, ok := ratios[queueKey{exporter: exporterID.String(), dataType: pipelineID.Signal().String()}]
+				if !ok {
+					ratio = ratios[queueKey{exporter: exporterID.String()}]
+				}
+				saturated = saturated || ratio >= cfg.Threshold
+			}
+			f.setPipelineSaturated(level, i, saturated)
+		}
+	}
+}
+
+// readQueueRatios reads the ratio of the size of the sending queues to their capacity from the Prometheus
+// endpoint of the internal telemetry of the collector.
+func readQueueRatios(ctx context.Context, client *http.Client, endpoint string) (map[queueKey]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	sizes := queueValues(families[queueSizeMetric])
+	capacities := queueValues(families[queueCapacityMetric])
+	ratios := make(map[queueKey]float64, len(sizes))
+	for key, size := range sizes {
+		if capacity := capacities[key]; capacity > 0 {
+			ratios[key] = size / capacity
+		}
+	}
+	return ratios, nil
+}
+
+func queueValues(family *dto.MetricFamily) map[queueKey]float64 {
+	values := map[queueKey]float64{}
+	for _, metric := range family.GetMetric() {
+		var key queueKey
+		for _, label := range metric.GetLabel() {
+			switch label.GetName() {
+			case "exporter":
+				key.exporter = label.GetValue()
+			case "data_type":
+				key.dataType = label.GetValue()
+			}
+		}
+		values[key] = max(values[key], metric.GetGauge().GetValue())
+	}
+	return values
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package failoverconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/failoverconnector/internal/metadata"
+)
+
+// queueMetrics returns the internal telemetry of the collector with the size of the sending queue of otlp/first
+func queueMetrics(size int) string {
+	return fmt.Sprintf(`# HELP otelcol_exporter_queue_capacity Fixed capacity of the retry queue (in batches)
+# TYPE otelcol_exporter_queue_capacity gauge
+otelcol_exporter_queue_capacity{data_type="traces",exporter="otlp/first"} 10
+otelcol_exporter_queue_capacity{data_type="logs",exporter="otlp/first"} 10
+# HELP otelcol_exporter_queue_size Current size of the retry queue (in batches)
+# TYPE otelcol_exporter_queue_size gauge
+otelcol_exporter_queue_size{data_type="traces",exporter="otlp/first"} %d
+otelcol_exporter_queue_size{data_type="logs",exporter="otlp/first"} 10
+`, size)
+}
+
+func TestQueueSaturationFailover(t *testing.T) {
+	var queueSize atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, queueMetrics(int(queueSize.Load())))
+	}))
+	defer server.Close()
+
+	var sinkFirst, sinkSecond consumertest.TracesSink
+	tracesFirst := pipeline.NewIDWithName(pipeline.SignalTraces, "first")
+	tracesSecond := pipeline.NewIDWithName(pipeline.SignalTraces, "second")
+
+	cfg := &Config{
+		PipelinePriority: [][]pipeline.ID{{tracesFirst}, {tracesSecond}},
+		RetryInterval:    time.Hour,
+		QueueSaturation: QueueSaturationConfig{
+			Endpoint:  server.URL,
+			Exporters: map[string][]component.ID{tracesFirst.String(): {component.MustNewIDWithName("otlp", "first")}},
+			Threshold: 0.8,
+			Interval:  5 * time.Millisecond,
+			Timeout:   time.Second,
+		},
+	}
+
+	router := connector.NewTracesRouter(map[pipeline.ID]consumer.Traces{
+		tracesFirst:  &sinkFirst,
+		tracesSecond: &sinkSecond,
+	})
+
+	conn, err := NewFactory().CreateTracesToTraces(context.Background(),
+		connectortest.NewNopSettings(metadata.Type), cfg, router.(consumer.Traces))
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(context.Background(), componenttest.NewNopHost()))
+
+	failoverConnector := conn.(*tracesFailover)
+	tr := sampleTrace()
+
+	defer func() {
+		assert.NoError(t, failoverConnector.Shutdown(context.Background()))
+	}()
+
+	// The saturated queue of the logs of the exporter doesn't affect the traces pipeline
+	require.NoError(t, conn.ConsumeTraces(context.Background(), tr))
+	assert.Equal(t, 1, sinkFirst.SpanCount())
+	assert.Equal(t, 0, failoverConnector.failover.TestGetCurrentConsumerIndex())
+
+	// The connector fails over once the queue is saturated, before the exporter rejects the data
+	queueSize.Store(9)
+	require.Eventually(t, func() bool {
+		return failoverConnector.failover.TestGetCurrentConsumerIndex() == 1
+	}, 3*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, conn.ConsumeTraces(context.Background(), tr))
+	assert.Equal(t, 1, sinkFirst.SpanCount())
+	assert.Equal(t, 1, sinkSecond.SpanCount())
+
+	// The level is probed again with the next data once the queue drains
+	queueSize.Store(2)
+	require.Eventually(t, func() bool {
+		return consumeTracesAndCheckStable(failoverConnector, 0, tr)
+	}, 3*time.Second, 5*time.Millisecond)
+	assert.Greater(t, sinkFirst.SpanCount(), 1)
+}
+
+func TestQueueSaturationEndpointNotConfigured(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tracesFirst := pipeline.NewIDWithName(pipeline.SignalTraces, "first")
+	cfg := &Config{
+		PipelinePriority: [][]pipeline.ID{{tracesFirst}},
+		RetryInterval:    time.Hour,
+		QueueSaturation: QueueSaturationConfig{
+			Endpoint:  server.URL + "/metrics",
+			Exporters: map[string][]component.ID{tracesFirst.String(): {component.MustNewIDWithName("otlp", "first")}},
+			Threshold: 0.8,
+			Interval:  time.Minute,
+			Timeout:   time.Second,
+		},
+	}
+
+	router := connector.NewTracesRouter(map[pipeline.ID]consumer.Traces{
+		tracesFirst: &consumertest.TracesSink{},
+	})
+
+	conn, err := NewFactory().CreateTracesToTraces(context.Background(),
+		connectortest.NewNopSettings(metadata.Type), cfg, router.(consumer.Traces))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, conn.Shutdown(context.Background()))
+	}()
+
+	err = conn.Start(context.Background(), componenttest.NewNopHost())
+	assert.ErrorContains(t, err, "the Prometheus endpoint of the internal telemetry must be enabled")
+}
+
+func TestReadQueueRatios(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, queueMetrics(4))
+	}))
+	defer server.Close()
+
+	ratios, err := readQueueRatios(context.Background(), server.Client(), server.URL+"/metrics")
+	require.NoError(t, err)
+	assert.Equal(t, map[queueKey]float64{
+		{exporter: "otlp/first", dataType: "traces"}: 0.4,
+		{exporter: "otlp/first", dataType: "logs"}:   1,
+	}, ratios)
+
+	_, err = readQueueRatios(context.Background(), server.Client(), server.URL)
+	assert.EqualError(t, err, `unexpected status "404 Not Found"`)
+}
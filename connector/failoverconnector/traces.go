@@ -60,6 +60,10 @@ func (f *tracesRouter) consumeByHealthyPipeline(ctx context.Context, td ptrace.T
 func (f *tracesRouter) sampleRetryConsumers(ctx context.Context, td ptrace.Traces) bool {
 	stableIndex := f.pS.CurrentPipeline()
 	for i := 0; i < stableIndex; i++ {
+		// the levels reported unhealthy by the status source are probed once they recover
+		if !f.pS.IsLevelHealthy(i) {
+			continue
+		}
 		consumer := f.getConsumerAtIndex(i)
 		err := consumer.ConsumeTraces(ctx, td)
 		if err == nil {
@@ -71,7 +75,6 @@ func (f *tracesRouter) sampleRetryConsumers(ctx context.Context, td ptrace.Trace
 }
 
 type tracesFailover struct {
-	component.StartFunc
 	component.ShutdownFunc
 
 	config   *Config
@@ -88,6 +91,15 @@ func (f *tracesFailover) ConsumeTraces(ctx context.Context, td ptrace.Traces) er
 	return f.failover.Consume(ctx, td)
 }
 
+// Start subscribes to the status of the pipelines when a status source is configured, and watches the
+// saturation of the sending queues of the exporters when they are configured
+func (f *tracesFailover) Start(ctx context.Context, host component.Host) error {
+	if err := f.failover.startHealthWatch(host); err != nil {
+		return err
+	}
+	return f.failover.startSaturationWatch(ctx, f.logger)
+}
+
 func (f *tracesFailover) Shutdown(_ context.Context) error {
 	if f.failover != nil {
 		f.failover.Shutdown()
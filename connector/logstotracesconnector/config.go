@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logstotracesconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+	"go.uber.org/zap"
+)
+
+var spanKinds = map[string]struct{}{
+	"internal": {},
+	"server":   {},
+	"client":   {},
+	"producer": {},
+	"consumer": {},
+}
+
+// Config defines the configuration options for the logstotraces connector.
+// All the expressions are OTTL value expressions or conditions evaluated in the log context.
+type Config struct {
+	// CorrelationKey is the expression correlating the log records of a same operation, for example
+	// `attributes["request.id"]`. The log records for which it evaluates to nil or an empty string are
+	// ignored. A span is synthesized for each correlation key and resource.
+	CorrelationKey string `mapstructure:"correlation_key"`
+	// Conditions select the log records taken into account. A log record is selected when any of the
+	// conditions is met. All the log records are selected when there are no conditions.
+	Conditions []string `mapstructure:"conditions"`
+	// TraceID is the expression of the hex encoded trace ID of the span, for example `trace_id.string`.
+	// When it's not set, or doesn't evaluate to a valid trace ID, the trace ID is derived from the
+	// correlation key, so that the spans of the different services logging the same key share a trace.
+	TraceID string `mapstructure:"trace_id"`
+	// SpanName is the expression of the name of the span. It's evaluated on the first log record of
+	// the span, and the span is named after the connector when it's not set.
+	SpanName string `mapstructure:"span_name"`
+	// SpanKind is the kind of the synthesized spans: internal, server, client, producer or consumer.
+	SpanKind string `mapstructure:"span_kind"`
+	// StartTime and EndTime are the expressions of the start and end times of the span. They can
+	// evaluate to a time, to unix nanoseconds or to an RFC 3339 string. By default, the span starts
+	// at the earliest log record and ends at the latest.
+	StartTime string `mapstructure:"start_time"`
+	EndTime   string `mapstructure:"end_time"`
+	// Duration is the expression of the duration of the span, used to compute its start time from its
+	// end time. It can evaluate to a duration, to nanoseconds or to a Go duration string.
+	Duration string `mapstructure:"duration"`
+	// EndCondition is the condition met by the last log record of a span. The span is emitted as
+	// soon as a log record meets it.
+	EndCondition string `mapstructure:"end_condition"`
+	// Timeout is the time after which a span is emitted when no log record was correlated to it.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxGroups is the maximum number of spans waiting for their log records. When it's reached,
+	// the oldest span is emitted right away.
+	MaxGroups int `mapstructure:"max_groups"`
+	// Attributes are the log record attributes copied to the span attributes. The first log record
+	// having the attribute sets it.
+	Attributes []string `mapstructure:"attributes"`
+}
+
+var _ xconfmap.Validator = (*Config)(nil)
+
+// Validate checks if the connector configuration is valid
+func (c Config) Validate() error {
+	if c.CorrelationKey == "" {
+		return errors.New("correlation_key must be set")
+	}
+	if _, ok := spanKinds[c.SpanKind]; !ok {
+		return fmt.Errorf("invalid span_kind %q", c.SpanKind)
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if c.MaxGroups <= 0 {
+		return errors.New("max_groups must be positive")
+	}
+	_, err := newExpressions(c, component.TelemetrySettings{Logger: zap.NewNop()})
+	return err
+}
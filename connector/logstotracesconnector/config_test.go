@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logstotracesconnector
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		id       component.ID
+		expected component.Config
+	}{
+		{
+			id: component.NewIDWithName(metadata.Type, "minimal"),
+			expected: &Config{
+				CorrelationKey: `attributes["request.id"]`,
+				SpanKind:       "server",
+				Timeout:        30 * time.Second,
+				MaxGroups:      10_000,
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: &Config{
+				CorrelationKey: `attributes["request.id"]`,
+				Conditions:     []string{`attributes["request.id"] != nil and severity_number >= SEVERITY_NUMBER_INFO`},
+				TraceID:        `attributes["trace.id"]`,
+				SpanName:       `attributes["http.route"]`,
+				SpanKind:       "internal",
+				StartTime:      `Time(attributes["request.start"], "%Y-%m-%dT%H:%M:%S%z")`,
+				EndTime:        "time",
+				Duration:       `Duration(attributes["request.duration"])`,
+				EndCondition:   `attributes["request.status"] != nil`,
+				Timeout:        time.Minute,
+				MaxGroups:      1000,
+				Attributes:     []string{"http.route", "user.id"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "correlation key",
+			modify:      func(cfg *Config) { cfg.CorrelationKey = "" },
+			expectedErr: "correlation_key must be set",
+		},
+		{
+			name:        "span kind",
+			modify:      func(cfg *Config) { cfg.SpanKind = "unspecified" },
+			expectedErr: `invalid span_kind "unspecified"`,
+		},
+		{
+			name:        "timeout",
+			modify:      func(cfg *Config) { cfg.Timeout = 0 },
+			expectedErr: "timeout must be positive",
+		},
+		{
+			name:        "max groups",
+			modify:      func(cfg *Config) { cfg.MaxGroups = 0 },
+			expectedErr: "max_groups must be positive",
+		},
+		{
+			name:        "invalid expression",
+			modify:      func(cfg *Config) { cfg.SpanName = `attributes["http.route"` },
+			expectedErr: "failed to parse span_name",
+		},
+		{
+			name:        "invalid condition",
+			modify:      func(cfg *Config) { cfg.EndCondition = "Unknown(body)" },
+			expectedErr: "failed to parse end_condition",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.CorrelationKey = `attributes["request.id"]`
+			tc.modify(cfg)
+			assert.ErrorContains(t, xconfmap.Validate(cfg), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logstotracesconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector"
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+const (
+	correlationKeyAttr = "log.correlation_key"
+	logRecordCountAttr = "log.record_count"
+
+	eventName         = "log"
+	eventBodyAttr     = "log.body"
+	eventSeverityAttr = "log.severity"
+
+	defaultSpanName = "logstotraces"
+)
+
+type connectorImp struct {
+	lock   sync.Mutex
+	logger *zap.Logger
+	config Config
+	exprs  *expressions
+	kind   ptrace.SpanKind
+	now    func() time.Time
+
+	tracesConsumer consumer.Traces
+
+	// groups are the spans waiting for their log records, ordered by the arrival of their last record.
+	groups map[[16]byte]*list.Element
+	order  *list.List
+	// sequence numbers the spans, so that a reused correlation key gets a new span ID.
+	sequence uint64
+
+	started      bool
+	done         chan struct{}
+	wg           sync.WaitGroup
+	shutdownOnce sync.Once
+}
+
+// group holds the span synthesized from the log records of a correlation key and resource.
+type group struct {
+	id       [16]byte
+	key      string
+	resource pcommon.Resource
+	span     ptrace.Span
+	records  int
+	// The start and end times of the span computed from the expressions, which take precedence over
+	// the timestamps of the log records.
+	start, end         pcommon.Timestamp
+	minTime, maxTime   pcommon.Timestamp
+	duration           time.Duration
+	hasTraceID, failed bool
+	expiration         time.Time
+}
+
+func newConnector(logger *zap.Logger, config *Config, now func() time.Time) (*connectorImp, error) {
+	exprs, err := newExpressions(*config, component.TelemetrySettings{Logger: logger})
+	if err != nil {
+		return nil, err
+	}
+	return &connectorImp{
+		logger: logger,
+		config: *config,
+		exprs:  exprs,
+		kind:   spanKind(config.SpanKind),
+		now:    now,
+		groups: make(map[[16]byte]*list.Element),
+		order:  list.New(),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+func spanKind(kind string) ptrace.SpanKind {
+	switch kind {
+	case "server":
+		return ptrace.SpanKindServer
+	case "client":
+		return ptrace.SpanKindClient
+	case "producer":
+		return ptrace.SpanKindProducer
+	case "consumer":
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindInternal
+	}
+}
+
+// Start implements the component.Component interface.
+func (c *connectorImp) Start(context.Context, component.Host) error {
+	c.started = true
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(min(c.config.Timeout, time.Second))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				c.export(context.Background(), c.expireGroups(false))
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown implements the component.Component interface. The spans still waiting for their log
+// records are emitted.
+func (c *connectorImp) Shutdown(ctx context.Context) error {
+	c.shutdownOnce.Do(func() {
+		if !c.started {
+			return
+		}
+		close(c.done)
+		c.wg.Wait()
+		c.export(ctx, c.expireGroups(true))
+	})
+	return nil
+}
+
+// Capabilities implements the consumer interface.
+func (c *connectorImp) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeLogs implements the consumer.Logs interface.
+// It correlates the log records to spans, and emits the spans completed by the log records.
+func (c *connectorImp) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
+	completed := c.addLogs(ctx, logs)
+	if completed.SpanCount() == 0 {
+		return nil
+	}
+	return c.tracesConsumer.ConsumeTraces(ctx, completed)
+}
+
+func (c *connectorImp) addLogs(ctx context.Context, logs plog.Logs) ptrace.Traces {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	completed := ptrace.NewTraces()
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		rlogs := logs.ResourceLogs().At(i)
+		for j := 0; j < rlogs.ScopeLogs().Len(); j++ {
+			slogs := rlogs.ScopeLogs().At(j)
+			for k := 0; k < slogs.LogRecords().Len(); k++ {
+				tCtx := ottllog.NewTransformContext(slogs.LogRecords().At(k), slogs.Scope(), rlogs.Resource(), slogs, rlogs)
+				c.addLogRecord(ctx, tCtx, completed)
+			}
+		}
+	}
+	return completed
+}
+
+func (c *connectorImp) addLogRecord(ctx context.Context, tCtx ottllog.TransformContext, completed ptrace.Traces) {
+	if c.exprs.conditions != nil {
+		match, err := c.exprs.conditions.Eval(ctx, tCtx)
+		if err != nil || !match {
+			return
+		}
+	}
+	v, err := c.exprs.correlationKey.Eval(ctx, tCtx)
+	if err != nil {
+		c.logger.Debug("Failed to evaluate the correlation key", zap.Error(err))
+		return
+	}
+	key := toString(v)
+	if key == "" {
+		return
+	}
+
+	g, isNew := c.getOrCreateGroup(key, tCtx.GetResource(), completed)
+	if isNew {
+		c.initGroup(ctx, g, tCtx)
+	}
+	c.addEvent(ctx, g, tCtx)
+
+	if c.exprs.endCondition != nil {
+		if done, err := c.exprs.endCondition.Eval(ctx, tCtx); err == nil && done {
+			c.finishGroup(c.removeGroup(c.groups[g.id]), completed)
+		}
+	}
+}
+
+// getOrCreateGroup returns the span waiting for the log records of a correlation key and resource,
+// or creates it. When the maximum number of spans is reached, the oldest span is emitted right away.
+func (c *connectorImp) getOrCreateGroup(key string, resource pcommon.Resource, completed ptrace.Traces) (*group, bool) {
+	id := pdatautil.Hash(pdatautil.WithMap(resource.Attributes()), pdatautil.WithString(key))
+	expiration := c.now().Add(c.config.Timeout)
+	if ele, ok := c.groups[id]; ok {
+		g := ele.Value.(*group)
+		g.expiration = expiration
+		c.order.MoveToBack(ele)
+		return g, false
+	}
+
+	if c.order.Len() >= c.config.MaxGroups {
+		c.logger.Debug("Too many spans waiting for their log records, emitting the oldest one early")
+		c.finishGroup(c.removeGroup(c.order.Front()), completed)
+	}
+
+	g := &group{
+		id:         id,
+		key:        key,
+		resource:   pcommon.NewResource(),
+		span:       ptrace.NewSpan(),
+		expiration: expiration,
+	}
+	resource.CopyTo(g.resource)
+	c.groups[id] = c.order.PushBack(g)
+	return g, true
+}
+
+func (c *connectorImp) removeGroup(ele *list.Element) *group {
+	g := c.order.Remove(ele).(*group)
+	delete(c.groups, g.id)
+	return g
+}
+
+// initGroup sets the identity of a span from its first log record.
+func (c *connectorImp) initGroup(ctx context.Context, g *group, tCtx ottllog.TransformContext) {
+	// The span ID is derived from the correlation key and resource, the timestamp of the first log record
+	// and a sequence number, so that a correlation key reused by another operation, even after a restart,
+	// gets a new span ID. The trace ID only depends on the correlation key.
+	c.sequence++
+	hash := pdatautil.Hash(
+		pdatautil.WithString(string(g.id[:])),
+		pdatautil.WithString(strconv.FormatUint(uint64(recordTimestamp(tCtx.GetLogRecord())), 10)),
+		pdatautil.WithString(strconv.FormatUint(c.sequence, 10)),
+	)
+	var spanID pcommon.SpanID
+	copy(spanID[:], hash[:])
+	g.span.SetSpanID(spanID)
+	g.span.SetTraceID(pdatautil.Hash(pdatautil.WithString(g.key)))
+	g.span.SetKind(c.kind)
+	g.span.Attributes().PutStr(correlationKeyAttr, g.key)
+
+	name := defaultSpanName
+	if v, err := eval(ctx, c.exprs.spanName, tCtx); err == nil && toString(v) != "" {
+		name = toString(v)
+	}
+	g.span.SetName(name)
+}
+
+// recordTimestamp returns the timestamp of a log record, or its observed timestamp when it isn't set.
+func recordTimestamp(record plog.LogRecord) pcommon.Timestamp {
+	if ts := record.Timestamp(); ts != 0 {
+		return ts
+	}
+	return record.ObservedTimestamp()
+}
+
+// addEvent attaches a log record to its span, and updates the span from the log record.
+func (c *connectorImp) addEvent(ctx context.Context, g *group, tCtx ottllog.TransformContext) {
+	record := tCtx.GetLogRecord()
+	ts := recordTimestamp(record)
+	if g.records == 0 || ts < g.minTime {
+		g.minTime = ts
+	}
+	g.maxTime = max(g.maxTime, ts)
+	g.records++
+
+	if !g.hasTraceID {
+		if v, err := eval(ctx, c.exprs.traceID, tCtx); err == nil {
+			if traceID, ok := toTraceID(v); ok {
+				g.span.SetTraceID(traceID)
+				g.hasTraceID = true
+			}
+		}
+	}
+	if v, err := eval(ctx, c.exprs.startTime, tCtx); err == nil && v != nil {
+		if start, err := toTimestamp(v); err == nil && (g.start == 0 || start < g.start) {
+			g.start = start
+		}
+	}
+	if v, err := eval(ctx, c.exprs.endTime, tCtx); err == nil && v != nil {
+		if end, err := toTimestamp(v); err == nil {
+			g.end = max(g.end, end)
+		}
+	}
+	if v, err := eval(ctx, c.exprs.duration, tCtx); err == nil && v != nil {
+		if d, err := toDuration(v); err == nil {
+			g.duration = max(g.duration, d)
+		}
+	}
+
+	for _, name := range c.config.Attributes {
+		if _, ok := g.span.Attributes().Get(name); ok {
+			continue
+		}
+		if v, ok := record.Attributes().Get(name); ok {
+			v.CopyTo(g.span.Attributes().PutEmpty(name))
+		}
+	}
+
+	if !g.failed && record.SeverityNumber() >= plog.SeverityNumberError {
+		g.failed = true
+		g.span.Status().SetCode(ptrace.StatusCodeError)
+		g.span.Status().SetMessage(record.Body().AsString())
+	}
+
+	event := g.span.Events().AppendEmpty()
+	event.SetName(eventName)
+	event.SetTimestamp(ts)
+	record.Attributes().CopyTo(event.Attributes())
+	event.Attributes().PutStr(eventBodyAttr, record.Body().AsString())
+	severity := record.SeverityText()
+	if severity == "" {
+		severity = record.SeverityNumber().String()
+	}
+	event.Attributes().PutStr(eventSeverityAttr, severity)
+}
+
+// finishGroup sets the timestamps of a span and appends it to the completed spans.
+func (c *connectorImp) finishGroup(g *group, completed ptrace.Traces) {
+	end := g.maxTime
+	if g.end != 0 {
+		end = g.end
+	}
+	start := g.minTime
+	switch {
+	case g.start != 0:
+		start = g.start
+	case g.duration > 0:
+		start = end - pcommon.Timestamp(g.duration)
+	}
+	g.span.SetStartTimestamp(start)
+	g.span.SetEndTimestamp(max(start, end))
+	g.span.Attributes().PutInt(logRecordCountAttr, int64(g.records))
+
+	rs := completed.ResourceSpans().AppendEmpty()
+	g.resource.CopyTo(rs.Resource())
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().SetName(metadata.ScopeName)
+	g.span.MoveTo(ss.Spans().AppendEmpty())
+}
+
+// expireGroups returns the spans which didn't get log records during the timeout, or all of them.
+func (c *connectorImp) expireGroups(all bool) ptrace.Traces {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	completed := ptrace.NewTraces()
+	now := c.now()
+	for ele := c.order.Front(); ele != nil; ele = c.order.Front() {
+		if !all && now.Before(ele.Value.(*group).expiration) {
+			break
+		}
+		c.finishGroup(c.removeGroup(ele), completed)
+	}
+	return completed
+}
+
+func (c *connectorImp) export(ctx context.Context, traces ptrace.Traces) {
+	if traces.SpanCount() == 0 {
+		return
+	}
+	if err := c.tracesConsumer.ConsumeTraces(ctx, traces); err != nil {
+		c.logger.Error("Failed ConsumeTraces", zap.Error(err))
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logstotracesconnector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector/internal/metadata"
+)
+
+type testRecord struct {
+	requestID string
+	body      string
+	severity  plog.SeverityNumber
+	ts        time.Duration
+	attrs     map[string]string
+}
+
+// buildLogs builds the log records of a service, with their timestamps relative to the unix epoch.
+func buildLogs(serviceName string, records ...testRecord) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", serviceName)
+	sl := rl.ScopeLogs().AppendEmpty()
+	for _, r := range records {
+		record := sl.LogRecords().AppendEmpty()
+		record.SetTimestamp(pcommon.Timestamp(r.ts))
+		record.Body().SetStr(r.body)
+		record.SetSeverityNumber(r.severity)
+		if r.requestID != "" {
+			record.Attributes().PutStr("request.id", r.requestID)
+		}
+		for k, v := range r.attrs {
+			record.Attributes().PutStr(k, v)
+		}
+	}
+	return logs
+}
+
+type testConnector struct {
+	*connectorImp
+	sink *consumertest.TracesSink
+	now  time.Time
+}
+
+func newTestConnector(t *testing.T, modify func(cfg *Config)) *testConnector {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CorrelationKey = `attributes["request.id"]`
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+	tc := &testConnector{sink: new(consumertest.TracesSink), now: time.Unix(1000, 0)}
+	c, err := newConnector(zaptest.NewLogger(t), cfg, func() time.Time { return tc.now })
+	require.NoError(t, err)
+	tc.connectorImp = c
+	tc.tracesConsumer = tc.sink
+	return tc
+}
+
+// spans returns the spans emitted by the connector, by correlation key.
+func (tc *testConnector) spans(t *testing.T) map[string]ptrace.Span {
+	spans := make(map[string]ptrace.Span)
+	for _, traces := range tc.sink.AllTraces() {
+		for i := 0; i < traces.ResourceSpans().Len(); i++ {
+			rs := traces.ResourceSpans().At(i)
+			require.Equal(t, 1, rs.ScopeSpans().Len())
+			assert.Equal(t, metadata.ScopeName, rs.ScopeSpans().At(0).Scope().Name())
+			ss := rs.ScopeSpans().At(0).Spans()
+			for j := 0; j < ss.Len(); j++ {
+				key, _ := ss.At(j).Attributes().Get(correlationKeyAttr)
+				spans[key.Str()] = ss.At(j)
+			}
+		}
+	}
+	return spans
+}
+
+func TestConnectorSynthesizesSpans(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.SpanName = `attributes["http.route"]`
+		cfg.Attributes = []string{"http.route"}
+	})
+	ms := time.Millisecond
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("checkout",
+		testRecord{requestID: "req-1", body: "request received", severity: plog.SeverityNumberInfo, ts: 10 * ms, attrs: map[string]string{"http.route": "/checkout"}},
+		testRecord{requestID: "req-2", body: "request received", severity: plog.SeverityNumberInfo, ts: 20 * ms},
+		testRecord{body: "uncorrelated", severity: plog.SeverityNumberInfo, ts: 30 * ms},
+	)))
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("checkout",
+		testRecord{requestID: "req-1", body: "payment failed", severity: plog.SeverityNumberError, ts: 50 * ms},
+		testRecord{requestID: "req-1", body: "request done", severity: plog.SeverityNumberInfo, ts: 40 * ms},
+	)))
+
+	// The spans are waiting for their log records
+	c.export(context.Background(), c.expireGroups(false))
+	assert.Empty(t, c.sink.AllTraces())
+
+	c.now = c.now.Add(c.config.Timeout)
+	c.export(context.Background(), c.expireGroups(false))
+	spans := c.spans(t)
+	require.Len(t, spans, 2)
+
+	span := spans["req-1"]
+	assert.Equal(t, "/checkout", span.Name())
+	assert.Equal(t, ptrace.SpanKindServer, span.Kind())
+	assert.Equal(t, pcommon.Timestamp(10*ms), span.StartTimestamp())
+	assert.Equal(t, pcommon.Timestamp(50*ms), span.EndTimestamp())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "payment failed", span.Status().Message())
+	assert.Equal(t, map[string]any{
+		correlationKeyAttr: "req-1",
+		logRecordCountAttr: int64(3),
+		"http.route":       "/checkout",
+	}, span.Attributes().AsRaw())
+
+	require.Equal(t, 3, span.Events().Len())
+	event := span.Events().At(1)
+	assert.Equal(t, eventName, event.Name())
+	assert.Equal(t, pcommon.Timestamp(50*ms), event.Timestamp())
+	assert.Equal(t, map[string]any{
+		"request.id":      "req-1",
+		eventBodyAttr:     "payment failed",
+		eventSeverityAttr: "Error",
+	}, event.Attributes().AsRaw())
+
+	other := spans["req-2"]
+	assert.Equal(t, defaultSpanName, other.Name())
+	assert.Equal(t, ptrace.StatusCodeUnset, other.Status().Code())
+	assert.NotEqual(t, span.TraceID(), other.TraceID())
+	assert.Empty(t, c.groups)
+}
+
+func TestConnectorIDs(t *testing.T) {
+	c := newTestConnector(t, nil)
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("frontend", testRecord{requestID: "req-1"})))
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("backend", testRecord{requestID: "req-1"})))
+	c.export(context.Background(), c.expireGroups(true))
+
+	// The spans of the services logging the same key share a trace
+	traces := c.sink.AllTraces()
+	require.Len(t, traces, 1)
+	require.Equal(t, 2, traces[0].SpanCount())
+	frontend := traces[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	backend := traces[0].ResourceSpans().At(1).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, frontend.TraceID(), backend.TraceID())
+	assert.NotEqual(t, frontend.SpanID(), backend.SpanID())
+	assert.False(t, frontend.TraceID().IsEmpty())
+	assert.False(t, frontend.SpanID().IsEmpty())
+	serviceName, _ := traces[0].ResourceSpans().At(1).Resource().Attributes().Get("service.name")
+	assert.Equal(t, "backend", serviceName.Str())
+}
+
+func TestConnectorReusedKey(t *testing.T) {
+	c := newTestConnector(t, nil)
+	spanOf := func(traces ptrace.Traces) ptrace.Span {
+		require.Equal(t, 1, traces.SpanCount())
+		return traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	}
+
+	// The key is reused by another operation once the span of the first one is emitted
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("frontend", testRecord{requestID: "req-1", ts: time.Millisecond})))
+	c.export(context.Background(), c.expireGroups(true))
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("frontend", testRecord{requestID: "req-1", ts: time.Millisecond})))
+	c.export(context.Background(), c.expireGroups(true))
+
+	traces := c.sink.AllTraces()
+	require.Len(t, traces, 2)
+	first, second := spanOf(traces[0]), spanOf(traces[1])
+	assert.Equal(t, first.TraceID(), second.TraceID())
+	assert.NotEqual(t, first.SpanID(), second.SpanID())
+	assert.False(t, second.SpanID().IsEmpty())
+}
+
+func TestConnectorExpressions(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.Conditions = []string{`severity_number >= SEVERITY_NUMBER_INFO`}
+		cfg.TraceID = `attributes["trace.id"]`
+		cfg.SpanKind = "consumer"
+		cfg.EndTime = `attributes["end"]`
+		cfg.Duration = `Duration(attributes["duration"])`
+		cfg.EndCondition = `attributes["duration"] != nil`
+	})
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("worker",
+		testRecord{requestID: "job-1", body: "debug", severity: plog.SeverityNumberDebug},
+		testRecord{requestID: "job-1", body: "started", severity: plog.SeverityNumberInfo, attrs: map[string]string{"trace.id": "invalid"}},
+	)))
+	assert.Empty(t, c.sink.AllTraces())
+
+	// The span is emitted as soon as its last log record is received
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("worker",
+		testRecord{requestID: "job-1", body: "done", severity: plog.SeverityNumberInfo, attrs: map[string]string{
+			"trace.id": traceID.String(),
+			"end":      "1970-01-01T00:00:02Z",
+			"duration": "500ms",
+		}},
+	)))
+	spans := c.spans(t)
+	require.Len(t, spans, 1)
+	span := spans["job-1"]
+	assert.Equal(t, traceID, span.TraceID())
+	assert.Equal(t, ptrace.SpanKindConsumer, span.Kind())
+	assert.Equal(t, pcommon.Timestamp(1500*time.Millisecond), span.StartTimestamp())
+	assert.Equal(t, pcommon.Timestamp(2*time.Second), span.EndTimestamp())
+	// The debug log record isn't selected by the conditions
+	assert.Equal(t, 2, span.Events().Len())
+	assert.Empty(t, c.groups)
+}
+
+func TestConnectorMaxGroups(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.MaxGroups = 1
+	})
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("checkout", testRecord{requestID: "req-1"})))
+	// The first span is emitted early to make room for the second one
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("checkout", testRecord{requestID: "req-2"})))
+	assert.Len(t, c.groups, 1)
+
+	spans := c.spans(t)
+	require.Len(t, spans, 1)
+	assert.Contains(t, spans, "req-1")
+}
+
+func TestConnectorShutdown(t *testing.T) {
+	c := newTestConnector(t, nil)
+	require.NoError(t, c.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, c.ConsumeLogs(context.Background(), buildLogs("checkout", testRecord{requestID: "req-1"})))
+
+	// The spans waiting for their log records are emitted on shutdown
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Len(t, c.spans(t), 1)
+}
@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logstotracesconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector"
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+)
+
+// expressions holds the parsed OTTL expressions of the configuration. The optional ones are nil when
+// they aren't configured.
+type expressions struct {
+	correlationKey *ottl.ValueExpression[ottllog.TransformContext]
+	conditions     *ottl.ConditionSequence[ottllog.TransformContext]
+	traceID        *ottl.ValueExpression[ottllog.TransformContext]
+	spanName       *ottl.ValueExpression[ottllog.TransformContext]
+	startTime      *ottl.ValueExpression[ottllog.TransformContext]
+	endTime        *ottl.ValueExpression[ottllog.TransformContext]
+	duration       *ottl.ValueExpression[ottllog.TransformContext]
+	endCondition   *ottl.ConditionSequence[ottllog.TransformContext]
+}
+
+func newExpressions(cfg Config, set component.TelemetrySettings) (*expressions, error) {
+	parser, err := ottllog.NewParser(ottlfuncs.StandardConverters[ottllog.TransformContext](), set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser for OTTL logs: %w", err)
+	}
+
+	exprs := &expressions{}
+	for _, field := range []struct {
+		name string
+		raw  string
+		expr **ottl.ValueExpression[ottllog.TransformContext]
+	}{
+		{name: "correlation_key", raw: cfg.CorrelationKey, expr: &exprs.correlationKey},
+		{name: "trace_id", raw: cfg.TraceID, expr: &exprs.traceID},
+		{name: "span_name", raw: cfg.SpanName, expr: &exprs.spanName},
+		{name: "start_time", raw: cfg.StartTime, expr: &exprs.startTime},
+		{name: "end_time", raw: cfg.EndTime, expr: &exprs.endTime},
+		{name: "duration", raw: cfg.Duration, expr: &exprs.duration},
+	} {
+		if field.raw == "" {
+			continue
+		}
+		if *field.expr, err = parser.ParseValueExpression(field.raw); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field.name, err)
+		}
+	}
+
+	if len(cfg.Conditions) > 0 {
+		if exprs.conditions, err = newConditionSequence(parser, cfg.Conditions, set); err != nil {
+			return nil, fmt.Errorf("failed to parse conditions: %w", err)
+		}
+	}
+	if cfg.EndCondition != "" {
+		if exprs.endCondition, err = newConditionSequence(parser, []string{cfg.EndCondition}, set); err != nil {
+			return nil, fmt.Errorf("failed to parse end_condition: %w", err)
+		}
+	}
+	return exprs, nil
+}
+
+func newConditionSequence(parser ottl.Parser[ottllog.TransformContext], raw []string, set component.TelemetrySettings) (*ottl.ConditionSequence[ottllog.TransformContext], error) {
+	conditions, err := parser.ParseConditions(raw)
+	if err != nil {
+		return nil, err
+	}
+	condSeq := ottl.NewConditionSequence(
+		conditions,
+		set,
+		ottl.WithLogicOperation[ottllog.TransformContext](ottl.Or),
+	)
+	return &condSeq, nil
+}
+
+// eval evaluates an optional expression. It returns nil when the expression isn't configured.
+func eval(ctx context.Context, expr *ottl.ValueExpression[ottllog.TransformContext], tCtx ottllog.TransformContext) (any, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	return expr.Eval(ctx, tCtx)
+}
+
+// toString converts the value of an expression to a string. Nil values are converted to an empty string.
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case pcommon.Value:
+		return v.AsString()
+	case []byte:
+		return hex.EncodeToString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toTimestamp converts the value of a time expression: a time, unix nanoseconds or an RFC 3339 string.
+func toTimestamp(v any) (pcommon.Timestamp, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return pcommon.NewTimestampFromTime(v), nil
+	case int64:
+		return pcommon.Timestamp(v), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, err
+		}
+		return pcommon.NewTimestampFromTime(t), nil
+	case pcommon.Value:
+		return toTimestamp(v.AsRaw())
+	default:
+		return 0, fmt.Errorf("unsupported time value of type %T", v)
+	}
+}
+
+// toDuration converts the value of a duration expression: a duration, nanoseconds or a Go duration string.
+func toDuration(v any) (time.Duration, error) {
+	switch v := v.(type) {
+	case time.Duration:
+		return v, nil
+	case int64:
+		return time.Duration(v), nil
+	case string:
+		return time.ParseDuration(v)
+	case pcommon.Value:
+		return toDuration(v.AsRaw())
+	default:
+		return 0, fmt.Errorf("unsupported duration value of type %T", v)
+	}
+}
+
+// toTraceID converts the value of a trace ID expression, a hex encoded trace ID. It returns false when
+// the value isn't a valid trace ID.
+func toTraceID(v any) (pcommon.TraceID, bool) {
+	if traceID, ok := v.(pcommon.TraceID); ok {
+		return traceID, !traceID.IsEmpty()
+	}
+	var traceID pcommon.TraceID
+	s := toString(v)
+	if len(s) != hex.EncodedLen(len(traceID)) {
+		return traceID, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(s)); err != nil {
+		return traceID, false
+	}
+	return traceID, !traceID.IsEmpty()
+}
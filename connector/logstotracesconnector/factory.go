@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+package logstotracesconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/logstotracesconnector/internal/metadata"
+)
+
+// NewFactory creates a factory for the logstotraces connector.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithLogsToTraces(createLogsToTracesConnector, metadata.LogsToTracesStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		SpanKind:  "server",
+		Timeout:   30 * time.Second,
+		MaxGroups: 10_000,
+	}
+}
+
+func createLogsToTracesConnector(_ context.Context, params connector.Settings, cfg component.Config, nextConsumer consumer.Traces) (connector.Logs, error) {
+	c, err := newConnector(params.Logger, cfg.(*Config), time.Now)
+	if err != nil {
+		return nil, err
+	}
+	c.tracesConsumer = nextConsumer
+	return c, nil
+}
@@ -5,6 +5,8 @@ package servicegraphconnector // import "github.com/open-telemetry/opentelemetry
 
 import (
 	"time"
+
+	"go.opentelemetry.io/collector/component"
 )
 
 // Config defines the configuration options for servicegraphprocessor.
@@ -49,6 +51,10 @@ type Config struct {
 	// DatabaseNameAttribute is the attribute name used to identify the database name from span attributes.
 	// The default value is db.name.
 	DatabaseNameAttribute string `mapstructure:"database_name_attribute"`
+
+	// EnableMessagingSystemLatencyHistogram enables the histogram of the time the messages spend in
+	// the messaging systems, between the end of the producer span and the start of the consumer span.
+	EnableMessagingSystemLatencyHistogram bool `mapstructure:"enable_messaging_system_latency_histogram"`
 }
 
 type StoreConfig struct {
@@ -56,4 +62,7 @@ type StoreConfig struct {
 	MaxItems int `mapstructure:"max_items"`
 	// TTL is the time to live for items in the store.
 	TTL time.Duration `mapstructure:"ttl"`
+	// StorageID is the optional storage extension keeping the items, instead of memory. A storage
+	// shared by several collector instances, such as redis, pairs spans received by different instances.
+	StorageID *component.ID `mapstructure:"storage"`
 }
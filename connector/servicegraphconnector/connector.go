@@ -15,6 +15,7 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	extensionstorage "go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -59,11 +60,19 @@ type metricSeries struct {
 var _ processor.Traces = (*serviceGraphConnector)(nil)
 
 type serviceGraphConnector struct {
+	id              component.ID
 	config          *Config
 	logger          *zap.Logger
 	metricsConsumer consumer.Metrics
+	// tracesConsumer receives the spans of the edges that expire unpaired, when the
+	// connector is used in a traces pipeline instead of a metrics pipeline.
+	tracesConsumer consumer.Traces
 
-	store *store.Store
+	store         store.EdgeStore
+	storageClient extensionstorage.Client
+
+	unpairedMutex sync.Mutex
+	unpaired      ptrace.Traces
 
 	startTime time.Time
 
@@ -78,6 +87,10 @@ type serviceGraphConnector struct {
 	reqServerDurationSecondsBucketCounts map[string][]uint64
 	reqDurationBounds                    []float64
 
+	reqMessagingSystemDurationSecondsCount        map[string]uint64
+	reqMessagingSystemDurationSecondsSum          map[string]float64
+	reqMessagingSystemDurationSecondsBucketCounts map[string][]uint64
+
 	metricMutex sync.RWMutex
 	keyToMetric map[string]metricSeries
 
@@ -127,24 +140,37 @@ func newConnector(set component.TelemetrySettings, config component.Config, next
 		logger:          set.Logger,
 		metricsConsumer: next,
 
-		startTime:                            time.Now(),
-		reqTotal:                             make(map[string]int64),
-		reqFailedTotal:                       make(map[string]int64),
-		reqClientDurationSecondsCount:        make(map[string]uint64),
-		reqClientDurationSecondsSum:          make(map[string]float64),
-		reqClientDurationSecondsBucketCounts: make(map[string][]uint64),
-		reqServerDurationSecondsCount:        make(map[string]uint64),
-		reqServerDurationSecondsSum:          make(map[string]float64),
-		reqServerDurationSecondsBucketCounts: make(map[string][]uint64),
-		reqDurationBounds:                    bounds,
-		keyToMetric:                          make(map[string]metricSeries),
-		shutdownCh:                           make(chan any),
-		telemetryBuilder:                     telemetryBuilder,
+		startTime:                                     time.Now(),
+		reqTotal:                                      make(map[string]int64),
+		reqFailedTotal:                                make(map[string]int64),
+		reqClientDurationSecondsCount:                 make(map[string]uint64),
+		reqClientDurationSecondsSum:                   make(map[string]float64),
+		reqClientDurationSecondsBucketCounts:          make(map[string][]uint64),
+		reqServerDurationSecondsCount:                 make(map[string]uint64),
+		reqServerDurationSecondsSum:                   make(map[string]float64),
+		reqServerDurationSecondsBucketCounts:          make(map[string][]uint64),
+		reqDurationBounds:                             bounds,
+		reqMessagingSystemDurationSecondsCount:        make(map[string]uint64),
+		reqMessagingSystemDurationSecondsSum:          make(map[string]float64),
+		reqMessagingSystemDurationSecondsBucketCounts: make(map[string][]uint64),
+		keyToMetric:                                   make(map[string]metricSeries),
+		unpaired:                                      ptrace.NewTraces(),
+		shutdownCh:                                    make(chan any),
+		telemetryBuilder:                              telemetryBuilder,
 	}, nil
 }
 
-func (p *serviceGraphConnector) Start(_ context.Context, _ component.Host) error {
-	p.store = store.NewStore(p.config.Store.TTL, p.config.Store.MaxItems, p.onComplete, p.onExpire)
+func (p *serviceGraphConnector) Start(ctx context.Context, host component.Host) error {
+	if p.config.Store.StorageID != nil {
+		client, err := p.getStorageClient(ctx, host)
+		if err != nil {
+			return err
+		}
+		p.storageClient = client
+		p.store = store.NewSharedStore(client, p.logger, p.config.Store.TTL, p.config.Store.MaxItems, p.onComplete, p.onExpire)
+	} else {
+		p.store = store.NewStore(p.config.Store.TTL, p.config.Store.MaxItems, p.onComplete, p.onExpire)
+	}
 
 	go p.metricFlushLoop(p.config.MetricsFlushInterval)
 
@@ -176,7 +202,32 @@ func (p *serviceGraphConnector) metricFlushLoop(flushInterval time.Duration) {
 	}
 }
 
+// getStorageClient returns the client of the storage extension sharing the store between
+// the collector instances. The connectors in metrics and traces pipelines use different
+// clients, as they pair the same spans.
+func (p *serviceGraphConnector) getStorageClient(ctx context.Context, host component.Host) (extensionstorage.Client, error) {
+	extension, ok := host.GetExtensions()[*p.config.Store.StorageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", p.config.Store.StorageID)
+	}
+
+	storageExtension, ok := extension.(extensionstorage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", p.config.Store.StorageID)
+	}
+
+	name := "metrics"
+	if p.tracesConsumer != nil {
+		name = "traces"
+	}
+	return storageExtension.GetClient(ctx, component.KindConnector, p.id, name)
+}
+
 func (p *serviceGraphConnector) flushMetrics(ctx context.Context) error {
+	if p.metricsConsumer == nil {
+		return nil
+	}
+
 	md, err := p.buildMetrics()
 	if err != nil {
 		return fmt.Errorf("failed to build metrics: %w", err)
@@ -191,9 +242,12 @@ func (p *serviceGraphConnector) flushMetrics(ctx context.Context) error {
 	return p.metricsConsumer.ConsumeMetrics(ctx, md)
 }
 
-func (p *serviceGraphConnector) Shutdown(_ context.Context) error {
+func (p *serviceGraphConnector) Shutdown(ctx context.Context) error {
 	p.logger.Info("Shutting down servicegraphconnector")
 	close(p.shutdownCh)
+	if p.storageClient != nil {
+		return p.storageClient.Close(ctx)
+	}
 	return nil
 }
 
@@ -218,11 +272,6 @@ func (p *serviceGraphConnector) ConsumeTraces(ctx context.Context, td ptrace.Tra
 }
 
 func (p *serviceGraphConnector) aggregateMetrics(ctx context.Context, td ptrace.Traces) (err error) {
-	var (
-		isNew             bool
-		totalDroppedSpans int
-	)
-
 	rss := td.ResourceSpans()
 	for i := 0; i < rss.Len(); i++ {
 		rSpans := rss.At(i)
@@ -251,18 +300,24 @@ func (p *serviceGraphConnector) aggregateMetrics(ctx context.Context, td ptrace.
 				case ptrace.SpanKindClient:
 					traceID := span.TraceID()
 					key := store.NewKey(traceID, span.SpanID())
-					isNew, err = p.store.UpsertEdge(key, func(e *store.Edge) {
+					err = p.upsertEdge(ctx, key, func(e *store.Edge) {
 						e.TraceID = traceID
 						e.ConnectionType = connectionType
 						e.ClientService = serviceName
 						e.ClientLatencySec = spanDuration(span)
 						e.Failed = e.Failed || span.Status().Code() == ptrace.StatusCodeError
 						p.upsertDimensions(clientKind, e.Dimensions, rAttributes, span.Attributes())
+						p.keepSpan(e, rSpans.Resource(), span)
 
 						if virtualNodeFeatureGate.IsEnabled() {
 							p.upsertPeerAttributes(p.config.VirtualNodePeerAttributes, e.Peer, span.Attributes())
 						}
 
+						if connectionType == store.MessagingSystem {
+							e.ClientEndTimestamp = span.EndTimestamp()
+							upsertDestination(e.Peer, span.Attributes())
+						}
+
 						// A database request will only have one span, we don't wait for the server
 						// span but just copy details from the client span
 						if dbName, ok := pdatautil.GetAttributeValue(p.config.DatabaseNameAttribute, rAttributes, span.Attributes()); ok {
@@ -276,41 +331,82 @@ func (p *serviceGraphConnector) aggregateMetrics(ctx context.Context, td ptrace.
 					connectionType = store.MessagingSystem
 					fallthrough
 				case ptrace.SpanKindServer:
-					traceID := span.TraceID()
-					key := store.NewKey(traceID, span.ParentSpanID())
-					isNew, err = p.store.UpsertEdge(key, func(e *store.Edge) {
-						e.TraceID = traceID
-						e.ConnectionType = connectionType
-						e.ServerService = serviceName
-						e.ServerLatencySec = spanDuration(span)
-						e.Failed = e.Failed || span.Status().Code() == ptrace.StatusCodeError
-						p.upsertDimensions(serverKind, e.Dimensions, rAttributes, span.Attributes())
-					})
+					for _, key := range serverEdgeKeys(span) {
+						err = p.upsertEdge(ctx, key, func(e *store.Edge) {
+							e.TraceID = key.TraceID()
+							e.ConnectionType = connectionType
+							e.ServerService = serviceName
+							e.ServerLatencySec = spanDuration(span)
+							e.Failed = e.Failed || span.Status().Code() == ptrace.StatusCodeError
+							p.upsertDimensions(serverKind, e.Dimensions, rAttributes, span.Attributes())
+							p.keepSpan(e, rSpans.Resource(), span)
+
+							if connectionType == store.MessagingSystem {
+								e.ServerStartTimestamp = span.StartTimestamp()
+								upsertDestination(e.Peer, span.Attributes())
+							}
+						})
+						if err != nil {
+							break
+						}
+					}
 				default:
 					// this span is not part of an edge
 					continue
 				}
 
-				if errors.Is(err, store.ErrTooManyItems) {
-					totalDroppedSpans++
-					p.telemetryBuilder.ConnectorServicegraphDroppedSpans.Add(ctx, 1)
-					continue
-				}
-
-				// UpsertEdge will only return ErrTooManyItems
 				if err != nil {
 					return err
 				}
-
-				if isNew {
-					p.telemetryBuilder.ConnectorServicegraphTotalEdges.Add(ctx, 1)
-				}
 			}
 		}
 	}
 	return nil
 }
 
+// upsertEdge upserts the edge of the key in the store, dropping the span when the store is full.
+func (p *serviceGraphConnector) upsertEdge(ctx context.Context, key store.Key, update store.Callback) error {
+	isNew, err := p.store.UpsertEdge(key, update)
+	if errors.Is(err, store.ErrTooManyItems) {
+		p.telemetryBuilder.ConnectorServicegraphDroppedSpans.Add(ctx, 1)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if isNew {
+		p.telemetryBuilder.ConnectorServicegraphTotalEdges.Add(ctx, 1)
+	}
+	return nil
+}
+
+// serverEdgeKeys returns the keys of the edges the server or consumer span is the server side of.
+// A consumer span receiving messages is usually linked to the producer spans rather than being
+// their child, e.g. when it processes a batch of messages sent in other traces, so it's
+// paired with every linked span. Otherwise, it's paired with its parent span.
+func serverEdgeKeys(span ptrace.Span) []store.Key {
+	links := span.Links()
+	if span.Kind() != ptrace.SpanKindConsumer || links.Len() == 0 {
+		return []store.Key{store.NewKey(span.TraceID(), span.ParentSpanID())}
+	}
+
+	keys := make([]store.Key, 0, links.Len())
+	for i := 0; i < links.Len(); i++ {
+		link := links.At(i)
+		keys = append(keys, store.NewKey(link.TraceID(), link.SpanID()))
+	}
+	return keys
+}
+
+// upsertDestination keeps the destination of a producer or consumer span, which names
+// the virtual node of the messaging system when the other side of the edge is missing.
+func upsertDestination(peers map[string]string, spanAttr pcommon.Map) {
+	if v, ok := pdatautil.GetAttributeValue(semconv.AttributeMessagingDestinationName, spanAttr); ok {
+		peers[semconv.AttributeMessagingDestinationName] = v
+	}
+}
+
 func (p *serviceGraphConnector) upsertDimensions(kind string, m map[string]string, resourceAttr pcommon.Map, spanAttr pcommon.Map) {
 	for _, dim := range p.config.Dimensions {
 		if v, ok := pdatautil.GetAttributeValue(dim, resourceAttr, spanAttr); ok {
@@ -319,6 +415,16 @@ func (p *serviceGraphConnector) upsertDimensions(kind string, m map[string]strin
 	}
 }
 
+// keepSpan copies the span to the edge when the connector forwards the spans of the unpaired edges
+func (p *serviceGraphConnector) keepSpan(e *store.Edge, resource pcommon.Resource, span ptrace.Span) {
+	if p.tracesConsumer == nil {
+		return
+	}
+	rs := e.Spans.ResourceSpans().AppendEmpty()
+	resource.CopyTo(rs.Resource())
+	span.CopyTo(rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty())
+}
+
 func (p *serviceGraphConnector) upsertPeerAttributes(m []string, peers map[string]string, spanAttr pcommon.Map) {
 	for _, s := range m {
 		if v, ok := pdatautil.GetAttributeValue(s, spanAttr); ok {
@@ -336,6 +442,10 @@ func (p *serviceGraphConnector) onComplete(e *store.Edge) {
 		zap.String("connection_type", string(e.ConnectionType)),
 		zap.Stringer("trace_id", e.TraceID),
 	)
+	if p.tracesConsumer != nil {
+		// The paired edges are accounted in the metrics pipelines, only the unpaired ones are forwarded
+		return
+	}
 	p.aggregateMetricsForEdge(e)
 }
 
@@ -350,7 +460,33 @@ func (p *serviceGraphConnector) onExpire(e *store.Edge) {
 
 	p.telemetryBuilder.ConnectorServicegraphExpiredEdges.Add(context.Background(), 1)
 
+	if p.tracesConsumer != nil {
+		p.unpairedMutex.Lock()
+		e.Spans.ResourceSpans().MoveAndAppendTo(p.unpaired.ResourceSpans())
+		p.unpairedMutex.Unlock()
+		return
+	}
+
 	if virtualNodeFeatureGate.IsEnabled() && len(p.config.VirtualNodePeerAttributes) > 0 {
+		// The messages sent or received without the other side are recorded as an edge
+		// between the service and the destination they were sent to, whatever the peer attributes
+		if destination, ok := e.Peer[semconv.AttributeMessagingDestinationName]; ok && e.ConnectionType == store.MessagingSystem {
+			e.ConnectionType = store.VirtualNode
+			if len(e.ClientService) == 0 {
+				e.ClientService = destination
+				if p.config.VirtualNodeExtraLabel {
+					e.VirtualNodeLabel = store.ClientVirtualNode
+				}
+			} else {
+				e.ServerService = destination
+				if p.config.VirtualNodeExtraLabel {
+					e.VirtualNodeLabel = store.ServerVirtualNode
+				}
+			}
+			p.onComplete(e)
+			return
+		}
+
 		e.ConnectionType = store.VirtualNode
 		if len(e.ClientService) == 0 && e.Key.SpanIDIsEmpty() {
 			e.ClientService = "user"
@@ -386,6 +522,9 @@ func (p *serviceGraphConnector) aggregateMetricsForEdge(e *store.Edge) {
 		p.updateErrorMetrics(metricKey)
 	}
 	p.updateDurationMetrics(metricKey, e.ServerLatencySec, e.ClientLatencySec)
+	if p.config.EnableMessagingSystemLatencyHistogram && e.ConnectionType == store.MessagingSystem {
+		p.updateMessagingSystemDurationMetrics(metricKey, messagingSystemLatency(e))
+	}
 }
 
 func (p *serviceGraphConnector) updateSeries(key string, dimensions pcommon.Map) {
@@ -437,6 +576,16 @@ func (p *serviceGraphConnector) updateClientDurationMetrics(key string, duration
 	p.reqClientDurationSecondsBucketCounts[key][index]++
 }
 
+func (p *serviceGraphConnector) updateMessagingSystemDurationMetrics(key string, duration float64) {
+	index := sort.SearchFloat64s(p.reqDurationBounds, duration) // Search bucket index
+	if _, ok := p.reqMessagingSystemDurationSecondsBucketCounts[key]; !ok {
+		p.reqMessagingSystemDurationSecondsBucketCounts[key] = make([]uint64, len(p.reqDurationBounds)+1)
+	}
+	p.reqMessagingSystemDurationSecondsSum[key] += duration
+	p.reqMessagingSystemDurationSecondsCount[key]++
+	p.reqMessagingSystemDurationSecondsBucketCounts[key][index]++
+}
+
 func buildDimensions(e *store.Edge) pcommon.Map {
 	dims := pcommon.NewMap()
 	dims.PutStr("client", e.ClientService)
@@ -532,7 +681,43 @@ func (p *serviceGraphConnector) collectLatencyMetrics(ilm pmetric.ScopeMetrics)
 		return err
 	}
 
-	return p.collectClientLatencyMetrics(ilm)
+	if err := p.collectClientLatencyMetrics(ilm); err != nil {
+		return err
+	}
+
+	return p.collectMessagingSystemLatencyMetrics(ilm)
+}
+
+func (p *serviceGraphConnector) collectMessagingSystemLatencyMetrics(ilm pmetric.ScopeMetrics) error {
+	if len(p.reqMessagingSystemDurationSecondsCount) > 0 {
+		mDuration := ilm.Metrics().AppendEmpty()
+		mDuration.SetName("traces_service_graph_request_messaging_system")
+		mDuration.SetUnit(secondsUnit)
+		if legacyLatencyUnitMsFeatureGate.IsEnabled() {
+			mDuration.SetUnit(millisecondsUnit)
+		}
+		// TODO: Support other aggregation temporalities
+		mDuration.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		timestamp := pcommon.NewTimestampFromTime(time.Now())
+
+		for key := range p.reqMessagingSystemDurationSecondsCount {
+			dpDuration := mDuration.Histogram().DataPoints().AppendEmpty()
+			dpDuration.SetStartTimestamp(pcommon.NewTimestampFromTime(p.startTime))
+			dpDuration.SetTimestamp(timestamp)
+			dpDuration.ExplicitBounds().FromRaw(p.reqDurationBounds)
+			dpDuration.BucketCounts().FromRaw(p.reqMessagingSystemDurationSecondsBucketCounts[key])
+			dpDuration.SetCount(p.reqMessagingSystemDurationSecondsCount[key])
+			dpDuration.SetSum(p.reqMessagingSystemDurationSecondsSum[key])
+
+			dimensions, ok := p.dimensionsForSeries(key)
+			if !ok {
+				return fmt.Errorf("failed to find dimensions for key %s", key)
+			}
+
+			dimensions.CopyTo(dpDuration.Attributes())
+		}
+	}
+	return nil
 }
 
 func (p *serviceGraphConnector) collectClientLatencyMetrics(ilm pmetric.ScopeMetrics) error {
@@ -623,12 +808,33 @@ func (p *serviceGraphConnector) storeExpirationLoop(d time.Duration) {
 		select {
 		case <-t.C:
 			p.store.Expire()
+			if err := p.flushUnpaired(context.Background()); err != nil {
+				p.logger.Error("failed to forward the spans of the unpaired edges", zap.Error(err))
+			}
 		case <-p.shutdownCh:
 			return
 		}
 	}
 }
 
+// flushUnpaired forwards the spans of the edges that expired unpaired, so another
+// servicegraph connector receiving the spans of all the collector instances can pair them.
+func (p *serviceGraphConnector) flushUnpaired(ctx context.Context) error {
+	if p.tracesConsumer == nil {
+		return nil
+	}
+
+	p.unpairedMutex.Lock()
+	td := p.unpaired
+	p.unpaired = ptrace.NewTraces()
+	p.unpairedMutex.Unlock()
+
+	if td.ResourceSpans().Len() == 0 {
+		return nil
+	}
+	return p.tracesConsumer.ConsumeTraces(ctx, td)
+}
+
 func (p *serviceGraphConnector) getPeerHost(m []string, peers map[string]string) string {
 	peerStr := "unknown"
 	for _, s := range m {
@@ -674,6 +880,9 @@ func (p *serviceGraphConnector) cleanCache() {
 		delete(p.reqServerDurationSecondsCount, key)
 		delete(p.reqServerDurationSecondsSum, key)
 		delete(p.reqServerDurationSecondsBucketCounts, key)
+		delete(p.reqMessagingSystemDurationSecondsCount, key)
+		delete(p.reqMessagingSystemDurationSecondsSum, key)
+		delete(p.reqMessagingSystemDurationSecondsBucketCounts, key)
 	}
 	p.seriesMutex.Unlock()
 
@@ -692,6 +901,15 @@ func spanDuration(span ptrace.Span) float64 {
 	return float64(span.EndTimestamp()-span.StartTimestamp()) / float64(time.Second.Nanoseconds())
 }
 
+// messagingSystemLatency returns the time in seconds (legacy ms) between the end of the producer span
+// and the start of the consumer span of the edge, or 0 if the consumer span started first.
+func messagingSystemLatency(e *store.Edge) float64 {
+	if e.ServerStartTimestamp <= e.ClientEndTimestamp {
+		return 0
+	}
+	return durationToFloat(e.ServerStartTimestamp.AsTime().Sub(e.ClientEndTimestamp.AsTime()))
+}
+
 // durationToFloat converts the given duration to the number of seconds (legacy ms) it represents.
 func durationToFloat(d time.Duration) float64 {
 	if legacyLatencyUnitMsFeatureGate.IsEnabled() {
@@ -19,6 +19,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/exporter"
+	extensionstorage "go.opentelemetry.io/collector/extension/xextension/storage"
 	"go.opentelemetry.io/collector/featuregate"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -30,6 +31,8 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/servicegraphconnector/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/servicegraphconnector/internal/metadatatest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/servicegraphconnector/internal/store"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/golden"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
 )
@@ -731,3 +734,259 @@ func TestVirtualNodeClientLabels(t *testing.T) {
 	)
 	require.NoError(t, err)
 }
+
+// sharedStorage is a storage extension returning the same client to all the connectors, like
+// a storage extension backed by a shared database would.
+type sharedStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	client extensionstorage.Client
+}
+
+func (s *sharedStorage) GetClient(context.Context, component.Kind, component.ID, string) (extensionstorage.Client, error) {
+	return s.client, nil
+}
+
+func TestConnectorSharedStore(t *testing.T) {
+	storageID := component.MustNewID("shared_storage")
+	host := storagetest.NewStorageHost().WithExtension(storageID, &sharedStorage{
+		client: storagetest.NewInMemoryClient(component.KindConnector, component.MustNewID("servicegraph"), ""),
+	})
+	cfg := &Config{
+		Dimensions: []string{"some-attribute"},
+		Store:      StoreConfig{MaxItems: 10, TTL: time.Hour, StorageID: &storageID},
+	}
+
+	// Each collector instance receives one half of the edge
+	td := buildSampleTrace(t, "val")
+	clientTraces, serverTraces := ptrace.NewTraces(), ptrace.NewTraces()
+	td.CopyTo(clientTraces)
+	td.CopyTo(serverTraces)
+	clientTraces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().RemoveIf(func(span ptrace.Span) bool {
+		return span.Kind() != ptrace.SpanKindClient
+	})
+	serverTraces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().RemoveIf(func(span ptrace.Span) bool {
+		return span.Kind() != ptrace.SpanKindServer
+	})
+
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zaptest.NewLogger(t)
+	var sinks []*consumertest.MetricsSink
+	for _, traces := range []ptrace.Traces{clientTraces, serverTraces} {
+		sink := new(consumertest.MetricsSink)
+		conn, err := newConnector(set, cfg, sink)
+		require.NoError(t, err)
+		require.NoError(t, conn.Start(context.Background(), host))
+		defer func() { require.NoError(t, conn.Shutdown(context.Background())) }()
+		require.NoError(t, conn.ConsumeTraces(context.Background(), traces))
+		sinks = append(sinks, sink)
+	}
+
+	// The first instance only stored its half, the second one completed the edge
+	assert.Empty(t, sinks[0].AllMetrics())
+	require.Len(t, sinks[1].AllMetrics(), 1)
+	md := sinks[1].AllMetrics()[0]
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	verifyHappyCaseMetricsWithDuration(2, 1)(t, md)
+}
+
+func TestConnectorForwardUnpairedSpans(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Store.TTL = -time.Second
+
+	sink := new(consumertest.TracesSink)
+	traceConnector, err := factory.CreateTracesToTraces(context.Background(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	conn := traceConnector.(*serviceGraphConnector)
+	require.NoError(t, conn.Start(context.Background(), componenttest.NewNopHost()))
+	defer require.NoError(t, conn.Shutdown(context.Background()))
+
+	// Only the spans of the incomplete edges are forwarded once they expire
+	require.NoError(t, conn.ConsumeTraces(context.Background(), buildSampleTrace(t, "val")))
+	require.NoError(t, conn.ConsumeTraces(context.Background(), incompleteClientTraces()))
+	conn.store.Expire()
+	require.NoError(t, conn.flushUnpaired(context.Background()))
+
+	require.Len(t, sink.AllTraces(), 1)
+	td := sink.AllTraces()[0]
+	require.Equal(t, 1, td.SpanCount())
+	rs := td.ResourceSpans().At(0)
+	serviceName, _ := rs.Resource().Attributes().Get(semconv.AttributeServiceName)
+	assert.Equal(t, "some-client-service", serviceName.Str())
+	span := rs.ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, ptrace.SpanKindClient, span.Kind())
+	assert.Equal(t, pcommon.SpanID([8]byte{1, 2, 3, 4, 4, 3, 2, 1}), span.SpanID())
+
+	// Nothing is forwarded when there are no unpaired edges
+	require.NoError(t, conn.flushUnpaired(context.Background()))
+	assert.Len(t, sink.AllTraces(), 1)
+}
+
+// buildMessagingTraces returns two producer spans of the orders service, each in its own trace,
+// and a consumer span of the billing service receiving both messages, linked to the producer spans.
+func buildMessagingTraces() (producers, consumers ptrace.Traces) {
+	tStart := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	producers = ptrace.NewTraces()
+	rs := producers.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(semconv.AttributeServiceName, "orders")
+	producerSpans := rs.ScopeSpans().AppendEmpty().Spans()
+	for i := byte(1); i <= 2; i++ {
+		span := producerSpans.AppendEmpty()
+		span.SetName("orders send")
+		span.SetTraceID(pcommon.TraceID([16]byte{i}))
+		span.SetSpanID(pcommon.SpanID([8]byte{i}))
+		span.SetKind(ptrace.SpanKindProducer)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(tStart))
+		// The messages are sent after 100ms and 200ms
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(tStart.Add(time.Duration(i) * 100 * time.Millisecond)))
+		span.Attributes().PutStr(semconv.AttributeMessagingDestinationName, "orders")
+	}
+
+	consumers = ptrace.NewTraces()
+	rs = consumers.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(semconv.AttributeServiceName, "billing")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("orders process")
+	span.SetTraceID(pcommon.TraceID([16]byte{3}))
+	span.SetSpanID(pcommon.SpanID([8]byte{3}))
+	span.SetKind(ptrace.SpanKindConsumer)
+	// The messages are received after 1s
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(tStart.Add(time.Second)))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(tStart.Add(2 * time.Second)))
+	span.Attributes().PutStr(semconv.AttributeMessagingDestinationName, "orders")
+	for i := byte(1); i <= 2; i++ {
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID([16]byte{i}))
+		link.SetSpanID(pcommon.SpanID([8]byte{i}))
+	}
+	return producers, consumers
+}
+
+func findMetric(md pmetric.Metrics, name string) (pmetric.Metric, bool) {
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i), true
+		}
+	}
+	return pmetric.Metric{}, false
+}
+
+func TestMessagingSystemEdgesFromLinks(t *testing.T) {
+	cfg := &Config{
+		LatencyHistogramBuckets:               []time.Duration{500 * time.Millisecond, time.Second},
+		Store:                                 StoreConfig{MaxItems: 10, TTL: time.Hour},
+		EnableMessagingSystemLatencyHistogram: true,
+	}
+
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zaptest.NewLogger(t)
+	sink := new(consumertest.MetricsSink)
+	conn, err := newConnector(set, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, conn.Shutdown(context.Background())) }()
+
+	producers, consumers := buildMessagingTraces()
+	require.NoError(t, conn.ConsumeTraces(context.Background(), producers))
+	require.NoError(t, conn.ConsumeTraces(context.Background(), consumers))
+
+	// The consumer span completes the edges of both linked producer spans
+	assert.Equal(t, 0, conn.store.Len())
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+
+	total, ok := findMetric(md, "traces_service_graph_request_total")
+	require.True(t, ok)
+	require.Equal(t, 1, total.Sum().DataPoints().Len())
+	dp := total.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(2), dp.IntValue())
+	verifyAttr(t, dp.Attributes(), "client", "orders")
+	verifyAttr(t, dp.Attributes(), "server", "billing")
+	verifyAttr(t, dp.Attributes(), "connection_type", string(store.MessagingSystem))
+
+	latency, ok := findMetric(md, "traces_service_graph_request_messaging_system")
+	require.True(t, ok)
+	assert.Equal(t, secondsUnit, latency.Unit())
+	require.Equal(t, 1, latency.Histogram().DataPoints().Len())
+	hdp := latency.Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(2), hdp.Count())
+	assert.InDelta(t, 1.7, hdp.Sum(), 1e-9) // 900ms and 800ms in the messaging system
+	assert.Equal(t, []uint64{0, 2, 0}, hdp.BucketCounts().AsRaw())
+}
+
+func TestMessagingSystemDestinationVirtualNode(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		peerAttributes []string
+		want           map[string]string
+	}{
+		{
+			name:           "peer attributes",
+			peerAttributes: []string{semconv.AttributePeerService},
+			want: map[string]string{
+				"orders->orders":  string(store.ServerVirtualNode),
+				"orders->billing": string(store.ClientVirtualNode),
+			},
+		},
+		{
+			name:           "virtual nodes disabled",
+			peerAttributes: []string{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			testMessagingSystemDestinationVirtualNode(t, tt.peerAttributes, tt.want)
+		})
+	}
+}
+
+func testMessagingSystemDestinationVirtualNode(t *testing.T, peerAttributes []string, want map[string]string) {
+	cfg := &Config{
+		Store:                     StoreConfig{MaxItems: 10, TTL: -time.Second},
+		VirtualNodePeerAttributes: peerAttributes,
+		VirtualNodeExtraLabel:     true,
+	}
+
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zaptest.NewLogger(t)
+	sink := new(consumertest.MetricsSink)
+	conn, err := newConnector(set, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, conn.Shutdown(context.Background())) }()
+
+	// The producer and consumer spans are received without the other side
+	producers, consumers := buildMessagingTraces()
+	producers.ResourceSpans().At(0).ScopeSpans().At(0).Spans().RemoveIf(func(span ptrace.Span) bool {
+		return span.SpanID() != pcommon.SpanID([8]byte{1})
+	})
+	consumers.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Links().RemoveIf(func(link ptrace.SpanLink) bool {
+		return link.SpanID() != pcommon.SpanID([8]byte{2})
+	})
+	require.NoError(t, conn.ConsumeTraces(context.Background(), producers))
+	require.NoError(t, conn.ConsumeTraces(context.Background(), consumers))
+	conn.store.Expire()
+	require.NoError(t, conn.flushMetrics(context.Background()))
+
+	edges := map[string]string{}
+	if want == nil {
+		// Without virtual nodes, the unpaired spans are only counted as expired edges
+		assert.Empty(t, sink.AllMetrics())
+		return
+	}
+	md := sink.AllMetrics()[len(sink.AllMetrics())-1]
+	total, ok := findMetric(md, "traces_service_graph_request_total")
+	require.True(t, ok)
+	for i := 0; i < total.Sum().DataPoints().Len(); i++ {
+		attrs := total.Sum().DataPoints().At(i).Attributes()
+		client, _ := attrs.Get("client")
+		server, _ := attrs.Get("server")
+		connectionType, _ := attrs.Get("connection_type")
+		label, _ := attrs.Get(virtualNodeLabel)
+		assert.Equal(t, string(store.VirtualNode), connectionType.Str())
+		edges[client.Str()+"->"+server.Str()] = label.Str()
+	}
+	assert.Equal(t, want, edges)
+}
@@ -55,6 +55,7 @@ func NewFactory() connector.Factory {
 		metadata.Type,
 		createDefaultConfig,
 		connector.WithTracesToMetrics(createTracesToMetricsConnector, metadata.TracesToMetricsStability),
+		connector.WithTracesToTraces(createTracesToTracesConnector, metadata.TracesToTracesStability),
 	)
 }
 
@@ -70,5 +71,22 @@ func createDefaultConfig() component.Config {
 }
 
 func createTracesToMetricsConnector(_ context.Context, params connector.Settings, cfg component.Config, nextConsumer consumer.Metrics) (connector.Traces, error) {
-	return newConnector(params.TelemetrySettings, cfg, nextConsumer)
+	c, err := newConnector(params.TelemetrySettings, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	c.id = params.ID
+	return c, nil
+}
+
+// createTracesToTracesConnector creates a connector forwarding the spans of the edges
+// that expire unpaired, instead of building metrics.
+func createTracesToTracesConnector(_ context.Context, params connector.Settings, cfg component.Config, nextConsumer consumer.Traces) (connector.Traces, error) {
+	c, err := newConnector(params.TelemetrySettings, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.id = params.ID
+	c.tracesConsumer = nextConsumer
+	return c, nil
 }
@@ -3,6 +3,7 @@ module github.com/open-telemetry/opentelemetry-collector-contrib/connector/servi
 go 1.23.0
 
 require (
+	github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage v0.120.1
 	github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil v0.120.1
 	github.com/open-telemetry/opentelemetry-collector-contrib/pkg/golden v0.120.1
 	github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest v0.120.1
@@ -15,6 +16,7 @@ require (
 	go.opentelemetry.io/collector/consumer v1.26.1-0.20250226024140-8099e51f9a77
 	go.opentelemetry.io/collector/consumer/consumertest v0.120.1-0.20250226024140-8099e51f9a77
 	go.opentelemetry.io/collector/exporter v0.120.1-0.20250226024140-8099e51f9a77
+	go.opentelemetry.io/collector/extension/xextension v0.120.1-0.20250226024140-8099e51f9a77
 	go.opentelemetry.io/collector/featuregate v1.26.1-0.20250226024140-8099e51f9a77
 	go.opentelemetry.io/collector/otelcol/otelcoltest v0.120.1-0.20250226024140-8099e51f9a77
 	go.opentelemetry.io/collector/pdata v1.26.1-0.20250226024140-8099e51f9a77
@@ -143,6 +145,8 @@ replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdata
 
 replace github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil => ../../pkg/pdatautil
 
+replace github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage => ../../extension/storage
+
 replace go.opentelemetry.io/collector/extension/extensionauth => go.opentelemetry.io/collector/extension/extensionauth v0.0.0-20250226024140-8099e51f9a77
 
 replace go.opentelemetry.io/collector/extension/extensionauth/extensionauthtest => go.opentelemetry.io/collector/extension/extensionauth/extensionauthtest v0.0.0-20250226024140-8099e51f9a77
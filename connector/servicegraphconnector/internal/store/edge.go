@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
 type ConnectionType string
@@ -35,6 +36,10 @@ type Edge struct {
 	ServerService, ClientService       string
 	ServerLatencySec, ClientLatencySec float64
 
+	// ClientEndTimestamp and ServerStartTimestamp are the end of the producer span and the start
+	// of the consumer span of a messaging system, the time the message spent in the system.
+	ClientEndTimestamp, ServerStartTimestamp pcommon.Timestamp
+
 	// If either the client or the server spans have status code error,
 	// the Edge will be considered as failed.
 	Failed bool
@@ -50,6 +55,9 @@ type Edge struct {
 
 	// VirtualNodeLabel is an optional label to be added to the spans
 	VirtualNodeLabel VirtualNodeLabel
+
+	// Spans optionally keeps the spans of the Edge, to forward them when it expires
+	Spans ptrace.Traces
 }
 
 func newEdge(key Key, ttl time.Duration) *Edge {
@@ -58,7 +66,37 @@ func newEdge(key Key, ttl time.Duration) *Edge {
 		Dimensions: make(map[string]string),
 		expiration: time.Now().Add(ttl),
 		Peer:       make(map[string]string),
+		Spans:      ptrace.NewTraces(),
+	}
+}
+
+// merge completes the Edge with the side of the other Edge it's missing
+func (e *Edge) merge(other *Edge) {
+	if len(e.ClientService) == 0 {
+		e.ClientService = other.ClientService
+		e.ClientLatencySec = other.ClientLatencySec
+		e.ClientEndTimestamp = other.ClientEndTimestamp
+	}
+	if len(e.ServerService) == 0 {
+		e.ServerService = other.ServerService
+		e.ServerLatencySec = other.ServerLatencySec
+		e.ServerStartTimestamp = other.ServerStartTimestamp
+	}
+	if e.ConnectionType == Unknown {
+		e.ConnectionType = other.ConnectionType
+	}
+	e.Failed = e.Failed || other.Failed
+	for k, v := range other.Dimensions {
+		if _, ok := e.Dimensions[k]; !ok {
+			e.Dimensions[k] = v
+		}
+	}
+	for k, v := range other.Peer {
+		if _, ok := e.Peer[k]; !ok {
+			e.Peer[k] = v
+		}
 	}
+	other.Spans.ResourceSpans().MoveAndAppendTo(e.Spans.ResourceSpans())
 }
 
 // isComplete returns true if the corresponding client and server
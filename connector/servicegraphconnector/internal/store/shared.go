@@ -0,0 +1,296 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package store // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/servicegraphconnector/internal/store"
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	extensionstorage "go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+const (
+	clientSide = "client"
+	serverSide = "server"
+)
+
+// EdgeStore pairs the client and server spans of the requests between services into edges.
+type EdgeStore interface {
+	// UpsertEdge fetches an Edge from the store and updates it using the given callback.
+	UpsertEdge(key Key, update Callback) (isNew bool, err error)
+	// Expire evicts all expired items in the store.
+	Expire()
+	// Len returns the number of items in the store.
+	Len() int
+}
+
+var (
+	_ EdgeStore = (*Store)(nil)
+	_ EdgeStore = (*SharedStore)(nil)
+)
+
+// pendingHalf is a half of an edge written to the storage by this instance, waiting for its pair.
+type pendingHalf struct {
+	key        Key
+	side       string
+	expiration time.Time
+}
+
+// SharedStore is a Store keeping the edges in a storage extension, so the client and server
+// spans of a request are paired even when they're received by different collector instances
+// sharing the storage, e.g. with the redis storage extension.
+//
+// Each half of an edge is written under its own key. A half is completed by the first
+// instance receiving its pair, while the instance that wrote it expires it when it's
+// still unpaired after the TTL. The halves are taken from the storage with a batch getting
+// and deleting them, so each half is completed or expired by a single instance, provided
+// the batches of the storage are atomic, as with the redis storage extension.
+type SharedStore struct {
+	client extensionstorage.Client
+	logger *zap.Logger
+
+	l   *list.List
+	mtx sync.Mutex
+	m   map[string]*list.Element
+
+	onComplete Callback
+	onExpire   Callback
+
+	ttl      time.Duration
+	maxItems int
+}
+
+// NewSharedStore creates a SharedStore keeping the edges with the storage client.
+// maxItems limits the number of halves written by this instance that are waiting for their pair.
+func NewSharedStore(client extensionstorage.Client, logger *zap.Logger, ttl time.Duration, maxItems int, onComplete, onExpire Callback) *SharedStore {
+	return &SharedStore{
+		client: client,
+		logger: logger,
+
+		l: list.New(),
+		m: make(map[string]*list.Element),
+
+		onComplete: onComplete,
+		onExpire:   onExpire,
+
+		ttl:      ttl,
+		maxItems: maxItems,
+	}
+}
+
+// Len returns the number of halves written by this instance that are waiting for their pair.
+func (s *SharedStore) Len() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.l.Len()
+}
+
+// UpsertEdge creates an Edge and updates it using the given callback. If the Edge is complete,
+// it's completed right away. Otherwise, it's completed with its other half if the storage has it,
+// or written to the storage to wait for it.
+func (s *SharedStore) UpsertEdge(key Key, update Callback) (isNew bool, err error) {
+	ctx := context.Background()
+
+	edge := newEdge(key, s.ttl)
+	update(edge)
+
+	if edge.isComplete() {
+		s.onComplete(edge)
+		return true, nil
+	}
+
+	side, otherSide := clientSide, serverSide
+	if edge.ClientService == "" {
+		side, otherSide = serverSide, clientSide
+	}
+
+	other, err := s.take(ctx, key, otherSide)
+	if err != nil {
+		return false, err
+	}
+	if other != nil {
+		edge.merge(other)
+		s.onComplete(edge)
+		return false, nil
+	}
+
+	s.mtx.Lock()
+	full := s.l.Len() >= s.maxItems
+	s.mtx.Unlock()
+	if full {
+		return false, ErrTooManyItems
+	}
+
+	data, err := encodeEdge(edge)
+	if err != nil {
+		return false, err
+	}
+	if err = s.client.Set(ctx, storageKey(key, side), data); err != nil {
+		return false, fmt.Errorf("failed to store edge: %w", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	half := &pendingHalf{key: key, side: side, expiration: edge.expiration}
+	if ele, ok := s.m[storageKey(key, side)]; ok {
+		s.l.Remove(ele)
+	}
+	s.m[storageKey(key, side)] = s.l.PushBack(half)
+	return true, nil
+}
+
+// Expire checks the expired halves written by this instance. Halves completed by another
+// instance are forgotten, the others are completed if their pair was stored meanwhile, or expired.
+func (s *SharedStore) Expire() {
+	ctx := context.Background()
+	for _, half := range s.popExpired() {
+		if err := s.expireHalf(ctx, half); err != nil {
+			s.logger.Warn("failed to expire edge", zap.Error(err))
+		}
+	}
+}
+
+func (s *SharedStore) popExpired() []*pendingHalf {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	var expired []*pendingHalf
+	for head := s.l.Front(); head != nil; head = s.l.Front() {
+		half := head.Value.(*pendingHalf)
+		if !now.After(half.expiration) {
+			break
+		}
+		expired = append(expired, half)
+		delete(s.m, storageKey(half.key, half.side))
+		s.l.Remove(head)
+	}
+	return expired
+}
+
+func (s *SharedStore) expireHalf(ctx context.Context, half *pendingHalf) error {
+	edge, err := s.take(ctx, half.key, half.side)
+	if err != nil || edge == nil {
+		// A nil edge was completed by another instance
+		return err
+	}
+
+	otherSide := clientSide
+	if half.side == clientSide {
+		otherSide = serverSide
+	}
+	other, err := s.take(ctx, half.key, otherSide)
+	if err != nil {
+		// The half was taken, it's expired so that it isn't lost
+		s.onExpire(edge)
+		return err
+	}
+
+	if other != nil {
+		// Both halves were stored at the same time
+		edge.merge(other)
+		s.onComplete(edge)
+		return nil
+	}
+	s.onExpire(edge)
+	return nil
+}
+
+// take gets and deletes the half of the edge of the given side in a single batch, so that
+// concurrent instances can't both take it. It returns nil if the storage doesn't have it.
+func (s *SharedStore) take(ctx context.Context, key Key, side string) (*Edge, error) {
+	get := extensionstorage.GetOperation(storageKey(key, side))
+	if err := s.client.Batch(ctx, get, extensionstorage.DeleteOperation(storageKey(key, side))); err != nil {
+		return nil, fmt.Errorf("failed to take edge: %w", err)
+	}
+	if get.Value == nil {
+		return nil, nil
+	}
+	edge, err := decodeEdge(key, get.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode edge: %w", err)
+	}
+	return edge, nil
+}
+
+// storageKey is the key of the half of the edge of the given side in the storage.
+func storageKey(key Key, side string) string {
+	return key.tid.String() + "/" + key.sid.String() + "/" + side
+}
+
+// storedEdge is the representation of a half of an edge in the storage.
+type storedEdge struct {
+	ConnectionType   ConnectionType    `json:"connection_type,omitempty"`
+	ClientService    string            `json:"client_service,omitempty"`
+	ServerService    string            `json:"server_service,omitempty"`
+	ClientLatencySec float64           `json:"client_latency_sec,omitempty"`
+	ServerLatencySec float64           `json:"server_latency_sec,omitempty"`
+	ClientEndTime    uint64            `json:"client_end_time,omitempty"`
+	ServerStartTime  uint64            `json:"server_start_time,omitempty"`
+	Failed           bool              `json:"failed,omitempty"`
+	Dimensions       map[string]string `json:"dimensions,omitempty"`
+	Peer             map[string]string `json:"peer,omitempty"`
+	Spans            []byte            `json:"spans,omitempty"`
+}
+
+func encodeEdge(e *Edge) ([]byte, error) {
+	stored := storedEdge{
+		ConnectionType:   e.ConnectionType,
+		ClientService:    e.ClientService,
+		ServerService:    e.ServerService,
+		ClientLatencySec: e.ClientLatencySec,
+		ServerLatencySec: e.ServerLatencySec,
+		ClientEndTime:    uint64(e.ClientEndTimestamp),
+		ServerStartTime:  uint64(e.ServerStartTimestamp),
+		Failed:           e.Failed,
+		Dimensions:       e.Dimensions,
+		Peer:             e.Peer,
+	}
+	if e.Spans.SpanCount() > 0 {
+		spans, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(e.Spans)
+		if err != nil {
+			return nil, err
+		}
+		stored.Spans = spans
+	}
+	return json.Marshal(stored)
+}
+
+func decodeEdge(key Key, data []byte) (*Edge, error) {
+	var stored storedEdge
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	e := newEdge(key, 0)
+	e.TraceID = key.tid
+	e.ConnectionType = stored.ConnectionType
+	e.ClientService = stored.ClientService
+	e.ServerService = stored.ServerService
+	e.ClientLatencySec = stored.ClientLatencySec
+	e.ServerLatencySec = stored.ServerLatencySec
+	e.ClientEndTimestamp = pcommon.Timestamp(stored.ClientEndTime)
+	e.ServerStartTimestamp = pcommon.Timestamp(stored.ServerStartTime)
+	e.Failed = stored.Failed
+	for k, v := range stored.Dimensions {
+		e.Dimensions[k] = v
+	}
+	for k, v := range stored.Peer {
+		e.Peer[k] = v
+	}
+	if len(stored.Spans) > 0 {
+		spans, err := (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(stored.Spans)
+		if err != nil {
+			return nil, err
+		}
+		e.Spans = spans
+	}
+	return e, nil
+}
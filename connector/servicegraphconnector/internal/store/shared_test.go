@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	extensionstorage "go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func newTestClient() *storagetest.TestClient {
+	return storagetest.NewInMemoryClient(component.KindConnector, component.MustNewID("servicegraph"), "")
+}
+
+func TestSharedStoreUpsertEdge(t *testing.T) {
+	key := NewKey(pcommon.TraceID([16]byte{1, 2, 3}), pcommon.SpanID([8]byte{1, 2, 3}))
+	client := newTestClient()
+
+	var completed []*Edge
+	var onExpireCount int
+	onComplete := func(e *Edge) { completed = append(completed, e) }
+
+	// Two collector instances sharing the storage
+	s1 := NewSharedStore(client, zaptest.NewLogger(t), -time.Second, 10, onComplete, countingCallback(&onExpireCount))
+	s2 := NewSharedStore(client, zaptest.NewLogger(t), time.Hour, 10, onComplete, countingCallback(&onExpireCount))
+
+	// The first instance receives the client span
+	isNew, err := s1.UpsertEdge(key, func(e *Edge) {
+		e.ClientService = clientService
+		e.ClientLatencySec = 1
+		e.Dimensions["client_attr"] = "a"
+	})
+	require.NoError(t, err)
+	require.True(t, isNew)
+	assert.Equal(t, 1, s1.Len())
+	assert.Empty(t, completed)
+
+	// The second instance receives the server span and completes the edge
+	isNew, err = s2.UpsertEdge(key, func(e *Edge) {
+		e.ServerService = "server"
+		e.ServerLatencySec = 2
+		e.Failed = true
+		e.Dimensions["server_attr"] = "b"
+	})
+	require.NoError(t, err)
+	require.False(t, isNew)
+	assert.Equal(t, 0, s2.Len())
+	require.Len(t, completed, 1)
+	assert.Equal(t, clientService, completed[0].ClientService)
+	assert.Equal(t, "server", completed[0].ServerService)
+	assert.Equal(t, 1.0, completed[0].ClientLatencySec)
+	assert.Equal(t, 2.0, completed[0].ServerLatencySec)
+	assert.True(t, completed[0].Failed)
+	assert.Equal(t, map[string]string{"client_attr": "a", "server_attr": "b"}, completed[0].Dimensions)
+
+	// The half of the first instance was completed by the second one, so it doesn't expire
+	s1.Expire()
+	assert.Equal(t, 0, s1.Len())
+	assert.Len(t, completed, 1)
+	assert.Equal(t, 0, onExpireCount)
+}
+
+func TestSharedStoreExpire(t *testing.T) {
+	key := NewKey(pcommon.TraceID([16]byte{1, 2, 3}), pcommon.SpanID([8]byte{1, 2, 3}))
+	client := newTestClient()
+
+	var expired []*Edge
+	var onCompleteCount int
+	s := NewSharedStore(client, zaptest.NewLogger(t), -time.Second, 10, countingCallback(&onCompleteCount), func(e *Edge) { expired = append(expired, e) })
+
+	_, err := s.UpsertEdge(key, func(e *Edge) {
+		e.ServerService = "server"
+		e.ConnectionType = MessagingSystem
+		e.Spans.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("server span")
+	})
+	require.NoError(t, err)
+
+	s.Expire()
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, 0, onCompleteCount)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "server", expired[0].ServerService)
+	assert.Equal(t, MessagingSystem, expired[0].ConnectionType)
+	assert.Equal(t, key, expired[0].Key)
+	require.Equal(t, 1, expired[0].Spans.SpanCount())
+	assert.Equal(t, "server span", expired[0].Spans.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+
+	// The expired half is removed from the storage
+	data, err := client.Get(context.Background(), storageKey(key, serverSide))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestSharedStoreExpireBothHalves(t *testing.T) {
+	key := NewKey(pcommon.TraceID([16]byte{1, 2, 3}), pcommon.SpanID([8]byte{1, 2, 3}))
+	client := newTestClient()
+
+	var onCompleteCount, onExpireCount int
+	s := NewSharedStore(client, zaptest.NewLogger(t), -time.Second, 10, countingCallback(&onCompleteCount), countingCallback(&onExpireCount))
+
+	_, err := s.UpsertEdge(key, func(e *Edge) {
+		e.ClientService = clientService
+	})
+	require.NoError(t, err)
+
+	// Another instance stored the other half at the same time, without seeing this one
+	other := newEdge(key, time.Hour)
+	other.ServerService = "server"
+	data, err := encodeEdge(other)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(context.Background(), storageKey(key, serverSide), data))
+
+	s.Expire()
+	assert.Equal(t, 1, onCompleteCount)
+	assert.Equal(t, 0, onExpireCount)
+}
+
+// yieldingClient yields the processor before each operation, so that the operations of concurrent
+// instances interleave.
+type yieldingClient struct {
+	*storagetest.TestClient
+}
+
+func (c yieldingClient) Get(ctx context.Context, key string) ([]byte, error) {
+	runtime.Gosched()
+	return c.TestClient.Get(ctx, key)
+}
+
+func (c yieldingClient) Set(ctx context.Context, key string, value []byte) error {
+	runtime.Gosched()
+	return c.TestClient.Set(ctx, key, value)
+}
+
+func (c yieldingClient) Delete(ctx context.Context, key string) error {
+	runtime.Gosched()
+	return c.TestClient.Delete(ctx, key)
+}
+
+func (c yieldingClient) Batch(ctx context.Context, ops ...*extensionstorage.Operation) error {
+	runtime.Gosched()
+	return c.TestClient.Batch(ctx, ops...)
+}
+
+func TestSharedStoreConcurrentInstances(t *testing.T) {
+	client := yieldingClient{newTestClient()}
+
+	var mtx sync.Mutex
+	completed := map[Key]int{}
+	expired := map[Key]int{}
+	onComplete := func(e *Edge) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		completed[e.Key]++
+	}
+	onExpire := func(e *Edge) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		expired[e.Key]++
+	}
+
+	// Two instances expiring the halves of the edges right away
+	s1 := NewSharedStore(client, zaptest.NewLogger(t), -time.Second, 1000, onComplete, onExpire)
+	s2 := NewSharedStore(client, zaptest.NewLogger(t), -time.Second, 1000, onComplete, onExpire)
+	keys := make([]Key, 1000)
+	for i := range keys {
+		keys[i] = NewKey(pcommon.TraceID([16]byte{byte(i), byte(i >> 8)}), pcommon.SpanID([8]byte{1}))
+	}
+
+	// The halves of each edge are received and expired at the same time
+	for _, key := range keys {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for _, instance := range []struct {
+			s      *SharedStore
+			update Callback
+		}{
+			{s: s1, update: func(e *Edge) { e.ClientService = clientService }},
+			{s: s2, update: func(e *Edge) { e.ServerService = "server" }},
+		} {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, err := instance.s.UpsertEdge(key, instance.update)
+				assert.NoError(t, err)
+			}()
+			go func() {
+				defer wg.Done()
+				<-start
+				for range 10 {
+					instance.s.Expire()
+					runtime.Gosched()
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+	}
+	s1.Expire()
+	s2.Expire()
+
+	// Each edge is either completed once, or both of its halves are expired
+	for _, key := range keys {
+		if completed[key] == 1 {
+			assert.Equal(t, 0, expired[key])
+		} else {
+			assert.Equal(t, 0, completed[key])
+			assert.Equal(t, 2, expired[key])
+		}
+	}
+	assert.Equal(t, 0, s1.Len()+s2.Len())
+}
+
+func TestSharedStoreTooManyItems(t *testing.T) {
+	s := NewSharedStore(newTestClient(), zaptest.NewLogger(t), time.Hour, 1, noopCallback, noopCallback)
+
+	_, err := s.UpsertEdge(NewKey(pcommon.TraceID([16]byte{1}), pcommon.SpanID([8]byte{1})), func(e *Edge) {
+		e.ClientService = clientService
+	})
+	require.NoError(t, err)
+
+	_, err = s.UpsertEdge(NewKey(pcommon.TraceID([16]byte{2}), pcommon.SpanID([8]byte{2})), func(e *Edge) {
+		e.ClientService = clientService
+	})
+	assert.ErrorIs(t, err, ErrTooManyItems)
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestEncodeEdge(t *testing.T) {
+	key := NewKey(pcommon.TraceID([16]byte{1, 2, 3}), pcommon.SpanID([8]byte{4, 5, 6}))
+	e := newEdge(key, time.Hour)
+	e.TraceID = key.tid
+	e.ConnectionType = Database
+	e.ClientService = clientService
+	e.ServerService = "db"
+	e.ClientLatencySec = 1.5
+	e.ServerLatencySec = 1.5
+	e.ClientEndTimestamp = pcommon.Timestamp(1000)
+	e.ServerStartTimestamp = pcommon.Timestamp(2000)
+	e.Dimensions["client_db.system"] = "postgresql"
+	e.Peer["db.name"] = "db"
+	e.Spans = ptrace.NewTraces()
+
+	data, err := encodeEdge(e)
+	require.NoError(t, err)
+	decoded, err := decodeEdge(key, data)
+	require.NoError(t, err)
+
+	decoded.expiration = e.expiration
+	assert.Equal(t, e, decoded)
+}
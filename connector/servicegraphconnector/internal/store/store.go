@@ -25,6 +25,10 @@ func (k *Key) SpanIDIsEmpty() bool {
 	return k.sid.IsEmpty()
 }
 
+func (k *Key) TraceID() pcommon.TraceID {
+	return k.tid
+}
+
 func NewKey(tid pcommon.TraceID, sid pcommon.SpanID) Key {
 	return Key{tid: tid, sid: sid}
 }
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector"
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
+	utilattri "github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
+)
+
+// cardinalityLimiter enforces the cardinality limits of a metric per service, whatever the
+// number of resources of the service.
+type cardinalityLimiter struct {
+	// seriesLimit is the maximum number of series of a service, 0 if unlimited.
+	seriesLimit int
+	dimensions  []utilattri.Dimension
+	// dimensionLimits are the maximum numbers of values of the dimensions of a service, 0 if unlimited.
+	dimensionLimits []int
+
+	clock    clockwork.Clock
+	services map[string]*serviceSeries
+}
+
+// serviceSeries are the series of a metric of a service, and the values of their dimensions
+// with a cardinality limit.
+type serviceSeries struct {
+	series          map[metrics.Key]struct{}
+	dimensionValues map[string]map[string]struct{}
+	// lastSeen is the last time a span of the service was recorded.
+	lastSeen time.Time
+}
+
+// newCardinalityLimiter returns the limiter of a metric with the given dimensions, or nil
+// if neither the series nor the dimensions are limited.
+func newCardinalityLimiter(seriesLimit int, cfgDims []Dimension, clock clockwork.Clock) *cardinalityLimiter {
+	limited := seriesLimit > 0
+	dimensionLimits := make([]int, len(cfgDims))
+	for i := range cfgDims {
+		dimensionLimits[i] = cfgDims[i].CardinalityLimit
+		limited = limited || dimensionLimits[i] > 0
+	}
+	if !limited {
+		return nil
+	}
+	return &cardinalityLimiter{
+		seriesLimit:     seriesLimit,
+		dimensions:      newDimensions(cfgDims),
+		dimensionLimits: dimensionLimits,
+		clock:           clock,
+		services:        make(map[string]*serviceSeries),
+	}
+}
+
+// isOverflow returns true if recording the span in a new series of the service exceeds the cardinality
+// limits, either the number of series or the number of values of one of the dimensions.
+// Otherwise, it tracks the series and the values of its dimensions.
+func (l *cardinalityLimiter) isOverflow(serviceName string, key metrics.Key, span ptrace.Span, resourceOrEventAttrs pcommon.Map) bool {
+	s, ok := l.services[serviceName]
+	if !ok {
+		s = &serviceSeries{
+			series:          make(map[metrics.Key]struct{}),
+			dimensionValues: make(map[string]map[string]struct{}),
+		}
+		l.services[serviceName] = s
+	}
+	s.lastSeen = l.clock.Now()
+	if _, ok = s.series[key]; ok {
+		return false
+	}
+	if l.seriesLimit > 0 && len(s.series) >= l.seriesLimit {
+		return true
+	}
+
+	var newValues [][2]string
+	for i, d := range l.dimensions {
+		if l.dimensionLimits[i] <= 0 {
+			continue
+		}
+		v, ok := utilattri.GetDimensionValue(d, span.Attributes(), resourceOrEventAttrs)
+		if !ok {
+			continue
+		}
+		values := s.dimensionValues[d.Name]
+		if _, ok := values[v.AsString()]; ok {
+			continue
+		}
+		if len(values) >= l.dimensionLimits[i] {
+			return true
+		}
+		newValues = append(newValues, [2]string{d.Name, v.AsString()})
+	}
+
+	s.series[key] = struct{}{}
+	for _, nv := range newValues {
+		values, ok := s.dimensionValues[nv[0]]
+		if !ok {
+			values = make(map[string]struct{})
+			s.dimensionValues[nv[0]] = values
+		}
+		values[nv[1]] = struct{}{}
+	}
+	return false
+}
+
+// retain forgets the series of the services other than the given ones.
+func (l *cardinalityLimiter) retain(services map[string]struct{}) {
+	for serviceName := range l.services {
+		if _, ok := services[serviceName]; !ok {
+			delete(l.services, serviceName)
+		}
+	}
+}
+
+// expire forgets the series of the services without spans for the expiration, if set, and of the least
+// recently seen services over maxServices.
+func (l *cardinalityLimiter) expire(expiration time.Duration, maxServices int) {
+	now := l.clock.Now()
+	names := make([]string, 0, len(l.services))
+	for serviceName, s := range l.services {
+		if expiration > 0 && now.Sub(s.lastSeen) >= expiration {
+			delete(l.services, serviceName)
+			continue
+		}
+		names = append(names, serviceName)
+	}
+	if len(names) <= maxServices {
+		return
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return l.services[names[i]].lastSeen.Before(l.services[names[j]].lastSeen)
+	})
+	for _, serviceName := range names[:len(names)-maxServices] {
+		delete(l.services, serviceName)
+	}
+}
@@ -29,6 +29,9 @@ var defaultDeltaTimestampCacheSize = 1000
 type Dimension struct {
 	Name    string  `mapstructure:"name"`
 	Default *string `mapstructure:"default"`
+	// CardinalityLimit is the maximum number of values of the Dimension per service. The spans with
+	// other values are recorded in the overflow series. Default value (0) means no limit.
+	CardinalityLimit int `mapstructure:"cardinality_limit"`
 }
 
 // Config defines the configuration options for spanmetricsconnector.
@@ -48,6 +51,11 @@ type Config struct {
 	// Optional. See defaultDimensionsCacheSize in connector.go for the default value.
 	DimensionsCacheSize int `mapstructure:"dimensions_cache_size"`
 
+	// AggregationCardinalityLimit is the maximum number of series of each metric per service. Once reached, the
+	// spans that would create new series are recorded in a single series with the `otel.metric.overflow` attribute.
+	// Default value (0) means no limit.
+	AggregationCardinalityLimit int `mapstructure:"aggregation_cardinality_limit"`
+
 	// ResourceMetricsCacheSize defines the size of the cache holding metrics for a service. This is mostly relevant for
 	// cumulative temporality to avoid memory leaks and correct metric timestamp resets.
 	// Optional. See defaultResourceMetricsCacheSize in connector.go for the default value.
@@ -132,6 +140,10 @@ func (c Config) Validate() error {
 		)
 	}
 
+	if c.AggregationCardinalityLimit < 0 {
+		return fmt.Errorf("invalid aggregation_cardinality_limit: %v, the limit should be positive", c.AggregationCardinalityLimit)
+	}
+
 	if c.Histogram.Explicit != nil && c.Histogram.Exponential != nil {
 		return errors.New("use either `explicit` or `exponential` buckets histogram")
 	}
@@ -181,6 +193,9 @@ func validateDimensions(dimensions []Dimension) error {
 		if _, ok := labelNames[key.Name]; ok {
 			return fmt.Errorf("duplicate dimension name %s", key.Name)
 		}
+		if key.CardinalityLimit < 0 {
+			return fmt.Errorf("invalid cardinality_limit of dimension %s: %v, the limit should be positive", key.Name, key.CardinalityLimit)
+		}
 		labelNames[key.Name] = struct{}{}
 	}
 
@@ -161,6 +161,23 @@ func TestLoadConfig(t *testing.T) {
 				assert.Equal(t, defaultDeltaTimestampCacheSize, config.GetDeltaTimestampCacheSize())
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "cardinality_limits"),
+			expected: &Config{
+				AggregationTemporality:      "AGGREGATION_TEMPORALITY_CUMULATIVE",
+				Dimensions:                  []Dimension{{Name: "http.route", CardinalityLimit: 100}},
+				DimensionsCacheSize:         defaultDimensionsCacheSize,
+				AggregationCardinalityLimit: 1000,
+				ResourceMetricsCacheSize:    defaultResourceMetricsCacheSize,
+				MetricsFlushInterval:        60 * time.Second,
+				Histogram:                   HistogramConfig{Disable: false, Unit: defaultUnit},
+				Namespace:                   DefaultNamespace,
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_aggregation_cardinality_limit"),
+			errorMessage: "invalid aggregation_cardinality_limit: -1, the limit should be positive",
+		},
 		{
 			id:           component.NewIDWithName(metadata.Type, "invalid_delta_timestamp_cache_size"),
 			errorMessage: "invalid delta timestamp cache size: 0, the maximum number of the items in the cache should be positive",
@@ -233,6 +250,13 @@ func TestValidateDimensions(t *testing.T) {
 			},
 			expectedErr: "duplicate dimension name service_name",
 		},
+		{
+			name: "negative cardinality limit",
+			dimensions: []Dimension{
+				{Name: "http.route", CardinalityLimit: -1},
+			},
+			expectedErr: "invalid cardinality_limit of dimension http.route: -1, the limit should be positive",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := validateDimensions(tc.dimensions)
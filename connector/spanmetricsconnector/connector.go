@@ -6,6 +6,7 @@ package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-
 import (
 	"bytes"
 	"context"
+	"slices"
 	"sync"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/cache"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
 	utilattri "github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
@@ -42,6 +44,11 @@ const (
 	metricNameEvents   = "events"
 
 	defaultUnit = metrics.Milliseconds
+
+	// overflowKey is the key of the series recording the spans over the cardinality limits,
+	// it starts with the separator so it can't be built from the dimensions of a span.
+	overflowKey          = metrics.Key(metricKeySeparator + overflowAttributeKey)
+	overflowAttributeKey = "otel.metric.overflow"
 )
 
 type connectorImp struct {
@@ -74,6 +81,16 @@ type connectorImp struct {
 	// Event dimensions to add to the events metric.
 	eDimensions []utilattri.Dimension
 
+	// The cardinality limiters of the calls and duration metrics, and of the events metric,
+	// nil when their cardinality isn't limited.
+	spansLimiter  *cardinalityLimiter
+	eventsLimiter *cardinalityLimiter
+
+	// The attributes of the series recording the spans over the cardinality limits.
+	overflowAttributes pcommon.Map
+
+	telemetryBuilder *metadata.TelemetryBuilder
+
 	events EventsConfig
 
 	// Tracks the last TimestampUnixNano for delta metrics so that they represent an uninterrupted series. Unused for cumulative span metrics.
@@ -106,10 +123,16 @@ func newDimensions(cfgDims []Dimension) []utilattri.Dimension {
 	return dims
 }
 
-func newConnector(logger *zap.Logger, config component.Config, clock clockwork.Clock) (*connectorImp, error) {
+func newConnector(set component.TelemetrySettings, config component.Config, clock clockwork.Clock) (*connectorImp, error) {
+	logger := set.Logger
 	logger.Info("Building spanmetrics connector")
 	cfg := config.(*Config)
 
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
+
 	metricKeyToDimensionsCache, err := cache.NewCache[metrics.Key, pcommon.Map](cfg.DimensionsCacheSize)
 	if err != nil {
 		return nil, err
@@ -136,6 +159,9 @@ func newConnector(logger *zap.Logger, config component.Config, clock clockwork.C
 		}
 	}
 
+	overflowAttributes := pcommon.NewMap()
+	overflowAttributes.PutBool(overflowAttributeKey, true)
+
 	return &connectorImp{
 		logger:                       logger,
 		config:                       *cfg,
@@ -149,7 +175,11 @@ func newConnector(logger *zap.Logger, config component.Config, clock clockwork.C
 		ticker:                       clock.NewTicker(cfg.MetricsFlushInterval),
 		done:                         make(chan struct{}),
 		eDimensions:                  newDimensions(cfg.Events.Dimensions),
+		spansLimiter:                 newCardinalityLimiter(cfg.AggregationCardinalityLimit, cfg.Dimensions, clock),
+		eventsLimiter:                newCardinalityLimiter(cfg.AggregationCardinalityLimit, append(slices.Clone(cfg.Dimensions), cfg.Events.Dimensions...), clock),
 		events:                       cfg.Events,
+		overflowAttributes:           overflowAttributes,
+		telemetryBuilder:             telemetryBuilder,
 	}, nil
 }
 
@@ -239,9 +269,9 @@ func (p *connectorImp) Capabilities() consumer.Capabilities {
 
 // ConsumeTraces implements the consumer.Traces interface.
 // It aggregates the trace data to generate metrics.
-func (p *connectorImp) ConsumeTraces(_ context.Context, traces ptrace.Traces) error {
+func (p *connectorImp) ConsumeTraces(ctx context.Context, traces ptrace.Traces) error {
 	p.lock.Lock()
-	p.aggregateMetrics(traces)
+	p.aggregateMetrics(ctx, traces)
 	p.lock.Unlock()
 	return nil
 }
@@ -251,6 +281,7 @@ func (p *connectorImp) exportMetrics(ctx context.Context) {
 
 	m := p.buildMetrics()
 	p.resetState()
+	p.resetCardinalityLimiters()
 
 	// This component no longer needs to read the metrics once built, so it is safe to unlock.
 	p.lock.Unlock()
@@ -367,7 +398,7 @@ func (p *connectorImp) resetState() {
 // Each metric is identified by a key that is built from the service name
 // and span metadata such as name, kind, status_code and any additional
 // dimensions the user has configured.
-func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
+func (p *connectorImp) aggregateMetrics(ctx context.Context, traces ptrace.Traces) {
 	startTimestamp := pcommon.NewTimestampFromTime(p.clock.Now())
 	for i := 0; i < traces.ResourceSpans().Len(); i++ {
 		rspans := traces.ResourceSpans().At(i)
@@ -399,8 +430,11 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 				}
 				key := p.buildKey(serviceName, span, p.dimensions, resourceAttr)
 
-				attributes, ok := p.metricKeyToDimensions.Get(key)
-				if !ok {
+				var attributes pcommon.Map
+				if p.spansLimiter != nil && p.spansLimiter.isOverflow(serviceName, key, span, resourceAttr) {
+					p.telemetryBuilder.ConnectorSpanmetricsOverflowedSpans.Add(ctx, 1)
+					key, attributes = overflowKey, p.overflowAttributes
+				} else if attributes, ok = p.metricKeyToDimensions.Get(key); !ok {
 					attributes = p.buildAttributes(serviceName, span, resourceAttr, p.dimensions)
 					p.metricKeyToDimensions.Add(key, attributes)
 				}
@@ -430,8 +464,11 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 						event.Attributes().CopyTo(rscAndEventAttrs)
 
 						eKey := p.buildKey(serviceName, span, eDimensions, rscAndEventAttrs)
-						eAttributes, ok := p.metricKeyToDimensions.Get(eKey)
-						if !ok {
+						var eAttributes pcommon.Map
+						if p.eventsLimiter != nil && p.eventsLimiter.isOverflow(serviceName, eKey, span, rscAndEventAttrs) {
+							p.telemetryBuilder.ConnectorSpanmetricsOverflowedSpans.Add(ctx, 1)
+							eKey, eAttributes = overflowKey, p.overflowAttributes
+						} else if eAttributes, ok = p.metricKeyToDimensions.Get(eKey); !ok {
 							eAttributes = p.buildAttributes(serviceName, span, rscAndEventAttrs, eDimensions)
 							p.metricKeyToDimensions.Add(eKey, eAttributes)
 						}
@@ -447,6 +484,38 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 	}
 }
 
+// resetCardinalityLimiters forgets the series of the services which are no longer tracked, so the cardinality
+// limits of a service are reset once it expires or is evicted. With cumulative temporality, these are the services
+// without resource metrics left. With delta temporality, as the resource metrics are purged on each flush, these
+// are the services without spans for the metrics expiration, or the least recently seen ones over the resource
+// metrics cache size, so that the limits apply across the flush intervals.
+func (p *connectorImp) resetCardinalityLimiters() {
+	if p.spansLimiter == nil && p.eventsLimiter == nil {
+		return
+	}
+	if p.config.GetAggregationTemporality() == pmetric.AggregationTemporalityDelta {
+		for _, limiter := range []*cardinalityLimiter{p.spansLimiter, p.eventsLimiter} {
+			if limiter != nil {
+				limiter.expire(p.config.MetricsExpiration, p.config.ResourceMetricsCacheSize)
+			}
+		}
+		return
+	}
+
+	services := map[string]struct{}{}
+	p.resourceMetrics.ForEach(func(_ resourceKey, m *resourceMetrics) {
+		if serviceAttr, ok := m.attributes.Get(conventions.AttributeServiceName); ok {
+			services[serviceAttr.Str()] = struct{}{}
+		}
+	})
+	if p.spansLimiter != nil {
+		p.spansLimiter.retain(services)
+	}
+	if p.eventsLimiter != nil {
+		p.eventsLimiter.retain(services)
+	}
+}
+
 func (p *connectorImp) addExemplar(span ptrace.Span, duration float64, h metrics.Histogram) {
 	if !p.config.Exemplars.Enabled {
 		return
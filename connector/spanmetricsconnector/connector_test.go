@@ -14,6 +14,7 @@ import (
 	"github.com/lightstep/go-expohisto/structure"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/connector/connectortest"
 	"go.opentelemetry.io/collector/consumer"
@@ -23,12 +24,15 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	conventions "go.opentelemetry.io/collector/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
 	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metadatatest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
 )
@@ -464,19 +468,19 @@ func newConnectorImp(defaultNullValue *string, histogramConfig func() HistogramC
 		ResourceMetricsKeyAttributes: resourceMetricsKeyAttributes,
 		Dimensions: []Dimension{
 			// Set nil defaults to force a lookup for the attribute in the span.
-			{stringAttrName, nil},
-			{intAttrName, nil},
-			{doubleAttrName, nil},
-			{boolAttrName, nil},
-			{mapAttrName, nil},
-			{arrayAttrName, nil},
-			{nullAttrName, defaultNullValue},
+			{Name: stringAttrName, Default: nil},
+			{Name: intAttrName, Default: nil},
+			{Name: doubleAttrName, Default: nil},
+			{Name: boolAttrName, Default: nil},
+			{Name: mapAttrName, Default: nil},
+			{Name: arrayAttrName, Default: nil},
+			{Name: nullAttrName, Default: defaultNullValue},
 			// Add a default value for an attribute that doesn't exist in a span
-			{notInSpanAttrName0, stringp("defaultNotInSpanAttrVal")},
+			{Name: notInSpanAttrName0, Default: stringp("defaultNotInSpanAttrVal")},
 			// Leave the default value unset to test that this dimension should not be added to the metric.
-			{notInSpanAttrName1, nil},
+			{Name: notInSpanAttrName1, Default: nil},
 			// Add a resource attribute to test "process" attributes like IP, host, region, cluster, etc.
-			{regionResourceAttrName, nil},
+			{Name: regionResourceAttrName, Default: nil},
 		},
 		Events:               eventsConfig(),
 		MetricsExpiration:    expiration,
@@ -484,7 +488,7 @@ func newConnectorImp(defaultNullValue *string, histogramConfig func() HistogramC
 		MetricsFlushInterval: time.Nanosecond,
 	}
 
-	c, err := newConnector(zap.NewNop(), cfg, clock)
+	c, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clock)
 	if err != nil {
 		return nil, err
 	}
@@ -492,6 +496,12 @@ func newConnectorImp(defaultNullValue *string, histogramConfig func() HistogramC
 	return c, nil
 }
 
+func newTestTelemetrySettings(t *testing.T) component.TelemetrySettings {
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zaptest.NewLogger(t)
+	return set
+}
+
 func stringp(str string) *string {
 	return &str
 }
@@ -499,7 +509,7 @@ func stringp(str string) *string {
 func TestBuildKeySameServiceNameCharSequence(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	span0 := ptrace.NewSpan()
@@ -519,7 +529,7 @@ func TestBuildKeyExcludeDimensionsAll(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.ExcludeDimensions = []string{"span.kind", "service.name", "span.name", "status.code"}
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	span0 := ptrace.NewSpan()
@@ -532,7 +542,7 @@ func TestBuildKeyExcludeWrongDimensions(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.ExcludeDimensions = []string{"span.kind", "service.name.wrong.name", "span.name", "status.code"}
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	span0 := ptrace.NewSpan()
@@ -544,7 +554,7 @@ func TestBuildKeyExcludeWrongDimensions(t *testing.T) {
 func TestBuildKeyWithDimensions(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	defaultFoo := pcommon.NewValueStr("bar")
@@ -685,7 +695,7 @@ func TestConnectorCapabilities(t *testing.T) {
 	cfg := factory.CreateDefaultConfig().(*Config)
 
 	// Test
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	// Override the default no-op consumer for testing.
 	c.metricsConsumer = new(consumertest.MetricsSink)
 	assert.NoError(t, err)
@@ -1509,7 +1519,7 @@ func TestSpanMetrics_Events(t *testing.T) {
 			factory := NewFactory()
 			cfg := factory.CreateDefaultConfig().(*Config)
 			cfg.Events = tt.eventsConfig
-			c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+			c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 			require.NoError(t, err)
 			err = c.ConsumeTraces(context.Background(), buildSampleTrace())
 			require.NoError(t, err)
@@ -1875,3 +1885,223 @@ func (c alwaysIncreasingClock) Now() time.Time {
 	c.Clock.(clockwork.FakeClock).Advance(time.Millisecond)
 	return c.Clock.Now()
 }
+
+func TestCardinalityLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		aggregationLimit int
+		dimensionLimit   int
+	}{
+		{
+			name:             "aggregation cardinality limit",
+			aggregationLimit: 3,
+		},
+		{
+			name:           "dimension cardinality limit",
+			dimensionLimit: 3,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tel := componenttest.NewTelemetry()
+			defer func() { require.NoError(t, tel.Shutdown(context.Background())) }()
+
+			cfg := NewFactory().CreateDefaultConfig().(*Config)
+			cfg.AggregationCardinalityLimit = tc.aggregationLimit
+			cfg.Dimensions = []Dimension{{Name: "http.route", CardinalityLimit: tc.dimensionLimit}}
+			p, err := newConnector(tel.NewTelemetrySettings(), cfg, clockwork.NewFakeClock())
+			require.NoError(t, err)
+
+			// 5 routes, the first one twice
+			traces := ptrace.NewTraces()
+			rs := traces.ResourceSpans().AppendEmpty()
+			rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "service-a")
+			spans := rs.ScopeSpans().AppendEmpty().Spans()
+			for _, route := range []string{"/0", "/1", "/2", "/3", "/4", "/0"} {
+				span := spans.AppendEmpty()
+				span.SetName("GET " + route)
+				span.SetKind(ptrace.SpanKindServer)
+				span.Attributes().PutStr("http.route", route)
+			}
+			require.NoError(t, p.ConsumeTraces(context.Background(), traces))
+
+			calls := p.buildMetrics().ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+			dps := calls.Sum().DataPoints()
+			require.Equal(t, 4, dps.Len())
+			counts := map[string]int64{}
+			for i := 0; i < dps.Len(); i++ {
+				dp := dps.At(i)
+				if overflow, ok := dp.Attributes().Get(overflowAttributeKey); ok {
+					assert.True(t, overflow.Bool())
+					assert.Equal(t, 1, dp.Attributes().Len())
+					counts[overflowAttributeKey] = dp.IntValue()
+					continue
+				}
+				route, ok := dp.Attributes().Get("http.route")
+				require.True(t, ok)
+				counts[route.Str()] = dp.IntValue()
+			}
+			assert.Equal(t, map[string]int64{"/0": 2, "/1": 1, "/2": 1, overflowAttributeKey: 2}, counts)
+
+			metadatatest.AssertEqualConnectorSpanmetricsOverflowedSpans(t, tel,
+				[]metricdata.DataPoint[int64]{{Value: 2}},
+				metricdatatest.IgnoreTimestamp())
+		})
+	}
+}
+
+// sumDataPoints returns the data points of the sum metric of the given name, by the value of
+// the attribute, or by overflowAttributeKey for the overflow series.
+func sumDataPoints(t *testing.T, md pmetric.Metrics, name string, attribute string) map[string]int64 {
+	counts := map[string]int64{}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		ms := md.ResourceMetrics().At(i).ScopeMetrics().At(0).Metrics()
+		for j := 0; j < ms.Len(); j++ {
+			if ms.At(j).Name() != buildMetricName(DefaultNamespace, name) {
+				continue
+			}
+			dps := ms.At(j).Sum().DataPoints()
+			for k := 0; k < dps.Len(); k++ {
+				dp := dps.At(k)
+				if _, ok := dp.Attributes().Get(overflowAttributeKey); ok {
+					counts[overflowAttributeKey] += dp.IntValue()
+					continue
+				}
+				v, ok := dp.Attributes().Get(attribute)
+				require.True(t, ok)
+				counts[v.Str()] += dp.IntValue()
+			}
+		}
+	}
+	return counts
+}
+
+func TestCardinalityLimitsPerService(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.AggregationCardinalityLimit = 2
+	cfg.Dimensions = []Dimension{{Name: "http.route"}}
+	p, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	// The instances of a service are different resources sharing the limit of the service
+	traces := ptrace.NewTraces()
+	for i, routes := range [][]string{{"/0", "/1"}, {"/0", "/2"}} {
+		rs := traces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "service-a")
+		rs.Resource().Attributes().PutInt(conventions.AttributeServiceInstanceID, int64(i))
+		spans := rs.ScopeSpans().AppendEmpty().Spans()
+		for _, route := range routes {
+			span := spans.AppendEmpty()
+			span.SetName("GET")
+			span.Attributes().PutStr("http.route", route)
+		}
+	}
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "service-b")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("GET")
+	span.Attributes().PutStr("http.route", "/2")
+	require.NoError(t, p.ConsumeTraces(context.Background(), traces))
+
+	assert.Equal(t, map[string]int64{"/0": 2, "/1": 1, "/2": 1, overflowAttributeKey: 1},
+		sumDataPoints(t, p.buildMetrics(), metricNameCalls, "http.route"))
+
+	// The limits of a service are reset once its resource metrics are evicted
+	p.resourceMetrics.Purge()
+	p.resetCardinalityLimiters()
+	assert.Empty(t, p.spansLimiter.services)
+}
+
+func TestCardinalityLimitsDelta(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.AggregationTemporality = delta
+	cfg.AggregationCardinalityLimit = 1
+	cfg.MetricsExpiration = time.Hour
+	cfg.ResourceMetricsCacheSize = 1
+	cfg.Dimensions = []Dimension{{Name: "http.route"}}
+	clock := clockwork.NewFakeClock()
+	p, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clock)
+	require.NoError(t, err)
+
+	consume := func(serviceName, route string) {
+		traces := ptrace.NewTraces()
+		rs := traces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, serviceName)
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetName("GET")
+		span.Attributes().PutStr("http.route", route)
+		require.NoError(t, p.ConsumeTraces(context.Background(), traces))
+	}
+	flush := func() map[string]int64 {
+		md := p.buildMetrics()
+		p.resetState()
+		p.resetCardinalityLimiters()
+		return sumDataPoints(t, md, metricNameCalls, "http.route")
+	}
+
+	consume("service-a", "/0")
+	assert.Equal(t, map[string]int64{"/0": 1}, flush())
+
+	// The limits apply across the flush intervals, although the metrics are purged
+	consume("service-a", "/1")
+	consume("service-a", "/0")
+	assert.Equal(t, map[string]int64{"/0": 1, overflowAttributeKey: 1}, flush())
+
+	// The limits of a service are reset once it expires
+	clock.Advance(time.Hour)
+	flush()
+	assert.Empty(t, p.spansLimiter.services)
+	consume("service-a", "/1")
+	assert.Equal(t, map[string]int64{"/1": 1}, flush())
+
+	// or once it is evicted by a more recently seen service
+	clock.Advance(time.Minute)
+	consume("service-b", "/0")
+	flush()
+	assert.Contains(t, p.spansLimiter.services, "service-b")
+	assert.NotContains(t, p.spansLimiter.services, "service-a")
+}
+
+func TestEventsCardinalityLimits(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	defer func() { require.NoError(t, tel.Shutdown(context.Background())) }()
+
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Dimensions = []Dimension{{Name: "http.route", CardinalityLimit: 2}}
+	cfg.Events = EventsConfig{Enabled: true, Dimensions: []Dimension{{Name: "exception.type", CardinalityLimit: 1}}}
+	p, err := newConnector(tel.NewTelemetrySettings(), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "service-a")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	for _, tc := range []struct {
+		route      string
+		exceptions []string
+	}{
+		{route: "/0", exceptions: []string{"IOException"}},
+		{route: "/1"},
+		{route: "/2", exceptions: []string{"IOException", "TimeoutException"}},
+	} {
+		span := spans.AppendEmpty()
+		span.SetName("GET")
+		span.Attributes().PutStr("http.route", tc.route)
+		for _, exception := range tc.exceptions {
+			span.Events().AppendEmpty().Attributes().PutStr("exception.type", exception)
+		}
+	}
+	require.NoError(t, p.ConsumeTraces(context.Background(), traces))
+
+	md := p.buildMetrics()
+	assert.Equal(t, map[string]int64{"/0": 1, "/1": 1, overflowAttributeKey: 1},
+		sumDataPoints(t, md, metricNameCalls, "http.route"))
+	// The values of the span dimensions of the events metric are limited separately from the ones of
+	// the calls metric, so the /2 route isn't in overflow for the events metric
+	assert.Equal(t, map[string]int64{"IOException": 2, overflowAttributeKey: 1},
+		sumDataPoints(t, md, metricNameEvents, "exception.type"))
+
+	// Both the span and the event in the overflow series are counted
+	metadatatest.AssertEqualConnectorSpanmetricsOverflowedSpans(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 2}},
+		metricdatatest.IgnoreTimestamp())
+}
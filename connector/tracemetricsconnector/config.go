@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracemetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+)
+
+var (
+	defaultDurationBuckets = []time.Duration{
+		10 * time.Millisecond,
+		25 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2500 * time.Millisecond,
+		5 * time.Second,
+		10 * time.Second,
+		30 * time.Second,
+		time.Minute,
+	}
+	defaultSpanCountBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+)
+
+// Dimension defines the dimension name and optional default value if the Dimension is missing from the
+// attributes of the root span and its resource.
+type Dimension struct {
+	Name    string  `mapstructure:"name"`
+	Default *string `mapstructure:"default"`
+}
+
+// Config defines the configuration options for the tracemetrics connector.
+type Config struct {
+	// WaitDuration is the time to wait for the spans of a trace after its first span was received,
+	// before the metrics of the trace are computed.
+	WaitDuration time.Duration `mapstructure:"wait_duration"`
+	// NumTraces is the maximum number of traces waiting for their spans. When it's reached, the metrics
+	// of the oldest trace are computed right away.
+	NumTraces int `mapstructure:"num_traces"`
+	// MetricsFlushInterval is the interval at which the metrics are emitted.
+	MetricsFlushInterval time.Duration `mapstructure:"metrics_flush_interval"`
+	// DurationBuckets are the explicit bucket boundaries of the duration histograms.
+	DurationBuckets []time.Duration `mapstructure:"duration_buckets"`
+	// SpanCountBuckets are the explicit bucket boundaries of the span count histogram.
+	SpanCountBuckets []float64 `mapstructure:"span_count_buckets"`
+	// Dimensions defines the list of additional dimensions on top of the provided:
+	// - service.name
+	// - span.name
+	// - trace.error
+	// The dimensions will be fetched from the root span's attributes, then from its resource attributes.
+	Dimensions []Dimension `mapstructure:"dimensions"`
+	// MetricsExpiration is the time after which the series not updated by any trace are removed, and no longer
+	// emitted. Default value (0) means that the series never expire.
+	MetricsExpiration time.Duration `mapstructure:"metrics_expiration"`
+	// AggregationCardinalityLimit is the maximum number of series of each metric. Once reached, the traces that
+	// would create new series are recorded in a single series with the `otel.metric.overflow` attribute.
+	// Default value (0) means no limit.
+	AggregationCardinalityLimit int `mapstructure:"aggregation_cardinality_limit"`
+}
+
+var _ xconfmap.Validator = (*Config)(nil)
+
+// Validate checks if the connector configuration is valid
+func (c Config) Validate() error {
+	if c.WaitDuration <= 0 {
+		return errors.New("wait_duration must be positive")
+	}
+	if c.NumTraces <= 0 {
+		return errors.New("num_traces must be positive")
+	}
+	if c.MetricsFlushInterval <= 0 {
+		return errors.New("metrics_flush_interval must be positive")
+	}
+	if !slices.IsSorted(c.DurationBuckets) {
+		return errors.New("duration_buckets must be sorted in ascending order")
+	}
+	if !slices.IsSorted(c.SpanCountBuckets) {
+		return errors.New("span_count_buckets must be sorted in ascending order")
+	}
+	if c.MetricsExpiration < 0 {
+		return errors.New("metrics_expiration must not be negative")
+	}
+	if c.AggregationCardinalityLimit < 0 {
+		return errors.New("aggregation_cardinality_limit must not be negative")
+	}
+	return validateDimensions(c.Dimensions)
+}
+
+// validateDimensions checks duplicates for reserved dimensions and additional dimensions.
+func validateDimensions(dimensions []Dimension) error {
+	labelNames := make(map[string]struct{})
+	for _, key := range []string{serviceNameKey, spanNameKey, traceErrorKey, criticalPathServiceNameKey} {
+		labelNames[key] = struct{}{}
+	}
+
+	for _, key := range dimensions {
+		if _, ok := labelNames[key.Name]; ok {
+			return fmt.Errorf("duplicate dimension name %q", key.Name)
+		}
+		labelNames[key.Name] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracemetricsconnector
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	defaultEnvironment := "unknown"
+	tests := []struct {
+		id       component.ID
+		expected component.Config
+	}{
+		{
+			id:       component.NewIDWithName(metadata.Type, "default"),
+			expected: createDefaultConfig(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: &Config{
+				WaitDuration:         30 * time.Second,
+				NumTraces:            1000,
+				MetricsFlushInterval: time.Minute,
+				DurationBuckets:      []time.Duration{100 * time.Millisecond, time.Second, 10 * time.Second},
+				SpanCountBuckets:     []float64{1, 10, 100},
+				Dimensions: []Dimension{
+					{Name: "http.route"},
+					{Name: "deployment.environment", Default: &defaultEnvironment},
+				},
+				MetricsExpiration:           5 * time.Minute,
+				AggregationCardinalityLimit: 1000,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "wait duration",
+			modify:      func(cfg *Config) { cfg.WaitDuration = 0 },
+			expectedErr: "wait_duration must be positive",
+		},
+		{
+			name:        "num traces",
+			modify:      func(cfg *Config) { cfg.NumTraces = 0 },
+			expectedErr: "num_traces must be positive",
+		},
+		{
+			name:        "metrics flush interval",
+			modify:      func(cfg *Config) { cfg.MetricsFlushInterval = 0 },
+			expectedErr: "metrics_flush_interval must be positive",
+		},
+		{
+			name:        "unsorted duration buckets",
+			modify:      func(cfg *Config) { cfg.DurationBuckets = []time.Duration{time.Second, time.Millisecond} },
+			expectedErr: "duration_buckets must be sorted in ascending order",
+		},
+		{
+			name:        "unsorted span count buckets",
+			modify:      func(cfg *Config) { cfg.SpanCountBuckets = []float64{10, 1} },
+			expectedErr: "span_count_buckets must be sorted in ascending order",
+		},
+		{
+			name:        "negative metrics expiration",
+			modify:      func(cfg *Config) { cfg.MetricsExpiration = -time.Second },
+			expectedErr: "metrics_expiration must not be negative",
+		},
+		{
+			name:        "negative aggregation cardinality limit",
+			modify:      func(cfg *Config) { cfg.AggregationCardinalityLimit = -1 },
+			expectedErr: "aggregation_cardinality_limit must not be negative",
+		},
+		{
+			name:        "duplicate dimension with reserved labels",
+			modify:      func(cfg *Config) { cfg.Dimensions = []Dimension{{Name: "trace.error"}} },
+			expectedErr: `duplicate dimension name "trace.error"`,
+		},
+		{
+			name:        "duplicate additional dimensions",
+			modify:      func(cfg *Config) { cfg.Dimensions = []Dimension{{Name: "http.route"}, {Name: "http.route"}} },
+			expectedErr: `duplicate dimension name "http.route"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.EqualError(t, xconfmap.Validate(cfg), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracemetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector"
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.27.0"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
+)
+
+const (
+	serviceNameKey             = conventions.AttributeServiceName
+	spanNameKey                = "span.name"
+	traceErrorKey              = "trace.error"
+	criticalPathServiceNameKey = "critical_path.service.name"
+
+	metricNameDuration             = "traces.duration"
+	metricNameSpanCount            = "traces.span_count"
+	metricNameCriticalPathDuration = "traces.critical_path.duration"
+
+	metricKeySeparator = string(byte(0))
+
+	// overflowKey is the key of the series recording the traces over the cardinality limit,
+	// which can't collide with the keys of the other series.
+	overflowKey          = metricKeySeparator + overflowAttributeKey
+	overflowAttributeKey = "otel.metric.overflow"
+)
+
+type connectorImp struct {
+	lock   sync.Mutex
+	logger *zap.Logger
+	config Config
+	now    func() time.Time
+
+	metricsConsumer consumer.Metrics
+
+	// Additional dimensions to add to metrics.
+	dimensions      []pdatautil.Dimension
+	durationBounds  []float64
+	spanCountBounds []float64
+
+	// traces are the traces waiting for their spans, ordered by the arrival of their first span.
+	traces map[pcommon.TraceID]*list.Element
+	order  *list.List
+
+	durations            map[string]*histogram
+	spanCounts           map[string]*histogram
+	criticalPathDuration map[string]*histogram
+
+	keyBuf *bytes.Buffer
+
+	started      bool
+	done         chan struct{}
+	wg           sync.WaitGroup
+	shutdownOnce sync.Once
+}
+
+// traceEntry holds the information of the spans of a trace needed to compute its metrics.
+type traceEntry struct {
+	traceID    pcommon.TraceID
+	spans      []spanInfo
+	root       *rootSpan
+	start      pcommon.Timestamp
+	end        pcommon.Timestamp
+	hasError   bool
+	expiration time.Time
+}
+
+type spanInfo struct {
+	spanID       pcommon.SpanID
+	parentSpanID pcommon.SpanID
+	serviceName  string
+	start        pcommon.Timestamp
+	end          pcommon.Timestamp
+}
+
+// rootSpan holds the dimensions of the entry point of a trace.
+type rootSpan struct {
+	index       int
+	serviceName string
+	name        string
+	dimensions  pcommon.Map
+}
+
+func newConnector(logger *zap.Logger, config *Config, now func() time.Time) *connectorImp {
+	durationBounds := make([]float64, len(config.DurationBuckets))
+	for i, b := range config.DurationBuckets {
+		durationBounds[i] = b.Seconds()
+	}
+
+	return &connectorImp{
+		logger:               logger,
+		config:               *config,
+		now:                  now,
+		dimensions:           newDimensions(config.Dimensions),
+		durationBounds:       durationBounds,
+		spanCountBounds:      config.SpanCountBuckets,
+		traces:               make(map[pcommon.TraceID]*list.Element),
+		order:                list.New(),
+		durations:            make(map[string]*histogram),
+		spanCounts:           make(map[string]*histogram),
+		criticalPathDuration: make(map[string]*histogram),
+		keyBuf:               bytes.NewBuffer(make([]byte, 0, 1024)),
+		done:                 make(chan struct{}),
+	}
+}
+
+func newDimensions(cfgDims []Dimension) []pdatautil.Dimension {
+	if len(cfgDims) == 0 {
+		return nil
+	}
+	dims := make([]pdatautil.Dimension, len(cfgDims))
+	for i := range cfgDims {
+		dims[i].Name = cfgDims[i].Name
+		if cfgDims[i].Default != nil {
+			val := pcommon.NewValueStr(*cfgDims[i].Default)
+			dims[i].Value = &val
+		}
+	}
+	return dims
+}
+
+// Start implements the component.Component interface.
+func (c *connectorImp) Start(context.Context, component.Host) error {
+	c.started = true
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		expireTicker := time.NewTicker(min(c.config.WaitDuration, time.Second))
+		defer expireTicker.Stop()
+		flushTicker := time.NewTicker(c.config.MetricsFlushInterval)
+		defer flushTicker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-expireTicker.C:
+				c.expireTraces(false)
+			case <-flushTicker.C:
+				c.exportMetrics(context.Background())
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown implements the component.Component interface. The metrics of the traces still waiting
+// for their spans are computed and emitted.
+func (c *connectorImp) Shutdown(ctx context.Context) error {
+	c.shutdownOnce.Do(func() {
+		if !c.started {
+			return
+		}
+		close(c.done)
+		c.wg.Wait()
+		c.expireTraces(true)
+		c.exportMetrics(ctx)
+	})
+	return nil
+}
+
+// Capabilities implements the consumer interface.
+func (c *connectorImp) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeTraces implements the consumer.Traces interface.
+// It buffers the spans by trace until the metrics of the traces are computed.
+func (c *connectorImp) ConsumeTraces(_ context.Context, traces ptrace.Traces) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i := 0; i < traces.ResourceSpans().Len(); i++ {
+		rspans := traces.ResourceSpans().At(i)
+		resourceAttrs := rspans.Resource().Attributes()
+		serviceName, _ := pdatautil.GetAttributeValue(serviceNameKey, resourceAttrs)
+		ilsSlice := rspans.ScopeSpans()
+		for j := 0; j < ilsSlice.Len(); j++ {
+			spans := ilsSlice.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				c.addSpan(serviceName, spans.At(k), resourceAttrs)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *connectorImp) addSpan(serviceName string, span ptrace.Span, resourceAttrs pcommon.Map) {
+	entry := c.getOrCreateTrace(span.TraceID())
+
+	if len(entry.spans) == 0 || span.StartTimestamp() < entry.start {
+		entry.start = span.StartTimestamp()
+	}
+	entry.end = max(entry.end, span.EndTimestamp())
+	entry.hasError = entry.hasError || span.Status().Code() == ptrace.StatusCodeError
+
+	if span.ParentSpanID().IsEmpty() && entry.root == nil {
+		entry.root = &rootSpan{
+			index:       len(entry.spans),
+			serviceName: serviceName,
+			name:        span.Name(),
+			dimensions:  pcommon.NewMap(),
+		}
+		for _, d := range c.dimensions {
+			if v, ok := pdatautil.GetDimensionValue(d, span.Attributes(), resourceAttrs); ok {
+				v.CopyTo(entry.root.dimensions.PutEmpty(d.Name))
+			}
+		}
+	}
+
+	entry.spans = append(entry.spans, spanInfo{
+		spanID:       span.SpanID(),
+		parentSpanID: span.ParentSpanID(),
+		serviceName:  serviceName,
+		start:        span.StartTimestamp(),
+		end:          span.EndTimestamp(),
+	})
+}
+
+// getOrCreateTrace returns the trace waiting for its spans, or creates it. When the maximum number of
+// traces is reached, the metrics of the oldest trace are computed right away.
+func (c *connectorImp) getOrCreateTrace(traceID pcommon.TraceID) *traceEntry {
+	if ele, ok := c.traces[traceID]; ok {
+		return ele.Value.(*traceEntry)
+	}
+
+	if c.order.Len() >= c.config.NumTraces {
+		c.logger.Debug("Too many traces waiting for their spans, computing the oldest one early")
+		c.recordTrace(c.removeTrace(c.order.Front()))
+	}
+
+	entry := &traceEntry{traceID: traceID, expiration: c.now().Add(c.config.WaitDuration)}
+	c.traces[traceID] = c.order.PushBack(entry)
+	return entry
+}
+
+func (c *connectorImp) removeTrace(ele *list.Element) *traceEntry {
+	entry := c.order.Remove(ele).(*traceEntry)
+	delete(c.traces, entry.traceID)
+	return entry
+}
+
+// expireTraces computes the metrics of the traces whose wait duration is over, or of all of them.
+func (c *connectorImp) expireTraces(all bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	for ele := c.order.Front(); ele != nil; ele = c.order.Front() {
+		if !all && now.Before(ele.Value.(*traceEntry).expiration) {
+			return
+		}
+		c.recordTrace(c.removeTrace(ele))
+	}
+}
+
+// recordTrace records the metrics of a trace. The traces without a root span are dropped, as
+// their entry point is unknown.
+func (c *connectorImp) recordTrace(entry *traceEntry) {
+	if entry.root == nil {
+		c.logger.Debug("Dropping trace without root span", zap.Stringer("trace_id", entry.traceID))
+		return
+	}
+	root := entry.root
+
+	c.keyBuf.Reset()
+	c.keyBuf.WriteString(root.serviceName)
+	c.keyBuf.WriteString(metricKeySeparator)
+	c.keyBuf.WriteString(root.name)
+	c.keyBuf.WriteString(metricKeySeparator)
+	c.keyBuf.WriteString(strconv.FormatBool(entry.hasError))
+	for _, d := range c.dimensions {
+		c.keyBuf.WriteString(metricKeySeparator)
+		if v, ok := root.dimensions.Get(d.Name); ok {
+			c.keyBuf.WriteString(v.AsString())
+		}
+	}
+	key := c.keyBuf.String()
+
+	var duration float64
+	if entry.end > entry.start {
+		duration = time.Duration(entry.end - entry.start).Seconds()
+	}
+	c.getOrCreateHistogram(c.durations, key, c.durationBounds, entry, "").observe(duration)
+	c.getOrCreateHistogram(c.spanCounts, key, c.spanCountBounds, entry, "").observe(float64(len(entry.spans)))
+
+	for serviceName, d := range criticalPath(entry.spans, root.index) {
+		serviceKey := key + metricKeySeparator + serviceName
+		c.getOrCreateHistogram(c.criticalPathDuration, serviceKey, c.durationBounds, entry, serviceName).observe(d.Seconds())
+	}
+}
+
+// getOrCreateHistogram returns the histogram of the series with the given key, or creates it. Once the cardinality
+// limit of the metric is reached, the overflow series is returned instead of creating a new series.
+func (c *connectorImp) getOrCreateHistogram(series map[string]*histogram, key string, bounds []float64, entry *traceEntry, criticalPathService string) *histogram {
+	h, ok := series[key]
+	if !ok && c.isOverflow(series) {
+		key = overflowKey
+		h, ok = series[key]
+	}
+	if ok {
+		h.lastUpdated = c.now()
+		return h
+	}
+
+	attrs := pcommon.NewMap()
+	if key == overflowKey {
+		attrs.PutBool(overflowAttributeKey, true)
+	} else {
+		attrs.PutStr(serviceNameKey, entry.root.serviceName)
+		attrs.PutStr(spanNameKey, entry.root.name)
+		attrs.PutBool(traceErrorKey, entry.hasError)
+		entry.root.dimensions.Range(func(k string, v pcommon.Value) bool {
+			v.CopyTo(attrs.PutEmpty(k))
+			return true
+		})
+		if criticalPathService != "" {
+			attrs.PutStr(criticalPathServiceNameKey, criticalPathService)
+		}
+	}
+
+	h = &histogram{
+		attrs:          attrs,
+		bounds:         bounds,
+		bucketCounts:   make([]uint64, len(bounds)+1),
+		startTimestamp: pcommon.NewTimestampFromTime(c.now()),
+		lastUpdated:    c.now(),
+	}
+	series[key] = h
+	return h
+}
+
+// isOverflow returns true if the metric has as many series as the cardinality limit, not counting the
+// overflow series.
+func (c *connectorImp) isOverflow(series map[string]*histogram) bool {
+	if c.config.AggregationCardinalityLimit <= 0 {
+		return false
+	}
+	n := len(series)
+	if _, ok := series[overflowKey]; ok {
+		n--
+	}
+	return n >= c.config.AggregationCardinalityLimit
+}
+
+// removeExpiredSeries removes the series which weren't updated by any trace during the metrics expiration.
+func (c *connectorImp) removeExpiredSeries(now time.Time) {
+	if c.config.MetricsExpiration <= 0 {
+		return
+	}
+	for _, series := range []map[string]*histogram{c.durations, c.spanCounts, c.criticalPathDuration} {
+		for key, h := range series {
+			if now.Sub(h.lastUpdated) >= c.config.MetricsExpiration {
+				delete(series, key)
+			}
+		}
+	}
+}
+
+func (c *connectorImp) exportMetrics(ctx context.Context) {
+	c.lock.Lock()
+	now := c.now()
+	c.removeExpiredSeries(now)
+	if len(c.durations) == 0 {
+		c.lock.Unlock()
+		return
+	}
+
+	m := pmetric.NewMetrics()
+	ilm := m.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	ilm.Scope().SetName(metadata.ScopeName)
+
+	timestamp := pcommon.NewTimestampFromTime(now)
+	c.collectHistograms(ilm, metricNameDuration, "s", c.durations, timestamp)
+	c.collectHistograms(ilm, metricNameSpanCount, "{span}", c.spanCounts, timestamp)
+	c.collectHistograms(ilm, metricNameCriticalPathDuration, "s", c.criticalPathDuration, timestamp)
+
+	// This component no longer needs to read the metrics once built, so it is safe to unlock.
+	c.lock.Unlock()
+
+	if err := c.metricsConsumer.ConsumeMetrics(ctx, m); err != nil {
+		c.logger.Error("Failed ConsumeMetrics", zap.Error(err))
+	}
+}
+
+// collectHistograms writes the cumulative histograms of a metric into the metrics object.
+func (c *connectorImp) collectHistograms(ilm pmetric.ScopeMetrics, name, unit string, series map[string]*histogram, timestamp pcommon.Timestamp) {
+	if len(series) == 0 {
+		return
+	}
+
+	m := ilm.Metrics().AppendEmpty()
+	m.SetName(name)
+	m.SetUnit(unit)
+	hist := m.SetEmptyHistogram()
+	hist.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dps := hist.DataPoints()
+	dps.EnsureCapacity(len(keys))
+	for _, key := range keys {
+		h := series[key]
+		dp := dps.AppendEmpty()
+		dp.SetStartTimestamp(h.startTimestamp)
+		dp.SetTimestamp(timestamp)
+		dp.ExplicitBounds().FromRaw(h.bounds)
+		dp.BucketCounts().FromRaw(h.bucketCounts)
+		dp.SetCount(h.count)
+		dp.SetSum(h.sum)
+		h.attrs.CopyTo(dp.Attributes())
+	}
+}
+
+// histogram is a cumulative explicit bucket histogram.
+type histogram struct {
+	attrs        pcommon.Map
+	bounds       []float64
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+
+	// startTimestamp is the time the series was created, so it's reset when the series expires.
+	startTimestamp pcommon.Timestamp
+	// lastUpdated is the last time a trace was recorded in the series.
+	lastUpdated time.Time
+}
+
+func (h *histogram) observe(value float64) {
+	// Binary search to find the value bucket index.
+	index := sort.SearchFloat64s(h.bounds, value)
+	h.bucketCounts[index]++
+	h.count++
+	h.sum += value
+}
@@ -0,0 +1,343 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracemetricsconnector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	traceID1 = pcommon.TraceID([16]byte{1})
+	traceID2 = pcommon.TraceID([16]byte{2})
+)
+
+type testSpan struct {
+	traceID    pcommon.TraceID
+	spanID     byte
+	parentID   byte
+	name       string
+	start, end time.Duration
+	failed     bool
+	attrs      map[string]string
+}
+
+// buildTraces builds the spans of a service, with their timestamps relative to the unix epoch.
+func buildTraces(serviceName string, spans ...testSpan) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(serviceNameKey, serviceName)
+	ss := rs.ScopeSpans().AppendEmpty()
+	for _, s := range spans {
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(s.traceID)
+		span.SetSpanID(pcommon.SpanID([8]byte{s.spanID}))
+		if s.parentID != 0 {
+			span.SetParentSpanID(pcommon.SpanID([8]byte{s.parentID}))
+		}
+		span.SetName(s.name)
+		span.SetStartTimestamp(pcommon.Timestamp(s.start))
+		span.SetEndTimestamp(pcommon.Timestamp(s.end))
+		if s.failed {
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+		for k, v := range s.attrs {
+			span.Attributes().PutStr(k, v)
+		}
+	}
+	return traces
+}
+
+// consumeCheckoutTrace sends the spans of the following trace, received from each service separately:
+//
+//	frontend GET /checkout [0, 100ms]
+//	  frontend call backend [10ms, 90ms]
+//	    backend checkout [15ms, 85ms] (error)
+//	      db query [20ms, 60ms]
+func consumeCheckoutTrace(t *testing.T, c *connectorImp, traceID pcommon.TraceID) {
+	ms := time.Millisecond
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildTraces("db",
+		testSpan{traceID: traceID, spanID: 4, parentID: 3, name: "query", start: 20 * ms, end: 60 * ms},
+	)))
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildTraces("backend",
+		testSpan{traceID: traceID, spanID: 3, parentID: 2, name: "checkout", start: 15 * ms, end: 85 * ms, failed: true},
+	)))
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildTraces("frontend",
+		testSpan{traceID: traceID, spanID: 1, name: "GET /checkout", start: 0, end: 100 * ms, attrs: map[string]string{"http.route": "/checkout"}},
+		testSpan{traceID: traceID, spanID: 2, parentID: 1, name: "call backend", start: 10 * ms, end: 90 * ms},
+	)))
+}
+
+type testConnector struct {
+	*connectorImp
+	sink *consumertest.MetricsSink
+	now  time.Time
+}
+
+func newTestConnector(t *testing.T, modify func(cfg *Config)) *testConnector {
+	cfg := createDefaultConfig().(*Config)
+	if modify != nil {
+		modify(cfg)
+	}
+	tc := &testConnector{sink: new(consumertest.MetricsSink), now: time.Unix(1000, 0)}
+	tc.connectorImp = newConnector(zaptest.NewLogger(t), cfg, func() time.Time { return tc.now })
+	tc.metricsConsumer = tc.sink
+	return tc
+}
+
+func (tc *testConnector) lastMetrics(t *testing.T) pmetric.MetricSlice {
+	all := tc.sink.AllMetrics()
+	require.NotEmpty(t, all)
+	return all[len(all)-1].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+}
+
+func findMetric(t *testing.T, metrics pmetric.MetricSlice, name string) pmetric.HistogramDataPointSlice {
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i).Histogram().DataPoints()
+		}
+	}
+	require.Failf(t, "metric not found", "metric %s not found", name)
+	return pmetric.NewHistogramDataPointSlice()
+}
+
+func attrStr(dp pmetric.HistogramDataPoint, key string) string {
+	v, _ := dp.Attributes().Get(key)
+	return v.AsString()
+}
+
+func TestConnectorTraceMetrics(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.Dimensions = []Dimension{{Name: "http.route"}}
+	})
+	consumeCheckoutTrace(t, c.connectorImp, traceID1)
+
+	// The trace is waiting for its spans
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+	assert.Empty(t, c.sink.AllMetrics())
+
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+	metrics := c.lastMetrics(t)
+	require.Equal(t, 3, metrics.Len())
+
+	durations := findMetric(t, metrics, metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	dp := durations.At(0)
+	assert.Equal(t, map[string]any{
+		serviceNameKey: "frontend",
+		spanNameKey:    "GET /checkout",
+		traceErrorKey:  true,
+		"http.route":   "/checkout",
+	}, dp.Attributes().AsRaw())
+	assert.Equal(t, uint64(1), dp.Count())
+	assert.InDelta(t, 0.1, dp.Sum(), 1e-9)
+	assert.Equal(t, c.durationBounds, dp.ExplicitBounds().AsRaw())
+	// 100ms falls in the (50ms, 100ms] bucket
+	assert.Equal(t, uint64(1), dp.BucketCounts().At(3))
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, metrics.At(0).Histogram().AggregationTemporality())
+
+	spanCounts := findMetric(t, metrics, metricNameSpanCount)
+	require.Equal(t, 1, spanCounts.Len())
+	assert.Equal(t, 4.0, spanCounts.At(0).Sum())
+
+	criticalPaths := findMetric(t, metrics, metricNameCriticalPathDuration)
+	require.Equal(t, 3, criticalPaths.Len())
+	byService := make(map[string]float64)
+	for i := 0; i < criticalPaths.Len(); i++ {
+		dp := criticalPaths.At(i)
+		assert.Equal(t, "GET /checkout", attrStr(dp, spanNameKey))
+		byService[attrStr(dp, criticalPathServiceNameKey)] = dp.Sum()
+	}
+	assert.InDeltaMapValues(t, map[string]float64{"frontend": 0.03, "backend": 0.03, "db": 0.04}, byService, 1e-9)
+
+	// The histograms are cumulative
+	consumeCheckoutTrace(t, c.connectorImp, traceID2)
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+	durations = findMetric(t, c.lastMetrics(t), metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	assert.Equal(t, uint64(2), durations.At(0).Count())
+}
+
+func TestConnectorDropsTracesWithoutRoot(t *testing.T) {
+	c := newTestConnector(t, nil)
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildTraces("backend",
+		testSpan{traceID: traceID1, spanID: 3, parentID: 2, name: "checkout", start: 0, end: time.Millisecond},
+	)))
+
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+	assert.Empty(t, c.sink.AllMetrics())
+	assert.Empty(t, c.traces)
+}
+
+func TestConnectorNumTraces(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.NumTraces = 1
+	})
+	consumeCheckoutTrace(t, c.connectorImp, traceID1)
+	// The first trace is computed early to make room for the second one
+	consumeCheckoutTrace(t, c.connectorImp, traceID2)
+	assert.Len(t, c.traces, 1)
+
+	c.exportMetrics(context.Background())
+	durations := findMetric(t, c.lastMetrics(t), metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	assert.Equal(t, uint64(1), durations.At(0).Count())
+}
+
+func TestConnectorShutdown(t *testing.T) {
+	c := newTestConnector(t, nil)
+	require.NoError(t, c.Start(context.Background(), componenttest.NewNopHost()))
+	consumeCheckoutTrace(t, c.connectorImp, traceID1)
+
+	// The traces waiting for their spans are computed on shutdown
+	require.NoError(t, c.Shutdown(context.Background()))
+	durations := findMetric(t, c.lastMetrics(t), metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	assert.Equal(t, uint64(1), durations.At(0).Count())
+}
+
+func TestCriticalPath(t *testing.T) {
+	ms := pcommon.Timestamp(time.Millisecond)
+	for _, tc := range []struct {
+		name     string
+		spans    []spanInfo
+		expected map[string]time.Duration
+	}{
+		{
+			name: "sequential children",
+			spans: []spanInfo{
+				{spanID: pcommon.SpanID{1}, serviceName: "a", start: 0, end: 100 * ms},
+				{spanID: pcommon.SpanID{2}, parentSpanID: pcommon.SpanID{1}, serviceName: "b", start: 10 * ms, end: 40 * ms},
+				{spanID: pcommon.SpanID{3}, parentSpanID: pcommon.SpanID{1}, serviceName: "c", start: 50 * ms, end: 90 * ms},
+			},
+			expected: map[string]time.Duration{"a": 30 * time.Millisecond, "b": 30 * time.Millisecond, "c": 40 * time.Millisecond},
+		},
+		{
+			name: "parallel children",
+			spans: []spanInfo{
+				{spanID: pcommon.SpanID{1}, serviceName: "a", start: 0, end: 100 * ms},
+				{spanID: pcommon.SpanID{2}, parentSpanID: pcommon.SpanID{1}, serviceName: "b", start: 10 * ms, end: 80 * ms},
+				{spanID: pcommon.SpanID{3}, parentSpanID: pcommon.SpanID{1}, serviceName: "c", start: 10 * ms, end: 50 * ms},
+			},
+			expected: map[string]time.Duration{"a": 30 * time.Millisecond, "b": 70 * time.Millisecond},
+		},
+		{
+			name: "child outliving its parent",
+			spans: []spanInfo{
+				{spanID: pcommon.SpanID{1}, serviceName: "a", start: 0, end: 100 * ms},
+				{spanID: pcommon.SpanID{2}, parentSpanID: pcommon.SpanID{1}, serviceName: "b", start: 50 * ms, end: 200 * ms},
+			},
+			expected: map[string]time.Duration{"a": 50 * time.Millisecond, "b": 50 * time.Millisecond},
+		},
+		{
+			name: "cycle",
+			spans: []spanInfo{
+				{spanID: pcommon.SpanID{1}, parentSpanID: pcommon.SpanID{1}, serviceName: "a", start: 0, end: 100 * ms},
+			},
+			expected: map[string]time.Duration{"a": 100 * time.Millisecond},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, criticalPath(tc.spans, 0))
+		})
+	}
+}
+
+// consumeRootSpan sends a trace made of a single root span with the given name.
+func consumeRootSpan(t *testing.T, c *connectorImp, traceID pcommon.TraceID, name string) {
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildTraces("frontend",
+		testSpan{traceID: traceID, spanID: 1, name: name, start: 0, end: time.Millisecond},
+	)))
+}
+
+func TestConnectorMetricsExpiration(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.MetricsExpiration = time.Minute
+	})
+	consumeRootSpan(t, c.connectorImp, traceID1, "GET /checkout")
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+	durations := findMetric(t, c.lastMetrics(t), metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	start := durations.At(0).StartTimestamp()
+
+	// The series is kept while it's updated within the expiration
+	c.now = c.now.Add(30 * time.Second)
+	consumeRootSpan(t, c.connectorImp, traceID2, "GET /checkout")
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.now = c.now.Add(50 * time.Second)
+	c.exportMetrics(context.Background())
+	durations = findMetric(t, c.lastMetrics(t), metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	assert.Equal(t, uint64(2), durations.At(0).Count())
+
+	// The series expires once it isn't updated
+	c.now = c.now.Add(time.Minute)
+	c.sink.Reset()
+	c.exportMetrics(context.Background())
+	assert.Empty(t, c.sink.AllMetrics())
+	assert.Empty(t, c.durations)
+	assert.Empty(t, c.spanCounts)
+	assert.Empty(t, c.criticalPathDuration)
+
+	// A new series starts from scratch
+	consumeRootSpan(t, c.connectorImp, pcommon.TraceID([16]byte{3}), "GET /checkout")
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+	durations = findMetric(t, c.lastMetrics(t), metricNameDuration)
+	require.Equal(t, 1, durations.Len())
+	assert.Equal(t, uint64(1), durations.At(0).Count())
+	assert.Greater(t, durations.At(0).StartTimestamp(), start)
+}
+
+func TestConnectorCardinalityLimit(t *testing.T) {
+	c := newTestConnector(t, func(cfg *Config) {
+		cfg.AggregationCardinalityLimit = 2
+	})
+	for i, name := range []string{"GET /a", "GET /b", "GET /c", "GET /d", "GET /a"} {
+		consumeRootSpan(t, c.connectorImp, pcommon.TraceID([16]byte{byte(i + 1)}), name)
+	}
+	c.now = c.now.Add(c.config.WaitDuration)
+	c.expireTraces(false)
+	c.exportMetrics(context.Background())
+
+	// The traces of the new root spans over the limit are recorded in the overflow series
+	metrics := c.lastMetrics(t)
+	for _, name := range []string{metricNameDuration, metricNameSpanCount, metricNameCriticalPathDuration} {
+		dps := findMetric(t, metrics, name)
+		require.Equal(t, 3, dps.Len(), name)
+		counts := make(map[string]uint64)
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if overflow, ok := dp.Attributes().Get(overflowAttributeKey); ok {
+				assert.True(t, overflow.Bool())
+				assert.Equal(t, 1, dp.Attributes().Len())
+				counts[overflowAttributeKey] = dp.Count()
+				continue
+			}
+			counts[attrStr(dp, spanNameKey)] = dp.Count()
+		}
+		assert.Equal(t, map[string]uint64{"GET /a": 2, "GET /b": 1, overflowAttributeKey: 2}, counts, name)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracemetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector"
+
+import (
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// criticalPath returns the time each service spent on the critical path of the trace, i.e. the
+// sequence of spans that determined the duration of the root span.
+//
+// Walking back from the end of a span, the last finishing child is on the critical path until
+// its start, and the time not covered by any child is the self time of the span. Children are
+// clipped to the part of their parent they overlap with.
+func criticalPath(spans []spanInfo, root int) map[string]time.Duration {
+	children := make(map[pcommon.SpanID][]int)
+	for i, span := range spans {
+		if !span.parentSpanID.IsEmpty() {
+			children[span.parentSpanID] = append(children[span.parentSpanID], i)
+		}
+	}
+	for _, c := range children {
+		sort.SliceStable(c, func(a, b int) bool {
+			return spans[c[a]].end > spans[c[b]].end
+		})
+	}
+
+	durations := make(map[string]time.Duration)
+	visited := make(map[int]bool)
+
+	var walk func(i int, end pcommon.Timestamp)
+	walk = func(i int, end pcommon.Timestamp) {
+		visited[i] = true
+
+		span := spans[i]
+		cursor := min(span.end, end)
+		for _, c := range children[span.spanID] {
+			if cursor <= span.start {
+				break
+			}
+			child := spans[c]
+			// The visited children are skipped to guard against the cycles of malformed traces
+			if visited[c] || child.start >= cursor || child.end <= span.start {
+				continue
+			}
+			childEnd := min(child.end, cursor)
+			durations[span.serviceName] += time.Duration(cursor - childEnd)
+			walk(c, childEnd)
+			cursor = max(child.start, span.start)
+		}
+		if cursor > span.start {
+			durations[span.serviceName] += time.Duration(cursor - span.start)
+		}
+	}
+	walk(root, spans[root].end)
+
+	for service, d := range durations {
+		if d == 0 {
+			delete(durations, service)
+		}
+	}
+	return durations
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+package tracemetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/tracemetricsconnector/internal/metadata"
+)
+
+// NewFactory creates a factory for the tracemetrics connector.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithTracesToMetrics(createTracesToMetricsConnector, metadata.TracesToMetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		WaitDuration:         10 * time.Second,
+		NumTraces:            100_000,
+		MetricsFlushInterval: 15 * time.Second,
+		DurationBuckets:      defaultDurationBuckets,
+		SpanCountBuckets:     defaultSpanCountBuckets,
+	}
+}
+
+func createTracesToMetricsConnector(_ context.Context, params connector.Settings, cfg component.Config, nextConsumer consumer.Metrics) (connector.Traces, error) {
+	c := newConnector(params.Logger, cfg.(*Config), time.Now)
+	c.metricsConsumer = nextConsumer
+	return c, nil
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -63,6 +64,10 @@ type Config struct {
 	AsyncInsert bool `mapstructure:"async_insert"`
 	// MetricsTables defines the table names for metric types.
 	MetricsTables MetricTablesConfig `mapstructure:"metrics_tables"`
+	// MigrationsTableName is the table name tracking the schema migrations applied. default is `otel_schema_migrations`.
+	MigrationsTableName string `mapstructure:"migrations_table_name"`
+	// LogsMaterializedColumns are the columns added to the logs table, materialized from an attribute.
+	LogsMaterializedColumns []MaterializedColumn `mapstructure:"logs_materialized_columns"`
 }
 
 type MetricTablesConfig struct {
@@ -84,9 +89,28 @@ type TableEngine struct {
 	Params string `mapstructure:"params"`
 }
 
+// MaterializedColumn defines a column materialized from a resource or log attribute when a row is inserted.
+type MaterializedColumn struct {
+	// Name is the name of the column.
+	Name string `mapstructure:"name"`
+	// Type is the type of the column. default is `String`.
+	// The attribute values which can't be converted to this type are stored as the default value of the type.
+	Type string `mapstructure:"type"`
+	// Source is the attributes the column is materialized from, `resource_attributes` or `log_attributes`.
+	Source string `mapstructure:"source"`
+	// Attribute is the key of the attribute the column is materialized from.
+	Attribute string `mapstructure:"attribute"`
+}
+
+const (
+	materializedColumnSourceResource = "resource_attributes"
+	materializedColumnSourceLog      = "log_attributes"
+)
+
 const (
 	defaultDatabase           = "default"
 	defaultTableEngineName    = "MergeTree"
+	defaultMaterializedType   = "String"
 	defaultMetricTableName    = "otel_metrics"
 	defaultGaugeSuffix        = "_gauge"
 	defaultSumSuffix          = "_sum"
@@ -98,6 +122,12 @@ const (
 var (
 	errConfigNoEndpoint      = errors.New("endpoint must be specified")
 	errConfigInvalidEndpoint = errors.New("endpoint must be url format")
+
+	// columnNameRegexp matches the column names which don't need to be quoted.
+	columnNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// columnTypeRegexp matches the column types, a name with optional parameters made of names, numbers and
+	// string literals, like `LowCardinality(String)` or `DateTime64(3, 'UTC')`.
+	columnTypeRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\((?:[a-zA-Z0-9_ ,.()]|'[a-zA-Z0-9_/+\-]*')*\))?$`)
 )
 
 // Validate the ClickHouse server configuration.
@@ -112,6 +142,11 @@ func (cfg *Config) Validate() (err error) {
 
 	cfg.buildMetricTableNames()
 
+	if cfg.MigrationsTableName == "" {
+		err = errors.Join(err, errors.New("migrations_table_name must be specified"))
+	}
+	err = errors.Join(err, validateMaterializedColumns(cfg.LogsMaterializedColumns, logsColumns))
+
 	// Validate DSN with clickhouse driver.
 	// Last chance to catch invalid config.
 	if _, e := clickhouse.ParseDSN(dsn); e != nil {
@@ -121,6 +156,57 @@ func (cfg *Config) Validate() (err error) {
 	return err
 }
 
+func validateMaterializedColumns(columns []MaterializedColumn, tableColumns []string) (err error) {
+	names := map[string]bool{}
+	for _, name := range tableColumns {
+		names[name] = true
+	}
+	for i, column := range columns {
+		if !columnNameRegexp.MatchString(column.Name) {
+			err = errors.Join(err, fmt.Errorf("materialized column %d: invalid name %q", i, column.Name))
+		} else if names[column.Name] {
+			err = errors.Join(err, fmt.Errorf("materialized column %d: duplicate column name %q", i, column.Name))
+		}
+		names[column.Name] = true
+		if column.Source != materializedColumnSourceResource && column.Source != materializedColumnSourceLog {
+			err = errors.Join(err, fmt.Errorf("materialized column %q: source must be %q or %q",
+				column.Name, materializedColumnSourceResource, materializedColumnSourceLog))
+		}
+		if column.Attribute == "" {
+			err = errors.Join(err, fmt.Errorf("materialized column %q: attribute must be specified", column.Name))
+		}
+		if column.Type != "" && !isColumnType(column.Type) {
+			err = errors.Join(err, fmt.Errorf("materialized column %q: invalid type %q", column.Name, column.Type))
+		}
+	}
+	return err
+}
+
+// isColumnType returns whether s is a column type, whose parentheses are balanced, so that it can't end the
+// column definition.
+func isColumnType(s string) bool {
+	if !columnTypeRegexp.MatchString(s) {
+		return false
+	}
+	depth := 0
+	quoted := false
+	for _, c := range s {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
 func (cfg *Config) buildDSN() (string, error) {
 	dsnURL, err := url.Parse(cfg.Endpoint)
 	if err != nil {
@@ -82,7 +82,22 @@ func TestLoadConfig(t *testing.T) {
 					QueueSize:    100,
 					StorageID:    &storageID,
 				},
-				AsyncInsert: true,
+				AsyncInsert:         true,
+				MigrationsTableName: "otel_migrations",
+				LogsMaterializedColumns: []MaterializedColumn{
+					{
+						Name:      "K8sNamespaceName",
+						Type:      "LowCardinality(String)",
+						Source:    "resource_attributes",
+						Attribute: "k8s.namespace.name",
+					},
+					{
+						Name:      "HTTPStatusCode",
+						Type:      "UInt16",
+						Source:    "log_attributes",
+						Attribute: "http.response.status_code",
+					},
+				},
 			},
 		},
 	}
@@ -102,6 +117,28 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestConfig_ValidateMaterializedColumns(t *testing.T) {
+	t.Parallel()
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	cfg := NewFactory().CreateDefaultConfig()
+	sub, err := cm.Sub(component.NewIDWithName(metadata.Type, "invalid-materialized-columns").String())
+	require.NoError(t, err)
+	require.NoError(t, sub.Unmarshal(cfg))
+
+	err = xconfmap.Validate(cfg)
+	assert.ErrorContains(t, err, `materialized column 0: duplicate column name "Body"`)
+	assert.ErrorContains(t, err, `materialized column 1: invalid name "Status Code"`)
+	assert.ErrorContains(t, err, `materialized column "Status Code": source must be "resource_attributes" or "log_attributes"`)
+	assert.ErrorContains(t, err, `materialized column "Status Code": attribute must be specified`)
+	assert.ErrorContains(t, err, `materialized column 3: duplicate column name "Namespace"`)
+	assert.ErrorContains(t, err, `materialized column "Injected": invalid type "String DEFAULT 1"`)
+	assert.ErrorContains(t, err, `materialized column "Unbalanced": invalid type "Nullable(String))"`)
+	assert.NotContains(t, err.Error(), "materialized column 2")
+	assert.NotContains(t, err.Error(), `"Zoned"`)
+}
+
 func withDefaultConfig(fns ...func(*Config)) *Config {
 	cfg := createDefaultConfig().(*Config)
 	for _, fn := range fns {
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/ClickHouse/clickhouse-go/v2" // For register database driver.
@@ -49,7 +50,11 @@ func (e *logsExporter) start(ctx context.Context, _ component.Host) error {
 		return err
 	}
 
-	return createLogsTable(ctx, e.cfg, e.client)
+	if err := migrateTable(ctx, e.logger, e.cfg, e.client, e.cfg.LogsTableName, logsMigrations(e.cfg)); err != nil {
+		return err
+	}
+
+	return addLogsMaterializedColumns(ctx, e.cfg, e.client)
 }
 
 // shutdown will shut down the exporter.
@@ -160,6 +165,11 @@ PRIMARY KEY (ServiceName, TimestampTime)
 ORDER BY (ServiceName, TimestampTime, Timestamp)
 %s
 SETTINGS index_granularity = 8192, ttl_only_drop_parts = 1;
+`
+	// language=ClickHouse SQL
+	addMaterializedColumnSQL = `
+ALTER TABLE %s %s
+ADD COLUMN IF NOT EXISTS %s %s MATERIALIZED %s;
 `
 	// language=ClickHouse SQL
 	insertLogsSQLTemplate = `INSERT INTO %s (
@@ -199,6 +209,12 @@ SETTINGS index_granularity = 8192, ttl_only_drop_parts = 1;
 
 var driverName = "clickhouse" // for testing
 
+// logsColumns are the columns of the logs table.
+var logsColumns = []string{
+	"Timestamp", "TimestampTime", "TraceId", "SpanId", "TraceFlags", "SeverityText", "SeverityNumber", "ServiceName", "Body",
+	"ResourceSchemaUrl", "ResourceAttributes", "ScopeSchemaUrl", "ScopeName", "ScopeVersion", "ScopeAttributes", "LogAttributes",
+}
+
 // newClickhouseClient create a clickhouse client.
 func newClickhouseClient(cfg *Config) (*sql.DB, error) {
 	db, err := cfg.buildDB()
@@ -229,9 +245,13 @@ func createDatabase(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
-func createLogsTable(ctx context.Context, cfg *Config, db *sql.DB) error {
-	if _, err := db.ExecContext(ctx, renderCreateLogsTableSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create logs table sql: %w", err)
+// addLogsMaterializedColumns adds the materialized columns missing from the logs table. The columns already
+// existing are left as is, even when their definition changed.
+func addLogsMaterializedColumns(ctx context.Context, cfg *Config, db *sql.DB) error {
+	for _, column := range cfg.LogsMaterializedColumns {
+		if _, err := db.ExecContext(ctx, renderAddMaterializedColumnSQL(cfg, cfg.LogsTableName, column)); err != nil {
+			return fmt.Errorf("exec add materialized column %s sql: %w", column.Name, err)
+		}
 	}
 	return nil
 }
@@ -241,6 +261,35 @@ func renderCreateLogsTableSQL(cfg *Config) string {
 	return fmt.Sprintf(createLogsTableSQL, cfg.LogsTableName, cfg.clusterString(), cfg.tableEngineString(), ttlExpr)
 }
 
+func renderAddMaterializedColumnSQL(cfg *Config, table string, column MaterializedColumn) string {
+	attributes := "LogAttributes"
+	if column.Source == materializedColumnSourceResource {
+		attributes = "ResourceAttributes"
+	}
+	expr := fmt.Sprintf("%s[%s]", attributes, quoteString(column.Attribute))
+
+	columnType := column.Type
+	switch columnType {
+	case "":
+		columnType = defaultMaterializedType
+	case defaultMaterializedType, "LowCardinality(String)":
+	default:
+		// The attribute values are strings, and the ones which can't be converted mustn't fail the inserts.
+		expr = fmt.Sprintf("accurateCastOrDefault(%s, %s)", expr, quoteString(columnType))
+	}
+	return fmt.Sprintf(addMaterializedColumnSQL, table, cfg.clusterString(), quoteIdentifier(column.Name), columnType, expr)
+}
+
+// quoteString quotes s as a ClickHouse string literal.
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// quoteIdentifier quotes s as a ClickHouse identifier.
+func quoteIdentifier(s string) string {
+	return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(s) + "`"
+}
+
 func renderInsertLogsSQL(cfg *Config) string {
 	return fmt.Sprintf(insertLogsSQLTemplate, cfg.LogsTableName)
 }
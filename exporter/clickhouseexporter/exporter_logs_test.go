@@ -8,11 +8,13 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/column/orderedmap"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -48,7 +50,7 @@ func TestLogsExporter_New(t *testing.T) {
 	}{
 		"no dsn": {
 			config: withDefaultConfig(),
-			want:   failWithMsg("exec create logs table sql: parse dsn address failed"),
+			want:   failWithMsg("exec create migrations table sql: parse dsn address failed"),
 		},
 	}
 
@@ -158,6 +160,55 @@ func TestLogsTableEngineConfig(t *testing.T) {
 	})
 }
 
+func TestLogsExporter_materializedColumns(t *testing.T) {
+	var alters []string
+	initClickhouseTestServer(t, func(query string, _ []driver.Value) error {
+		if strings.HasPrefix(strings.TrimSpace(query), "ALTER TABLE") {
+			alters = append(alters, strings.TrimSpace(query))
+		}
+		return nil
+	})
+
+	newTestLogsExporter(t, defaultEndpoint, func(cfg *Config) {
+		cfg.ClusterName = "cluster_a_b"
+		cfg.LogsMaterializedColumns = []MaterializedColumn{
+			{Name: "Namespace", Source: materializedColumnSourceResource, Attribute: "k8s.namespace.name"},
+			{Name: "StatusCode", Type: "UInt16", Source: materializedColumnSourceLog, Attribute: "http.response.status_code"},
+		}
+	})
+	assert.Equal(t, []string{
+		"ALTER TABLE otel_logs ON CLUSTER cluster_a_b\nADD COLUMN IF NOT EXISTS `Namespace` String MATERIALIZED ResourceAttributes['k8s.namespace.name'];",
+		"ALTER TABLE otel_logs ON CLUSTER cluster_a_b\nADD COLUMN IF NOT EXISTS `StatusCode` UInt16 MATERIALIZED accurateCastOrDefault(LogAttributes['http.response.status_code'], 'UInt16');",
+	}, alters)
+}
+
+func TestRenderAddMaterializedColumnSQL(t *testing.T) {
+	cfg := withDefaultConfig()
+	tests := []struct {
+		column   MaterializedColumn
+		expected string
+	}{
+		{
+			column:   MaterializedColumn{Name: "Namespace", Type: "LowCardinality(String)", Source: materializedColumnSourceResource, Attribute: "k8s.namespace.name"},
+			expected: "ADD COLUMN IF NOT EXISTS `Namespace` LowCardinality(String) MATERIALIZED ResourceAttributes['k8s.namespace.name'];",
+		},
+		{
+			column:   MaterializedColumn{Name: "Quoted", Source: materializedColumnSourceLog, Attribute: `it's\\here`},
+			expected: "ADD COLUMN IF NOT EXISTS `Quoted` String MATERIALIZED LogAttributes['it\\'s\\\\\\\\here'];",
+		},
+		{
+			column:   MaterializedColumn{Name: "Retried", Type: "Bool", Source: materializedColumnSourceLog, Attribute: "retried"},
+			expected: "ADD COLUMN IF NOT EXISTS `Retried` Bool MATERIALIZED accurateCastOrDefault(LogAttributes['retried'], 'Bool');",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column.Name, func(t *testing.T) {
+			query := strings.TrimSpace(renderAddMaterializedColumnSQL(cfg, "otel_logs", tt.column))
+			assert.Equal(t, "ALTER TABLE otel_logs \n"+tt.expected, query)
+		})
+	}
+}
+
 func newTestLogsExporter(t *testing.T, dsn string, fns ...func(*Config)) *logsExporter {
 	exporter, err := newLogsExporter(zaptest.NewLogger(t), withTestExporterConfig(fns...)(dsn))
 	require.NoError(t, err)
@@ -318,7 +369,22 @@ func (t *testClickhouseDriverStmt) Exec(args []driver.Value) (driver.Result, err
 }
 
 func (t *testClickhouseDriverStmt) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, nil
+	return &testClickhouseDriverRows{}, nil
+}
+
+// testClickhouseDriverRows are the rows of a query, which are always empty.
+type testClickhouseDriverRows struct{}
+
+func (*testClickhouseDriverRows) Columns() []string {
+	return nil
+}
+
+func (*testClickhouseDriverRows) Close() error {
+	return nil
+}
+
+func (*testClickhouseDriverRows) Next(_ []driver.Value) error {
+	return io.EOF
 }
 
 type testClickhouseDriverTx struct{}
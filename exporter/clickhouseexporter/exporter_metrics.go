@@ -51,8 +51,12 @@ func (e *metricsExporter) start(ctx context.Context, _ component.Host) error {
 		return err
 	}
 
-	ttlExpr := generateTTLExpr(e.cfg.TTL, "toDateTime(TimeUnix)")
-	return internal.NewMetricsTable(ctx, e.tablesConfig, e.cfg.clusterString(), e.cfg.tableEngineString(), ttlExpr, e.client)
+	for metricType, migrations := range metricsMigrations(e.cfg, e.tablesConfig) {
+		if err := migrateTable(ctx, e.logger, e.cfg, e.client, e.tablesConfig[metricType].Name, migrations); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func generateMetricTablesConfigMapper(cfg *Config) internal.MetricTablesConfigMapper {
@@ -40,7 +40,7 @@ func checkClusterQueryDefinition(query string, clusterName string) error {
 	line := getQueryFirstLine(query)
 	lowercasedLine := strings.ToLower(line)
 	suffix := fmt.Sprintf("ON CLUSTER %s", clusterName)
-	prefixes := []string{"create database", "create table", "create materialized view"}
+	prefixes := []string{"create database", "create table", "create materialized view", "alter table"}
 	for _, prefix := range prefixes {
 		if strings.HasPrefix(lowercasedLine, prefix) {
 			if strings.HasSuffix(line, suffix) {
@@ -69,6 +69,10 @@ func testClusterConfig(t *testing.T, completion clusterTestCompletion) {
 	for _, tt := range tests {
 		t.Run("test cluster config "+tt.name, func(t *testing.T) {
 			initClickhouseTestServer(t, func(query string, _ []driver.Value) error {
+				// The rows inserted in the tables don't depend on the cluster.
+				if strings.HasPrefix(strings.ToLower(getQueryFirstLine(query)), "insert") {
+					return nil
+				}
 				if tt.shouldPass {
 					require.NoError(t, checkClusterQueryDefinition(query, tt.cluster))
 				} else {
@@ -51,7 +51,7 @@ func (e *tracesExporter) start(ctx context.Context, _ component.Host) error {
 		return err
 	}
 
-	return createTracesTable(ctx, e.cfg, e.client)
+	return migrateTable(ctx, e.logger, e.cfg, e.client, e.cfg.TracesTableName, tracesMigrations(e.cfg))
 }
 
 // shutdown will shut down the exporter.
@@ -266,19 +266,6 @@ GROUP BY TraceId;
 `
 )
 
-func createTracesTable(ctx context.Context, cfg *Config, db *sql.DB) error {
-	if _, err := db.ExecContext(ctx, renderCreateTracesTableSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create traces table sql: %w", err)
-	}
-	if _, err := db.ExecContext(ctx, renderCreateTraceIDTsTableSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create traceID timestamp table sql: %w", err)
-	}
-	if _, err := db.ExecContext(ctx, renderTraceIDTsMaterializedViewSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create traceID timestamp view sql: %w", err)
-	}
-	return nil
-}
-
 func renderInsertTracesSQL(cfg *Config) string {
 	return fmt.Sprintf(strings.ReplaceAll(insertTracesSQLTemplate, "'", "`"), cfg.TracesTableName)
 }
@@ -51,6 +51,7 @@ func createDefaultConfig() component.Config {
 			Histogram:            internal.MetricTypeConfig{Name: defaultMetricTableName + defaultHistogramSuffix},
 			ExponentialHistogram: internal.MetricTypeConfig{Name: defaultMetricTableName + defaultExpHistogramSuffix},
 		},
+		MigrationsTableName: "otel_schema_migrations",
 	}
 }
 
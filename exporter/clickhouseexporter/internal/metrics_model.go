@@ -59,15 +59,14 @@ func SetLogger(l *zap.Logger) {
 	logger = l
 }
 
-// NewMetricsTable create metric tables with an expiry time to storage metric telemetry data
-func NewMetricsTable(ctx context.Context, tablesConfig MetricTablesConfigMapper, cluster, engine, ttlExpr string, db *sql.DB) error {
+// CreateMetricsTablesSQL renders the statements creating the table of each metric type, with an expiry time
+// to storage metric telemetry data.
+func CreateMetricsTablesSQL(tablesConfig MetricTablesConfigMapper, cluster, engine, ttlExpr string) map[pmetric.MetricType]string {
+	queries := make(map[pmetric.MetricType]string, len(supportedMetricTypes))
 	for key, queryTemplate := range supportedMetricTypes {
-		query := fmt.Sprintf(queryTemplate, tablesConfig[key].Name, cluster, engine, ttlExpr)
-		if _, err := db.ExecContext(ctx, query); err != nil {
-			return fmt.Errorf("exec create metrics table sql: %w", err)
-		}
+		queries[key] = fmt.Sprintf(queryTemplate, tablesConfig[key].Name, cluster, engine, ttlExpr)
 	}
-	return nil
+	return queries
 }
 
 // NewMetricsModel create a model for contain different metric data
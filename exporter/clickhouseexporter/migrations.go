@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouseexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/clickhouseexporter"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/clickhouseexporter/internal"
+)
+
+// migration is a forward-only change of the schema of a table. The migrations of a table are applied in
+// the order of their version, and each version is applied once, the applied versions being tracked in the
+// migrations table. The statements must be idempotent, since several exporters may apply a migration at
+// the same time, and a migration failing in the middle is applied again.
+type migration struct {
+	version     uint32
+	description string
+	statements  []string
+}
+
+const (
+	// language=ClickHouse SQL
+	createMigrationsTableSQL = `
+CREATE TABLE IF NOT EXISTS %s %s (
+	TableName String,
+	Version UInt32,
+	Description String,
+	AppliedAt DateTime64(3) DEFAULT now64(3)
+) ENGINE = %s
+ORDER BY (TableName, Version);
+`
+	// language=ClickHouse SQL
+	selectMigrationVersionSQL = `SELECT max(Version) FROM %s WHERE TableName = ?`
+	// language=ClickHouse SQL
+	insertMigrationSQL = `
+INSERT INTO %s (
+	TableName,
+	Version,
+	Description
+) VALUES (
+	?,
+	?,
+	?
+)`
+)
+
+// logsMigrations returns the migrations of the logs table. New migrations are appended with the next version,
+// and the existing ones must not change, as they are applied once.
+func logsMigrations(cfg *Config) []migration {
+	return []migration{
+		{
+			version:     1,
+			description: "create logs table",
+			statements:  []string{renderCreateLogsTableSQL(cfg)},
+		},
+	}
+}
+
+// tracesMigrations returns the migrations of the traces table and of the trace ID timestamp table and view
+// derived from it.
+func tracesMigrations(cfg *Config) []migration {
+	return []migration{
+		{
+			version:     1,
+			description: "create traces tables",
+			statements: []string{
+				renderCreateTracesTableSQL(cfg),
+				renderCreateTraceIDTsTableSQL(cfg),
+				renderTraceIDTsMaterializedViewSQL(cfg),
+			},
+		},
+	}
+}
+
+// metricsMigrations returns the migrations of the table of each metric type.
+func metricsMigrations(cfg *Config, tablesConfig internal.MetricTablesConfigMapper) map[pmetric.MetricType][]migration {
+	ttlExpr := generateTTLExpr(cfg.TTL, "toDateTime(TimeUnix)")
+	createTables := internal.CreateMetricsTablesSQL(tablesConfig, cfg.clusterString(), cfg.tableEngineString(), ttlExpr)
+	migrations := make(map[pmetric.MetricType][]migration, len(createTables))
+	for metricType, createTable := range createTables {
+		migrations[metricType] = []migration{
+			{
+				version:     1,
+				description: "create metrics table",
+				statements:  []string{createTable},
+			},
+		}
+	}
+	return migrations
+}
+
+// migrateTable applies the migrations of the table not applied yet.
+func migrateTable(ctx context.Context, logger *zap.Logger, cfg *Config, db *sql.DB, table string, migrations []migration) error {
+	if _, err := db.ExecContext(ctx, renderCreateMigrationsTableSQL(cfg)); err != nil {
+		return fmt.Errorf("exec create migrations table sql: %w", err)
+	}
+
+	var applied uint32
+	err := db.QueryRowContext(ctx, fmt.Sprintf(selectMigrationVersionSQL, cfg.MigrationsTableName), table).Scan(&applied)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("select applied migration version of table %s: %w", table, err)
+	}
+
+	if latest := migrations[len(migrations)-1].version; applied > latest {
+		logger.Warn("The schema of the table is newer than the exporter, it is left as is",
+			zap.String("table", table), zap.Uint32("version", applied), zap.Uint32("exporter_version", latest))
+		return nil
+	}
+
+	insertSQL := fmt.Sprintf(insertMigrationSQL, cfg.MigrationsTableName)
+	for _, m := range pendingMigrations(migrations, applied) {
+		logger.Info("Applying schema migration",
+			zap.String("table", table), zap.Uint32("version", m.version), zap.String("description", m.description))
+		for _, statement := range m.statements {
+			if _, err := db.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("apply migration %d of table %s: %w", m.version, table, err)
+			}
+		}
+		if _, err := db.ExecContext(ctx, insertSQL, table, m.version, m.description); err != nil {
+			return fmt.Errorf("record migration %d of table %s: %w", m.version, table, err)
+		}
+	}
+	return nil
+}
+
+// pendingMigrations returns the migrations with a version greater than the applied one.
+func pendingMigrations(migrations []migration, applied uint32) []migration {
+	for i, m := range migrations {
+		if m.version > applied {
+			return migrations[i:]
+		}
+	}
+	return nil
+}
+
+func renderCreateMigrationsTableSQL(cfg *Config) string {
+	return fmt.Sprintf(createMigrationsTableSQL, cfg.MigrationsTableName, cfg.clusterString(), cfg.tableEngineString())
+}
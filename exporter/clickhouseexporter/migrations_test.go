@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouseexporter
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []migration{
+		{version: 1, description: "create table"},
+		{version: 2, description: "add column"},
+		{version: 3, description: "add index"},
+	}
+
+	assert.Equal(t, migrations, pendingMigrations(migrations, 0))
+	assert.Equal(t, migrations[2:], pendingMigrations(migrations, 2))
+	assert.Empty(t, pendingMigrations(migrations, 3))
+	assert.Empty(t, pendingMigrations(migrations, 4))
+}
+
+func TestMigrateTable(t *testing.T) {
+	var queries []string
+	var records [][]driver.Value
+	initClickhouseTestServer(t, func(query string, values []driver.Value) error {
+		queries = append(queries, getQueryFirstLine(query))
+		if strings.HasPrefix(getQueryFirstLine(query), "INSERT INTO otel_schema_migrations") {
+			records = append(records, values)
+		}
+		return nil
+	})
+
+	cfg := withTestExporterConfig()(defaultEndpoint)
+	db, err := cfg.buildDB()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations := []migration{
+		{version: 1, description: "create table", statements: []string{"CREATE TABLE IF NOT EXISTS test_table"}},
+		{version: 2, description: "add column", statements: []string{
+			"ALTER TABLE test_table ADD COLUMN IF NOT EXISTS A String",
+			"ALTER TABLE test_table ADD COLUMN IF NOT EXISTS B String",
+		}},
+	}
+	require.NoError(t, migrateTable(context.Background(), zaptest.NewLogger(t), cfg, db, "test_table", migrations))
+
+	// The migrations are applied in order, and recorded once applied
+	assert.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS otel_schema_migrations",
+		"CREATE TABLE IF NOT EXISTS test_table",
+		"INSERT INTO otel_schema_migrations",
+		"ALTER TABLE test_table ADD COLUMN IF NOT EXISTS A String",
+		"ALTER TABLE test_table ADD COLUMN IF NOT EXISTS B String",
+		"INSERT INTO otel_schema_migrations",
+	}, queries)
+	assert.Equal(t, [][]driver.Value{
+		{"test_table", uint32(1), "create table"},
+		{"test_table", uint32(2), "add column"},
+	}, records)
+}
+
+func TestMigrateTable_failure(t *testing.T) {
+	var records int
+	initClickhouseTestServer(t, func(query string, _ []driver.Value) error {
+		if strings.HasPrefix(query, "ALTER TABLE") {
+			return errors.New("column exists with a different type")
+		}
+		if strings.HasPrefix(getQueryFirstLine(query), "INSERT INTO otel_schema_migrations") {
+			records++
+		}
+		return nil
+	})
+
+	cfg := withTestExporterConfig()(defaultEndpoint)
+	db, err := cfg.buildDB()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations := []migration{
+		{version: 1, description: "create table", statements: []string{"CREATE TABLE IF NOT EXISTS test_table"}},
+		{version: 2, description: "add column", statements: []string{"ALTER TABLE test_table ADD COLUMN IF NOT EXISTS A String"}},
+	}
+	err = migrateTable(context.Background(), zaptest.NewLogger(t), cfg, db, "test_table", migrations)
+	assert.ErrorContains(t, err, "apply migration 2 of table test_table: column exists with a different type")
+	// The failed migration isn't recorded, and is applied again at the next start
+	assert.Equal(t, 1, records)
+}
+
+func TestMigrations(t *testing.T) {
+	cfg := withDefaultConfig()
+	cfg.buildMetricTableNames()
+
+	// The versions of the migrations of each table are increasing
+	assertVersions := func(t *testing.T, migrations []migration) {
+		require.NotEmpty(t, migrations)
+		for i, m := range migrations {
+			assert.Equal(t, uint32(i+1), m.version)
+			assert.NotEmpty(t, m.description)
+			assert.NotEmpty(t, m.statements)
+		}
+	}
+	assertVersions(t, logsMigrations(cfg))
+	assertVersions(t, tracesMigrations(cfg))
+	metrics := metricsMigrations(cfg, generateMetricTablesConfigMapper(cfg))
+	assert.Len(t, metrics, 5)
+	for _, migrations := range metrics {
+		assertVersions(t, migrations)
+	}
+}
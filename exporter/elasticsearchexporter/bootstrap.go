@@ -0,0 +1,397 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"go.uber.org/zap"
+)
+
+// bootstrapManagedBy identifies the resources installed by the exporter.
+const bootstrapManagedBy = "opentelemetry-collector"
+
+// mappingsFS holds the mappings of the indices of each mapping mode.
+//
+//go:embed mappings/*.json
+var mappingsFS embed.FS
+
+// bootstrapMeta is the _meta of the installed resources, which identifies the resources installed by the
+// exporter and the settings they were installed with.
+type bootstrapMeta struct {
+	ManagedBy   string `json:"managed_by"`
+	Version     int    `json:"version"`
+	MappingMode string `json:"mapping_mode"`
+}
+
+// bootstrapResource is a resource installed at start.
+type bootstrapResource struct {
+	kind string
+	name string
+	body map[string]any
+	// get returns the _meta of the installed resource, or nil when it doesn't exist.
+	get func(ctx context.Context) (*bootstrapMeta, error)
+	put func(ctx context.Context, body io.Reader) (*esapi.Response, error)
+}
+
+// bootstrapper installs the lifecycle policy, the component templates and the index template of the
+// indices of a signal.
+type bootstrapper struct {
+	client        esapi.Transport
+	config        BootstrapSettings
+	meta          bootstrapMeta
+	signal        string
+	indexPatterns []string
+	// dataStream is false when the indices are regular indices, which can't be rolled over.
+	dataStream bool
+	logger     *zap.Logger
+}
+
+func newBootstrapper(
+	client esapi.Transport,
+	cfg *Config,
+	signal string,
+	index string,
+	dynamicIndex bool,
+	logger *zap.Logger,
+) *bootstrapper {
+	mode := cfg.MappingMode()
+	return &bootstrapper{
+		client: client,
+		config: cfg.Bootstrap,
+		meta: bootstrapMeta{
+			ManagedBy:   bootstrapManagedBy,
+			Version:     cfg.Bootstrap.Version,
+			MappingMode: mappingModeName(mode),
+		},
+		signal:        signal,
+		indexPatterns: bootstrapIndexPatterns(cfg, mode, signal, index, dynamicIndex),
+		dataStream:    !cfg.LogstashFormat.Enabled,
+		logger:        logger,
+	}
+}
+
+// mappingModeName returns the name of the mapping mode, which is also the name of its mappings file.
+func mappingModeName(mode MappingMode) string {
+	if mode == MappingNone {
+		return "none"
+	}
+	return mode.String()
+}
+
+// bootstrapIndexPatterns returns the patterns of the indices the signal is written to. With the dynamic index,
+// only the data streams of the configured datasets are matched, in any namespace, so that the index template
+// doesn't take precedence over the built-in templates of the other data streams. The indices with an
+// elasticsearch.index.prefix or elasticsearch.index.suffix attribute aren't matched.
+func bootstrapIndexPatterns(cfg *Config, mode MappingMode, signal, index string, dynamicIndex bool) []string {
+	patterns := []string{index}
+	if dynamicIndex {
+		patterns = patterns[:0]
+		for _, dataset := range cfg.Bootstrap.Datasets {
+			if mode == MappingOTel && !strings.HasSuffix(dataset, ".otel") {
+				dataset += ".otel"
+			}
+			patterns = append(patterns, signal+"-"+dataset+"-*")
+		}
+	}
+	if cfg.LogstashFormat.Enabled {
+		for i := range patterns {
+			patterns[i] += cfg.LogstashFormat.PrefixSeparator + "*"
+		}
+	}
+	return patterns
+}
+
+// run installs the resources in the background, so that the exporter starts while Elasticsearch is unavailable.
+// The installation is retried with the backoff of the retry settings until it succeeds or ctx is canceled, the
+// documents indexed in the meantime are mapped by the other index templates.
+func (b *bootstrapper) run(ctx context.Context, retry RetrySettings) {
+	expBackoff := backoff.NewExponentialBackOff()
+	if retry.InitialInterval > 0 {
+		expBackoff.InitialInterval = retry.InitialInterval
+	}
+	if retry.MaxInterval > 0 {
+		expBackoff.MaxInterval = retry.MaxInterval
+	}
+	expBackoff.MaxElapsedTime = 0
+	expBackoff.Reset()
+
+	for {
+		err := b.bootstrap(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		wait := expBackoff.NextBackOff()
+		b.logger.Error("Failed to bootstrap the indices, retrying",
+			zap.String("signal", b.signal), zap.Duration("interval", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (b *bootstrapper) bootstrap(ctx context.Context) error {
+	resources, err := b.resources()
+	if err != nil {
+		return err
+	}
+	// The resources are installed in order, as the index template depends on the component templates,
+	// which depend on the lifecycle policy.
+	for _, r := range resources {
+		if err := b.install(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bootstrapper) install(ctx context.Context, r bootstrapResource) error {
+	logger := b.logger.With(zap.String("kind", r.kind), zap.String("name", r.name))
+	installed, err := r.get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get %s %q: %w", r.kind, r.name, err)
+	}
+	switch {
+	case installed == nil:
+	case installed.ManagedBy != bootstrapManagedBy:
+		logger.Warn("Not updating the resource, which wasn't installed by the exporter")
+		return nil
+	case installed.Version > b.meta.Version || *installed == b.meta:
+		logger.Debug("The resource is up to date", zap.Int("version", installed.Version))
+		return nil
+	}
+
+	body, err := json.Marshal(r.body)
+	if err != nil {
+		return err
+	}
+	resp, err := r.put(ctx, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to put %s %q: %w", r.kind, r.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("failed to put %s %q: %s", r.kind, r.name, resp.String())
+	}
+	logger.Info("Installed the resource", zap.Int("version", b.meta.Version))
+	return nil
+}
+
+func (b *bootstrapper) resources() ([]bootstrapResource, error) {
+	name := b.config.NamePrefix + "-" + b.signal
+	mappings, err := b.mappings()
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []bootstrapResource
+	var composedOf []string
+	settings := map[string]any{}
+	if b.config.ILM.Enabled {
+		resources = append(resources, b.lifecyclePolicy(name))
+		settings["index.lifecycle.name"] = name
+	}
+	if b.timeSeries() {
+		settings["index.mode"] = "time_series"
+	}
+	if len(settings) > 0 {
+		resources = append(resources, b.componentTemplate(name+"@settings", map[string]any{"settings": settings}))
+		composedOf = append(composedOf, name+"@settings")
+	}
+	resources = append(resources, b.componentTemplate(name+"@mappings", map[string]any{
+		"mappings": json.RawMessage(mappings),
+	}))
+	composedOf = append(composedOf, name+"@mappings")
+
+	indexTemplate := map[string]any{
+		"index_patterns": b.indexPatterns,
+		"composed_of":    composedOf,
+		"priority":       b.config.Priority,
+		"version":        b.meta.Version,
+		"_meta":          b.meta,
+	}
+	if b.dataStream {
+		indexTemplate["data_stream"] = map[string]any{}
+	}
+	resources = append(resources, bootstrapResource{
+		kind: "index template",
+		name: name,
+		body: indexTemplate,
+		get: func(ctx context.Context) (*bootstrapMeta, error) {
+			var templates struct {
+				IndexTemplates []struct {
+					IndexTemplate struct {
+						Meta *bootstrapMeta `json:"_meta"`
+					} `json:"index_template"`
+				} `json:"index_templates"`
+			}
+			resp, err := esapi.IndicesGetIndexTemplateRequest{Name: name}.Do(ctx, b.client)
+			found, err := decodeResponse(resp, err, &templates)
+			if err != nil || !found || len(templates.IndexTemplates) == 0 {
+				return nil, err
+			}
+			return metaOrEmpty(templates.IndexTemplates[0].IndexTemplate.Meta), nil
+		},
+		put: func(ctx context.Context, body io.Reader) (*esapi.Response, error) {
+			return esapi.IndicesPutIndexTemplateRequest{Name: name, Body: body}.Do(ctx, b.client)
+		},
+	})
+	return resources, nil
+}
+
+// mappings returns the mappings of the signal in the mapping mode, from mappings/<mode>-<signal>.json when the
+// signal has its own mappings, from mappings/<mode>.json otherwise.
+func (b *bootstrapper) mappings() ([]byte, error) {
+	mappings, err := mappingsFS.ReadFile("mappings/" + b.meta.MappingMode + "-" + b.signal + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return mappingsFS.ReadFile("mappings/" + b.meta.MappingMode + ".json")
+	}
+	return mappings, err
+}
+
+// timeSeries returns true when the indices are time series data streams, like the data streams of the
+// built-in metrics-otel@template of Elasticsearch, which the index template takes precedence over.
+func (b *bootstrapper) timeSeries() bool {
+	return b.dataStream && b.meta.MappingMode == "otel" && b.signal == defaultDataStreamTypeMetrics
+}
+
+func (b *bootstrapper) lifecyclePolicy(name string) bootstrapResource {
+	hot := map[string]any{}
+	if b.dataStream {
+		rollover := map[string]any{}
+		if b.config.ILM.RolloverMaxAge > 0 {
+			rollover["max_age"] = formatTimeUnits(b.config.ILM.RolloverMaxAge)
+		}
+		if b.config.ILM.RolloverMaxPrimaryShardSize != "" {
+			rollover["max_primary_shard_size"] = b.config.ILM.RolloverMaxPrimaryShardSize
+		}
+		if len(rollover) > 0 {
+			hot["rollover"] = rollover
+		}
+	}
+	phases := map[string]any{
+		"hot": map[string]any{"actions": hot},
+	}
+	if b.config.ILM.DeleteAfter > 0 {
+		phases["delete"] = map[string]any{
+			"min_age": formatTimeUnits(b.config.ILM.DeleteAfter),
+			"actions": map[string]any{"delete": map[string]any{}},
+		}
+	}
+
+	return bootstrapResource{
+		kind: "lifecycle policy",
+		name: name,
+		body: map[string]any{
+			"policy": map[string]any{
+				"phases": phases,
+				"_meta":  b.meta,
+			},
+		},
+		get: func(ctx context.Context) (*bootstrapMeta, error) {
+			var policies map[string]struct {
+				Policy struct {
+					Meta *bootstrapMeta `json:"_meta"`
+				} `json:"policy"`
+			}
+			resp, err := esapi.ILMGetLifecycleRequest{Policy: name}.Do(ctx, b.client)
+			found, err := decodeResponse(resp, err, &policies)
+			if err != nil || !found {
+				return nil, err
+			}
+			policy, ok := policies[name]
+			if !ok {
+				return nil, nil
+			}
+			return metaOrEmpty(policy.Policy.Meta), nil
+		},
+		put: func(ctx context.Context, body io.Reader) (*esapi.Response, error) {
+			return esapi.ILMPutLifecycleRequest{Policy: name, Body: body}.Do(ctx, b.client)
+		},
+	}
+}
+
+func (b *bootstrapper) componentTemplate(name string, template map[string]any) bootstrapResource {
+	return bootstrapResource{
+		kind: "component template",
+		name: name,
+		body: map[string]any{
+			"template": template,
+			"version":  b.meta.Version,
+			"_meta":    b.meta,
+		},
+		get: func(ctx context.Context) (*bootstrapMeta, error) {
+			var templates struct {
+				ComponentTemplates []struct {
+					ComponentTemplate struct {
+						Meta *bootstrapMeta `json:"_meta"`
+					} `json:"component_template"`
+				} `json:"component_templates"`
+			}
+			resp, err := esapi.ClusterGetComponentTemplateRequest{Name: []string{name}}.Do(ctx, b.client)
+			found, err := decodeResponse(resp, err, &templates)
+			if err != nil || !found || len(templates.ComponentTemplates) == 0 {
+				return nil, err
+			}
+			return metaOrEmpty(templates.ComponentTemplates[0].ComponentTemplate.Meta), nil
+		},
+		put: func(ctx context.Context, body io.Reader) (*esapi.Response, error) {
+			return esapi.ClusterPutComponentTemplateRequest{Name: name, Body: body}.Do(ctx, b.client)
+		},
+	}
+}
+
+// metaOrEmpty returns an empty _meta for the resources without one, which weren't installed by the exporter.
+func metaOrEmpty(meta *bootstrapMeta) *bootstrapMeta {
+	if meta == nil {
+		return &bootstrapMeta{}
+	}
+	return meta
+}
+
+// decodeResponse decodes the body of the response of a get request into v. It returns false when the
+// resource doesn't exist.
+func decodeResponse(resp *esapi.Response, err error, v any) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.IsError() {
+		return false, errors.New(resp.String())
+	}
+	return true, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// formatTimeUnits formats a duration with the largest time unit of Elasticsearch dividing it.
+func formatTimeUnits(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	}
+}
@@ -0,0 +1,450 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/datapoints"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/elasticsearch"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/metadata"
+)
+
+// bootstrapCluster is a fake Elasticsearch cluster storing the lifecycle policies, component templates
+// and index templates.
+type bootstrapCluster struct {
+	mu        sync.Mutex
+	resources map[string]map[string]any
+	puts      []string
+	// status is returned by the put requests when it is set.
+	status int
+}
+
+func newBootstrapCluster(t *testing.T) (*bootstrapCluster, *httptest.Server) {
+	c := &bootstrapCluster{resources: map[string]map[string]any{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"version": map[string]any{"number": currentESVersion}})
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			c.get(w, r.URL.Path)
+		case http.MethodPut:
+			if c.status != 0 {
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(`{"error": {"type": "security_exception"}}`))
+				return
+			}
+			reqBody := r.Body
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				reqBody, _ = gzip.NewReader(r.Body)
+			}
+			var body map[string]any
+			if err := json.NewDecoder(reqBody).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			c.resources[r.URL.Path] = body
+			c.puts = append(c.puts, r.URL.Path)
+			_, _ = w.Write([]byte(`{"acknowledged": true}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return c, server
+}
+
+// get writes the resource in the format of the get APIs.
+func (c *bootstrapCluster) get(w http.ResponseWriter, path string) {
+	body, ok := c.resources[path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+	name := path[strings.LastIndex(path, "/")+1:]
+	var resp any
+	switch {
+	case strings.HasPrefix(path, "/_ilm/policy/"):
+		resp = map[string]any{name: map[string]any{"version": 1, "policy": body["policy"]}}
+	case strings.HasPrefix(path, "/_component_template/"):
+		resp = map[string]any{"component_templates": []any{map[string]any{"name": name, "component_template": body}}}
+	case strings.HasPrefix(path, "/_index_template/"):
+		resp = map[string]any{"index_templates": []any{map[string]any{"name": name, "index_template": body}}}
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (c *bootstrapCluster) takePuts() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	puts := c.puts
+	c.puts = nil
+	return puts
+}
+
+func (c *bootstrapCluster) resource(path string) map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resources[path]
+}
+
+func (c *bootstrapCluster) setStatus(status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = status
+}
+
+// startBootstrapLogs starts a logs exporter installing the resources of its indices.
+func startBootstrapLogs(t *testing.T, set exporter.Settings, url string, fns ...func(*Config)) *elasticsearchExporter {
+	cfg := withDefaultConfig(append([]func(*Config){func(cfg *Config) {
+		cfg.Endpoints = []string{url}
+		cfg.Bootstrap.Enabled = true
+	}}, fns...)...)
+	exp := newExporter(cfg, set, defaultDataStreamTypeLogs, cfg.LogsIndex, cfg.LogsDynamicIndex.Enabled)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	return exp
+}
+
+func waitBootstrap(t *testing.T, exp *elasticsearchExporter) {
+	select {
+	case <-exp.bootstrapDone:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "the resources weren't installed")
+	}
+}
+
+// bootstrapLogs starts a logs exporter, and shuts it down once the resources of its indices are installed.
+func bootstrapLogs(t *testing.T, url string, fns ...func(*Config)) {
+	exp := startBootstrapLogs(t, exportertest.NewNopSettings(metadata.Type), url, fns...)
+	waitBootstrap(t, exp)
+	require.NoError(t, exp.Shutdown(context.Background()))
+}
+
+func TestBootstrap(t *testing.T) {
+	cluster, server := newBootstrapCluster(t)
+	otelMode := func(cfg *Config) {
+		cfg.Mapping.Mode = "otel"
+		cfg.LogsDynamicIndex.Enabled = true
+		cfg.Bootstrap.ILM.DeleteAfter = 90 * 24 * time.Hour
+	}
+
+	bootstrapLogs(t, server.URL, otelMode)
+	assert.Equal(t, []string{
+		"/_ilm/policy/otel-logs",
+		"/_component_template/otel-logs@settings",
+		"/_component_template/otel-logs@mappings",
+		"/_index_template/otel-logs",
+	}, cluster.takePuts())
+
+	meta := map[string]any{"managed_by": "opentelemetry-collector", "version": float64(1), "mapping_mode": "otel"}
+	assert.Equal(t, map[string]any{
+		"policy": map[string]any{
+			"_meta": meta,
+			"phases": map[string]any{
+				"hot": map[string]any{"actions": map[string]any{
+					"rollover": map[string]any{"max_age": "30d", "max_primary_shard_size": "50gb"},
+				}},
+				"delete": map[string]any{"min_age": "90d", "actions": map[string]any{"delete": map[string]any{}}},
+			},
+		},
+	}, cluster.resource("/_ilm/policy/otel-logs"))
+	assert.Equal(t, map[string]any{
+		"template": map[string]any{"settings": map[string]any{"index.lifecycle.name": "otel-logs"}},
+		"version":  float64(1),
+		"_meta":    meta,
+	}, cluster.resource("/_component_template/otel-logs@settings"))
+	mappings := cluster.resource("/_component_template/otel-logs@mappings")["template"].(map[string]any)["mappings"]
+	assert.Contains(t, mappings.(map[string]any)["properties"], "severity_text")
+	assert.Equal(t, map[string]any{
+		"index_patterns": []any{"logs-generic.otel-*"},
+		"composed_of":    []any{"otel-logs@settings", "otel-logs@mappings"},
+		"data_stream":    map[string]any{},
+		"priority":       float64(150),
+		"version":        float64(1),
+		"_meta":          meta,
+	}, cluster.resource("/_index_template/otel-logs"))
+
+	// The installed resources are up to date
+	bootstrapLogs(t, server.URL, otelMode)
+	assert.Empty(t, cluster.takePuts())
+
+	// The resources are updated when the mapping mode or the version change
+	bootstrapLogs(t, server.URL, func(cfg *Config) { cfg.Mapping.Mode = "ecs" })
+	assert.Len(t, cluster.takePuts(), 4)
+	mappings = cluster.resource("/_component_template/otel-logs@mappings")["template"].(map[string]any)["mappings"]
+	assert.Contains(t, mappings.(map[string]any)["properties"], "log")
+	assert.Equal(t, []any{"logs-generic-default"}, cluster.resource("/_index_template/otel-logs")["index_patterns"])
+
+	bootstrapLogs(t, server.URL, func(cfg *Config) {
+		cfg.Mapping.Mode = "ecs"
+		cfg.Bootstrap.Version = 2
+	})
+	assert.Len(t, cluster.takePuts(), 4)
+
+	// The resources of a higher version aren't downgraded
+	bootstrapLogs(t, server.URL, func(cfg *Config) { cfg.Mapping.Mode = "raw" })
+	assert.Empty(t, cluster.takePuts())
+}
+
+func TestBootstrap_otelMetrics(t *testing.T) {
+	cluster, server := newBootstrapCluster(t)
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.Endpoints = []string{server.URL}
+		cfg.Mapping.Mode = "otel"
+		cfg.MetricsDynamicIndex.Enabled = true
+		cfg.Bootstrap.Enabled = true
+	})
+	exp := newExporter(cfg, exportertest.NewNopSettings(metadata.Type), defaultDataStreamTypeMetrics, cfg.MetricsIndex, cfg.MetricsDynamicIndex.Enabled)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	waitBootstrap(t, exp)
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	// The metrics are indexed into time series data streams, like with the built-in metrics-otel@template
+	settings := cluster.resource("/_component_template/otel-metrics@settings")["template"].(map[string]any)["settings"]
+	assert.Equal(t, map[string]any{"index.lifecycle.name": "otel-metrics", "index.mode": "time_series"}, settings)
+	mappings := cluster.resource("/_component_template/otel-metrics@mappings")["template"].(map[string]any)["mappings"].(map[string]any)
+	dynamicTemplates := map[string]any{}
+	for _, dynamicTemplate := range mappings["dynamic_templates"].([]any) {
+		for name, template := range dynamicTemplate.(map[string]any) {
+			dynamicTemplates[name] = template
+		}
+	}
+	properties := mappings["properties"].(map[string]any)
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "foo")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("bar")
+	var dataPoints []datapoints.DataPoint
+	counter := sm.Metrics().AppendEmpty()
+	counter.SetName("counter")
+	counter.SetEmptySum().SetIsMonotonic(true)
+	counterDP := counter.Sum().DataPoints().AppendEmpty()
+	counterDP.SetDoubleValue(1.5)
+	dataPoints = append(dataPoints, datapoints.NewNumber(counter, counterDP))
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("gauge")
+	gaugeDP := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gaugeDP.SetIntValue(2)
+	dataPoints = append(dataPoints, datapoints.NewNumber(gauge, gaugeDP))
+	histogram := sm.Metrics().AppendEmpty()
+	histogram.SetName("histogram")
+	histogramDP := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	histogramDP.ExplicitBounds().FromRaw([]float64{1})
+	histogramDP.BucketCounts().FromRaw([]uint64{1, 2})
+	dataPoints = append(dataPoints, datapoints.NewHistogram(histogram, histogramDP))
+	summary := sm.Metrics().AppendEmpty()
+	summary.SetName("summary")
+	summaryDP := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	summaryDP.SetCount(3)
+	summaryDP.SetSum(4)
+	dataPoints = append(dataPoints, datapoints.NewSummary(summary, summaryDP))
+	for _, dp := range dataPoints {
+		dp.Attributes().PutStr("host.name", "baz")
+	}
+
+	encoder, err := newEncoder(MappingOTel)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	var validationErrors []error
+	docTemplates, err := encoder.encodeMetrics(
+		encodingContext{resource: rm.Resource(), scope: sm.Scope()},
+		dataPoints, &validationErrors, elasticsearch.Index{Type: "metrics", Dataset: "generic.otel", Namespace: "default"}, &buf,
+	)
+	require.NoError(t, err)
+	require.Empty(t, validationErrors)
+
+	// The dynamic templates of the bulk requests are defined by the mappings
+	assert.Len(t, docTemplates, len(dataPoints))
+	for path, name := range docTemplates {
+		assert.Contains(t, dynamicTemplates, name, path)
+	}
+	// The fields of the documents are mapped
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	for field := range doc {
+		assert.Contains(t, properties, field)
+	}
+	assert.Equal(t, "passthrough", properties["metrics"].(map[string]any)["type"])
+	for _, attributes := range []any{
+		properties["attributes"],
+		properties["resource"].(map[string]any)["properties"].(map[string]any)["attributes"],
+		properties["scope"].(map[string]any)["properties"].(map[string]any)["attributes"],
+	} {
+		assert.Equal(t, true, attributes.(map[string]any)["time_series_dimension"])
+	}
+
+	// The other signals aren't time series
+	bootstrapLogs(t, server.URL, func(cfg *Config) { cfg.Mapping.Mode = "otel" })
+	settings = cluster.resource("/_component_template/otel-logs@settings")["template"].(map[string]any)["settings"]
+	assert.Equal(t, map[string]any{"index.lifecycle.name": "otel-logs"}, settings)
+}
+
+func TestBootstrap_unmanaged(t *testing.T) {
+	cluster, server := newBootstrapCluster(t)
+	cluster.resources["/_index_template/otel-logs"] = map[string]any{"index_patterns": []any{"logs-*"}}
+
+	bootstrapLogs(t, server.URL)
+	assert.Equal(t, []string{
+		"/_ilm/policy/otel-logs",
+		"/_component_template/otel-logs@settings",
+		"/_component_template/otel-logs@mappings",
+	}, cluster.takePuts())
+	assert.Equal(t, map[string]any{"index_patterns": []any{"logs-*"}}, cluster.resource("/_index_template/otel-logs"))
+}
+
+func TestBootstrap_logstashFormat(t *testing.T) {
+	cluster, server := newBootstrapCluster(t)
+
+	bootstrapLogs(t, server.URL, func(cfg *Config) {
+		cfg.LogsIndex = "my-logs"
+		cfg.LogstashFormat.Enabled = true
+		cfg.Bootstrap.NamePrefix = "collector"
+		cfg.Bootstrap.ILM.DeleteAfter = 36 * time.Hour
+	})
+	// The regular indices aren't rolled over
+	assert.Equal(t, map[string]any{
+		"hot":    map[string]any{"actions": map[string]any{}},
+		"delete": map[string]any{"min_age": "36h", "actions": map[string]any{"delete": map[string]any{}}},
+	}, cluster.resource("/_ilm/policy/collector-logs")["policy"].(map[string]any)["phases"])
+	template := cluster.resource("/_index_template/collector-logs")
+	assert.Equal(t, []any{"my-logs-*"}, template["index_patterns"])
+	assert.NotContains(t, template, "data_stream")
+}
+
+func TestBootstrap_ilmDisabled(t *testing.T) {
+	cluster, server := newBootstrapCluster(t)
+
+	bootstrapLogs(t, server.URL, func(cfg *Config) { cfg.Bootstrap.ILM.Enabled = false })
+	assert.Equal(t, []string{
+		"/_component_template/otel-logs@mappings",
+		"/_index_template/otel-logs",
+	}, cluster.takePuts())
+	assert.Equal(t, []any{"otel-logs@mappings"}, cluster.resource("/_index_template/otel-logs")["composed_of"])
+}
+
+func TestBootstrap_retry(t *testing.T) {
+	cluster, server := newBootstrapCluster(t)
+	cluster.setStatus(http.StatusForbidden)
+	core, observed := observer.New(zap.ErrorLevel)
+	set := exportertest.NewNopSettings(metadata.Type)
+	set.Logger = zap.New(core)
+	retryFast := func(cfg *Config) {
+		cfg.Retry.InitialInterval = 5 * time.Millisecond
+		cfg.Retry.MaxInterval = 5 * time.Millisecond
+	}
+
+	// The exporter starts while the resources can't be installed, the failures are logged and retried
+	exp := startBootstrapLogs(t, set, server.URL, retryFast)
+	require.Eventually(t, func() bool {
+		return observed.FilterMessage("Failed to bootstrap the indices, retrying").Len() > 1
+	}, 10*time.Second, 5*time.Millisecond)
+	entry := observed.FilterMessage("Failed to bootstrap the indices, retrying").All()[0]
+	assert.Contains(t, entry.ContextMap()["error"], `failed to put lifecycle policy "otel-logs": [403 Forbidden]`)
+	assert.Equal(t, "logs", entry.ContextMap()["signal"])
+
+	cluster.setStatus(0)
+	waitBootstrap(t, exp)
+	assert.Len(t, cluster.takePuts(), 4)
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	// The installation stops when the exporter shuts down
+	cluster.setStatus(http.StatusForbidden)
+	exp = startBootstrapLogs(t, set, server.URL, retryFast, func(cfg *Config) { cfg.Bootstrap.Version = 2 })
+	require.NoError(t, exp.Shutdown(context.Background()))
+	assert.Empty(t, cluster.takePuts())
+}
+
+func TestBootstrapIndexPatterns(t *testing.T) {
+	tests := map[string]struct {
+		modify   func(cfg *Config)
+		expected []string
+	}{
+		"index": {
+			modify:   func(*Config) {},
+			expected: []string{"logs-generic-default"},
+		},
+		"dynamic index": {
+			modify: func(cfg *Config) {
+				cfg.LogsDynamicIndex.Enabled = true
+				cfg.Bootstrap.Datasets = []string{"generic", "nginx"}
+			},
+			expected: []string{"logs-generic-*", "logs-nginx-*"},
+		},
+		"dynamic index in otel mode": {
+			modify: func(cfg *Config) {
+				cfg.Mapping.Mode = "otel"
+				cfg.LogsDynamicIndex.Enabled = true
+				cfg.Bootstrap.Datasets = []string{"generic", "nginx.otel"}
+			},
+			expected: []string{"logs-generic.otel-*", "logs-nginx.otel-*"},
+		},
+		"logstash format": {
+			modify: func(cfg *Config) {
+				cfg.LogsDynamicIndex.Enabled = true
+				cfg.LogstashFormat.Enabled = true
+			},
+			expected: []string{"logs-generic-*-*"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := withDefaultConfig(tt.modify)
+			patterns := bootstrapIndexPatterns(cfg, cfg.MappingMode(), defaultDataStreamTypeLogs, cfg.LogsIndex, cfg.LogsDynamicIndex.Enabled)
+			assert.Equal(t, tt.expected, patterns)
+		})
+	}
+}
+
+func TestBootstrapMappings(t *testing.T) {
+	// The mappings of every mapping mode and signal are installed as the mappings of a component template
+	for _, mode := range []MappingMode{MappingNone, MappingECS, MappingRaw, MappingOTel, MappingBodyMap} {
+		for _, signal := range []string{defaultDataStreamTypeLogs, defaultDataStreamTypeMetrics, defaultDataStreamTypeTraces} {
+			b := bootstrapper{meta: bootstrapMeta{MappingMode: mappingModeName(mode)}, signal: signal}
+			data, err := b.mappings()
+			require.NoError(t, err, mode.String())
+			var mappings map[string]any
+			require.NoError(t, json.Unmarshal(data, &mappings), mode.String())
+			assert.NotEmpty(t, mappings, mode.String())
+		}
+	}
+}
+
+func TestFormatTimeUnits(t *testing.T) {
+	for d, expected := range map[time.Duration]string{
+		48 * time.Hour:          "2d",
+		36 * time.Hour:          "36h",
+		90 * time.Minute:        "90m",
+		30 * time.Second:        "30s",
+		1500 * time.Millisecond: "1500ms",
+	} {
+		assert.Equal(t, expected, formatTimeUnits(d))
+	}
+}
@@ -69,6 +69,7 @@ type Config struct {
 	Flush                   FlushSettings          `mapstructure:"flush"`
 	Mapping                 MappingsSettings       `mapstructure:"mapping"`
 	LogstashFormat          LogstashFormatSettings `mapstructure:"logstash_format"`
+	Bootstrap               BootstrapSettings      `mapstructure:"bootstrap"`
 
 	// TelemetrySettings contains settings useful for testing/debugging purposes
 	// This is experimental and may change at any time.
@@ -111,6 +112,52 @@ type LogstashFormatSettings struct {
 	DateFormat      string `mapstructure:"date_format"`
 }
 
+// BootstrapSettings defines the installation of the lifecycle policy, the component templates and the
+// index template of the indices of the signal when the exporter starts.
+//
+// The resources are installed when they don't exist, and updated when they were installed by the exporter
+// with a lower version or another mapping mode. The resources installed by other means are left untouched.
+type BootstrapSettings struct {
+	// Enabled installs the resources at start.
+	Enabled bool `mapstructure:"enabled"`
+
+	// NamePrefix prefixes the names of the resources, which are followed by the signal.
+	NamePrefix string `mapstructure:"name_prefix"`
+
+	// Version of the resources, which must be increased to update the installed resources after
+	// changing their settings.
+	Version int `mapstructure:"version"`
+
+	// Priority of the index template, which must be higher than the priority of the other templates
+	// matching the indices.
+	Priority int `mapstructure:"priority"`
+
+	// Datasets are the datasets of the data streams matched by the index template when the dynamic index
+	// is enabled, so that the built-in templates still apply to the other data streams.
+	Datasets []string `mapstructure:"datasets"`
+
+	// ILM configures the lifecycle policy of the indices.
+	ILM ILMSettings `mapstructure:"ilm"`
+}
+
+// ILMSettings defines the index lifecycle management policy of the indices.
+//
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/index-lifecycle-management.html
+type ILMSettings struct {
+	// Enabled installs the lifecycle policy, and sets it in the settings of the indices.
+	Enabled bool `mapstructure:"enabled"`
+
+	// RolloverMaxAge configures the age of the data stream backing indices after which they are rolled over.
+	RolloverMaxAge time.Duration `mapstructure:"rollover_max_age"`
+
+	// RolloverMaxPrimaryShardSize configures the size of the largest primary shard of the data stream
+	// backing indices after which they are rolled over, e.g. 50gb.
+	RolloverMaxPrimaryShardSize string `mapstructure:"rollover_max_primary_shard_size"`
+
+	// DeleteAfter configures the age after which the indices are deleted. They aren't deleted if it is 0.
+	DeleteAfter time.Duration `mapstructure:"delete_after"`
+}
+
 type DynamicIndexSetting struct {
 	Enabled bool `mapstructure:"enabled"`
 }
@@ -275,6 +322,42 @@ func (cfg *Config) Validate() error {
 		return errors.New("retry::max_retries should be non-negative")
 	}
 
+	if cfg.Bootstrap.Enabled {
+		dynamicIndex := cfg.LogsDynamicIndex.Enabled || cfg.MetricsDynamicIndex.Enabled || cfg.TracesDynamicIndex.Enabled
+		if err := cfg.Bootstrap.validate(dynamicIndex); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate validates the bootstrap settings, which are only used when the bootstrap is enabled. The datasets
+// are only used when the dynamic index of a signal is enabled.
+func (cfg *BootstrapSettings) validate(dynamicIndex bool) error {
+	if cfg.NamePrefix == "" {
+		return errors.New("bootstrap::name_prefix must not be empty")
+	}
+	if cfg.Version < 1 {
+		return errors.New("bootstrap::version should be positive")
+	}
+	if cfg.Priority < 0 {
+		return errors.New("bootstrap::priority should be non-negative")
+	}
+	if dynamicIndex && len(cfg.Datasets) == 0 {
+		return errors.New("bootstrap::datasets must not be empty when the dynamic index is enabled")
+	}
+	for _, dataset := range cfg.Datasets {
+		if dataset == "" {
+			return errors.New("bootstrap::datasets must not contain empty datasets")
+		}
+	}
+	if cfg.ILM.RolloverMaxAge < 0 {
+		return errors.New("bootstrap::ilm::rollover_max_age should be non-negative")
+	}
+	if cfg.ILM.DeleteAfter < 0 {
+		return errors.New("bootstrap::ilm::delete_after should be non-negative")
+	}
 	return nil
 }
 
@@ -116,6 +116,17 @@ func TestConfig(t *testing.T) {
 					PrefixSeparator: "-",
 					DateFormat:      "%Y.%m.%d",
 				},
+				Bootstrap: BootstrapSettings{
+					NamePrefix: "otel",
+					Version:    1,
+					Priority:   150,
+					Datasets:   []string{"generic"},
+					ILM: ILMSettings{
+						Enabled:                     true,
+						RolloverMaxAge:              30 * 24 * time.Hour,
+						RolloverMaxPrimaryShardSize: "50gb",
+					},
+				},
 				Batcher: BatcherConfig{
 					FlushTimeout: 30 * time.Second,
 					MinSizeConfig: exporterbatcher.MinSizeConfig{ //nolint:staticcheck
@@ -190,6 +201,17 @@ func TestConfig(t *testing.T) {
 					PrefixSeparator: "-",
 					DateFormat:      "%Y.%m.%d",
 				},
+				Bootstrap: BootstrapSettings{
+					NamePrefix: "otel",
+					Version:    1,
+					Priority:   150,
+					Datasets:   []string{"generic"},
+					ILM: ILMSettings{
+						Enabled:                     true,
+						RolloverMaxAge:              30 * 24 * time.Hour,
+						RolloverMaxPrimaryShardSize: "50gb",
+					},
+				},
 				Batcher: BatcherConfig{
 					FlushTimeout: 30 * time.Second,
 					MinSizeConfig: exporterbatcher.MinSizeConfig{ //nolint:staticcheck
@@ -264,6 +286,17 @@ func TestConfig(t *testing.T) {
 					PrefixSeparator: "-",
 					DateFormat:      "%Y.%m.%d",
 				},
+				Bootstrap: BootstrapSettings{
+					NamePrefix: "otel",
+					Version:    1,
+					Priority:   150,
+					Datasets:   []string{"generic"},
+					ILM: ILMSettings{
+						Enabled:                     true,
+						RolloverMaxAge:              30 * 24 * time.Hour,
+						RolloverMaxPrimaryShardSize: "50gb",
+					},
+				},
 				Batcher: BatcherConfig{
 					FlushTimeout: 30 * time.Second,
 					MinSizeConfig: exporterbatcher.MinSizeConfig{ //nolint:staticcheck
@@ -327,6 +360,20 @@ func TestConfig(t *testing.T) {
 				cfg.Compression = "gzip"
 			}),
 		},
+		{
+			id:         component.NewIDWithName(metadata.Type, "bootstrap"),
+			configFile: "config.yaml",
+			expected: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoint = "https://elastic.example.com:9200"
+
+				cfg.Bootstrap.Enabled = true
+				cfg.Bootstrap.NamePrefix = "collector"
+				cfg.Bootstrap.Version = 2
+				cfg.Bootstrap.ILM.RolloverMaxAge = 24 * time.Hour
+				cfg.Bootstrap.ILM.DeleteAfter = 7 * 24 * time.Hour
+				cfg.Bootstrap.Datasets = []string{"generic", "nginx"}
+			}),
+		},
 	}
 
 	for _, tt := range tests {
@@ -425,6 +472,39 @@ func TestConfig_Validate(t *testing.T) {
 			}),
 			err: `must not specify both retry::max_requests and retry::max_retries`,
 		},
+		"bootstrap without name prefix": {
+			config: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://test:9200"}
+				cfg.Bootstrap.Enabled = true
+				cfg.Bootstrap.NamePrefix = ""
+			}),
+			err: `bootstrap::name_prefix must not be empty`,
+		},
+		"bootstrap version not positive": {
+			config: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://test:9200"}
+				cfg.Bootstrap.Enabled = true
+				cfg.Bootstrap.Version = 0
+			}),
+			err: `bootstrap::version should be positive`,
+		},
+		"bootstrap negative delete_after": {
+			config: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://test:9200"}
+				cfg.Bootstrap.Enabled = true
+				cfg.Bootstrap.ILM.DeleteAfter = -time.Hour
+			}),
+			err: `bootstrap::ilm::delete_after should be non-negative`,
+		},
+		"bootstrap without datasets": {
+			config: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://test:9200"}
+				cfg.Bootstrap.Enabled = true
+				cfg.Bootstrap.Datasets = nil
+				cfg.LogsDynamicIndex.Enabled = true
+			}),
+			err: `bootstrap::datasets must not be empty when the dynamic index is enabled`,
+		},
 	}
 
 	for name, tt := range tests {
@@ -434,6 +514,17 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate_BootstrapWithoutDatasets(t *testing.T) {
+	// The datasets aren't used without the dynamic index
+	config := withDefaultConfig(func(cfg *Config) {
+		cfg.Endpoints = []string{"http://test:9200"}
+		cfg.Bootstrap.Enabled = true
+		cfg.Bootstrap.Datasets = nil
+		cfg.MetricsDynamicIndex.Enabled = false
+	})
+	assert.NoError(t, xconfmap.Validate(config))
+}
+
 func TestConfig_Validate_Environment(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		t.Setenv("ELASTICSEARCH_URL", "http://test:9200")
@@ -32,6 +32,7 @@ type elasticsearchExporter struct {
 	userAgent string
 
 	config         *Config
+	signal         string
 	index          string
 	dynamicIndex   bool
 	logstashFormat LogstashFormatSettings
@@ -39,6 +40,10 @@ type elasticsearchExporter struct {
 	wg          sync.WaitGroup // active sessions
 	bulkIndexer bulkIndexer
 
+	// The resources of the indices are installed in the background until the exporter shuts down
+	bootstrapCancel context.CancelFunc
+	bootstrapDone   chan struct{}
+
 	// Profiles requires multiple bulk indexers depending on the data type
 	// Bulk indexer for profiling-events-*
 	biEvents bulkIndexer
@@ -55,6 +60,7 @@ type elasticsearchExporter struct {
 func newExporter(
 	cfg *Config,
 	set exporter.Settings,
+	signal string,
 	index string,
 	dynamicIndex bool,
 ) *elasticsearchExporter {
@@ -71,6 +77,7 @@ func newExporter(
 		userAgent:         userAgent,
 
 		config:         cfg,
+		signal:         signal,
 		index:          index,
 		dynamicIndex:   dynamicIndex,
 		logstashFormat: cfg.LogstashFormat,
@@ -83,6 +90,16 @@ func (e *elasticsearchExporter) Start(ctx context.Context, host component.Host)
 	if err != nil {
 		return err
 	}
+	if e.config.Bootstrap.Enabled && e.signal != "" {
+		b := newBootstrapper(client, e.config, e.signal, e.index, e.dynamicIndex, e.Logger)
+		bootstrapCtx, cancel := context.WithCancel(context.Background())
+		e.bootstrapCancel = cancel
+		e.bootstrapDone = make(chan struct{})
+		go func() {
+			defer close(e.bootstrapDone)
+			b.run(bootstrapCtx, e.config.Retry)
+		}()
+	}
 	bulkIndexer, err := newBulkIndexer(e.Logger, client, e.config, e.config.MappingMode() == MappingOTel)
 	if err != nil {
 		return err
@@ -113,6 +130,10 @@ func (e *elasticsearchExporter) Start(ctx context.Context, host component.Host)
 }
 
 func (e *elasticsearchExporter) Shutdown(ctx context.Context) error {
+	if e.bootstrapCancel != nil {
+		e.bootstrapCancel()
+		<-e.bootstrapDone
+	}
 	if e.bulkIndexer != nil {
 		if err := e.bulkIndexer.Close(ctx); err != nil {
 			return err
@@ -89,6 +89,18 @@ func createDefaultConfig() component.Config {
 			PrefixSeparator: "-",
 			DateFormat:      "%Y.%m.%d",
 		},
+		Bootstrap: BootstrapSettings{
+			Enabled:    false,
+			NamePrefix: "otel",
+			Version:    1,
+			Priority:   150,
+			Datasets:   []string{defaultDataStreamDataset},
+			ILM: ILMSettings{
+				Enabled:                     true,
+				RolloverMaxAge:              30 * 24 * time.Hour,
+				RolloverMaxPrimaryShardSize: "50gb",
+			},
+		},
 		TelemetrySettings: TelemetrySettings{
 			LogRequestBody:  false,
 			LogResponseBody: false,
@@ -121,7 +133,7 @@ func createLogsExporter(
 
 	handleDeprecatedConfig(cf, set.Logger)
 
-	exporter := newExporter(cf, set, cf.LogsIndex, cf.LogsDynamicIndex.Enabled)
+	exporter := newExporter(cf, set, defaultDataStreamTypeLogs, cf.LogsIndex, cf.LogsDynamicIndex.Enabled)
 
 	return exporterhelper.NewLogs(
 		ctx,
@@ -140,7 +152,7 @@ func createMetricsExporter(
 	cf := cfg.(*Config)
 	handleDeprecatedConfig(cf, set.Logger)
 
-	exporter := newExporter(cf, set, cf.MetricsIndex, cf.MetricsDynamicIndex.Enabled)
+	exporter := newExporter(cf, set, defaultDataStreamTypeMetrics, cf.MetricsIndex, cf.MetricsDynamicIndex.Enabled)
 
 	return exporterhelper.NewMetrics(
 		ctx,
@@ -158,7 +170,7 @@ func createTracesExporter(ctx context.Context,
 	cf := cfg.(*Config)
 	handleDeprecatedConfig(cf, set.Logger)
 
-	exporter := newExporter(cf, set, cf.TracesIndex, cf.TracesDynamicIndex.Enabled)
+	exporter := newExporter(cf, set, defaultDataStreamTypeTraces, cf.TracesIndex, cf.TracesDynamicIndex.Enabled)
 
 	return exporterhelper.NewTraces(
 		ctx,
@@ -181,7 +193,7 @@ func createProfilesExporter(
 
 	handleDeprecatedConfig(cf, set.Logger)
 
-	exporter := newExporter(cf, set, "", false)
+	exporter := newExporter(cf, set, "", "", false)
 
 	return xexporterhelper.NewProfilesExporter(
 		ctx,
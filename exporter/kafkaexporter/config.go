@@ -4,6 +4,7 @@
 package kafkaexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/kafkaexporter"
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -55,6 +56,19 @@ type Config struct {
 
 	PartitionLogsByResourceAttributes bool `mapstructure:"partition_logs_by_resource_attributes"`
 
+	// PartitionKeyExpression is an OTTL value expression whose value is the message key, evaluated for each
+	// span of traces, each log record of logs and each resource of metrics. It takes precedence over the
+	// other partitioning options.
+	PartitionKeyExpression string `mapstructure:"partition_key_expression"`
+
+	// TopicExpression is an OTTL value expression whose value is the topic of the message, evaluated in the
+	// same contexts as PartitionKeyExpression. The topic is selected as usual when the value is empty.
+	TopicExpression string `mapstructure:"topic_expression"`
+
+	// MessagePerRecord sends each span and each log record as a separate message. It has no effect on
+	// metrics.
+	MessagePerRecord bool `mapstructure:"message_per_record"`
+
 	// Metadata is the namespace for metadata management properties used by the
 	// Client, and shared by the Producer/Consumer.
 	Metadata Metadata `mapstructure:"metadata"`
@@ -102,6 +116,19 @@ type Producer struct {
 	// broker request. Defaults to 0 for unlimited. Similar to
 	// `queue.buffering.max.messages` in the JVM producer.
 	FlushMaxMessages int `mapstructure:"flush_max_messages"`
+
+	// Idempotent ensures that the messages retried by the producer are written exactly once.
+	// It requires RequiredAcks to be WaitForAll.
+	Idempotent bool `mapstructure:"idempotent"`
+
+	// TransactionalID enables the transactional producer, which commits the messages of each exporter
+	// request atomically. The ID is followed by the exporter ID, the signal of the exporter and
+	// TransactionalIDSuffix. It requires Idempotent to be true.
+	TransactionalID string `mapstructure:"transactional_id"`
+
+	// TransactionalIDSuffix suffixes the transactional ID, so that it's unique for each collector instance
+	// sharing the configuration, e.g. with the host name.
+	TransactionalIDSuffix string `mapstructure:"transactional_id_suffix"`
 }
 
 // MetadataRetry defines retry configuration for Metadata.
@@ -127,6 +154,24 @@ func (cfg *Config) Validate() error {
 		return err
 	}
 
+	if cfg.Producer.TransactionalID != "" && !cfg.Producer.Idempotent {
+		return errors.New("producer.transactional_id requires producer.idempotent to be true")
+	}
+	if cfg.Producer.TransactionalIDSuffix != "" && cfg.Producer.TransactionalID == "" {
+		return errors.New("producer.transactional_id_suffix requires producer.transactional_id to be set")
+	}
+	if cfg.Producer.Idempotent {
+		if cfg.Producer.RequiredAcks != sarama.WaitForAll {
+			return fmt.Errorf("producer.idempotent requires producer.required_acks to be -1. configured value %v", cfg.Producer.RequiredAcks)
+		}
+		if cfg.ProtocolVersion != "" {
+			version, err := sarama.ParseKafkaVersion(cfg.ProtocolVersion)
+			if err == nil && !version.IsAtLeast(sarama.V0_11_0_0) {
+				return fmt.Errorf("producer.idempotent requires protocol_version to be at least 0.11.0. configured value %v", cfg.ProtocolVersion)
+			}
+		}
+	}
+
 	return validateSASLConfig(cfg.Authentication.SASL)
 }
 
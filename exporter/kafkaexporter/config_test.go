@@ -295,6 +295,47 @@ func TestValidate_sasl_version(t *testing.T) {
 	assert.EqualError(t, err, "auth.sasl.version has to be either 0 or 1. configured value 42")
 }
 
+func TestValidate_idempotent(t *testing.T) {
+	tests := map[string]struct {
+		producer        Producer
+		protocolVersion string
+		expectedErr     string
+	}{
+		"transactional without idempotent": {
+			producer:    Producer{Compression: "none", RequiredAcks: sarama.WaitForAll, TransactionalID: "collector"},
+			expectedErr: "producer.transactional_id requires producer.idempotent to be true",
+		},
+		"transactional suffix without transactional id": {
+			producer:    Producer{Compression: "none", RequiredAcks: sarama.WaitForAll, Idempotent: true, TransactionalIDSuffix: "host"},
+			expectedErr: "producer.transactional_id_suffix requires producer.transactional_id to be set",
+		},
+		"required acks": {
+			producer:    Producer{Compression: "none", RequiredAcks: sarama.WaitForLocal, Idempotent: true},
+			expectedErr: "producer.idempotent requires producer.required_acks to be -1. configured value 1",
+		},
+		"protocol version": {
+			producer:        Producer{Compression: "none", RequiredAcks: sarama.WaitForAll, Idempotent: true},
+			protocolVersion: "0.10.2.0",
+			expectedErr:     "producer.idempotent requires protocol_version to be at least 0.11.0. configured value 0.10.2.0",
+		},
+		"transactional": {
+			producer:        Producer{Compression: "none", RequiredAcks: sarama.WaitForAll, Idempotent: true, TransactionalID: "collector"},
+			protocolVersion: "2.0.0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := &Config{Producer: test.producer, ProtocolVersion: test.protocolVersion}
+			err := config.Validate()
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
+
 func Test_saramaProducerCompressionCodec(t *testing.T) {
 	tests := map[string]struct {
 		compression         string
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/IBM/sarama"
 	"go.opentelemetry.io/collector/component"
@@ -20,16 +21,23 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/kafka"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/kafka/topic"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
 )
 
 var errUnrecognizedEncoding = fmt.Errorf("unrecognized encoding")
 
 // kafkaTracesProducer uses sarama to produce trace messages to Kafka.
 type kafkaTracesProducer struct {
-	cfg       Config
-	producer  sarama.SyncProducer
-	marshaler TracesMarshaler
-	logger    *zap.Logger
+	cfg         Config
+	producer    sarama.SyncProducer
+	marshaler   TracesMarshaler
+	expressions *messageExpressions[ottlspan.TransformContext]
+	id          component.ID
+	settings    component.TelemetrySettings
+	logger      *zap.Logger
 }
 
 type kafkaErrors struct {
@@ -42,7 +50,7 @@ func (ke kafkaErrors) Error() string {
 }
 
 func (e *kafkaTracesProducer) tracesPusher(ctx context.Context, td ptrace.Traces) error {
-	messages, err := e.marshaler.Marshal(td, getTopic(ctx, &e.cfg, td.ResourceSpans()))
+	messages, err := e.marshal(ctx, td)
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
@@ -59,6 +67,20 @@ func (e *kafkaTracesProducer) tracesPusher(ctx context.Context, td ptrace.Traces
 	return nil
 }
 
+// marshal marshals the traces into messages, split by the topic and key expressions or per record.
+func (e *kafkaTracesProducer) marshal(ctx context.Context, td ptrace.Traces) ([]*sarama.ProducerMessage, error) {
+	if e.expressions == nil && !e.cfg.MessagePerRecord {
+		return e.marshaler.Marshal(td, getTopic(ctx, &e.cfg, td.ResourceSpans()))
+	}
+	groups, err := splitTraces(ctx, td, e.expressions, e.cfg.MessagePerRecord)
+	if err != nil {
+		return nil, err
+	}
+	return toMessages(groups, func(data ptrace.Traces) string {
+		return getTopic(ctx, &e.cfg, data.ResourceSpans())
+	}, e.marshaler.Marshal)
+}
+
 func (e *kafkaTracesProducer) Close(context.Context) error {
 	if e.producer == nil {
 		return nil
@@ -83,7 +105,14 @@ func (e *kafkaTracesProducer) start(ctx context.Context, host component.Host) er
 	if e.marshaler == nil {
 		return errUnrecognizedEncoding
 	}
-	producer, err := newSaramaProducer(ctx, e.cfg)
+	parser, err := ottlspan.NewParser(ottlfuncs.StandardConverters[ottlspan.TransformContext](), e.settings)
+	if err != nil {
+		return err
+	}
+	if e.expressions, err = newMessageExpressions(parser, e.cfg); err != nil {
+		return err
+	}
+	producer, err := newSaramaProducer(ctx, e.cfg, e.id, "traces")
 	if err != nil {
 		return err
 	}
@@ -93,14 +122,17 @@ func (e *kafkaTracesProducer) start(ctx context.Context, host component.Host) er
 
 // kafkaMetricsProducer uses sarama to produce metrics messages to kafka
 type kafkaMetricsProducer struct {
-	cfg       Config
-	producer  sarama.SyncProducer
-	marshaler MetricsMarshaler
-	logger    *zap.Logger
+	cfg         Config
+	producer    sarama.SyncProducer
+	marshaler   MetricsMarshaler
+	expressions *messageExpressions[ottlresource.TransformContext]
+	id          component.ID
+	settings    component.TelemetrySettings
+	logger      *zap.Logger
 }
 
 func (e *kafkaMetricsProducer) metricsDataPusher(ctx context.Context, md pmetric.Metrics) error {
-	messages, err := e.marshaler.Marshal(md, getTopic(ctx, &e.cfg, md.ResourceMetrics()))
+	messages, err := e.marshal(ctx, md)
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
@@ -117,6 +149,20 @@ func (e *kafkaMetricsProducer) metricsDataPusher(ctx context.Context, md pmetric
 	return nil
 }
 
+// marshal marshals the metrics into messages, split by the topic and key expressions.
+func (e *kafkaMetricsProducer) marshal(ctx context.Context, md pmetric.Metrics) ([]*sarama.ProducerMessage, error) {
+	if e.expressions == nil {
+		return e.marshaler.Marshal(md, getTopic(ctx, &e.cfg, md.ResourceMetrics()))
+	}
+	groups, err := splitMetrics(ctx, md, e.expressions)
+	if err != nil {
+		return nil, err
+	}
+	return toMessages(groups, func(data pmetric.Metrics) string {
+		return getTopic(ctx, &e.cfg, data.ResourceMetrics())
+	}, e.marshaler.Marshal)
+}
+
 func (e *kafkaMetricsProducer) Close(context.Context) error {
 	if e.producer == nil {
 		return nil
@@ -141,7 +187,14 @@ func (e *kafkaMetricsProducer) start(ctx context.Context, host component.Host) e
 	if e.marshaler == nil {
 		return errUnrecognizedEncoding
 	}
-	producer, err := newSaramaProducer(ctx, e.cfg)
+	parser, err := ottlresource.NewParser(ottlfuncs.StandardConverters[ottlresource.TransformContext](), e.settings)
+	if err != nil {
+		return err
+	}
+	if e.expressions, err = newMessageExpressions(parser, e.cfg); err != nil {
+		return err
+	}
+	producer, err := newSaramaProducer(ctx, e.cfg, e.id, "metrics")
 	if err != nil {
 		return err
 	}
@@ -151,14 +204,17 @@ func (e *kafkaMetricsProducer) start(ctx context.Context, host component.Host) e
 
 // kafkaLogsProducer uses sarama to produce logs messages to kafka
 type kafkaLogsProducer struct {
-	cfg       Config
-	producer  sarama.SyncProducer
-	marshaler LogsMarshaler
-	logger    *zap.Logger
+	cfg         Config
+	producer    sarama.SyncProducer
+	marshaler   LogsMarshaler
+	expressions *messageExpressions[ottllog.TransformContext]
+	id          component.ID
+	settings    component.TelemetrySettings
+	logger      *zap.Logger
 }
 
 func (e *kafkaLogsProducer) logsDataPusher(ctx context.Context, ld plog.Logs) error {
-	messages, err := e.marshaler.Marshal(ld, getTopic(ctx, &e.cfg, ld.ResourceLogs()))
+	messages, err := e.marshal(ctx, ld)
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
@@ -175,6 +231,20 @@ func (e *kafkaLogsProducer) logsDataPusher(ctx context.Context, ld plog.Logs) er
 	return nil
 }
 
+// marshal marshals the logs into messages, split by the topic and key expressions or per record.
+func (e *kafkaLogsProducer) marshal(ctx context.Context, ld plog.Logs) ([]*sarama.ProducerMessage, error) {
+	if e.expressions == nil && !e.cfg.MessagePerRecord {
+		return e.marshaler.Marshal(ld, getTopic(ctx, &e.cfg, ld.ResourceLogs()))
+	}
+	groups, err := splitLogs(ctx, ld, e.expressions, e.cfg.MessagePerRecord)
+	if err != nil {
+		return nil, err
+	}
+	return toMessages(groups, func(data plog.Logs) string {
+		return getTopic(ctx, &e.cfg, data.ResourceLogs())
+	}, e.marshaler.Marshal)
+}
+
 func (e *kafkaLogsProducer) Close(context.Context) error {
 	if e.producer == nil {
 		return nil
@@ -199,7 +269,14 @@ func (e *kafkaLogsProducer) start(ctx context.Context, host component.Host) erro
 	if e.marshaler == nil {
 		return errUnrecognizedEncoding
 	}
-	producer, err := newSaramaProducer(ctx, e.cfg)
+	parser, err := ottllog.NewParser(ottlfuncs.StandardConverters[ottllog.TransformContext](), e.settings)
+	if err != nil {
+		return err
+	}
+	if e.expressions, err = newMessageExpressions(parser, e.cfg); err != nil {
+		return err
+	}
+	producer, err := newSaramaProducer(ctx, e.cfg, e.id, "logs")
 	if err != nil {
 		return err
 	}
@@ -207,7 +284,8 @@ func (e *kafkaLogsProducer) start(ctx context.Context, host component.Host) erro
 	return nil
 }
 
-func newSaramaProducer(ctx context.Context, config Config) (sarama.SyncProducer, error) {
+// newSaramaProducer creates the producer of the signal of the exporter.
+func newSaramaProducer(ctx context.Context, config Config, id component.ID, signal string) (sarama.SyncProducer, error) {
 	c := sarama.NewConfig()
 
 	c.ClientID = config.ClientID
@@ -223,6 +301,14 @@ func newSaramaProducer(ctx context.Context, config Config) (sarama.SyncProducer,
 	c.Metadata.Retry.Backoff = config.Metadata.Retry.Backoff
 	c.Producer.MaxMessageBytes = config.Producer.MaxMessageBytes
 	c.Producer.Flush.MaxMessages = config.Producer.FlushMaxMessages
+	if config.Producer.Idempotent {
+		c.Producer.Idempotent = true
+		// The ordering of the retried messages is only guaranteed with a single in-flight request
+		c.Net.MaxOpenRequests = 1
+	}
+	if config.Producer.TransactionalID != "" {
+		c.Producer.Transaction.ID = transactionalID(config.Producer, id, signal)
+	}
 
 	if config.ResolveCanonicalBootstrapServersOnly {
 		c.Net.ResolveCanonicalBootstrapServers = true
@@ -250,28 +336,125 @@ func newSaramaProducer(ctx context.Context, config Config) (sarama.SyncProducer,
 	if err != nil {
 		return nil, err
 	}
+	if producer.IsTransactional() {
+		return &transactionalProducer{
+			SyncProducer: producer,
+			newProducer: func() (sarama.SyncProducer, error) {
+				return sarama.NewSyncProducer(config.Brokers, c)
+			},
+		}, nil
+	}
 	return producer, nil
 }
 
+// transactionalID returns the transactional ID of the producer of the signal of the exporter: the configured ID,
+// followed by the exporter ID, the signal and the suffix when there is one, e.g. `collector-kafka/primary-traces`.
+func transactionalID(config Producer, id component.ID, signal string) string {
+	transactionalID := config.TransactionalID + "-" + id.String() + "-" + signal
+	if config.TransactionalIDSuffix != "" {
+		transactionalID += "-" + config.TransactionalIDSuffix
+	}
+	return transactionalID
+}
+
+// transactionalProducer sends the messages of each call to SendMessages in a transaction, so that the
+// messages of an exporter request are committed atomically. The producer has a single transaction at a
+// time, the concurrent requests are sent one after the other. A producer in a fatal error state, e.g. fenced by
+// another producer with the same transactional ID, can't be used anymore, so it's replaced by a new one.
+type transactionalProducer struct {
+	sarama.SyncProducer
+	newProducer func() (sarama.SyncProducer, error)
+	mu          sync.Mutex
+	// closed is whether the producer was closed after a fatal error, and not replaced yet.
+	closed bool
+}
+
+func (p *transactionalProducer) SendMessages(messages []*sarama.ProducerMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		if err := p.replace(); err != nil {
+			return err
+		}
+	}
+	err := p.sendMessages(messages)
+	if err != nil && p.TxnStatus()&sarama.ProducerTxnFlagFatalError != 0 {
+		// The producer is closed now, and replaced now or by the next request when it fails.
+		// Its close error is expected after a fatal error.
+		_ = p.SyncProducer.Close()
+		p.closed = true
+		if replaceErr := p.replace(); replaceErr != nil {
+			return errors.Join(err, replaceErr)
+		}
+	}
+	return err
+}
+
+func (p *transactionalProducer) sendMessages(messages []*sarama.ProducerMessage) error {
+	if err := p.BeginTxn(); err != nil {
+		return fmt.Errorf("failed to begin the transaction: %w", err)
+	}
+	if err := p.SyncProducer.SendMessages(messages); err != nil {
+		if abortErr := p.AbortTxn(); abortErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to abort the transaction: %w", abortErr))
+		}
+		return err
+	}
+	if err := p.CommitTxn(); err != nil {
+		if abortErr := p.AbortTxn(); abortErr != nil {
+			return errors.Join(fmt.Errorf("failed to commit the transaction: %w", err), fmt.Errorf("failed to abort the transaction: %w", abortErr))
+		}
+		return fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+	return nil
+}
+
+// replace replaces the closed producer by a new one.
+func (p *transactionalProducer) replace() error {
+	producer, err := p.newProducer()
+	if err != nil {
+		return fmt.Errorf("failed to create a new transactional producer: %w", err)
+	}
+	p.SyncProducer = producer
+	p.closed = false
+	return nil
+}
+
+func (p *transactionalProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.SyncProducer.Close()
+}
+
 func newMetricsExporter(config Config, set exporter.Settings) *kafkaMetricsProducer {
 	return &kafkaMetricsProducer{
-		cfg:    config,
-		logger: set.Logger,
+		cfg:      config,
+		id:       set.ID,
+		settings: set.TelemetrySettings,
+		logger:   set.Logger,
 	}
 }
 
 // newTracesExporter creates Kafka exporter.
 func newTracesExporter(config Config, set exporter.Settings) *kafkaTracesProducer {
 	return &kafkaTracesProducer{
-		cfg:    config,
-		logger: set.Logger,
+		cfg:      config,
+		id:       set.ID,
+		settings: set.TelemetrySettings,
+		logger:   set.Logger,
 	}
 }
 
 func newLogsExporter(config Config, set exporter.Settings) *kafkaLogsProducer {
 	return &kafkaLogsProducer{
-		cfg:    config,
-		logger: set.Logger,
+		cfg:      config,
+		id:       set.ID,
+		settings: set.TelemetrySettings,
+		logger:   set.Logger,
 	}
 }
 
@@ -5,6 +5,7 @@ package kafkaexporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -400,6 +401,128 @@ func TestLogsDataPusher_err(t *testing.T) {
 	assert.EqualError(t, err, expErr.Error())
 }
 
+func TestLogsDataPusher_transactional(t *testing.T) {
+	c := sarama.NewConfig()
+	c.Producer.Idempotent = true
+	c.Producer.RequiredAcks = sarama.WaitForAll
+	c.Net.MaxOpenRequests = 1
+	c.Producer.Transaction.ID = "collector-logs"
+	producer := mocks.NewSyncProducer(t, c)
+	producer.ExpectSendMessageAndSucceed()
+
+	p := kafkaLogsProducer{
+		producer:  &transactionalProducer{SyncProducer: producer},
+		marshaler: newPdataLogsMarshaler(&plog.ProtoMarshaler{}, defaultEncoding, false),
+		logger:    zap.NewNop(),
+	}
+	t.Cleanup(func() {
+		require.NoError(t, p.Close(context.Background()))
+	})
+	// The mock producer fails to send messages outside of a transaction
+	err := p.logsDataPusher(context.Background(), testdata.GenerateLogs(1))
+	require.NoError(t, err)
+	assert.Equal(t, sarama.ProducerTxnFlagReady, producer.TxnStatus())
+}
+
+func TestLogsDataPusher_transactional_err(t *testing.T) {
+	c := sarama.NewConfig()
+	c.Producer.Idempotent = true
+	c.Producer.RequiredAcks = sarama.WaitForAll
+	c.Net.MaxOpenRequests = 1
+	c.Producer.Transaction.ID = "collector-logs"
+	producer := mocks.NewSyncProducer(t, c)
+	expErr := fmt.Errorf("failed to send")
+	producer.ExpectSendMessageAndFail(expErr)
+
+	p := kafkaLogsProducer{
+		producer:  &transactionalProducer{SyncProducer: producer},
+		marshaler: newPdataLogsMarshaler(&plog.ProtoMarshaler{}, defaultEncoding, false),
+		logger:    zap.NewNop(),
+	}
+	t.Cleanup(func() {
+		require.NoError(t, p.Close(context.Background()))
+	})
+	err := p.logsDataPusher(context.Background(), testdata.GenerateLogs(1))
+	assert.EqualError(t, err, expErr.Error())
+	// The transaction is aborted
+	assert.Equal(t, sarama.ProducerTxnFlagReady, producer.TxnStatus())
+}
+
+// fatalTxnProducer is a transactional producer failing to commit with a fatal error.
+type fatalTxnProducer struct {
+	*mocks.SyncProducer
+	closed bool
+}
+
+func (*fatalTxnProducer) CommitTxn() error {
+	return errors.New("producer fenced")
+}
+
+func (*fatalTxnProducer) AbortTxn() error {
+	return errors.New("producer fenced")
+}
+
+func (*fatalTxnProducer) TxnStatus() sarama.ProducerTxnStatusFlag {
+	return sarama.ProducerTxnFlagInError | sarama.ProducerTxnFlagFatalError
+}
+
+func (p *fatalTxnProducer) Close() error {
+	p.closed = true
+	return p.SyncProducer.Close()
+}
+
+func TestLogsDataPusher_transactional_fatal(t *testing.T) {
+	c := sarama.NewConfig()
+	c.Producer.Idempotent = true
+	c.Producer.RequiredAcks = sarama.WaitForAll
+	c.Net.MaxOpenRequests = 1
+	c.Producer.Transaction.ID = "collector-logs"
+	fatal := &fatalTxnProducer{SyncProducer: mocks.NewSyncProducer(t, c)}
+	fatal.ExpectSendMessageAndSucceed()
+	producer := mocks.NewSyncProducer(t, c)
+	producer.ExpectSendMessageAndSucceed()
+	created := 0
+	newProducerErr := errors.New("broker unavailable")
+
+	p := kafkaLogsProducer{
+		producer: &transactionalProducer{
+			SyncProducer: fatal,
+			newProducer: func() (sarama.SyncProducer, error) {
+				if newProducerErr != nil {
+					return nil, newProducerErr
+				}
+				created++
+				return producer, nil
+			},
+		},
+		marshaler: newPdataLogsMarshaler(&plog.ProtoMarshaler{}, defaultEncoding, false),
+		logger:    zap.NewNop(),
+	}
+	t.Cleanup(func() {
+		require.NoError(t, p.Close(context.Background()))
+	})
+	err := p.logsDataPusher(context.Background(), testdata.GenerateLogs(1))
+	assert.EqualError(t, err, "failed to commit the transaction: producer fenced\n"+
+		"failed to abort the transaction: producer fenced\n"+
+		"failed to create a new transactional producer: broker unavailable")
+	// The producer in a fatal error state is closed, and replaced by the next request
+	assert.True(t, fatal.closed)
+
+	newProducerErr = nil
+	err = p.logsDataPusher(context.Background(), testdata.GenerateLogs(1))
+	require.NoError(t, err)
+	assert.Equal(t, sarama.ProducerTxnFlagReady, producer.TxnStatus())
+	assert.Equal(t, 1, created)
+}
+
+func TestTransactionalID(t *testing.T) {
+	id := component.MustNewIDWithName("kafka", "primary")
+	assert.Equal(t, "collector-kafka/primary-traces",
+		transactionalID(Producer{TransactionalID: "collector"}, id, "traces"))
+	assert.Equal(t, "collector-kafka/primary-logs-host-1",
+		transactionalID(Producer{TransactionalID: "collector", TransactionalIDSuffix: "host-1"}, id, "logs"))
+}
+
 func TestLogsDataPusher_marshal_error(t *testing.T) {
 	expErr := fmt.Errorf("failed to marshal")
 	p := kafkaLogsProducer{
@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/zipkin/zipkinv2"
 )
 
@@ -153,6 +154,20 @@ type logsEncodingMarshaler struct {
 
 func (l *logsEncodingMarshaler) Marshal(logs plog.Logs, topic string) ([]*sarama.ProducerMessage, error) {
 	var messages []*sarama.ProducerMessage
+	// The formats holding a single log record are sent as one message per log record
+	if marshaler, ok := l.marshaler.(encoding.LogRecordsMarshaler); ok {
+		records, err := marshaler.MarshalLogRecords(logs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal logs: %w", err)
+		}
+		for _, data := range records {
+			messages = append(messages, &sarama.ProducerMessage{
+				Topic: topic,
+				Value: sarama.ByteEncoder(data),
+			})
+		}
+		return messages, nil
+	}
 	data, err := l.marshaler.MarshalLogs(logs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal logs: %w", err)
@@ -5,6 +5,7 @@ package kafkaexporter
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -18,6 +19,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
 )
 
 func TestDefaultTracesMarshalers(t *testing.T) {
@@ -570,3 +573,43 @@ func TestLogsEncodingMarshaler_error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, data)
 }
+
+var _ encoding.LogRecordsMarshaler = (*encodingLogRecordsMarshaler)(nil)
+
+type encodingLogRecordsMarshaler struct{}
+
+func (m *encodingLogRecordsMarshaler) MarshalLogs(plog.Logs) ([]byte, error) {
+	return nil, errors.New("a single log record is supported")
+}
+
+func (m *encodingLogRecordsMarshaler) MarshalLogRecords(logs plog.Logs) ([][]byte, error) {
+	var records [][]byte
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		scopeLogs := logs.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			for k := 0; k < scopeLogs.At(j).LogRecords().Len(); k++ {
+				records = append(records, []byte(scopeLogs.At(j).LogRecords().At(k).Body().Str()))
+			}
+		}
+	}
+	return records, nil
+}
+
+func TestLogsEncodingMarshaler_logRecords(t *testing.T) {
+	m := &logsEncodingMarshaler{
+		marshaler: &encodingLogRecordsMarshaler{},
+		encoding:  "logs_encoding",
+	}
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetStr("first")
+	records.AppendEmpty().Body().SetStr("second")
+
+	messages, err := m.Marshal(logs, "topic")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	for i, expected := range []string{"first", "second"} {
+		assert.Equal(t, "topic", messages[i].Topic)
+		assert.Equal(t, sarama.ByteEncoder(expected), messages[i].Value)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/kafkaexporter"
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
+)
+
+// messageExpressions computes the topic and the key of the messages from OTTL value expressions.
+// The expressions which aren't configured are nil.
+type messageExpressions[K any] struct {
+	topic *ottl.ValueExpression[K]
+	key   *ottl.ValueExpression[K]
+}
+
+// newMessageExpressions parses the topic and partition key expressions of the config. It returns nil when
+// none is configured.
+func newMessageExpressions[K any](parser ottl.Parser[K], cfg Config) (*messageExpressions[K], error) {
+	if cfg.TopicExpression == "" && cfg.PartitionKeyExpression == "" {
+		return nil, nil
+	}
+	exprs := &messageExpressions[K]{}
+	var err error
+	if cfg.TopicExpression != "" {
+		if exprs.topic, err = parser.ParseValueExpression(cfg.TopicExpression); err != nil {
+			return nil, fmt.Errorf("failed to parse topic_expression: %w", err)
+		}
+	}
+	if cfg.PartitionKeyExpression != "" {
+		if exprs.key, err = parser.ParseValueExpression(cfg.PartitionKeyExpression); err != nil {
+			return nil, fmt.Errorf("failed to parse partition_key_expression: %w", err)
+		}
+	}
+	return exprs, nil
+}
+
+// eval returns the topic and the key of a message. The topic is empty and the key is nil when their
+// expression isn't configured or evaluates to nil.
+func (e *messageExpressions[K]) eval(ctx context.Context, tCtx K) (string, []byte, error) {
+	if e == nil {
+		return "", nil, nil
+	}
+	var topic string
+	var key []byte
+	if e.topic != nil {
+		v, err := e.topic.Eval(ctx, tCtx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to evaluate topic_expression: %w", err)
+		}
+		topic = string(toBytes(v))
+	}
+	if e.key != nil {
+		v, err := e.key.Eval(ctx, tCtx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to evaluate partition_key_expression: %w", err)
+		}
+		key = toBytes(v)
+	}
+	return topic, key, nil
+}
+
+// toBytes converts the value of an expression. The trace and span IDs are hex encoded, as the trace IDs
+// of partition_traces_by_id.
+func toBytes(v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	case pcommon.TraceID:
+		return []byte(traceutil.TraceIDToHexOrEmptyString(v))
+	case pcommon.SpanID:
+		return []byte(traceutil.SpanIDToHexOrEmptyString(v))
+	case pcommon.Value:
+		if v.Type() == pcommon.ValueTypeEmpty {
+			return nil
+		}
+		if v.Type() == pcommon.ValueTypeBytes {
+			return v.Bytes().AsRaw()
+		}
+		return []byte(v.AsString())
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// messageGroup holds the telemetry sent as a separate message, with the topic and the key computed by the
+// expressions. The topic is empty when the default topic is used, and the key is nil when the key of the
+// marshaler is used.
+type messageGroup[T any] struct {
+	data  T
+	topic string
+	key   []byte
+}
+
+// messageGroups groups the telemetry by topic and key, keeping the order in which the groups are created.
+type messageGroups[T any] struct {
+	groups  []*messageGroup[T]
+	byKey   map[string]*messageGroup[T]
+	newData func() T
+}
+
+func newMessageGroups[T any](newData func() T) *messageGroups[T] {
+	return &messageGroups[T]{byKey: map[string]*messageGroup[T]{}, newData: newData}
+}
+
+// get returns the group of the topic and key. When unique is true, a new group is always created.
+func (g *messageGroups[T]) get(topic string, key []byte, unique bool) *messageGroup[T] {
+	id := topic + "\x00" + string(key)
+	if group, ok := g.byKey[id]; ok && !unique {
+		return group
+	}
+	group := &messageGroup[T]{data: g.newData(), topic: topic, key: key}
+	g.groups = append(g.groups, group)
+	if !unique {
+		g.byKey[id] = group
+	}
+	return group
+}
+
+// toMessages marshals each group into messages, with the topic returned by defaultTopic when the group
+// has none.
+func toMessages[T any](
+	groups []*messageGroup[T],
+	defaultTopic func(data T) string,
+	marshal func(data T, topic string) ([]*sarama.ProducerMessage, error),
+) ([]*sarama.ProducerMessage, error) {
+	var messages []*sarama.ProducerMessage
+	for _, group := range groups {
+		topic := group.topic
+		if topic == "" {
+			topic = defaultTopic(group.data)
+		}
+		msgs, err := marshal(group.data, topic)
+		if err != nil {
+			return nil, err
+		}
+		if group.key != nil {
+			for _, msg := range msgs {
+				msg.Key = sarama.ByteEncoder(group.key)
+			}
+		}
+		messages = append(messages, msgs...)
+	}
+	return messages, nil
+}
+
+// splitLogs groups the log records by the topic and key computed by the expressions, or puts each log
+// record in its own group when perRecord is true.
+func splitLogs(ctx context.Context, ld plog.Logs, exprs *messageExpressions[ottllog.TransformContext], perRecord bool) ([]*messageGroup[plog.Logs], error) {
+	groups := newMessageGroups(plog.NewLogs)
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			// The destination scopes of the groups, to avoid copying the resource and scope for each record
+			scopes := map[*messageGroup[plog.Logs]]plog.ScopeLogs{}
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				record := sl.LogRecords().At(k)
+				topic, key, err := exprs.eval(ctx, ottllog.NewTransformContext(record, sl.Scope(), rl.Resource(), sl, rl))
+				if err != nil {
+					return nil, err
+				}
+				group := groups.get(topic, key, perRecord)
+				scope, ok := scopes[group]
+				if !ok {
+					newRL := group.data.ResourceLogs().AppendEmpty()
+					rl.Resource().CopyTo(newRL.Resource())
+					newRL.SetSchemaUrl(rl.SchemaUrl())
+					scope = newRL.ScopeLogs().AppendEmpty()
+					sl.Scope().CopyTo(scope.Scope())
+					scope.SetSchemaUrl(sl.SchemaUrl())
+					scopes[group] = scope
+				}
+				record.CopyTo(scope.LogRecords().AppendEmpty())
+			}
+		}
+	}
+	return groups.groups, nil
+}
+
+// splitTraces groups the spans by the topic and key computed by the expressions, or puts each span in its
+// own group when perRecord is true.
+func splitTraces(ctx context.Context, td ptrace.Traces, exprs *messageExpressions[ottlspan.TransformContext], perRecord bool) ([]*messageGroup[ptrace.Traces], error) {
+	groups := newMessageGroups(ptrace.NewTraces)
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		rs := td.ResourceSpans().At(i)
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			// The destination scopes of the groups, to avoid copying the resource and scope for each span
+			scopes := map[*messageGroup[ptrace.Traces]]ptrace.ScopeSpans{}
+			for k := 0; k < ss.Spans().Len(); k++ {
+				span := ss.Spans().At(k)
+				topic, key, err := exprs.eval(ctx, ottlspan.NewTransformContext(span, ss.Scope(), rs.Resource(), ss, rs))
+				if err != nil {
+					return nil, err
+				}
+				group := groups.get(topic, key, perRecord)
+				scope, ok := scopes[group]
+				if !ok {
+					newRS := group.data.ResourceSpans().AppendEmpty()
+					rs.Resource().CopyTo(newRS.Resource())
+					newRS.SetSchemaUrl(rs.SchemaUrl())
+					scope = newRS.ScopeSpans().AppendEmpty()
+					ss.Scope().CopyTo(scope.Scope())
+					scope.SetSchemaUrl(ss.SchemaUrl())
+					scopes[group] = scope
+				}
+				span.CopyTo(scope.Spans().AppendEmpty())
+			}
+		}
+	}
+	return groups.groups, nil
+}
+
+// splitMetrics groups the resources by the topic and key computed by the expressions.
+func splitMetrics(ctx context.Context, md pmetric.Metrics, exprs *messageExpressions[ottlresource.TransformContext]) ([]*messageGroup[pmetric.Metrics], error) {
+	groups := newMessageGroups(pmetric.NewMetrics)
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		topic, key, err := exprs.eval(ctx, ottlresource.NewTransformContext(rm.Resource(), rm))
+		if err != nil {
+			return nil, err
+		}
+		rm.CopyTo(groups.get(topic, key, false).data.ResourceMetrics().AppendEmpty())
+	}
+	return groups.groups, nil
+}
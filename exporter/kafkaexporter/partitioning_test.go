@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkaexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+)
+
+func newLogExpressions(t *testing.T, cfg Config) *messageExpressions[ottllog.TransformContext] {
+	parser, err := ottllog.NewParser(ottlfuncs.StandardConverters[ottllog.TransformContext](), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	exprs, err := newMessageExpressions(parser, cfg)
+	require.NoError(t, err)
+	return exprs
+}
+
+// testLogs returns logs of two resources, each with a scope holding the given tenants as log records.
+func testLogs(tenants ...string) plog.Logs {
+	logs := plog.NewLogs()
+	for _, service := range []string{"service1", "service2"} {
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", service)
+		sl := rl.ScopeLogs().AppendEmpty()
+		sl.Scope().SetName("scope")
+		for _, tenant := range tenants {
+			record := sl.LogRecords().AppendEmpty()
+			record.Body().SetStr(service + "/" + tenant)
+			if tenant != "" {
+				record.Attributes().PutStr("tenant", tenant)
+			}
+		}
+	}
+	return logs
+}
+
+func bodies(logs plog.Logs) []string {
+	var result []string
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		rl := logs.ResourceLogs().At(i)
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			records := rl.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				result = append(result, records.At(k).Body().Str())
+			}
+		}
+	}
+	return result
+}
+
+func TestNewMessageExpressions(t *testing.T) {
+	parser, err := ottllog.NewParser(ottlfuncs.StandardConverters[ottllog.TransformContext](), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	exprs, err := newMessageExpressions(parser, Config{})
+	require.NoError(t, err)
+	assert.Nil(t, exprs)
+
+	exprs, err = newMessageExpressions(parser, Config{PartitionKeyExpression: `attributes["tenant"]`})
+	require.NoError(t, err)
+	assert.Nil(t, exprs.topic)
+	assert.NotNil(t, exprs.key)
+
+	_, err = newMessageExpressions(parser, Config{TopicExpression: `attributes[`})
+	assert.ErrorContains(t, err, "failed to parse topic_expression")
+
+	_, err = newMessageExpressions(parser, Config{PartitionKeyExpression: `Unknown(body)`})
+	assert.ErrorContains(t, err, "failed to parse partition_key_expression")
+}
+
+func TestSplitLogs(t *testing.T) {
+	exprs := newLogExpressions(t, Config{
+		TopicExpression:        `Concat(["logs", attributes["tenant"]], "-")`,
+		PartitionKeyExpression: `resource.attributes["service.name"]`,
+	})
+	groups, err := splitLogs(context.Background(), testLogs("a", "b", "a"), exprs, false)
+	require.NoError(t, err)
+
+	require.Len(t, groups, 4)
+	expected := []struct {
+		topic  string
+		key    string
+		bodies []string
+	}{
+		{topic: "logs-a", key: "service1", bodies: []string{"service1/a", "service1/a"}},
+		{topic: "logs-b", key: "service1", bodies: []string{"service1/b"}},
+		{topic: "logs-a", key: "service2", bodies: []string{"service2/a", "service2/a"}},
+		{topic: "logs-b", key: "service2", bodies: []string{"service2/b"}},
+	}
+	for i, group := range groups {
+		assert.Equal(t, expected[i].topic, group.topic)
+		assert.Equal(t, []byte(expected[i].key), group.key)
+		assert.Equal(t, expected[i].bodies, bodies(group.data))
+		// The resource and scope of the records are kept
+		rl := group.data.ResourceLogs().At(0)
+		assert.Equal(t, expected[i].key, rl.Resource().Attributes().AsRaw()["service.name"])
+		assert.Equal(t, "scope", rl.ScopeLogs().At(0).Scope().Name())
+	}
+}
+
+func TestSplitLogs_perRecord(t *testing.T) {
+	groups, err := splitLogs(context.Background(), testLogs("a", "a"), nil, true)
+	require.NoError(t, err)
+
+	require.Len(t, groups, 4)
+	for i, body := range []string{"service1/a", "service1/a", "service2/a", "service2/a"} {
+		assert.Empty(t, groups[i].topic)
+		assert.Nil(t, groups[i].key)
+		assert.Equal(t, []string{body}, bodies(groups[i].data))
+	}
+}
+
+func TestSplitLogs_nilValues(t *testing.T) {
+	exprs := newLogExpressions(t, Config{
+		TopicExpression:        `attributes["tenant"]`,
+		PartitionKeyExpression: `attributes["tenant"]`,
+	})
+	groups, err := splitLogs(context.Background(), testLogs("", "a"), exprs, false)
+	require.NoError(t, err)
+
+	require.Len(t, groups, 2)
+	assert.Empty(t, groups[0].topic)
+	assert.Nil(t, groups[0].key)
+	assert.Equal(t, []string{"service1/", "service2/"}, bodies(groups[0].data))
+	assert.Equal(t, "a", groups[1].topic)
+	assert.Equal(t, []byte("a"), groups[1].key)
+}
+
+func TestSplitTraces(t *testing.T) {
+	parser, err := ottlspan.NewParser(ottlfuncs.StandardConverters[ottlspan.TransformContext](), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	exprs, err := newMessageExpressions(parser, Config{PartitionKeyExpression: `trace_id`})
+	require.NoError(t, err)
+
+	traces := ptrace.NewTraces()
+	spans := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	for _, traceID := range []pcommon.TraceID{{1}, {2}, {1}} {
+		spans.AppendEmpty().SetTraceID(traceID)
+	}
+
+	groups, err := splitTraces(context.Background(), traces, exprs, false)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, 2, groups[0].data.SpanCount())
+	assert.Equal(t, []byte("01000000000000000000000000000000"), groups[0].key)
+	assert.Equal(t, 1, groups[1].data.SpanCount())
+	assert.Equal(t, []byte("02000000000000000000000000000000"), groups[1].key)
+
+	groups, err = splitTraces(context.Background(), traces, exprs, true)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	for _, group := range groups {
+		assert.Equal(t, 1, group.data.SpanCount())
+	}
+}
+
+func TestSplitMetrics(t *testing.T) {
+	parser, err := ottlresource.NewParser(ottlfuncs.StandardConverters[ottlresource.TransformContext](), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	exprs, err := newMessageExpressions(parser, Config{TopicExpression: `attributes["topic"]`})
+	require.NoError(t, err)
+
+	metrics := pmetric.NewMetrics()
+	for _, topic := range []string{"metrics-a", "metrics-b", "metrics-a"} {
+		metrics.ResourceMetrics().AppendEmpty().Resource().Attributes().PutStr("topic", topic)
+	}
+
+	groups, err := splitMetrics(context.Background(), metrics, exprs)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "metrics-a", groups[0].topic)
+	assert.Equal(t, 2, groups[0].data.ResourceMetrics().Len())
+	assert.Nil(t, groups[0].key)
+	assert.Equal(t, "metrics-b", groups[1].topic)
+	assert.Equal(t, 1, groups[1].data.ResourceMetrics().Len())
+}
+
+func TestLogsDataPusher_expressions(t *testing.T) {
+	c := sarama.NewConfig()
+	producer := mocks.NewSyncProducer(t, c)
+	var messages []*sarama.ProducerMessage
+	for i := 0; i < 4; i++ {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			messages = append(messages, msg)
+			return nil
+		})
+	}
+
+	cfg := Config{
+		Topic:                  "otlp_logs",
+		PartitionKeyExpression: `attributes["tenant"]`,
+		TopicExpression:        `resource.attributes["topic"]`,
+		MessagePerRecord:       true,
+	}
+	p := kafkaLogsProducer{
+		cfg:         cfg,
+		producer:    producer,
+		marshaler:   newPdataLogsMarshaler(&plog.ProtoMarshaler{}, defaultEncoding, false),
+		expressions: newLogExpressions(t, cfg),
+	}
+	t.Cleanup(func() {
+		require.NoError(t, p.Close(context.Background()))
+	})
+	logs := testLogs("a", "b")
+	logs.ResourceLogs().At(1).Resource().Attributes().PutStr("topic", "service2_logs")
+	require.NoError(t, p.logsDataPusher(context.Background(), logs))
+
+	require.Len(t, messages, 4)
+	for i, expected := range []struct{ topic, key string }{
+		{"otlp_logs", "a"}, {"otlp_logs", "b"}, {"service2_logs", "a"}, {"service2_logs", "b"},
+	} {
+		assert.Equal(t, expected.topic, messages[i].Topic)
+		assert.Equal(t, sarama.ByteEncoder(expected.key), messages[i].Key)
+	}
+}
+
+func TestLogsDataPusher_expression_error(t *testing.T) {
+	cfg := Config{PartitionKeyExpression: `Int(body)`}
+	p := kafkaLogsProducer{
+		cfg:         cfg,
+		marshaler:   newPdataLogsMarshaler(&plog.ProtoMarshaler{}, defaultEncoding, false),
+		expressions: newLogExpressions(t, cfg),
+	}
+	logs := testLogs("a")
+	logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().SetEmptyMap()
+	err := p.logsDataPusher(context.Background(), logs)
+	assert.ErrorContains(t, err, "failed to evaluate partition_key_expression")
+}
@@ -4,6 +4,7 @@
 package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
 
 import (
+	"errors"
 	"fmt"
 
 	"go.opentelemetry.io/collector/component"
@@ -57,6 +58,30 @@ type Config struct {
 
 	// SendMetadata controls whether prometheus metadata will be generated and sent
 	SendMetadata bool `mapstructure:"send_metadata"`
+
+	// RemoteWriteProtoMsg controls the Remote-Write protobuf message sent to the endpoint.
+	// Use "prometheus.WriteRequest" for Remote-Write 1.0 (default) or "io.prometheus.write.v2.Request" for Remote-Write 2.0.
+	RemoteWriteProtoMsg RemoteWriteProtoMsg `mapstructure:"protobuf_message"`
+}
+
+// RemoteWriteProtoMsg represents the protobuf message sent to the remote write endpoint.
+type RemoteWriteProtoMsg string
+
+const (
+	// RemoteWriteProtoMsgV1 represents the `prometheus.WriteRequest` protobuf message of Remote-Write 1.0.
+	RemoteWriteProtoMsgV1 RemoteWriteProtoMsg = "prometheus.WriteRequest"
+	// RemoteWriteProtoMsgV2 represents the `io.prometheus.write.v2.Request` protobuf message of Remote-Write 2.0.
+	RemoteWriteProtoMsgV2 RemoteWriteProtoMsg = "io.prometheus.write.v2.Request"
+)
+
+// Validate checks if the protobuf message is supported.
+func (m RemoteWriteProtoMsg) Validate() error {
+	switch m {
+	case RemoteWriteProtoMsgV1, RemoteWriteProtoMsgV2:
+		return nil
+	default:
+		return fmt.Errorf("unknown remote write protobuf message %q, supported: %q, %q", m, RemoteWriteProtoMsgV1, RemoteWriteProtoMsgV2)
+	}
 }
 
 type CreatedMetric struct {
@@ -116,6 +141,10 @@ func (cfg *Config) Validate() error {
 			Enabled: false,
 		}
 	}
+	if cfg.CreatedMetric.Enabled && cfg.RemoteWriteProtoMsg == RemoteWriteProtoMsgV2 {
+		// Remote-Write 2.0 sends the start time as the created timestamp of the series instead of _created series.
+		return errors.New("export_created_metric can't be enabled with the io.prometheus.write.v2.Request protobuf message")
+	}
 	if cfg.MaxBatchSizeBytes < 0 {
 		return fmt.Errorf("max_batch_byte_size must be greater than 0")
 	}
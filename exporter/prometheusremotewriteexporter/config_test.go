@@ -81,9 +81,27 @@ func TestLoadConfig(t *testing.T) {
 				TargetInfo: &TargetInfo{
 					Enabled: true,
 				},
-				CreatedMetric: &CreatedMetric{Enabled: true},
+				CreatedMetric:       &CreatedMetric{Enabled: true},
+				RemoteWriteProtoMsg: RemoteWriteProtoMsgV1,
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "rw2"),
+			expected: func() component.Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "localhost:8888"
+				cfg.RemoteWriteProtoMsg = RemoteWriteProtoMsgV2
+				return cfg
+			}(),
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_protobuf_message"),
+			errorMessage: `protobuf_message: unknown remote write protobuf message "prometheus.WriteRequestV3", supported: "prometheus.WriteRequest", "io.prometheus.write.v2.Request"`,
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "rw2_created_metric"),
+			errorMessage: "export_created_metric can't be enabled with the io.prometheus.write.v2.Request protobuf message",
+		},
 		{
 			id:           component.NewIDWithName(metadata.Type, "negative_queue_size"),
 			errorMessage: "remote write queue size can't be negative",
@@ -84,6 +84,7 @@ type prwExporter struct {
 	wal               *prweWAL
 	exporterSettings  prometheusremotewrite.Settings
 	telemetry         prwTelemetry
+	protoMsg          RemoteWriteProtoMsg
 
 	// When concurrency is enabled, concurrent goroutines would potentially
 	// fight over the same batchState object. To avoid this, we use a pool
@@ -152,6 +153,7 @@ func newPRWExporter(cfg *Config, set exporter.Settings) (*prwExporter, error) {
 			SendMetadata:        cfg.SendMetadata,
 		},
 		telemetry:      prwTelemetry,
+		protoMsg:       cfg.RemoteWriteProtoMsg,
 		batchStatePool: sync.Pool{New: func() any { return newBatchTimeServicesState() }},
 	}
 
@@ -159,7 +161,7 @@ func newPRWExporter(cfg *Config, set exporter.Settings) (*prwExporter, error) {
 		prwe.settings.Logger.Warn("export_created_metric is deprecated and will be removed in a future release")
 	}
 
-	prwe.wal = newWAL(cfg.WAL, prwe.export)
+	prwe.wal = newWAL(cfg.WAL, cfg.RemoteWriteProtoMsg, prwe.export)
 	return prwe, nil
 }
 
@@ -203,6 +205,9 @@ func (prwe *prwExporter) PushMetrics(ctx context.Context, md pmetric.Metrics) er
 	case <-prwe.closeChan:
 		return errors.New("shutdown has been called")
 	default:
+		if prwe.protoMsg == RemoteWriteProtoMsgV2 {
+			return prwe.pushMetricsV2(ctx, md)
+		}
 
 		tsMap, err := prometheusremotewrite.FromMetrics(md, prwe.exporterSettings)
 		if err != nil {
@@ -247,6 +252,11 @@ func (prwe *prwExporter) handleExport(ctx context.Context, tsMap map[string]*pro
 	if err != nil {
 		return err
 	}
+	return prwe.exportOrPersist(ctx, protoMessages(requests))
+}
+
+// exportOrPersist exports the requests directly, or persists them to the WAL if it is enabled.
+func (prwe *prwExporter) exportOrPersist(ctx context.Context, requests []proto.Message) error {
 	if !prwe.walEnabled() {
 		// Perform a direct export otherwise.
 		return prwe.export(ctx, requests)
@@ -254,15 +264,24 @@ func (prwe *prwExporter) handleExport(ctx context.Context, tsMap map[string]*pro
 
 	// Otherwise the WAL is enabled, and just persist the requests to the WAL
 	// and they'll be exported in another goroutine to the RemoteWrite endpoint.
-	if err = prwe.wal.persistToWAL(requests); err != nil {
+	if err := prwe.wal.persistToWAL(requests); err != nil {
 		return consumererror.NewPermanent(err)
 	}
 	return nil
 }
 
-// export sends a Snappy-compressed WriteRequest containing TimeSeries to a remote write endpoint in order
-func (prwe *prwExporter) export(ctx context.Context, requests []*prompb.WriteRequest) error {
-	input := make(chan *prompb.WriteRequest, len(requests))
+// protoMessages converts a slice of write requests to a slice of proto.Message.
+func protoMessages[T proto.Message](requests []T) []proto.Message {
+	msgs := make([]proto.Message, 0, len(requests))
+	for _, req := range requests {
+		msgs = append(msgs, req)
+	}
+	return msgs
+}
+
+// export sends a Snappy-compressed write request containing TimeSeries to a remote write endpoint in order
+func (prwe *prwExporter) export(ctx context.Context, requests []proto.Message) error {
+	input := make(chan proto.Message, len(requests))
 	for _, request := range requests {
 		input <- request
 	}
@@ -303,7 +322,7 @@ func (prwe *prwExporter) export(ctx context.Context, requests []*prompb.WriteReq
 	return errs
 }
 
-func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequest) error {
+func (prwe *prwExporter) execute(ctx context.Context, writeReq proto.Message) error {
 	buf := bufferPool.Get().(*buffer)
 	buf.protobuf.Reset()
 	defer bufferPool.Put(buf)
@@ -345,8 +364,14 @@ func (prwe *prwExporter) execute(ctx context.Context, writeReq *prompb.WriteRequ
 		// Add necessary headers specified by:
 		// https://cortexmetrics.io/docs/apis/#remote-api
 		req.Header.Add("Content-Encoding", "snappy")
-		req.Header.Set("Content-Type", "application/x-protobuf")
-		req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		if prwe.protoMsg == RemoteWriteProtoMsgV2 {
+			// https://prometheus.io/docs/specs/remote_write_spec_2_0/#protocol
+			req.Header.Set("Content-Type", "application/x-protobuf;proto="+string(RemoteWriteProtoMsgV2))
+			req.Header.Set("X-Prometheus-Remote-Write-Version", "2.0.0")
+		} else {
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		}
 		req.Header.Set("User-Agent", prwe.userAgentHeader)
 
 		resp, err := prwe.client.Do(req)
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter/internal/metadata"
+)
+
+func TestPushMetricsV2(t *testing.T) {
+	ts := pcommon.NewTimestampFromTime(time.Now())
+	start := pcommon.NewTimestampFromTime(time.Now().Add(-time.Minute))
+
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	counter := sm.Metrics().AppendEmpty()
+	counter.SetName("requests")
+	counter.SetDescription("number of requests")
+	sum := counter.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(10)
+	exemplar := dp.Exemplars().AppendEmpty()
+	exemplar.SetTimestamp(ts)
+	exemplar.SetIntValue(1)
+	exemplar.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+
+	expHist := sm.Metrics().AppendEmpty()
+	expHist.SetName("latency")
+	expHist.SetUnit("s")
+	eh := expHist.SetEmptyExponentialHistogram()
+	eh.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	ehp := eh.DataPoints().AppendEmpty()
+	ehp.SetStartTimestamp(start)
+	ehp.SetTimestamp(ts)
+	ehp.SetScale(10)
+	ehp.SetCount(4)
+	ehp.SetSum(3)
+	ehp.Positive().BucketCounts().FromRaw([]uint64{1, 1, 1, 1})
+
+	received := make(chan *writev2.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf;proto=io.prometheus.write.v2.Request", r.Header.Get("Content-Type"))
+		assert.Equal(t, "2.0.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		assert.NoError(t, err)
+		req := &writev2.Request{}
+		assert.NoError(t, proto.Unmarshal(decoded, req))
+		received <- req
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig = confighttp.NewDefaultClientConfig()
+	cfg.ClientConfig.Endpoint = server.URL
+	cfg.AddMetricSuffixes = false
+	cfg.RemoteWriteProtoMsg = RemoteWriteProtoMsgV2
+
+	set := exportertest.NewNopSettings(metadata.Type)
+	set.BuildInfo = component.BuildInfo{Description: "OpenTelemetry Collector", Version: "1.0"}
+	prwe, err := newPRWExporter(cfg, set)
+	require.NoError(t, err)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, prwe.Shutdown(context.Background()))
+	}()
+
+	require.NoError(t, prwe.PushMetrics(context.Background(), md))
+
+	var req *writev2.Request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the remote write request")
+	}
+
+	series := map[string]writev2.TimeSeries{}
+	for _, s := range req.Timeseries {
+		for i := 0; i < len(s.LabelsRefs); i += 2 {
+			if req.Symbols[s.LabelsRefs[i]] == "__name__" {
+				series[req.Symbols[s.LabelsRefs[i+1]]] = s
+			}
+		}
+	}
+	require.Len(t, series, 2)
+
+	requests := series["requests"]
+	assert.Equal(t, writev2.Metadata_METRIC_TYPE_COUNTER, requests.Metadata.Type)
+	assert.Equal(t, "number of requests", req.Symbols[requests.Metadata.HelpRef])
+	assert.Equal(t, start.AsTime().UnixMilli(), requests.CreatedTimestamp)
+	require.Len(t, requests.Samples, 1)
+	assert.Equal(t, float64(10), requests.Samples[0].Value)
+	require.Len(t, requests.Exemplars, 1)
+	require.Len(t, requests.Exemplars[0].LabelsRefs, 2)
+	assert.Equal(t, "trace_id", req.Symbols[requests.Exemplars[0].LabelsRefs[0]])
+
+	latency := series["latency"]
+	assert.Equal(t, writev2.Metadata_METRIC_TYPE_HISTOGRAM, latency.Metadata.Type)
+	assert.Equal(t, "s", req.Symbols[latency.Metadata.UnitRef])
+	assert.Equal(t, start.AsTime().UnixMilli(), latency.CreatedTimestamp)
+	require.Len(t, latency.Histograms, 1)
+	// Scale 10 is downscaled to the maximum native histogram schema.
+	assert.Equal(t, int32(8), latency.Histograms[0].Schema)
+	assert.Equal(t, uint64(4), latency.Histograms[0].GetCountInt())
+}
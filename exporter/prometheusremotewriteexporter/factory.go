@@ -119,5 +119,6 @@ func createDefaultConfig() component.Config {
 		CreatedMetric: &CreatedMetric{
 			Enabled: false,
 		},
+		RemoteWriteProtoMsg: RemoteWriteProtoMsgV1,
 	}
 }
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"errors"
+	"sort"
+
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+)
+
+// batchTimeSeriesV2 splits series into multiple Remote-Write 2.0 requests.
+// Every request carries its own symbols table, so the references of each series
+// are rewritten against the table of the request it is batched into.
+func batchTimeSeriesV2(tsMap map[string]*writev2.TimeSeries, symbolsTable writev2.SymbolsTable, maxBatchByteSize int, state *batchTimeSeriesState) ([]*writev2.Request, error) {
+	if len(tsMap) == 0 {
+		return nil, errors.New("invalid tsMap: cannot be empty map")
+	}
+
+	// Allocate a buffer size of at least 10, or twice the last # of requests we sent
+	requests := make([]*writev2.Request, 0, max(10, state.nextRequestBufferSize))
+
+	// Allocate a time series buffer 2x the last time series batch size or the length of the input if smaller
+	tsArray := make([]writev2.TimeSeries, 0, min(state.nextTimeSeriesBufferSize, len(tsMap)))
+	symbols := symbolsTable.Symbols()
+	batchSymbols := writev2.NewSymbolTable()
+	sizeOfCurrentBatch := 0
+
+	i := 0
+	for _, v := range tsMap {
+		// The series may bring all of its symbols into the request, account for them as well.
+		sizeOfSeries := v.Size() + sizeOfSymbols(v, symbols)
+
+		if sizeOfCurrentBatch+sizeOfSeries >= maxBatchByteSize && len(tsArray) != 0 {
+			state.nextTimeSeriesBufferSize = max(10, 2*len(tsArray))
+			wrapped := convertTimeseriesToRequestV2(tsArray, batchSymbols.Symbols())
+			requests = append(requests, wrapped)
+
+			tsArray = make([]writev2.TimeSeries, 0, min(state.nextTimeSeriesBufferSize, len(tsMap)-i))
+			batchSymbols = writev2.NewSymbolTable()
+			sizeOfCurrentBatch = 0
+		}
+
+		tsArray = append(tsArray, resymbolize(v, symbols, &batchSymbols))
+		sizeOfCurrentBatch += sizeOfSeries
+		i++
+	}
+
+	if len(tsArray) != 0 {
+		wrapped := convertTimeseriesToRequestV2(tsArray, batchSymbols.Symbols())
+		requests = append(requests, wrapped)
+	}
+
+	state.nextRequestBufferSize = 2 * len(requests)
+	return requests, nil
+}
+
+// sizeOfSymbols returns the size of the symbols referenced by the series.
+func sizeOfSymbols(ts *writev2.TimeSeries, symbols []string) int {
+	size := len(symbols[ts.Metadata.HelpRef]) + len(symbols[ts.Metadata.UnitRef])
+	for _, ref := range ts.LabelsRefs {
+		size += len(symbols[ref])
+	}
+	for _, e := range ts.Exemplars {
+		for _, ref := range e.LabelsRefs {
+			size += len(symbols[ref])
+		}
+	}
+	return size
+}
+
+// resymbolize returns a copy of the series whose references point to the given symbols table.
+func resymbolize(ts *writev2.TimeSeries, symbols []string, symbolsTable *writev2.SymbolsTable) writev2.TimeSeries {
+	out := *ts
+	out.LabelsRefs = symbolizeRefs(ts.LabelsRefs, symbols, symbolsTable)
+	out.Metadata.HelpRef = symbolsTable.Symbolize(symbols[ts.Metadata.HelpRef])
+	out.Metadata.UnitRef = symbolsTable.Symbolize(symbols[ts.Metadata.UnitRef])
+	if len(ts.Exemplars) > 0 {
+		out.Exemplars = make([]writev2.Exemplar, len(ts.Exemplars))
+		for i, e := range ts.Exemplars {
+			out.Exemplars[i] = e
+			out.Exemplars[i].LabelsRefs = symbolizeRefs(e.LabelsRefs, symbols, symbolsTable)
+		}
+	}
+	return out
+}
+
+func symbolizeRefs(refs []uint32, symbols []string, symbolsTable *writev2.SymbolsTable) []uint32 {
+	out := make([]uint32, len(refs))
+	for i, ref := range refs {
+		out[i] = symbolsTable.Symbolize(symbols[ref])
+	}
+	return out
+}
+
+func convertTimeseriesToRequestV2(tsArray []writev2.TimeSeries, symbols []string) *writev2.Request {
+	return &writev2.Request{
+		Symbols: symbols,
+		// Prometheus requires time series to be sorted by Timestamp to avoid out of order problems.
+		Timeseries: orderBySampleTimestampV2(tsArray),
+	}
+}
+
+func orderBySampleTimestampV2(tsArray []writev2.TimeSeries) []writev2.TimeSeries {
+	for i := range tsArray {
+		sL := tsArray[i].Samples
+		sort.Slice(sL, func(i, j int) bool {
+			return sL[i].Timestamp < sL[j].Timestamp
+		})
+		hL := tsArray[i].Histograms
+		sort.Slice(hL, func(i, j int) bool {
+			return hL[i].Timestamp < hL[j].Timestamp
+		})
+	}
+	return tsArray
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"testing"
+
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_batchTimeSeriesV2(t *testing.T) {
+	symbolsTable := writev2.NewSymbolTable()
+	newSeries := func(name string, ts int64) *writev2.TimeSeries {
+		return &writev2.TimeSeries{
+			LabelsRefs: []uint32{symbolsTable.Symbolize("__name__"), symbolsTable.Symbolize(name)},
+			Samples:    []writev2.Sample{{Value: 1, Timestamp: ts}},
+			Metadata: writev2.Metadata{
+				Type:    writev2.Metadata_METRIC_TYPE_GAUGE,
+				HelpRef: symbolsTable.Symbolize("help of " + name),
+			},
+			Exemplars: []writev2.Exemplar{
+				{LabelsRefs: []uint32{symbolsTable.Symbolize("trace_id"), symbolsTable.Symbolize(name + "-trace")}, Value: 1, Timestamp: ts},
+			},
+		}
+	}
+	tsMap := map[string]*writev2.TimeSeries{
+		"1": newSeries("first", 1),
+		"2": newSeries("second", 2),
+		"3": newSeries("third", 3),
+	}
+
+	tests := []struct {
+		name             string
+		maxBatchByteSize int
+		numExpectedReqs  int
+	}{
+		{"single_request", 1000000, 1},
+		{"one_request_per_series", 10, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newBatchTimeServicesState()
+			requests, err := batchTimeSeriesV2(tsMap, symbolsTable, tt.maxBatchByteSize, state)
+			require.NoError(t, err)
+
+			var got []string
+			for _, req := range requests {
+				for _, ts := range req.Timeseries {
+					// Every reference must resolve against the symbols of its own request.
+					name := req.Symbols[ts.LabelsRefs[1]]
+					assert.Equal(t, "__name__", req.Symbols[ts.LabelsRefs[0]])
+					assert.Equal(t, "help of "+name, req.Symbols[ts.Metadata.HelpRef])
+					assert.Equal(t, name+"-trace", req.Symbols[ts.Exemplars[0].LabelsRefs[1]])
+					got = append(got, name)
+				}
+			}
+			assert.ElementsMatch(t, []string{"first", "second", "third"}, got)
+			assert.Len(t, requests, tt.numExpectedReqs)
+		})
+	}
+
+	_, err := batchTimeSeriesV2(map[string]*writev2.TimeSeries{}, symbolsTable, 100, newBatchTimeServicesState())
+	assert.Error(t, err)
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/tidwall/wal"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -25,8 +26,9 @@ type prweWAL struct {
 	wal       *wal.Log
 	walConfig *WALConfig
 	walPath   string
+	protoMsg  RemoteWriteProtoMsg
 
-	exportSink func(ctx context.Context, reqL []*prompb.WriteRequest) error
+	exportSink func(ctx context.Context, reqL []proto.Message) error
 
 	stopOnce  sync.Once
 	stopChan  chan struct{}
@@ -60,7 +62,7 @@ func (wc *WALConfig) truncateFrequency() time.Duration {
 	return defaultWALTruncateFrequency
 }
 
-func newWAL(walConfig *WALConfig, exportSink func(context.Context, []*prompb.WriteRequest) error) *prweWAL {
+func newWAL(walConfig *WALConfig, protoMsg RemoteWriteProtoMsg, exportSink func(context.Context, []proto.Message) error) *prweWAL {
 	if walConfig == nil {
 		// There are cases for which the WAL can be disabled.
 		// TODO: Perhaps log that the WAL wasn't enabled.
@@ -70,6 +72,7 @@ func newWAL(walConfig *WALConfig, exportSink func(context.Context, []*prompb.Wri
 	return &prweWAL{
 		exportSink: exportSink,
 		walConfig:  walConfig,
+		protoMsg:   protoMsg,
 		stopChan:   make(chan struct{}),
 		rNotify:    make(chan struct{}),
 		rWALIndex:  &atomic.Uint64{},
@@ -77,8 +80,14 @@ func newWAL(walConfig *WALConfig, exportSink func(context.Context, []*prompb.Wri
 	}
 }
 
-func (wc *WALConfig) createWAL() (*wal.Log, string, error) {
-	walPath := filepath.Join(wc.Directory, "prom_remotewrite")
+// createWAL opens the WAL for the given protobuf message. Remote-Write 2.0 requests are stored
+// in their own directory, so that switching protocols never replays payloads of the other one.
+func (wc *WALConfig) createWAL(protoMsg RemoteWriteProtoMsg) (*wal.Log, string, error) {
+	walDir := "prom_remotewrite"
+	if protoMsg == RemoteWriteProtoMsgV2 {
+		walDir = "prom_remotewrite_v2"
+	}
+	walPath := filepath.Join(wc.Directory, walDir)
 	log, err := wal.Open(walPath, &wal.Options{
 		SegmentCacheSize: wc.bufferSize(),
 		NoCopy:           true,
@@ -104,7 +113,7 @@ func (prweWAL *prweWAL) retrieveWALIndices() (err error) {
 		return err
 	}
 
-	log, walPath, err := prweWAL.walConfig.createWAL()
+	log, walPath, err := prweWAL.walConfig.createWAL(prweWAL.protoMsg)
 	if err != nil {
 		return err
 	}
@@ -184,13 +193,13 @@ func (prweWAL *prweWAL) run(ctx context.Context) (err error) {
 	return nil
 }
 
-// continuallyPopWALThenExport reads a prompb.WriteRequest proto encoded blob from the WAL, and moves
+// continuallyPopWALThenExport reads a write request proto encoded blob from the WAL, and moves
 // the WAL's front index forward until either the read buffer period expires or the maximum
 // buffer size is exceeded. When either of the two conditions are matched, it then exports
 // the requests to the Remote-Write endpoint, and then truncates the head of the WAL to where
 // it last read from.
 func (prweWAL *prweWAL) continuallyPopWALThenExport(ctx context.Context, signalStart func()) (err error) {
-	var reqL []*prompb.WriteRequest
+	var reqL []proto.Message
 	defer func() {
 		// Keeping it within a closure to ensure that the later
 		// updated value of reqL is always flushed to disk.
@@ -222,8 +231,8 @@ func (prweWAL *prweWAL) continuallyPopWALThenExport(ctx context.Context, signalS
 		default:
 		}
 
-		var req *prompb.WriteRequest
-		req, err = prweWAL.readPrompbFromWAL(ctx, prweWAL.rWALIndex.Load())
+		var req proto.Message
+		req, err = prweWAL.readFromWAL(ctx, prweWAL.rWALIndex.Load())
 		if err != nil {
 			return err
 		}
@@ -282,7 +291,7 @@ func (prweWAL *prweWAL) syncAndTruncateFront() error {
 	return nil
 }
 
-func (prweWAL *prweWAL) exportThenFrontTruncateWAL(ctx context.Context, reqL []*prompb.WriteRequest) error {
+func (prweWAL *prweWAL) exportThenFrontTruncateWAL(ctx context.Context, reqL []proto.Message) error {
 	if len(reqL) == 0 {
 		return nil
 	}
@@ -303,7 +312,7 @@ func (prweWAL *prweWAL) exportThenFrontTruncateWAL(ctx context.Context, reqL []*
 // persistToWAL is the routine that'll be hooked into the exporter's receiving side and it'll
 // write them to the Write-Ahead-Log so that shutdowns won't lose data, and that the routine that
 // reads from the WAL can then process the previously serialized requests.
-func (prweWAL *prweWAL) persistToWAL(requests []*prompb.WriteRequest) error {
+func (prweWAL *prweWAL) persistToWAL(requests []proto.Message) error {
 	prweWAL.mu.Lock()
 	defer prweWAL.mu.Unlock()
 
@@ -326,7 +335,15 @@ func (prweWAL *prweWAL) persistToWAL(requests []*prompb.WriteRequest) error {
 	return prweWAL.wal.WriteBatch(batch)
 }
 
-func (prweWAL *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wreq *prompb.WriteRequest, err error) {
+// newRequest returns an empty write request of the protobuf message stored in the WAL.
+func (prweWAL *prweWAL) newRequest() proto.Message {
+	if prweWAL.protoMsg == RemoteWriteProtoMsgV2 {
+		return new(writev2.Request)
+	}
+	return new(prompb.WriteRequest)
+}
+
+func (prweWAL *prweWAL) readFromWAL(ctx context.Context, index uint64) (wreq proto.Message, err error) {
 	var protoBlob []byte
 	for i := 0; i < 12; i++ {
 		// Firstly check if we've been terminated, then exit if so.
@@ -348,7 +365,7 @@ func (prweWAL *prweWAL) readPrompbFromWAL(ctx context.Context, index uint64) (wr
 		}
 		protoBlob, err = prweWAL.wal.Read(index)
 		if err == nil { // The read succeeded.
-			req := new(prompb.WriteRequest)
+			req := prweWAL.newRequest()
 			if err = proto.Unmarshal(protoBlob, req); err != nil {
 				return nil, err
 			}
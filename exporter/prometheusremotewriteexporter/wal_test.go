@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
@@ -25,20 +27,20 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter/internal/metadata"
 )
 
-func doNothingExportSink(_ context.Context, reqL []*prompb.WriteRequest) error {
+func doNothingExportSink(_ context.Context, reqL []proto.Message) error {
 	_ = reqL
 	return nil
 }
 
 func TestWALCreation_nilConfig(t *testing.T) {
 	config := (*WALConfig)(nil)
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, RemoteWriteProtoMsgV1, doNothingExportSink)
 	require.Nil(t, pwal)
 }
 
 func TestWALCreation_nonNilConfig(t *testing.T) {
 	config := &WALConfig{Directory: t.TempDir()}
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, RemoteWriteProtoMsgV1, doNothingExportSink)
 	require.NotNil(t, pwal)
 	assert.NoError(t, pwal.stop())
 }
@@ -89,7 +91,7 @@ func TestWALStopManyTimes(t *testing.T) {
 		TruncateFrequency: 60 * time.Microsecond,
 		BufferSize:        1,
 	}
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, RemoteWriteProtoMsgV1, doNothingExportSink)
 	require.NotNil(t, pwal)
 
 	// Ensure that invoking .stop() multiple times doesn't cause a panic, but actually
@@ -105,7 +107,7 @@ func TestWAL_persist(t *testing.T) {
 	// Unit tests that requests written to the WAL persist.
 	config := &WALConfig{Directory: t.TempDir()}
 
-	pwal := newWAL(config, doNothingExportSink)
+	pwal := newWAL(config, RemoteWriteProtoMsgV1, doNothingExportSink)
 	require.NotNil(t, pwal)
 
 	// 1. Write out all the entries.
@@ -138,7 +140,7 @@ func TestWAL_persist(t *testing.T) {
 		assert.NoError(t, pwal.stop())
 	})
 
-	require.NoError(t, pwal.persistToWAL(reqL))
+	require.NoError(t, pwal.persistToWAL(protoMessages(reqL)))
 
 	// 2. Read all the entries from the WAL itself, guided by the indices available,
 	// and ensure that they are exactly in order as we'd expect them.
@@ -150,9 +152,9 @@ func TestWAL_persist(t *testing.T) {
 
 	var reqLFromWAL []*prompb.WriteRequest
 	for i := start; i <= end; i++ {
-		req, err := pwal.readPrompbFromWAL(ctx, i)
+		req, err := pwal.readFromWAL(ctx, i)
 		require.NoError(t, err)
-		reqLFromWAL = append(reqLFromWAL, req)
+		reqLFromWAL = append(reqLFromWAL, req.(*prompb.WriteRequest))
 	}
 
 	orderByLabelValueForEach(reqL)
@@ -161,6 +163,43 @@ func TestWAL_persist(t *testing.T) {
 	require.Equal(t, reqLFromWAL[1], reqL[1])
 }
 
+func TestWAL_persistV2(t *testing.T) {
+	// Unit tests that Remote-Write 2.0 requests written to the WAL persist.
+	config := &WALConfig{Directory: t.TempDir()}
+
+	pwal := newWAL(config, RemoteWriteProtoMsgV2, doNothingExportSink)
+	require.NotNil(t, pwal)
+
+	reqL := []*writev2.Request{
+		{
+			Symbols: []string{"", "__name__", "test_metric", "help"},
+			Timeseries: []writev2.TimeSeries{
+				{
+					LabelsRefs:       []uint32{1, 2},
+					Samples:          []writev2.Sample{{Value: 1, Timestamp: 100}},
+					Metadata:         writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_COUNTER, HelpRef: 3},
+					CreatedTimestamp: 50,
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	require.NoError(t, pwal.retrieveWALIndices())
+	t.Cleanup(func() {
+		assert.NoError(t, pwal.stop())
+	})
+	assert.Equal(t, filepath.Join(config.Directory, "prom_remotewrite_v2"), pwal.walPath)
+
+	require.NoError(t, pwal.persistToWAL(protoMessages(reqL)))
+
+	start, err := pwal.wal.FirstIndex()
+	require.NoError(t, err)
+	req, err := pwal.readFromWAL(ctx, start)
+	require.NoError(t, err)
+	require.Equal(t, reqL[0], req)
+}
+
 func TestExportWithWALEnabled(t *testing.T) {
 	cfg := &Config{
 		WAL: &WALConfig{
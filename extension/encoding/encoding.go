@@ -17,6 +17,12 @@ type LogsMarshalerExtension interface {
 	plog.Marshaler
 }
 
+// LogRecordsMarshaler is implemented by the logs marshaler extensions whose format holds a single log record,
+// such as the schema registry wire format. MarshalLogRecords returns one encoded message per log record, in order.
+type LogRecordsMarshaler interface {
+	MarshalLogRecords(logs plog.Logs) ([][]byte, error)
+}
+
 // LogsUnmarshalerExtension is an extension that unmarshals logs.
 type LogsUnmarshalerExtension interface {
 	extension.Extension
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension"
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+type avroCodec struct {
+	codec *goavro.Codec
+}
+
+func newAvroCodec(schema string) (*avroCodec, error) {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create avro codec: %w", err)
+	}
+	return &avroCodec{codec: codec}, nil
+}
+
+func (c *avroCodec) decode(payload []byte) (any, error) {
+	native, _, err := c.codec.NativeFromBinary(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize avro record: %w", err)
+	}
+	// removes time.Time values as FromRaw does not support it
+	return transformValue(native), nil
+}
+
+func (c *avroCodec) encode(value any) ([]byte, error) {
+	payload, err := c.codec.BinaryFromNative(nil, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize avro record: %w", err)
+	}
+	return payload, nil
+}
+
+func transformValue(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UnixNano()
+	case map[string]any:
+		for k, item := range v {
+			v[k] = transformValue(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = transformValue(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
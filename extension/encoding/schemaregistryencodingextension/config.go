@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+var (
+	errNoRegistry                    = errors.New("either registry::endpoint or schemas_file must be set")
+	errRegistryAndSchemasFile        = errors.New("registry::endpoint and schemas_file cannot be both set")
+	errInvalidSubjectRefreshInterval = errors.New("subject_refresh_interval must be positive")
+	errInvalidNegativeCacheTTL       = errors.New("negative_cache_ttl cannot be negative")
+)
+
+type Config struct {
+	// Registry is the HTTP client configuration of the schema registry.
+	Registry confighttp.ClientConfig `mapstructure:"registry"`
+
+	// SchemasFile is the path of a file listing the schemas, used instead of a schema registry.
+	SchemasFile string `mapstructure:"schemas_file"`
+
+	// Subject is the subject whose latest schema is used to marshal the log records.
+	// Marshaling isn't supported when it isn't set.
+	Subject string `mapstructure:"subject"`
+
+	// SubjectRefreshInterval is how frequently the latest schema of the subject is looked up.
+	SubjectRefreshInterval time.Duration `mapstructure:"subject_refresh_interval"`
+
+	// NegativeCacheTTL is how long the schema IDs which couldn't be looked up are remembered, during which
+	// their messages fail without querying the schema registry. They aren't remembered when it's 0.
+	NegativeCacheTTL time.Duration `mapstructure:"negative_cache_ttl"`
+}
+
+func (c *Config) Validate() error {
+	if c.Registry.Endpoint == "" && c.SchemasFile == "" {
+		return errNoRegistry
+	}
+	if c.Registry.Endpoint != "" && c.SchemasFile != "" {
+		return errRegistryAndSchemasFile
+	}
+	if c.SubjectRefreshInterval <= 0 {
+		return errInvalidSubjectRefreshInterval
+	}
+	if c.NegativeCacheTTL < 0 {
+		return errInvalidNegativeCacheTTL
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id       component.ID
+		expected func(cfg *Config)
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: func(cfg *Config) { cfg.Registry.Endpoint = "http://localhost:8081" },
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "file"),
+			expected: func(cfg *Config) {
+				cfg.SchemasFile = "testdata/schemas.yaml"
+				cfg.Subject = "logs-value"
+				cfg.SubjectRefreshInterval = time.Minute
+				cfg.NegativeCacheTTL = 0
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+			assert.NoError(t, xconfmap.Validate(cfg))
+
+			expected := factory.CreateDefaultConfig().(*Config)
+			tt.expected(expected)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr error
+	}{
+		{
+			name:        "no registry",
+			modify:      func(*Config) {},
+			expectedErr: errNoRegistry,
+		},
+		{
+			name: "registry and schemas file",
+			modify: func(cfg *Config) {
+				cfg.Registry.Endpoint = "http://localhost:8081"
+				cfg.SchemasFile = "testdata/schemas.yaml"
+			},
+			expectedErr: errRegistryAndSchemasFile,
+		},
+		{
+			name: "subject refresh interval",
+			modify: func(cfg *Config) {
+				cfg.SchemasFile = "testdata/schemas.yaml"
+				cfg.SubjectRefreshInterval = 0
+			},
+			expectedErr: errInvalidSubjectRefreshInterval,
+		},
+		{
+			name: "negative cache TTL",
+			modify: func(cfg *Config) {
+				cfg.SchemasFile = "testdata/schemas.yaml"
+				cfg.NegativeCacheTTL = -time.Second
+			},
+			expectedErr: errInvalidNegativeCacheTTL,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			assert.ErrorIs(t, cfg.Validate(), tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension"
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
+)
+
+// The messages of the schema registry wire format start with a magic byte followed by the
+// big-endian ID of the schema.
+const (
+	magicByte  = 0
+	headerSize = 5
+)
+
+var (
+	_ encoding.LogsUnmarshalerExtension = (*schemaRegistryExtension)(nil)
+	_ encoding.LogsMarshalerExtension   = (*schemaRegistryExtension)(nil)
+	_ encoding.LogRecordsMarshaler      = (*schemaRegistryExtension)(nil)
+
+	errNoSubject = errors.New("subject must be set to marshal log records")
+)
+
+// codec decodes and encodes the payload of the messages of a schema.
+type codec interface {
+	decode(payload []byte) (any, error)
+	encode(value any) ([]byte, error)
+}
+
+// failedLookup is a schema which couldn't be looked up or parsed, remembered so that the messages of
+// unknown schemas don't query the schema registry each time.
+type failedLookup struct {
+	err       error
+	expiresAt time.Time
+}
+
+type subjectCodec struct {
+	id        uint32
+	codec     codec
+	expiresAt time.Time
+}
+
+type schemaRegistryExtension struct {
+	config   *Config
+	settings component.TelemetrySettings
+	now      func() time.Time
+
+	// registry may be set in tests to inject a fake implementation.
+	registry registry
+	client   *http.Client
+
+	mu sync.RWMutex
+	// The schemas are immutable, their codecs are cached for the lifetime of the extension
+	codecs   map[uint32]codec
+	failures map[uint32]failedLookup
+	subject  *subjectCodec
+	lookups singleflight.Group
+}
+
+func newExtension(config *Config, settings component.TelemetrySettings) *schemaRegistryExtension {
+	return &schemaRegistryExtension{
+		config:   config,
+		settings: settings,
+		now:      time.Now,
+		codecs:   map[uint32]codec{},
+		failures: map[uint32]failedLookup{},
+	}
+}
+
+func (e *schemaRegistryExtension) Start(ctx context.Context, host component.Host) error {
+	if e.registry != nil {
+		return nil
+	}
+	if e.config.SchemasFile != "" {
+		r, err := newFileRegistry(e.config.SchemasFile)
+		if err != nil {
+			return err
+		}
+		e.registry = r
+		return nil
+	}
+	client, err := e.config.Registry.ToClient(ctx, host, e.settings)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP Client: %w", err)
+	}
+	e.client = client
+	e.registry = &httpRegistry{endpoint: e.config.Registry.Endpoint, client: client}
+	return nil
+}
+
+func (e *schemaRegistryExtension) Shutdown(context.Context) error {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// UnmarshalLogs decodes a message of the schema registry wire format into the body of a log record.
+func (e *schemaRegistryExtension) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	p := plog.NewLogs()
+	if len(buf) < headerSize || buf[0] != magicByte {
+		return p, errors.New("message isn't in the schema registry wire format")
+	}
+	id := binary.BigEndian.Uint32(buf[1:headerSize])
+	c, err := e.codecByID(id)
+	if err != nil {
+		return p, fmt.Errorf("failed to look up schema %d: %w", id, err)
+	}
+	value, err := c.decode(buf[headerSize:])
+	if err != nil {
+		return p, err
+	}
+
+	logRecord := p.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	logRecord.SetObservedTimestamp(pcommon.NewTimestampFromTime(e.now()))
+	if err := logRecord.Body().FromRaw(value); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// MarshalLogs encodes the body of a single log record with the latest schema of the subject. A message of
+// the wire format holds a single log record, MarshalLogRecords must be used to marshal several log records.
+func (e *schemaRegistryExtension) MarshalLogs(logs plog.Logs) ([]byte, error) {
+	switch count := logs.LogRecordCount(); count {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("marshaling %d log records into a single message isn't supported by the schema registry wire format, "+
+			"they must be marshaled with MarshalLogRecords", count)
+	}
+	messages, err := e.MarshalLogRecords(logs)
+	if err != nil {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// MarshalLogRecords encodes the body of each log record as a message with the latest schema of the subject.
+func (e *schemaRegistryExtension) MarshalLogRecords(logs plog.Logs) ([][]byte, error) {
+	if e.config.Subject == "" {
+		return nil, errNoSubject
+	}
+	sc, err := e.subjectCodec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up subject %q: %w", e.config.Subject, err)
+	}
+
+	messages := make([][]byte, 0, logs.LogRecordCount())
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		scopeLogs := logs.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				payload, err := sc.codec.encode(records.At(k).Body().AsRaw())
+				if err != nil {
+					return nil, err
+				}
+				message := make([]byte, headerSize, headerSize+len(payload))
+				message[0] = magicByte
+				binary.BigEndian.PutUint32(message[1:], sc.id)
+				messages = append(messages, append(message, payload...))
+			}
+		}
+	}
+	return messages, nil
+}
+
+func (e *schemaRegistryExtension) codecByID(id uint32) (codec, error) {
+	e.mu.RLock()
+	c, ok := e.codecs[id]
+	failure, failed := e.failures[id]
+	e.mu.RUnlock()
+	if ok {
+		return c, nil
+	}
+	if failed && e.now().Before(failure.expiresAt) {
+		return nil, failure.err
+	}
+
+	// The concurrent lookups of a schema share a single request
+	v, err, _ := e.lookups.Do(fmt.Sprintf("id/%d", id), func() (any, error) {
+		s, err := e.registry.schemaByID(context.Background(), id)
+		if err != nil {
+			e.cacheFailure(id, err)
+			return nil, err
+		}
+		c, err := e.cacheCodec(s)
+		if err != nil {
+			e.cacheFailure(id, err)
+			return nil, err
+		}
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(codec), nil
+}
+
+// cacheFailure remembers a schema which couldn't be looked up or parsed for the negative cache TTL,
+// the expired failures are removed at the same time.
+func (e *schemaRegistryExtension) cacheFailure(id uint32, err error) {
+	if e.config.NegativeCacheTTL <= 0 {
+		return
+	}
+	now := e.now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for failedID, failure := range e.failures {
+		if !now.Before(failure.expiresAt) {
+			delete(e.failures, failedID)
+		}
+	}
+	e.failures[id] = failedLookup{err: err, expiresAt: now.Add(e.config.NegativeCacheTTL)}
+}
+
+// subjectCodec returns the codec of the latest schema of the subject, which is looked up again after
+// the refresh interval.
+func (e *schemaRegistryExtension) subjectCodec() (*subjectCodec, error) {
+	e.mu.RLock()
+	sc := e.subject
+	e.mu.RUnlock()
+	if sc != nil && e.now().Before(sc.expiresAt) {
+		return sc, nil
+	}
+
+	v, err, _ := e.lookups.Do("subject", func() (any, error) {
+		s, err := e.registry.latestSchema(context.Background(), e.config.Subject)
+		if err != nil {
+			return nil, err
+		}
+		c, err := e.cacheCodec(s)
+		if err != nil {
+			return nil, err
+		}
+		sc := &subjectCodec{id: s.ID, codec: c, expiresAt: e.now().Add(e.config.SubjectRefreshInterval)}
+		e.mu.Lock()
+		e.subject = sc
+		e.mu.Unlock()
+		return sc, nil
+	})
+	if err != nil {
+		if sc != nil {
+			// Keep using the previous schema while the registry is unavailable
+			e.settings.Logger.Warn("Failed to refresh the schema of the subject", zap.Error(err))
+			return sc, nil
+		}
+		return nil, err
+	}
+	return v.(*subjectCodec), nil
+}
+
+func (e *schemaRegistryExtension) cacheCodec(s schema) (codec, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c, ok := e.codecs[s.ID]; ok {
+		return c, nil
+	}
+	c, err := newCodec(s)
+	if err != nil {
+		return nil, err
+	}
+	e.codecs[s.ID] = c
+	return c, nil
+}
+
+func newCodec(s schema) (codec, error) {
+	switch s.Type {
+	case "", schemaTypeAvro:
+		return newAvroCodec(s.Schema)
+	case schemaTypeProtobuf:
+		return newProtobufCodec(s.Schema)
+	default:
+		return nil, fmt.Errorf("unsupported schema type %q", s.Type)
+	}
+}
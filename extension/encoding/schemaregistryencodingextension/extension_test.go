@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func newTestExtension(t *testing.T, modify func(cfg *Config)) *schemaRegistryExtension {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.SchemasFile = "testdata/schemas.yaml"
+	modify(cfg)
+	require.NoError(t, cfg.Validate())
+
+	e := newExtension(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, e.Shutdown(context.Background())) })
+	return e
+}
+
+func wireMessage(id uint32, payload []byte) []byte {
+	message := []byte{magicByte, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(message[1:], id)
+	return append(message, payload...)
+}
+
+func logsWithBodies(t *testing.T, bodies ...map[string]any) plog.Logs {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		require.NoError(t, records.AppendEmpty().Body().SetEmptyMap().FromRaw(body))
+	}
+	return logs
+}
+
+func bodyOf(t *testing.T, logs plog.Logs) map[string]any {
+	require.Equal(t, 1, logs.LogRecordCount())
+	return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Map().AsRaw()
+}
+
+func TestUnmarshalAvro(t *testing.T) {
+	e := newTestExtension(t, func(*Config) {})
+	e.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	s, err := e.registry.schemaByID(context.Background(), 2)
+	require.NoError(t, err)
+	codec, err := goavro.NewCodec(s.Schema)
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromTextual([]byte(`{"message": "log message", "severity": 9, "hostname": {"string": "host1"}}`))
+	require.NoError(t, err)
+	payload, err := codec.BinaryFromNative(nil, native)
+	require.NoError(t, err)
+
+	logs, err := e.UnmarshalLogs(wireMessage(2, payload))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"message":  "log message",
+		"severity": int64(9),
+		"hostname": map[string]any{"string": "host1"},
+	}, bodyOf(t, logs))
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), record.ObservedTimestamp().AsTime())
+}
+
+func TestUnmarshalProtobuf(t *testing.T) {
+	e := newTestExtension(t, func(*Config) {})
+	c, err := e.codecByID(3)
+	require.NoError(t, err)
+	file := c.(*protobufCodec).file
+
+	event := dynamicpb.NewMessage(file.Messages().ByName("Event"))
+	fields := event.Descriptor().Fields()
+	event.Set(fields.ByName("name"), protoreflect.ValueOfString("login"))
+	event.Set(fields.ByName("count"), protoreflect.ValueOfInt64(3))
+	event.Set(fields.ByName("level"), protoreflect.ValueOfEnum(2))
+	tags := event.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("a"))
+	tags.Append(protoreflect.ValueOfString("b"))
+	event.Mutable(fields.ByName("labels")).Map().Set(protoreflect.ValueOfString("env").MapKey(), protoreflect.ValueOfString("prod"))
+	origin := event.Mutable(fields.ByName("origin")).Message()
+	origin.Set(origin.Descriptor().Fields().ByName("host"), protoreflect.ValueOfString("host1"))
+	payload, err := proto.Marshal(event)
+	require.NoError(t, err)
+
+	// The first message type is written as a single 0 index
+	logs, err := e.UnmarshalLogs(wireMessage(3, append([]byte{0}, payload...)))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":   "login",
+		"count":  int64(3),
+		"level":  "LEVEL_ERROR",
+		"tags":   []any{"a", "b"},
+		"labels": map[string]any{"env": "prod"},
+		"origin": map[string]any{"host": "host1"},
+	}, bodyOf(t, logs))
+
+	// The nested message type is written as the indexes [0, 1], zigzag encoded. The entries of the
+	// labels map are the first nested message type.
+	payload, err = proto.Marshal(origin.Interface())
+	require.NoError(t, err)
+	logs, err = e.UnmarshalLogs(wireMessage(3, append([]byte{4, 0, 2}, payload...)))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "host1"}, bodyOf(t, logs))
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	e := newTestExtension(t, func(*Config) {})
+
+	_, err := e.UnmarshalLogs([]byte("not a schema registry message"))
+	assert.EqualError(t, err, "message isn't in the schema registry wire format")
+
+	_, err = e.UnmarshalLogs(wireMessage(42, []byte{0}))
+	assert.EqualError(t, err, "failed to look up schema 42: schema 42 not found")
+
+	_, err = e.UnmarshalLogs(wireMessage(1, []byte{0xff}))
+	assert.ErrorContains(t, err, "failed to deserialize avro record")
+
+	_, err = e.UnmarshalLogs(wireMessage(3, []byte{2, 6}))
+	assert.EqualError(t, err, "protobuf message index 3 out of range")
+}
+
+func TestMarshalAvro(t *testing.T) {
+	e := newTestExtension(t, func(cfg *Config) { cfg.Subject = "logs-value" })
+	bodies := []map[string]any{
+		{"message": "first", "severity": int64(9), "hostname": map[string]any{"string": "host1"}},
+		{"message": "second", "severity": int64(17), "hostname": nil},
+	}
+
+	messages, err := e.MarshalLogRecords(logsWithBodies(t, bodies...))
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	for i, message := range messages {
+		// The latest schema of the subject is used
+		assert.Equal(t, uint32(2), binary.BigEndian.Uint32(message[1:headerSize]))
+		logs, err := e.UnmarshalLogs(message)
+		require.NoError(t, err)
+		assert.Equal(t, bodies[i], bodyOf(t, logs))
+	}
+
+	message, err := e.MarshalLogs(logsWithBodies(t, bodies[0]))
+	require.NoError(t, err)
+	assert.Equal(t, messages[0], message)
+
+	_, err = e.MarshalLogs(logsWithBodies(t, bodies...))
+	assert.EqualError(t, err, "marshaling 2 log records into a single message isn't supported by the schema registry wire format, "+
+		"they must be marshaled with MarshalLogRecords")
+
+	message, err = e.MarshalLogs(plog.NewLogs())
+	require.NoError(t, err)
+	assert.Empty(t, message)
+
+	_, err = e.MarshalLogs(logsWithBodies(t, map[string]any{"message": "no severity"}))
+	assert.ErrorContains(t, err, "failed to serialize avro record")
+}
+
+func TestMarshalProtobuf(t *testing.T) {
+	e := newTestExtension(t, func(cfg *Config) { cfg.Subject = "events-value" })
+	body := map[string]any{
+		"name":   "login",
+		"count":  int64(3),
+		"level":  "LEVEL_INFO",
+		"tags":   []any{"a"},
+		"labels": map[string]any{"env": "prod"},
+		"origin": map[string]any{"host": "host1"},
+	}
+
+	message, err := e.MarshalLogs(logsWithBodies(t, body))
+	require.NoError(t, err)
+	logs, err := e.UnmarshalLogs(message)
+	require.NoError(t, err)
+	assert.Equal(t, body, bodyOf(t, logs))
+}
+
+func TestMarshalNoSubject(t *testing.T) {
+	e := newTestExtension(t, func(*Config) {})
+	_, err := e.MarshalLogs(logsWithBodies(t, map[string]any{"message": "log"}))
+	assert.ErrorIs(t, err, errNoSubject)
+}
+
+func TestHTTPRegistry(t *testing.T) {
+	var idRequests, subjectRequests, notFoundRequests atomic.Int32
+	var available atomic.Bool
+	available.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			idRequests.Add(1)
+			_, _ = w.Write([]byte(`{"schema": "\"string\""}`))
+		case "/subjects/logs-value/versions/latest":
+			subjectRequests.Add(1)
+			_, _ = w.Write([]byte(`{"subject": "logs-value", "version": 1, "id": 1, "schema": "\"string\""}`))
+		default:
+			notFoundRequests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code": 40403, "message": "Schema not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Registry.Endpoint = server.URL
+	cfg.Subject = "logs-value"
+	e := newExtension(cfg, componenttest.NewNopTelemetrySettings())
+	e.now = func() time.Time { return now }
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, e.Shutdown(context.Background())) }()
+
+	// The schemas are looked up once
+	for i := 0; i < 3; i++ {
+		logs, err := e.UnmarshalLogs(wireMessage(1, []byte{0x06, 'l', 'o', 'g'}))
+		require.NoError(t, err)
+		assert.Equal(t, "log", logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	}
+	assert.Equal(t, int32(1), idRequests.Load())
+
+	// The unknown schemas are looked up again after the negative cache TTL
+	for i := 0; i < 3; i++ {
+		_, err := e.UnmarshalLogs(wireMessage(2, nil))
+		assert.EqualError(t, err, "failed to look up schema 2: schema registry returned 404 Not Found: Schema not found")
+	}
+	assert.Equal(t, int32(1), notFoundRequests.Load())
+	now = now.Add(cfg.NegativeCacheTTL)
+	_, err := e.UnmarshalLogs(wireMessage(2, nil))
+	require.Error(t, err)
+	assert.Equal(t, int32(2), notFoundRequests.Load())
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log")
+	message, err := e.MarshalLogs(logs)
+	require.NoError(t, err)
+	assert.Equal(t, wireMessage(1, []byte{0x06, 'l', 'o', 'g'}), message)
+	_, err = e.MarshalLogs(logs)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), subjectRequests.Load())
+
+	// The subject is looked up again after the refresh interval
+	now = now.Add(cfg.SubjectRefreshInterval)
+	_, err = e.MarshalLogs(logs)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), subjectRequests.Load())
+
+	// The previous schema is used while the registry is unavailable
+	available.Store(false)
+	now = now.Add(cfg.SubjectRefreshInterval)
+	message, err = e.MarshalLogs(logs)
+	require.NoError(t, err)
+	assert.Equal(t, wireMessage(1, []byte{0x06, 'l', 'o', 'g'}), message)
+}
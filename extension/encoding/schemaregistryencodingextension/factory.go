@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension/internal/metadata"
+)
+
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createExtension(_ context.Context, settings extension.Settings, config component.Config) (extension.Extension, error) {
+	return newExtension(config.(*Config), settings.TelemetrySettings), nil
+}
+
+func createDefaultConfig() component.Config {
+	registry := confighttp.NewDefaultClientConfig()
+	registry.Timeout = 10 * time.Second
+	return &Config{
+		Registry:               registry,
+		SubjectRefreshInterval: 5 * time.Minute,
+		NegativeCacheTTL:       time.Minute,
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension"
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const protobufSchemaFile = "schema.proto"
+
+var errInvalidMessageIndexes = errors.New("invalid protobuf message indexes")
+
+// protobufCodec decodes the protobuf messages of a schema. The payload of the messages starts with the
+// indexes of the message type in the schema, as written by the Confluent serializers.
+type protobufCodec struct {
+	file protoreflect.FileDescriptor
+}
+
+func newProtobufCodec(schema string) (*protobufCodec, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{protobufSchemaFile: schema}),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), protobufSchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile protobuf schema: %w", err)
+	}
+	if files[0].Messages().Len() == 0 {
+		return nil, errors.New("protobuf schema has no message type")
+	}
+	return &protobufCodec{file: files[0]}, nil
+}
+
+func (c *protobufCodec) decode(payload []byte) (any, error) {
+	descriptor, payload, err := c.readMessageDescriptor(payload)
+	if err != nil {
+		return nil, err
+	}
+	message := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, fmt.Errorf("failed to deserialize protobuf message: %w", err)
+	}
+	return messageToMap(message), nil
+}
+
+// encode serializes a value as the first message type of the schema.
+func (c *protobufCodec) encode(value any) ([]byte, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	message := dynamicpb.NewMessage(c.file.Messages().Get(0))
+	if err := protojson.Unmarshal(content, message); err != nil {
+		return nil, fmt.Errorf("failed to serialize protobuf message: %w", err)
+	}
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize protobuf message: %w", err)
+	}
+	// The indexes of the first message type are written as a single 0
+	return append([]byte{0}, payload...), nil
+}
+
+// readMessageDescriptor reads the indexes of the message type, which are the index of the message in
+// the schema followed by the indexes of its nested messages.
+func (c *protobufCodec) readMessageDescriptor(payload []byte) (protoreflect.MessageDescriptor, []byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 {
+		return nil, nil, errInvalidMessageIndexes
+	}
+	payload = payload[n:]
+	indexes := []int64{0}
+	if count > 0 {
+		indexes = make([]int64, count)
+		for i := range indexes {
+			if indexes[i], n = binary.Varint(payload); n <= 0 {
+				return nil, nil, errInvalidMessageIndexes
+			}
+			payload = payload[n:]
+		}
+	}
+
+	messages := c.file.Messages()
+	var descriptor protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index < 0 || index >= int64(messages.Len()) {
+			return nil, nil, fmt.Errorf("protobuf message index %d out of range", index)
+		}
+		descriptor = messages.Get(int(index))
+		messages = descriptor.Messages()
+	}
+	return descriptor, payload, nil
+}
+
+// messageToMap converts a message to a map keyed by the field names, where the fields without
+// presence have their default value when they aren't set.
+func messageToMap(message protoreflect.Message) map[string]any {
+	fields := message.Descriptor().Fields()
+	m := make(map[string]any, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.HasPresence() && !message.Has(field) {
+			continue
+		}
+		value := message.Get(field)
+		switch {
+		case field.IsList():
+			list := value.List()
+			items := make([]any, list.Len())
+			for j := range items {
+				items[j] = valueToRaw(field, list.Get(j))
+			}
+			m[string(field.Name())] = items
+		case field.IsMap():
+			entries := make(map[string]any, value.Map().Len())
+			value.Map().Range(func(key protoreflect.MapKey, v protoreflect.Value) bool {
+				entries[key.String()] = valueToRaw(field.MapValue(), v)
+				return true
+			})
+			m[string(field.Name())] = entries
+		default:
+			m[string(field.Name())] = valueToRaw(field, value)
+		}
+	}
+	return m
+}
+
+func valueToRaw(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return value.Bool()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(value.Uint()) //nolint:gosec // the log record values are signed
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float()
+	case protoreflect.StringKind:
+		return value.String()
+	case protoreflect.BytesKind:
+		return value.Bytes()
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return int64(value.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageToMap(value.Message())
+	default:
+		return nil
+	}
+}
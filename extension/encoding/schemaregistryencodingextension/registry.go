@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schemaregistryencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/schemaregistryencodingextension"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	schemaTypeAvro     = "AVRO"
+	schemaTypeProtobuf = "PROTOBUF"
+)
+
+// schema is a schema registered in a schema registry.
+type schema struct {
+	ID      uint32 `json:"id" yaml:"id"`
+	Subject string `json:"subject" yaml:"subject"`
+	// Type is AVRO when it's empty, as for the schema registry.
+	Type   string `json:"schemaType" yaml:"schema_type"`
+	Schema string `json:"schema" yaml:"schema"`
+}
+
+// registry looks up the schemas of a schema registry.
+type registry interface {
+	schemaByID(ctx context.Context, id uint32) (schema, error)
+	latestSchema(ctx context.Context, subject string) (schema, error)
+}
+
+// httpRegistry looks up the schemas with the REST API of a Confluent compatible schema registry.
+type httpRegistry struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (r *httpRegistry) schemaByID(ctx context.Context, id uint32) (schema, error) {
+	s, err := r.get(ctx, "/schemas/ids/"+strconv.FormatUint(uint64(id), 10))
+	s.ID = id
+	return s, err
+}
+
+func (r *httpRegistry) latestSchema(ctx context.Context, subject string) (schema, error) {
+	return r.get(ctx, "/subjects/"+url.PathEscape(subject)+"/versions/latest")
+}
+
+func (r *httpRegistry) get(ctx context.Context, path string) (schema, error) {
+	var s schema
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(r.endpoint, "/")+path, http.NoBody)
+	if err != nil {
+		return s, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return s, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return s, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var registryErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &registryErr) == nil && registryErr.Message != "" {
+			return s, fmt.Errorf("schema registry returned %s: %s", resp.Status, registryErr.Message)
+		}
+		return s, fmt.Errorf("schema registry returned %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, &s); err != nil {
+		return s, fmt.Errorf("failed to decode the schema registry response: %w", err)
+	}
+	return s, nil
+}
+
+// fileRegistry is a stand-in for a schema registry, with the schemas listed in a file.
+type fileRegistry struct {
+	schemas []schema
+}
+
+func newFileRegistry(path string) (*fileRegistry, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schemas file: %w", err)
+	}
+	var file struct {
+		Schemas []schema `yaml:"schemas"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode the schemas file: %w", err)
+	}
+	return &fileRegistry{schemas: file.Schemas}, nil
+}
+
+func (r *fileRegistry) schemaByID(_ context.Context, id uint32) (schema, error) {
+	for _, s := range r.schemas {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return schema{}, fmt.Errorf("schema %d not found", id)
+}
+
+// latestSchema returns the schema of the subject with the highest ID.
+func (r *fileRegistry) latestSchema(_ context.Context, subject string) (schema, error) {
+	var latest *schema
+	for i, s := range r.schemas {
+		if s.Subject == subject && (latest == nil || s.ID > latest.ID) {
+			latest = &r.schemas[i]
+		}
+	}
+	if latest == nil {
+		return schema{}, fmt.Errorf("subject %q not found", subject)
+	}
+	return *latest, nil
+}
@@ -167,6 +167,13 @@ func (hc *healthCheckExtension) NotReady() error {
 	return nil
 }
 
+// Subscribe streams the aggregated status of the given scope, so other components, such as the
+// failover connector, can react to the status of the pipelines. To unsubscribe, call the returned
+// UnsubscribeFunc.
+func (hc *healthCheckExtension) Subscribe(scope status.Scope, verbosity status.Verbosity) (<-chan *status.AggregateStatus, status.UnsubscribeFunc) {
+	return hc.aggregator.Subscribe(scope, verbosity)
+}
+
 func (hc *healthCheckExtension) eventLoop(ctx context.Context) {
 	// Record events with component.StatusStarting, but queue other events until
 	// PipelineWatcher.Ready is called. This prevents aggregate statuses from
@@ -91,6 +91,37 @@ func TestComponentStatus(t *testing.T) {
 	assert.Equal(t, componentstatus.StatusStopping, st.Status())
 }
 
+func TestSubscribe(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HTTPConfig.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.GRPCConfig.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.UseV2 = true
+	ext := newExtension(context.Background(), *cfg, extensiontest.NewNopSettings(extensiontest.NopType))
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, ext.Ready())
+
+	traces := testhelpers.NewPipelineMetadata("traces")
+	statusCh, unsubscribe := ext.Subscribe(status.Scope(traces.PipelineID.String()), status.Concise)
+	defer unsubscribe()
+
+	// The pipeline hasn't reported yet
+	assert.Nil(t, <-statusCh)
+
+	ext.ComponentStatusChanged(traces.ExporterID, componentstatus.NewRecoverableErrorEvent(assert.AnError))
+
+	select {
+	case st := <-statusCh:
+		require.NotNil(t, st)
+		assert.Equal(t, componentstatus.StatusRecoverableError, st.Status())
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the pipeline status")
+	}
+
+	require.NoError(t, ext.NotReady())
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
 func TestNotifyConfig(t *testing.T) {
 	confMap, err := confmaptest.LoadConf(
 		filepath.Join("internal", "http", "testdata", "config.yaml"),
@@ -38,6 +38,8 @@ const (
 	ContainerType EndpointType = "container"
 	// KafkaTopicType is a kafka topic endpoint
 	KafkaTopicType EndpointType = "kafka.topics"
+	// SDTargetType is a target discovered through Prometheus file or HTTP service discovery.
+	SDTargetType EndpointType = "sd.target"
 )
 
 var (
@@ -48,6 +50,7 @@ var (
 	_ EndpointDetails = (*HostPort)(nil)
 	_ EndpointDetails = (*Container)(nil)
 	_ EndpointDetails = (*KafkaTopic)(nil)
+	_ EndpointDetails = (*SDTarget)(nil)
 )
 
 // EndpointDetails provides additional context about an endpoint such as a Pod or Port.
@@ -400,3 +403,22 @@ func (k *KafkaTopic) Env() EndpointEnv {
 func (k *KafkaTopic) Type() EndpointType {
 	return KafkaTopicType
 }
+
+// SDTarget is a target discovered through Prometheus file or HTTP service discovery.
+type SDTarget struct {
+	// Labels is the map of labels of the target group of the target.
+	Labels map[string]string
+	// Source is the file or URL the target was discovered from.
+	Source string
+}
+
+func (t *SDTarget) Env() EndpointEnv {
+	return map[string]any{
+		"labels": t.Labels,
+		"source": t.Source,
+	}
+}
+
+func (t *SDTarget) Type() EndpointType {
+	return SDTargetType
+}
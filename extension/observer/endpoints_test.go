@@ -296,6 +296,26 @@ func TestEndpointEnv(t *testing.T) {
 				"endpoint": "topic1",
 			},
 		},
+		{
+			name: "SD target",
+			endpoint: Endpoint{
+				ID:     EndpointID("10.0.0.1:9100/1"),
+				Target: "10.0.0.1:9100",
+				Details: &SDTarget{
+					Labels: map[string]string{"job": "node"},
+					Source: "/etc/targets.json",
+				},
+			},
+			want: EndpointEnv{
+				"id":       "10.0.0.1:9100/1",
+				"type":     "sd.target",
+				"endpoint": "10.0.0.1:9100",
+				"host":     "10.0.0.1",
+				"port":     "9100",
+				"labels":   map[string]string{"job": "node"},
+				"source":   "/etc/targets.json",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
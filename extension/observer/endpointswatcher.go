@@ -26,6 +26,7 @@ type EndpointsWatcher struct {
 	// map of NotifyID to known endpoints for that Notify (subscriptions can occur at different times in service startup).
 	// ~sync.Map(map[NotifyID]map[EndpointID]Endpoint)
 	existingEndpoints sync.Map
+	notifyMu          sync.Mutex
 	stop              chan struct{}
 	once              *sync.Once
 	logger            *zap.Logger
@@ -56,12 +57,7 @@ func (ew *EndpointsWatcher) ListAndWatch(notify Notify) {
 				case <-ew.stop:
 					return
 				case <-ticker.C:
-					var toNotify []NotifyID
-					ew.toNotify.Range(func(notifyID, _ any) bool {
-						toNotify = append(toNotify, notifyID.(NotifyID))
-						return true
-					})
-					ew.notifyOfLatestEndpoints(toNotify...)
+					ew.Refresh()
 				}
 			}
 		}()
@@ -71,6 +67,21 @@ func (ew *EndpointsWatcher) ListAndWatch(notify Notify) {
 	ew.notifyOfLatestEndpoints(notify.ID())
 }
 
+// Refresh runs EndpointsLister.ListEndpoints() and alerts all subscribed Notify's of the differences
+// from the previous call without waiting for the next RefreshInterval, e.g. when the observer is
+// notified of a change of the endpoints.
+func (ew *EndpointsWatcher) Refresh() {
+	var toNotify []NotifyID
+	ew.toNotify.Range(func(notifyID, _ any) bool {
+		toNotify = append(toNotify, notifyID.(NotifyID))
+		return true
+	})
+	if len(toNotify) == 0 {
+		return
+	}
+	ew.notifyOfLatestEndpoints(toNotify...)
+}
+
 func (ew *EndpointsWatcher) Unsubscribe(notify Notify) {
 	ew.toNotify.Delete(notify.ID())
 	ew.existingEndpoints.Delete(notify.ID())
@@ -79,6 +90,11 @@ func (ew *EndpointsWatcher) Unsubscribe(notify Notify) {
 // notifyOfLatestEndpoints alerts subscribed Notify instances by their NotifyID of latest Endpoint events,
 // updating their internal store with results of ListEndpoints() call.
 func (ew *EndpointsWatcher) notifyOfLatestEndpoints(notifyIDs ...NotifyID) {
+	// The endpoints are listed and compared to the previous ones one call at a time, as calls can
+	// happen concurrently on a regular interval, on subscription and on refresh.
+	ew.notifyMu.Lock()
+	defer ew.notifyMu.Unlock()
+
 	latestEndpoints := ew.EndpointsLister.ListEndpoints()
 
 	wg := &sync.WaitGroup{}
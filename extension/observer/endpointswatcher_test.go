@@ -86,6 +86,31 @@ func TestNotifyOfLatestEndpoints(t *testing.T) {
 	require.Nil(t, existingEndpoints(t, watcher, notify.ID()))
 }
 
+func TestRefresh(t *testing.T) {
+	lister, watcher, notify := setup(t)
+	defer watcher.StopListAndWatch()
+
+	// Nothing is listed before a Notify subscribes
+	lister.addEndpoint(0)
+	watcher.Refresh()
+	notify.AssertNotCalled(t, "OnAdd", mock.Anything)
+
+	zeroAdded := notify.On("OnAdd", []Endpoint{expectedEndpointZero})
+	watcher.ListAndWatch(notify)
+	notify.AssertExpectations(t)
+	zeroAdded.Unset()
+
+	// The subscribed Notify's are alerted without waiting for the refresh interval
+	oneAdded := notify.On("OnAdd", []Endpoint{expectedEndpointOne})
+	lister.addEndpoint(1)
+	watcher.Refresh()
+	notify.AssertExpectations(t)
+	oneAdded.Unset()
+
+	expected := map[EndpointID]Endpoint{"0": {ID: "0"}, "1": {ID: "1"}}
+	require.Equal(t, expected, existingEndpoints(t, watcher, notify.ID()))
+}
+
 func TestNotifyOfLatestEndpointsMultipleNotify(t *testing.T) {
 	lister, watcher, notifyOne := setup(t)
 	defer watcher.StopListAndWatch()
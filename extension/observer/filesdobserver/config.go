@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filesdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/filesdobserver"
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const defaultRefreshInterval = 5 * time.Minute
+
+// Config defines configuration for the file service discovery observer.
+type Config struct {
+	// Files are the patterns of the files to read the targets from, as supported by filepath.Match.
+	// The files must be in the Prometheus file service discovery format, in JSON (.json) or YAML (.yml, .yaml).
+	Files []string `mapstructure:"files"`
+	// RefreshInterval determines how often the files are read, in addition to when
+	// their directories change.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Files) == 0 {
+		return errors.New("files must be specified")
+	}
+	for _, pattern := range cfg.Files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid files pattern %q: %w", pattern, err)
+		}
+	}
+	if cfg.RefreshInterval <= 0 {
+		return errors.New("refresh_interval must be greater than 0")
+	}
+	return nil
+}
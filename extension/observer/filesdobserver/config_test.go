@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filesdobserver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/filesdobserver/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: &Config{
+				Files:           []string{"/etc/targets/*.json"},
+				RefreshInterval: defaultRefreshInterval,
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all_settings"),
+			expected: &Config{
+				Files:           []string{"/etc/targets/*.json", "/etc/targets/*.yaml"},
+				RefreshInterval: time.Minute,
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "no_files"),
+			expectedErr: "files must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_pattern"),
+			expectedErr: `invalid files pattern "/etc/targets/[.json": syntax error in pattern`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_refresh_interval"),
+			expectedErr: "refresh_interval must be greater than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package filesdobserver provides an observer reporting the targets listed in files
+// in the Prometheus file service discovery format.
+package filesdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/filesdobserver"
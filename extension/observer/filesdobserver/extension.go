@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filesdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/filesdobserver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+var (
+	_ extension.Extension      = (*fileSDObserver)(nil)
+	_ observer.EndpointsLister = (*fileSDObserver)(nil)
+	_ observer.Observable      = (*fileSDObserver)(nil)
+)
+
+type fileSDObserver struct {
+	*observer.EndpointsWatcher
+	logger *zap.Logger
+	config *Config
+
+	mu sync.Mutex
+	// endpointsByFile are the endpoints last read from each file, reported again
+	// when a file can't be read, for instance while it is being written.
+	endpointsByFile map[string][]observer.Endpoint
+
+	// watcher notifies the changes of the directories of the files, nil when they
+	// can't be watched and the files are only read every refresh interval.
+	watcher  *fsnotify.Watcher
+	watching sync.WaitGroup
+}
+
+func newObserver(logger *zap.Logger, config *Config) *fileSDObserver {
+	o := &fileSDObserver{
+		logger:          logger,
+		config:          config,
+		endpointsByFile: map[string][]observer.Endpoint{},
+	}
+	o.EndpointsWatcher = observer.NewEndpointsWatcher(o, config.RefreshInterval, logger)
+	return o
+}
+
+// Start watches the directories of the files, so the files are read again as soon as they change.
+// The files are still read every refresh interval, in case the directories can't be watched or
+// their changes are missed, for instance on network file systems.
+func (o *fileSDObserver) Start(context.Context, component.Host) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		o.logger.Warn("failed to watch the files, they are only read every refresh interval", zap.Error(err))
+		return nil
+	}
+	for _, dir := range o.dirs() {
+		if err = watcher.Add(dir); err != nil {
+			o.logger.Warn("failed to watch the directory, its files are only read every refresh interval", zap.String("directory", dir), zap.Error(err))
+		}
+	}
+	o.watcher = watcher
+
+	o.watching.Add(1)
+	go o.watch()
+	return nil
+}
+
+// watch reads the files again on every change of their directories, including the changes of
+// other files, e.g. the symbolic links swapped when a Kubernetes ConfigMap is updated.
+func (o *fileSDObserver) watch() {
+	defer o.watching.Done()
+	for {
+		select {
+		case _, ok := <-o.watcher.Events:
+			if !ok {
+				return
+			}
+			o.Refresh()
+		case err, ok := <-o.watcher.Errors:
+			if !ok {
+				return
+			}
+			o.logger.Warn("failed to watch the files", zap.Error(err))
+		}
+	}
+}
+
+func (o *fileSDObserver) Shutdown(context.Context) error {
+	var err error
+	if o.watcher != nil {
+		err = o.watcher.Close()
+		o.watching.Wait()
+	}
+	o.StopListAndWatch()
+	return err
+}
+
+// ListEndpoints is invoked by an observer.EndpointsWatcher helper to report the targets of the files.
+// It's required to implement observer.EndpointsLister
+func (o *fileSDObserver) ListEndpoints() []observer.Endpoint {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	endpointsByFile := map[string][]observer.Endpoint{}
+	for _, file := range o.files() {
+		groups, err := readTargetGroups(file)
+		if err != nil {
+			o.logger.Warn("failed to read targets, keeping the previous ones", zap.String("file", file), zap.Error(err))
+			endpointsByFile[file] = o.endpointsByFile[file]
+			continue
+		}
+		endpointsByFile[file] = observer.TargetGroupsEndpoints(groups, file)
+	}
+	o.endpointsByFile = endpointsByFile
+
+	var endpoints []observer.Endpoint
+	for _, file := range sortedKeys(endpointsByFile) {
+		endpoints = append(endpoints, endpointsByFile[file]...)
+	}
+	return endpoints
+}
+
+// files returns the files matching the configured patterns.
+func (o *fileSDObserver) files() []string {
+	matched := map[string]bool{}
+	for _, pattern := range o.config.Files {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			o.logger.Error("invalid files pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		for _, file := range files {
+			matched[file] = true
+		}
+	}
+	return sortedKeys(matched)
+}
+
+// dirs returns the directories of the files matching the configured patterns.
+func (o *fileSDObserver) dirs() []string {
+	matched := map[string]bool{}
+	for _, pattern := range o.config.Files {
+		dirs, err := filepath.Glob(filepath.Dir(pattern))
+		if err != nil {
+			continue
+		}
+		for _, dir := range dirs {
+			matched[dir] = true
+		}
+	}
+	return sortedKeys(matched)
+}
+
+func readTargetGroups(file string) ([]observer.TargetGroup, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []observer.TargetGroup
+	switch ext := filepath.Ext(file); ext {
+	case ".json":
+		err = json.Unmarshal(content, &groups)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(content, &groups)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q, expected .json, .yml or .yaml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse targets: %w", err)
+	}
+	return groups, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
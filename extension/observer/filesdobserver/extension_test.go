@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filesdobserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+func targets(endpoints []observer.Endpoint) []string {
+	var out []string
+	for _, e := range endpoints {
+		out = append(out, e.Target)
+	}
+	return out
+}
+
+func TestListEndpoints(t *testing.T) {
+	o := newObserver(zap.NewNop(), &Config{
+		Files: []string{filepath.Join("testdata", "*.json"), filepath.Join("testdata", "*.yaml")},
+	})
+
+	endpoints := o.ListEndpoints()
+	assert.Equal(t, []string{"10.0.0.1:9100", "10.0.0.2:9100", "10.0.0.3:8080", "10.0.1.1:9187"}, targets(endpoints))
+
+	// config.yaml is matched but isn't a list of target groups, it doesn't add any target.
+	jsonFile := filepath.Join("testdata", "targets.json")
+	assert.Equal(t, observer.Endpoint{
+		ID:     endpoints[0].ID,
+		Target: "10.0.0.1:9100",
+		Details: &observer.SDTarget{
+			Labels: map[string]string{"env": "prod", "job": "node"},
+			Source: jsonFile,
+		},
+	}, endpoints[0])
+	assert.Equal(t, &observer.SDTarget{
+		Labels: map[string]string{},
+		Source: jsonFile,
+	}, endpoints[2].Details)
+	assert.Equal(t, &observer.SDTarget{
+		Labels: map[string]string{"job": "postgres"},
+		Source: filepath.Join("testdata", "targets.yaml"),
+	}, endpoints[3].Details)
+}
+
+func TestListEndpointsUpdates(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "targets.yml")
+	require.NoError(t, os.WriteFile(file, []byte(`[{targets: ["a:80"]}]`), 0o600))
+
+	o := newObserver(zap.NewNop(), &Config{Files: []string{filepath.Join(dir, "*")}})
+	assert.Equal(t, []string{"a:80"}, targets(o.ListEndpoints()))
+
+	require.NoError(t, os.WriteFile(file, []byte(`[{targets: ["a:80", "b:80"]}]`), 0o600))
+	assert.Equal(t, []string{"a:80", "b:80"}, targets(o.ListEndpoints()))
+
+	// The previous targets are kept while the file is invalid.
+	require.NoError(t, os.WriteFile(file, []byte(`[{targets: ["a:80"`), 0o600))
+	assert.Equal(t, []string{"a:80", "b:80"}, targets(o.ListEndpoints()))
+
+	// Files with an unsupported extension are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "targets.txt"), []byte(`[{"targets": ["c:80"]}]`), 0o600))
+	assert.Equal(t, []string{"a:80", "b:80"}, targets(o.ListEndpoints()))
+
+	require.NoError(t, os.Remove(file))
+	assert.Empty(t, o.ListEndpoints())
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "targets.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"targets": ["a:80"]}]`), 0o600))
+
+	// The refresh interval is long enough for the changes to only be read by watching the directory.
+	o := newObserver(zap.NewNop(), &Config{Files: []string{filepath.Join(dir, "*.json")}, RefreshInterval: time.Hour})
+	require.NoError(t, o.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, o.Shutdown(context.Background())) }()
+
+	notify := &mockNotifier{endpoints: map[observer.EndpointID]observer.Endpoint{}}
+	o.ListAndWatch(notify)
+	assert.Equal(t, []string{"a:80"}, notify.targets())
+
+	require.NoError(t, os.WriteFile(file, []byte(`[{"targets": ["a:80", "b:80"]}]`), 0o600))
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []string{"a:80", "b:80"}, notify.targets())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.json"), []byte(`[{"targets": ["c:80"]}]`), 0o600))
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []string{"a:80", "b:80", "c:80"}, notify.targets())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(file))
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []string{"c:80"}, notify.targets())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchMissingDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+
+	// The files of a missing directory are only read every refresh interval.
+	o := newObserver(zap.NewNop(), &Config{Files: []string{filepath.Join(dir, "*.json")}, RefreshInterval: 10 * time.Millisecond})
+	require.NoError(t, o.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, o.Shutdown(context.Background())) }()
+
+	notify := &mockNotifier{endpoints: map[observer.EndpointID]observer.Endpoint{}}
+	o.ListAndWatch(notify)
+	assert.Empty(t, notify.targets())
+
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "targets.json"), []byte(`[{"targets": ["a:80"]}]`), 0o600))
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []string{"a:80"}, notify.targets())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+type mockNotifier struct {
+	mu        sync.Mutex
+	endpoints map[observer.EndpointID]observer.Endpoint
+}
+
+func (m *mockNotifier) ID() observer.NotifyID {
+	return "mockNotifier"
+}
+
+func (m *mockNotifier) OnAdd(added []observer.Endpoint) {
+	m.OnChange(added)
+}
+
+func (m *mockNotifier) OnRemove(removed []observer.Endpoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range removed {
+		delete(m.endpoints, e.ID)
+	}
+}
+
+func (m *mockNotifier) OnChange(changed []observer.Endpoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range changed {
+		m.endpoints[e.ID] = e
+	}
+}
+
+// targets returns the sorted targets of the endpoints.
+func (m *mockNotifier) targets() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, e := range m.endpoints {
+		out = append(out, e.Target)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filesdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/filesdobserver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/filesdobserver/internal/metadata"
+)
+
+// NewFactory creates a factory for the file service discovery observer extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		RefreshInterval: defaultRefreshInterval,
+	}
+}
+
+func createExtension(
+	_ context.Context,
+	params extension.Settings,
+	cfg component.Config,
+) (extension.Extension, error) {
+	return newObserver(params.Logger, cfg.(*Config)), nil
+}
@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/httpsdobserver"
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+const (
+	defaultRefreshInterval = 60 * time.Second
+	defaultTimeout         = 10 * time.Second
+)
+
+// Config defines configuration for the HTTP service discovery observer.
+type Config struct {
+	// ClientConfig configures the client polling the service discovery endpoint,
+	// which must return the targets in the Prometheus HTTP service discovery format.
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// RefreshInterval determines how often the service discovery endpoint is polled.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to parse endpoint %q: %w", cfg.Endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("endpoint %q must use the http or https scheme", cfg.Endpoint)
+	}
+	if cfg.RefreshInterval <= 0 {
+		return errors.New("refresh_interval must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsdobserver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/httpsdobserver/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	allSettings := createDefaultConfig().(*Config)
+	allSettings.Endpoint = "https://sd.example.com/targets"
+	allSettings.RefreshInterval = 30 * time.Second
+	allSettings.Timeout = 5 * time.Second
+	allSettings.Headers = map[string]configopaque.String{"Authorization": "Bearer token"}
+
+	defaultSettings := createDefaultConfig().(*Config)
+	defaultSettings.Endpoint = "http://localhost:8080/targets"
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: defaultSettings,
+		},
+		{
+			id:       component.NewIDWithName(metadata.Type, "all_settings"),
+			expected: allSettings,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "no_endpoint"),
+			expectedErr: "endpoint must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_scheme"),
+			expectedErr: `endpoint "file:///etc/targets.json" must use the http or https scheme`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_refresh_interval"),
+			expectedErr: "refresh_interval must be greater than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package httpsdobserver provides an observer reporting the targets returned by an
+// endpoint implementing the Prometheus HTTP service discovery protocol.
+package httpsdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/httpsdobserver"
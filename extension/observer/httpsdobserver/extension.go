@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/httpsdobserver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+var (
+	_ extension.Extension      = (*httpSDObserver)(nil)
+	_ observer.EndpointsLister = (*httpSDObserver)(nil)
+	_ observer.Observable      = (*httpSDObserver)(nil)
+)
+
+type httpSDObserver struct {
+	*observer.EndpointsWatcher
+	telemetry component.TelemetrySettings
+	config    *Config
+	// ctx is canceled on shutdown to abort the in-flight request.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	client *http.Client
+	// endpoints are the endpoints of the last successful poll, reported again
+	// when the service discovery endpoint can't be reached.
+	endpoints []observer.Endpoint
+}
+
+func newObserver(telemetry component.TelemetrySettings, config *Config) *httpSDObserver {
+	o := &httpSDObserver{
+		telemetry: telemetry,
+		config:    config,
+	}
+	o.ctx, o.cancel = context.WithCancel(context.Background())
+	o.EndpointsWatcher = observer.NewEndpointsWatcher(o, config.RefreshInterval, telemetry.Logger)
+	return o
+}
+
+func (o *httpSDObserver) Start(ctx context.Context, host component.Host) error {
+	client, err := o.config.ToClient(ctx, host, o.telemetry)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.client = client
+	return nil
+}
+
+func (o *httpSDObserver) Shutdown(context.Context) error {
+	o.cancel()
+	o.StopListAndWatch()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.client != nil {
+		o.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// maxResponseSize is the maximum size of the response of the service discovery endpoint.
+const maxResponseSize = 10 << 20
+
+// ListEndpoints is invoked by an observer.EndpointsWatcher helper to report the targets returned
+// by the service discovery endpoint. It's required to implement observer.EndpointsLister
+func (o *httpSDObserver) ListEndpoints() []observer.Endpoint {
+	// The endpoint is polled without holding the lock, so a slow endpoint doesn't block Shutdown.
+	o.mu.Lock()
+	client := o.client
+	o.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+	groups, err := o.fetchTargetGroups(client)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err != nil {
+		o.telemetry.Logger.Warn("failed to fetch targets, keeping the previous ones", zap.String("endpoint", o.config.Endpoint), zap.Error(err))
+		return o.endpoints
+	}
+	o.endpoints = observer.TargetGroupsEndpoints(groups, o.config.Endpoint)
+	return o.endpoints
+}
+
+func (o *httpSDObserver) fetchTargetGroups(client *http.Client) ([]observer.TargetGroup, error) {
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodGet, o.config.Endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	// Advertised as in the Prometheus HTTP service discovery protocol.
+	req.Header.Set("X-Prometheus-Refresh-Interval-Seconds", strconv.FormatFloat(o.config.RefreshInterval.Seconds(), 'f', -1, 64))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("the response is larger than %d bytes", maxResponseSize)
+	}
+	var groups []observer.TargetGroup
+	if err = json.Unmarshal(body, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode targets: %w", err)
+	}
+	return groups, nil
+}
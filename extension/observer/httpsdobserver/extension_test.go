@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsdobserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+func TestListEndpoints(t *testing.T) {
+	var (
+		response atomic.Value
+		status   atomic.Int32
+	)
+	response.Store(`[{"targets": ["10.0.0.1:9100", "10.0.0.2:9100"], "labels": {"job": "node"}}]`)
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "60", r.Header.Get("X-Prometheus-Refresh-Interval-Seconds"))
+		w.WriteHeader(int(status.Load()))
+		_, _ = w.Write([]byte(response.Load().(string)))
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	o := newObserver(componenttest.NewNopTelemetrySettings(), cfg)
+
+	// The endpoint isn't polled before the client is created.
+	assert.Empty(t, o.ListEndpoints())
+
+	require.NoError(t, o.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, o.Shutdown(context.Background())) }()
+
+	endpoints := o.ListEndpoints()
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, observer.Endpoint{
+		ID:     endpoints[0].ID,
+		Target: "10.0.0.1:9100",
+		Details: &observer.SDTarget{
+			Labels: map[string]string{"job": "node"},
+			Source: server.URL,
+		},
+	}, endpoints[0])
+	assert.Equal(t, "10.0.0.2:9100", endpoints[1].Target)
+
+	response.Store(`[{"targets": ["10.0.0.2:9100"], "labels": {"job": "node"}}]`)
+	endpoints = o.ListEndpoints()
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "10.0.0.2:9100", endpoints[0].Target)
+
+	// The previous targets are kept while the endpoint fails.
+	status.Store(http.StatusInternalServerError)
+	assert.Equal(t, endpoints, o.ListEndpoints())
+
+	status.Store(http.StatusOK)
+	response.Store(`{"targets": []}`)
+	assert.Equal(t, endpoints, o.ListEndpoints())
+
+	response.Store(`[]`)
+	assert.Empty(t, o.ListEndpoints())
+}
+
+func TestListEndpointsTooLarge(t *testing.T) {
+	var tooLarge atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if tooLarge.Load() {
+			_, _ = w.Write([]byte(`[{"targets": ["10.0.0.2:9100"], "labels": {"job": "` + strings.Repeat("x", maxResponseSize) + `"}}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"targets": ["10.0.0.1:9100"]}]`))
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	o := newObserver(componenttest.NewNopTelemetrySettings(), cfg)
+	require.NoError(t, o.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, o.Shutdown(context.Background())) }()
+
+	endpoints := o.ListEndpoints()
+	require.Len(t, endpoints, 1)
+
+	// The previous targets are kept when the response is too large.
+	tooLarge.Store(true)
+	assert.Equal(t, endpoints, o.ListEndpoints())
+}
+
+func TestListEndpointsUnlocked(t *testing.T) {
+	polled := make(chan struct{})
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		polled <- struct{}{}
+		<-unblock
+		_, _ = w.Write([]byte(`[{"targets": ["10.0.0.1:9100"]}]`))
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	o := newObserver(componenttest.NewNopTelemetrySettings(), cfg)
+	require.NoError(t, o.Start(context.Background(), componenttest.NewNopHost()))
+
+	listed := make(chan []observer.Endpoint)
+	go func() { listed <- o.ListEndpoints() }()
+	<-polled
+
+	// The lock isn't held while the endpoint is polled
+	require.True(t, o.mu.TryLock())
+	o.mu.Unlock()
+
+	close(unblock)
+	assert.Len(t, <-listed, 1)
+	require.NoError(t, o.Shutdown(context.Background()))
+}
@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsdobserver // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/httpsdobserver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer/httpsdobserver/internal/metadata"
+)
+
+// NewFactory creates a factory for the HTTP service discovery observer extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Timeout = defaultTimeout
+	return &Config{
+		ClientConfig:    clientConfig,
+		RefreshInterval: defaultRefreshInterval,
+	}
+}
+
+func createExtension(
+	_ context.Context,
+	params extension.Settings,
+	cfg component.Config,
+) (extension.Extension, error) {
+	return newObserver(params.TelemetrySettings, cfg.(*Config)), nil
+}
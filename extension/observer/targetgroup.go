@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package observer // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+
+import (
+	"fmt"
+)
+
+// TargetGroup is a group of targets sharing the same labels, in the format used by
+// Prometheus file and HTTP service discovery.
+type TargetGroup struct {
+	Targets []string          `json:"targets" yaml:"targets"`
+	Labels  map[string]string `json:"labels" yaml:"labels"`
+}
+
+// TargetGroupsEndpoints returns the endpoints of the targets of the groups discovered from source.
+// The ID of an endpoint depends on its source and target only, so the endpoint of a target whose
+// labels change is reported as changed. A target listed in several groups results in several
+// endpoints, the ID of the n-th one after the first is suffixed with #n.
+func TargetGroupsEndpoints(groups []TargetGroup, source string) []Endpoint {
+	var endpoints []Endpoint
+	occurrences := map[string]int{}
+	for _, group := range groups {
+		labels := group.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		seen := map[string]bool{}
+		for _, target := range group.Targets {
+			if target == "" || seen[target] {
+				continue
+			}
+			seen[target] = true
+			id := EndpointID(source + "/" + target)
+			if n := occurrences[target]; n > 0 {
+				id = EndpointID(fmt.Sprintf("%s#%d", id, n))
+			}
+			occurrences[target]++
+			endpoints = append(endpoints, Endpoint{
+				ID:     id,
+				Target: target,
+				Details: &SDTarget{
+					Labels: labels,
+					Source: source,
+				},
+			})
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package observer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetGroupsEndpoints(t *testing.T) {
+	groups := []TargetGroup{
+		{
+			Targets: []string{"10.0.0.1:9100", "10.0.0.2:9100", "10.0.0.1:9100", ""},
+			Labels:  map[string]string{"job": "node", "env": "prod"},
+		},
+		{
+			Targets: []string{"10.0.0.1:9100"},
+			Labels:  map[string]string{"job": "other"},
+		},
+		{
+			Targets: []string{"10.0.0.3:9100"},
+		},
+	}
+
+	endpoints := TargetGroupsEndpoints(groups, "targets.json")
+	require.Len(t, endpoints, 4)
+
+	assert.Equal(t, "10.0.0.1:9100", endpoints[0].Target)
+	assert.Equal(t, &SDTarget{Labels: map[string]string{"job": "node", "env": "prod"}, Source: "targets.json"}, endpoints[0].Details)
+	assert.Equal(t, "10.0.0.2:9100", endpoints[1].Target)
+	assert.Equal(t, "10.0.0.1:9100", endpoints[2].Target)
+	assert.NotEqual(t, endpoints[0].ID, endpoints[2].ID, "the same target with different labels must have different IDs")
+	assert.Equal(t, &SDTarget{Labels: map[string]string{}, Source: "targets.json"}, endpoints[3].Details)
+
+	assert.Equal(t, EndpointID("targets.json/10.0.0.1:9100"), endpoints[0].ID)
+	assert.Equal(t, EndpointID("targets.json/10.0.0.1:9100#1"), endpoints[2].ID)
+
+	// The IDs don't depend on the labels, whatever the order of the groups
+	again := TargetGroupsEndpoints([]TargetGroup{{
+		Targets: []string{"10.0.0.3:9100", "10.0.0.1:9100"},
+		Labels:  map[string]string{"job": "changed"},
+	}}, "targets.json")
+	require.Len(t, again, 2)
+	assert.Equal(t, endpoints[3].ID, again[0].ID)
+	assert.Equal(t, endpoints[0].ID, again[1].ID)
+
+	// The same targets of another source have other IDs
+	other := TargetGroupsEndpoints(groups[2:], "other.json")
+	require.Len(t, other, 1)
+	assert.NotEqual(t, endpoints[3].ID, other[0].ID)
+}
+
+func TestTargetGroupsEndpointsLabelsChange(t *testing.T) {
+	ml, watcher, mn := setup(t)
+	groups := []TargetGroup{{
+		Targets: []string{"10.0.0.1:9100"},
+		Labels:  map[string]string{"job": "node"},
+	}}
+	added := TargetGroupsEndpoints(groups, "targets.json")
+	ml.endpointsMap[added[0].ID] = added[0]
+
+	mn.On("OnAdd", added)
+	watcher.ListAndWatch(mn)
+	t.Cleanup(watcher.StopListAndWatch)
+	mn.AssertExpectations(t)
+
+	// Only the labels of the target change, the endpoint is changed rather than removed and added
+	groups[0].Labels = map[string]string{"job": "changed"}
+	changed := TargetGroupsEndpoints(groups, "targets.json")
+	require.Equal(t, added[0].ID, changed[0].ID)
+	ml.Lock()
+	ml.endpointsMap[changed[0].ID] = changed[0]
+	ml.Unlock()
+
+	mn.On("OnChange", changed)
+	watcher.Refresh()
+	mn.AssertExpectations(t)
+	mn.AssertNotCalled(t, "OnRemove", mock.Anything)
+}
@@ -77,20 +77,36 @@ func (rc redisClient) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+// Batch applies the operations in a transaction, so that they're atomic, e.g. a key is only returned by one of
+// the clients getting and deleting it at the same time.
 func (rc redisClient) Batch(ctx context.Context, ops ...*storage.Operation) error {
-	p := rc.client.Pipeline()
+	p := rc.client.TxPipeline()
+	gets := make(map[*storage.Operation]*redis.StringCmd)
 	for _, op := range ops {
 		switch op.Type {
 		case storage.Delete:
-			p.Del(ctx, op.Key)
+			p.Del(ctx, rc.prefix+op.Key)
 		case storage.Get:
-			p.Get(ctx, op.Key)
+			gets[op] = p.Get(ctx, rc.prefix+op.Key)
 		case storage.Set:
-			p.Set(ctx, op.Key, op.Value, rc.expiration)
+			p.Set(ctx, rc.prefix+op.Key, op.Value, rc.expiration)
 		}
 	}
-	_, err := p.Exec(ctx)
-	return err
+	if _, err := p.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	for op, cmd := range gets {
+		b, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			op.Value = nil
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		op.Value = b
+	}
+	return nil
 }
 
 func (rc redisClient) Close(_ context.Context) error {
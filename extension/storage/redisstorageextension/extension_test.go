@@ -137,6 +137,42 @@ func TestClientHandlesSimpleCases(t *testing.T) {
 	require.Nil(t, data)
 }
 
+func TestClientBatch(t *testing.T) {
+	t.Skip("Requires a Redis cluster to be present at localhost:6379")
+	ctx := context.Background()
+	se := newTestExtension(t)
+
+	client, err := se.GetClient(
+		ctx,
+		component.KindReceiver,
+		newTestEntity("my_component"),
+		"",
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close(ctx))
+	})
+
+	myBytes := []byte("value")
+	require.NoError(t, client.Batch(ctx, storage.SetOperation("key", myBytes)))
+
+	// The keys of the batches are the keys of the client
+	data, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, myBytes, data)
+
+	// Get and delete the data
+	get := storage.GetOperation("key")
+	missing := storage.GetOperation("missing")
+	require.NoError(t, client.Batch(ctx, get, missing, storage.DeleteOperation("key")))
+	require.Equal(t, myBytes, get.Value)
+	require.Nil(t, missing.Value)
+
+	data, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Nil(t, data)
+}
+
 func TestTwoClientsWithDifferentNames(t *testing.T) {
 	t.Skip("Requires a Redis cluster to be present at localhost:6379")
 	ctx := context.Background()
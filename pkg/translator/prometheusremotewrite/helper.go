@@ -19,6 +19,7 @@ import (
 	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"go.opentelemetry.io/collector/featuregate"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -523,10 +524,41 @@ func (c *prometheusConverter) addTimeSeriesIfNeeded(lbls []prompb.Label, startTi
 
 // addResourceTargetInfo converts the resource to the target info metric.
 func addResourceTargetInfo(resource pcommon.Resource, settings Settings, timestamp pcommon.Timestamp, converter *prometheusConverter) {
-	if settings.DisableTargetInfo || timestamp == 0 {
+	labels := targetInfoLabels(resource, settings, timestamp)
+	if labels == nil {
+		return
+	}
+
+	sample := &prompb.Sample{
+		Value: float64(1),
+		// convert ns to ms
+		Timestamp: convertTimeStamp(timestamp),
+	}
+	converter.addSample(sample, labels)
+}
+
+// addResourceTargetInfoV2 is the Remote-Write 2.0 counterpart of addResourceTargetInfo.
+func addResourceTargetInfoV2(resource pcommon.Resource, settings Settings, timestamp pcommon.Timestamp, converter *prometheusConverterV2) {
+	labels := targetInfoLabels(resource, settings, timestamp)
+	if labels == nil {
 		return
 	}
 
+	sample := &writev2.Sample{
+		Value: float64(1),
+		// convert ns to ms
+		Timestamp: convertTimeStamp(timestamp),
+	}
+	converter.addSample(sample, labels, writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE})
+}
+
+// targetInfoLabels returns the labels of the target_info series for the resource,
+// or nil if no target_info series should be generated.
+func targetInfoLabels(resource pcommon.Resource, settings Settings, timestamp pcommon.Timestamp) []prompb.Label {
+	if settings.DisableTargetInfo || timestamp == 0 {
+		return nil
+	}
+
 	attributes := resource.Attributes()
 	identifyingAttrs := []string{
 		conventions.AttributeServiceNamespace,
@@ -542,7 +574,7 @@ func addResourceTargetInfo(resource pcommon.Resource, settings Settings, timesta
 	}
 	if nonIdentifyingAttrsCount == 0 {
 		// If we only have job + instance, then target_info isn't useful, so don't add it.
-		return
+		return nil
 	}
 
 	name := prometheustranslator.TargetInfoMetricName
@@ -551,25 +583,14 @@ func addResourceTargetInfo(resource pcommon.Resource, settings Settings, timesta
 	}
 
 	labels := createAttributes(resource, attributes, settings.ExternalLabels, identifyingAttrs, false, model.MetricNameLabel, name)
-	haveIdentifier := false
 	for _, l := range labels {
 		if l.Name == model.JobLabel || l.Name == model.InstanceLabel {
-			haveIdentifier = true
-			break
+			return labels
 		}
 	}
 
-	if !haveIdentifier {
-		// We need at least one identifying label to generate target_info.
-		return
-	}
-
-	sample := &prompb.Sample{
-		Value: float64(1),
-		// convert ns to ms
-		Timestamp: convertTimeStamp(timestamp),
-	}
-	converter.addSample(sample, labels)
+	// We need at least one identifying label to generate target_info.
+	return nil
 }
 
 // convertTimeStamp converts OTLP timestamp in ns to timestamp in ms
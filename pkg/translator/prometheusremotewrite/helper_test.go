@@ -549,39 +549,76 @@ func Test_getPromExemplars(t *testing.T) {
 func Test_getPromExemplarsV2(t *testing.T) {
 	tnow := time.Now()
 	tests := []struct {
-		name      string
-		histogram pmetric.HistogramDataPoint
-		expected  []writev2.Exemplar
+		name           string
+		histogram      pmetric.HistogramDataPoint
+		expected       []writev2.Exemplar
+		expectedLabels [][]string
 	}{
 		{
 			name:      "with_exemplars_double_value",
 			histogram: getHistogramDataPointWithExemplars(t, tnow, floatVal1, traceIDValue1, spanIDValue1, label11, value11),
 			expected: []writev2.Exemplar{
 				{
-					Value:     floatVal1,
-					Timestamp: timestamp.FromTime(tnow),
-					// TODO: after deal with examplar labels on getPromExemplarsV2, add the labels here
-					// LabelsRefs: []uint32{},
+					Value:      floatVal1,
+					Timestamp:  timestamp.FromTime(tnow),
+					LabelsRefs: []uint32{1, 2, 3, 4, 5, 6},
 				},
 			},
+			expectedLabels: [][]string{
+				{prometheustranslator.ExemplarTraceIDKey, traceIDValue1, prometheustranslator.ExemplarSpanIDKey, spanIDValue1, label11, value11},
+			},
 		},
 		{
 			name:      "with_exemplars_int_value",
 			histogram: getHistogramDataPointWithExemplars(t, tnow, intVal2, traceIDValue1, spanIDValue1, label11, value11),
 			expected: []writev2.Exemplar{
 				{
-					Value:     float64(intVal2),
-					Timestamp: timestamp.FromTime(tnow),
-					// TODO: after deal with examplar labels on getPromExemplarsV2, add the labels here
-					// LabelsRefs: []uint32{},
+					Value:      float64(intVal2),
+					Timestamp:  timestamp.FromTime(tnow),
+					LabelsRefs: []uint32{1, 2, 3, 4, 5, 6},
 				},
 			},
+			expectedLabels: [][]string{
+				{prometheustranslator.ExemplarTraceIDKey, traceIDValue1, prometheustranslator.ExemplarSpanIDKey, spanIDValue1, label11, value11},
+			},
+		},
+		{
+			name:      "too_many_runes_with_exemplar_drops_attrs_keeps_exemplar",
+			histogram: getHistogramDataPointWithExemplars(t, tnow, floatVal1, traceIDValue1, spanIDValue1, keyWith64Runes, ""),
+			expected: []writev2.Exemplar{
+				{
+					Value:      floatVal1,
+					Timestamp:  timestamp.FromTime(tnow),
+					LabelsRefs: []uint32{1, 2, 3, 4},
+				},
+			},
+			expectedLabels: [][]string{
+				{prometheustranslator.ExemplarTraceIDKey, traceIDValue1, prometheustranslator.ExemplarSpanIDKey, spanIDValue1},
+			},
+		},
+		{
+			name:           "without_exemplar",
+			histogram:      pmetric.NewHistogramDataPoint(),
+			expected:       []writev2.Exemplar{},
+			expectedLabels: [][]string{},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			requests := getPromExemplarsV2(tt.histogram)
+			symbolTable := writev2.NewSymbolTable()
+			requests := getPromExemplarsV2(tt.histogram, &symbolTable)
 			assert.Exactly(t, tt.expected, requests)
+
+			symbols := symbolTable.Symbols()
+			gotLabels := make([][]string, 0, len(requests))
+			for _, e := range requests {
+				lbls := make([]string, 0, len(e.LabelsRefs))
+				for _, ref := range e.LabelsRefs {
+					lbls = append(lbls, symbols[ref])
+				}
+				gotLabels = append(gotLabels, lbls)
+			}
+			assert.Equal(t, tt.expectedLabels, gotLabels)
 		})
 	}
 }
@@ -0,0 +1,273 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+)
+
+func (c *prometheusConverterV2) addExponentialHistogramDataPoints(dataPoints pmetric.ExponentialHistogramDataPointSlice,
+	resource pcommon.Resource, settings Settings, baseName string, metadata writev2.Metadata,
+) (errs error) {
+	for x := 0; x < dataPoints.Len(); x++ {
+		pt := dataPoints.At(x)
+		lbls := createAttributes(
+			resource,
+			pt.Attributes(),
+			settings.ExternalLabels,
+			nil,
+			true,
+			model.MetricNameLabel,
+			baseName,
+		)
+		histogram, err := exponentialToNativeHistogramV2(pt)
+		if err != nil {
+			// The series isn't created for a data point that can't be converted, and the next ones are converted.
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		ts, _ := c.getOrCreateTimeSeries(lbls, metadata)
+		ts.Histograms = append(ts.Histograms, histogram)
+		if startTimestamp := pt.StartTimestamp(); startTimestamp != 0 {
+			ts.CreatedTimestamp = convertTimeStamp(startTimestamp)
+		}
+
+		exemplars := getPromExemplarsV2[pmetric.ExponentialHistogramDataPoint](pt, &c.symbolTable)
+		ts.Exemplars = append(ts.Exemplars, exemplars...)
+	}
+
+	return errs
+}
+
+// exponentialToNativeHistogramV2 translates OTel Exponential Histogram data point
+// to Prometheus Native Histogram, as sent by Remote-Write 2.0.
+// Scales above the maximum native histogram schema are downscaled by merging buckets.
+func exponentialToNativeHistogramV2(p pmetric.ExponentialHistogramDataPoint) (writev2.Histogram, error) {
+	scale := p.Scale()
+	if scale < -4 {
+		return writev2.Histogram{},
+			fmt.Errorf("cannot convert exponential to native histogram."+
+				" Scale must be >= -4, was %d", scale)
+	}
+
+	var scaleDown int32
+	if scale > 8 {
+		scaleDown = scale - 8
+		scale = 8
+	}
+
+	pSpans, pDeltas := convertBucketsLayout(p.Positive(), scaleDown)
+	nSpans, nDeltas := convertBucketsLayout(p.Negative(), scaleDown)
+
+	zeroThreshold := p.ZeroThreshold()
+	if zeroThreshold == 0 {
+		zeroThreshold = defaultZeroThreshold
+	}
+
+	h := writev2.Histogram{
+		// See exponentialToNativeHistogram for why the reset hint is always UNKNOWN.
+		// The start time is sent as the created timestamp of the series instead.
+		ResetHint: writev2.Histogram_RESET_HINT_UNSPECIFIED,
+		Schema:    scale,
+
+		ZeroCount:     &writev2.Histogram_ZeroCountInt{ZeroCountInt: p.ZeroCount()},
+		ZeroThreshold: zeroThreshold,
+
+		PositiveSpans:  bucketSpansToV2(pSpans),
+		PositiveDeltas: pDeltas,
+		NegativeSpans:  bucketSpansToV2(nSpans),
+		NegativeDeltas: nDeltas,
+
+		Timestamp: convertTimeStamp(p.Timestamp()),
+	}
+
+	if p.Flags().NoRecordedValue() {
+		h.Sum = math.Float64frombits(value.StaleNaN)
+		h.Count = &writev2.Histogram_CountInt{CountInt: value.StaleNaN}
+	} else {
+		if p.HasSum() {
+			h.Sum = p.Sum()
+		}
+		h.Count = &writev2.Histogram_CountInt{CountInt: p.Count()}
+	}
+	return h, nil
+}
+
+func bucketSpansToV2(spans []prompb.BucketSpan) []writev2.BucketSpan {
+	if spans == nil {
+		return nil
+	}
+	out := make([]writev2.BucketSpan, len(spans))
+	for i, s := range spans {
+		out[i] = writev2.BucketSpan{Offset: s.Offset, Length: s.Length}
+	}
+	return out
+}
+
+type bucketBoundsDataV2 struct {
+	ts    *writev2.TimeSeries
+	bound float64
+}
+
+func (c *prometheusConverterV2) addHistogramDataPoints(dataPoints pmetric.HistogramDataPointSlice,
+	resource pcommon.Resource, settings Settings, baseName string, metadata writev2.Metadata,
+) {
+	for x := 0; x < dataPoints.Len(); x++ {
+		pt := dataPoints.At(x)
+		timestamp := convertTimeStamp(pt.Timestamp())
+		baseLabels := createAttributes(resource, pt.Attributes(), settings.ExternalLabels, nil, false)
+		var createdTimestamp int64
+		if startTimestamp := pt.StartTimestamp(); startTimestamp != 0 {
+			createdTimestamp = convertTimeStamp(startTimestamp)
+		}
+
+		// If the sum is unset, it indicates the _sum metric point should be
+		// omitted
+		if pt.HasSum() {
+			// treat sum as a sample in an individual TimeSeries
+			sum := &writev2.Sample{
+				Value:     pt.Sum(),
+				Timestamp: timestamp,
+			}
+			if pt.Flags().NoRecordedValue() {
+				sum.Value = math.Float64frombits(value.StaleNaN)
+			}
+
+			sumlabels := createLabels(baseName+sumStr, baseLabels)
+			c.addSample(sum, sumlabels, metadata).CreatedTimestamp = createdTimestamp
+		}
+
+		// treat count as a sample in an individual TimeSeries
+		count := &writev2.Sample{
+			Value:     float64(pt.Count()),
+			Timestamp: timestamp,
+		}
+		if pt.Flags().NoRecordedValue() {
+			count.Value = math.Float64frombits(value.StaleNaN)
+		}
+
+		countlabels := createLabels(baseName+countStr, baseLabels)
+		c.addSample(count, countlabels, metadata).CreatedTimestamp = createdTimestamp
+
+		// cumulative count for conversion to cumulative histogram
+		var cumulativeCount uint64
+
+		var bucketBounds []bucketBoundsDataV2
+
+		// process each bound, based on histograms proto definition, # of buckets = # of explicit bounds + 1
+		for i := 0; i < pt.ExplicitBounds().Len() && i < pt.BucketCounts().Len(); i++ {
+			bound := pt.ExplicitBounds().At(i)
+			cumulativeCount += pt.BucketCounts().At(i)
+			bucket := &writev2.Sample{
+				Value:     float64(cumulativeCount),
+				Timestamp: timestamp,
+			}
+			if pt.Flags().NoRecordedValue() {
+				bucket.Value = math.Float64frombits(value.StaleNaN)
+			}
+			boundStr := strconv.FormatFloat(bound, 'f', -1, 64)
+			labels := createLabels(baseName+bucketStr, baseLabels, leStr, boundStr)
+			ts := c.addSample(bucket, labels, metadata)
+			ts.CreatedTimestamp = createdTimestamp
+
+			bucketBounds = append(bucketBounds, bucketBoundsDataV2{ts: ts, bound: bound})
+		}
+		// add le=+Inf bucket
+		infBucket := &writev2.Sample{
+			Timestamp: timestamp,
+		}
+		if pt.Flags().NoRecordedValue() {
+			infBucket.Value = math.Float64frombits(value.StaleNaN)
+		} else {
+			infBucket.Value = float64(pt.Count())
+		}
+		infLabels := createLabels(baseName+bucketStr, baseLabels, leStr, pInfStr)
+		ts := c.addSample(infBucket, infLabels, metadata)
+		ts.CreatedTimestamp = createdTimestamp
+
+		bucketBounds = append(bucketBounds, bucketBoundsDataV2{ts: ts, bound: math.Inf(1)})
+		c.addExemplars(pt, bucketBounds)
+	}
+}
+
+// addExemplars adds exemplars for the dataPoint. For each exemplar, the exemplar is added to the
+// time series of the first bucket bound greater or equal to its value.
+func (c *prometheusConverterV2) addExemplars(dataPoint pmetric.HistogramDataPoint, bucketBounds []bucketBoundsDataV2) {
+	if len(bucketBounds) == 0 {
+		return
+	}
+
+	exemplars := getPromExemplarsV2(dataPoint, &c.symbolTable)
+	for _, exemplar := range exemplars {
+		// bucketBounds are already sorted, as explicit bounds are required to be increasing.
+		for _, bound := range bucketBounds {
+			if len(bound.ts.Samples) > 0 && exemplar.Value <= bound.bound {
+				bound.ts.Exemplars = append(bound.ts.Exemplars, exemplar)
+				break
+			}
+		}
+	}
+}
+
+func (c *prometheusConverterV2) addSummaryDataPoints(dataPoints pmetric.SummaryDataPointSlice, resource pcommon.Resource,
+	settings Settings, baseName string, metadata writev2.Metadata,
+) {
+	for x := 0; x < dataPoints.Len(); x++ {
+		pt := dataPoints.At(x)
+		timestamp := convertTimeStamp(pt.Timestamp())
+		baseLabels := createAttributes(resource, pt.Attributes(), settings.ExternalLabels, nil, false)
+		var createdTimestamp int64
+		if startTimestamp := pt.StartTimestamp(); startTimestamp != 0 {
+			createdTimestamp = convertTimeStamp(startTimestamp)
+		}
+
+		// treat sum as a sample in an individual TimeSeries
+		sum := &writev2.Sample{
+			Value:     pt.Sum(),
+			Timestamp: timestamp,
+		}
+		if pt.Flags().NoRecordedValue() {
+			sum.Value = math.Float64frombits(value.StaleNaN)
+		}
+		// sum and count of the summary should append suffix to baseName
+		sumlabels := createLabels(baseName+sumStr, baseLabels)
+		c.addSample(sum, sumlabels, metadata).CreatedTimestamp = createdTimestamp
+
+		// treat count as a sample in an individual TimeSeries
+		count := &writev2.Sample{
+			Value:     float64(pt.Count()),
+			Timestamp: timestamp,
+		}
+		if pt.Flags().NoRecordedValue() {
+			count.Value = math.Float64frombits(value.StaleNaN)
+		}
+		countlabels := createLabels(baseName+countStr, baseLabels)
+		c.addSample(count, countlabels, metadata).CreatedTimestamp = createdTimestamp
+
+		// process each percentile/quantile
+		for i := 0; i < pt.QuantileValues().Len(); i++ {
+			qt := pt.QuantileValues().At(i)
+			quantile := &writev2.Sample{
+				Value:     qt.Value(),
+				Timestamp: timestamp,
+			}
+			if pt.Flags().NoRecordedValue() {
+				quantile.Value = math.Float64frombits(value.StaleNaN)
+			}
+			percentileStr := strconv.FormatFloat(qt.Quantile(), 'f', -1, 64)
+			qtlabels := createLabels(baseName, baseLabels, quantileStr, percentileStr)
+			c.addSample(quantile, qtlabels, metadata).CreatedTimestamp = createdTimestamp
+		}
+	}
+}
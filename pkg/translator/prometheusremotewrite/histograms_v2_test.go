@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewrite
+
+import (
+	"testing"
+
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestExponentialToNativeHistogramV2(t *testing.T) {
+	tests := []struct {
+		name    string
+		pt      func() pmetric.ExponentialHistogramDataPoint
+		want    writev2.Histogram
+		wantErr string
+	}{
+		{
+			name: "convert exp. to native histogram",
+			pt: func() pmetric.ExponentialHistogramDataPoint {
+				pt := pmetric.NewExponentialHistogramDataPoint()
+				pt.SetTimestamp(500000000)
+				pt.SetCount(4)
+				pt.SetSum(10.1)
+				pt.SetScale(1)
+				pt.SetZeroCount(1)
+				pt.SetZeroThreshold(0.5)
+				pt.Positive().BucketCounts().FromRaw([]uint64{1, 1})
+				pt.Positive().SetOffset(1)
+				pt.Negative().BucketCounts().FromRaw([]uint64{1})
+				return pt
+			},
+			want: writev2.Histogram{
+				Count:          &writev2.Histogram_CountInt{CountInt: 4},
+				Sum:            10.1,
+				Schema:         1,
+				ZeroThreshold:  0.5,
+				ZeroCount:      &writev2.Histogram_ZeroCountInt{ZeroCountInt: 1},
+				NegativeSpans:  []writev2.BucketSpan{{Offset: 1, Length: 1}},
+				NegativeDeltas: []int64{1},
+				PositiveSpans:  []writev2.BucketSpan{{Offset: 2, Length: 2}},
+				PositiveDeltas: []int64{1, 0},
+				Timestamp:      500,
+			},
+		},
+		{
+			name: "convert scale above 8 by downscaling",
+			pt: func() pmetric.ExponentialHistogramDataPoint {
+				pt := pmetric.NewExponentialHistogramDataPoint()
+				pt.SetTimestamp(500000000)
+				pt.SetCount(4)
+				pt.SetScale(9)
+				pt.Positive().BucketCounts().FromRaw([]uint64{1, 1, 1, 1})
+				return pt
+			},
+			want: writev2.Histogram{
+				Count:          &writev2.Histogram_CountInt{CountInt: 4},
+				Schema:         8,
+				ZeroThreshold:  defaultZeroThreshold,
+				ZeroCount:      &writev2.Histogram_ZeroCountInt{ZeroCountInt: 0},
+				PositiveSpans:  []writev2.BucketSpan{{Offset: 1, Length: 2}},
+				PositiveDeltas: []int64{2, 0},
+				Timestamp:      500,
+			},
+		},
+		{
+			name: "invalid scale",
+			pt: func() pmetric.ExponentialHistogramDataPoint {
+				pt := pmetric.NewExponentialHistogramDataPoint()
+				pt.SetScale(-10)
+				return pt
+			},
+			wantErr: "cannot convert exponential to native histogram." +
+				" Scale must be >= -4, was -10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exponentialToNativeHistogramV2(tt.pt())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -18,9 +18,11 @@ import (
 )
 
 type Settings struct {
-	Namespace           string
-	ExternalLabels      map[string]string
-	DisableTargetInfo   bool
+	Namespace         string
+	ExternalLabels    map[string]string
+	DisableTargetInfo bool
+	// ExportCreatedMetric adds _created series to the Remote-Write 1.0 time series. It isn't supported by
+	// FromMetricsV2, since Remote-Write 2.0 sends the start time as the created timestamp of the series.
 	ExportCreatedMetric bool
 	AddMetricSuffixes   bool
 	SendMetadata        bool
@@ -6,7 +6,7 @@ package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry
 import (
 	"errors"
 	"fmt"
-	"sort"
+	"slices"
 	"strconv"
 
 	"github.com/prometheus/prometheus/prompb"
@@ -18,8 +18,14 @@ import (
 	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
 )
 
+var errExportCreatedMetricV2 = errors.New("exporting _created series isn't supported by Remote-Write 2.0, the start time is sent as the created timestamp of the series")
+
 // FromMetricsV2 converts pmetric.Metrics to Prometheus remote write format 2.0.
+// Settings.ExportCreatedMetric isn't supported, and returns an error.
 func FromMetricsV2(md pmetric.Metrics, settings Settings) (map[string]*writev2.TimeSeries, writev2.SymbolsTable, error) {
+	if settings.ExportCreatedMetric {
+		return nil, writev2.SymbolsTable{}, errExportCreatedMetricV2
+	}
 	c := newPrometheusConverterV2()
 	errs := c.fromMetrics(md, settings)
 	tss := c.timeSeries()
@@ -33,14 +39,15 @@ func FromMetricsV2(md pmetric.Metrics, settings Settings) (map[string]*writev2.T
 
 // prometheusConverterV2 converts from OTLP to Prometheus write 2.0 format.
 type prometheusConverterV2 struct {
-	// TODO handle conflicts
 	unique      map[uint64]*writev2.TimeSeries
+	conflicts   map[uint64][]*writev2.TimeSeries
 	symbolTable writev2.SymbolsTable
 }
 
 func newPrometheusConverterV2() *prometheusConverterV2 {
 	return &prometheusConverterV2{
 		unique:      map[uint64]*writev2.TimeSeries{},
+		conflicts:   map[uint64][]*writev2.TimeSeries{},
 		symbolTable: writev2.NewSymbolTable(),
 	}
 }
@@ -69,6 +76,7 @@ func (c *prometheusConverterV2) fromMetrics(md pmetric.Metrics, settings Setting
 				}
 
 				promName := prometheustranslator.BuildCompliantName(metric, settings.Namespace, settings.AddMetricSuffixes)
+				metadata := c.metadata(metric)
 
 				// handle individual metrics based on type
 				//exhaustive:enforce
@@ -76,68 +84,145 @@ func (c *prometheusConverterV2) fromMetrics(md pmetric.Metrics, settings Setting
 				case pmetric.MetricTypeGauge:
 					dataPoints := metric.Gauge().DataPoints()
 					if dataPoints.Len() == 0 {
+						errs = multierr.Append(errs, fmt.Errorf("empty data points. %s is dropped", metric.Name()))
 						break
 					}
-					c.addGaugeNumberDataPoints(dataPoints, resource, settings, promName)
+					c.addGaugeNumberDataPoints(dataPoints, resource, settings, promName, metadata)
 				case pmetric.MetricTypeSum:
 					dataPoints := metric.Sum().DataPoints()
 					if dataPoints.Len() == 0 {
+						errs = multierr.Append(errs, fmt.Errorf("empty data points. %s is dropped", metric.Name()))
 						break
 					}
 					if !metric.Sum().IsMonotonic() {
-						c.addGaugeNumberDataPoints(dataPoints, resource, settings, promName)
+						c.addGaugeNumberDataPoints(dataPoints, resource, settings, promName, metadata)
 					} else {
-						c.addSumNumberDataPoints(dataPoints, resource, metric, settings, promName)
+						c.addSumNumberDataPoints(dataPoints, resource, settings, promName, metadata)
 					}
 				case pmetric.MetricTypeHistogram:
-					// TODO implement
+					dataPoints := metric.Histogram().DataPoints()
+					if dataPoints.Len() == 0 {
+						errs = multierr.Append(errs, fmt.Errorf("empty data points. %s is dropped", metric.Name()))
+						break
+					}
+					c.addHistogramDataPoints(dataPoints, resource, settings, promName, metadata)
 				case pmetric.MetricTypeExponentialHistogram:
-					// TODO implement
+					dataPoints := metric.ExponentialHistogram().DataPoints()
+					if dataPoints.Len() == 0 {
+						errs = multierr.Append(errs, fmt.Errorf("empty data points. %s is dropped", metric.Name()))
+						break
+					}
+					errs = multierr.Append(errs, c.addExponentialHistogramDataPoints(
+						dataPoints,
+						resource,
+						settings,
+						promName,
+						metadata,
+					))
 				case pmetric.MetricTypeSummary:
-					// TODO implement
+					dataPoints := metric.Summary().DataPoints()
+					if dataPoints.Len() == 0 {
+						errs = multierr.Append(errs, fmt.Errorf("empty data points. %s is dropped", metric.Name()))
+						break
+					}
+					c.addSummaryDataPoints(dataPoints, resource, settings, promName, metadata)
 				default:
 					errs = multierr.Append(errs, errors.New("unsupported metric type"))
 				}
 			}
 		}
-		// TODO implement
-		// addResourceTargetInfov2(resource, settings, mostRecentTimestamp, c)
+		addResourceTargetInfoV2(resource, settings, mostRecentTimestamp, c)
 	}
 
 	return
 }
 
+// metadata returns the Remote-Write 2.0 metadata of the metric, interning its help and unit in the symbols table.
+func (c *prometheusConverterV2) metadata(metric pmetric.Metric) writev2.Metadata {
+	return writev2.Metadata{
+		Type:    otelMetricTypeToPromMetricTypeV2(metric),
+		HelpRef: c.symbolTable.Symbolize(metric.Description()),
+		UnitRef: c.symbolTable.Symbolize(metric.Unit()),
+	}
+}
+
 // timeSeries returns a slice of the writev2.TimeSeries that were converted from OTel format.
 func (c *prometheusConverterV2) timeSeries() []writev2.TimeSeries {
-	allTS := make([]writev2.TimeSeries, 0, len(c.unique))
+	conflicts := 0
+	for _, ts := range c.conflicts {
+		conflicts += len(ts)
+	}
+	allTS := make([]writev2.TimeSeries, 0, len(c.unique)+conflicts)
 	for _, ts := range c.unique {
 		allTS = append(allTS, *ts)
 	}
+	for _, cTS := range c.conflicts {
+		for _, ts := range cTS {
+			allTS = append(allTS, *ts)
+		}
+	}
 	return allTS
 }
 
-func (c *prometheusConverterV2) addSample(sample *writev2.Sample, lbls []prompb.Label) {
-	buf := make([]uint32, 0, len(lbls)*2)
+// addSample finds a TimeSeries that corresponds to lbls, and adds sample to it.
+// If there is no corresponding TimeSeries already, it's created with the given metadata.
+// The corresponding TimeSeries is returned.
+// If either lbls is nil/empty or sample is nil, nothing is done.
+func (c *prometheusConverterV2) addSample(sample *writev2.Sample, lbls []prompb.Label, metadata writev2.Metadata) *writev2.TimeSeries {
+	if sample == nil || len(lbls) == 0 {
+		// This shouldn't happen
+		return nil
+	}
 
-	// TODO: Read the PRW spec to see if labels need to be sorted. If it is, then we need to sort in export code. If not, we can sort in the test. (@dashpole have more context on this)
-	sort.Slice(lbls, func(i, j int) bool {
-		return lbls[i].Name < lbls[j].Name
-	})
+	ts, _ := c.getOrCreateTimeSeries(lbls, metadata)
+	ts.Samples = append(ts.Samples, *sample)
+	return ts
+}
 
-	var off uint32
-	for _, l := range lbls {
-		off = c.symbolTable.Symbolize(l.Name)
-		buf = append(buf, off)
-		off = c.symbolTable.Symbolize(l.Value)
-		buf = append(buf, off)
+// getOrCreateTimeSeries returns the time series corresponding to the label set if existent, and false.
+// Otherwise it creates a new one with the given metadata and returns that, and true.
+func (c *prometheusConverterV2) getOrCreateTimeSeries(lbls []prompb.Label, metadata writev2.Metadata) (*writev2.TimeSeries, bool) {
+	// timeSeriesSignature sorts the labels, so the references are symbolized in label name order.
+	h := timeSeriesSignature(lbls)
+	refs := c.symbolizeLabels(lbls)
+
+	ts := c.unique[h]
+	if ts != nil {
+		if slices.Equal(ts.LabelsRefs, refs) {
+			// We already have this metric
+			return ts, false
+		}
+
+		// Look for a matching conflict
+		for _, cTS := range c.conflicts[h] {
+			if slices.Equal(cTS.LabelsRefs, refs) {
+				// We already have this metric
+				return cTS, false
+			}
+		}
+
+		// New conflict
+		ts = &writev2.TimeSeries{
+			LabelsRefs: refs,
+			Metadata:   metadata,
+		}
+		c.conflicts[h] = append(c.conflicts[h], ts)
+		return ts, true
 	}
-	ts := writev2.TimeSeries{
-		LabelsRefs: buf,
-		Samples:    []writev2.Sample{*sample},
+
+	// This metric is new
+	ts = &writev2.TimeSeries{
+		LabelsRefs: refs,
+		Metadata:   metadata,
 	}
-	c.unique[timeSeriesSignature(lbls)] = &ts
+	c.unique[h] = ts
+	return ts, true
 }
 
-// TODO: implement this function.
-func (c *prometheusConverterV2) addTimeSeriesIfNeeded(_ []prompb.Label, _ pcommon.Timestamp, _ pcommon.Timestamp) {
+func (c *prometheusConverterV2) symbolizeLabels(lbls []prompb.Label) []uint32 {
+	refs := make([]uint32, 0, len(lbls)*2)
+	for _, l := range lbls {
+		refs = append(refs, c.symbolTable.Symbolize(l.Name), c.symbolTable.Symbolize(l.Value))
+	}
+	return refs
 }
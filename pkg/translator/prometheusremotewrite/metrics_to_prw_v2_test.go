@@ -7,9 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
 func TestFromMetricsV2(t *testing.T) {
@@ -24,16 +26,18 @@ func TestFromMetricsV2(t *testing.T) {
 
 	ts := uint64(time.Now().UnixNano())
 	payload := createExportRequest(5, 0, 1, 3, 0, pcommon.Timestamp(ts))
-	want := func() map[string]*writev2.TimeSeries {
-		return map[string]*writev2.TimeSeries{
-			"0": {
+	want := func() []*writev2.TimeSeries {
+		return []*writev2.TimeSeries{
+			{
 				LabelsRefs: []uint32{1, 2, 3, 4, 5, 6, 7, 8},
+				Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
 				Samples: []writev2.Sample{
 					{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: 1.23},
 				},
 			},
-			"1": {
+			{
 				LabelsRefs: []uint32{1, 9, 3, 4, 5, 6, 7, 8},
+				Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
 				Samples: []writev2.Sample{
 					{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: 1.23},
 				},
@@ -43,6 +47,132 @@ func TestFromMetricsV2(t *testing.T) {
 	wantedSymbols := []string{"", "series_name_2", "value-2", "series_name_3", "value-3", "__name__", "gauge_1", "series_name_1", "value-1", "sum_1"}
 	tsMap, symbolsTable, err := FromMetricsV2(payload.Metrics(), settings)
 	require.NoError(t, err)
-	require.Equal(t, want(), tsMap)
+	got := make([]*writev2.TimeSeries, 0, len(tsMap))
+	for _, ts := range tsMap {
+		got = append(got, ts)
+	}
+	require.ElementsMatch(t, want(), got)
 	require.ElementsMatch(t, wantedSymbols, symbolsTable.Symbols())
 }
+
+func TestFromMetricsV2Histograms(t *testing.T) {
+	settings := Settings{
+		Namespace:         "",
+		DisableTargetInfo: true,
+		AddMetricSuffixes: false,
+	}
+
+	ts := pcommon.Timestamp(time.Now().UnixNano())
+	start := ts - pcommon.Timestamp(time.Minute)
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+
+	m := sm.Metrics().AppendEmpty()
+	m.SetName("exp_hist")
+	m.SetDescription("an exponential histogram")
+	m.SetUnit("s")
+	m.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	pt := m.ExponentialHistogram().DataPoints().AppendEmpty()
+	pt.SetStartTimestamp(start)
+	pt.SetTimestamp(ts)
+	pt.SetScale(1)
+	pt.SetCount(5)
+	pt.SetSum(12)
+	pt.SetZeroCount(1)
+	pt.Positive().BucketCounts().FromRaw([]uint64{2, 2})
+
+	m = sm.Metrics().AppendEmpty()
+	m.SetName("hist")
+	m.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	hpt := m.Histogram().DataPoints().AppendEmpty()
+	hpt.SetStartTimestamp(start)
+	hpt.SetTimestamp(ts)
+	hpt.SetCount(3)
+	hpt.SetSum(6)
+	hpt.BucketCounts().FromRaw([]uint64{1, 2})
+	hpt.ExplicitBounds().FromRaw([]float64{2})
+
+	tsMap, symbolsTable, err := FromMetricsV2(md, settings)
+	require.NoError(t, err)
+
+	symbols := symbolsTable.Symbols()
+	byName := map[string][]*writev2.TimeSeries{}
+	for _, series := range tsMap {
+		for i := 0; i < len(series.LabelsRefs); i += 2 {
+			if symbols[series.LabelsRefs[i]] == "__name__" {
+				name := symbols[series.LabelsRefs[i+1]]
+				byName[name] = append(byName[name], series)
+			}
+		}
+	}
+
+	require.Len(t, byName["exp_hist"], 1)
+	expHist := byName["exp_hist"][0]
+	require.Equal(t, writev2.Metadata_METRIC_TYPE_HISTOGRAM, expHist.Metadata.Type)
+	require.Equal(t, "an exponential histogram", symbols[expHist.Metadata.HelpRef])
+	require.Equal(t, "s", symbols[expHist.Metadata.UnitRef])
+	require.Equal(t, convertTimeStamp(start), expHist.CreatedTimestamp)
+	require.Empty(t, expHist.Samples)
+	require.Len(t, expHist.Histograms, 1)
+	require.Equal(t, int32(1), expHist.Histograms[0].Schema)
+	require.Equal(t, []writev2.BucketSpan{{Offset: 1, Length: 2}}, expHist.Histograms[0].PositiveSpans)
+	require.Equal(t, []int64{2, 0}, expHist.Histograms[0].PositiveDeltas)
+
+	require.Len(t, byName["hist_bucket"], 2)
+	require.Len(t, byName["hist_sum"], 1)
+	require.Len(t, byName["hist_count"], 1)
+	for _, series := range append(byName["hist_bucket"], byName["hist_sum"][0], byName["hist_count"][0]) {
+		require.Equal(t, writev2.Metadata_METRIC_TYPE_HISTOGRAM, series.Metadata.Type)
+		require.Equal(t, convertTimeStamp(start), series.CreatedTimestamp)
+	}
+}
+
+func TestFromMetricsV2InvalidExponentialHistogram(t *testing.T) {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("exp_hist")
+	m.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	invalid := m.ExponentialHistogram().DataPoints().AppendEmpty()
+	invalid.Attributes().PutStr("point", "invalid")
+	invalid.SetScale(-5)
+	valid := m.ExponentialHistogram().DataPoints().AppendEmpty()
+	valid.Attributes().PutStr("point", "valid")
+	valid.SetScale(1)
+	valid.SetCount(1)
+	valid.Positive().BucketCounts().FromRaw([]uint64{1})
+
+	tsMap, symbolsTable, err := FromMetricsV2(md, Settings{DisableTargetInfo: true})
+	require.ErrorContains(t, err, "Scale must be >= -4, was -5")
+
+	// Only the series of the valid data point is created.
+	require.Len(t, tsMap, 1)
+	for _, series := range tsMap {
+		require.Equal(t, "valid", series.ToLabels(&labels.ScratchBuilder{}, symbolsTable.Symbols()).Get("point"))
+		require.Len(t, series.Histograms, 1)
+	}
+}
+
+func TestFromMetricsV2GaugeExemplars(t *testing.T) {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("gauge")
+	pt := m.SetEmptyGauge().DataPoints().AppendEmpty()
+	pt.SetDoubleValue(1)
+	exemplar := pt.Exemplars().AppendEmpty()
+	exemplar.SetDoubleValue(2)
+	exemplar.SetTraceID([16]byte{1})
+
+	tsMap, symbolsTable, err := FromMetricsV2(md, Settings{DisableTargetInfo: true})
+	require.NoError(t, err)
+	require.Len(t, tsMap, 1)
+	for _, series := range tsMap {
+		require.Len(t, series.Exemplars, 1)
+		require.Equal(t, 2.0, series.Exemplars[0].Value)
+		require.Equal(t, "01000000000000000000000000000000", series.Exemplars[0].ToExemplar(&labels.ScratchBuilder{}, symbolsTable.Symbols()).Labels.Get("trace_id"))
+	}
+}
+
+func TestFromMetricsV2ExportCreatedMetric(t *testing.T) {
+	_, _, err := FromMetricsV2(pmetric.NewMetrics(), Settings{ExportCreatedMetric: true})
+	require.ErrorIs(t, err, errExportCreatedMetricV2)
+}
@@ -4,19 +4,22 @@
 package prometheusremotewrite // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
 
 import (
+	"encoding/hex"
 	"math"
+	"unicode/utf8"
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/prometheus/prometheus/model/value"
-	"github.com/prometheus/prometheus/prompb"
 	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	prometheustranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
 )
 
 func (c *prometheusConverterV2) addGaugeNumberDataPoints(dataPoints pmetric.NumberDataPointSlice,
-	resource pcommon.Resource, settings Settings, name string,
+	resource pcommon.Resource, settings Settings, name string, metadata writev2.Metadata,
 ) {
 	for x := 0; x < dataPoints.Len(); x++ {
 		pt := dataPoints.At(x)
@@ -44,12 +47,15 @@ func (c *prometheusConverterV2) addGaugeNumberDataPoints(dataPoints pmetric.Numb
 		if pt.Flags().NoRecordedValue() {
 			sample.Value = math.Float64frombits(value.StaleNaN)
 		}
-		c.addSample(sample, labels)
+		ts := c.addSample(sample, labels, metadata)
+		if ts != nil {
+			ts.Exemplars = append(ts.Exemplars, getPromExemplarsV2(pt, &c.symbolTable)...)
+		}
 	}
 }
 
 func (c *prometheusConverterV2) addSumNumberDataPoints(dataPoints pmetric.NumberDataPointSlice,
-	resource pcommon.Resource, metric pmetric.Metric, settings Settings, name string,
+	resource pcommon.Resource, settings Settings, name string, metadata writev2.Metadata,
 ) {
 	for x := 0; x < dataPoints.Len(); x++ {
 		pt := dataPoints.At(x)
@@ -76,34 +82,25 @@ func (c *prometheusConverterV2) addSumNumberDataPoints(dataPoints pmetric.Number
 		if pt.Flags().NoRecordedValue() {
 			sample.Value = math.Float64frombits(value.StaleNaN)
 		}
-		// TODO: properly add exemplars to the TimeSeries
-		c.addSample(sample, lbls)
-
-		if settings.ExportCreatedMetric && metric.Sum().IsMonotonic() {
-			startTimestamp := pt.StartTimestamp()
-			if startTimestamp != 0 {
-				return
-			}
-
-			createdLabels := make([]prompb.Label, len(lbls))
-			copy(createdLabels, lbls)
-			for i, l := range createdLabels {
-				if l.Name == model.MetricNameLabel {
-					createdLabels[i].Value = name + createdSuffix
-					break
-				}
-			}
-			// TODO: implement this function.
-			c.addTimeSeriesIfNeeded(createdLabels, startTimestamp, pt.Timestamp())
+		ts := c.addSample(sample, lbls, metadata)
+		if ts == nil {
+			continue
+		}
+		ts.Exemplars = append(ts.Exemplars, getPromExemplarsV2(pt, &c.symbolTable)...)
+		// Remote-Write 2.0 carries the start time natively, replacing the _created series.
+		if startTimestamp := pt.StartTimestamp(); startTimestamp != 0 {
+			ts.CreatedTimestamp = convertTimeStamp(startTimestamp)
 		}
 	}
 }
 
 // getPromExemplarsV2 returns a slice of writev2.Exemplar from pdata exemplars.
-func getPromExemplarsV2[T exemplarType](pt T) []writev2.Exemplar {
+// The exemplar labels are interned in the given symbols table.
+func getPromExemplarsV2[T exemplarType](pt T, symbolTable *writev2.SymbolsTable) []writev2.Exemplar {
 	promExemplars := make([]writev2.Exemplar, 0, pt.Exemplars().Len())
 	for i := 0; i < pt.Exemplars().Len(); i++ {
 		exemplar := pt.Exemplars().At(i)
+		exemplarRunes := 0
 
 		var promExemplar writev2.Exemplar
 
@@ -119,7 +116,34 @@ func getPromExemplarsV2[T exemplarType](pt T) []writev2.Exemplar {
 				Timestamp: timestamp.FromTime(exemplar.Timestamp().AsTime()),
 			}
 		}
-		// TODO append labels to promExemplar.Labels
+		if traceID := exemplar.TraceID(); !traceID.IsEmpty() {
+			val := hex.EncodeToString(traceID[:])
+			exemplarRunes += utf8.RuneCountInString(prometheustranslator.ExemplarTraceIDKey) + utf8.RuneCountInString(val)
+			promExemplar.LabelsRefs = append(promExemplar.LabelsRefs,
+				symbolTable.Symbolize(prometheustranslator.ExemplarTraceIDKey), symbolTable.Symbolize(val))
+		}
+		if spanID := exemplar.SpanID(); !spanID.IsEmpty() {
+			val := hex.EncodeToString(spanID[:])
+			exemplarRunes += utf8.RuneCountInString(prometheustranslator.ExemplarSpanIDKey) + utf8.RuneCountInString(val)
+			promExemplar.LabelsRefs = append(promExemplar.LabelsRefs,
+				symbolTable.Symbolize(prometheustranslator.ExemplarSpanIDKey), symbolTable.Symbolize(val))
+		}
+
+		attrs := exemplar.FilteredAttributes()
+		labelsFromAttributes := make([]string, 0, attrs.Len()*2)
+		attrs.Range(func(key string, value pcommon.Value) bool {
+			val := value.AsString()
+			exemplarRunes += utf8.RuneCountInString(key) + utf8.RuneCountInString(val)
+			labelsFromAttributes = append(labelsFromAttributes, key, val)
+			return true
+		})
+		if exemplarRunes <= maxExemplarRunes {
+			// only append filtered attributes if it does not cause exemplar
+			// labels to exceed the max number of runes
+			for _, s := range labelsFromAttributes {
+				promExemplar.LabelsRefs = append(promExemplar.LabelsRefs, symbolTable.Symbolize(s))
+			}
+		}
 
 		promExemplars = append(promExemplars, promExemplar)
 	}
@@ -44,6 +44,7 @@ func TestPrometheusConverterV2_addGaugeNumberDataPoints(t *testing.T) {
 				return map[uint64]*writev2.TimeSeries{
 					labels.Hash(): {
 						LabelsRefs: []uint32{1, 2},
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
 						Samples: []writev2.Sample{
 							{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: 1},
 						},
@@ -70,6 +71,7 @@ func TestPrometheusConverterV2_addGaugeNumberDataPoints(t *testing.T) {
 				return map[uint64]*writev2.TimeSeries{
 					labels.Hash(): {
 						LabelsRefs: []uint32{1, 2},
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
 						Samples: []writev2.Sample{
 							{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: 1.5},
 						},
@@ -96,6 +98,7 @@ func TestPrometheusConverterV2_addGaugeNumberDataPoints(t *testing.T) {
 				return map[uint64]*writev2.TimeSeries{
 					labels.Hash(): {
 						LabelsRefs: []uint32{1, 2},
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
 						Samples: []writev2.Sample{
 							{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: math.Float64frombits(value.StaleNaN)},
 						},
@@ -117,7 +120,7 @@ func TestPrometheusConverterV2_addGaugeNumberDataPoints(t *testing.T) {
 				SendMetadata:        false,
 			}
 			converter := newPrometheusConverterV2()
-			converter.addGaugeNumberDataPoints(metric.Gauge().DataPoints(), pcommon.NewResource(), settings, metric.Name())
+			converter.addGaugeNumberDataPoints(metric.Gauge().DataPoints(), pcommon.NewResource(), settings, metric.Name(), writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE})
 			w := tt.want()
 
 			diff := cmp.Diff(w, converter.unique, cmpopts.EquateNaNs())
@@ -126,8 +129,7 @@ func TestPrometheusConverterV2_addGaugeNumberDataPoints(t *testing.T) {
 	}
 }
 
-// Right now we are not handling duplicates, the second one will just overwrite the first one as this test case shows
-// In follow-up PRs we plan to start handling conflicts and this test will be updated to reflect the new behavior.
+// Data points of the same series are appended to the existing time series.
 func TestPrometheusConverterV2_addGaugeNumberDataPointsDuplicate(t *testing.T) {
 	ts := uint64(time.Now().UnixNano())
 	metric1 := getIntGaugeMetric(
@@ -150,7 +152,9 @@ func TestPrometheusConverterV2_addGaugeNumberDataPointsDuplicate(t *testing.T) {
 		return map[uint64]*writev2.TimeSeries{
 			labels.Hash(): {
 				LabelsRefs: []uint32{1, 2},
+				Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
 				Samples: []writev2.Sample{
+					{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: 1},
 					{Timestamp: convertTimeStamp(pcommon.Timestamp(ts)), Value: 2},
 				},
 			},
@@ -167,8 +171,8 @@ func TestPrometheusConverterV2_addGaugeNumberDataPointsDuplicate(t *testing.T) {
 	}
 
 	converter := newPrometheusConverterV2()
-	converter.addGaugeNumberDataPoints(metric1.Gauge().DataPoints(), pcommon.NewResource(), settings, metric1.Name())
-	converter.addGaugeNumberDataPoints(metric2.Gauge().DataPoints(), pcommon.NewResource(), settings, metric2.Name())
+	converter.addGaugeNumberDataPoints(metric1.Gauge().DataPoints(), pcommon.NewResource(), settings, metric1.Name(), writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE})
+	converter.addGaugeNumberDataPoints(metric2.Gauge().DataPoints(), pcommon.NewResource(), settings, metric2.Name(), writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE})
 
 	assert.Equal(t, want(), converter.unique)
 }
@@ -4,7 +4,10 @@
 package groupbytraceprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/groupbytraceprocessor"
 
 import (
+	"errors"
 	"time"
+
+	"go.opentelemetry.io/collector/component"
 )
 
 // Config is the configuration for the processor.
@@ -29,7 +32,17 @@ type Config struct {
 
 	// StoreOnDisk tells the processor to keep only the trace ID in memory, serializing the trace spans to disk.
 	// Useful when the duration to wait for traces to complete is high.
+	// The traces are persisted by the storage extension set in StorageID, and survive restarts of the collector.
 	// Default: false.
-	// Not yet implemented, and an error will be returned when this option is used.
 	StoreOnDisk bool `mapstructure:"store_on_disk"`
+
+	// StorageID is the ID of the storage extension used to persist the traces when StoreOnDisk is set.
+	StorageID *component.ID `mapstructure:"storage"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.StoreOnDisk && cfg.StorageID == nil {
+		return errors.New("storage must be set when store_on_disk is enabled")
+	}
+	return nil
 }
@@ -32,6 +32,9 @@ const (
 
 	// traceID to be removed
 	traceRemoved
+
+	// traceID found in the storage on start
+	traceRecovered
 )
 
 var (
@@ -72,12 +75,13 @@ type eventMachine struct {
 	metricsCollectionInterval time.Duration
 	shutdownTimeout           time.Duration
 
-	logger          *zap.Logger
-	telemetry       *metadata.TelemetryBuilder
-	onTraceReceived func(td tracesWithID, worker *eventMachineWorker) error
-	onTraceExpired  func(traceID pcommon.TraceID, worker *eventMachineWorker) error
-	onTraceReleased func(rss []ptrace.ResourceSpans) error
-	onTraceRemoved  func(traceID pcommon.TraceID) error
+	logger           *zap.Logger
+	telemetry        *metadata.TelemetryBuilder
+	onTraceReceived  func(td tracesWithID, worker *eventMachineWorker) error
+	onTraceExpired   func(traceID pcommon.TraceID, worker *eventMachineWorker) error
+	onTraceReleased  func(rss []ptrace.ResourceSpans) error
+	onTraceRemoved   func(traceID pcommon.TraceID) error
+	onTraceRecovered func(traceID pcommon.TraceID, worker *eventMachineWorker) error
 
 	onError func(event)
 
@@ -208,6 +212,22 @@ func (em *eventMachine) handleEvent(e event, w *eventMachineWorker) {
 		em.handleEventWithObservability("onTraceRemoved", func() error {
 			return em.onTraceRemoved(payload)
 		})
+	case traceRecovered:
+		if em.onTraceRecovered == nil {
+			em.logger.Debug("onTraceRecovered not set, skipping event")
+			em.callOnError(e)
+			return
+		}
+		payload, ok := e.payload.(pcommon.TraceID)
+		if !ok {
+			// the payload had an unexpected type!
+			em.callOnError(e)
+			return
+		}
+
+		em.handleEventWithObservability("onTraceRecovered", func() error {
+			return em.onTraceRecovered(payload, w)
+		})
 	default:
 		em.logger.Info("unknown event type", zap.Any("event", e.typ))
 		em.callOnError(e)
@@ -236,6 +256,19 @@ func (em *eventMachine) consume(td ptrace.Traces) error {
 	return nil
 }
 
+// recover routes the ID of a trace found in the storage to the worker responsible for it.
+func (em *eventMachine) recover(traceID pcommon.TraceID) {
+	var bucket uint64
+	if len(em.workers) != 1 {
+		bucket = workerIndexForTraceID(traceID, len(em.workers))
+	}
+
+	em.workers[bucket].fire(event{
+		typ:     traceRecovered,
+		payload: traceID,
+	})
+}
+
 func workerIndexForTraceID(traceID pcommon.TraceID, numWorkers int) uint64 {
 	hash := hashPool.Get().(*maphash.Hash)
 	defer func() {
@@ -23,10 +23,7 @@ const (
 	defaultStoreOnDisk    = false
 )
 
-var (
-	errDiskStorageNotSupported    = fmt.Errorf("option 'disk storage' not supported in this release")
-	errDiscardOrphansNotSupported = fmt.Errorf("option 'discard orphans' not supported in this release")
-)
+var errDiscardOrphansNotSupported = fmt.Errorf("option 'discard orphans' not supported in this release")
 
 // NewFactory returns a new factory for the Filter processor.
 func NewFactory() processor.Factory {
@@ -45,7 +42,8 @@ func createDefaultConfig() component.Config {
 
 		// not supported for now
 		DiscardOrphans: defaultDiscardOrphans,
-		StoreOnDisk:    defaultStoreOnDisk,
+
+		StoreOnDisk: defaultStoreOnDisk,
 	}
 }
 
@@ -58,17 +56,15 @@ func createTracesProcessor(
 ) (processor.Traces, error) {
 	oCfg := cfg.(*Config)
 
-	var st storage
-	if oCfg.StoreOnDisk {
-		return nil, errDiskStorageNotSupported
-	}
 	if oCfg.DiscardOrphans {
 		return nil, errDiscardOrphansNotSupported
 	}
 
 	processor := newGroupByTraceProcessor(params, nextConsumer, *oCfg)
-	// the only supported storage for now
-	st = newMemoryStorage(processor.telemetryBuilder)
-	processor.st = st
+	if oCfg.StoreOnDisk {
+		processor.st = newDiskStorage(oCfg.StorageID, params.ID, processor.telemetryBuilder)
+	} else {
+		processor.st = newMemoryStorage(processor.telemetryBuilder)
+	}
 	return processor, nil
 }
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/processor/processortest"
 
@@ -52,12 +54,6 @@ func TestCreateTestProcessorWithNotImplementedOptions(t *testing.T) {
 			},
 			errDiscardOrphansNotSupported,
 		},
-		{
-			&Config{
-				StoreOnDisk: true,
-			},
-			errDiskStorageNotSupported,
-		},
 	} {
 		p, err := f.CreateTraces(context.Background(), processortest.NewNopSettings(metadata.Type), tt.config, consumertest.NewNop())
 
@@ -66,3 +62,29 @@ func TestCreateTestProcessorWithNotImplementedOptions(t *testing.T) {
 		assert.Nil(t, p)
 	}
 }
+
+func TestCreateTestProcessorStoringOnDisk(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	storageID := component.MustNewID("file_storage")
+	c.StoreOnDisk = true
+	c.StorageID = &storageID
+
+	// test
+	p, err := createTracesProcessor(context.Background(), processortest.NewNopSettings(metadata.Type), c, consumertest.NewNop())
+
+	// verify
+	require.NoError(t, err)
+	assert.IsType(t, &diskStorage{}, p.(*groupByTraceProcessor).st)
+}
+
+func TestValidateConfig(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	assert.NoError(t, c.Validate())
+
+	c.StoreOnDisk = true
+	assert.EqualError(t, c.Validate(), "storage must be set when store_on_disk is enabled")
+
+	storageID := component.MustNewID("file_storage")
+	c.StorageID = &storageID
+	assert.NoError(t, c.Validate())
+}
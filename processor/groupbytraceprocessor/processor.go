@@ -71,6 +71,7 @@ func newGroupByTraceProcessor(set processor.Settings, nextConsumer consumer.Trac
 	eventMachine.onTraceExpired = sp.onTraceExpired
 	eventMachine.onTraceReleased = sp.onTraceReleased
 	eventMachine.onTraceRemoved = sp.onTraceRemoved
+	eventMachine.onTraceRecovered = sp.onTraceRecovered
 
 	return sp
 }
@@ -88,19 +89,29 @@ func (sp *groupByTraceProcessor) Capabilities() consumer.Capabilities {
 }
 
 // Start is invoked during service startup.
-func (sp *groupByTraceProcessor) Start(context.Context, component.Host) error {
+func (sp *groupByTraceProcessor) Start(ctx context.Context, host component.Host) error {
 	// start these metrics, as it might take a while for them to receive their first event
 	sp.telemetryBuilder.ProcessorGroupbytraceTracesEvicted.Add(context.Background(), 0)
 	sp.telemetryBuilder.ProcessorGroupbytraceIncompleteReleases.Add(context.Background(), 0)
 	sp.telemetryBuilder.ProcessorGroupbytraceConfNumTraces.Record(context.Background(), (int64(sp.config.NumTraces)))
+	if err := sp.st.start(ctx, host); err != nil {
+		return err
+	}
 	sp.eventMachine.startInBackground()
-	return sp.st.start()
+
+	// the traces persisted before a restart wait again for the whole duration before being released
+	if st, ok := sp.st.(persistentStorage); ok {
+		for _, traceID := range st.traceIDs() {
+			sp.eventMachine.recover(traceID)
+		}
+	}
+	return nil
 }
 
 // Shutdown is invoked during service shutdown.
-func (sp *groupByTraceProcessor) Shutdown(_ context.Context) error {
+func (sp *groupByTraceProcessor) Shutdown(ctx context.Context) error {
 	sp.eventMachine.shutdown()
-	return sp.st.shutdown()
+	return sp.st.shutdown(ctx)
 }
 
 func (sp *groupByTraceProcessor) onTraceReceived(trace tracesWithID, worker *eventMachineWorker) error {
@@ -119,7 +130,27 @@ func (sp *groupByTraceProcessor) onTraceReceived(trace tracesWithID, worker *eve
 
 	// at this point, we determined that we haven't seen the trace yet, so, record the
 	// traceID in the map and the spans to the storage
+	sp.track(traceID, worker)
+
+	// we have the traceID in the memory, place the spans in the storage too
+	if err := sp.addSpans(traceID, trace.td); err != nil {
+		return fmt.Errorf("couldn't add spans to existing trace: %w", err)
+	}
+	return nil
+}
 
+// onTraceRecovered tracks a trace found in the storage when the processor started.
+func (sp *groupByTraceProcessor) onTraceRecovered(traceID pcommon.TraceID, worker *eventMachineWorker) error {
+	if worker.buffer.contains(traceID) {
+		// spans for this trace were received since the processor started
+		return nil
+	}
+	sp.track(traceID, worker)
+	return nil
+}
+
+// track places the trace ID in the worker's buffer and schedules the release of the trace.
+func (sp *groupByTraceProcessor) track(traceID pcommon.TraceID, worker *eventMachineWorker) {
 	// place the trace ID in the buffer, and check if an item had to be evicted
 	evicted := worker.buffer.put(traceID)
 	if !evicted.IsEmpty() {
@@ -134,11 +165,6 @@ func (sp *groupByTraceProcessor) onTraceReceived(trace tracesWithID, worker *eve
 			zap.Stringer("traceID", evicted))
 	}
 
-	// we have the traceID in the memory, place the spans in the storage too
-	if err := sp.addSpans(traceID, trace.td); err != nil {
-		return fmt.Errorf("couldn't add spans to existing trace: %w", err)
-	}
-
 	sp.logger.Debug("scheduled to release trace", zap.Duration("duration", sp.config.WaitDuration))
 
 	time.AfterFunc(sp.config.WaitDuration, func() {
@@ -148,7 +174,6 @@ func (sp *groupByTraceProcessor) onTraceReceived(trace tracesWithID, worker *eve
 			payload: traceID,
 		})
 	})
-	return nil
 }
 
 func (sp *groupByTraceProcessor) onTraceExpired(traceID pcommon.TraceID, worker *eventMachineWorker) error {
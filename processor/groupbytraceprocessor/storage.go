@@ -4,6 +4,9 @@
 package groupbytraceprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/groupbytraceprocessor"
 
 import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 )
@@ -25,8 +28,17 @@ type storage interface {
 	delete(pcommon.TraceID) ([]ptrace.ResourceSpans, error)
 
 	// start gives the storage the opportunity to initialize any resources or procedures
-	start() error
+	start(context.Context, component.Host) error
 
 	// shutdown signals the storage that the processor is shutting down
-	shutdown() error
+	shutdown(context.Context) error
+}
+
+// persistentStorage is a storage keeping the traces across restarts of the processor.
+type persistentStorage interface {
+	storage
+
+	// traceIDs returns the IDs of the traces found in the storage when it started,
+	// so that they are released again after the wait duration
+	traceIDs() []pcommon.TraceID
 }
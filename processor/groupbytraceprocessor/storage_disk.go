@@ -0,0 +1,329 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package groupbytraceprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/groupbytraceprocessor"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	extensionstorage "go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/groupbytraceprocessor/internal/metadata"
+)
+
+// indexShards is the number of keys the index of the stored traces is split into, by the first byte
+// of the trace IDs, so that adding or removing a trace only rewrites a small part of the index.
+const indexShards = 256
+
+var errInvalidIndex = errors.New("invalid index of the stored traces")
+
+// diskStorage keeps the spans in a storage extension, each batch of spans received for a trace
+// being serialized under its own key. Only the number of batches of each trace is kept in memory.
+// The IDs of the stored traces are persisted in an index updated along with the first and the
+// removed batches of each trace, so that the traces are recovered even when the collector stops
+// without shutting down.
+type diskStorage struct {
+	sync.RWMutex
+	storageID   *component.ID
+	componentID component.ID
+	client      extensionstorage.Client
+	// batches holds the number of batches stored for each trace
+	batches map[pcommon.TraceID]int
+	// shards holds the IDs of the stored traces by index shard, and shardLocks serialize the
+	// writes of each shard
+	shards     [indexShards]map[pcommon.TraceID]struct{}
+	shardLocks [indexShards]sync.Mutex
+	recovered  []pcommon.TraceID
+
+	marshaler   ptrace.ProtoMarshaler
+	unmarshaler ptrace.ProtoUnmarshaler
+
+	telemetry                 *metadata.TelemetryBuilder
+	stopped                   bool
+	stoppedLock               sync.RWMutex
+	metricsCollectionInterval time.Duration
+}
+
+var _ persistentStorage = (*diskStorage)(nil)
+
+func newDiskStorage(storageID *component.ID, componentID component.ID, telemetry *metadata.TelemetryBuilder) *diskStorage {
+	st := &diskStorage{
+		storageID:                 storageID,
+		componentID:               componentID,
+		batches:                   make(map[pcommon.TraceID]int),
+		metricsCollectionInterval: time.Second,
+		telemetry:                 telemetry,
+	}
+	for i := range st.shards {
+		st.shards[i] = make(map[pcommon.TraceID]struct{})
+	}
+	return st
+}
+
+func (st *diskStorage) createOrAppend(traceID pcommon.TraceID, td ptrace.Traces) error {
+	value, err := st.marshaler.MarshalTraces(td)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the spans: %w", err)
+	}
+
+	// spans of a trace are always added by the same worker, so there's
+	// no concurrent append for the same trace
+	st.RLock()
+	batch, ok := st.batches[traceID]
+	st.RUnlock()
+
+	if ok {
+		if err := st.client.Set(context.Background(), batchKey(traceID, batch), value); err != nil {
+			return err
+		}
+		st.Lock()
+		st.batches[traceID] = batch + 1
+		st.Unlock()
+		return nil
+	}
+
+	// the first batch of the trace is stored along with the index shard listing the trace
+	shard := shardOf(traceID)
+	st.shardLocks[shard].Lock()
+	defer st.shardLocks[shard].Unlock()
+
+	st.Lock()
+	st.shards[shard][traceID] = struct{}{}
+	index := encodeIndex(st.shards[shard])
+	st.Unlock()
+
+	err = st.client.Batch(context.Background(),
+		extensionstorage.SetOperation(batchKey(traceID, 0), value),
+		extensionstorage.SetOperation(indexKey(shard), index),
+	)
+
+	st.Lock()
+	defer st.Unlock()
+	if err != nil {
+		delete(st.shards[shard], traceID)
+		return err
+	}
+	st.batches[traceID] = 1
+	return nil
+}
+
+func (st *diskStorage) get(traceID pcommon.TraceID) ([]ptrace.ResourceSpans, error) {
+	st.RLock()
+	batches, ok := st.batches[traceID]
+	st.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	ops := make([]*extensionstorage.Operation, batches)
+	for i := range ops {
+		ops[i] = extensionstorage.GetOperation(batchKey(traceID, i))
+	}
+	if err := st.client.Batch(context.Background(), ops...); err != nil {
+		return nil, err
+	}
+	return st.unmarshal(ops)
+}
+
+// delete will return the spans of the trace, read from the storage before removing them.
+func (st *diskStorage) delete(traceID pcommon.TraceID) ([]ptrace.ResourceSpans, error) {
+	shard := shardOf(traceID)
+	st.shardLocks[shard].Lock()
+	defer st.shardLocks[shard].Unlock()
+
+	st.Lock()
+	batches, ok := st.batches[traceID]
+	delete(st.batches, traceID)
+	delete(st.shards[shard], traceID)
+	index := encodeIndex(st.shards[shard])
+	st.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	ops := make([]*extensionstorage.Operation, 0, 2*batches+1)
+	for i := 0; i < batches; i++ {
+		ops = append(ops, extensionstorage.GetOperation(batchKey(traceID, i)))
+	}
+	for i := 0; i < batches; i++ {
+		ops = append(ops, extensionstorage.DeleteOperation(batchKey(traceID, i)))
+	}
+	ops = append(ops, extensionstorage.SetOperation(indexKey(shard), index))
+	if err := st.client.Batch(context.Background(), ops...); err != nil {
+		return nil, err
+	}
+	return st.unmarshal(ops[:batches])
+}
+
+func (st *diskStorage) unmarshal(ops []*extensionstorage.Operation) ([]ptrace.ResourceSpans, error) {
+	var result []ptrace.ResourceSpans
+	for _, op := range ops {
+		if op.Value == nil {
+			// the batch might not have been stored yet
+			continue
+		}
+		td, err := st.unmarshaler.UnmarshalTraces(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize the spans: %w", err)
+		}
+		for i := 0; i < td.ResourceSpans().Len(); i++ {
+			result = append(result, td.ResourceSpans().At(i))
+		}
+	}
+	return result, nil
+}
+
+func (st *diskStorage) start(ctx context.Context, host component.Host) error {
+	client, err := getStorageClient(ctx, host, st.storageID, st.componentID)
+	if err != nil {
+		return err
+	}
+	st.client = client
+
+	ops := make([]*extensionstorage.Operation, indexShards)
+	for shard := range ops {
+		ops[shard] = extensionstorage.GetOperation(indexKey(shard))
+	}
+	if err = client.Batch(ctx, ops...); err != nil {
+		return fmt.Errorf("failed to read the index of the stored traces: %w", err)
+	}
+
+	st.Lock()
+	defer st.Unlock()
+	for shard, op := range ops {
+		traceIDs, err := decodeIndex(op.Value)
+		if err != nil {
+			return err
+		}
+		for _, traceID := range traceIDs {
+			// the number of batches isn't indexed, as the batches of a trace are stored under
+			// consecutive keys
+			batches, err := countBatches(ctx, client, traceID)
+			if err != nil {
+				return fmt.Errorf("failed to read the stored traces: %w", err)
+			}
+			if batches == 0 {
+				continue
+			}
+			st.shards[shard][traceID] = struct{}{}
+			st.batches[traceID] = batches
+			st.recovered = append(st.recovered, traceID)
+		}
+	}
+
+	go st.periodicMetrics()
+	return nil
+}
+
+// shutdown closes the storage client. The traces still in the storage are released after the
+// collector restarts.
+func (st *diskStorage) shutdown(ctx context.Context) error {
+	st.stoppedLock.Lock()
+	st.stopped = true
+	st.stoppedLock.Unlock()
+
+	if st.client == nil {
+		return nil
+	}
+	return st.client.Close(ctx)
+}
+
+func (st *diskStorage) traceIDs() []pcommon.TraceID {
+	st.RLock()
+	defer st.RUnlock()
+	return st.recovered
+}
+
+func (st *diskStorage) periodicMetrics() {
+	numTraces := st.count()
+	st.telemetry.ProcessorGroupbytraceNumTracesInMemory.Record(context.Background(), int64(numTraces))
+
+	st.stoppedLock.RLock()
+	stopped := st.stopped
+	st.stoppedLock.RUnlock()
+	if stopped {
+		return
+	}
+
+	time.AfterFunc(st.metricsCollectionInterval, func() {
+		st.periodicMetrics()
+	})
+}
+
+func (st *diskStorage) count() int {
+	st.RLock()
+	defer st.RUnlock()
+	return len(st.batches)
+}
+
+// countBatches returns the number of batches stored for the trace.
+func countBatches(ctx context.Context, client extensionstorage.Client, traceID pcommon.TraceID) (int, error) {
+	for batch := 0; ; batch++ {
+		value, err := client.Get(ctx, batchKey(traceID, batch))
+		if err != nil {
+			return 0, err
+		}
+		if value == nil {
+			return batch, nil
+		}
+	}
+}
+
+func batchKey(traceID pcommon.TraceID, batch int) string {
+	return fmt.Sprintf("%s/%d", traceID, batch)
+}
+
+func indexKey(shard int) string {
+	return fmt.Sprintf("traces/%02x", shard)
+}
+
+func shardOf(traceID pcommon.TraceID) int {
+	return int(traceID[0])
+}
+
+// encodeIndex concatenates the trace IDs.
+func encodeIndex(traceIDs map[pcommon.TraceID]struct{}) []byte {
+	index := make([]byte, 0, len(traceIDs)*len(pcommon.TraceID{}))
+	for traceID := range traceIDs {
+		index = append(index, traceID[:]...)
+	}
+	return index
+}
+
+func decodeIndex(index []byte) ([]pcommon.TraceID, error) {
+	var traceID pcommon.TraceID
+	if len(index)%len(traceID) != 0 {
+		return nil, errInvalidIndex
+	}
+	traceIDs := make([]pcommon.TraceID, 0, len(index)/len(traceID))
+	for ; len(index) > 0; index = index[len(traceID):] {
+		copy(traceID[:], index)
+		traceIDs = append(traceIDs, traceID)
+	}
+	return traceIDs, nil
+}
+
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (extensionstorage.Client, error) {
+	if storageID == nil {
+		return nil, errors.New("storage must be set when store_on_disk is enabled")
+	}
+
+	extension, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+
+	storageExtension, ok := extension.(extensionstorage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+
+	return storageExtension.GetClient(ctx, component.KindProcessor, componentID, "")
+}
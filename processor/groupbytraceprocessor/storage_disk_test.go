@@ -0,0 +1,266 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package groupbytraceprocessor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	extensionstorage "go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/groupbytraceprocessor/internal/metadata"
+)
+
+func newTestDiskStorage(t *testing.T, host component.Host, storageID component.ID) *diskStorage {
+	set := processortest.NewNopSettings(metadata.Type)
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	require.NoError(t, err)
+	st := newDiskStorage(&storageID, set.ID, tel)
+	require.NoError(t, st.start(context.Background(), host))
+	return st
+}
+
+func TestDiskCreateAndGetTrace(t *testing.T) {
+	host := storagetest.NewStorageHost().WithInMemoryStorageExtension("disk")
+	st := newTestDiskStorage(t, host, storagetest.NewStorageID("disk"))
+	defer func() {
+		assert.NoError(t, st.shutdown(context.Background()))
+	}()
+
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	first := simpleTracesWithID(traceID)
+	first.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).SetName("first-span")
+	second := simpleTracesWithID(traceID)
+	second.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).SetName("second-span")
+
+	// test
+	require.NoError(t, st.createOrAppend(traceID, first))
+	require.NoError(t, st.createOrAppend(traceID, second))
+
+	// verify
+	assert.Equal(t, 1, st.count())
+	retrieved, err := st.get(traceID)
+	require.NoError(t, err)
+	require.Len(t, retrieved, 2)
+	assert.Equal(t, first.ResourceSpans().At(0), retrieved[0])
+	assert.Equal(t, second.ResourceSpans().At(0), retrieved[1])
+
+	notFound, err := st.get(pcommon.TraceID([16]byte{2, 3, 4, 5}))
+	require.NoError(t, err)
+	assert.Nil(t, notFound)
+}
+
+func TestDiskDeleteTrace(t *testing.T) {
+	host := storagetest.NewStorageHost().WithInMemoryStorageExtension("disk")
+	st := newTestDiskStorage(t, host, storagetest.NewStorageID("disk"))
+	defer func() {
+		assert.NoError(t, st.shutdown(context.Background()))
+	}()
+
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	trace := simpleTracesWithID(traceID)
+	require.NoError(t, st.createOrAppend(traceID, trace))
+
+	// test
+	deleted, err := st.delete(traceID)
+
+	// verify
+	require.NoError(t, err)
+	assert.Equal(t, []ptrace.ResourceSpans{trace.ResourceSpans().At(0)}, deleted)
+	assert.Equal(t, 0, st.count())
+
+	retrieved, err := st.get(traceID)
+	require.NoError(t, err)
+	assert.Nil(t, retrieved)
+
+	value, err := st.client.Get(context.Background(), batchKey(traceID, 0))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestDiskStorageSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	storageID := storagetest.NewStorageID("disk")
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	trace := simpleTracesWithID(traceID)
+
+	st := newTestDiskStorage(t, storagetest.NewStorageHost().WithFileBackedStorageExtension("disk", dir), storageID)
+	require.NoError(t, st.createOrAppend(traceID, trace))
+	require.NoError(t, st.shutdown(context.Background()))
+
+	// test
+	st = newTestDiskStorage(t, storagetest.NewStorageHost().WithFileBackedStorageExtension("disk", dir), storageID)
+	defer func() {
+		assert.NoError(t, st.shutdown(context.Background()))
+	}()
+
+	// verify
+	assert.Equal(t, []pcommon.TraceID{traceID}, st.traceIDs())
+	retrieved, err := st.get(traceID)
+	require.NoError(t, err)
+	assert.Equal(t, []ptrace.ResourceSpans{trace.ResourceSpans().At(0)}, retrieved)
+}
+
+func TestDiskStorageClientErrors(t *testing.T) {
+	set := processortest.NewNopSettings(metadata.Type)
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name        string
+		storageID   *component.ID
+		host        component.Host
+		expectedErr string
+	}{
+		{
+			name:        "missing storage",
+			expectedErr: "storage must be set when store_on_disk is enabled",
+		},
+		{
+			name:        "unknown extension",
+			storageID:   &component.ID{},
+			host:        componenttest.NewNopHost(),
+			expectedErr: "not found",
+		},
+		{
+			name:        "non-storage extension",
+			storageID:   func() *component.ID { id := storagetest.NewNonStorageID("other"); return &id }(),
+			host:        storagetest.NewStorageHost().WithNonStorageExtension("other"),
+			expectedErr: "non-storage extension",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newDiskStorage(tt.storageID, set.ID, tel)
+			assert.ErrorContains(t, st.start(context.Background(), tt.host), tt.expectedErr)
+			assert.NoError(t, st.shutdown(context.Background()))
+		})
+	}
+}
+
+func TestDiskStorageSurvivesCrash(t *testing.T) {
+	// the storage client is shared by the two storages, the first one never shutting down
+	client := storagetest.NewInMemoryClient(component.KindProcessor, processortest.NewNopSettings(metadata.Type).ID, "")
+	storageID := storagetest.NewStorageID("disk")
+	host := storagetest.NewStorageHost().WithExtension(storageID, &sharedStorage{client: client})
+	first := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	second := pcommon.TraceID([16]byte{1, 3, 4, 5})
+	deleted := pcommon.TraceID([16]byte{1, 4, 5, 6})
+
+	st := newTestDiskStorage(t, host, storageID)
+	require.NoError(t, st.createOrAppend(first, simpleTracesWithID(first)))
+	require.NoError(t, st.createOrAppend(first, simpleTracesWithID(first)))
+	require.NoError(t, st.createOrAppend(second, simpleTracesWithID(second)))
+	require.NoError(t, st.createOrAppend(deleted, simpleTracesWithID(deleted)))
+	_, err := st.delete(deleted)
+	require.NoError(t, err)
+
+	// test
+	st = newTestDiskStorage(t, host, storageID)
+	defer func() {
+		assert.NoError(t, st.shutdown(context.Background()))
+	}()
+
+	// verify
+	assert.ElementsMatch(t, []pcommon.TraceID{first, second}, st.traceIDs())
+	retrieved, err := st.get(first)
+	require.NoError(t, err)
+	assert.Len(t, retrieved, 2)
+	retrieved, err = st.get(second)
+	require.NoError(t, err)
+	assert.Len(t, retrieved, 1)
+
+	// the recovered traces are removed from the index once released
+	_, err = st.delete(first)
+	require.NoError(t, err)
+	_, err = st.delete(second)
+	require.NoError(t, err)
+	index, err := client.Get(context.Background(), indexKey(1))
+	require.NoError(t, err)
+	assert.Empty(t, index)
+}
+
+func TestIndexEncoding(t *testing.T) {
+	traceIDs := map[pcommon.TraceID]struct{}{
+		pcommon.TraceID([16]byte{1, 2, 3, 4}): {},
+		pcommon.TraceID([16]byte{2, 3, 4, 5}): {},
+	}
+
+	decoded, err := decodeIndex(encodeIndex(traceIDs))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []pcommon.TraceID{{1, 2, 3, 4}, {2, 3, 4, 5}}, decoded)
+
+	_, err = decodeIndex([]byte{1, 2, 3})
+	assert.ErrorIs(t, err, errInvalidIndex)
+}
+
+// sharedStorage is a storage extension returning the same client to every component.
+type sharedStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	client extensionstorage.Client
+}
+
+func (s *sharedStorage) GetClient(context.Context, component.Kind, component.ID, string) (extensionstorage.Client, error) {
+	return s.client, nil
+}
+
+func TestTraceIsReleasedAfterRestart(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+	storageID := storagetest.NewStorageID("disk")
+	config := Config{
+		WaitDuration: time.Hour,
+		NumTraces:    10,
+		NumWorkers:   1,
+		StoreOnDisk:  true,
+		StorageID:    &storageID,
+	}
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4})
+	ctx := context.Background()
+
+	p := newGroupByTraceProcessor(processortest.NewNopSettings(metadata.Type), &mockProcessor{}, config)
+	p.st = newDiskStorage(config.StorageID, processortest.NewNopSettings(metadata.Type).ID, p.telemetryBuilder)
+	require.NoError(t, p.Start(ctx, storagetest.NewStorageHost().WithFileBackedStorageExtension("disk", dir)))
+	require.NoError(t, p.ConsumeTraces(ctx, simpleTracesWithID(traceID)))
+	assert.Eventually(t, func() bool {
+		return p.st.(*diskStorage).count() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, p.Shutdown(ctx))
+
+	// test
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	var released ptrace.Traces
+	next := &mockProcessor{
+		onTraces: func(_ context.Context, td ptrace.Traces) error {
+			released = td
+			wg.Done()
+			return nil
+		},
+	}
+	config.WaitDuration = 10 * time.Millisecond
+	p = newGroupByTraceProcessor(processortest.NewNopSettings(metadata.Type), next, config)
+	p.st = newDiskStorage(config.StorageID, processortest.NewNopSettings(metadata.Type).ID, p.telemetryBuilder)
+	require.NoError(t, p.Start(ctx, storagetest.NewStorageHost().WithFileBackedStorageExtension("disk", dir)))
+	defer func() {
+		assert.NoError(t, p.Shutdown(ctx))
+	}()
+
+	// verify
+	wg.Wait()
+	assert.Equal(t, traceID, released.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID())
+	assert.Eventually(t, func() bool {
+		return p.st.(*diskStorage).count() == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -3,6 +3,26 @@
 
 package redactionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor"
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+const (
+	// actionMask replaces the matched values with a fixed length of asterisks.
+	actionMask = "mask"
+	// actionHash replaces the matched values with their hex encoded HMAC-SHA256.
+	actionHash = "hash"
+	// actionMaskKeepLast masks all the letters and digits of the matched values but the last ones.
+	actionMaskKeepLast = "mask_keep_last"
+	// actionTokenize replaces the letters and digits of the matched values with
+	// others derived from their HMAC-SHA256, with a keyed format-preserving tokenization.
+	actionTokenize = "tokenize"
+)
+
 type Config struct {
 	// AllowAllKeys is a flag to allow all span attribute keys. Setting this
 	// to true disables the AllowedKeys list. The list of BlockedValues is
@@ -27,6 +47,20 @@ type Config struct {
 	// allowed span attributes. Values that match are masked.
 	BlockedValues []string `mapstructure:"blocked_values"`
 
+	// BlockedValueRules is a list of rules for blocking values of allowed span
+	// attributes with a specific action. They're applied after BlockedValues.
+	BlockedValueRules []BlockedValueRule `mapstructure:"blocked_value_rules"`
+
+	// HMACKey is the secret key used by the `hash` and `tokenize` actions,
+	// so the redacted values remain joinable without being reversible.
+	HMACKey configopaque.String `mapstructure:"hmac_key"`
+
+	// RedactSpanEvents applies the configuration to the attributes of the span events.
+	RedactSpanEvents bool `mapstructure:"redact_span_events"`
+
+	// RedactLogBody applies the blocked values and rules to the string bodies of the logs.
+	RedactLogBody bool `mapstructure:"redact_log_body"`
+
 	// AllowedValues is a list of regular expressions for allowing values of
 	// blocked span attributes. Values that match are not masked.
 	AllowedValues []string `mapstructure:"allowed_values"`
@@ -38,3 +72,64 @@ type Config struct {
 	// configuration. Possible values are `debug`, `info`, and `silent`.
 	Summary string `mapstructure:"summary"`
 }
+
+// BlockedValueRule redacts the values matching a regular expression or found
+// by a built-in detector with an action.
+type BlockedValueRule struct {
+	// Pattern is the regular expression matching the parts of the values to redact.
+	Pattern string `mapstructure:"pattern"`
+
+	// Detector is the name of the built-in detector finding the parts of the values
+	// to redact, as an alternative to Pattern. Possible values are `credit_card`,
+	// `iban`, `us_ssn`, `email`, `ipv4`, `ipv6`, `jwt`, `aws_access_key_id` and `gcp_api_key`.
+	Detector string `mapstructure:"detector"`
+
+	// Action is how the matching parts are redacted. Possible values are `mask`
+	// (default), `hash`, `mask_keep_last` and `tokenize`.
+	Action string `mapstructure:"action"`
+
+	// KeepLast is the number of trailing letters and digits the `mask_keep_last`
+	// action leaves unmasked, such as the last 4 digits of a card number.
+	KeepLast int `mapstructure:"keep_last"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	for i, rule := range cfg.BlockedValueRules {
+		if err := rule.validate(cfg); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("blocked_value_rules[%d]: %w", i, err))
+		}
+	}
+	return errs
+}
+
+func (r *BlockedValueRule) validate(cfg *Config) error {
+	switch {
+	case r.Pattern == "" && r.Detector == "":
+		return errors.New("one of pattern or detector must be set")
+	case r.Pattern != "" && r.Detector != "":
+		return errors.New("pattern and detector can't be both set")
+	case r.Detector != "":
+		if _, ok := detectors[r.Detector]; !ok {
+			return fmt.Errorf("unknown detector %q", r.Detector)
+		}
+	default:
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	switch r.Action {
+	case "", actionMask:
+	case actionHash, actionTokenize:
+		if cfg.HMACKey == "" {
+			return fmt.Errorf("hmac_key must be set for the %q action", r.Action)
+		}
+	case actionMaskKeepLast:
+		if r.KeepLast <= 0 {
+			return fmt.Errorf("keep_last must be greater than 0 for the %q action", r.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	return nil
+}
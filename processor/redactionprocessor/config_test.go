@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/confmap/xconfmap"
 
@@ -39,6 +40,21 @@ func TestLoadConfig(t *testing.T) {
 			id:       component.NewIDWithName(metadata.Type, "empty"),
 			expected: createDefaultConfig(),
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "rules"),
+			expected: &Config{
+				AllowAllKeys: true,
+				BlockedValueRules: []BlockedValueRule{
+					{Pattern: "4[0-9]{12}(?:[0-9]{3})?", Action: actionMaskKeepLast, KeepLast: 4},
+					{Pattern: "[a-z0-9._%+-]+@[a-z0-9.-]+\\.[a-z]{2,}", Action: actionHash},
+					{Pattern: "ACC-[0-9]{8}", Action: actionTokenize},
+					{Detector: detectorIBAN, Action: actionMaskKeepLast, KeepLast: 4},
+				},
+				HMACKey:          "secret",
+				RedactSpanEvents: true,
+				RedactLogBody:    true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -58,3 +74,75 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        BlockedValueRule
+		hmacKey     configopaque.String
+		expectedErr string
+	}{
+		{
+			name: "mask",
+			rule: BlockedValueRule{Pattern: "secret"},
+		},
+		{
+			name:        "missing pattern",
+			rule:        BlockedValueRule{Action: actionMask},
+			expectedErr: "blocked_value_rules[0]: one of pattern or detector must be set",
+		},
+		{
+			name: "detector",
+			rule: BlockedValueRule{Detector: detectorCreditCard, Action: actionMaskKeepLast, KeepLast: 4},
+		},
+		{
+			name:        "pattern and detector",
+			rule:        BlockedValueRule{Pattern: "secret", Detector: detectorEmail},
+			expectedErr: "blocked_value_rules[0]: pattern and detector can't be both set",
+		},
+		{
+			name:        "unknown detector",
+			rule:        BlockedValueRule{Detector: "passport"},
+			expectedErr: `blocked_value_rules[0]: unknown detector "passport"`,
+		},
+		{
+			name:        "invalid pattern",
+			rule:        BlockedValueRule{Pattern: "(secret"},
+			expectedErr: "blocked_value_rules[0]: invalid pattern",
+		},
+		{
+			name:        "hash without key",
+			rule:        BlockedValueRule{Pattern: "secret", Action: actionHash},
+			expectedErr: `blocked_value_rules[0]: hmac_key must be set for the "hash" action`,
+		},
+		{
+			name:    "tokenize",
+			rule:    BlockedValueRule{Pattern: "secret", Action: actionTokenize},
+			hmacKey: "key",
+		},
+		{
+			name:        "keep last without count",
+			rule:        BlockedValueRule{Pattern: "secret", Action: actionMaskKeepLast},
+			expectedErr: `blocked_value_rules[0]: keep_last must be greater than 0 for the "mask_keep_last" action`,
+		},
+		{
+			name:        "unknown action",
+			rule:        BlockedValueRule{Pattern: "secret", Action: "encrypt"},
+			expectedErr: `blocked_value_rules[0]: unknown action "encrypt"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				BlockedValueRules: []BlockedValueRule{tt.rule},
+				HMACKey:           tt.hmacKey,
+			}
+			err := xconfmap.Validate(cfg)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redactionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor"
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+const (
+	detectorCreditCard   = "credit_card"
+	detectorIBAN         = "iban"
+	detectorUSSSN        = "us_ssn"
+	detectorEmail        = "email"
+	detectorIPv4         = "ipv4"
+	detectorIPv6         = "ipv6"
+	detectorJWT          = "jwt"
+	detectorAWSAccessKey = "aws_access_key_id"
+	detectorGCPAPIKey    = "gcp_api_key"
+)
+
+// detector finds the values of a well-known kind of sensitive data. The candidates
+// matching the regular expression are only redacted when they pass the validation,
+// e.g. the checksum of a card number, which avoids masking look-alike values.
+type detector struct {
+	re       *regexp.Regexp
+	validate func(string) bool
+}
+
+// detectors are the built-in detectors, by name.
+var detectors = map[string]detector{
+	detectorCreditCard: {
+		re:       regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		validate: isValidCardNumber,
+	},
+	detectorIBAN: {
+		re:       regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[0-9A-Z]{4}){2,7}(?: ?[0-9A-Z]{1,4})?\b`),
+		validate: isValidIBAN,
+	},
+	detectorUSSSN: {
+		re:       regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		validate: isValidSSN,
+	},
+	detectorEmail: {
+		re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+	detectorIPv4: {
+		re:       regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		validate: isValidIPv4,
+	},
+	detectorIPv6: {
+		re:       regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}(?:(?:\d{1,3}\.){3}\d{1,3}|[0-9a-f]{1,4})?`),
+		validate: isValidIPv6,
+	},
+	detectorJWT: {
+		re:       regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		validate: isValidJWT,
+	},
+	detectorAWSAccessKey: {
+		re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[A-Z2-7]{16}\b`),
+	},
+	detectorGCPAPIKey: {
+		re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),
+	},
+}
+
+// isValidCardNumber returns whether the number has 13 to 19 digits and passes the Luhn check.
+func isValidCardNumber(value string) bool {
+	digits := stripSeparators(value)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return luhnSum(digits)%10 == 0
+}
+
+// luhnSum returns the sum of the Luhn algorithm of the digits, doubling every second digit
+// from the right. The digits pass the Luhn check when it's a multiple of 10.
+func luhnSum(digits string) int {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum
+}
+
+// isValidIBAN returns whether the IBAN passes the ISO 13616 mod 97 check.
+func isValidIBAN(value string) bool {
+	iban := stripSeparators(value)
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	// The country code and check digits are moved to the end, then letters are
+	// converted to numbers, A being 10, and the remainder computed digit by digit.
+	rem := 0
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
+
+// isValidSSN returns whether the number has a valid area, group and serial number.
+func isValidSSN(value string) bool {
+	area, group, serial := value[0:3], value[4:6], value[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func isValidIPv4(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	return err == nil && addr.Is4()
+}
+
+func isValidIPv6(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	return err == nil && addr.Is6()
+}
+
+// isValidJWT returns whether the header of the token is a JSON object with an algorithm.
+func isValidJWT(value string) bool {
+	header, _, _ := strings.Cut(value, ".")
+	decoded, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return false
+	}
+	var fields struct {
+		Alg string `json:"alg"`
+	}
+	return json.Unmarshal(decoded, &fields) == nil && fields.Alg != ""
+}
+
+func stripSeparators(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
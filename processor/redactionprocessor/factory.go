@@ -41,7 +41,7 @@ func createTracesProcessor(
 ) (processor.Traces, error) {
 	oCfg := cfg.(*Config)
 
-	redaction, err := newRedaction(ctx, oCfg, set.Logger)
+	redaction, err := newRedaction(ctx, oCfg, set)
 	if err != nil {
 		// TODO: Placeholder for an error metric in the next PR
 		return nil, fmt.Errorf("error creating a redaction processor: %w", err)
@@ -65,7 +65,7 @@ func createLogsProcessor(
 ) (processor.Logs, error) {
 	oCfg := cfg.(*Config)
 
-	red, err := newRedaction(ctx, oCfg, set.Logger)
+	red, err := newRedaction(ctx, oCfg, set)
 	if err != nil {
 		return nil, fmt.Errorf("error creating a redaction processor: %w", err)
 	}
@@ -88,7 +88,7 @@ func createMetricsProcessor(
 ) (processor.Metrics, error) {
 	oCfg := cfg.(*Config)
 
-	red, err := newRedaction(ctx, oCfg, set.Logger)
+	red, err := newRedaction(ctx, oCfg, set)
 	if err != nil {
 		return nil, fmt.Errorf("error creating a redaction processor: %w", err)
 	}
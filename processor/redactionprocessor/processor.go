@@ -14,7 +14,12 @@ import (
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor/internal/metadata"
 )
 
 const attrValuesSeparator = ","
@@ -24,8 +29,8 @@ type redaction struct {
 	allowList map[string]string
 	// Attribute keys ignored in a span
 	ignoreList map[string]string
-	// Attribute values blocked in a span
-	blockRegexList map[string]*regexp.Regexp
+	// Rules redacting the attribute values blocked in a span
+	valueRules []valueRule
 	// Attribute values allowed in a span
 	allowRegexList map[string]*regexp.Regexp
 	// Attribute keys blocked in a span
@@ -34,13 +39,15 @@ type redaction struct {
 	config *Config
 	// Logger
 	logger *zap.Logger
+	// Internal telemetry
+	telemetryBuilder *metadata.TelemetryBuilder
 }
 
 // newRedaction creates a new instance of the redaction processor
-func newRedaction(ctx context.Context, config *Config, logger *zap.Logger) (*redaction, error) {
+func newRedaction(ctx context.Context, config *Config, set processor.Settings) (*redaction, error) {
 	allowList := makeAllowList(config)
 	ignoreList := makeIgnoreList(config)
-	blockRegexList, err := makeRegexList(ctx, config.BlockedValues)
+	valueRules, err := makeValueRules(config)
 	if err != nil {
 		// TODO: Placeholder for an error metric in the next PR
 		return nil, fmt.Errorf("failed to process block list: %w", err)
@@ -57,14 +64,20 @@ func newRedaction(ctx context.Context, config *Config, logger *zap.Logger) (*red
 		return nil, fmt.Errorf("failed to process allow list: %w", err)
 	}
 
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry builder: %w", err)
+	}
+
 	return &redaction{
 		allowList:         allowList,
 		ignoreList:        ignoreList,
-		blockRegexList:    blockRegexList,
+		valueRules:        valueRules,
 		allowRegexList:    allowRegexList,
 		blockKeyRegexList: blockKeysRegexList,
 		config:            config,
-		logger:            logger,
+		logger:            set.Logger,
+		telemetryBuilder:  telemetryBuilder,
 	}, nil
 }
 
@@ -110,6 +123,12 @@ func (s *redaction) processResourceSpan(ctx context.Context, rs ptrace.ResourceS
 
 			// Attributes can also be part of span
 			s.processAttrs(ctx, spanAttrs)
+
+			if s.config.RedactSpanEvents {
+				for l := 0; l < span.Events().Len(); l++ {
+					s.processAttrs(ctx, span.Events().At(l).Attributes())
+				}
+			}
 		}
 	}
 }
@@ -126,6 +145,10 @@ func (s *redaction) processResourceLog(ctx context.Context, rl plog.ResourceLogs
 		for k := 0; k < ils.LogRecords().Len(); k++ {
 			log := ils.LogRecords().At(k)
 			s.processAttrs(ctx, log.Attributes())
+
+			if s.config.RedactLogBody {
+				s.processLogBody(ctx, log)
+			}
 		}
 	}
 }
@@ -172,8 +195,8 @@ func (s *redaction) processResourceMetric(ctx context.Context, rm pmetric.Resour
 }
 
 // processAttrs redacts the attributes of a resource span or a span
-func (s *redaction) processAttrs(_ context.Context, attributes pcommon.Map) {
-	// TODO: Use the context for recording metrics
+func (s *redaction) processAttrs(ctx context.Context, attributes pcommon.Map) {
+	detected := map[string]int64{}
 	var toDelete []string
 	var toBlock []string
 	var allowed []string
@@ -224,18 +247,9 @@ func (s *redaction) processAttrs(_ context.Context, attributes pcommon.Map) {
 		}
 
 		// Mask any blocked values for the other attributes
-		var matched bool
-		for _, compiledRE := range s.blockRegexList {
-			if match := compiledRE.MatchString(strVal); match {
-				if !matched {
-					matched = true
-					toBlock = append(toBlock, k)
-				}
-
-				maskedValue := compiledRE.ReplaceAllString(strVal, "****")
-				value.SetStr(maskedValue)
-				strVal = maskedValue
-			}
+		if redactedValue, matched := s.redactValue(strVal, detected); matched {
+			toBlock = append(toBlock, k)
+			value.SetStr(redactedValue)
 		}
 		return true
 	})
@@ -249,6 +263,60 @@ func (s *redaction) processAttrs(_ context.Context, attributes pcommon.Map) {
 	s.addMetaAttrs(toBlock, attributes, maskedValues, maskedValueCount)
 	s.addMetaAttrs(allowed, attributes, allowedValues, allowedValueCount)
 	s.addMetaAttrs(ignoring, attributes, "", ignoredKeyCount)
+	s.addDetectedAttrs(ctx, detected, attributes)
+}
+
+// processLogBody redacts the blocked values of a string log body. The summary is added to the log attributes.
+func (s *redaction) processLogBody(ctx context.Context, log plog.LogRecord) {
+	if log.Body().Type() != pcommon.ValueTypeStr {
+		return
+	}
+	for _, compiledRE := range s.allowRegexList {
+		if compiledRE.MatchString(log.Body().Str()) {
+			return
+		}
+	}
+	detected := map[string]int64{}
+	if redactedValue, matched := s.redactValue(log.Body().Str(), detected); matched {
+		log.Body().SetStr(redactedValue)
+		s.addMetaAttrs([]string{bodyKey}, log.Attributes(), maskedValues, maskedValueCount)
+		s.addDetectedAttrs(ctx, detected, log.Attributes())
+	}
+}
+
+// redactValue applies the blocked value rules to the value in order, returning
+// the redacted value and whether any rule matched. The number of values found by
+// each detector is added to detected.
+func (s *redaction) redactValue(value string, detected map[string]int64) (string, bool) {
+	var matched bool
+	for i := range s.valueRules {
+		var count int
+		value, count = s.valueRules[i].redact(value)
+		if count == 0 {
+			continue
+		}
+		matched = true
+		if s.valueRules[i].detector != "" {
+			detected[s.valueRules[i].detector] += int64(count)
+		}
+	}
+	return value, matched
+}
+
+// addDetectedAttrs records the number of values found by each detector, in the
+// internal telemetry and as the redaction.detected.<detector>.count attributes
+func (s *redaction) addDetectedAttrs(ctx context.Context, detected map[string]int64, attributes pcommon.Map) {
+	for name, count := range detected {
+		s.telemetryBuilder.RedactionValuesDetected.Add(ctx, count, metric.WithAttributes(attribute.String("detector", name)))
+
+		if s.config.Summary == info || s.config.Summary == debug {
+			countAttr := detectedCountPrefix + name + detectedCountSuffix
+			if existingVal, found := attributes.Get(countAttr); found {
+				count += existingVal.Int()
+			}
+			attributes.PutInt(countAttr, count)
+		}
+	}
 }
 
 // addMetaAttrs adds diagnostic information about redacted or masked attribute keys
@@ -284,6 +352,12 @@ const (
 	allowedValues     = "redaction.allowed.keys"
 	allowedValueCount = "redaction.allowed.count"
 	ignoredKeyCount   = "redaction.ignored.count"
+	// detectedCountPrefix and detectedCountSuffix surround the name of a detector
+	// in the attribute counting the values it found
+	detectedCountPrefix = "redaction.detected."
+	detectedCountSuffix = ".count"
+	// bodyKey is the key listed in the summary when the body of a log is masked
+	bodyKey = "body"
 )
 
 // makeAllowList sets up a lookup table of allowed span attribute keys
@@ -298,7 +372,14 @@ func makeAllowList(c *Config) map[string]string {
 	// span attributes (e.g. `notes`, `description`), then it will those
 	// attribute keys in `redaction.masked.keys` and set the
 	// `redaction.masked.count` to 2
+	//
+	// The values found by a detector are counted in `redaction.detected.<detector>.count`
 	redactionKeys := []string{redactedKeys, redactedKeyCount, maskedValues, maskedValueCount, ignoredKeyCount}
+	for _, rule := range c.BlockedValueRules {
+		if rule.Detector != "" {
+			redactionKeys = append(redactionKeys, detectedCountPrefix+rule.Detector+detectedCountSuffix)
+		}
+	}
 	// allowList consists of the keys explicitly allowed by the configuration
 	// as well as of the new span attributes that the processor creates to
 	// summarize its changes
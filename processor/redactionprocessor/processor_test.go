@@ -11,11 +11,18 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
-	"go.uber.org/zap/zaptest"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor/internal/metadatatest"
 )
 
 type TestConfig struct {
@@ -536,7 +543,7 @@ func TestProcessAttrsAppliedTwice(t *testing.T) {
 		BlockedValues: []string{"4[0-9]{12}(?:[0-9]{3})?"},
 		Summary:       "debug",
 	}
-	processor, err := newRedaction(context.TODO(), config, zaptest.NewLogger(t))
+	processor, err := newRedaction(context.TODO(), config, processortest.NewNopSettings(metadata.Type))
 	require.NoError(t, err)
 
 	attrs := pcommon.NewMap()
@@ -567,6 +574,152 @@ func TestProcessAttrsAppliedTwice(t *testing.T) {
 	assert.Equal(t, int64(2), val.Int())
 }
 
+// TestBlockedValueRules validates that the blocked value rules redact the
+// matching parts of the values with their action
+func TestBlockedValueRules(t *testing.T) {
+	config := &Config{
+		AllowAllKeys: true,
+		BlockedValueRules: []BlockedValueRule{
+			{Pattern: "4[0-9]{12}(?:[0-9]{3})?", Action: actionMaskKeepLast, KeepLast: 4},
+			{Pattern: "[a-z]+@example\\.com", Action: actionHash},
+			{Pattern: "secret"},
+		},
+		HMACKey: "key",
+		Summary: "debug",
+	}
+	processor, err := newRedaction(context.TODO(), config, processortest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	assert.NoError(t, attrs.FromRaw(map[string]any{
+		"credit_card": "4111111111111111",
+		"email":       "user@example.com",
+		"note":        "my secret",
+		"id":          5,
+	}))
+	processor.processAttrs(context.TODO(), attrs)
+
+	val, _ := attrs.Get("credit_card")
+	assert.Equal(t, "************1111", val.Str())
+	val, _ = attrs.Get("email")
+	assert.Equal(t, hashValue([]byte("key"), "user@example.com"), val.Str())
+	val, _ = attrs.Get("note")
+	assert.Equal(t, "my ****", val.Str())
+	val, _ = attrs.Get(maskedValues)
+	assert.Equal(t, "credit_card,email,note", val.Str())
+}
+
+// TestRedactSpanEvents validates that the attributes of the span events are
+// redacted when redact_span_events is enabled
+func TestRedactSpanEvents(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		config := &Config{
+			AllowAllKeys:     true,
+			BlockedValues:    []string{"4[0-9]{12}(?:[0-9]{3})?"},
+			RedactSpanEvents: enabled,
+		}
+		processor, err := newRedaction(context.TODO(), config, processortest.NewNopSettings(metadata.Type))
+		require.NoError(t, err)
+
+		traces := ptrace.NewTraces()
+		span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		event := span.Events().AppendEmpty()
+		event.Attributes().PutStr("credit_card", "4111111111111111")
+
+		_, err = processor.processTraces(context.TODO(), traces)
+		require.NoError(t, err)
+
+		val, _ := event.Attributes().Get("credit_card")
+		if enabled {
+			assert.Equal(t, "****", val.Str())
+		} else {
+			assert.Equal(t, "4111111111111111", val.Str())
+		}
+	}
+}
+
+// TestRedactLogBody validates that the string body of the logs is redacted
+// when redact_log_body is enabled
+func TestRedactLogBody(t *testing.T) {
+	config := &Config{
+		AllowAllKeys:  true,
+		BlockedValues: []string{"4[0-9]{12}(?:[0-9]{3})?"},
+		AllowedValues: []string{".+@mycompany.com"},
+		RedactLogBody: true,
+		Summary:       "debug",
+	}
+	processor, err := newRedaction(context.TODO(), config, processortest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	masked := records.AppendEmpty()
+	masked.Body().SetStr("paid with 4111111111111111")
+	allowed := records.AppendEmpty()
+	allowed.Body().SetStr("user@mycompany.com paid with 4111111111111111")
+	structured := records.AppendEmpty()
+	assert.NoError(t, structured.Body().SetEmptyMap().FromRaw(map[string]any{"card": "4111111111111111"}))
+
+	_, err = processor.processLogs(context.TODO(), logs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "paid with ****", masked.Body().Str())
+	val, found := masked.Attributes().Get(maskedValues)
+	assert.True(t, found)
+	assert.Equal(t, "body", val.Str())
+	val, found = masked.Attributes().Get(maskedValueCount)
+	assert.True(t, found)
+	assert.Equal(t, int64(1), val.Int())
+
+	assert.Equal(t, "user@mycompany.com paid with 4111111111111111", allowed.Body().Str())
+	_, found = allowed.Attributes().Get(maskedValues)
+	assert.False(t, found)
+
+	card, _ := structured.Body().Map().Get("card")
+	assert.Equal(t, "4111111111111111", card.Str())
+}
+
+// TestDetectorSummary validates that the values found by the detectors are
+// counted per detector in the summary and the internal telemetry
+func TestDetectorSummary(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	config := &Config{
+		AllowedKeys: []string{"card", "contact"},
+		BlockedValueRules: []BlockedValueRule{
+			{Detector: detectorCreditCard, Action: actionMaskKeepLast, KeepLast: 4},
+			{Detector: detectorEmail},
+		},
+		Summary: "info",
+	}
+	processor, err := newRedaction(context.TODO(), config, metadatatest.NewSettings(tel))
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	assert.NoError(t, attrs.FromRaw(map[string]any{
+		"card":    "4111111111111111 or 5500000000000004",
+		"contact": "user@example.com",
+	}))
+	processor.processAttrs(context.TODO(), attrs)
+	// The summary attributes are kept and accumulated when processed twice
+	attrs.PutStr("contact", "other@example.com")
+	processor.processAttrs(context.TODO(), attrs)
+
+	val, _ := attrs.Get("card")
+	assert.Equal(t, "************1111 or ************0004", val.Str())
+	val, found := attrs.Get("redaction.detected.credit_card.count")
+	assert.True(t, found)
+	assert.Equal(t, int64(2), val.Int())
+	val, found = attrs.Get("redaction.detected.email.count")
+	assert.True(t, found)
+	assert.Equal(t, int64(2), val.Int())
+
+	metadatatest.AssertEqualRedactionValuesDetected(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("detector", detectorCreditCard))},
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("detector", detectorEmail))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
 // runTest transforms the test input data and passes it through the processor
 func runTest(
 	t *testing.T,
@@ -611,7 +764,7 @@ func runTest(
 
 	// test
 	ctx := context.Background()
-	processor, err := newRedaction(ctx, cfg.config, zaptest.NewLogger(t))
+	processor, err := newRedaction(ctx, cfg.config, processortest.NewNopSettings(metadata.Type))
 	assert.NoError(t, err)
 	outBatch, err := processor.processTraces(ctx, inBatch)
 
@@ -665,7 +818,7 @@ func runLogsTest(
 
 	// test
 	ctx := context.Background()
-	processor, err := newRedaction(ctx, cfg.config, zaptest.NewLogger(t))
+	processor, err := newRedaction(ctx, cfg.config, processortest.NewNopSettings(metadata.Type))
 	assert.NoError(t, err)
 	outBatch, err := processor.processLogs(ctx, inBatch)
 
@@ -739,7 +892,7 @@ func runMetricsTest(
 
 	// test
 	ctx := context.Background()
-	processor, err := newRedaction(ctx, cfg.config, zaptest.NewLogger(t))
+	processor, err := newRedaction(ctx, cfg.config, processortest.NewNopSettings(metadata.Type))
 	assert.NoError(t, err)
 	outBatch, err := processor.processMetrics(ctx, inBatch)
 
@@ -773,7 +926,7 @@ func BenchmarkRedactSummaryDebug(b *testing.B) {
 		"credit_card": pcommon.NewValueStr("would be nice"),
 	}
 	ctx := context.Background()
-	processor, _ := newRedaction(ctx, config, zaptest.NewLogger(b))
+	processor, _ := newRedaction(ctx, config, processortest.NewNopSettings(metadata.Type))
 
 	for i := 0; i < b.N; i++ {
 		runBenchmark(allowed, redacted, masked, ignored, processor)
@@ -804,7 +957,7 @@ func BenchmarkMaskSummaryDebug(b *testing.B) {
 		"also_safe":      pcommon.NewValueStr("suspicious 4111111111111113"),
 	}
 	ctx := context.Background()
-	processor, _ := newRedaction(ctx, config, zaptest.NewLogger(b))
+	processor, _ := newRedaction(ctx, config, processortest.NewNopSettings(metadata.Type))
 
 	for i := 0; i < b.N; i++ {
 		runBenchmark(allowed, nil, masked, ignored, processor)
@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redactionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor"
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+const mask = "****"
+
+// valueRule redacts the parts of the values matching a regular expression.
+type valueRule struct {
+	re      *regexp.Regexp
+	replace func(string) string
+	// detector is the name of the built-in detector of the rule, if any.
+	detector string
+	// validate optionally filters the matching parts to redact.
+	validate func(string) bool
+}
+
+// redact returns the value with the matching parts redacted, and the number of redacted parts.
+func (r *valueRule) redact(value string) (string, int) {
+	if !r.re.MatchString(value) {
+		return value, 0
+	}
+	count := 0
+	redacted := r.re.ReplaceAllStringFunc(value, func(match string) string {
+		if r.validate != nil && !r.validate(match) {
+			return match
+		}
+		count++
+		return r.replace(match)
+	})
+	return redacted, count
+}
+
+// makeValueRules precompiles the blocked values, masked with asterisks, followed by the blocked value rules.
+func makeValueRules(c *Config) ([]valueRule, error) {
+	rules := make([]valueRule, 0, len(c.BlockedValues)+len(c.BlockedValueRules))
+	for _, pattern := range c.BlockedValues {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling regex in list: %w", err)
+		}
+		rules = append(rules, valueRule{re: re, replace: maskAll})
+	}
+	key := []byte(c.HMACKey)
+	for _, rule := range c.BlockedValueRules {
+		var vr valueRule
+		if rule.Detector != "" {
+			d, ok := detectors[rule.Detector]
+			if !ok {
+				return nil, fmt.Errorf("unknown detector %q", rule.Detector)
+			}
+			vr = valueRule{re: d.re, detector: rule.Detector, validate: d.validate}
+		} else {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("error compiling regex in rules: %w", err)
+			}
+			vr = valueRule{re: re}
+		}
+		switch rule.Action {
+		case "", actionMask:
+			vr.replace = maskAll
+		case actionHash:
+			vr.replace = func(s string) string { return hashValue(key, s) }
+		case actionMaskKeepLast:
+			keepLast := rule.KeepLast
+			vr.replace = func(s string) string { return maskKeepLast(s, keepLast) }
+		case actionTokenize:
+			vr.replace = func(s string) string { return tokenize(key, s) }
+		default:
+			return nil, fmt.Errorf("unknown action %q", rule.Action)
+		}
+		rules = append(rules, vr)
+	}
+	return rules, nil
+}
+
+func maskAll(string) string {
+	return mask
+}
+
+// hashValue returns the hex encoded HMAC-SHA256 of the value, so equal values
+// are redacted the same way.
+func hashValue(key []byte, value string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// maskKeepLast masks the letters and digits of the value with asterisks, except the
+// last keepLast ones. Other characters, such as separators, are kept.
+func maskKeepLast(value string, keepLast int) string {
+	runes := []rune(value)
+	kept := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		if !isAlphanumeric(runes[i]) {
+			continue
+		}
+		if kept < keepLast {
+			kept++
+			continue
+		}
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+// tokenize replaces the digits, lowercase and uppercase ASCII letters of the value with ones
+// derived from its HMAC-SHA256, preserving the format of the value. It's a keyed format-preserving
+// tokenization rather than an encryption: equal values are tokenized the same way, but the tokens
+// can't be reversed. The token of a valid card number is a valid card number too.
+func tokenize(key []byte, value string) string {
+	stream := newKeyStream(key, value)
+	runes := []rune(value)
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			runes[i] = '0' + rune(stream.intn(10))
+		case r >= 'a' && r <= 'z':
+			runes[i] = 'a' + rune(stream.intn(26))
+		case r >= 'A' && r <= 'Z':
+			runes[i] = 'A' + rune(stream.intn(26))
+		}
+	}
+	if isValidCardNumber(value) {
+		setLuhnCheckDigit(runes)
+	}
+	return string(runes)
+}
+
+// setLuhnCheckDigit replaces the last digit of the number so that it passes the Luhn check.
+func setLuhnCheckDigit(number []rune) {
+	last := -1
+	var digits []byte
+	for i, r := range number {
+		if r >= '0' && r <= '9' {
+			last = i
+			digits = append(digits, byte(r))
+		}
+	}
+	if last < 0 {
+		return
+	}
+	// The check digit isn't doubled, so the sum with a 0 check digit gives the missing amount.
+	digits[len(digits)-1] = '0'
+	number[last] = '0' + rune((10-luhnSum(string(digits))%10)%10)
+}
+
+// keyStream is a stream of pseudo-random bytes derived from a key and a value.
+type keyStream struct {
+	key     []byte
+	value   string
+	counter uint32
+	block   []byte
+}
+
+func newKeyStream(key []byte, value string) *keyStream {
+	return &keyStream{key: key, value: value}
+}
+
+// intn returns a uniformly distributed number in [0, n), for n up to 256. The bytes
+// above the largest multiple of n are rejected, so that all the numbers are as likely.
+func (s *keyStream) intn(n int) int {
+	limit := 256 - 256%n
+	for {
+		if b := int(s.next()); b < limit {
+			return b % n
+		}
+	}
+}
+
+func (s *keyStream) next() byte {
+	if len(s.block) == 0 {
+		h := hmac.New(sha256.New, s.key)
+		h.Write(binary.BigEndian.AppendUint32(nil, s.counter))
+		h.Write([]byte(s.value))
+		s.block = h.Sum(nil)
+		s.counter++
+	}
+	b := s.block[0]
+	s.block = s.block[1:]
+	return b
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redactionprocessor
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashValue(t *testing.T) {
+	key := []byte("secret")
+	hashed := hashValue(key, "user@example.com")
+
+	assert.Len(t, hashed, 64)
+	assert.Equal(t, hashed, hashValue(key, "user@example.com"))
+	assert.NotEqual(t, hashed, hashValue(key, "other@example.com"))
+	assert.NotEqual(t, hashed, hashValue([]byte("other"), "user@example.com"))
+}
+
+func TestMaskKeepLast(t *testing.T) {
+	tests := []struct {
+		value    string
+		keepLast int
+		expected string
+	}{
+		{"4111111111111111", 4, "************1111"},
+		{"4111-1111-1111-1111", 4, "****-****-****-1111"},
+		{"+1 (555) 123-4567", 2, "+* (***) ***-**67"},
+		{"123", 4, "123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskKeepLast(tt.value, tt.keepLast))
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	key := []byte("secret")
+	value := "Card 4111-1111-1111-1111, exp 12/30"
+	tokenized := tokenize(key, value)
+
+	assert.Equal(t, tokenized, tokenize(key, value))
+	assert.NotEqual(t, value, tokenized)
+	assert.NotEqual(t, tokenized, tokenize([]byte("other"), value))
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z][a-z]{3} \d{4}-\d{4}-\d{4}-\d{4}, [a-z]{3} \d{2}/\d{2}$`), tokenized)
+
+	// longer values than a single HMAC block are tokenized too
+	long := "0123456789012345678901234567890123456789"
+	assert.Regexp(t, regexp.MustCompile(`^\d{40}$`), tokenize(key, long))
+}
+
+func TestTokenizeCardNumber(t *testing.T) {
+	key := []byte("secret")
+	for _, card := range []string{"4111111111111111", "4111-1111-1111-1111", "5500 0000 0000 0004", "378282246310005"} {
+		t.Run(card, func(t *testing.T) {
+			tokenized := tokenize(key, card)
+			assert.NotEqual(t, card, tokenized)
+			assert.Len(t, tokenized, len(card))
+			assert.True(t, isValidCardNumber(tokenized), tokenized)
+		})
+	}
+}
+
+func TestKeyStreamIntn(t *testing.T) {
+	stream := newKeyStream([]byte("secret"), "value")
+	counts := make([]int, 26)
+	const samples = 260000
+	for i := 0; i < samples; i++ {
+		counts[stream.intn(26)]++
+	}
+	// Without rejecting the bytes above 233, the last 4 letters would be 10% less likely.
+	for letter, count := range counts {
+		assert.InDelta(t, samples/26, count, 400, "letter %c", 'a'+letter)
+	}
+}
+
+func TestMakeValueRules(t *testing.T) {
+	rules, err := makeValueRules(&Config{
+		BlockedValues: []string{"secret"},
+		BlockedValueRules: []BlockedValueRule{
+			{Pattern: `\d{16}`, Action: actionMaskKeepLast, KeepLast: 4},
+			{Pattern: `[a-z]+@example\.com`, Action: actionHash},
+			{Pattern: `ID-\d+`, Action: actionTokenize},
+			{Pattern: `password`},
+		},
+		HMACKey: "key",
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 5)
+
+	value, count := rules[0].redact("my secret")
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "my ****", value)
+
+	value, count = rules[1].redact("card 4111111111111111")
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "card ************1111", value)
+
+	value, count = rules[2].redact("user user@example.com")
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "user "+hashValue([]byte("key"), "user@example.com"), value)
+
+	value, count = rules[3].redact("ID-1234")
+	assert.Equal(t, 1, count)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z]{2}-\d{4}$`), value)
+
+	value, count = rules[4].redact("no match")
+	assert.Zero(t, count)
+	assert.Equal(t, "no match", value)
+}
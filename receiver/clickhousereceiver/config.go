@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+// Config defines configuration for the ClickHouse receiver.
+type Config struct {
+	// Endpoint is the clickhouse endpoint.
+	Endpoint string `mapstructure:"endpoint"`
+	// Username is the authentication username.
+	Username string `mapstructure:"username"`
+	// Password is the authentication password.
+	Password configopaque.String `mapstructure:"password"`
+	// Database is the database name the tables are read from.
+	Database string `mapstructure:"database"`
+	// ConnectionParams is the extra connection parameters with map format. for example compression/dial_timeout
+	ConnectionParams map[string]string `mapstructure:"connection_params"`
+	// LogsTableName is the table name logs are read from. default is `otel_logs`.
+	LogsTableName string `mapstructure:"logs_table_name"`
+	// TracesTableName is the table name traces are read from. default is `otel_traces`.
+	TracesTableName string `mapstructure:"traces_table_name"`
+	// MetricsTables defines the table names metrics are read from, by metric type.
+	MetricsTables MetricsTablesConfig `mapstructure:"metrics_tables"`
+	// StartTime is the time the rows are read from. The rows are read from the oldest one when it isn't set.
+	StartTime string `mapstructure:"start_time"`
+	// EndTime is the time the rows are read until. The new rows are read as they are inserted when it isn't set.
+	EndTime string `mapstructure:"end_time"`
+	// Window is the time range of the rows read by each query. default is `1m`.
+	Window time.Duration `mapstructure:"window"`
+	// PollInterval is the interval between two reads of the rows inserted since the previous one. default is `30s`.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Delay is how long the rows are waited for before reading a window, so that the rows inserted late are read.
+	// default is `1m`.
+	Delay time.Duration `mapstructure:"delay"`
+	// MaxRows is the maximum number of rows read by each query, the windows holding more rows being shortened.
+	// The rows of a single time are read at once. There is no maximum when it is 0. default is `10000`.
+	MaxRows int `mapstructure:"max_rows"`
+	// StorageID is the ID of the storage extension saving the progress of the receiver.
+	StorageID *component.ID `mapstructure:"storage"`
+}
+
+// MetricsTablesConfig defines the table names of the metric types.
+type MetricsTablesConfig struct {
+	// Gauge is the table name for gauge metric type. default is `otel_metrics_gauge`.
+	Gauge TableConfig `mapstructure:"gauge"`
+	// Sum is the table name for sum metric type. default is `otel_metrics_sum`.
+	Sum TableConfig `mapstructure:"sum"`
+	// Summary is the table name for summary metric type. default is `otel_metrics_summary`.
+	Summary TableConfig `mapstructure:"summary"`
+	// Histogram is the table name for histogram metric type. default is `otel_metrics_histogram`.
+	Histogram TableConfig `mapstructure:"histogram"`
+	// ExponentialHistogram is the table name for exponential histogram metric type. default is `otel_metrics_exponential_histogram`.
+	ExponentialHistogram TableConfig `mapstructure:"exponential_histogram"`
+}
+
+// TableConfig defines a table.
+type TableConfig struct {
+	Name string `mapstructure:"name"`
+}
+
+const defaultDatabase = "default"
+
+var (
+	errConfigNoEndpoint      = errors.New("endpoint must be specified")
+	errConfigInvalidEndpoint = errors.New("endpoint must be url format")
+)
+
+// Validate the ClickHouse receiver configuration.
+func (cfg *Config) Validate() (err error) {
+	if cfg.Endpoint == "" {
+		err = errors.Join(err, errConfigNoEndpoint)
+	}
+	dsn, e := cfg.buildDSN()
+	if e != nil {
+		err = errors.Join(err, e)
+	} else if cfg.Endpoint != "" {
+		if _, e := clickhouse.ParseDSN(dsn); e != nil {
+			err = errors.Join(err, e)
+		}
+	}
+
+	for _, table := range []struct{ setting, name string }{
+		{"logs_table_name", cfg.LogsTableName},
+		{"traces_table_name", cfg.TracesTableName},
+		{"metrics_tables::gauge::name", cfg.MetricsTables.Gauge.Name},
+		{"metrics_tables::sum::name", cfg.MetricsTables.Sum.Name},
+		{"metrics_tables::summary::name", cfg.MetricsTables.Summary.Name},
+		{"metrics_tables::histogram::name", cfg.MetricsTables.Histogram.Name},
+		{"metrics_tables::exponential_histogram::name", cfg.MetricsTables.ExponentialHistogram.Name},
+	} {
+		if table.name == "" {
+			err = errors.Join(err, fmt.Errorf("%s must be specified", table.setting))
+		}
+	}
+
+	startTime, e := parseTime(cfg.StartTime, "start_time")
+	err = errors.Join(err, e)
+	endTime, e := parseTime(cfg.EndTime, "end_time")
+	err = errors.Join(err, e)
+	if !startTime.IsZero() && !endTime.IsZero() && !endTime.After(startTime) {
+		err = errors.Join(err, errors.New("end_time must be after start_time"))
+	}
+
+	if cfg.Window <= 0 {
+		err = errors.Join(err, errors.New("window must be positive"))
+	}
+	if cfg.PollInterval <= 0 {
+		err = errors.Join(err, errors.New("poll_interval must be positive"))
+	}
+	if cfg.Delay < 0 {
+		err = errors.Join(err, errors.New("delay must not be negative"))
+	}
+	if cfg.MaxRows < 0 {
+		err = errors.Join(err, errors.New("max_rows must not be negative"))
+	}
+	return err
+}
+
+// parseTime parses a time of the configuration. An empty time is the zero time.
+func parseTime(s, name string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s must be a RFC 3339 time, or a date and time or date in UTC: %q", name, s)
+}
+
+func (cfg *Config) buildDSN() (string, error) {
+	dsnURL, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errConfigInvalidEndpoint, err.Error())
+	}
+
+	queryParams := dsnURL.Query()
+
+	// Add connection params to query params.
+	for k, v := range cfg.ConnectionParams {
+		queryParams.Set(k, v)
+	}
+
+	// Enable TLS if scheme is https. This flag is necessary to support https connections.
+	if dsnURL.Scheme == "https" {
+		queryParams.Set("secure", "true")
+	}
+
+	// Use database from config if not specified in path, or if config is not default.
+	if dsnURL.Path == "" || cfg.Database != defaultDatabase {
+		dsnURL.Path = cfg.Database
+	}
+
+	// Override username and password if specified in config.
+	if cfg.Username != "" {
+		dsnURL.User = url.UserPassword(cfg.Username, string(cfg.Password))
+	}
+
+	dsnURL.RawQuery = queryParams.Encode()
+
+	return dsnURL.String(), nil
+}
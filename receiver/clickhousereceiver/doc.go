@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package clickhousereceiver reads back the logs, traces and metrics written to ClickHouse by the ClickHouse
+// exporter.
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+)
+
+// NewFactory creates a factory for the ClickHouse receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability),
+		receiver.WithTraces(createTracesReceiver, metadata.TracesStability),
+		receiver.WithMetrics(createMetricsReceiver, metadata.MetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ConnectionParams: map[string]string{},
+		Database:         defaultDatabase,
+		LogsTableName:    "otel_logs",
+		TracesTableName:  "otel_traces",
+		MetricsTables: MetricsTablesConfig{
+			Gauge:                TableConfig{Name: "otel_metrics_gauge"},
+			Sum:                  TableConfig{Name: "otel_metrics_sum"},
+			Summary:              TableConfig{Name: "otel_metrics_summary"},
+			Histogram:            TableConfig{Name: "otel_metrics_histogram"},
+			ExponentialHistogram: TableConfig{Name: "otel_metrics_exponential_histogram"},
+		},
+		Window:       time.Minute,
+		PollInterval: 30 * time.Second,
+		Delay:        time.Minute,
+		MaxRows:      10000,
+	}
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	next consumer.Logs,
+) (receiver.Logs, error) {
+	return newLogsReceiver(cfg.(*Config), set, next)
+}
+
+func createTracesReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	next consumer.Traces,
+) (receiver.Traces, error) {
+	return newTracesReceiver(cfg.(*Config), set, next)
+}
+
+func createMetricsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	next consumer.Metrics,
+) (receiver.Metrics, error) {
+	return newMetricsReceiver(cfg.(*Config), set, next)
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+)
+
+// language=ClickHouse SQL
+const selectLogsSQL = `SELECT
+	Timestamp,
+	TraceId,
+	SpanId,
+	TraceFlags,
+	SeverityText,
+	SeverityNumber,
+	Body,
+	ResourceSchemaUrl,
+	ResourceAttributes,
+	ScopeSchemaUrl,
+	ScopeName,
+	ScopeVersion,
+	ScopeAttributes,
+	LogAttributes
+FROM %s
+WHERE %s
+ORDER BY Timestamp`
+
+func newLogsReceiver(cfg *Config, set receiver.Settings, next consumer.Logs) (*clickhouseReceiver, error) {
+	r, err := newReceiver(cfg, set, "logs")
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(selectLogsSQL, cfg.LogsTableName, windowCondition("Timestamp"))
+	r.readers = []tableReader{{
+		table:      cfg.LogsTableName,
+		timeColumn: "Timestamp",
+		read: func(ctx context.Context, db *sql.DB, from, to time.Time) (int, error) {
+			logs, err := selectLogs(ctx, db, query, from, to, r.skipRows(ctx, cfg.LogsTableName))
+			if err != nil {
+				return 0, fmt.Errorf("select logs of table %s: %w", cfg.LogsTableName, err)
+			}
+			count := logs.LogRecordCount()
+			if count == 0 {
+				return 0, nil
+			}
+			obsCtx := r.obsrecv.StartLogsOp(ctx)
+			err = next.ConsumeLogs(obsCtx, logs)
+			r.obsrecv.EndLogsOp(obsCtx, metadata.Type.String(), count, err)
+			return count, err
+		},
+	}}
+	return r, nil
+}
+
+// selectLogs reads the log records of a window, grouped by resource and scope. The rows which can't be converted
+// are passed to skip.
+func selectLogs(ctx context.Context, db *sql.DB, query string, from, to time.Time, skip rowSkipper) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	rows, err := db.QueryContext(ctx, query, from.UnixNano(), to.UnixNano())
+	if err != nil {
+		return logs, err
+	}
+	defer rows.Close()
+
+	resourceLogs := map[string]plog.ResourceLogs{}
+	scopeLogs := map[string]plog.ScopeLogs{}
+	for rows.Next() {
+		var (
+			r              resourceRow
+			ts             time.Time
+			traceIDHex     string
+			spanIDHex      string
+			flags          uint8
+			severityText   string
+			severityNumber uint8
+			body           string
+			attributes     map[string]string
+		)
+		if err := rows.Scan(&ts, &traceIDHex, &spanIDHex, &flags, &severityText, &severityNumber, &body,
+			&r.resourceSchemaURL, &r.resourceAttributes, &r.scopeSchemaURL, &r.scopeName, &r.scopeVersion,
+			&r.scopeAttributes, &attributes); err != nil {
+			return logs, err
+		}
+		tid, err := traceID(traceIDHex)
+		if err != nil {
+			skip(ts, err)
+			continue
+		}
+		sid, err := spanID(spanIDHex)
+		if err != nil {
+			skip(ts, err)
+			continue
+		}
+
+		rl, added := getOrAppend(resourceLogs, r.resourceKey(), logs.ResourceLogs().AppendEmpty)
+		if added {
+			rl.SetSchemaUrl(r.resourceSchemaURL)
+			r.copyResource(rl.Resource())
+		}
+		sl, added := getOrAppend(scopeLogs, r.scopeKey(), rl.ScopeLogs().AppendEmpty)
+		if added {
+			sl.SetSchemaUrl(r.scopeSchemaURL)
+			r.copyScope(sl.Scope())
+		}
+
+		record := sl.LogRecords().AppendEmpty()
+		record.SetTimestamp(timestamp(ts))
+		record.SetTraceID(tid)
+		record.SetSpanID(sid)
+		record.SetFlags(plog.LogRecordFlags(flags))
+		record.SetSeverityText(severityText)
+		record.SetSeverityNumber(plog.SeverityNumber(severityNumber))
+		if body != "" {
+			record.Body().SetStr(body)
+		}
+		putAttributes(record.Attributes(), attributes)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// openTestDB opens a database served by the test server.
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open(driverName, "clickhouse://127.0.0.1:9000")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	return db
+}
+
+func TestSelectLogs(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	resource := map[string]string{"service.name": "test"}
+	row := func(ts time.Time, body string, resource map[string]string, scopeName string) testRow {
+		return testRow{time: ts, values: []driver.Value{
+			ts, "0102030405060708090a0b0c0d0e0f10", "0102030405060708", uint8(1), "Info", uint8(9), body,
+			"https://opentelemetry.io/schemas/1.4.0", resource, "", scopeName, "1.0.0", map[string]string{"lib": "x"},
+			map[string]string{"http.method": "GET"},
+		}}
+	}
+	server.insert("otel_logs",
+		row(testStart, "a", resource, "io.opentelemetry.contrib.clickhouse"),
+		row(testStart.Add(time.Second), "b", map[string]string{"service.name": "other"}, "io.opentelemetry.contrib.clickhouse"),
+		row(testStart.Add(2*time.Second), "c", resource, "io.opentelemetry.contrib.clickhouse"),
+		row(testStart.Add(3*time.Second), "", resource, "other"),
+	)
+
+	query := fmt.Sprintf(selectLogsSQL, "otel_logs", windowCondition("Timestamp"))
+	logs, err := selectLogs(context.Background(), openTestDB(t), query, testStart, testStart.Add(time.Minute), failSkip(t))
+	require.NoError(t, err)
+
+	// The records are grouped by resource and scope
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.4.0", rl.SchemaUrl())
+	assert.Equal(t, map[string]any{"service.name": "test"}, rl.Resource().Attributes().AsRaw())
+	require.Equal(t, 2, rl.ScopeLogs().Len())
+	sl := rl.ScopeLogs().At(0)
+	assert.Equal(t, "io.opentelemetry.contrib.clickhouse", sl.Scope().Name())
+	assert.Equal(t, "1.0.0", sl.Scope().Version())
+	assert.Equal(t, map[string]any{"lib": "x"}, sl.Scope().Attributes().AsRaw())
+	require.Equal(t, 2, sl.LogRecords().Len())
+	assert.Equal(t, "c", sl.LogRecords().At(1).Body().Str())
+	assert.Equal(t, "other", rl.ScopeLogs().At(1).Scope().Name())
+	assert.Equal(t, pcommon.ValueTypeEmpty, rl.ScopeLogs().At(1).LogRecords().At(0).Body().Type())
+	assert.Equal(t, map[string]any{"service.name": "other"}, logs.ResourceLogs().At(1).Resource().Attributes().AsRaw())
+
+	record := sl.LogRecords().At(0)
+	assert.Equal(t, pcommon.NewTimestampFromTime(testStart), record.Timestamp())
+	assert.Equal(t, pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, record.TraceID())
+	assert.Equal(t, pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, record.SpanID())
+	assert.Equal(t, plog.LogRecordFlags(1), record.Flags())
+	assert.Equal(t, "Info", record.SeverityText())
+	assert.Equal(t, plog.SeverityNumberInfo, record.SeverityNumber())
+	assert.Equal(t, "a", record.Body().Str())
+	assert.Equal(t, map[string]any{"http.method": "GET"}, record.Attributes().AsRaw())
+}
+
+func TestSelectLogs_invalidID(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	invalid := testLogRow(testStart.Add(time.Second), "b")
+	invalid.values[1] = "invalid"
+	server.insert("otel_logs", testLogRow(testStart, "a"), invalid, testLogRow(testStart.Add(2*time.Second), "c"))
+
+	query := fmt.Sprintf(selectLogsSQL, "otel_logs", windowCondition("Timestamp"))
+	skipped := &skippedRows{}
+	logs, err := selectLogs(context.Background(), openTestDB(t), query, testStart, testStart.Add(time.Minute), skipped.skip)
+	require.NoError(t, err)
+
+	// The row with an invalid ID is skipped, the next rows are read
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	assert.Equal(t, "a", records.At(0).Body().Str())
+	assert.Equal(t, "c", records.At(1).Body().Str())
+	assert.Equal(t, []time.Time{testStart.Add(time.Second)}, skipped.times)
+	assert.EqualError(t, skipped.err, `invalid ID "invalid": encoding/hex: invalid byte: U+0069 'i'`)
+}
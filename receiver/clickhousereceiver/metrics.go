@@ -0,0 +1,457 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+)
+
+// language=ClickHouse SQL
+const selectMetricsSQL = `SELECT
+	ResourceAttributes,
+	ResourceSchemaUrl,
+	ScopeName,
+	ScopeVersion,
+	ScopeAttributes,
+	ScopeDroppedAttrCount,
+	ScopeSchemaUrl,
+	MetricName,
+	MetricDescription,
+	MetricUnit,
+	Attributes,
+	StartTimeUnix,
+	TimeUnix,
+	Flags,
+	%s
+FROM %s
+WHERE %s
+ORDER BY TimeUnix`
+
+const (
+	// language=ClickHouse SQL
+	exemplarsColumns = `Exemplars.FilteredAttributes,
+	Exemplars.TimeUnix,
+	Exemplars.Value,
+	Exemplars.SpanId,
+	Exemplars.TraceId`
+	// language=ClickHouse SQL
+	gaugeColumns = `Value,
+	` + exemplarsColumns
+	// language=ClickHouse SQL
+	sumColumns = `Value,
+	` + exemplarsColumns + `,
+	AggregationTemporality,
+	IsMonotonic`
+	// language=ClickHouse SQL
+	histogramColumns = `Count,
+	Sum,
+	BucketCounts,
+	ExplicitBounds,
+	` + exemplarsColumns + `,
+	Min,
+	Max,
+	AggregationTemporality`
+	// language=ClickHouse SQL
+	expHistogramColumns = `Count,
+	Sum,
+	Scale,
+	ZeroCount,
+	PositiveOffset,
+	PositiveBucketCounts,
+	NegativeOffset,
+	NegativeBucketCounts,
+	` + exemplarsColumns + `,
+	Min,
+	Max,
+	AggregationTemporality`
+	// language=ClickHouse SQL
+	summaryColumns = `Count,
+	Sum,
+	ValueAtQuantiles.Quantile,
+	ValueAtQuantiles.Value`
+)
+
+// dataPointRow holds the columns of a data point specific to a metric type.
+type dataPointRow interface {
+	// dest returns the scan destinations of the columns.
+	dest() []any
+	// decode decodes the columns which can be invalid, before the data point is appended.
+	decode() error
+	// appendTo appends the data point to the metrics, in the metric of the row.
+	appendTo(b *metricsBuilder, r *metricRow)
+}
+
+func newMetricsReceiver(cfg *Config, set receiver.Settings, next consumer.Metrics) (*clickhouseReceiver, error) {
+	r, err := newReceiver(cfg, set, "metrics")
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range []struct {
+		name    string
+		columns string
+		newRow  func() dataPointRow
+	}{
+		{cfg.MetricsTables.Gauge.Name, gaugeColumns, func() dataPointRow { return &gaugeRow{} }},
+		{cfg.MetricsTables.Sum.Name, sumColumns, func() dataPointRow { return &sumRow{} }},
+		{cfg.MetricsTables.Histogram.Name, histogramColumns, func() dataPointRow { return &histogramRow{} }},
+		{cfg.MetricsTables.ExponentialHistogram.Name, expHistogramColumns, func() dataPointRow { return &expHistogramRow{} }},
+		{cfg.MetricsTables.Summary.Name, summaryColumns, func() dataPointRow { return &summaryRow{} }},
+	} {
+		query := fmt.Sprintf(selectMetricsSQL, table.columns, table.name, windowCondition("TimeUnix"))
+		r.readers = append(r.readers, tableReader{
+			table:      table.name,
+			timeColumn: "TimeUnix",
+			read: func(ctx context.Context, db *sql.DB, from, to time.Time) (int, error) {
+				metrics, err := selectMetrics(ctx, db, query, table.newRow, from, to, r.skipRows(ctx, table.name))
+				if err != nil {
+					return 0, fmt.Errorf("select metrics of table %s: %w", table.name, err)
+				}
+				count := metrics.DataPointCount()
+				if count == 0 {
+					return 0, nil
+				}
+				obsCtx := r.obsrecv.StartMetricsOp(ctx)
+				err = next.ConsumeMetrics(obsCtx, metrics)
+				r.obsrecv.EndMetricsOp(obsCtx, metadata.Type.String(), count, err)
+				return count, err
+			},
+		})
+	}
+	return r, nil
+}
+
+// selectMetrics reads the data points of a window, grouped by resource, scope and metric. The rows which can't be
+// converted are passed to skip.
+func selectMetrics(ctx context.Context, db *sql.DB, query string, newRow func() dataPointRow, from, to time.Time, skip rowSkipper) (pmetric.Metrics, error) {
+	b := newMetricsBuilder()
+	rows, err := db.QueryContext(ctx, query, from.UnixNano(), to.UnixNano())
+	if err != nil {
+		return b.metrics, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r metricRow
+		point := newRow()
+		if err := rows.Scan(append(r.dest(), point.dest()...)...); err != nil {
+			return b.metrics, err
+		}
+		if err := point.decode(); err != nil {
+			skip(r.time, err)
+			continue
+		}
+		point.appendTo(b, &r)
+	}
+	return b.metrics, rows.Err()
+}
+
+// metricRow holds the columns common to the metric types.
+type metricRow struct {
+	resourceRow
+	name        string
+	description string
+	unit        string
+	attributes  map[string]string
+	startTime   time.Time
+	time        time.Time
+	flags       uint32
+}
+
+func (r *metricRow) dest() []any {
+	return []any{
+		&r.resourceAttributes, &r.resourceSchemaURL, &r.scopeName, &r.scopeVersion, &r.scopeAttributes, &r.scopeDropped,
+		&r.scopeSchemaURL, &r.name, &r.description, &r.unit, &r.attributes, &r.startTime, &r.time, &r.flags,
+	}
+}
+
+// dataPoint is implemented by the data points of the metric types.
+type dataPoint interface {
+	Attributes() pcommon.Map
+	SetStartTimestamp(pcommon.Timestamp)
+	SetTimestamp(pcommon.Timestamp)
+	SetFlags(pmetric.DataPointFlags)
+}
+
+// copyDataPoint copies the common columns to a data point.
+func (r *metricRow) copyDataPoint(dp dataPoint) {
+	putAttributes(dp.Attributes(), r.attributes)
+	dp.SetStartTimestamp(timestamp(r.startTime))
+	dp.SetTimestamp(timestamp(r.time))
+	dp.SetFlags(pmetric.DataPointFlags(r.flags))
+}
+
+// metricsBuilder groups the data points by resource, scope and metric.
+type metricsBuilder struct {
+	metrics         pmetric.Metrics
+	resourceMetrics map[string]pmetric.ResourceMetrics
+	scopeMetrics    map[string]pmetric.ScopeMetrics
+	metricsByKey    map[string]pmetric.Metric
+}
+
+func newMetricsBuilder() *metricsBuilder {
+	return &metricsBuilder{
+		metrics:         pmetric.NewMetrics(),
+		resourceMetrics: map[string]pmetric.ResourceMetrics{},
+		scopeMetrics:    map[string]pmetric.ScopeMetrics{},
+		metricsByKey:    map[string]pmetric.Metric{},
+	}
+}
+
+// metric returns the metric of the row, which is appended when it doesn't exist. The metrics of the same name
+// are distinct when their type or the properties of their type, in typeKey, differ.
+func (b *metricsBuilder) metric(r *metricRow, typeKey string) (pmetric.Metric, bool) {
+	rm, added := getOrAppend(b.resourceMetrics, r.resourceKey(), b.metrics.ResourceMetrics().AppendEmpty)
+	if added {
+		rm.SetSchemaUrl(r.resourceSchemaURL)
+		r.copyResource(rm.Resource())
+	}
+	scopeKey := r.scopeKey()
+	sm, added := getOrAppend(b.scopeMetrics, scopeKey, rm.ScopeMetrics().AppendEmpty)
+	if added {
+		sm.SetSchemaUrl(r.scopeSchemaURL)
+		r.copyScope(sm.Scope())
+	}
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", scopeKey, r.name, r.description, r.unit, typeKey)
+	m, added := getOrAppend(b.metricsByKey, key, sm.Metrics().AppendEmpty)
+	if added {
+		m.SetName(r.name)
+		m.SetDescription(r.description)
+		m.SetUnit(r.unit)
+	}
+	return m, added
+}
+
+// exemplarsRow holds the exemplars of a data point.
+type exemplarsRow struct {
+	attributes []map[string]string
+	times      []time.Time
+	values     []float64
+	spanIDs    []string
+	traceIDs   []string
+
+	decodedSpanIDs  []pcommon.SpanID
+	decodedTraceIDs []pcommon.TraceID
+}
+
+func (e *exemplarsRow) dest() []any {
+	return []any{&e.attributes, &e.times, &e.values, &e.spanIDs, &e.traceIDs}
+}
+
+func (e *exemplarsRow) decode() (err error) {
+	e.decodedTraceIDs = make([]pcommon.TraceID, len(e.traceIDs))
+	for i := range e.traceIDs {
+		if e.decodedTraceIDs[i], err = traceID(e.traceIDs[i]); err != nil {
+			return err
+		}
+	}
+	e.decodedSpanIDs = make([]pcommon.SpanID, len(e.spanIDs))
+	for i := range e.spanIDs {
+		if e.decodedSpanIDs[i], err = spanID(e.spanIDs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exemplarsRow) copyTo(exemplars pmetric.ExemplarSlice) {
+	for i := range e.values {
+		exemplar := exemplars.AppendEmpty()
+		exemplar.SetDoubleValue(e.values[i])
+		if i < len(e.attributes) {
+			putAttributes(exemplar.FilteredAttributes(), e.attributes[i])
+		}
+		if i < len(e.times) {
+			exemplar.SetTimestamp(timestamp(e.times[i]))
+		}
+		if i < len(e.decodedTraceIDs) {
+			exemplar.SetTraceID(e.decodedTraceIDs[i])
+		}
+		if i < len(e.decodedSpanIDs) {
+			exemplar.SetSpanID(e.decodedSpanIDs[i])
+		}
+	}
+}
+
+type gaugeRow struct {
+	value     float64
+	exemplars exemplarsRow
+}
+
+func (g *gaugeRow) dest() []any {
+	return append([]any{&g.value}, g.exemplars.dest()...)
+}
+
+func (g *gaugeRow) decode() error {
+	return g.exemplars.decode()
+}
+
+func (g *gaugeRow) appendTo(b *metricsBuilder, r *metricRow) {
+	m, added := b.metric(r, "gauge")
+	if added {
+		m.SetEmptyGauge()
+	}
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	r.copyDataPoint(dp)
+	dp.SetDoubleValue(g.value)
+	g.exemplars.copyTo(dp.Exemplars())
+}
+
+type sumRow struct {
+	value       float64
+	exemplars   exemplarsRow
+	temporality int32
+	monotonic   bool
+}
+
+func (s *sumRow) dest() []any {
+	return append(append([]any{&s.value}, s.exemplars.dest()...), &s.temporality, &s.monotonic)
+}
+
+func (s *sumRow) decode() error {
+	return s.exemplars.decode()
+}
+
+func (s *sumRow) appendTo(b *metricsBuilder, r *metricRow) {
+	m, added := b.metric(r, fmt.Sprintf("sum\x00%d\x00%t", s.temporality, s.monotonic))
+	if added {
+		sum := m.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporality(s.temporality))
+		sum.SetIsMonotonic(s.monotonic)
+	}
+	dp := m.Sum().DataPoints().AppendEmpty()
+	r.copyDataPoint(dp)
+	dp.SetDoubleValue(s.value)
+	s.exemplars.copyTo(dp.Exemplars())
+}
+
+type histogramRow struct {
+	count          uint64
+	sum            float64
+	bucketCounts   []uint64
+	explicitBounds []float64
+	exemplars      exemplarsRow
+	min            float64
+	max            float64
+	temporality    int32
+}
+
+func (h *histogramRow) dest() []any {
+	return append(append([]any{&h.count, &h.sum, &h.bucketCounts, &h.explicitBounds}, h.exemplars.dest()...),
+		&h.min, &h.max, &h.temporality)
+}
+
+func (h *histogramRow) decode() error {
+	return h.exemplars.decode()
+}
+
+func (h *histogramRow) appendTo(b *metricsBuilder, r *metricRow) {
+	m, added := b.metric(r, fmt.Sprintf("histogram\x00%d", h.temporality))
+	if added {
+		m.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporality(h.temporality))
+	}
+	dp := m.Histogram().DataPoints().AppendEmpty()
+	r.copyDataPoint(dp)
+	dp.SetCount(h.count)
+	dp.SetSum(h.sum)
+	dp.BucketCounts().FromRaw(h.bucketCounts)
+	dp.ExplicitBounds().FromRaw(h.explicitBounds)
+	// The min and max which aren't set are stored as 0.
+	if h.min != 0 || h.max != 0 {
+		dp.SetMin(h.min)
+		dp.SetMax(h.max)
+	}
+	h.exemplars.copyTo(dp.Exemplars())
+}
+
+type expHistogramRow struct {
+	count                uint64
+	sum                  float64
+	scale                int32
+	zeroCount            uint64
+	positiveOffset       int32
+	positiveBucketCounts []uint64
+	negativeOffset       int32
+	negativeBucketCounts []uint64
+	exemplars            exemplarsRow
+	min                  float64
+	max                  float64
+	temporality          int32
+}
+
+func (h *expHistogramRow) dest() []any {
+	return append(append([]any{
+		&h.count, &h.sum, &h.scale, &h.zeroCount,
+		&h.positiveOffset, &h.positiveBucketCounts, &h.negativeOffset, &h.negativeBucketCounts,
+	}, h.exemplars.dest()...), &h.min, &h.max, &h.temporality)
+}
+
+func (h *expHistogramRow) decode() error {
+	return h.exemplars.decode()
+}
+
+func (h *expHistogramRow) appendTo(b *metricsBuilder, r *metricRow) {
+	m, added := b.metric(r, fmt.Sprintf("exponential_histogram\x00%d", h.temporality))
+	if added {
+		m.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporality(h.temporality))
+	}
+	dp := m.ExponentialHistogram().DataPoints().AppendEmpty()
+	r.copyDataPoint(dp)
+	dp.SetCount(h.count)
+	dp.SetSum(h.sum)
+	dp.SetScale(h.scale)
+	dp.SetZeroCount(h.zeroCount)
+	dp.Positive().SetOffset(h.positiveOffset)
+	dp.Positive().BucketCounts().FromRaw(h.positiveBucketCounts)
+	dp.Negative().SetOffset(h.negativeOffset)
+	dp.Negative().BucketCounts().FromRaw(h.negativeBucketCounts)
+	// The min and max which aren't set are stored as 0.
+	if h.min != 0 || h.max != 0 {
+		dp.SetMin(h.min)
+		dp.SetMax(h.max)
+	}
+	h.exemplars.copyTo(dp.Exemplars())
+}
+
+type summaryRow struct {
+	count     uint64
+	sum       float64
+	quantiles []float64
+	values    []float64
+}
+
+func (s *summaryRow) dest() []any {
+	return []any{&s.count, &s.sum, &s.quantiles, &s.values}
+}
+
+func (*summaryRow) decode() error {
+	return nil
+}
+
+func (s *summaryRow) appendTo(b *metricsBuilder, r *metricRow) {
+	m, added := b.metric(r, "summary")
+	if added {
+		m.SetEmptySummary()
+	}
+	dp := m.Summary().DataPoints().AppendEmpty()
+	r.copyDataPoint(dp)
+	dp.SetCount(s.count)
+	dp.SetSum(s.sum)
+	for i := range s.quantiles {
+		quantile := dp.QuantileValues().AppendEmpty()
+		quantile.SetQuantile(s.quantiles[i])
+		if i < len(s.values) {
+			quantile.SetValue(s.values[i])
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+)
+
+// testMetricRow returns a row of a metrics table, with the columns of the data point.
+func testMetricRow(t time.Time, name string, attributes map[string]string, values ...driver.Value) testRow {
+	return testRow{time: t, values: append([]driver.Value{
+		map[string]string{"service.name": "test"}, "", "io.opentelemetry.contrib.clickhouse", "1.0.0", map[string]string{},
+		uint32(0), "", name, "description", "1", attributes, testStart, t, uint32(0),
+	}, values...)}
+}
+
+// testExemplars returns the exemplars columns with an exemplar of the value.
+func testExemplars(value float64) []driver.Value {
+	return []driver.Value{
+		[]map[string]string{{"k": "v"}}, []time.Time{testStart}, []float64{value},
+		[]string{"0102030405060708"}, []string{"0102030405060708090a0b0c0d0e0f10"},
+	}
+}
+
+func noExemplars() []driver.Value {
+	return []driver.Value{[]map[string]string{}, []time.Time{}, []float64{}, []string{}, []string{}}
+}
+
+func TestMetricsReceiver(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	gaugeValues := func(value float64) []driver.Value {
+		return append([]driver.Value{value}, noExemplars()...)
+	}
+	server.insert("otel_metrics_gauge",
+		testMetricRow(testStart, "gauge", map[string]string{"a": "1"}, append([]driver.Value{1.5}, testExemplars(2)...)...),
+		testMetricRow(testStart, "gauge", map[string]string{"a": "2"}, gaugeValues(2.5)...),
+		testMetricRow(testStart, "other", map[string]string{}, gaugeValues(3.5)...),
+	)
+	server.insert("otel_metrics_sum",
+		testMetricRow(testStart, "sum", map[string]string{}, append(append([]driver.Value{4.0}, noExemplars()...),
+			int32(pmetric.AggregationTemporalityCumulative), true)...),
+		// The data points of different temporalities are in different metrics
+		testMetricRow(testStart, "sum", map[string]string{}, append(append([]driver.Value{5.0}, noExemplars()...),
+			int32(pmetric.AggregationTemporalityDelta), true)...),
+	)
+	server.insert("otel_metrics_histogram",
+		testMetricRow(testStart, "histogram", map[string]string{}, append(append([]driver.Value{
+			uint64(3), 6.0, []uint64{1, 2}, []float64{2},
+		}, noExemplars()...), 1.0, 3.0, int32(pmetric.AggregationTemporalityDelta))...),
+		testMetricRow(testStart.Add(time.Second), "histogram", map[string]string{}, append(append([]driver.Value{
+			uint64(0), 0.0, []uint64{0, 0}, []float64{2},
+		}, noExemplars()...), 0.0, 0.0, int32(pmetric.AggregationTemporalityDelta))...),
+	)
+	server.insert("otel_metrics_exponential_histogram",
+		testMetricRow(testStart, "exponential_histogram", map[string]string{}, append(append([]driver.Value{
+			uint64(4), 10.0, int32(2), uint64(1), int32(1), []uint64{1, 1}, int32(-1), []uint64{1},
+		}, noExemplars()...), -1.0, 8.0, int32(pmetric.AggregationTemporalityCumulative))...),
+	)
+	server.insert("otel_metrics_summary",
+		testMetricRow(testStart, "summary", map[string]string{}, uint64(2), 3.0, []float64{0.5, 1}, []float64{1, 2}),
+	)
+
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+	cfg.StartTime = "2025-01-01"
+	sink := new(consumertest.MetricsSink)
+	r, err := newMetricsReceiver(cfg, receivertest.NewNopSettings(metadata.Type), sink)
+	require.NoError(t, err)
+	require.Len(t, r.readers, 5)
+	readers := r.readers
+	r.readers = nil
+	require.NoError(t, r.Start(context.Background(), storagetest.NewStorageHost()))
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+	for _, reader := range readers {
+		require.NoError(t, r.poll(context.Background(), reader, testStart.Add(time.Hour)))
+	}
+
+	// A batch of metrics is sent for each table
+	require.Len(t, sink.AllMetrics(), 5)
+	metrics := func(i int) pmetric.MetricSlice {
+		rms := sink.AllMetrics()[i].ResourceMetrics()
+		require.Equal(t, 1, rms.Len())
+		assert.Equal(t, map[string]any{"service.name": "test"}, rms.At(0).Resource().Attributes().AsRaw())
+		require.Equal(t, 1, rms.At(0).ScopeMetrics().Len())
+		assert.Equal(t, "io.opentelemetry.contrib.clickhouse", rms.At(0).ScopeMetrics().At(0).Scope().Name())
+		return rms.At(0).ScopeMetrics().At(0).Metrics()
+	}
+
+	gauges := metrics(0)
+	require.Equal(t, 2, gauges.Len())
+	gauge := gauges.At(0)
+	assert.Equal(t, "gauge", gauge.Name())
+	assert.Equal(t, "description", gauge.Description())
+	assert.Equal(t, "1", gauge.Unit())
+	require.Equal(t, 2, gauge.Gauge().DataPoints().Len())
+	dp := gauge.Gauge().DataPoints().At(0)
+	assert.Equal(t, map[string]any{"a": "1"}, dp.Attributes().AsRaw())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testStart), dp.StartTimestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testStart), dp.Timestamp())
+	assert.Equal(t, 1.5, dp.DoubleValue())
+	require.Equal(t, 1, dp.Exemplars().Len())
+	exemplar := dp.Exemplars().At(0)
+	assert.Equal(t, 2.0, exemplar.DoubleValue())
+	assert.Equal(t, map[string]any{"k": "v"}, exemplar.FilteredAttributes().AsRaw())
+	assert.Equal(t, pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, exemplar.SpanID())
+	assert.Equal(t, pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, exemplar.TraceID())
+	assert.Equal(t, 2.5, gauge.Gauge().DataPoints().At(1).DoubleValue())
+	assert.Equal(t, "other", gauges.At(1).Name())
+
+	sums := metrics(1)
+	require.Equal(t, 2, sums.Len())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, sums.At(0).Sum().AggregationTemporality())
+	assert.True(t, sums.At(0).Sum().IsMonotonic())
+	assert.Equal(t, 4.0, sums.At(0).Sum().DataPoints().At(0).DoubleValue())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, sums.At(1).Sum().AggregationTemporality())
+
+	histograms := metrics(2)
+	require.Equal(t, 1, histograms.Len())
+	histogram := histograms.At(0).Histogram()
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, histogram.AggregationTemporality())
+	require.Equal(t, 2, histogram.DataPoints().Len())
+	hdp := histogram.DataPoints().At(0)
+	assert.Equal(t, uint64(3), hdp.Count())
+	assert.Equal(t, 6.0, hdp.Sum())
+	assert.Equal(t, []uint64{1, 2}, hdp.BucketCounts().AsRaw())
+	assert.Equal(t, []float64{2}, hdp.ExplicitBounds().AsRaw())
+	assert.Equal(t, 1.0, hdp.Min())
+	assert.Equal(t, 3.0, hdp.Max())
+	assert.False(t, histogram.DataPoints().At(1).HasMin())
+	assert.False(t, histogram.DataPoints().At(1).HasMax())
+
+	expHistograms := metrics(3)
+	require.Equal(t, 1, expHistograms.Len())
+	expHistogram := expHistograms.At(0).ExponentialHistogram()
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, expHistogram.AggregationTemporality())
+	edp := expHistogram.DataPoints().At(0)
+	assert.Equal(t, uint64(4), edp.Count())
+	assert.Equal(t, 10.0, edp.Sum())
+	assert.Equal(t, int32(2), edp.Scale())
+	assert.Equal(t, uint64(1), edp.ZeroCount())
+	assert.Equal(t, int32(1), edp.Positive().Offset())
+	assert.Equal(t, []uint64{1, 1}, edp.Positive().BucketCounts().AsRaw())
+	assert.Equal(t, int32(-1), edp.Negative().Offset())
+	assert.Equal(t, []uint64{1}, edp.Negative().BucketCounts().AsRaw())
+	assert.Equal(t, -1.0, edp.Min())
+	assert.Equal(t, 8.0, edp.Max())
+
+	summaries := metrics(4)
+	require.Equal(t, 1, summaries.Len())
+	sdp := summaries.At(0).Summary().DataPoints().At(0)
+	assert.Equal(t, uint64(2), sdp.Count())
+	assert.Equal(t, 3.0, sdp.Sum())
+	require.Equal(t, 2, sdp.QuantileValues().Len())
+	assert.Equal(t, 0.5, sdp.QuantileValues().At(0).Quantile())
+	assert.Equal(t, 1.0, sdp.QuantileValues().At(0).Value())
+	assert.Equal(t, 1.0, sdp.QuantileValues().At(1).Quantile())
+	assert.Equal(t, 2.0, sdp.QuantileValues().At(1).Value())
+}
+
+func TestSelectMetrics_invalidID(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	exemplars := testExemplars(2)
+	exemplars[3] = []string{"01"}
+	server.insert("otel_metrics_gauge",
+		testMetricRow(testStart, "gauge", map[string]string{}, append([]driver.Value{1.0}, testExemplars(1)...)...),
+		testMetricRow(testStart.Add(time.Second), "gauge", map[string]string{}, append([]driver.Value{2.0}, exemplars...)...),
+		testMetricRow(testStart.Add(2*time.Second), "gauge", map[string]string{}, append([]driver.Value{3.0}, testExemplars(3)...)...),
+	)
+
+	query := fmt.Sprintf(selectMetricsSQL, gaugeColumns, "otel_metrics_gauge", windowCondition("TimeUnix"))
+	skipped := &skippedRows{}
+	metrics, err := selectMetrics(context.Background(), openTestDB(t), query, func() dataPointRow { return &gaugeRow{} },
+		testStart, testStart.Add(time.Minute), skipped.skip)
+	require.NoError(t, err)
+
+	// The data point with an invalid exemplar is skipped, the next ones are read
+	dps := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	assert.Equal(t, 1.0, dps.At(0).DoubleValue())
+	assert.Equal(t, 3.0, dps.At(1).DoubleValue())
+	assert.Equal(t, []time.Time{testStart.Add(time.Second)}, skipped.times)
+	assert.EqualError(t, skipped.err, `invalid ID "01": expected 8 bytes, got 1`)
+}
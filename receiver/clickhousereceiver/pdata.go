@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// resourceRow holds the resource and scope columns of a row.
+type resourceRow struct {
+	resourceSchemaURL  string
+	resourceAttributes map[string]string
+	scopeSchemaURL     string
+	scopeName          string
+	scopeVersion       string
+	scopeAttributes    map[string]string
+	scopeDropped       uint32
+}
+
+// resourceKey returns the key grouping the rows of the same resource.
+func (r *resourceRow) resourceKey() string {
+	return r.resourceSchemaURL + "\x00" + attributesKey(r.resourceAttributes)
+}
+
+// scopeKey returns the key grouping the rows of the same resource and scope.
+func (r *resourceRow) scopeKey() string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s\x00%d", r.resourceKey(), r.scopeSchemaURL, r.scopeName, r.scopeVersion,
+		attributesKey(r.scopeAttributes), r.scopeDropped)
+}
+
+func (r *resourceRow) copyResource(resource pcommon.Resource) {
+	putAttributes(resource.Attributes(), r.resourceAttributes)
+}
+
+func (r *resourceRow) copyScope(scope pcommon.InstrumentationScope) {
+	scope.SetName(r.scopeName)
+	scope.SetVersion(r.scopeVersion)
+	putAttributes(scope.Attributes(), r.scopeAttributes)
+	scope.SetDroppedAttributesCount(r.scopeDropped)
+}
+
+// attributesKey encodes the attributes with their keys sorted.
+func attributesKey(attributes map[string]string) string {
+	// The maps of strings are always encoded.
+	b, _ := json.Marshal(attributes)
+	return string(b)
+}
+
+// putAttributes puts the attributes of a row in dest. The values of the attributes are stored as strings.
+func putAttributes(dest pcommon.Map, attributes map[string]string) {
+	dest.EnsureCapacity(len(attributes))
+	for k, v := range attributes {
+		dest.PutStr(k, v)
+	}
+}
+
+// getOrAppend returns the element of the key, which is appended when it doesn't exist.
+func getOrAppend[T any](elements map[string]T, key string, appendEmpty func() T) (T, bool) {
+	if element, ok := elements[key]; ok {
+		return element, false
+	}
+	element := appendEmpty()
+	elements[key] = element
+	return element, true
+}
+
+func timestamp(t time.Time) pcommon.Timestamp {
+	if t.UnixNano() <= 0 {
+		return 0
+	}
+	return pcommon.NewTimestampFromTime(t)
+}
+
+func traceID(s string) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	err := decodeID(s, id[:])
+	return id, err
+}
+
+func spanID(s string) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	err := decodeID(s, id[:])
+	return id, err
+}
+
+// decodeID decodes a hex encoded trace or span ID into id. An empty ID is left as is.
+func decodeID(s string, id []byte) error {
+	if s == "" {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q: %w", s, err)
+	}
+	if len(b) != len(id) {
+		return fmt.Errorf("invalid ID %q: expected %d bytes, got %d", s, len(id), len(b))
+	}
+	copy(id, b)
+	return nil
+}
@@ -0,0 +1,314 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2" // For register database driver.
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+)
+
+var driverName = "clickhouse" // for testing
+
+const checkpointStorageKeyPrefix = "checkpoint."
+
+// checkpoint is the progress of the receiver in a table: the rows older than Time have been read.
+type checkpoint struct {
+	Time time.Time `json:"time"`
+}
+
+// tableReader reads the rows of a table inserted in a window of time, and sends them to the next consumer.
+type tableReader struct {
+	table string
+	// timeColumn is the column the rows are read in the order of.
+	timeColumn string
+	// read reads the rows with a time in [from, to), and returns their count.
+	read func(ctx context.Context, db *sql.DB, from, to time.Time) (int, error)
+}
+
+// rowSkipper is passed the rows of a window which can't be converted, by their time.
+type rowSkipper func(t time.Time, err error)
+
+// clickhouseReceiver reads the tables of a signal in windows of time: each poll reads the windows ending before
+// the delay, from the end of the last window read.
+type clickhouseReceiver struct {
+	config   *Config
+	settings receiver.Settings
+	// signal names the storage client of the receiver, the receivers of the signals sharing the component ID.
+	signal           string
+	readers          []tableReader
+	obsrecv          *receiverhelper.ObsReport
+	telemetryBuilder *metadata.TelemetryBuilder
+
+	db            *sql.DB
+	storageClient storage.Client
+	// checkpoints hold the checkpoints of the tables loaded or stored since the start.
+	checkpoints map[string]time.Time
+	startTime   time.Time
+	endTime     time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newReceiver(cfg *Config, set receiver.Settings, signal string) (*clickhouseReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		Transport:              "tcp",
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	// The configuration is validated, so the times are valid.
+	startTime, _ := parseTime(cfg.StartTime, "start_time")
+	endTime, _ := parseTime(cfg.EndTime, "end_time")
+	return &clickhouseReceiver{
+		config:           cfg,
+		settings:         set,
+		signal:           signal,
+		obsrecv:          obsrecv,
+		telemetryBuilder: telemetryBuilder,
+		startTime:        startTime,
+		endTime:          endTime,
+		checkpoints:      map[string]time.Time{},
+	}, nil
+}
+
+func (r *clickhouseReceiver) Start(ctx context.Context, host component.Host) error {
+	dsn, err := r.config.buildDSN()
+	if err != nil {
+		return err
+	}
+	// ClickHouse sql driver will read clickhouse settings from the DSN string.
+	r.db, err = sql.Open(driverName, dsn)
+	if err != nil {
+		return err
+	}
+	r.storageClient, err = getStorageClient(ctx, host, r.config.StorageID, r.settings.ID, r.signal)
+	if err != nil {
+		return fmt.Errorf("error connecting to storage: %w", err)
+	}
+
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go r.run(ctx)
+	return nil
+}
+
+func (r *clickhouseReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	r.telemetryBuilder.Shutdown()
+	var err error
+	if r.storageClient != nil {
+		err = r.storageClient.Close(ctx)
+	}
+	if r.db != nil {
+		if closeErr := r.db.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (r *clickhouseReceiver) run(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		for _, reader := range r.readers {
+			if err := r.poll(ctx, reader, time.Now()); err != nil && ctx.Err() == nil {
+				r.settings.Logger.Error("Failed to read the table", zap.String("table", reader.table), zap.Error(err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the windows of the table ending before the delay, or before the end time when it is earlier.
+func (r *clickhouseReceiver) poll(ctx context.Context, reader tableReader, now time.Time) error {
+	horizon := now.Add(-r.config.Delay)
+	if !r.endTime.IsZero() && r.endTime.Before(horizon) {
+		horizon = r.endTime
+	}
+
+	from, err := r.loadCheckpoint(ctx, reader.table)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		// The table is read from the oldest row.
+		oldest, found, err := nextTime(ctx, r.db, reader, time.Unix(0, 0), horizon)
+		if err != nil || !found {
+			return err
+		}
+		from = oldest
+	}
+	for from.Before(horizon) {
+		to := from.Add(r.config.Window)
+		if to.After(horizon) {
+			to = horizon
+		}
+		if r.config.MaxRows > 0 {
+			// The window is shortened up to the row following the maximum number of rows, so its rows are
+			// read in several queries. The rows of a single time are read at once, as they can't be split.
+			limit, found, err := limitTime(ctx, r.db, reader, from, to, r.config.MaxRows)
+			if err != nil {
+				return err
+			}
+			if found {
+				to = limit
+				if !to.After(from) {
+					to = from.Add(time.Nanosecond)
+				}
+			}
+		}
+		count, err := reader.read(ctx, r.db, from, to)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			// The windows without rows are skipped, up to the next row.
+			next, found, err := nextTime(ctx, r.db, reader, to, horizon)
+			if err != nil {
+				return err
+			}
+			if !found {
+				next = horizon
+			}
+			to = next
+		}
+		if err := r.storeCheckpoint(ctx, reader.table, to); err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// skipRows returns the rowSkipper of a table, which logs and counts the skipped rows. A row which can't be
+// converted can't be read again, so it's skipped not to block the receiver.
+func (r *clickhouseReceiver) skipRows(ctx context.Context, table string) rowSkipper {
+	return func(t time.Time, err error) {
+		r.settings.Logger.Warn("Skipping a row which can't be converted",
+			zap.String("table", table), zap.Time("time", t), zap.Error(err))
+		r.telemetryBuilder.ClickhouseRowsSkipped.Add(ctx, 1, metric.WithAttributes(attribute.String("table", table)))
+	}
+}
+
+// nextTime returns the time of the first row with a time in [from, to).
+func nextTime(ctx context.Context, db *sql.DB, reader tableReader, from, to time.Time) (time.Time, bool, error) {
+	// language=ClickHouse SQL
+	query := fmt.Sprintf(`SELECT count(), toUnixTimestamp64Nano(min(%s)) FROM %s WHERE %s`,
+		reader.timeColumn, reader.table, windowCondition(reader.timeColumn))
+	var count uint64
+	var next int64
+	if err := db.QueryRowContext(ctx, query, from.UnixNano(), to.UnixNano()).Scan(&count, &next); err != nil {
+		return time.Time{}, false, fmt.Errorf("select next time of table %s: %w", reader.table, err)
+	}
+	if count == 0 {
+		return time.Time{}, false, nil
+	}
+	return time.Unix(0, next).UTC(), true, nil
+}
+
+// limitTime returns the time of the row following the first maxRows rows with a time in [from, to), so that the
+// window [from, limitTime) holds at most maxRows rows.
+func limitTime(ctx context.Context, db *sql.DB, reader tableReader, from, to time.Time, maxRows int) (time.Time, bool, error) {
+	// language=ClickHouse SQL
+	query := fmt.Sprintf(`SELECT toUnixTimestamp64Nano(%s) FROM %s WHERE %s ORDER BY %s LIMIT 1 OFFSET %d`,
+		reader.timeColumn, reader.table, windowCondition(reader.timeColumn), reader.timeColumn, maxRows)
+	var limit int64
+	err := db.QueryRowContext(ctx, query, from.UnixNano(), to.UnixNano()).Scan(&limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("select limit time of table %s: %w", reader.table, err)
+	}
+	return time.Unix(0, limit).UTC(), true, nil
+}
+
+// windowCondition returns the condition selecting the rows with a time in a window, whose bounds are the
+// parameters of the query in nanoseconds.
+func windowCondition(timeColumn string) string {
+	return fmt.Sprintf("%s >= fromUnixTimestamp64Nano(?) AND %s < fromUnixTimestamp64Nano(?)", timeColumn, timeColumn)
+}
+
+// loadCheckpoint returns the time the table is read from: the checkpoint when there is one, else the start time.
+// The zero time is returned when the table is read from the oldest row.
+func (r *clickhouseReceiver) loadCheckpoint(ctx context.Context, table string) (time.Time, error) {
+	if t, ok := r.checkpoints[table]; ok {
+		return t, nil
+	}
+	data, err := r.storageClient.Get(ctx, checkpointStorageKeyPrefix+table)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get the checkpoint: %w", err)
+	}
+	if data != nil {
+		var c checkpoint
+		if err := json.Unmarshal(data, &c); err != nil {
+			return time.Time{}, fmt.Errorf("failed to decode the checkpoint: %w", err)
+		}
+		r.checkpoints[table] = c.Time
+		return c.Time, nil
+	}
+	return r.startTime, nil
+}
+
+func (r *clickhouseReceiver) storeCheckpoint(ctx context.Context, table string, t time.Time) error {
+	r.checkpoints[table] = t
+	data, err := json.Marshal(checkpoint{Time: t})
+	if err != nil {
+		return err
+	}
+	if err := r.storageClient.Set(ctx, checkpointStorageKeyPrefix+table, data); err != nil {
+		return fmt.Errorf("failed to store the checkpoint: %w", err)
+	}
+	return nil
+}
+
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID, signal string) (storage.Client, error) {
+	if storageID == nil {
+		return storage.NewNopClient(), nil
+	}
+
+	extension, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+
+	return storageExtension.GetClient(ctx, component.KindReceiver, componentID, signal)
+}
@@ -0,0 +1,494 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadatatest"
+)
+
+// testRow is a row of a test table, whose time is the one of the time column.
+type testRow struct {
+	time   time.Time
+	values []driver.Value
+}
+
+// testServer is a fake ClickHouse server serving the queries of the receiver on test tables.
+type testServer struct {
+	mu      sync.Mutex
+	tables  map[string][]testRow
+	queries []testQuery
+}
+
+var (
+	fromRegexp   = regexp.MustCompile(`FROM\s+(\S+)`)
+	offsetRegexp = regexp.MustCompile(`OFFSET (\d+)`)
+)
+
+type testQuery struct {
+	table    string
+	from, to time.Time
+	next     bool
+	limit    bool
+}
+
+// initClickhouseTestServer registers a fake driver named t.Name() serving the queries on the tables.
+func initClickhouseTestServer(t *testing.T) *testServer {
+	s := &testServer{tables: map[string][]testRow{}}
+	driverName = t.Name()
+	sql.Register(t.Name(), &testClickhouseDriver{server: s})
+	return s
+}
+
+func (s *testServer) insert(table string, rows ...testRow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[table] = append(s.tables[table], rows...)
+}
+
+func (s *testServer) allQueries() []testQuery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]testQuery(nil), s.queries...)
+}
+
+func (s *testServer) query(query string, args []driver.Value) ([][]driver.Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := testQuery{
+		table: fromRegexp.FindStringSubmatch(query)[1],
+		from:  time.Unix(0, args[0].(int64)).UTC(),
+		to:    time.Unix(0, args[1].(int64)).UTC(),
+		next:  strings.HasPrefix(query, "SELECT count()"),
+		limit: offsetRegexp.MatchString(query),
+	}
+	s.queries = append(s.queries, q)
+
+	table, ok := s.tables[q.table]
+	if !ok {
+		return nil, errors.New("table " + q.table + " doesn't exist")
+	}
+	var rows [][]driver.Value
+	var count uint64
+	var next time.Time
+	for _, row := range table {
+		if row.time.Before(q.from) || !row.time.Before(q.to) {
+			continue
+		}
+		count++
+		if next.IsZero() || row.time.Before(next) {
+			next = row.time
+		}
+		rows = append(rows, row.values)
+	}
+	if q.limit {
+		offset, _ := strconv.Atoi(offsetRegexp.FindStringSubmatch(query)[1])
+		var times []time.Time
+		for _, row := range table {
+			if !row.time.Before(q.from) && row.time.Before(q.to) {
+				times = append(times, row.time)
+			}
+		}
+		if offset >= len(times) {
+			return nil, nil
+		}
+		slices.SortFunc(times, time.Time.Compare)
+		return [][]driver.Value{{times[offset].UnixNano()}}, nil
+	}
+	if q.next {
+		var nanos int64
+		if count > 0 {
+			nanos = next.UnixNano()
+		}
+		return [][]driver.Value{{count, nanos}}, nil
+	}
+	return rows, nil
+}
+
+type testClickhouseDriver struct {
+	server *testServer
+}
+
+func (t *testClickhouseDriver) Open(_ string) (driver.Conn, error) {
+	return &testClickhouseDriverConn{server: t.server}, nil
+}
+
+type testClickhouseDriverConn struct {
+	server *testServer
+}
+
+func (t *testClickhouseDriverConn) Prepare(query string) (driver.Stmt, error) {
+	return &testClickhouseDriverStmt{query: query, server: t.server}, nil
+}
+
+func (*testClickhouseDriverConn) Close() error {
+	return nil
+}
+
+func (*testClickhouseDriverConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions aren't supported")
+}
+
+func (*testClickhouseDriverConn) CheckNamedValue(_ *driver.NamedValue) error {
+	return nil
+}
+
+type testClickhouseDriverStmt struct {
+	query  string
+	server *testServer
+}
+
+func (*testClickhouseDriverStmt) Close() error {
+	return nil
+}
+
+func (t *testClickhouseDriverStmt) NumInput() int {
+	return strings.Count(t.query, "?")
+}
+
+func (*testClickhouseDriverStmt) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec isn't supported")
+}
+
+func (t *testClickhouseDriverStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := t.server.query(t.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &testClickhouseDriverRows{rows: rows}, nil
+}
+
+type testClickhouseDriverRows struct {
+	rows [][]driver.Value
+}
+
+func (t *testClickhouseDriverRows) Columns() []string {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(t.rows[0]))
+}
+
+func (*testClickhouseDriverRows) Close() error {
+	return nil
+}
+
+func (t *testClickhouseDriverRows) Next(dest []driver.Value) error {
+	if len(t.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, t.rows[0])
+	t.rows = t.rows[1:]
+	return nil
+}
+
+// testLogRow returns a row of the logs table with the body.
+func testLogRow(t time.Time, body string) testRow {
+	return testRow{time: t, values: []driver.Value{
+		t, "", "", uint8(0), "", uint8(0), body,
+		"", map[string]string{"service.name": "test"}, "", "", "", map[string]string{}, map[string]string{},
+	}}
+}
+
+func bodies(sink *consumertest.LogsSink) []string {
+	var result []string
+	for _, logs := range sink.AllLogs() {
+		records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < records.Len(); i++ {
+			result = append(result, records.At(i).Body().Str())
+		}
+	}
+	return result
+}
+
+// skippedRows records the rows skipped by a select function.
+type skippedRows struct {
+	times []time.Time
+	err   error
+}
+
+func (s *skippedRows) skip(t time.Time, err error) {
+	s.times = append(s.times, t)
+	s.err = errors.Join(s.err, err)
+}
+
+// failSkip returns a rowSkipper failing the test.
+func failSkip(t *testing.T) rowSkipper {
+	return func(_ time.Time, err error) {
+		t.Errorf("unexpected skipped row: %v", err)
+	}
+}
+
+var testStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "clickhouse://127.0.0.1:9000"
+	cfg.PollInterval = time.Hour
+	storageID := storagetest.NewStorageID("test")
+	cfg.StorageID = &storageID
+	return cfg
+}
+
+// startLogsReceiver starts a logs receiver which doesn't poll the table, and returns the reader of the table.
+func startLogsReceiver(t *testing.T, cfg *Config, host component.Host, next consumer.Logs) (*clickhouseReceiver, tableReader) {
+	set := receivertest.NewNopSettings(metadata.Type)
+	// The ID of the receiver identifies its storage.
+	set.ID = component.NewID(metadata.Type)
+	r, err := newLogsReceiver(cfg, set, next)
+	require.NoError(t, err)
+	reader := r.readers[0]
+	r.readers = nil
+	require.NoError(t, r.Start(context.Background(), host))
+	return r, reader
+}
+
+func TestReceiver_poll(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_logs",
+		testLogRow(testStart.Add(10*time.Second), "a"),
+		testLogRow(testStart.Add(50*time.Second), "b"),
+		testLogRow(testStart.Add(70*time.Second), "c"),
+		testLogRow(testStart.Add(time.Hour), "d"),
+	)
+	cfg := newTestConfig()
+	cfg.MaxRows = 0
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	sink := new(consumertest.LogsSink)
+	r, reader := startLogsReceiver(t, cfg, host, sink)
+	horizon := testStart.Add(2*time.Hour - time.Minute)
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(2*time.Hour)))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, bodies(sink))
+	assert.Len(t, sink.AllLogs(), 3)
+	assert.Equal(t, []testQuery{
+		// The table is read from the oldest row
+		{table: "otel_logs", from: time.Unix(0, 0).UTC(), to: horizon, next: true},
+		{table: "otel_logs", from: testStart.Add(10 * time.Second), to: testStart.Add(70 * time.Second)},
+		{table: "otel_logs", from: testStart.Add(70 * time.Second), to: testStart.Add(130 * time.Second)},
+		{table: "otel_logs", from: testStart.Add(130 * time.Second), to: testStart.Add(190 * time.Second)},
+		// The windows without rows are skipped
+		{table: "otel_logs", from: testStart.Add(190 * time.Second), to: horizon, next: true},
+		{table: "otel_logs", from: testStart.Add(time.Hour), to: testStart.Add(time.Hour + time.Minute)},
+		{table: "otel_logs", from: testStart.Add(time.Hour + time.Minute), to: testStart.Add(time.Hour + 2*time.Minute)},
+		{table: "otel_logs", from: testStart.Add(time.Hour + 2*time.Minute), to: horizon, next: true},
+	}, server.allQueries())
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	// The checkpoint is restored after a restart, so only the rows inserted since then are read
+	server.insert("otel_logs", testLogRow(testStart.Add(2*time.Hour+30*time.Minute), "e"))
+	sink.Reset()
+	r, reader = startLogsReceiver(t, cfg, host, sink)
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(3*time.Hour)))
+	assert.Equal(t, []string{"e"}, bodies(sink))
+	assert.Equal(t, testStart.Add(3*time.Hour-time.Minute), r.checkpoints["otel_logs"])
+}
+
+func TestReceiver_maxRows(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_logs",
+		testLogRow(testStart.Add(10*time.Second), "a"),
+		testLogRow(testStart.Add(20*time.Second), "b"),
+		testLogRow(testStart.Add(20*time.Second), "c"),
+		testLogRow(testStart.Add(20*time.Second), "d"),
+		testLogRow(testStart.Add(30*time.Second), "e"),
+	)
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+	cfg.StartTime = "2025-01-01"
+	cfg.MaxRows = 2
+
+	sink := new(consumertest.LogsSink)
+	r, reader := startLogsReceiver(t, cfg, storagetest.NewStorageHost(), sink)
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(2*time.Minute)))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, bodies(sink))
+	var counts []int
+	for _, logs := range sink.AllLogs() {
+		counts = append(counts, logs.LogRecordCount())
+	}
+	// The rows of a single time are read at once, although they are more than the maximum
+	assert.Equal(t, []int{1, 3, 1}, counts)
+
+	var windows []testQuery
+	for _, q := range server.allQueries() {
+		if !q.limit && !q.next {
+			windows = append(windows, q)
+		}
+	}
+	assert.Equal(t, []testQuery{
+		{table: "otel_logs", from: testStart, to: testStart.Add(20 * time.Second)},
+		{table: "otel_logs", from: testStart.Add(20 * time.Second), to: testStart.Add(20*time.Second + time.Nanosecond)},
+		{table: "otel_logs", from: testStart.Add(20*time.Second + time.Nanosecond), to: testStart.Add(time.Minute)},
+	}, windows)
+	assert.Equal(t, testStart.Add(time.Minute), r.checkpoints["otel_logs"])
+}
+
+func TestReceiver_startTime(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_logs",
+		testLogRow(testStart, "a"),
+		testLogRow(testStart.Add(30*time.Second), "b"),
+		testLogRow(testStart.Add(90*time.Second), "c"),
+	)
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+	cfg.StartTime = "2025-01-01T00:00:20Z"
+	cfg.Delay = 10 * time.Second
+
+	sink := new(consumertest.LogsSink)
+	r, reader := startLogsReceiver(t, cfg, storagetest.NewStorageHost(), sink)
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+
+	// The rows are read from the start time, up to the delay
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(90*time.Second)))
+	assert.Equal(t, []string{"b"}, bodies(sink))
+	assert.Equal(t, testStart.Add(80*time.Second), r.checkpoints["otel_logs"])
+
+	// The checkpoint is kept in memory without a storage extension
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(2*time.Minute)))
+	assert.Equal(t, []string{"b", "c"}, bodies(sink))
+}
+
+func TestReceiver_endTime(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_logs",
+		testLogRow(testStart, "a"),
+		testLogRow(testStart.Add(time.Hour), "b"),
+	)
+	cfg := newTestConfig()
+	cfg.StartTime = "2025-01-01"
+	cfg.EndTime = "2025-01-01 00:30:00"
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	sink := new(consumertest.LogsSink)
+	r, reader := startLogsReceiver(t, cfg, host, sink)
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(2*time.Hour)))
+	assert.Equal(t, []string{"a"}, bodies(sink))
+
+	data, err := r.storageClient.Get(context.Background(), "checkpoint.otel_logs")
+	require.NoError(t, err)
+	var c checkpoint
+	require.NoError(t, json.Unmarshal(data, &c))
+	assert.Equal(t, testStart.Add(30*time.Minute), c.Time)
+
+	// The table isn't queried anymore once the end time is reached
+	queries := len(server.allQueries())
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(3*time.Hour)))
+	assert.Len(t, server.allQueries(), queries)
+}
+
+func TestReceiver_consumeError(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_logs", testLogRow(testStart, "a"))
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+	cfg.StartTime = "2025-01-01"
+
+	r, reader := startLogsReceiver(t, cfg, storagetest.NewStorageHost(), consumertest.NewErr(errors.New("rejected")))
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+
+	// The checkpoint isn't stored when the rows are rejected, so they are read again by the next poll
+	assert.EqualError(t, r.poll(context.Background(), reader, testStart.Add(time.Hour)), "rejected")
+	assert.Empty(t, r.checkpoints)
+}
+
+func TestReceiver_skippedRow(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	invalid := testLogRow(testStart.Add(20*time.Second), "b")
+	invalid.values[2] = "0102"
+	server.insert("otel_logs",
+		testLogRow(testStart.Add(10*time.Second), "a"),
+		invalid,
+		testLogRow(testStart.Add(30*time.Second), "c"),
+	)
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+	cfg.StartTime = "2025-01-01"
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	sink := new(consumertest.LogsSink)
+	r, err := newLogsReceiver(cfg, metadatatest.NewSettings(tel), sink)
+	require.NoError(t, err)
+	reader := r.readers[0]
+	r.readers = nil
+	require.NoError(t, r.Start(context.Background(), storagetest.NewStorageHost()))
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+
+	// The malformed row is skipped and counted, and the checkpoint moves past the window
+	require.NoError(t, r.poll(context.Background(), reader, testStart.Add(2*time.Minute)))
+	assert.Equal(t, []string{"a", "c"}, bodies(sink))
+	assert.Equal(t, testStart.Add(time.Minute), r.checkpoints["otel_logs"])
+	metadatatest.AssertEqualClickhouseRowsSkipped(t, tel, []metricdata.DataPoint[int64]{{
+		Attributes: attribute.NewSet(attribute.String("table", "otel_logs")),
+		Value:      1,
+	}}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestReceiver_queryError(t *testing.T) {
+	initClickhouseTestServer(t)
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+	cfg.StartTime = "2025-01-01"
+	cfg.MaxRows = 0
+
+	r, reader := startLogsReceiver(t, cfg, storagetest.NewStorageHost(), consumertest.NewNop())
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+	assert.EqualError(t, r.poll(context.Background(), reader, testStart.Add(time.Hour)),
+		"select logs of table otel_logs: table otel_logs doesn't exist")
+}
+
+func TestReceiver_run(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_logs", testLogRow(time.Now().Add(-time.Hour), "a"))
+	cfg := newTestConfig()
+	cfg.StorageID = nil
+
+	sink := new(consumertest.LogsSink)
+	r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(metadata.Type), sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), storagetest.NewStorageHost()))
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestReceiver_storageNotFound(t *testing.T) {
+	initClickhouseTestServer(t)
+	r, err := newLogsReceiver(newTestConfig(), receivertest.NewNopSettings(metadata.Type), consumertest.NewNop())
+	require.NoError(t, err)
+	err = r.Start(context.Background(), storagetest.NewStorageHost())
+	assert.ErrorContains(t, err, "storage extension 'test_storage/test' not found")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
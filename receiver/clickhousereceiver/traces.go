@@ -0,0 +1,230 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver"
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/clickhousereceiver/internal/metadata"
+)
+
+// language=ClickHouse SQL
+const selectTracesSQL = `SELECT
+	Timestamp,
+	TraceId,
+	SpanId,
+	ParentSpanId,
+	TraceState,
+	SpanName,
+	SpanKind,
+	ResourceAttributes,
+	ScopeName,
+	ScopeVersion,
+	SpanAttributes,
+	Duration,
+	StatusCode,
+	StatusMessage,
+	Events.Timestamp,
+	Events.Name,
+	Events.Attributes,
+	Links.TraceId,
+	Links.SpanId,
+	Links.TraceState,
+	Links.Attributes
+FROM %s
+WHERE %s
+ORDER BY Timestamp`
+
+func newTracesReceiver(cfg *Config, set receiver.Settings, next consumer.Traces) (*clickhouseReceiver, error) {
+	r, err := newReceiver(cfg, set, "traces")
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(selectTracesSQL, cfg.TracesTableName, windowCondition("Timestamp"))
+	r.readers = []tableReader{{
+		table:      cfg.TracesTableName,
+		timeColumn: "Timestamp",
+		read: func(ctx context.Context, db *sql.DB, from, to time.Time) (int, error) {
+			traces, err := selectTraces(ctx, db, query, from, to, r.skipRows(ctx, cfg.TracesTableName))
+			if err != nil {
+				return 0, fmt.Errorf("select traces of table %s: %w", cfg.TracesTableName, err)
+			}
+			count := traces.SpanCount()
+			if count == 0 {
+				return 0, nil
+			}
+			obsCtx := r.obsrecv.StartTracesOp(ctx)
+			err = next.ConsumeTraces(obsCtx, traces)
+			r.obsrecv.EndTracesOp(obsCtx, metadata.Type.String(), count, err)
+			return count, err
+		},
+	}}
+	return r, nil
+}
+
+// selectTraces reads the spans starting in a window, grouped by resource and scope. The rows which can't be
+// converted are passed to skip.
+func selectTraces(ctx context.Context, db *sql.DB, query string, from, to time.Time, skip rowSkipper) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	rows, err := db.QueryContext(ctx, query, from.UnixNano(), to.UnixNano())
+	if err != nil {
+		return traces, err
+	}
+	defer rows.Close()
+
+	resourceSpans := map[string]ptrace.ResourceSpans{}
+	scopeSpans := map[string]ptrace.ScopeSpans{}
+	for rows.Next() {
+		var (
+			r               resourceRow
+			start           time.Time
+			traceIDHex      string
+			spanIDHex       string
+			parentSpanIDHex string
+			traceState      string
+			name            string
+			kind            string
+			attributes      map[string]string
+			duration        uint64
+			code            string
+			statusMessage   string
+			eventTimes      []time.Time
+			eventNames      []string
+			eventAttributes []map[string]string
+			linkTraceIDs    []string
+			linkSpanIDs     []string
+			linkTraceStates []string
+			linkAttributes  []map[string]string
+		)
+		if err := rows.Scan(&start, &traceIDHex, &spanIDHex, &parentSpanIDHex, &traceState, &name, &kind,
+			&r.resourceAttributes, &r.scopeName, &r.scopeVersion, &attributes, &duration, &code, &statusMessage,
+			&eventTimes, &eventNames, &eventAttributes, &linkTraceIDs, &linkSpanIDs, &linkTraceStates, &linkAttributes); err != nil {
+			return traces, err
+		}
+		// The IDs are decoded before the span is appended, so that a row which can't be converted is skipped whole.
+		ids, err := decodeSpanIDs(traceIDHex, spanIDHex, parentSpanIDHex, linkTraceIDs, linkSpanIDs)
+		if err != nil {
+			skip(start, err)
+			continue
+		}
+
+		rs, added := getOrAppend(resourceSpans, r.resourceKey(), traces.ResourceSpans().AppendEmpty)
+		if added {
+			r.copyResource(rs.Resource())
+		}
+		ss, added := getOrAppend(scopeSpans, r.scopeKey(), rs.ScopeSpans().AppendEmpty)
+		if added {
+			r.copyScope(ss.Scope())
+		}
+
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(ids.traceID)
+		span.SetSpanID(ids.spanID)
+		span.SetParentSpanID(ids.parentSpanID)
+		span.TraceState().FromRaw(traceState)
+		span.SetName(name)
+		span.SetKind(spanKind(kind))
+		span.SetStartTimestamp(timestamp(start))
+		span.SetEndTimestamp(timestamp(start.Add(time.Duration(duration))))
+		putAttributes(span.Attributes(), attributes)
+		span.Status().SetCode(statusCode(code))
+		span.Status().SetMessage(statusMessage)
+
+		for i := range eventNames {
+			event := span.Events().AppendEmpty()
+			event.SetName(eventNames[i])
+			if i < len(eventTimes) {
+				event.SetTimestamp(timestamp(eventTimes[i]))
+			}
+			if i < len(eventAttributes) {
+				putAttributes(event.Attributes(), eventAttributes[i])
+			}
+		}
+		for i := range linkTraceIDs {
+			link := span.Links().AppendEmpty()
+			link.SetTraceID(ids.linkTraceIDs[i])
+			if i < len(ids.linkSpanIDs) {
+				link.SetSpanID(ids.linkSpanIDs[i])
+			}
+			if i < len(linkTraceStates) {
+				link.TraceState().FromRaw(linkTraceStates[i])
+			}
+			if i < len(linkAttributes) {
+				putAttributes(link.Attributes(), linkAttributes[i])
+			}
+		}
+	}
+	return traces, rows.Err()
+}
+
+// spanIDs holds the decoded IDs of a span and of its links.
+type spanIDs struct {
+	traceID      pcommon.TraceID
+	spanID       pcommon.SpanID
+	parentSpanID pcommon.SpanID
+	linkTraceIDs []pcommon.TraceID
+	linkSpanIDs  []pcommon.SpanID
+}
+
+func decodeSpanIDs(traceIDHex, spanIDHex, parentSpanIDHex string, linkTraceIDs, linkSpanIDs []string) (ids spanIDs, err error) {
+	if ids.traceID, err = traceID(traceIDHex); err != nil {
+		return ids, err
+	}
+	if ids.spanID, err = spanID(spanIDHex); err != nil {
+		return ids, err
+	}
+	if ids.parentSpanID, err = spanID(parentSpanIDHex); err != nil {
+		return ids, err
+	}
+	ids.linkTraceIDs = make([]pcommon.TraceID, len(linkTraceIDs))
+	for i := range linkTraceIDs {
+		if ids.linkTraceIDs[i], err = traceID(linkTraceIDs[i]); err != nil {
+			return ids, err
+		}
+	}
+	ids.linkSpanIDs = make([]pcommon.SpanID, len(linkSpanIDs))
+	for i := range linkSpanIDs {
+		if ids.linkSpanIDs[i], err = spanID(linkSpanIDs[i]); err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
+}
+
+// spanKind parses a span kind, written like ptrace.SpanKind.String or like its protobuf name.
+func spanKind(s string) ptrace.SpanKind {
+	for _, kind := range []ptrace.SpanKind{
+		ptrace.SpanKindInternal,
+		ptrace.SpanKindServer,
+		ptrace.SpanKindClient,
+		ptrace.SpanKindProducer,
+		ptrace.SpanKindConsumer,
+	} {
+		name := kind.String()
+		if s == name || s == "SPAN_KIND_"+strings.ToUpper(name) {
+			return kind
+		}
+	}
+	return ptrace.SpanKindUnspecified
+}
+
+// statusCode parses a status code, written like ptrace.StatusCode.String or like its protobuf name.
+func statusCode(s string) ptrace.StatusCode {
+	for _, code := range []ptrace.StatusCode{ptrace.StatusCodeOk, ptrace.StatusCodeError} {
+		name := code.String()
+		if s == name || s == "STATUS_CODE_"+strings.ToUpper(name) {
+			return code
+		}
+	}
+	return ptrace.StatusCodeUnset
+}
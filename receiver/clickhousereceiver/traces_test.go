@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhousereceiver
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestSelectTraces(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	server.insert("otel_traces", testRow{time: testStart, values: []driver.Value{
+		testStart, "0102030405060708090a0b0c0d0e0f10", "0102030405060708", "", "state", "GET /", "Server",
+		map[string]string{"service.name": "test"}, "io.opentelemetry.contrib.clickhouse", "1.0.0",
+		map[string]string{"http.method": "GET"}, uint64(time.Second), "Error", "failed",
+		[]time.Time{testStart.Add(time.Millisecond)}, []string{"exception"}, []map[string]string{{"exception.type": "error"}},
+		[]string{"1112131415161718191a1b1c1d1e1f20"}, []string{"1112131415161718"}, []string{""}, []map[string]string{{"k": "v"}},
+	}}, testRow{time: testStart.Add(time.Second), values: []driver.Value{
+		testStart.Add(time.Second), "0102030405060708090a0b0c0d0e0f10", "0807060504030201", "0102030405060708", "", "query",
+		"SPAN_KIND_CLIENT", map[string]string{"service.name": "test"}, "io.opentelemetry.contrib.clickhouse", "1.0.0",
+		map[string]string{}, uint64(0), "STATUS_CODE_OK", "",
+		[]time.Time{}, []string{}, []map[string]string{}, []string{}, []string{}, []string{}, []map[string]string{},
+	}})
+
+	query := fmt.Sprintf(selectTracesSQL, "otel_traces", windowCondition("Timestamp"))
+	traces, err := selectTraces(context.Background(), openTestDB(t), query, testStart, testStart.Add(time.Minute), failSkip(t))
+	require.NoError(t, err)
+
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	rs := traces.ResourceSpans().At(0)
+	assert.Equal(t, map[string]any{"service.name": "test"}, rs.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rs.ScopeSpans().Len())
+	ss := rs.ScopeSpans().At(0)
+	assert.Equal(t, "io.opentelemetry.contrib.clickhouse", ss.Scope().Name())
+	assert.Equal(t, "1.0.0", ss.Scope().Version())
+	require.Equal(t, 2, ss.Spans().Len())
+
+	span := ss.Spans().At(0)
+	assert.Equal(t, pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, span.TraceID())
+	assert.Equal(t, pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, span.SpanID())
+	assert.True(t, span.ParentSpanID().IsEmpty())
+	assert.Equal(t, "state", span.TraceState().AsRaw())
+	assert.Equal(t, "GET /", span.Name())
+	assert.Equal(t, ptrace.SpanKindServer, span.Kind())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testStart), span.StartTimestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testStart.Add(time.Second)), span.EndTimestamp())
+	assert.Equal(t, map[string]any{"http.method": "GET"}, span.Attributes().AsRaw())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "failed", span.Status().Message())
+	require.Equal(t, 1, span.Events().Len())
+	assert.Equal(t, "exception", span.Events().At(0).Name())
+	assert.Equal(t, pcommon.NewTimestampFromTime(testStart.Add(time.Millisecond)), span.Events().At(0).Timestamp())
+	assert.Equal(t, map[string]any{"exception.type": "error"}, span.Events().At(0).Attributes().AsRaw())
+	require.Equal(t, 1, span.Links().Len())
+	assert.Equal(t, pcommon.TraceID{17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}, span.Links().At(0).TraceID())
+	assert.Equal(t, pcommon.SpanID{17, 18, 19, 20, 21, 22, 23, 24}, span.Links().At(0).SpanID())
+	assert.Equal(t, map[string]any{"k": "v"}, span.Links().At(0).Attributes().AsRaw())
+
+	span = ss.Spans().At(1)
+	assert.Equal(t, pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, span.ParentSpanID())
+	assert.Equal(t, ptrace.SpanKindClient, span.Kind())
+	assert.Equal(t, ptrace.StatusCodeOk, span.Status().Code())
+	assert.Equal(t, span.StartTimestamp(), span.EndTimestamp())
+	assert.Equal(t, 0, span.Events().Len())
+	assert.Equal(t, 0, span.Links().Len())
+}
+
+func TestSelectTraces_invalidID(t *testing.T) {
+	server := initClickhouseTestServer(t)
+	row := func(ts time.Time, name, linkTraceID string) testRow {
+		return testRow{time: ts, values: []driver.Value{
+			ts, "0102030405060708090a0b0c0d0e0f10", "0102030405060708", "", "", name, "Server",
+			map[string]string{"service.name": "test"}, "", "", map[string]string{}, uint64(0), "Unset", "",
+			[]time.Time{}, []string{}, []map[string]string{},
+			[]string{linkTraceID}, []string{""}, []string{""}, []map[string]string{{}},
+		}}
+	}
+	server.insert("otel_traces",
+		row(testStart, "a", ""),
+		row(testStart.Add(time.Second), "b", "0102"),
+		row(testStart.Add(2*time.Second), "c", ""),
+	)
+
+	query := fmt.Sprintf(selectTracesSQL, "otel_traces", windowCondition("Timestamp"))
+	skipped := &skippedRows{}
+	traces, err := selectTraces(context.Background(), openTestDB(t), query, testStart, testStart.Add(time.Minute), skipped.skip)
+	require.NoError(t, err)
+
+	// The span with an invalid link is skipped whole, the next ones are read
+	spans := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	require.Equal(t, 2, spans.Len())
+	assert.Equal(t, "a", spans.At(0).Name())
+	assert.Equal(t, 1, spans.At(0).Links().Len())
+	assert.Equal(t, "c", spans.At(1).Name())
+	assert.Equal(t, []time.Time{testStart.Add(time.Second)}, skipped.times)
+	assert.EqualError(t, skipped.err, `invalid ID "0102": expected 16 bytes, got 2`)
+}
+
+func TestSpanKind(t *testing.T) {
+	for s, expected := range map[string]ptrace.SpanKind{
+		"Internal":           ptrace.SpanKindInternal,
+		"SPAN_KIND_PRODUCER": ptrace.SpanKindProducer,
+		"Consumer":           ptrace.SpanKindConsumer,
+		"Unspecified":        ptrace.SpanKindUnspecified,
+		"unknown":            ptrace.SpanKindUnspecified,
+	} {
+		assert.Equal(t, expected, spanKind(s), s)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	for s, expected := range map[string]ptrace.StatusCode{
+		"Ok":                ptrace.StatusCodeOk,
+		"STATUS_CODE_ERROR": ptrace.StatusCodeError,
+		"Unset":             ptrace.StatusCodeUnset,
+		"":                  ptrace.StatusCodeUnset,
+	} {
+		assert.Equal(t, expected, statusCode(s), s)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver"
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// searchClient runs the point in time searches of the receiver with the REST API of the cluster.
+type searchClient struct {
+	client       *http.Client
+	endpoint     string
+	authHeader   string
+	distribution string
+}
+
+func newSearchClient(ctx context.Context, cfg *Config, host component.Host, settings component.TelemetrySettings) (*searchClient, error) {
+	client, err := cfg.ClientConfig.ToClient(ctx, host, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var authHeader string
+	switch {
+	case cfg.APIKey != "":
+		authHeader = "ApiKey " + string(cfg.APIKey)
+	case cfg.Username != "":
+		userPass := cfg.Username + ":" + string(cfg.Password)
+		authHeader = "Basic " + base64.StdEncoding.EncodeToString([]byte(userPass))
+	}
+
+	return &searchClient{
+		client:       client,
+		endpoint:     strings.TrimSuffix(cfg.Endpoint, "/"),
+		authHeader:   authHeader,
+		distribution: cfg.Distribution,
+	}, nil
+}
+
+// searchHit is a document returned by a search request.
+type searchHit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+	// Sort holds the sort values of the document, which are passed verbatim as search_after to get the
+	// next page.
+	Sort []json.RawMessage `json:"sort"`
+}
+
+type searchResponse struct {
+	// PitID is the point in time ID to use in the next search request, which may differ from the ID of
+	// the request.
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Hits []searchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// openPointInTime opens a point in time of the index, kept for keepAlive.
+func (c *searchClient) openPointInTime(ctx context.Context, index string, keepAlive time.Duration) (string, error) {
+	path := "/" + url.PathEscape(index) + "/_pit"
+	if c.distribution == distributionOpenSearch {
+		path = "/" + url.PathEscape(index) + "/_search/point_in_time"
+	}
+	var resp struct {
+		ID    string `json:"id"`
+		PitID string `json:"pit_id"`
+	}
+	err := c.do(ctx, http.MethodPost, path+"?keep_alive="+formatKeepAlive(keepAlive), nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to open a point in time of %q: %w", index, err)
+	}
+	if c.distribution == distributionOpenSearch {
+		return resp.PitID, nil
+	}
+	return resp.ID, nil
+}
+
+// closePointInTime releases the resources of the point in time.
+func (c *searchClient) closePointInTime(ctx context.Context, id string) error {
+	path, body := "/_pit", map[string]any{"id": id}
+	if c.distribution == distributionOpenSearch {
+		path, body = "/_search/point_in_time", map[string]any{"pit_id": []string{id}}
+	}
+	if err := c.do(ctx, http.MethodDelete, path, body, nil); err != nil {
+		return fmt.Errorf("failed to close the point in time: %w", err)
+	}
+	return nil
+}
+
+// search runs a search request of a point in time, whose body must hold the pit.
+func (c *searchClient) search(ctx context.Context, body map[string]any) (*searchResponse, error) {
+	var resp searchResponse
+	if err := c.do(ctx, http.MethodPost, "/_search", body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *searchClient) do(ctx context.Context, method, path string, body, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authHeader != "" {
+		req.Header.Set("Authorization", c.authHeader)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, respBody)
+	}
+	if v == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// formatKeepAlive formats a duration with the time units of the APIs.
+func formatKeepAlive(d time.Duration) string {
+	if d%time.Second == 0 {
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+const (
+	distributionElasticsearch = "elasticsearch"
+	distributionOpenSearch    = "opensearch"
+
+	mappingModeBodyMap = "bodymap"
+	mappingModeOTel    = "otel"
+
+	// maxPageSize is the default index.max_result_window of the indices.
+	maxPageSize = 10000
+)
+
+var (
+	errEmptyEndpoint        = errors.New("endpoint must be specified")
+	errEndpointBadScheme    = errors.New("endpoint scheme must be http or https")
+	errUsernameNotSpecified = errors.New("password was specified, but not username")
+	errPasswordNotSpecified = errors.New("username was specified, but not password")
+	errEmptyIndex           = errors.New("index must be specified")
+	errEmptyTimestampField  = errors.New("timestamp_field must be specified")
+)
+
+// Config defines the configuration of the Elasticsearch query receiver.
+type Config struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Distribution is the search engine of the cluster, elasticsearch or opensearch, whose point in time
+	// APIs differ.
+	Distribution string `mapstructure:"distribution"`
+
+	// Username is the username used to authenticate the requests. Must be specified if Password is.
+	Username string `mapstructure:"username"`
+	// Password is the password used to authenticate the requests. Must be specified if Username is.
+	Password configopaque.String `mapstructure:"password"`
+	// APIKey is the base64 encoded API key used to authenticate the requests.
+	APIKey configopaque.String `mapstructure:"api_key"`
+
+	// Index is the index, data stream, alias or index pattern the documents are read from.
+	Index string `mapstructure:"index"`
+	// Query is the query DSL selecting the documents. All the documents are selected when it is empty.
+	Query map[string]any `mapstructure:"query"`
+	// TimestampField is the date field the documents are read in the order of. The documents without the
+	// field aren't read.
+	TimestampField string `mapstructure:"timestamp_field"`
+
+	// PageSize is the number of documents of each search request.
+	PageSize int `mapstructure:"page_size"`
+	// KeepAlive is how long the point in time of a poll is kept between two search requests.
+	KeepAlive time.Duration `mapstructure:"keep_alive"`
+	// PollInterval is the interval between the polls reading the documents indexed since the previous one.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// Mapping defines how the documents are mapped to log records.
+	Mapping MappingSettings `mapstructure:"mapping"`
+
+	// StorageID is the ID of the storage extension persisting the progress of the receiver, so that the
+	// documents already read aren't read again after a restart.
+	StorageID *component.ID `mapstructure:"storage"`
+}
+
+// MappingSettings defines how the documents are mapped to log records.
+type MappingSettings struct {
+	// Mode is the mapping mode: bodymap stores the document in the body of the log record, and otel
+	// decodes the documents written by the otel mapping mode of the Elasticsearch exporter.
+	Mode string `mapstructure:"mode"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errEmptyEndpoint
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %w", cfg.Endpoint, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return errEndpointBadScheme
+	}
+
+	if cfg.Username == "" && cfg.Password != "" {
+		return errUsernameNotSpecified
+	}
+	if cfg.Password == "" && cfg.Username != "" {
+		return errPasswordNotSpecified
+	}
+
+	switch cfg.Distribution {
+	case distributionElasticsearch, distributionOpenSearch:
+	default:
+		return fmt.Errorf("distribution must be %q or %q, got %q", distributionElasticsearch, distributionOpenSearch, cfg.Distribution)
+	}
+	if cfg.Index == "" {
+		return errEmptyIndex
+	}
+	if cfg.TimestampField == "" {
+		return errEmptyTimestampField
+	}
+	if cfg.PageSize <= 0 || cfg.PageSize > maxPageSize {
+		return fmt.Errorf("page_size must be between 1 and %d, got %d", maxPageSize, cfg.PageSize)
+	}
+	if cfg.KeepAlive < time.Second {
+		return fmt.Errorf("keep_alive must be at least 1s, got %s", cfg.KeepAlive)
+	}
+	if cfg.PollInterval <= 0 {
+		return errors.New("poll_interval must be positive")
+	}
+
+	switch cfg.Mapping.Mode {
+	case mappingModeBodyMap, mappingModeOTel:
+	default:
+		return fmt.Errorf("mapping::mode must be %q or %q, got %q", mappingModeBodyMap, mappingModeOTel, cfg.Mapping.Mode)
+	}
+	return nil
+}
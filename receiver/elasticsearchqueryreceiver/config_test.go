@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id       component.ID
+		expected func(cfg *Config)
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: func(*Config) {},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "opensearch"),
+			expected: func(cfg *Config) {
+				cfg.Endpoint = "https://opensearch:9200"
+				cfg.Distribution = distributionOpenSearch
+				cfg.Username = "otel"
+				cfg.Password = "secret"
+				cfg.Index = "logs-generic-default"
+				cfg.Query = map[string]any{"term": map[string]any{"service.name": "checkout"}}
+				cfg.TimestampField = "event.created"
+				cfg.PageSize = 500
+				cfg.KeepAlive = 5 * time.Minute
+				cfg.PollInterval = 10 * time.Second
+				cfg.Mapping.Mode = mappingModeOTel
+				cfg.StorageID = &storageID
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+			require.NoError(t, xconfmap.Validate(cfg))
+
+			expected := factory.CreateDefaultConfig().(*Config)
+			tt.expected(expected)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "empty endpoint",
+			modify: func(cfg *Config) { cfg.Endpoint = "" },
+			err:    errEmptyEndpoint.Error(),
+		},
+		{
+			name:   "bad scheme",
+			modify: func(cfg *Config) { cfg.Endpoint = "ftp://localhost:9200" },
+			err:    errEndpointBadScheme.Error(),
+		},
+		{
+			name:   "username without password",
+			modify: func(cfg *Config) { cfg.Username = "otel" },
+			err:    errPasswordNotSpecified.Error(),
+		},
+		{
+			name:   "password without username",
+			modify: func(cfg *Config) { cfg.Password = "secret" },
+			err:    errUsernameNotSpecified.Error(),
+		},
+		{
+			name:   "unknown distribution",
+			modify: func(cfg *Config) { cfg.Distribution = "solr" },
+			err:    `distribution must be "elasticsearch" or "opensearch", got "solr"`,
+		},
+		{
+			name:   "empty index",
+			modify: func(cfg *Config) { cfg.Index = "" },
+			err:    errEmptyIndex.Error(),
+		},
+		{
+			name:   "empty timestamp field",
+			modify: func(cfg *Config) { cfg.TimestampField = "" },
+			err:    errEmptyTimestampField.Error(),
+		},
+		{
+			name:   "page size too large",
+			modify: func(cfg *Config) { cfg.PageSize = 20000 },
+			err:    "page_size must be between 1 and 10000, got 20000",
+		},
+		{
+			name:   "keep alive too short",
+			modify: func(cfg *Config) { cfg.KeepAlive = 100 * time.Millisecond },
+			err:    "keep_alive must be at least 1s, got 100ms",
+		},
+		{
+			name:   "zero poll interval",
+			modify: func(cfg *Config) { cfg.PollInterval = 0 },
+			err:    "poll_interval must be positive",
+		},
+		{
+			name:   "unknown mapping mode",
+			modify: func(cfg *Config) { cfg.Mapping.Mode = "ecs" },
+			err:    `mapping::mode must be "bodymap" or "otel", got "ecs"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			assert.EqualError(t, xconfmap.Validate(cfg), tt.err)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package elasticsearchqueryreceiver reads the documents matching a query from Elasticsearch or OpenSearch
+// as logs.
+package elasticsearchqueryreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver"
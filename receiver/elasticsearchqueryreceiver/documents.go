@@ -0,0 +1,313 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver"
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver/internal/metadata"
+)
+
+// logsBuilder appends the documents to logs as log records.
+type logsBuilder struct {
+	mode     string
+	logs     plog.Logs
+	observed pcommon.Timestamp
+	// resourceLogs and scopeLogs hold the resource logs and scope logs by their encoded resource and scope.
+	resourceLogs map[string]plog.ResourceLogs
+	scopeLogs    map[string]plog.ScopeLogs
+}
+
+func newLogsBuilder(mode string) *logsBuilder {
+	return &logsBuilder{
+		mode:         mode,
+		logs:         plog.NewLogs(),
+		observed:     pcommon.NewTimestampFromTime(time.Now()),
+		resourceLogs: map[string]plog.ResourceLogs{},
+		scopeLogs:    map[string]plog.ScopeLogs{},
+	}
+}
+
+// append appends the document to the logs, timestamp being the sort value of its timestamp field.
+func (b *logsBuilder) append(hit searchHit, timestamp int64) error {
+	if b.mode == mappingModeOTel {
+		return b.appendOTel(hit)
+	}
+	return b.appendBodyMap(hit, timestamp)
+}
+
+// appendBodyMap stores the document in the body of a log record.
+func (b *logsBuilder) appendBodyMap(hit searchHit, timestamp int64) error {
+	var source map[string]any
+	if err := decodeJSON(hit.Source, &source); err != nil {
+		return err
+	}
+	scopeLogs, err := b.scope(nil, nil)
+	if err != nil {
+		return err
+	}
+	record := scopeLogs.LogRecords().AppendEmpty()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(timestamp)))
+	record.SetObservedTimestamp(b.observed)
+	putValue(record.Body(), source)
+	return nil
+}
+
+// otelDocument is a log record written by the otel mapping mode of the Elasticsearch exporter.
+type otelDocument struct {
+	Timestamp              any            `json:"@timestamp"`
+	ObservedTimestamp      any            `json:"observed_timestamp"`
+	SeverityText           string         `json:"severity_text"`
+	SeverityNumber         int32          `json:"severity_number"`
+	TraceID                string         `json:"trace_id"`
+	SpanID                 string         `json:"span_id"`
+	EventName              string         `json:"event_name"`
+	Attributes             map[string]any `json:"attributes"`
+	DroppedAttributesCount uint32         `json:"dropped_attributes_count"`
+	DataStream             map[string]any `json:"data_stream"`
+	Body                   struct {
+		Text       any `json:"text"`
+		Structured any `json:"structured"`
+		Flattened  any `json:"flattened"`
+	} `json:"body"`
+	Resource json.RawMessage `json:"resource"`
+	Scope    json.RawMessage `json:"scope"`
+}
+
+type otelResource struct {
+	SchemaURL              string         `json:"schema_url"`
+	Attributes             map[string]any `json:"attributes"`
+	DroppedAttributesCount uint32         `json:"dropped_attributes_count"`
+}
+
+type otelScope struct {
+	SchemaURL              string         `json:"schema_url"`
+	Name                   string         `json:"name"`
+	Version                string         `json:"version"`
+	Attributes             map[string]any `json:"attributes"`
+	DroppedAttributesCount uint32         `json:"dropped_attributes_count"`
+}
+
+func (b *logsBuilder) appendOTel(hit searchHit) error {
+	var doc otelDocument
+	if err := decodeJSON(hit.Source, &doc); err != nil {
+		return err
+	}
+	timestamp, err := parseTimestamp(doc.Timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid @timestamp: %w", err)
+	}
+	observedTimestamp, err := parseTimestamp(doc.ObservedTimestamp)
+	if err != nil {
+		return fmt.Errorf("invalid observed_timestamp: %w", err)
+	}
+	var traceID pcommon.TraceID
+	if err := decodeID(doc.TraceID, traceID[:]); err != nil {
+		return fmt.Errorf("invalid trace_id: %w", err)
+	}
+	var spanID pcommon.SpanID
+	if err := decodeID(doc.SpanID, spanID[:]); err != nil {
+		return fmt.Errorf("invalid span_id: %w", err)
+	}
+	scopeLogs, err := b.scope(doc.Resource, doc.Scope)
+	if err != nil {
+		return err
+	}
+
+	record := scopeLogs.LogRecords().AppendEmpty()
+	record.SetTimestamp(timestamp)
+	record.SetObservedTimestamp(observedTimestamp)
+	record.SetSeverityText(doc.SeverityText)
+	record.SetSeverityNumber(plog.SeverityNumber(doc.SeverityNumber))
+	record.SetTraceID(traceID)
+	record.SetSpanID(spanID)
+	record.SetEventName(doc.EventName)
+	record.SetDroppedAttributesCount(doc.DroppedAttributesCount)
+	putAttributes(record.Attributes(), doc.Attributes)
+	// The data stream is kept in the attributes, which the exporter routes the documents with.
+	for k, v := range doc.DataStream {
+		putValue(record.Attributes().PutEmpty("data_stream."+k), v)
+	}
+	switch {
+	case doc.Body.Text != nil:
+		putValue(record.Body(), doc.Body.Text)
+	case doc.Body.Structured != nil:
+		putValue(record.Body(), doc.Body.Structured)
+	case doc.Body.Flattened != nil:
+		putValue(record.Body(), doc.Body.Flattened)
+	}
+	return nil
+}
+
+// scope returns the scope logs of the encoded resource and scope, which are appended when they don't
+// exist. The documents of the same resource and scope hold the same encoded resource and scope.
+func (b *logsBuilder) scope(resource, scope json.RawMessage) (plog.ScopeLogs, error) {
+	key := string(resource) + "\n" + string(scope)
+	if scopeLogs, ok := b.scopeLogs[key]; ok {
+		return scopeLogs, nil
+	}
+
+	resourceLogs, ok := b.resourceLogs[string(resource)]
+	if !ok {
+		resourceLogs = b.logs.ResourceLogs().AppendEmpty()
+		if resource != nil {
+			var r otelResource
+			if err := decodeJSON(resource, &r); err != nil {
+				return plog.ScopeLogs{}, fmt.Errorf("invalid resource: %w", err)
+			}
+			resourceLogs.SetSchemaUrl(r.SchemaURL)
+			putAttributes(resourceLogs.Resource().Attributes(), r.Attributes)
+			resourceLogs.Resource().SetDroppedAttributesCount(r.DroppedAttributesCount)
+		}
+		b.resourceLogs[string(resource)] = resourceLogs
+	}
+
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	if b.mode != mappingModeOTel {
+		scopeLogs.Scope().SetName(metadata.ScopeName)
+	} else if scope != nil {
+		var s otelScope
+		if err := decodeJSON(scope, &s); err != nil {
+			return plog.ScopeLogs{}, fmt.Errorf("invalid scope: %w", err)
+		}
+		scopeLogs.SetSchemaUrl(s.SchemaURL)
+		scopeLogs.Scope().SetName(s.Name)
+		scopeLogs.Scope().SetVersion(s.Version)
+		putAttributes(scopeLogs.Scope().Attributes(), s.Attributes)
+		scopeLogs.Scope().SetDroppedAttributesCount(s.DroppedAttributesCount)
+	}
+	b.scopeLogs[key] = scopeLogs
+	return scopeLogs, nil
+}
+
+// putAttributes puts the attributes of a document in dest. The geo points the exporter merges from the
+// geo.location.lat and geo.location.lon attributes are split back.
+func putAttributes(dest pcommon.Map, attributes map[string]any) {
+	for k, v := range attributes {
+		if lon, lat, ok := geoLocation(k, v); ok {
+			dest.PutDouble(k+".lat", lat)
+			dest.PutDouble(k+".lon", lon)
+			continue
+		}
+		putValue(dest.PutEmpty(k), v)
+	}
+}
+
+// geoLocation returns the longitude and latitude of a geo point attribute, stored as [lon, lat].
+func geoLocation(k string, v any) (float64, float64, bool) {
+	if k != "geo.location" && !strings.HasSuffix(k, ".geo.location") {
+		return 0, 0, false
+	}
+	point, ok := v.([]any)
+	if !ok || len(point) != 2 {
+		return 0, 0, false
+	}
+	lon, lonOK := point[0].(json.Number)
+	lat, latOK := point[1].(json.Number)
+	if !lonOK || !latOK {
+		return 0, 0, false
+	}
+	lonValue, lonErr := lon.Float64()
+	latValue, latErr := lat.Float64()
+	if lonErr != nil || latErr != nil {
+		return 0, 0, false
+	}
+	return lonValue, latValue, true
+}
+
+// putValue sets dest to a JSON value decoded with json.Decoder.UseNumber. The numbers without a fraction
+// or an exponent are integers.
+func putValue(dest pcommon.Value, v any) {
+	switch v := v.(type) {
+	case string:
+		dest.SetStr(v)
+	case bool:
+		dest.SetBool(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			dest.SetInt(i)
+		} else if f, err := v.Float64(); err == nil {
+			dest.SetDouble(f)
+		} else {
+			dest.SetStr(v.String())
+		}
+	case map[string]any:
+		m := dest.SetEmptyMap()
+		m.EnsureCapacity(len(v))
+		for k, e := range v {
+			putValue(m.PutEmpty(k), e)
+		}
+	case []any:
+		s := dest.SetEmptySlice()
+		s.EnsureCapacity(len(v))
+		for _, e := range v {
+			putValue(s.AppendEmpty(), e)
+		}
+	}
+}
+
+// parseTimestamp parses a timestamp of a document: the otel mapping mode writes the milliseconds and the
+// remaining nanoseconds separated by a dot, and the other writers usually write RFC 3339 dates or
+// milliseconds.
+func parseTimestamp(v any) (pcommon.Timestamp, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		millis, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return pcommon.Timestamp(millis * float64(time.Millisecond)), nil
+	case string:
+		if millis, nanos, ok := strings.Cut(v, "."); ok {
+			msec, msecErr := strconv.ParseUint(millis, 10, 64)
+			nsec, nsecErr := strconv.ParseUint(nanos, 10, 64)
+			if msecErr == nil && nsecErr == nil {
+				return pcommon.Timestamp(msec*uint64(time.Millisecond) + nsec), nil
+			}
+		}
+		if msec, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return pcommon.Timestamp(msec * uint64(time.Millisecond)), nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, err
+		}
+		return pcommon.NewTimestampFromTime(t), nil
+	default:
+		return 0, fmt.Errorf("unexpected value %v", v)
+	}
+}
+
+// decodeID decodes a hex encoded trace or span ID into id. An empty ID is left as is.
+func decodeID(s string, id []byte) error {
+	if s == "" {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(id) {
+		return fmt.Errorf("expected %d bytes, got %d", len(id), len(b))
+	}
+	copy(id, b)
+	return nil
+}
+
+func decodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// otelLogDocument is a log record written by the otel mapping mode of the Elasticsearch exporter.
+const otelLogDocument = `{
+	"@timestamp": "1700000000000.123",
+	"observed_timestamp": "1700000001000.0",
+	"data_stream": {"type": "logs", "dataset": "checkout.otel", "namespace": "default"},
+	"severity_text": "ERROR",
+	"severity_number": 17,
+	"trace_id": "0102030405060708090a0b0c0d0e0f10",
+	"span_id": "0102030405060708",
+	"attributes": {
+		"http.response.status_code": 500,
+		"duration": 1.0,
+		"retried": true,
+		"tags": ["a", "b"],
+		"client.geo.location": [2.35, 48.86]
+	},
+	"event_name": "checkout.failed",
+	"resource": {"schema_url": "https://opentelemetry.io/schemas/1.26.0", "attributes": {"service.name": "checkout"}, "dropped_attributes_count": 1},
+	"scope": {"name": "checkout", "version": "1.2.0", "attributes": {"component": "cart"}},
+	"body": {"text": "payment failed"}
+}`
+
+func testHit(id, source string) searchHit {
+	return searchHit{Index: "logs-checkout.otel-default", ID: id, Source: json.RawMessage(source)}
+}
+
+func TestLogsBuilder_otel(t *testing.T) {
+	b := newLogsBuilder(mappingModeOTel)
+	require.NoError(t, b.append(testHit("1", otelLogDocument), 1700000000000))
+	require.NoError(t, b.append(testHit("2", `{
+		"@timestamp": "2023-11-14T22:13:21.5Z",
+		"resource": {"schema_url": "https://opentelemetry.io/schemas/1.26.0", "attributes": {"service.name": "checkout"}, "dropped_attributes_count": 1},
+		"scope": {"name": "checkout", "version": "1.2.0", "attributes": {"component": "cart"}},
+		"body": {"structured": {"amount": 12.5, "items": [{"sku": "a"}]}}
+	}`), 1700000001500))
+	require.NoError(t, b.append(testHit("3", `{
+		"@timestamp": 1700000002000,
+		"resource": {"attributes": {"service.name": "cart"}},
+		"scope": {}
+	}`), 1700000002000))
+
+	logs := b.logs
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.26.0", rl.SchemaUrl())
+	assert.Equal(t, map[string]any{"service.name": "checkout"}, rl.Resource().Attributes().AsRaw())
+	assert.Equal(t, uint32(1), rl.Resource().DroppedAttributesCount())
+	require.Equal(t, 1, rl.ScopeLogs().Len())
+	sl := rl.ScopeLogs().At(0)
+	assert.Equal(t, "checkout", sl.Scope().Name())
+	assert.Equal(t, "1.2.0", sl.Scope().Version())
+	assert.Equal(t, map[string]any{"component": "cart"}, sl.Scope().Attributes().AsRaw())
+	require.Equal(t, 2, sl.LogRecords().Len())
+
+	record := sl.LogRecords().At(0)
+	assert.Equal(t, pcommon.Timestamp(1700000000000*int64(time.Millisecond)+123), record.Timestamp())
+	assert.Equal(t, pcommon.Timestamp(1700000001000*int64(time.Millisecond)), record.ObservedTimestamp())
+	assert.Equal(t, "ERROR", record.SeverityText())
+	assert.Equal(t, plog.SeverityNumberError, record.SeverityNumber())
+	assert.Equal(t, pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, record.TraceID())
+	assert.Equal(t, pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, record.SpanID())
+	assert.Equal(t, "checkout.failed", record.EventName())
+	assert.Equal(t, map[string]any{
+		"http.response.status_code": int64(500),
+		"duration":                  1.0,
+		"retried":                   true,
+		"tags":                      []any{"a", "b"},
+		"client.geo.location.lon":   2.35,
+		"client.geo.location.lat":   48.86,
+		"data_stream.type":          "logs",
+		"data_stream.dataset":       "checkout.otel",
+		"data_stream.namespace":     "default",
+	}, record.Attributes().AsRaw())
+	assert.Equal(t, "payment failed", record.Body().Str())
+
+	record = sl.LogRecords().At(1)
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 21, 5e8, time.UTC), record.Timestamp().AsTime())
+	assert.Equal(t, map[string]any{"amount": 12.5, "items": []any{map[string]any{"sku": "a"}}}, record.Body().Map().AsRaw())
+
+	record = logs.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, time.UnixMilli(1700000002000).UTC(), record.Timestamp().AsTime())
+	assert.Equal(t, pcommon.ValueTypeEmpty, record.Body().Type())
+}
+
+func TestLogsBuilder_otelErrors(t *testing.T) {
+	for source, expected := range map[string]string{
+		`{"@timestamp": "yesterday"}`:            "invalid @timestamp",
+		`{"observed_timestamp": true}`:           "invalid observed_timestamp",
+		`{"trace_id": "0102"}`:                   "invalid trace_id: expected 16 bytes, got 2",
+		`{"span_id": "xyz"}`:                     "invalid span_id",
+		`{"resource": {"attributes": "a"}}`:      "invalid resource",
+		`{"scope": {"name": 1}}`:                 "invalid scope",
+		`{"attributes": "a", "severity_text": 1`: "unexpected EOF",
+	} {
+		b := newLogsBuilder(mappingModeOTel)
+		assert.ErrorContains(t, b.append(testHit("1", source), 0), expected, source)
+	}
+}
+
+func TestLogsBuilder_bodyMap(t *testing.T) {
+	b := newLogsBuilder(mappingModeBodyMap)
+	require.NoError(t, b.append(testHit("1", `{"@timestamp": "2023-11-14T22:13:20Z", "message": "a", "count": 2}`), 1700000000000))
+	require.NoError(t, b.append(testHit("2", `{"@timestamp": "2023-11-14T22:13:21Z", "message": "b", "resource": {"x": 1.5}}`), 1700000001000))
+
+	require.Equal(t, 1, b.logs.ResourceLogs().Len())
+	records := b.logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), records.At(0).Timestamp().AsTime())
+	assert.NotZero(t, records.At(0).ObservedTimestamp())
+	assert.Equal(t, map[string]any{"@timestamp": "2023-11-14T22:13:20Z", "message": "a", "count": int64(2)}, records.At(0).Body().Map().AsRaw())
+	assert.Equal(t, map[string]any{"x": 1.5}, records.At(1).Body().Map().AsRaw()["resource"])
+}
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver/internal/metadata"
+)
+
+const defaultEndpoint = "http://localhost:9200"
+
+// NewFactory creates a factory for the Elasticsearch query receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = defaultEndpoint
+	clientConfig.Timeout = 30 * time.Second
+
+	return &Config{
+		ClientConfig:   clientConfig,
+		Distribution:   distributionElasticsearch,
+		Index:          "logs-*",
+		TimestampField: "@timestamp",
+		PageSize:       1000,
+		KeepAlive:      time.Minute,
+		PollInterval:   time.Minute,
+		Mapping: MappingSettings{
+			Mode: mappingModeBodyMap,
+		},
+	}
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	next consumer.Logs,
+) (receiver.Logs, error) {
+	return newLogsReceiver(cfg.(*Config), set, next)
+}
@@ -0,0 +1,338 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver/internal/metadata"
+)
+
+const checkpointStorageKey = "checkpoint"
+
+// maxCheckpointDocuments is the maximum number of documents with the timestamp of the checkpoint kept, so the
+// checkpoint is bounded whatever the number of documents with the same timestamp.
+const maxCheckpointDocuments = 10000
+
+// checkpoint is the progress of the receiver. The documents are read in the order of their timestamp, so
+// the next poll reads the documents from the timestamp of the last document read, skipping the documents
+// with this timestamp already read.
+type checkpoint struct {
+	// Timestamp is the sort value of the timestamp field of the last document read, in milliseconds.
+	Timestamp int64 `json:"timestamp"`
+	// Documents are the index and ID of the documents with the timestamp already read.
+	Documents []string `json:"documents"`
+	// Complete is set once more than maxCheckpointDocuments documents with the timestamp are read, the
+	// next poll then reads the documents after the timestamp only.
+	Complete bool `json:"complete,omitempty"`
+
+	// read indexes the documents, it's built on the first lookup.
+	read map[string]struct{}
+}
+
+func (c *checkpoint) isEmpty() bool {
+	return len(c.Documents) == 0 && !c.Complete
+}
+
+// contains returns whether the document was read. The documents with the timestamp of a complete checkpoint
+// read by the current poll aren't kept, and aren't read again by the next polls.
+func (c *checkpoint) contains(timestamp int64, document string) bool {
+	if timestamp != c.Timestamp || c.Complete {
+		return false
+	}
+	if c.read == nil {
+		c.read = make(map[string]struct{}, len(c.Documents))
+		for _, d := range c.Documents {
+			c.read[d] = struct{}{}
+		}
+	}
+	_, ok := c.read[document]
+	return ok
+}
+
+func (c *checkpoint) add(timestamp int64, document string) {
+	switch {
+	case c.isEmpty() || timestamp > c.Timestamp:
+		*c = checkpoint{Timestamp: timestamp, Documents: []string{document}}
+	case timestamp == c.Timestamp && !c.Complete:
+		if len(c.Documents) == maxCheckpointDocuments {
+			*c = checkpoint{Timestamp: timestamp, Complete: true}
+			return
+		}
+		c.Documents = append(c.Documents, document)
+		if c.read != nil {
+			c.read[document] = struct{}{}
+		}
+	}
+}
+
+// clone returns a copy of the checkpoint which can be updated independently.
+func (c *checkpoint) clone() checkpoint {
+	return checkpoint{Timestamp: c.Timestamp, Documents: slices.Clone(c.Documents), Complete: c.Complete}
+}
+
+type logsReceiver struct {
+	config   *Config
+	settings receiver.Settings
+	next     consumer.Logs
+	obsrecv  *receiverhelper.ObsReport
+
+	client        *searchClient
+	storageClient storage.Client
+	checkpoint    checkpoint
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newLogsReceiver(cfg *Config, set receiver.Settings, next consumer.Logs) (*logsReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &logsReceiver{
+		config:   cfg,
+		settings: set,
+		next:     next,
+		obsrecv:  obsrecv,
+	}, nil
+}
+
+func (r *logsReceiver) Start(ctx context.Context, host component.Host) error {
+	var err error
+	r.client, err = newSearchClient(ctx, r.config, host, r.settings.TelemetrySettings)
+	if err != nil {
+		return err
+	}
+	r.storageClient, err = getStorageClient(ctx, host, r.config.StorageID, r.settings.ID)
+	if err != nil {
+		return fmt.Errorf("error connecting to storage: %w", err)
+	}
+	if err = r.loadCheckpoint(ctx); err != nil {
+		return err
+	}
+
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go r.run(ctx)
+	return nil
+}
+
+func (r *logsReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	if r.storageClient != nil {
+		return r.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+func (r *logsReceiver) run(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.poll(ctx); err != nil && ctx.Err() == nil {
+			r.settings.Logger.Error("Failed to read the documents", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the documents indexed since the previous poll, page by page in a point in time.
+func (r *logsReceiver) poll(ctx context.Context) (err error) {
+	pit, err := r.client.openPointInTime(ctx, r.config.Index, r.config.KeepAlive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, r.client.closePointInTime(context.WithoutCancel(ctx), pit))
+	}()
+
+	query := r.query()
+	var searchAfter []json.RawMessage
+	for {
+		request := r.searchRequest(query, pit, searchAfter)
+		resp, err := r.client.search(ctx, request)
+		if err != nil {
+			return err
+		}
+		if resp.PitID != "" {
+			pit = resp.PitID
+		}
+		hits := resp.Hits.Hits
+		if len(hits) == 0 {
+			return nil
+		}
+		if err := r.consume(ctx, hits); err != nil {
+			return err
+		}
+		if len(hits) < r.config.PageSize {
+			return nil
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+}
+
+// query returns the configured query, restricted to the documents with a timestamp since the checkpoint.
+func (r *logsReceiver) query() map[string]any {
+	filters := []any{
+		map[string]any{"exists": map[string]any{"field": r.config.TimestampField}},
+	}
+	if len(r.config.Query) > 0 {
+		filters = append(filters, r.config.Query)
+	}
+	if !r.checkpoint.isEmpty() {
+		operator := "gte"
+		if r.checkpoint.Complete {
+			operator = "gt"
+		}
+		filters = append(filters, map[string]any{"range": map[string]any{
+			r.config.TimestampField: map[string]any{operator: r.checkpoint.Timestamp, "format": "epoch_millis"},
+		}})
+	}
+	return map[string]any{"bool": map[string]any{"filter": filters}}
+}
+
+func (r *logsReceiver) searchRequest(query map[string]any, pit string, searchAfter []json.RawMessage) map[string]any {
+	// The sort values of date_nanos fields are converted to milliseconds, like the ones of date fields.
+	sort := []any{
+		map[string]any{r.config.TimestampField: map[string]any{"order": "asc", "numeric_type": "date"}},
+	}
+	// Elasticsearch adds an implicit _shard_doc tiebreaker to the searches of a point in time, which
+	// OpenSearch doesn't.
+	if r.config.Distribution == distributionOpenSearch {
+		sort = append(sort, map[string]any{"_id": "asc"})
+	}
+	request := map[string]any{
+		"size":             r.config.PageSize,
+		"query":            query,
+		"sort":             sort,
+		"track_total_hits": false,
+		"pit": map[string]any{
+			"id":         pit,
+			"keep_alive": formatKeepAlive(r.config.KeepAlive),
+		},
+	}
+	if searchAfter != nil {
+		request["search_after"] = searchAfter
+	}
+	return request
+}
+
+// consume sends the documents not read yet to the next consumer, and moves the checkpoint past them.
+func (r *logsReceiver) consume(ctx context.Context, hits []searchHit) error {
+	next := r.checkpoint.clone()
+	builder := newLogsBuilder(r.config.Mapping.Mode)
+	for _, hit := range hits {
+		timestamp, err := sortTimestamp(hit)
+		if err != nil {
+			return err
+		}
+		document := hit.Index + "/" + hit.ID
+		if next.contains(timestamp, document) {
+			continue
+		}
+		next.add(timestamp, document)
+		if err := builder.append(hit, timestamp); err != nil {
+			// The document can't be read again, so it's skipped not to block the receiver.
+			r.settings.Logger.Warn("Skipping a document which can't be decoded",
+				zap.String("index", hit.Index), zap.String("id", hit.ID), zap.Error(err))
+		}
+	}
+
+	logs := builder.logs
+	if count := logs.LogRecordCount(); count > 0 {
+		obsCtx := r.obsrecv.StartLogsOp(ctx)
+		err := r.next.ConsumeLogs(obsCtx, logs)
+		r.obsrecv.EndLogsOp(obsCtx, metadata.Type.String(), count, err)
+		if err != nil {
+			return fmt.Errorf("failed to consume the logs: %w", err)
+		}
+	}
+	r.checkpoint = next
+	return r.storeCheckpoint(ctx)
+}
+
+func (r *logsReceiver) loadCheckpoint(ctx context.Context) error {
+	data, err := r.storageClient.Get(ctx, checkpointStorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to get the checkpoint: %w", err)
+	}
+	if data == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &r.checkpoint); err != nil {
+		return fmt.Errorf("failed to decode the checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *logsReceiver) storeCheckpoint(ctx context.Context) error {
+	data, err := json.Marshal(r.checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := r.storageClient.Set(ctx, checkpointStorageKey, data); err != nil {
+		return fmt.Errorf("failed to store the checkpoint: %w", err)
+	}
+	return nil
+}
+
+// sortTimestamp returns the sort value of the timestamp field of the document.
+func sortTimestamp(hit searchHit) (int64, error) {
+	if len(hit.Sort) == 0 {
+		return 0, fmt.Errorf("document %q of %q has no sort values", hit.ID, hit.Index)
+	}
+	value := string(hit.Sort[0])
+	if timestamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return timestamp, nil
+	}
+	timestamp, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp sort value %s of document %q of %q", value, hit.ID, hit.Index)
+	}
+	return int64(timestamp), nil
+}
+
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	if storageID == nil {
+		return storage.NewNopClient(), nil
+	}
+
+	extension, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+
+	return storageExtension.GetClient(ctx, component.KindReceiver, componentID, "")
+}
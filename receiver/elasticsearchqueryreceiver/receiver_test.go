@@ -0,0 +1,304 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchqueryreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/elasticsearchqueryreceiver/internal/metadata"
+)
+
+type testDocument struct {
+	id        int
+	timestamp int64
+}
+
+// testCluster is a fake cluster serving the point in time APIs of a distribution. The documents are sorted
+// by timestamp and ID, the ID being the tiebreaker.
+type testCluster struct {
+	mu           sync.Mutex
+	distribution string
+	documents    []testDocument
+	// pits holds the documents of the open points in time.
+	pits     map[string][]testDocument
+	opened   int
+	closed   int
+	searches []map[string]any
+}
+
+func newTestCluster(t *testing.T, distribution string) (*testCluster, *httptest.Server) {
+	c := &testCluster{distribution: distribution, pits: map[string][]testDocument{}}
+	pitPath, pitIDKey := "/logs-*/_pit", "id"
+	closePath := "/_pit"
+	if distribution == distributionOpenSearch {
+		pitPath, pitIDKey = "/logs-*/_search/point_in_time", "pit_id"
+		closePath = "/_search/point_in_time"
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if r.Header.Get("Authorization") != "ApiKey a2V5" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		if r.Body != http.NoBody {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == pitPath:
+			assert.Equal(t, "60s", r.URL.Query().Get("keep_alive"))
+			c.opened++
+			id := "pit-" + strconv.Itoa(c.opened)
+			c.pits[id] = slices.Clone(c.documents)
+			_ = json.NewEncoder(w).Encode(map[string]any{pitIDKey: id})
+		case r.Method == http.MethodDelete && r.URL.Path == closePath:
+			id := body[pitIDKey]
+			if ids, ok := id.([]any); ok {
+				id = ids[0]
+			}
+			delete(c.pits, id.(string))
+			c.closed++
+		case r.Method == http.MethodPost && r.URL.Path == "/_search":
+			c.searches = append(c.searches, body)
+			documents, ok := c.pits[body["pit"].(map[string]any)["id"].(string)]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(c.search(documents, body))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return c, server
+}
+
+func (c *testCluster) search(documents []testDocument, body map[string]any) map[string]any {
+	gte := int64(-1)
+	for _, filter := range body["query"].(map[string]any)["bool"].(map[string]any)["filter"].([]any) {
+		if r, ok := filter.(map[string]any)["range"]; ok {
+			gte = int64(r.(map[string]any)["@timestamp"].(map[string]any)["gte"].(float64))
+		}
+	}
+	after := testDocument{timestamp: -1}
+	if searchAfter, ok := body["search_after"].([]any); ok {
+		after.timestamp = int64(searchAfter[0].(float64))
+		if id, ok := searchAfter[1].(string); ok {
+			after.id, _ = strconv.Atoi(id)
+		} else {
+			after.id = int(searchAfter[1].(float64))
+		}
+	}
+
+	hits := []any{}
+	for _, d := range documents {
+		if d.timestamp < gte || d.timestamp < after.timestamp || (d.timestamp == after.timestamp && d.id <= after.id) {
+			continue
+		}
+		if len(hits) == int(body["size"].(float64)) {
+			break
+		}
+		var tiebreaker any = d.id
+		if c.distribution == distributionOpenSearch {
+			tiebreaker = strconv.Itoa(d.id)
+		}
+		hits = append(hits, map[string]any{
+			"_index":  "logs-generic-default",
+			"_id":     strconv.Itoa(d.id),
+			"_source": map[string]any{"@timestamp": d.timestamp, "message": "log " + strconv.Itoa(d.id)},
+			"sort":    []any{d.timestamp, tiebreaker},
+		})
+	}
+	return map[string]any{
+		"pit_id": body["pit"].(map[string]any)["id"],
+		"hits":   map[string]any{"hits": hits},
+	}
+}
+
+func (c *testCluster) addDocuments(documents ...testDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.documents = append(c.documents, documents...)
+}
+
+func (c *testCluster) closedPits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// runPoll starts a receiver, waits for its first poll and shuts it down.
+func runPoll(t *testing.T, cluster *testCluster, cfg *Config, host component.Host, next consumer.Logs) {
+	closed := cluster.closedPits()
+	// The ID of the receiver identifies its storage
+	set := receivertest.NewNopSettings(metadata.Type)
+	set.ID = component.NewID(metadata.Type)
+	r, err := newLogsReceiver(cfg, set, next)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), host))
+	assert.Eventually(t, func() bool {
+		return cluster.closedPits() > closed
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func messages(sink *consumertest.LogsSink) []string {
+	var result []string
+	for _, logs := range sink.AllLogs() {
+		records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < records.Len(); i++ {
+			result = append(result, records.At(i).Body().Map().AsRaw()["message"].(string))
+		}
+	}
+	return result
+}
+
+func newTestConfig(endpoint, distribution string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.Distribution = distribution
+	cfg.APIKey = "a2V5"
+	cfg.PageSize = 2
+	cfg.PollInterval = time.Hour
+	storageID := storagetest.NewStorageID("test")
+	cfg.StorageID = &storageID
+	return cfg
+}
+
+func TestLogsReceiver(t *testing.T) {
+	for _, distribution := range []string{distributionElasticsearch, distributionOpenSearch} {
+		t.Run(distribution, func(t *testing.T) {
+			cluster, server := newTestCluster(t, distribution)
+			cluster.addDocuments(testDocument{1, 1000}, testDocument{2, 2000}, testDocument{3, 2000})
+			cfg := newTestConfig(server.URL, distribution)
+			host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+			sink := new(consumertest.LogsSink)
+			runPoll(t, cluster, cfg, host, sink)
+			assert.Equal(t, []string{"log 1", "log 2", "log 3"}, messages(sink))
+			records := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0)
+			assert.Equal(t, metadata.ScopeName, records.Scope().Name())
+			assert.Equal(t, time.UnixMilli(1000).UTC(), records.LogRecords().At(0).Timestamp().AsTime())
+
+			// The documents are paginated in the point in time
+			require.Len(t, cluster.searches, 2)
+			assert.Equal(t, map[string]any{"id": "pit-1", "keep_alive": "60s"}, cluster.searches[0]["pit"])
+			assert.NotContains(t, cluster.searches[0], "search_after")
+			assert.Contains(t, cluster.searches[1], "search_after")
+			assert.Len(t, cluster.searches[0]["sort"], map[string]int{distributionElasticsearch: 1, distributionOpenSearch: 2}[distribution])
+			assert.Empty(t, cluster.pits)
+
+			// The progress is restored after a restart, and the documents indexed since then are read
+			cluster.addDocuments(testDocument{4, 2000}, testDocument{5, 3000})
+			sink.Reset()
+			runPoll(t, cluster, cfg, host, sink)
+			assert.Equal(t, []string{"log 4", "log 5"}, messages(sink))
+			assert.Equal(t, map[string]any{"range": map[string]any{"@timestamp": map[string]any{
+				"gte": float64(2000), "format": "epoch_millis",
+			}}}, cluster.searches[2]["query"].(map[string]any)["bool"].(map[string]any)["filter"].([]any)[1])
+
+			sink.Reset()
+			runPoll(t, cluster, cfg, host, sink)
+			assert.Empty(t, messages(sink))
+		})
+	}
+}
+
+func TestLogsReceiver_query(t *testing.T) {
+	cluster, server := newTestCluster(t, distributionElasticsearch)
+	cfg := newTestConfig(server.URL, distributionElasticsearch)
+	cfg.Query = map[string]any{"term": map[string]any{"service.name": "checkout"}}
+	cfg.StorageID = nil
+
+	runPoll(t, cluster, cfg, storagetest.NewStorageHost(), consumertest.NewNop())
+	require.Len(t, cluster.searches, 1)
+	assert.Equal(t, map[string]any{"bool": map[string]any{"filter": []any{
+		map[string]any{"exists": map[string]any{"field": "@timestamp"}},
+		map[string]any{"term": map[string]any{"service.name": "checkout"}},
+	}}}, cluster.searches[0]["query"])
+}
+
+func TestLogsReceiver_consumeError(t *testing.T) {
+	cluster, server := newTestCluster(t, distributionElasticsearch)
+	cluster.addDocuments(testDocument{1, 1000}, testDocument{2, 2000}, testDocument{3, 3000})
+	cfg := newTestConfig(server.URL, distributionElasticsearch)
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	// The progress isn't saved when the logs are rejected, and the point in time is closed anyway
+	runPoll(t, cluster, cfg, host, consumertest.NewErr(errors.New("rejected")))
+	assert.Len(t, cluster.searches, 1)
+
+	sink := new(consumertest.LogsSink)
+	runPoll(t, cluster, cfg, host, sink)
+	assert.Equal(t, []string{"log 1", "log 2", "log 3"}, messages(sink))
+}
+
+func TestLogsReceiver_storageNotFound(t *testing.T) {
+	cfg := newTestConfig("http://localhost:9200", distributionElasticsearch)
+	r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(metadata.Type), consumertest.NewNop())
+	require.NoError(t, err)
+	err = r.Start(context.Background(), storagetest.NewStorageHost())
+	assert.ErrorContains(t, err, "storage extension 'test_storage/test' not found")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestCheckpoint(t *testing.T) {
+	var c checkpoint
+	assert.True(t, c.isEmpty())
+	c.add(1000, "a")
+	c.add(1000, "b")
+	assert.True(t, c.contains(1000, "b"))
+	assert.False(t, c.contains(1000, "c"))
+	c.add(2000, "c")
+	assert.Equal(t, int64(2000), c.Timestamp)
+	assert.Equal(t, []string{"c"}, c.Documents)
+	assert.False(t, c.contains(1000, "a"))
+
+	// The documents with the timestamp aren't kept beyond the maximum
+	for i := 1; i < maxCheckpointDocuments; i++ {
+		c.add(2000, strconv.Itoa(i))
+	}
+	assert.Len(t, c.Documents, maxCheckpointDocuments)
+	assert.True(t, c.contains(2000, "1"))
+	c.add(2000, "d")
+	assert.Equal(t, checkpoint{Timestamp: 2000, Complete: true}, c)
+	assert.False(t, c.isEmpty())
+	assert.False(t, c.contains(2000, "1"))
+	c.add(2000, "e")
+	assert.Empty(t, c.Documents)
+	c.add(3000, "f")
+	assert.Equal(t, checkpoint{Timestamp: 3000, Documents: []string{"f"}}, c)
+}
+
+func TestLogsReceiver_completeCheckpoint(t *testing.T) {
+	cfg := newTestConfig("http://localhost:9200", distributionElasticsearch)
+	r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(metadata.Type), consumertest.NewNop())
+	require.NoError(t, err)
+
+	// The documents with the timestamp of a complete checkpoint aren't read again
+	r.checkpoint = checkpoint{Timestamp: 2000, Complete: true}
+	assert.Equal(t, map[string]any{"range": map[string]any{"@timestamp": map[string]any{
+		"gt": int64(2000), "format": "epoch_millis",
+	}}}, r.query()["bool"].(map[string]any)["filter"].([]any)[1])
+}
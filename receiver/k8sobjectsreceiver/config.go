@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/collector/component"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apiWatch "k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -28,6 +29,10 @@ const (
 	defaultResourceVersion               = "1"
 )
 
+// defaultDiffIgnoredPaths are the fields ignored by the diff mode when ignored_paths isn't set, which change on
+// every update of an object.
+var defaultDiffIgnoredPaths = []string{"metadata.managedFields", "metadata.resourceVersion"}
+
 var modeMap = map[mode]bool{
 	PullMode:  true,
 	WatchMode: true,
@@ -43,15 +48,29 @@ type K8sObjectsConfig struct {
 	Interval         time.Duration        `mapstructure:"interval"`
 	ResourceVersion  string               `mapstructure:"resource_version"`
 	ExcludeWatchType []apiWatch.EventType `mapstructure:"exclude_watch_type"`
+	Diff             DiffConfig           `mapstructure:"diff"`
 	exclude          map[apiWatch.EventType]bool
+	ignoredPaths     []fieldPath
 	gvr              *schema.GroupVersionResource
 }
 
+// DiffConfig configures the diff mode of the watch mode, which emits only the changes of the objects on MODIFIED
+// events instead of the whole objects.
+type DiffConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// IgnoredPaths are the fields not compared, like `metadata.managedFields` or
+	// `status.conditions[*].lastHeartbeatTime`.
+	IgnoredPaths []string `mapstructure:"ignored_paths"`
+}
+
 type Config struct {
 	k8sconfig.APIConfig `mapstructure:",squash"`
 
 	Objects []*K8sObjectsConfig `mapstructure:"objects"`
 
+	// StorageID is the ID of the storage extension saving the last seen objects of the diff mode.
+	StorageID *component.ID `mapstructure:"storage"`
+
 	// For mocking purposes only.
 	makeDiscoveryClient func() (discovery.ServerResourcesInterface, error)
 	makeDynamicClient   func() (dynamic.Interface, error)
@@ -94,6 +113,20 @@ func (c *Config) Validate() error {
 			return errors.New("the Exclude config can only be used with watch mode")
 		}
 
+		if object.Diff.Enabled {
+			if object.Mode != WatchMode {
+				return errors.New("the Diff config can only be used with watch mode")
+			}
+			if object.Diff.IgnoredPaths == nil {
+				object.Diff.IgnoredPaths = defaultDiffIgnoredPaths
+			}
+			for _, path := range object.Diff.IgnoredPaths {
+				if _, err := parseFieldPath(path); err != nil {
+					return err
+				}
+			}
+		}
+
 		object.gvr = gvr
 	}
 	return nil
@@ -23,6 +23,7 @@ import (
 func TestLoadConfig(t *testing.T) {
 	t.Parallel()
 
+	fileStorageID := component.MustNewID("file_storage")
 	tests := []struct {
 		id       component.ID
 		expected *Config
@@ -132,12 +133,57 @@ func TestLoadConfig(t *testing.T) {
 				makeDiscoveryClient: getMockDiscoveryClient,
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "diff"),
+			expected: &Config{
+				APIConfig: k8sconfig.APIConfig{
+					AuthType: k8sconfig.AuthTypeServiceAccount,
+				},
+				StorageID: &fileStorageID,
+				Objects: []*K8sObjectsConfig{
+					{
+						Name: "pods",
+						Mode: WatchMode,
+						Diff: DiffConfig{
+							Enabled:      true,
+							IgnoredPaths: []string{"metadata.managedFields", "metadata.resourceVersion"},
+						},
+						gvr: &schema.GroupVersionResource{
+							Group:    "",
+							Version:  "v1",
+							Resource: "pods",
+						},
+					},
+					{
+						Name:  "events",
+						Mode:  WatchMode,
+						Group: "events.k8s.io",
+						Diff: DiffConfig{
+							Enabled:      true,
+							IgnoredPaths: []string{"metadata.managedFields", "status.conditions[*].lastHeartbeatTime"},
+						},
+						gvr: &schema.GroupVersionResource{
+							Group:    "events.k8s.io",
+							Version:  "v1",
+							Resource: "events",
+						},
+					},
+				},
+				makeDiscoveryClient: getMockDiscoveryClient,
+			},
+		},
 		{
 			id: component.NewIDWithName(metadata.Type, "invalid_resource"),
 		},
 		{
 			id: component.NewIDWithName(metadata.Type, "exclude_deleted_with_pull"),
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "diff_with_pull"),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "diff_invalid_path"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +207,7 @@ func TestLoadConfig(t *testing.T) {
 			assert.NoError(t, xconfmap.Validate(cfg))
 			assert.Equal(t, tt.expected.AuthType, cfg.AuthType)
 			assert.Equal(t, tt.expected.Objects, cfg.Objects)
+			assert.Equal(t, tt.expected.StorageID, cfg.StorageID)
 		})
 	}
 }
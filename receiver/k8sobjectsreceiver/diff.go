@@ -0,0 +1,397 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package k8sobjectsreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/k8sobjectsreceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	apiWatch "k8s.io/apimachinery/pkg/watch"
+)
+
+// pathSegment is a segment of a field path: a key of a map or an index of a list, or every entry of a map or list.
+type pathSegment struct {
+	key      string
+	wildcard bool
+}
+
+type fieldPath []pathSegment
+
+// parseFieldPath parses a path like `status.conditions[*].lastHeartbeatTime`. The keys are separated by dots,
+// and the keys containing dots are written in brackets, like `metadata.annotations[example.com/key]`.
+func parseFieldPath(s string) (fieldPath, error) {
+	var path fieldPath
+	for rest := s; rest != ""; {
+		var key string
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", s)
+			}
+			key, rest = rest[1:end], rest[end+1:]
+			if key == "*" {
+				path = append(path, pathSegment{wildcard: true})
+			} else {
+				path = append(path, pathSegment{key: key})
+			}
+			if rest != "" && rest[0] != '.' && rest[0] != '[' {
+				return nil, fmt.Errorf("invalid path %q: expected . or [ after ]", s)
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+			path = append(path, pathSegment{key: key})
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid path %q: empty key", s)
+		}
+		if rest != "" && rest[0] == '.' {
+			if rest = rest[1:]; rest == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", s)
+			}
+		}
+	}
+	if len(path) == 0 {
+		return nil, errors.New("invalid path: empty path")
+	}
+	return path, nil
+}
+
+// removeField removes the fields of the path from value, and returns the value.
+func removeField(value any, path fieldPath) any {
+	segment, last := path[0], len(path) == 1
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if !segment.wildcard && key != segment.key {
+				continue
+			}
+			if last {
+				delete(v, key)
+			} else {
+				v[key] = removeField(child, path[1:])
+			}
+		}
+	case []any:
+		if segment.wildcard {
+			if last {
+				return v[:0]
+			}
+			for i := range v {
+				v[i] = removeField(v[i], path[1:])
+			}
+			return v
+		}
+		i, err := strconv.Atoi(segment.key)
+		if err != nil || i < 0 || i >= len(v) {
+			return v
+		}
+		if last {
+			return append(v[:i:i], v[i+1:]...)
+		}
+		v[i] = removeField(v[i], path[1:])
+	}
+	return value
+}
+
+// diffValues appends to ops the JSON patch operations changing the value at path from old to new.
+func diffValues(ops []any, path string, old, new any) []any {
+	switch o := old.(type) {
+	case map[string]any:
+		if n, ok := new.(map[string]any); ok {
+			return diffMaps(ops, path, o, n)
+		}
+	case []any:
+		if n, ok := new.([]any); ok {
+			return diffLists(ops, path, o, n)
+		}
+	default:
+		if reflect.DeepEqual(old, new) {
+			return ops
+		}
+	}
+	return append(ops, patchOperation("replace", path, new))
+}
+
+func diffMaps(ops []any, path string, old, new map[string]any) []any {
+	keys := make([]string, 0, len(old)+len(new))
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		keyPath := path + "/" + escapePointer(key)
+		oldValue, inOld := old[key]
+		newValue, inNew := new[key]
+		switch {
+		case !inNew:
+			ops = append(ops, map[string]any{"op": "remove", "path": keyPath})
+		case !inOld:
+			ops = append(ops, patchOperation("add", keyPath, newValue))
+		default:
+			ops = diffValues(ops, keyPath, oldValue, newValue)
+		}
+	}
+	return ops
+}
+
+// diffLists compares the elements of the lists by index. The elements added or removed at the end of the list are
+// added or removed, the last one first.
+func diffLists(ops []any, path string, old, new []any) []any {
+	for i := 0; i < len(old) && i < len(new); i++ {
+		ops = diffValues(ops, path+"/"+strconv.Itoa(i), old[i], new[i])
+	}
+	for i := len(old); i < len(new); i++ {
+		ops = append(ops, patchOperation("add", path+"/"+strconv.Itoa(i), new[i]))
+	}
+	for i := len(old) - 1; i >= len(new); i-- {
+		ops = append(ops, map[string]any{"op": "remove", "path": path + "/" + strconv.Itoa(i)})
+	}
+	return ops
+}
+
+func patchOperation(op, path string, value any) map[string]any {
+	return map[string]any{"op": op, "path": path, "value": value}
+}
+
+// escapePointer escapes a key in a JSON pointer, see https://datatracker.ietf.org/doc/html/rfc6901#section-3.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// objectHeader returns the fields identifying an object.
+func objectHeader(obj *unstructured.Unstructured) map[string]any {
+	metadata := map[string]any{
+		"name":            obj.GetName(),
+		"uid":             string(obj.GetUID()),
+		"resourceVersion": obj.GetResourceVersion(),
+	}
+	if namespace := obj.GetNamespace(); namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return map[string]any{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"metadata":   metadata,
+	}
+}
+
+// objectDiffer keeps the last seen objects by UID, without their ignored fields, to emit only their changes on the
+// MODIFIED events. The objects are saved in the storage client, so that they are kept after a restart, along with
+// the index of the UIDs saved for each watch scope, so that the objects deleted while the receiver was stopped are
+// removed once the scope is listed again.
+type objectDiffer struct {
+	logger  *zap.Logger
+	client  storage.Client
+	mu      sync.Mutex
+	objects map[types.UID]map[string]any
+	// indexes are the UIDs of the objects saved by scope, loaded from the storage client on first use.
+	indexes map[string]map[types.UID]struct{}
+}
+
+func newObjectDiffer(logger *zap.Logger, client storage.Client) *objectDiffer {
+	return &objectDiffer{
+		logger:  logger,
+		client:  client,
+		objects: map[types.UID]map[string]any{},
+		indexes: map[string]map[types.UID]struct{}{},
+	}
+}
+
+// watchScope identifies the objects watched with a configuration in a namespace, an empty namespace standing for
+// all the namespaces.
+func watchScope(config *K8sObjectsConfig, namespace string) string {
+	return strings.Join([]string{config.gvr.String(), namespace, config.LabelSelector, config.FieldSelector}, "|")
+}
+
+// watchObjectsToLogData converts a watch event to log data. A MODIFIED event of a known object is converted to its
+// changes, and to no log record when only ignored fields changed. The other events are converted to the whole
+// object.
+func (d *objectDiffer) watchObjectsToLogData(ctx context.Context, scope string, event *apiWatch.Event, observedAt time.Time, config *K8sObjectsConfig) (plog.Logs, error) {
+	udata, ok := event.Object.(*unstructured.Unstructured)
+	if !ok || udata.GetUID() == "" {
+		return watchObjectsToLogData(event, observedAt, config)
+	}
+	uid := udata.GetUID()
+
+	switch event.Type {
+	case apiWatch.Added, apiWatch.Modified:
+		current := runtime.DeepCopyJSON(udata.Object)
+		for _, path := range config.ignoredPaths {
+			removeField(current, path)
+		}
+		previous := d.swap(ctx, scope, uid, current)
+		if event.Type == apiWatch.Modified && previous != nil {
+			ops := diffValues(nil, "", previous, current)
+			if len(ops) == 0 {
+				return plog.NewLogs(), nil
+			}
+			return watchObjectDiffToLogData(event, udata, ops, observedAt, config), nil
+		}
+	case apiWatch.Deleted:
+		d.delete(ctx, uid)
+		d.updateIndex(ctx, scope, func(index map[types.UID]struct{}) bool {
+			_, ok := index[uid]
+			delete(index, uid)
+			return ok
+		})
+	}
+	return watchObjectsToLogData(event, observedAt, config)
+}
+
+// swap saves the current object, and returns the previous one, or nil when the object wasn't seen.
+func (d *objectDiffer) swap(ctx context.Context, scope string, uid types.UID, current map[string]any) map[string]any {
+	d.mu.Lock()
+	previous, ok := d.objects[uid]
+	d.objects[uid] = current
+	d.mu.Unlock()
+
+	if !ok {
+		previous = d.load(ctx, uid)
+		d.updateIndex(ctx, scope, func(index map[types.UID]struct{}) bool {
+			_, ok := index[uid]
+			index[uid] = struct{}{}
+			return !ok
+		})
+	}
+	data, err := json.Marshal(current)
+	if err == nil {
+		err = d.client.Set(ctx, string(uid), data)
+	}
+	if err != nil {
+		d.logger.Warn("failed to save the object", zap.String("uid", string(uid)), zap.Error(err))
+	}
+	return previous
+}
+
+// load returns the object saved in the storage client, or nil when there isn't one.
+func (d *objectDiffer) load(ctx context.Context, uid types.UID) map[string]any {
+	data, err := d.client.Get(ctx, string(uid))
+	if err != nil {
+		d.logger.Warn("failed to load the object", zap.String("uid", string(uid)), zap.Error(err))
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+	var obj map[string]any
+	// The numbers are decoded to int64 or float64, like the objects of the watch events.
+	if err := utiljson.Unmarshal(data, &obj); err != nil {
+		d.logger.Warn("failed to decode the object", zap.String("uid", string(uid)), zap.Error(err))
+		return nil
+	}
+	return obj
+}
+
+func (d *objectDiffer) delete(ctx context.Context, uid types.UID) {
+	d.mu.Lock()
+	delete(d.objects, uid)
+	d.mu.Unlock()
+
+	if err := d.client.Delete(ctx, string(uid)); err != nil {
+		d.logger.Warn("failed to delete the object", zap.String("uid", string(uid)), zap.Error(err))
+	}
+}
+
+// prune removes the objects of the scope saved before, which aren't listed anymore, like the objects deleted while
+// the receiver was stopped, whose DELETED events were missed.
+func (d *objectDiffer) prune(ctx context.Context, scope string, listed []unstructured.Unstructured) {
+	uids := make(map[types.UID]struct{}, len(listed))
+	for i := range listed {
+		uids[listed[i].GetUID()] = struct{}{}
+	}
+	var removed []types.UID
+	d.updateIndex(ctx, scope, func(index map[types.UID]struct{}) bool {
+		for uid := range index {
+			if _, ok := uids[uid]; !ok {
+				delete(index, uid)
+				removed = append(removed, uid)
+			}
+		}
+		return len(removed) > 0
+	})
+	for _, uid := range removed {
+		d.delete(ctx, uid)
+	}
+	if len(removed) > 0 {
+		d.logger.Debug("removed the objects which aren't listed anymore", zap.String("scope", scope), zap.Int("count", len(removed)))
+	}
+}
+
+// updateIndex updates the index of the UIDs of the scope, and saves it when update returns true.
+func (d *objectDiffer) updateIndex(ctx context.Context, scope string, update func(index map[types.UID]struct{}) bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	index, ok := d.indexes[scope]
+	if !ok {
+		index = d.loadIndex(ctx, scope)
+		d.indexes[scope] = index
+	}
+	if !update(index) {
+		return
+	}
+	uids := make([]string, 0, len(index))
+	for uid := range index {
+		uids = append(uids, string(uid))
+	}
+	slices.Sort(uids)
+	data, err := json.Marshal(uids)
+	if err == nil {
+		err = d.client.Set(ctx, indexStorageKey(scope), data)
+	}
+	if err != nil {
+		d.logger.Warn("failed to save the index of the objects", zap.String("scope", scope), zap.Error(err))
+	}
+}
+
+// loadIndex returns the index of the UIDs of the scope saved in the storage client, or an empty one.
+func (d *objectDiffer) loadIndex(ctx context.Context, scope string) map[types.UID]struct{} {
+	index := map[types.UID]struct{}{}
+	data, err := d.client.Get(ctx, indexStorageKey(scope))
+	if err != nil {
+		d.logger.Warn("failed to load the index of the objects", zap.String("scope", scope), zap.Error(err))
+		return index
+	}
+	if data == nil {
+		return index
+	}
+	var uids []types.UID
+	if err := json.Unmarshal(data, &uids); err != nil {
+		d.logger.Warn("failed to decode the index of the objects", zap.String("scope", scope), zap.Error(err))
+		return index
+	}
+	for _, uid := range uids {
+		index[uid] = struct{}{}
+	}
+	return index
+}
+
+// indexStorageKey returns the storage key of the index of a scope, which can't be a UID.
+func indexStorageKey(scope string) string {
+	return "index/" + scope
+}
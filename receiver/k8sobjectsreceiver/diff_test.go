@@ -0,0 +1,351 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package k8sobjectsreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		expected fieldPath
+		err      string
+	}{
+		{
+			path:     "metadata.managedFields",
+			expected: fieldPath{{key: "metadata"}, {key: "managedFields"}},
+		},
+		{
+			path:     "status.conditions[*].lastHeartbeatTime",
+			expected: fieldPath{{key: "status"}, {key: "conditions"}, {wildcard: true}, {key: "lastHeartbeatTime"}},
+		},
+		{
+			path:     "metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]",
+			expected: fieldPath{{key: "metadata"}, {key: "annotations"}, {key: "kubectl.kubernetes.io/last-applied-configuration"}},
+		},
+		{
+			path:     "spec.containers[0][*]",
+			expected: fieldPath{{key: "spec"}, {key: "containers"}, {key: "0"}, {wildcard: true}},
+		},
+		{
+			path: "",
+			err:  "invalid path: empty path",
+		},
+		{
+			path: "metadata..name",
+			err:  `invalid path "metadata..name": empty key`,
+		},
+		{
+			path: "metadata.",
+			err:  `invalid path "metadata.": empty key`,
+		},
+		{
+			path: "status.conditions[*",
+			err:  `invalid path "status.conditions[*": missing ]`,
+		},
+		{
+			path: "status.conditions[]",
+			err:  `invalid path "status.conditions[]": empty key`,
+		},
+		{
+			path: "status.conditions[*]type",
+			err:  `invalid path "status.conditions[*]type": expected . or [ after ]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := parseFieldPath(tt.path)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestRemoveField(t *testing.T) {
+	t.Parallel()
+
+	newObject := func() map[string]any {
+		return map[string]any{
+			"metadata": map[string]any{
+				"name":          "node1",
+				"managedFields": []any{map[string]any{"manager": "kubelet"}},
+				"annotations":   map[string]any{"example.com/key": "value", "other": "value"},
+			},
+			"status": map[string]any{
+				"conditions": []any{
+					map[string]any{"type": "Ready", "lastHeartbeatTime": "2025-01-01T00:00:00Z"},
+					map[string]any{"type": "MemoryPressure", "lastHeartbeatTime": "2025-01-01T00:00:00Z"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		path     string
+		expected func(map[string]any)
+	}{
+		{
+			path: "metadata.managedFields",
+			expected: func(obj map[string]any) {
+				delete(obj["metadata"].(map[string]any), "managedFields")
+			},
+		},
+		{
+			path: "metadata.annotations[example.com/key]",
+			expected: func(obj map[string]any) {
+				delete(obj["metadata"].(map[string]any)["annotations"].(map[string]any), "example.com/key")
+			},
+		},
+		{
+			path: "status.conditions[*].lastHeartbeatTime",
+			expected: func(obj map[string]any) {
+				for _, condition := range obj["status"].(map[string]any)["conditions"].([]any) {
+					delete(condition.(map[string]any), "lastHeartbeatTime")
+				}
+			},
+		},
+		{
+			path: "status.conditions[0]",
+			expected: func(obj map[string]any) {
+				status := obj["status"].(map[string]any)
+				status["conditions"] = status["conditions"].([]any)[1:]
+			},
+		},
+		{
+			path: "status.conditions[*]",
+			expected: func(obj map[string]any) {
+				obj["status"].(map[string]any)["conditions"] = []any{}
+			},
+		},
+		{
+			path:     "spec.replicas",
+			expected: func(map[string]any) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := parseFieldPath(tt.path)
+			require.NoError(t, err)
+			expected := newObject()
+			tt.expected(expected)
+			assert.Equal(t, expected, removeField(newObject(), path))
+		})
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	t.Parallel()
+
+	old := map[string]any{
+		"spec": map[string]any{
+			"replicas": int64(1),
+			"paused":   false,
+			"selector": map[string]any{"app": "web"},
+			"containers": []any{
+				map[string]any{"name": "web", "image": "web:1"},
+				map[string]any{"name": "sidecar", "image": "sidecar:1"},
+			},
+			"tolerations": []any{"a"},
+		},
+		"metadata": map[string]any{
+			"annotations": map[string]any{"example.com/removed": "true"},
+		},
+	}
+	new := map[string]any{
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"paused":   false,
+			"selector": "app=web",
+			"containers": []any{
+				map[string]any{"name": "web", "image": "web:2"},
+			},
+			"tolerations": []any{"a", "b", "c"},
+		},
+		"metadata": map[string]any{
+			"annotations": map[string]any{"example.com/added": "true"},
+		},
+	}
+
+	assert.Equal(t, []any{
+		map[string]any{"op": "add", "path": "/metadata/annotations/example.com~1added", "value": "true"},
+		map[string]any{"op": "remove", "path": "/metadata/annotations/example.com~1removed"},
+		map[string]any{"op": "replace", "path": "/spec/containers/0/image", "value": "web:2"},
+		map[string]any{"op": "remove", "path": "/spec/containers/1"},
+		map[string]any{"op": "replace", "path": "/spec/replicas", "value": int64(3)},
+		map[string]any{"op": "replace", "path": "/spec/selector", "value": "app=web"},
+		map[string]any{"op": "add", "path": "/spec/tolerations/1", "value": "b"},
+		map[string]any{"op": "add", "path": "/spec/tolerations/2", "value": "c"},
+	}, diffValues(nil, "", old, new))
+
+	assert.Empty(t, diffValues(nil, "", old, old))
+}
+
+func TestObjectDiffer(t *testing.T) {
+	t.Parallel()
+
+	path, err := parseFieldPath("metadata.resourceVersion")
+	require.NoError(t, err)
+	config := &K8sObjectsConfig{
+		Diff:         DiffConfig{Enabled: true},
+		ignoredPaths: []fieldPath{path},
+		gvr: &schema.GroupVersionResource{
+			Version:  "v1",
+			Resource: "configmaps",
+		},
+	}
+	newEvent := func(eventType watch.EventType, resourceVersion string, data map[string]any) *watch.Event {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":            "config",
+				"namespace":       "default",
+				"uid":             "b6f2c1d4",
+				"resourceVersion": resourceVersion,
+			},
+			"data": data,
+		}}
+		return &watch.Event{Type: eventType, Object: obj}
+	}
+	body := func(t *testing.T, logs plog.Logs) map[string]any {
+		require.Equal(t, 1, logs.LogRecordCount())
+		return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Map().AsRaw()
+	}
+
+	client := storagetest.NewInMemoryClient(component.KindReceiver, component.MustNewID("k8sobjects"), "")
+	differ := newObjectDiffer(zap.NewNop(), client)
+	ctx := context.Background()
+	scope := watchScope(config, "default")
+
+	// The added object is emitted whole.
+	logs, err := differ.watchObjectsToLogData(ctx, scope, newEvent(watch.Added, "1", map[string]any{"key": "value"}), time.Now(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "ADDED", body(t, logs)["type"])
+	assert.Contains(t, body(t, logs)["object"], "data")
+
+	// The modified object is emitted as its changes.
+	logs, err = differ.watchObjectsToLogData(ctx, scope, newEvent(watch.Modified, "2", map[string]any{"key": "new"}), time.Now(), config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"type": "MODIFIED",
+		"object": map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":            "config",
+				"namespace":       "default",
+				"uid":             "b6f2c1d4",
+				"resourceVersion": "2",
+			},
+		},
+		"patch": []any{
+			map[string]any{"op": "replace", "path": "/data/key", "value": "new"},
+		},
+	}, body(t, logs))
+
+	// Nothing is emitted when only ignored fields changed.
+	logs, err = differ.watchObjectsToLogData(ctx, scope, newEvent(watch.Modified, "3", map[string]any{"key": "new"}), time.Now(), config)
+	require.NoError(t, err)
+	assert.Zero(t, logs.LogRecordCount())
+
+	// The object is loaded from the storage by another differ, like after a restart.
+	differ = newObjectDiffer(zap.NewNop(), client)
+	logs, err = differ.watchObjectsToLogData(ctx, scope, newEvent(watch.Modified, "4", map[string]any{"key": "new", "other": int64(1)}), time.Now(), config)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"op": "add", "path": "/data/other", "value": int64(1)},
+	}, body(t, logs)["patch"])
+
+	// The deleted object is emitted whole and forgotten.
+	logs, err = differ.watchObjectsToLogData(ctx, scope, newEvent(watch.Deleted, "5", map[string]any{"key": "new"}), time.Now(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "DELETED", body(t, logs)["type"])
+	data, err := client.Get(ctx, "b6f2c1d4")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	logs, err = differ.watchObjectsToLogData(ctx, scope, newEvent(watch.Modified, "6", map[string]any{"key": "new"}), time.Now(), config)
+	require.NoError(t, err)
+	assert.Contains(t, body(t, logs)["object"], "data")
+}
+
+func TestObjectDifferPrune(t *testing.T) {
+	t.Parallel()
+
+	config := &K8sObjectsConfig{
+		Diff: DiffConfig{Enabled: true},
+		gvr: &schema.GroupVersionResource{
+			Version:  "v1",
+			Resource: "configmaps",
+		},
+	}
+	newObject := func(uid string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": uid, "namespace": "default", "uid": uid},
+		}}
+	}
+	saved := func(t *testing.T, client storage.Client, uid string) bool {
+		data, err := client.Get(context.Background(), uid)
+		require.NoError(t, err)
+		return data != nil
+	}
+
+	client := storagetest.NewInMemoryClient(component.KindReceiver, component.MustNewID("k8sobjects"), "")
+	differ := newObjectDiffer(zap.NewNop(), client)
+	ctx := context.Background()
+	scope := watchScope(config, "default")
+	otherScope := watchScope(config, "other")
+	for _, uid := range []string{"a", "b"} {
+		_, err := differ.watchObjectsToLogData(ctx, scope, &watch.Event{Type: watch.Added, Object: newObject(uid)}, time.Now(), config)
+		require.NoError(t, err)
+	}
+	_, err := differ.watchObjectsToLogData(ctx, otherScope, &watch.Event{Type: watch.Added, Object: newObject("c")}, time.Now(), config)
+	require.NoError(t, err)
+
+	// The objects deleted while the receiver was stopped are removed once their scope is listed after a restart,
+	// and the objects of the other scopes are kept.
+	differ = newObjectDiffer(zap.NewNop(), client)
+	differ.prune(ctx, scope, []unstructured.Unstructured{*newObject("b")})
+	assert.False(t, saved(t, client, "a"))
+	assert.True(t, saved(t, client, "b"))
+	assert.True(t, saved(t, client, "c"))
+
+	data, err := client.Get(ctx, indexStorageKey(scope))
+	require.NoError(t, err)
+	assert.JSONEq(t, `["b"]`, string(data))
+
+	// The deleted objects are removed from the index.
+	_, err = differ.watchObjectsToLogData(ctx, scope, &watch.Event{Type: watch.Deleted, Object: newObject("b")}, time.Now(), config)
+	require.NoError(t, err)
+	differ = newObjectDiffer(zap.NewNop(), client)
+	differ.prune(ctx, scope, nil)
+	data, err = client.Get(ctx, indexStorageKey(scope))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(data))
+}
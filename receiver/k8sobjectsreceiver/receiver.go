@@ -13,11 +13,14 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/receiverhelper"
 	"go.uber.org/zap"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 	apiWatch "k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
@@ -34,6 +37,8 @@ type k8sobjectsreceiver struct {
 	client          dynamic.Interface
 	consumer        consumer.Logs
 	obsrecv         *receiverhelper.ObsReport
+	storageClient   storage.Client
+	differ          *objectDiffer
 	mu              sync.Mutex
 	cancel          context.CancelFunc
 }
@@ -55,6 +60,14 @@ func newReceiver(params receiver.Settings, config *Config, consumer consumer.Log
 		for _, item := range object.ExcludeWatchType {
 			object.exclude[item] = true
 		}
+		object.ignoredPaths = nil
+		for _, item := range object.Diff.IgnoredPaths {
+			path, err := parseFieldPath(item)
+			if err != nil {
+				return nil, err
+			}
+			object.ignoredPaths = append(object.ignoredPaths, path)
+		}
 	}
 
 	return &k8sobjectsreceiver{
@@ -66,12 +79,18 @@ func newReceiver(params receiver.Settings, config *Config, consumer consumer.Log
 	}, nil
 }
 
-func (kr *k8sobjectsreceiver) Start(ctx context.Context, _ component.Host) error {
+func (kr *k8sobjectsreceiver) Start(ctx context.Context, host component.Host) error {
 	client, err := kr.config.getDynamicClient()
 	if err != nil {
 		return err
 	}
 	kr.client = client
+
+	kr.storageClient, err = getStorageClient(ctx, host, kr.config.StorageID, kr.setting.ID)
+	if err != nil {
+		return fmt.Errorf("error connecting to storage: %w", err)
+	}
+	kr.differ = newObjectDiffer(kr.setting.Logger, kr.storageClient)
 	kr.setting.Logger.Info("Object Receiver started")
 
 	cctx, cancel := context.WithCancel(ctx)
@@ -83,7 +102,7 @@ func (kr *k8sobjectsreceiver) Start(ctx context.Context, _ component.Host) error
 	return nil
 }
 
-func (kr *k8sobjectsreceiver) Shutdown(context.Context) error {
+func (kr *k8sobjectsreceiver) Shutdown(ctx context.Context) error {
 	kr.setting.Logger.Info("Object Receiver stopped")
 	if kr.cancel != nil {
 		kr.cancel()
@@ -94,6 +113,10 @@ func (kr *k8sobjectsreceiver) Shutdown(context.Context) error {
 		close(stopperChan)
 	}
 	kr.mu.Unlock()
+
+	if kr.storageClient != nil {
+		return kr.storageClient.Close(ctx)
+	}
 	return nil
 }
 
@@ -113,10 +136,10 @@ func (kr *k8sobjectsreceiver) start(ctx context.Context, object *K8sObjectsConfi
 
 	case WatchMode:
 		if len(object.Namespaces) == 0 {
-			go kr.startWatch(ctx, object, resource)
+			go kr.startWatch(ctx, object, resource, "")
 		} else {
 			for _, ns := range object.Namespaces {
-				go kr.startWatch(ctx, object, resource.Namespace(ns))
+				go kr.startWatch(ctx, object, resource.Namespace(ns), ns)
 			}
 		}
 	}
@@ -158,7 +181,7 @@ func (kr *k8sobjectsreceiver) startPull(ctx context.Context, config *K8sObjectsC
 	}
 }
 
-func (kr *k8sobjectsreceiver) startWatch(ctx context.Context, config *K8sObjectsConfig, resource dynamic.ResourceInterface) {
+func (kr *k8sobjectsreceiver) startWatch(ctx context.Context, config *K8sObjectsConfig, resource dynamic.ResourceInterface, namespace string) {
 	stopperChan := make(chan struct{})
 	kr.mu.Lock()
 	kr.stopperChanList = append(kr.stopperChanList, stopperChan)
@@ -172,15 +195,20 @@ func (kr *k8sobjectsreceiver) startWatch(ctx context.Context, config *K8sObjects
 
 	cancelCtx, cancel := context.WithCancel(ctx)
 	cfgCopy := *config
+	scope := watchScope(config, namespace)
 	wait.UntilWithContext(cancelCtx, func(newCtx context.Context) {
-		resourceVersion, err := getResourceVersion(newCtx, &cfgCopy, resource)
+		resourceVersion, err := getResourceVersion(newCtx, &cfgCopy, resource, func(objects []unstructured.Unstructured) {
+			if cfgCopy.Diff.Enabled {
+				kr.differ.prune(newCtx, scope, objects)
+			}
+		})
 		if err != nil {
 			kr.setting.Logger.Error("could not retrieve a resourceVersion", zap.String("resource", cfgCopy.gvr.String()), zap.Error(err))
 			cancel()
 			return
 		}
 
-		done := kr.doWatch(newCtx, &cfgCopy, resourceVersion, watchFunc, stopperChan)
+		done := kr.doWatch(newCtx, &cfgCopy, scope, resourceVersion, watchFunc, stopperChan)
 		if done {
 			cancel()
 			return
@@ -192,7 +220,7 @@ func (kr *k8sobjectsreceiver) startWatch(ctx context.Context, config *K8sObjects
 }
 
 // doWatch returns true when watching is done, false when watching should be restarted.
-func (kr *k8sobjectsreceiver) doWatch(ctx context.Context, config *K8sObjectsConfig, resourceVersion string, watchFunc func(options metav1.ListOptions) (apiWatch.Interface, error), stopperChan chan struct{}) bool {
+func (kr *k8sobjectsreceiver) doWatch(ctx context.Context, config *K8sObjectsConfig, scope, resourceVersion string, watchFunc func(options metav1.ListOptions) (apiWatch.Interface, error), stopperChan chan struct{}) bool {
 	watcher, err := watch.NewRetryWatcher(resourceVersion, &cache.ListWatch{WatchFunc: watchFunc})
 	if err != nil {
 		kr.setting.Logger.Error("error in watching object", zap.String("resource", config.gvr.String()), zap.Error(err))
@@ -224,9 +252,16 @@ func (kr *k8sobjectsreceiver) doWatch(ctx context.Context, config *K8sObjectsCon
 				continue
 			}
 
-			logs, err := watchObjectsToLogData(&data, time.Now(), config)
+			var logs plog.Logs
+			if config.Diff.Enabled {
+				logs, err = kr.differ.watchObjectsToLogData(ctx, scope, &data, time.Now(), config)
+			} else {
+				logs, err = watchObjectsToLogData(&data, time.Now(), config)
+			}
 			if err != nil {
 				kr.setting.Logger.Error("error converting objects to log data", zap.Error(err))
+			} else if logs.LogRecordCount() == 0 {
+				kr.setting.Logger.Debug("dropping unchanged object", zap.String("type", string(data.Type)))
 			} else {
 				obsCtx := kr.obsrecv.StartLogsOp(ctx)
 				err := kr.consumer.ConsumeLogs(obsCtx, logs)
@@ -239,7 +274,9 @@ func (kr *k8sobjectsreceiver) doWatch(ctx context.Context, config *K8sObjectsCon
 	}
 }
 
-func getResourceVersion(ctx context.Context, config *K8sObjectsConfig, resource dynamic.ResourceInterface) (string, error) {
+// getResourceVersion returns the resource version the watch starts from, listing the objects when it isn't
+// configured, in which case the listed objects are passed to listed.
+func getResourceVersion(ctx context.Context, config *K8sObjectsConfig, resource dynamic.ResourceInterface, listed func([]unstructured.Unstructured)) (string, error) {
 	resourceVersion := config.ResourceVersion
 	if resourceVersion == "" || resourceVersion == "0" {
 		// Proper use of the Kubernetes API Watch capability when no resourceVersion is supplied is to do a list first
@@ -256,6 +293,7 @@ func getResourceVersion(ctx context.Context, config *K8sObjectsConfig, resource
 			return "", errors.New("nil objects returned, this is an error in the k8sobjectsreceiver")
 		}
 
+		listed(objects.Items)
 		resourceVersion = objects.GetResourceVersion()
 
 		// If we still don't have a resourceVersion we can try 1 as a last ditch effort.
@@ -268,6 +306,24 @@ func getResourceVersion(ctx context.Context, config *K8sObjectsConfig, resource
 	return resourceVersion, nil
 }
 
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	if storageID == nil {
+		return storage.NewNopClient(), nil
+	}
+
+	extension, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+
+	return storageExtension.GetClient(ctx, component.KindReceiver, componentID, "")
+}
+
 // Start ticking immediately.
 // Ref: https://stackoverflow.com/questions/32705582/how-to-get-time-tick-to-tick-immediately
 func newTicker(ctx context.Context, repeat time.Duration) *time.Ticker {
@@ -207,3 +207,69 @@ func TestExcludeDeletedTrue(t *testing.T) {
 
 	assert.NoError(t, r.Shutdown(ctx))
 }
+
+func TestWatchObjectDiff(t *testing.T) {
+	t.Parallel()
+
+	mockClient := newMockDynamicClient()
+
+	rCfg := createDefaultConfig().(*Config)
+	rCfg.makeDynamicClient = mockClient.getMockDynamicClient
+	rCfg.makeDiscoveryClient = getMockDiscoveryClient
+
+	rCfg.Objects = []*K8sObjectsConfig{
+		{
+			Name:       "pods",
+			Mode:       WatchMode,
+			Namespaces: []string{"default"},
+			Diff: DiffConfig{
+				Enabled: true,
+			},
+		},
+	}
+
+	err := rCfg.Validate()
+	require.NoError(t, err)
+
+	consumer := newMockLogConsumer()
+	r, err := newReceiver(
+		receivertest.NewNopSettings(metadata.Type),
+		rCfg,
+		consumer,
+	)
+
+	ctx := context.Background()
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.NoError(t, r.Start(ctx, componenttest.NewNopHost()))
+
+	time.Sleep(time.Millisecond * 100)
+
+	pod := generatePod("pod1", "default", map[string]any{
+		"environment": "test",
+	}, "1")
+	pod.SetUID("5c3e7a8b")
+	mockClient.createPods(pod)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 1, consumer.Count())
+
+	// Only the resource version changed, which is ignored.
+	pod.SetResourceVersion("2")
+	mockClient.updatePods(pod)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 1, consumer.Count())
+
+	pod.SetResourceVersion("3")
+	pod.SetLabels(map[string]string{"environment": "production"})
+	mockClient.updatePods(pod)
+	time.Sleep(time.Millisecond * 100)
+	logs := consumer.Logs()
+	require.Len(t, logs, 2)
+	body := logs[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Map().AsRaw()
+	assert.Equal(t, "MODIFIED", body["type"])
+	assert.Equal(t, []any{
+		map[string]any{"op": "replace", "path": "/metadata/labels/environment", "value": "production"},
+	}, body["patch"])
+
+	assert.NoError(t, r.Shutdown(ctx))
+}
@@ -41,6 +41,27 @@ func watchObjectsToLogData(event *watch.Event, observedAt time.Time, config *K8s
 	}), nil
 }
 
+// watchObjectDiffToLogData converts the changes of an object to log data, whose body holds the fields identifying
+// the object and the JSON patch operations of the changes.
+func watchObjectDiffToLogData(event *watch.Event, udata *unstructured.Unstructured, ops []any, observedAt time.Time, config *K8sObjectsConfig) plog.Logs {
+	ul := unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{{
+			Object: map[string]any{
+				"type":   string(event.Type),
+				"object": objectHeader(udata),
+				"patch":  ops,
+			},
+		}},
+	}
+
+	return unstructuredListToLogData(&ul, observedAt, config, func(attrs pcommon.Map) {
+		if name := udata.GetName(); name != "" {
+			attrs.PutStr("event.domain", "k8s")
+			attrs.PutStr("event.name", name)
+		}
+	})
+}
+
 func pullObjectsToLogData(event *unstructured.UnstructuredList, observedAt time.Time, config *K8sObjectsConfig) plog.Logs {
 	return unstructuredListToLogData(event, observedAt, config)
 }
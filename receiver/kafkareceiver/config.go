@@ -4,6 +4,10 @@
 package kafkareceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver"
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -38,6 +42,23 @@ type HeaderExtraction struct {
 	Headers        []string `mapstructure:"headers"`
 }
 
+type MessageMetadata struct {
+	// If true, the topic, partition and offset of the kafka message are added
+	// to the attributes of the telemetry decoded from the message.
+	Enabled bool `mapstructure:"enabled"`
+	// Level is where the attributes are added: "resource" (default) for
+	// the resource attributes, or "log_record" for the log record attributes.
+	// Traces and metrics always use the resource attributes.
+	Level string `mapstructure:"level"`
+}
+
+type DeadLetter struct {
+	// The name of the kafka topic the messages which can't be decoded, or are
+	// rejected by the next consumer with a permanent error, are republished to.
+	// The dead-letter topic is disabled when it's empty (default).
+	Topic string `mapstructure:"topic"`
+}
+
 // Config defines configuration for Kafka receiver.
 type Config struct {
 	// The list of kafka brokers (default localhost:9092)
@@ -55,8 +76,18 @@ type Config struct {
 	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
 	// The name of the kafka topic to consume from (default "otlp_spans" for traces, "otlp_metrics" for metrics, "otlp_logs" for logs)
 	Topic string `mapstructure:"topic"`
+	// The names of the kafka topics to consume from, instead of a single topic.
+	Topics []string `mapstructure:"topics"`
+	// The regular expression matching the names of the kafka topics to consume from.
+	// It must match the whole topic name, and the internal topics starting with "__" are ignored.
+	TopicPattern string `mapstructure:"topic_pattern"`
+	// How frequently to look up the topics matching the topic pattern, to consume
+	// from the topics created at runtime (default 30s).
+	TopicRefreshInterval time.Duration `mapstructure:"topic_refresh_interval"`
 	// Encoding of the messages (default "otlp_proto")
 	Encoding string `mapstructure:"encoding"`
+	// Encoding of the messages of specific topics, by topic name, overriding Encoding.
+	TopicEncodings map[string]string `mapstructure:"topic_encodings"`
 	// The consumer group that receiver will be consuming messages from (default "otel-collector")
 	GroupID string `mapstructure:"group_id"`
 	// The consumer client ID that receiver will use (default "otel-collector")
@@ -64,6 +95,9 @@ type Config struct {
 	// The initial offset to use if no offset was previously committed.
 	// Must be `latest` or `earliest` (default "latest").
 	InitialOffset string `mapstructure:"initial_offset"`
+	// Whether to read the messages of the aborted transactions.
+	// Must be `read_uncommitted` or `read_committed` (default "read_uncommitted").
+	IsolationLevel string `mapstructure:"isolation_level"`
 
 	// Metadata is the namespace for metadata management properties used by the
 	// Client, and shared by the Producer/Consumer.
@@ -80,6 +114,12 @@ type Config struct {
 	// Extract headers from kafka records
 	HeaderExtraction HeaderExtraction `mapstructure:"header_extraction"`
 
+	// Add the topic, partition and offset of kafka records as attributes
+	MessageMetadata MessageMetadata `mapstructure:"message_metadata"`
+
+	// Republish the messages which can't be consumed to a dead-letter topic
+	DeadLetter DeadLetter `mapstructure:"dead_letter"`
+
 	// The minimum bytes per fetch from Kafka (default "1")
 	MinFetchSize int32 `mapstructure:"min_fetch_size"`
 	// The default bytes per fetch from Kafka (default "1048576")
@@ -93,11 +133,55 @@ type Config struct {
 const (
 	offsetLatest   string = "latest"
 	offsetEarliest string = "earliest"
+
+	isolationLevelReadUncommitted string = "read_uncommitted"
+	isolationLevelReadCommitted   string = "read_committed"
+
+	metadataLevelResource  string = "resource"
+	metadataLevelLogRecord string = "log_record"
 )
 
 var _ component.Config = (*Config)(nil)
 
 // Validate checks the receiver configuration is valid
 func (cfg *Config) Validate() error {
+	if len(cfg.Topics) > 0 && cfg.TopicPattern != "" {
+		return errors.New("topics and topic_pattern cannot be both set")
+	}
+	if cfg.Topic != "" && (len(cfg.Topics) > 0 || cfg.TopicPattern != "") {
+		return errors.New("topic cannot be set with topics or topic_pattern")
+	}
+	if cfg.TopicPattern != "" {
+		if _, err := regexp.Compile(cfg.TopicPattern); err != nil {
+			return fmt.Errorf("invalid topic_pattern: %w", err)
+		}
+		if cfg.TopicRefreshInterval <= 0 {
+			return errors.New("topic_refresh_interval must be positive")
+		}
+	}
+	if cfg.DeadLetter.Topic != "" {
+		if slices.Contains(cfg.topics(), cfg.DeadLetter.Topic) {
+			return errors.New("dead_letter topic cannot be consumed by the receiver")
+		}
+		if cfg.TopicPattern != "" && regexp.MustCompile("^(?:"+cfg.TopicPattern+")$").MatchString(cfg.DeadLetter.Topic) {
+			return errors.New("dead_letter topic cannot match topic_pattern")
+		}
+	}
+	if _, err := toSaramaIsolationLevel(cfg.IsolationLevel); err != nil {
+		return fmt.Errorf("%w %q", err, cfg.IsolationLevel)
+	}
+	switch cfg.MessageMetadata.Level {
+	case "", metadataLevelResource, metadataLevelLogRecord:
+	default:
+		return fmt.Errorf("invalid message_metadata level %q", cfg.MessageMetadata.Level)
+	}
 	return nil
 }
+
+// topics returns the names of the topics to consume from, when they aren't matched by a pattern.
+func (cfg *Config) topics() []string {
+	if len(cfg.Topics) > 0 {
+		return cfg.Topics
+	}
+	return []string{cfg.Topic}
+}
@@ -42,8 +42,10 @@ func TestLoadConfig(t *testing.T) {
 				ClientID:                             "otel-collector",
 				GroupID:                              "otel-collector",
 				InitialOffset:                        "latest",
+				IsolationLevel:                       "read_uncommitted",
 				SessionTimeout:                       10 * time.Second,
 				HeartbeatInterval:                    3 * time.Second,
+				TopicRefreshInterval:                 30 * time.Second,
 				Authentication: kafka.Authentication{
 					TLS: &configtls.ClientConfig{
 						Config: configtls.Config{
@@ -64,6 +66,9 @@ func TestLoadConfig(t *testing.T) {
 					Enable:   true,
 					Interval: 1 * time.Second,
 				},
+				MessageMetadata: MessageMetadata{
+					Level: "resource",
+				},
 				MinFetchSize:     1,
 				DefaultFetchSize: 1048576,
 				MaxFetchSize:     0,
@@ -75,14 +80,16 @@ func TestLoadConfig(t *testing.T) {
 		{
 			id: component.NewIDWithName(metadata.Type, "logs"),
 			expected: &Config{
-				Topic:             "logs",
-				Encoding:          "direct",
-				Brokers:           []string{"coffee:123", "foobar:456"},
-				ClientID:          "otel-collector",
-				GroupID:           "otel-collector",
-				InitialOffset:     "earliest",
-				SessionTimeout:    45 * time.Second,
-				HeartbeatInterval: 15 * time.Second,
+				Topic:                "logs",
+				Encoding:             "direct",
+				Brokers:              []string{"coffee:123", "foobar:456"},
+				ClientID:             "otel-collector",
+				GroupID:              "otel-collector",
+				InitialOffset:        "earliest",
+				IsolationLevel:       "read_committed",
+				SessionTimeout:       45 * time.Second,
+				HeartbeatInterval:    15 * time.Second,
+				TopicRefreshInterval: 30 * time.Second,
 				Authentication: kafka.Authentication{
 					TLS: &configtls.ClientConfig{
 						Config: configtls.Config{
@@ -103,6 +110,9 @@ func TestLoadConfig(t *testing.T) {
 					Enable:   true,
 					Interval: 1 * time.Second,
 				},
+				MessageMetadata: MessageMetadata{
+					Level: "resource",
+				},
 				MinFetchSize:     1,
 				DefaultFetchSize: 1048576,
 				MaxFetchSize:     0,
@@ -115,6 +125,42 @@ func TestLoadConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "topics"),
+			expected: &Config{
+				TopicPattern:         "logs-.*",
+				TopicRefreshInterval: 10 * time.Second,
+				Encoding:             "otlp_proto",
+				TopicEncodings: map[string]string{
+					"logs-raw": "raw",
+				},
+				Brokers:           []string{"localhost:9092"},
+				ClientID:          "otel-collector",
+				GroupID:           "otel-collector",
+				InitialOffset:     "latest",
+				IsolationLevel:    "read_uncommitted",
+				SessionTimeout:    10 * time.Second,
+				HeartbeatInterval: 3 * time.Second,
+				Metadata: kafkaexporter.Metadata{
+					Full: true,
+					Retry: kafkaexporter.MetadataRetry{
+						Max:     3,
+						Backoff: 250 * time.Millisecond,
+					},
+				},
+				AutoCommit: AutoCommit{
+					Enable:   true,
+					Interval: 1 * time.Second,
+				},
+				MessageMetadata: MessageMetadata{
+					Enabled: true,
+					Level:   "log_record",
+				},
+				MinFetchSize:     1,
+				DefaultFetchSize: 1048576,
+				MaxFetchSize:     0,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,3 +177,73 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name: "topics and topic pattern",
+			modify: func(cfg *Config) {
+				cfg.Topics = []string{"spans"}
+				cfg.TopicPattern = "spans-.*"
+			},
+			expectedErr: "topics and topic_pattern cannot be both set",
+		},
+		{
+			name: "topic and topics",
+			modify: func(cfg *Config) {
+				cfg.Topic = "spans"
+				cfg.Topics = []string{"spans"}
+			},
+			expectedErr: "topic cannot be set with topics or topic_pattern",
+		},
+		{
+			name:        "invalid topic pattern",
+			modify:      func(cfg *Config) { cfg.TopicPattern = "spans-(" },
+			expectedErr: "invalid topic_pattern: error parsing regexp: missing closing ): `spans-(`",
+		},
+		{
+			name: "topic refresh interval",
+			modify: func(cfg *Config) {
+				cfg.TopicPattern = "spans-.*"
+				cfg.TopicRefreshInterval = 0
+			},
+			expectedErr: "topic_refresh_interval must be positive",
+		},
+		{
+			name:        "isolation level",
+			modify:      func(cfg *Config) { cfg.IsolationLevel = "serializable" },
+			expectedErr: `invalid isolation level "serializable"`,
+		},
+		{
+			name:        "message metadata level",
+			modify:      func(cfg *Config) { cfg.MessageMetadata.Level = "scope" },
+			expectedErr: `invalid message_metadata level "scope"`,
+		},
+		{
+			name: "dead letter topic consumed",
+			modify: func(cfg *Config) {
+				cfg.Topics = []string{"spans", "dead_spans"}
+				cfg.DeadLetter.Topic = "dead_spans"
+			},
+			expectedErr: "dead_letter topic cannot be consumed by the receiver",
+		},
+		{
+			name: "dead letter topic matching pattern",
+			modify: func(cfg *Config) {
+				cfg.TopicPattern = "spans-.*"
+				cfg.DeadLetter.Topic = "spans-dead"
+			},
+			expectedErr: "dead_letter topic cannot match topic_pattern",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tc.modify(cfg)
+			assert.EqualError(t, xconfmap.Validate(cfg), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkareceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver"
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+const (
+	deadLetterHeaderError     = "otel.dead_letter.error"
+	deadLetterHeaderTopic     = "otel.dead_letter.topic"
+	deadLetterHeaderPartition = "otel.dead_letter.partition"
+	deadLetterHeaderOffset    = "otel.dead_letter.offset"
+	deadLetterHeaderComponent = "otel.dead_letter.component_id"
+)
+
+// deadLetterProducer republishes the messages which can't be decoded or are rejected by the
+// next consumer to the dead-letter topic.
+type deadLetterProducer struct {
+	topic    string
+	id       component.ID
+	producer sarama.SyncProducer
+	logger   *zap.Logger
+}
+
+func newDeadLetterProducer(ctx context.Context, config Config, id component.ID, logger *zap.Logger) (*deadLetterProducer, error) {
+	saramaConfig, err := newSaramaConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	// The message must be written before its offset is marked
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	producer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &deadLetterProducer{
+		topic:    config.DeadLetter.Topic,
+		id:       id,
+		producer: producer,
+		logger:   logger,
+	}, nil
+}
+
+// handle republishes a message to the dead-letter topic, with the cause of the failure and the origin of
+// the message in its headers. Once republished, the message is marked so that the consumption continues.
+// It returns false when the dead-letter topic isn't configured or the message couldn't be republished,
+// in which case the failure is handled as if there was no dead-letter topic.
+func (d *deadLetterProducer) handle(session sarama.ConsumerGroupSession, message *sarama.ConsumerMessage, cause error, autocommitEnabled bool) bool {
+	if d == nil {
+		return false
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+5)
+	for _, h := range message.Headers {
+		headers = append(headers, *h)
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(deadLetterHeaderError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(deadLetterHeaderTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(deadLetterHeaderPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(deadLetterHeaderOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(deadLetterHeaderComponent), Value: []byte(d.id.String())},
+	)
+	msg := &sarama.ProducerMessage{
+		Topic:   d.topic,
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+	if message.Key != nil {
+		msg.Key = sarama.ByteEncoder(message.Key)
+	}
+	if _, _, err := d.producer.SendMessage(msg); err != nil {
+		d.logger.Error("Failed to send the message to the dead-letter topic",
+			zap.String("topic", d.topic), zap.Error(err))
+		return false
+	}
+
+	d.logger.Debug("Message sent to the dead-letter topic",
+		zap.String("topic", message.Topic),
+		zap.Int32("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+		zap.NamedError("cause", cause))
+	session.MarkMessage(message, "")
+	if !autocommitEnabled {
+		session.Commit()
+	}
+	return true
+}
+
+func (d *deadLetterProducer) close() error {
+	if d == nil {
+		return nil
+	}
+	return d.producer.Close()
+}
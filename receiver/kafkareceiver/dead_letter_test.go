@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkareceiver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/testdata"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver/internal/metadata"
+)
+
+// markingConsumerGroupSession records the offsets of the marked messages.
+type markingConsumerGroupSession struct {
+	testConsumerGroupSession
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *markingConsumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func headerValues(headers []sarama.RecordHeader) map[string]string {
+	values := make(map[string]string, len(headers))
+	for _, h := range headers {
+		values[string(h.Key)] = string(h.Value)
+	}
+	return values
+}
+
+func newDeadLetterLogsHandler(t *testing.T, producer sarama.SyncProducer, nextConsumer consumer.Logs) *logsConsumerGroupHandler {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{ReceiverCreateSettings: receivertest.NewNopSettings(metadata.Type)})
+	require.NoError(t, err)
+	return &logsConsumerGroupHandler{
+		unmarshaler:      newPdataLogsUnmarshaler(&plog.ProtoUnmarshaler{}, defaultEncoding),
+		logger:           zap.NewNop(),
+		ready:            make(chan bool),
+		nextConsumer:     nextConsumer,
+		obsrecv:          obsrecv,
+		headerExtractor:  &nopHeaderExtractor{},
+		telemetryBuilder: nopTelemetryBuilder(t),
+		messageMarking:   MessageMarking{After: true},
+		deadLetter: &deadLetterProducer{
+			topic:    "dead_letters",
+			id:       component.NewIDWithName(metadata.Type, "logs"),
+			producer: producer,
+			logger:   zap.NewNop(),
+		},
+	}
+}
+
+func consumeMessages(t *testing.T, handler sarama.ConsumerGroupHandler, session sarama.ConsumerGroupSession, messages ...*sarama.ConsumerMessage) error {
+	groupClaim := &testConsumerGroupClaim{
+		messageChan: make(chan *sarama.ConsumerMessage, len(messages)),
+	}
+	for _, m := range messages {
+		groupClaim.messageChan <- m
+	}
+	close(groupClaim.messageChan)
+	return handler.ConsumeClaim(session, groupClaim)
+}
+
+func TestDeadLetterUnmarshalError(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, "dead_letters", msg.Topic)
+		value, err := msg.Value.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("invalid"), value)
+		key, err := msg.Key.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("key"), key)
+
+		headers := headerValues(msg.Headers)
+		assert.Equal(t, "value", headers["original"])
+		assert.Equal(t, "otlp_logs", headers[deadLetterHeaderTopic])
+		assert.Equal(t, "5", headers[deadLetterHeaderPartition])
+		assert.Equal(t, "1", headers[deadLetterHeaderOffset])
+		assert.Equal(t, "kafka/logs", headers[deadLetterHeaderComponent])
+		assert.NotEmpty(t, headers[deadLetterHeaderError])
+		return nil
+	})
+	sink := &consumertest.LogsSink{}
+	handler := newDeadLetterLogsHandler(t, producer, sink)
+
+	bts, err := (&plog.ProtoMarshaler{}).MarshalLogs(testdata.GenerateLogs(1))
+	require.NoError(t, err)
+	session := &markingConsumerGroupSession{testConsumerGroupSession: testConsumerGroupSession{ctx: context.Background()}}
+
+	// The consumption continues after the undecodable message
+	require.NoError(t, consumeMessages(t, handler, session,
+		&sarama.ConsumerMessage{
+			Topic:     "otlp_logs",
+			Partition: 5,
+			Offset:    1,
+			Key:       []byte("key"),
+			Value:     []byte("invalid"),
+			Headers:   []*sarama.RecordHeader{{Key: []byte("original"), Value: []byte("value")}},
+		},
+		&sarama.ConsumerMessage{Topic: "otlp_logs", Partition: 5, Offset: 2, Value: bts},
+	))
+	assert.Equal(t, 1, sink.LogRecordCount())
+	assert.Equal(t, []int64{1, 2}, session.marked)
+	require.NoError(t, producer.Close())
+}
+
+func TestDeadLetterConsumerError(t *testing.T) {
+	bts, err := (&plog.ProtoMarshaler{}).MarshalLogs(testdata.GenerateLogs(1))
+	require.NoError(t, err)
+	message := &sarama.ConsumerMessage{Topic: "otlp_logs", Partition: 5, Offset: 1, Value: bts}
+
+	t.Run("permanent", func(t *testing.T) {
+		producer := mocks.NewSyncProducer(t, nil)
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			assert.Equal(t, "Permanent error: rejected", headerValues(msg.Headers)[deadLetterHeaderError])
+			return nil
+		})
+		handler := newDeadLetterLogsHandler(t, producer, consumertest.NewErr(consumererror.NewPermanent(errors.New("rejected"))))
+		session := &markingConsumerGroupSession{testConsumerGroupSession: testConsumerGroupSession{ctx: context.Background()}}
+
+		require.NoError(t, consumeMessages(t, handler, session, message))
+		assert.Equal(t, []int64{1}, session.marked)
+		require.NoError(t, producer.Close())
+	})
+
+	t.Run("retryable", func(t *testing.T) {
+		// The retryable errors aren't sent to the dead-letter topic
+		producer := mocks.NewSyncProducer(t, nil)
+		handler := newDeadLetterLogsHandler(t, producer, consumertest.NewErr(errors.New("unavailable")))
+		session := &markingConsumerGroupSession{testConsumerGroupSession: testConsumerGroupSession{ctx: context.Background()}}
+
+		require.EqualError(t, consumeMessages(t, handler, session, message), "unavailable")
+		assert.Empty(t, session.marked)
+		require.NoError(t, producer.Close())
+	})
+
+	t.Run("producer failure", func(t *testing.T) {
+		producer := mocks.NewSyncProducer(t, nil)
+		producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+		handler := newDeadLetterLogsHandler(t, producer, consumertest.NewErr(consumererror.NewPermanent(errors.New("rejected"))))
+		session := &markingConsumerGroupSession{testConsumerGroupSession: testConsumerGroupSession{ctx: context.Background()}}
+
+		require.EqualError(t, consumeMessages(t, handler, session, message), "Permanent error: rejected")
+		assert.Empty(t, session.marked)
+		require.NoError(t, producer.Close())
+	})
+}
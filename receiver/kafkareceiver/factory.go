@@ -26,8 +26,10 @@ const (
 	defaultClientID          = "otel-collector"
 	defaultGroupID           = defaultClientID
 	defaultInitialOffset     = offsetLatest
+	defaultIsolationLevel    = isolationLevelReadUncommitted
 	defaultSessionTimeout    = 10 * time.Second
 	defaultHeartbeatInterval = 3 * time.Second
+	defaultTopicRefresh      = 30 * time.Second
 
 	// default from sarama.NewConfig()
 	defaultMetadataRetryMax = 3
@@ -71,13 +73,15 @@ func NewFactory(options ...FactoryOption) receiver.Factory {
 
 func createDefaultConfig() component.Config {
 	return &Config{
-		Encoding:          defaultEncoding,
-		Brokers:           []string{defaultBroker},
-		ClientID:          defaultClientID,
-		GroupID:           defaultGroupID,
-		InitialOffset:     defaultInitialOffset,
-		SessionTimeout:    defaultSessionTimeout,
-		HeartbeatInterval: defaultHeartbeatInterval,
+		Encoding:             defaultEncoding,
+		Brokers:              []string{defaultBroker},
+		ClientID:             defaultClientID,
+		GroupID:              defaultGroupID,
+		InitialOffset:        defaultInitialOffset,
+		IsolationLevel:       defaultIsolationLevel,
+		SessionTimeout:       defaultSessionTimeout,
+		HeartbeatInterval:    defaultHeartbeatInterval,
+		TopicRefreshInterval: defaultTopicRefresh,
 		Metadata: kafkaexporter.Metadata{
 			Full: defaultMetadataFull,
 			Retry: kafkaexporter.MetadataRetry{
@@ -96,6 +100,9 @@ func createDefaultConfig() component.Config {
 		HeaderExtraction: HeaderExtraction{
 			ExtractHeaders: false,
 		},
+		MessageMetadata: MessageMetadata{
+			Level: metadataLevelResource,
+		},
 		MinFetchSize:     defaultMinFetchSize,
 		DefaultFetchSize: defaultDefaultFetchSize,
 		MaxFetchSize:     defaultMaxFetchSize,
@@ -111,7 +118,7 @@ func (f *kafkaReceiverFactory) createTracesReceiver(
 	nextConsumer consumer.Traces,
 ) (receiver.Traces, error) {
 	oCfg := *(cfg.(*Config))
-	if oCfg.Topic == "" {
+	if oCfg.Topic == "" && len(oCfg.Topics) == 0 && oCfg.TopicPattern == "" {
 		oCfg.Topic = defaultTracesTopic
 	}
 
@@ -129,7 +136,7 @@ func (f *kafkaReceiverFactory) createMetricsReceiver(
 	nextConsumer consumer.Metrics,
 ) (receiver.Metrics, error) {
 	oCfg := *(cfg.(*Config))
-	if oCfg.Topic == "" {
+	if oCfg.Topic == "" && len(oCfg.Topics) == 0 && oCfg.TopicPattern == "" {
 		oCfg.Topic = defaultMetricsTopic
 	}
 
@@ -147,7 +154,7 @@ func (f *kafkaReceiverFactory) createLogsReceiver(
 	nextConsumer consumer.Logs,
 ) (receiver.Logs, error) {
 	oCfg := *(cfg.(*Config))
-	if oCfg.Topic == "" {
+	if oCfg.Topic == "" && len(oCfg.Topics) == 0 && oCfg.TopicPattern == "" {
 		oCfg.Topic = defaultLogsTopic
 	}
 
@@ -16,6 +16,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -36,7 +37,10 @@ const (
 	attrPartition    = "partition"
 )
 
-var errInvalidInitialOffset = errors.New("invalid initial offset")
+var (
+	errInvalidInitialOffset  = errors.New("invalid initial offset")
+	errInvalidIsolationLevel = errors.New("invalid isolation level")
+)
 
 var errMemoryLimiterDataRefused = errors.New("data refused due to high memory usage")
 
@@ -46,6 +50,8 @@ type kafkaTracesConsumer struct {
 	consumerGroup     sarama.ConsumerGroup
 	nextConsumer      consumer.Traces
 	topics            []string
+	topicWatcher      *topicWatcher
+	deadLetter        *deadLetterProducer
 	cancelConsumeLoop context.CancelFunc
 	unmarshaler       TracesUnmarshaler
 	consumeLoopWG     *sync.WaitGroup
@@ -68,6 +74,8 @@ type kafkaMetricsConsumer struct {
 	consumerGroup     sarama.ConsumerGroup
 	nextConsumer      consumer.Metrics
 	topics            []string
+	topicWatcher      *topicWatcher
+	deadLetter        *deadLetterProducer
 	cancelConsumeLoop context.CancelFunc
 	unmarshaler       MetricsUnmarshaler
 	consumeLoopWG     *sync.WaitGroup
@@ -90,6 +98,8 @@ type kafkaLogsConsumer struct {
 	consumerGroup     sarama.ConsumerGroup
 	nextConsumer      consumer.Logs
 	topics            []string
+	topicWatcher      *topicWatcher
+	deadLetter        *deadLetterProducer
 	cancelConsumeLoop context.CancelFunc
 	unmarshaler       LogsUnmarshaler
 	consumeLoopWG     *sync.WaitGroup
@@ -117,10 +127,15 @@ func newTracesReceiver(config Config, set receiver.Settings, nextConsumer consum
 	if err != nil {
 		return nil, err
 	}
+	topicWatcher, err := newTopicWatcher(config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &kafkaTracesConsumer{
 		config:            config,
-		topics:            []string{config.Topic},
+		topics:            config.topics(),
+		topicWatcher:      topicWatcher,
 		nextConsumer:      nextConsumer,
 		consumeLoopWG:     &sync.WaitGroup{},
 		settings:          set,
@@ -136,6 +151,14 @@ func newTracesReceiver(config Config, set receiver.Settings, nextConsumer consum
 }
 
 func createKafkaClient(ctx context.Context, config Config) (sarama.ConsumerGroup, error) {
+	saramaConfig, err := newSaramaConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return sarama.NewConsumerGroup(config.Brokers, config.GroupID, saramaConfig)
+}
+
+func newSaramaConfig(ctx context.Context, config Config) (*sarama.Config, error) {
 	saramaConfig := sarama.NewConfig()
 	saramaConfig.ClientID = config.ClientID
 	saramaConfig.Metadata.Full = config.Metadata.Full
@@ -153,6 +176,9 @@ func createKafkaClient(ctx context.Context, config Config) (sarama.ConsumerGroup
 	if saramaConfig.Consumer.Offsets.Initial, err = toSaramaInitialOffset(config.InitialOffset); err != nil {
 		return nil, err
 	}
+	if saramaConfig.Consumer.IsolationLevel, err = toSaramaIsolationLevel(config.IsolationLevel); err != nil {
+		return nil, err
+	}
 	if config.ResolveCanonicalBootstrapServersOnly {
 		saramaConfig.Net.ResolveCanonicalBootstrapServers = true
 	}
@@ -164,7 +190,7 @@ func createKafkaClient(ctx context.Context, config Config) (sarama.ConsumerGroup
 	if err := kafka.ConfigureAuthentication(ctx, config.Authentication, saramaConfig); err != nil {
 		return nil, err
 	}
-	return sarama.NewConsumerGroup(config.Brokers, config.GroupID, saramaConfig)
+	return saramaConfig, nil
 }
 
 func (c *kafkaTracesConsumer) Start(_ context.Context, host component.Host) error {
@@ -178,21 +204,14 @@ func (c *kafkaTracesConsumer) Start(_ context.Context, host component.Host) erro
 	if err != nil {
 		return err
 	}
-	// extensions take precedence over internal encodings
-	if unmarshaler, errExt := loadEncodingExtension[ptrace.Unmarshaler](
-		host,
-		c.config.Encoding,
-	); errExt == nil {
-		c.unmarshaler = &tracesEncodingUnmarshaler{
-			unmarshaler: *unmarshaler,
-			encoding:    c.config.Encoding,
-		}
-	}
-	if unmarshaler, ok := defaultTracesUnmarshalers()[c.config.Encoding]; c.unmarshaler == nil && ok {
-		c.unmarshaler = unmarshaler
+	if c.unmarshaler, err = newTracesUnmarshaler(host, c.config.Encoding); err != nil {
+		return err
 	}
-	if c.unmarshaler == nil {
-		return errUnrecognizedEncoding
+	topicUnmarshalers := make(map[string]TracesUnmarshaler, len(c.config.TopicEncodings))
+	for topic, encoding := range c.config.TopicEncodings {
+		if topicUnmarshalers[topic], err = newTracesUnmarshaler(host, encoding); err != nil {
+			return fmt.Errorf("topic %q: %w", topic, err)
+		}
 	}
 	// consumerGroup may be set in tests to inject fake implementation.
 	if c.consumerGroup == nil {
@@ -200,15 +219,29 @@ func (c *kafkaTracesConsumer) Start(_ context.Context, host component.Host) erro
 			return err
 		}
 	}
+	if c.topicWatcher != nil {
+		if err = c.topicWatcher.start(ctx, c.config); err != nil {
+			return err
+		}
+	}
+	// deadLetter may be set in tests to inject fake implementation.
+	if c.deadLetter == nil && c.config.DeadLetter.Topic != "" {
+		if c.deadLetter, err = newDeadLetterProducer(ctx, c.config, c.settings.ID, c.settings.Logger); err != nil {
+			return err
+		}
+	}
 	consumerGroup := &tracesConsumerGroupHandler{
 		logger:            c.settings.Logger,
 		unmarshaler:       c.unmarshaler,
+		topicUnmarshalers: topicUnmarshalers,
 		nextConsumer:      c.nextConsumer,
 		ready:             make(chan bool),
 		obsrecv:           obsrecv,
 		autocommitEnabled: c.autocommitEnabled,
 		messageMarking:    c.messageMarking,
 		headerExtractor:   &nopHeaderExtractor{},
+		messageMetadata:   c.config.MessageMetadata,
+		deadLetter:        c.deadLetter,
 		telemetryBuilder:  c.telemetryBuilder,
 		backOff:           newExponentialBackOff(c.config.ErrorBackOff),
 	}
@@ -224,21 +257,9 @@ func (c *kafkaTracesConsumer) Start(_ context.Context, host component.Host) erro
 	return nil
 }
 
-func (c *kafkaTracesConsumer) consumeLoop(ctx context.Context, handler sarama.ConsumerGroupHandler) {
+func (c *kafkaTracesConsumer) consumeLoop(ctx context.Context, handler consumerGroupHandler) {
 	defer c.consumeLoopWG.Done()
-	for {
-		// `Consume` should be called inside an infinite loop, when a
-		// server-side rebalance happens, the consumer session will need to be
-		// recreated to get the new claims
-		if err := c.consumerGroup.Consume(ctx, c.topics, handler); err != nil {
-			c.settings.Logger.Error("Error from consumer", zap.Error(err))
-		}
-		// check if context was cancelled, signaling that the consumer should stop
-		if ctx.Err() != nil {
-			c.settings.Logger.Info("Consumer stopped", zap.Error(ctx.Err()))
-			return
-		}
-	}
+	consumeTopics(ctx, c.settings.Logger, c.consumerGroup, c.topics, c.topicWatcher, handler)
 }
 
 func (c *kafkaTracesConsumer) Shutdown(context.Context) error {
@@ -247,10 +268,15 @@ func (c *kafkaTracesConsumer) Shutdown(context.Context) error {
 	}
 	c.cancelConsumeLoop()
 	c.consumeLoopWG.Wait()
+	var err error
+	if c.topicWatcher != nil {
+		err = c.topicWatcher.shutdown()
+	}
+	err = errors.Join(err, c.deadLetter.close())
 	if c.consumerGroup == nil {
-		return nil
+		return err
 	}
-	return c.consumerGroup.Close()
+	return errors.Join(err, c.consumerGroup.Close())
 }
 
 func newMetricsReceiver(config Config, set receiver.Settings, nextConsumer consumer.Metrics) (*kafkaMetricsConsumer, error) {
@@ -258,10 +284,15 @@ func newMetricsReceiver(config Config, set receiver.Settings, nextConsumer consu
 	if err != nil {
 		return nil, err
 	}
+	topicWatcher, err := newTopicWatcher(config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &kafkaMetricsConsumer{
 		config:            config,
-		topics:            []string{config.Topic},
+		topics:            config.topics(),
+		topicWatcher:      topicWatcher,
 		nextConsumer:      nextConsumer,
 		consumeLoopWG:     &sync.WaitGroup{},
 		settings:          set,
@@ -287,21 +318,14 @@ func (c *kafkaMetricsConsumer) Start(_ context.Context, host component.Host) err
 	if err != nil {
 		return err
 	}
-	// extensions take precedence over internal encodings
-	if unmarshaler, errExt := loadEncodingExtension[pmetric.Unmarshaler](
-		host,
-		c.config.Encoding,
-	); errExt == nil {
-		c.unmarshaler = &metricsEncodingUnmarshaler{
-			unmarshaler: *unmarshaler,
-			encoding:    c.config.Encoding,
-		}
-	}
-	if unmarshaler, ok := defaultMetricsUnmarshalers()[c.config.Encoding]; c.unmarshaler == nil && ok {
-		c.unmarshaler = unmarshaler
+	if c.unmarshaler, err = newMetricsUnmarshaler(host, c.config.Encoding); err != nil {
+		return err
 	}
-	if c.unmarshaler == nil {
-		return errUnrecognizedEncoding
+	topicUnmarshalers := make(map[string]MetricsUnmarshaler, len(c.config.TopicEncodings))
+	for topic, encoding := range c.config.TopicEncodings {
+		if topicUnmarshalers[topic], err = newMetricsUnmarshaler(host, encoding); err != nil {
+			return fmt.Errorf("topic %q: %w", topic, err)
+		}
 	}
 	// consumerGroup may be set in tests to inject fake implementation.
 	if c.consumerGroup == nil {
@@ -309,15 +333,29 @@ func (c *kafkaMetricsConsumer) Start(_ context.Context, host component.Host) err
 			return err
 		}
 	}
+	if c.topicWatcher != nil {
+		if err = c.topicWatcher.start(ctx, c.config); err != nil {
+			return err
+		}
+	}
+	// deadLetter may be set in tests to inject fake implementation.
+	if c.deadLetter == nil && c.config.DeadLetter.Topic != "" {
+		if c.deadLetter, err = newDeadLetterProducer(ctx, c.config, c.settings.ID, c.settings.Logger); err != nil {
+			return err
+		}
+	}
 	metricsConsumerGroup := &metricsConsumerGroupHandler{
 		logger:            c.settings.Logger,
 		unmarshaler:       c.unmarshaler,
+		topicUnmarshalers: topicUnmarshalers,
 		nextConsumer:      c.nextConsumer,
 		ready:             make(chan bool),
 		obsrecv:           obsrecv,
 		autocommitEnabled: c.autocommitEnabled,
 		messageMarking:    c.messageMarking,
 		headerExtractor:   &nopHeaderExtractor{},
+		messageMetadata:   c.config.MessageMetadata,
+		deadLetter:        c.deadLetter,
 		telemetryBuilder:  c.telemetryBuilder,
 		backOff:           newExponentialBackOff(c.config.ErrorBackOff),
 	}
@@ -333,21 +371,9 @@ func (c *kafkaMetricsConsumer) Start(_ context.Context, host component.Host) err
 	return nil
 }
 
-func (c *kafkaMetricsConsumer) consumeLoop(ctx context.Context, handler sarama.ConsumerGroupHandler) {
+func (c *kafkaMetricsConsumer) consumeLoop(ctx context.Context, handler consumerGroupHandler) {
 	defer c.consumeLoopWG.Done()
-	for {
-		// `Consume` should be called inside an infinite loop, when a
-		// server-side rebalance happens, the consumer session will need to be
-		// recreated to get the new claims
-		if err := c.consumerGroup.Consume(ctx, c.topics, handler); err != nil {
-			c.settings.Logger.Error("Error from consumer", zap.Error(err))
-		}
-		// check if context was cancelled, signaling that the consumer should stop
-		if ctx.Err() != nil {
-			c.settings.Logger.Info("Consumer stopped", zap.Error(ctx.Err()))
-			return
-		}
-	}
+	consumeTopics(ctx, c.settings.Logger, c.consumerGroup, c.topics, c.topicWatcher, handler)
 }
 
 func (c *kafkaMetricsConsumer) Shutdown(context.Context) error {
@@ -356,10 +382,15 @@ func (c *kafkaMetricsConsumer) Shutdown(context.Context) error {
 	}
 	c.cancelConsumeLoop()
 	c.consumeLoopWG.Wait()
+	var err error
+	if c.topicWatcher != nil {
+		err = c.topicWatcher.shutdown()
+	}
+	err = errors.Join(err, c.deadLetter.close())
 	if c.consumerGroup == nil {
-		return nil
+		return err
 	}
-	return c.consumerGroup.Close()
+	return errors.Join(err, c.consumerGroup.Close())
 }
 
 func newLogsReceiver(config Config, set receiver.Settings, nextConsumer consumer.Logs) (*kafkaLogsConsumer, error) {
@@ -367,10 +398,15 @@ func newLogsReceiver(config Config, set receiver.Settings, nextConsumer consumer
 	if err != nil {
 		return nil, err
 	}
+	topicWatcher, err := newTopicWatcher(config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &kafkaLogsConsumer{
 		config:            config,
-		topics:            []string{config.Topic},
+		topics:            config.topics(),
+		topicWatcher:      topicWatcher,
 		nextConsumer:      nextConsumer,
 		consumeLoopWG:     &sync.WaitGroup{},
 		settings:          set,
@@ -396,24 +432,14 @@ func (c *kafkaLogsConsumer) Start(_ context.Context, host component.Host) error
 	if err != nil {
 		return err
 	}
-	// extensions take precedence over internal encodings
-	if unmarshaler, errExt := loadEncodingExtension[plog.Unmarshaler](
-		host,
-		c.config.Encoding,
-	); errExt == nil {
-		c.unmarshaler = &logsEncodingUnmarshaler{
-			unmarshaler: *unmarshaler,
-			encoding:    c.config.Encoding,
-		}
-	}
-	if unmarshaler, errInt := getLogsUnmarshaler(
-		c.config.Encoding,
-		defaultLogsUnmarshalers(c.settings.BuildInfo.Version, c.settings.Logger),
-	); c.unmarshaler == nil && errInt == nil {
-		c.unmarshaler = unmarshaler
+	if c.unmarshaler, err = c.newUnmarshaler(host, c.config.Encoding); err != nil {
+		return err
 	}
-	if c.unmarshaler == nil {
-		return errUnrecognizedEncoding
+	topicUnmarshalers := make(map[string]LogsUnmarshaler, len(c.config.TopicEncodings))
+	for topic, encoding := range c.config.TopicEncodings {
+		if topicUnmarshalers[topic], err = c.newUnmarshaler(host, encoding); err != nil {
+			return fmt.Errorf("topic %q: %w", topic, err)
+		}
 	}
 	// consumerGroup may be set in tests to inject fake implementation.
 	if c.consumerGroup == nil {
@@ -421,15 +447,29 @@ func (c *kafkaLogsConsumer) Start(_ context.Context, host component.Host) error
 			return err
 		}
 	}
+	if c.topicWatcher != nil {
+		if err = c.topicWatcher.start(ctx, c.config); err != nil {
+			return err
+		}
+	}
+	// deadLetter may be set in tests to inject fake implementation.
+	if c.deadLetter == nil && c.config.DeadLetter.Topic != "" {
+		if c.deadLetter, err = newDeadLetterProducer(ctx, c.config, c.settings.ID, c.settings.Logger); err != nil {
+			return err
+		}
+	}
 	logsConsumerGroup := &logsConsumerGroupHandler{
 		logger:            c.settings.Logger,
 		unmarshaler:       c.unmarshaler,
+		topicUnmarshalers: topicUnmarshalers,
 		nextConsumer:      c.nextConsumer,
 		ready:             make(chan bool),
 		obsrecv:           obsrecv,
 		autocommitEnabled: c.autocommitEnabled,
 		messageMarking:    c.messageMarking,
 		headerExtractor:   &nopHeaderExtractor{},
+		messageMetadata:   c.config.MessageMetadata,
+		deadLetter:        c.deadLetter,
 		telemetryBuilder:  c.telemetryBuilder,
 		backOff:           newExponentialBackOff(c.config.ErrorBackOff),
 	}
@@ -445,21 +485,9 @@ func (c *kafkaLogsConsumer) Start(_ context.Context, host component.Host) error
 	return nil
 }
 
-func (c *kafkaLogsConsumer) consumeLoop(ctx context.Context, handler sarama.ConsumerGroupHandler) {
+func (c *kafkaLogsConsumer) consumeLoop(ctx context.Context, handler consumerGroupHandler) {
 	defer c.consumeLoopWG.Done()
-	for {
-		// `Consume` should be called inside an infinite loop, when a
-		// server-side rebalance happens, the consumer session will need to be
-		// recreated to get the new claims
-		if err := c.consumerGroup.Consume(ctx, c.topics, handler); err != nil {
-			c.settings.Logger.Error("Error from consumer", zap.Error(err))
-		}
-		// check if context was cancelled, signaling that the consumer should stop
-		if ctx.Err() != nil {
-			c.settings.Logger.Info("Consumer stopped", zap.Error(ctx.Err()))
-			return
-		}
-	}
+	consumeTopics(ctx, c.settings.Logger, c.consumerGroup, c.topics, c.topicWatcher, handler)
 }
 
 func (c *kafkaLogsConsumer) Shutdown(context.Context) error {
@@ -468,18 +496,25 @@ func (c *kafkaLogsConsumer) Shutdown(context.Context) error {
 	}
 	c.cancelConsumeLoop()
 	c.consumeLoopWG.Wait()
+	var err error
+	if c.topicWatcher != nil {
+		err = c.topicWatcher.shutdown()
+	}
+	err = errors.Join(err, c.deadLetter.close())
 	if c.consumerGroup == nil {
-		return nil
+		return err
 	}
-	return c.consumerGroup.Close()
+	return errors.Join(err, c.consumerGroup.Close())
 }
 
 type tracesConsumerGroupHandler struct {
-	id           component.ID
-	unmarshaler  TracesUnmarshaler
-	nextConsumer consumer.Traces
-	ready        chan bool
-	readyCloser  sync.Once
+	id          component.ID
+	unmarshaler TracesUnmarshaler
+	// topicUnmarshalers override unmarshaler for the messages of specific topics
+	topicUnmarshalers map[string]TracesUnmarshaler
+	nextConsumer      consumer.Traces
+	ready             chan bool
+	readyCloser       sync.Once
 
 	logger *zap.Logger
 
@@ -489,15 +524,19 @@ type tracesConsumerGroupHandler struct {
 	autocommitEnabled bool
 	messageMarking    MessageMarking
 	headerExtractor   HeaderExtractor
+	messageMetadata   MessageMetadata
+	deadLetter        *deadLetterProducer
 	backOff           *backoff.ExponentialBackOff
 }
 
 type metricsConsumerGroupHandler struct {
-	id           component.ID
-	unmarshaler  MetricsUnmarshaler
-	nextConsumer consumer.Metrics
-	ready        chan bool
-	readyCloser  sync.Once
+	id          component.ID
+	unmarshaler MetricsUnmarshaler
+	// topicUnmarshalers override unmarshaler for the messages of specific topics
+	topicUnmarshalers map[string]MetricsUnmarshaler
+	nextConsumer      consumer.Metrics
+	ready             chan bool
+	readyCloser       sync.Once
 
 	logger *zap.Logger
 
@@ -507,15 +546,19 @@ type metricsConsumerGroupHandler struct {
 	autocommitEnabled bool
 	messageMarking    MessageMarking
 	headerExtractor   HeaderExtractor
+	messageMetadata   MessageMetadata
+	deadLetter        *deadLetterProducer
 	backOff           *backoff.ExponentialBackOff
 }
 
 type logsConsumerGroupHandler struct {
-	id           component.ID
-	unmarshaler  LogsUnmarshaler
-	nextConsumer consumer.Logs
-	ready        chan bool
-	readyCloser  sync.Once
+	id          component.ID
+	unmarshaler LogsUnmarshaler
+	// topicUnmarshalers override unmarshaler for the messages of specific topics
+	topicUnmarshalers map[string]LogsUnmarshaler
+	nextConsumer      consumer.Logs
+	ready             chan bool
+	readyCloser       sync.Once
 
 	logger *zap.Logger
 
@@ -525,6 +568,8 @@ type logsConsumerGroupHandler struct {
 	autocommitEnabled bool
 	messageMarking    MessageMarking
 	headerExtractor   HeaderExtractor
+	messageMetadata   MessageMetadata
+	deadLetter        *deadLetterProducer
 	backOff           *backoff.ExponentialBackOff
 }
 
@@ -534,10 +579,14 @@ var (
 	_ sarama.ConsumerGroupHandler = (*logsConsumerGroupHandler)(nil)
 )
 
-func (c *tracesConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+func (c *tracesConsumerGroupHandler) markReady() {
 	c.readyCloser.Do(func() {
 		close(c.ready)
 	})
+}
+
+func (c *tracesConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	c.markReady()
 	c.telemetryBuilder.KafkaReceiverPartitionStart.Add(session.Context(), 1, metric.WithAttributes(attribute.String(attrInstanceName, c.id.Name())))
 	return nil
 }
@@ -575,10 +624,14 @@ func (c *tracesConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSe
 			c.telemetryBuilder.KafkaReceiverCurrentOffset.Record(ctx, message.Offset, metric.WithAttributeSet(attrs))
 			c.telemetryBuilder.KafkaReceiverOffsetLag.Record(ctx, claim.HighWaterMarkOffset()-message.Offset-1, metric.WithAttributeSet(attrs))
 
-			traces, err := c.unmarshaler.Unmarshal(message.Value)
+			unmarshaler := c.unmarshalerFor(message.Topic)
+			traces, err := unmarshaler.Unmarshal(message.Value)
 			if err != nil {
 				c.logger.Error("failed to unmarshal message", zap.Error(err))
 				c.telemetryBuilder.KafkaReceiverUnmarshalFailedSpans.Add(session.Context(), 1, metric.WithAttributes(attribute.String(attrInstanceName, c.id.String())))
+				if c.deadLetter.handle(session, message, err, c.autocommitEnabled) {
+					continue
+				}
 				if c.messageMarking.After && c.messageMarking.OnError {
 					session.MarkMessage(message, "")
 				}
@@ -586,9 +639,12 @@ func (c *tracesConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSe
 			}
 
 			c.headerExtractor.extractHeadersTraces(traces, message)
+			if c.messageMetadata.Enabled {
+				addMessageMetadataTraces(traces, message)
+			}
 			spanCount := traces.SpanCount()
 			err = c.nextConsumer.ConsumeTraces(session.Context(), traces)
-			c.obsrecv.EndTracesOp(ctx, c.unmarshaler.Encoding(), spanCount, err)
+			c.obsrecv.EndTracesOp(ctx, unmarshaler.Encoding(), spanCount, err)
 			if err != nil {
 				if errorRequiresBackoff(err) && c.backOff != nil {
 					backOffDelay := c.backOff.NextBackOff()
@@ -605,6 +661,9 @@ func (c *tracesConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSe
 						}
 					}
 				}
+				if consumererror.IsPermanent(err) && c.deadLetter.handle(session, message, err, c.autocommitEnabled) {
+					continue
+				}
 				if c.messageMarking.After && c.messageMarking.OnError {
 					session.MarkMessage(message, "")
 				}
@@ -629,10 +688,14 @@ func (c *tracesConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSe
 	}
 }
 
-func (c *metricsConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+func (c *metricsConsumerGroupHandler) markReady() {
 	c.readyCloser.Do(func() {
 		close(c.ready)
 	})
+}
+
+func (c *metricsConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	c.markReady()
 	c.telemetryBuilder.KafkaReceiverPartitionStart.Add(session.Context(), 1, metric.WithAttributes(attribute.String(attrInstanceName, c.id.Name())))
 	return nil
 }
@@ -670,20 +733,27 @@ func (c *metricsConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupS
 			c.telemetryBuilder.KafkaReceiverCurrentOffset.Record(ctx, message.Offset, metric.WithAttributeSet(attrs))
 			c.telemetryBuilder.KafkaReceiverOffsetLag.Record(ctx, claim.HighWaterMarkOffset()-message.Offset-1, metric.WithAttributeSet(attrs))
 
-			metrics, err := c.unmarshaler.Unmarshal(message.Value)
+			unmarshaler := c.unmarshalerFor(message.Topic)
+			metrics, err := unmarshaler.Unmarshal(message.Value)
 			if err != nil {
 				c.logger.Error("failed to unmarshal message", zap.Error(err))
 				c.telemetryBuilder.KafkaReceiverUnmarshalFailedMetricPoints.Add(session.Context(), 1, metric.WithAttributes(attribute.String(attrInstanceName, c.id.String())))
+				if c.deadLetter.handle(session, message, err, c.autocommitEnabled) {
+					continue
+				}
 				if c.messageMarking.After && c.messageMarking.OnError {
 					session.MarkMessage(message, "")
 				}
 				return err
 			}
 			c.headerExtractor.extractHeadersMetrics(metrics, message)
+			if c.messageMetadata.Enabled {
+				addMessageMetadataMetrics(metrics, message)
+			}
 
 			dataPointCount := metrics.DataPointCount()
 			err = c.nextConsumer.ConsumeMetrics(session.Context(), metrics)
-			c.obsrecv.EndMetricsOp(ctx, c.unmarshaler.Encoding(), dataPointCount, err)
+			c.obsrecv.EndMetricsOp(ctx, unmarshaler.Encoding(), dataPointCount, err)
 			if err != nil {
 				if errorRequiresBackoff(err) && c.backOff != nil {
 					backOffDelay := c.backOff.NextBackOff()
@@ -700,6 +770,9 @@ func (c *metricsConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupS
 						}
 					}
 				}
+				if consumererror.IsPermanent(err) && c.deadLetter.handle(session, message, err, c.autocommitEnabled) {
+					continue
+				}
 				if c.messageMarking.After && c.messageMarking.OnError {
 					session.MarkMessage(message, "")
 				}
@@ -724,10 +797,14 @@ func (c *metricsConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupS
 	}
 }
 
-func (c *logsConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+func (c *logsConsumerGroupHandler) markReady() {
 	c.readyCloser.Do(func() {
 		close(c.ready)
 	})
+}
+
+func (c *logsConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	c.markReady()
 	c.telemetryBuilder.KafkaReceiverPartitionStart.Add(session.Context(), 1, metric.WithAttributes(attribute.String(attrInstanceName, c.id.String())))
 	return nil
 }
@@ -765,19 +842,26 @@ func (c *logsConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSess
 			c.telemetryBuilder.KafkaReceiverCurrentOffset.Record(ctx, message.Offset, metric.WithAttributeSet(attrs))
 			c.telemetryBuilder.KafkaReceiverOffsetLag.Record(ctx, claim.HighWaterMarkOffset()-message.Offset-1, metric.WithAttributeSet(attrs))
 
-			logs, err := c.unmarshaler.Unmarshal(message.Value)
+			unmarshaler := c.unmarshalerFor(message.Topic)
+			logs, err := unmarshaler.Unmarshal(message.Value)
 			if err != nil {
 				c.logger.Error("failed to unmarshal message", zap.Error(err))
 				c.telemetryBuilder.KafkaReceiverUnmarshalFailedLogRecords.Add(ctx, 1, metric.WithAttributes(attribute.String(attrInstanceName, c.id.String())))
+				if c.deadLetter.handle(session, message, err, c.autocommitEnabled) {
+					continue
+				}
 				if c.messageMarking.After && c.messageMarking.OnError {
 					session.MarkMessage(message, "")
 				}
 				return err
 			}
 			c.headerExtractor.extractHeadersLogs(logs, message)
+			if c.messageMetadata.Enabled {
+				addMessageMetadataLogs(logs, message, c.messageMetadata.Level == metadataLevelLogRecord)
+			}
 			logRecordCount := logs.LogRecordCount()
 			err = c.nextConsumer.ConsumeLogs(session.Context(), logs)
-			c.obsrecv.EndLogsOp(ctx, c.unmarshaler.Encoding(), logRecordCount, err)
+			c.obsrecv.EndLogsOp(ctx, unmarshaler.Encoding(), logRecordCount, err)
 			if err != nil {
 				if errorRequiresBackoff(err) && c.backOff != nil {
 					backOffDelay := c.backOff.NextBackOff()
@@ -794,6 +878,9 @@ func (c *logsConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSess
 						}
 					}
 				}
+				if consumererror.IsPermanent(err) && c.deadLetter.handle(session, message, err, c.autocommitEnabled) {
+					continue
+				}
 				if c.messageMarking.After && c.messageMarking.OnError {
 					session.MarkMessage(message, "")
 				}
@@ -818,6 +905,27 @@ func (c *logsConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSess
 	}
 }
 
+func (c *tracesConsumerGroupHandler) unmarshalerFor(topic string) TracesUnmarshaler {
+	if unmarshaler, ok := c.topicUnmarshalers[topic]; ok {
+		return unmarshaler
+	}
+	return c.unmarshaler
+}
+
+func (c *metricsConsumerGroupHandler) unmarshalerFor(topic string) MetricsUnmarshaler {
+	if unmarshaler, ok := c.topicUnmarshalers[topic]; ok {
+		return unmarshaler
+	}
+	return c.unmarshaler
+}
+
+func (c *logsConsumerGroupHandler) unmarshalerFor(topic string) LogsUnmarshaler {
+	if unmarshaler, ok := c.topicUnmarshalers[topic]; ok {
+		return unmarshaler
+	}
+	return c.unmarshaler
+}
+
 func newExponentialBackOff(config configretry.BackOffConfig) *backoff.ExponentialBackOff {
 	if !config.Enabled {
 		return nil
@@ -845,6 +953,62 @@ func toSaramaInitialOffset(initialOffset string) (int64, error) {
 	}
 }
 
+func toSaramaIsolationLevel(isolationLevel string) (sarama.IsolationLevel, error) {
+	switch isolationLevel {
+	case isolationLevelReadCommitted:
+		return sarama.ReadCommitted, nil
+	case isolationLevelReadUncommitted, "":
+		return sarama.ReadUncommitted, nil
+	default:
+		return 0, errInvalidIsolationLevel
+	}
+}
+
+// newTracesUnmarshaler returns the unmarshaler of an encoding. Extensions take precedence over internal encodings.
+func newTracesUnmarshaler(host component.Host, encoding string) (TracesUnmarshaler, error) {
+	if unmarshaler, errExt := loadEncodingExtension[ptrace.Unmarshaler](host, encoding); errExt == nil {
+		return &tracesEncodingUnmarshaler{
+			unmarshaler: *unmarshaler,
+			encoding:    encoding,
+		}, nil
+	}
+	if unmarshaler, ok := defaultTracesUnmarshalers()[encoding]; ok {
+		return unmarshaler, nil
+	}
+	return nil, errUnrecognizedEncoding
+}
+
+// newMetricsUnmarshaler returns the unmarshaler of an encoding. Extensions take precedence over internal encodings.
+func newMetricsUnmarshaler(host component.Host, encoding string) (MetricsUnmarshaler, error) {
+	if unmarshaler, errExt := loadEncodingExtension[pmetric.Unmarshaler](host, encoding); errExt == nil {
+		return &metricsEncodingUnmarshaler{
+			unmarshaler: *unmarshaler,
+			encoding:    encoding,
+		}, nil
+	}
+	if unmarshaler, ok := defaultMetricsUnmarshalers()[encoding]; ok {
+		return unmarshaler, nil
+	}
+	return nil, errUnrecognizedEncoding
+}
+
+// newUnmarshaler returns the unmarshaler of an encoding. Extensions take precedence over internal encodings.
+func (c *kafkaLogsConsumer) newUnmarshaler(host component.Host, encoding string) (LogsUnmarshaler, error) {
+	if unmarshaler, errExt := loadEncodingExtension[plog.Unmarshaler](host, encoding); errExt == nil {
+		return &logsEncodingUnmarshaler{
+			unmarshaler: *unmarshaler,
+			encoding:    encoding,
+		}, nil
+	}
+	if unmarshaler, errInt := getLogsUnmarshaler(
+		encoding,
+		defaultLogsUnmarshalers(c.settings.BuildInfo.Version, c.settings.Logger),
+	); errInt == nil {
+		return unmarshaler, nil
+	}
+	return nil, errUnrecognizedEncoding
+}
+
 // loadEncodingExtension tries to load an available extension for the given encoding.
 func loadEncodingExtension[T any](host component.Host, encoding string) (*T, error) {
 	extensionID, err := encodingToComponentID(encoding)
@@ -1158,6 +1158,106 @@ func TestLogsConsumerGroupHandler_unmarshal_text(t *testing.T) {
 	}
 }
 
+func TestLogsConsumerGroupHandler_topic_encodings(t *testing.T) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{ReceiverCreateSettings: receivertest.NewNopSettings(metadata.Type)})
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	c := logsConsumerGroupHandler{
+		unmarshaler:       newPdataLogsUnmarshaler(&plog.ProtoUnmarshaler{}, defaultEncoding),
+		topicUnmarshalers: map[string]LogsUnmarshaler{"raw_logs": newRawLogsUnmarshaler()},
+		logger:            zap.NewNop(),
+		ready:             make(chan bool),
+		nextConsumer:      sink,
+		obsrecv:           obsrecv,
+		headerExtractor:   &nopHeaderExtractor{},
+		messageMetadata:   MessageMetadata{Enabled: true, Level: metadataLevelLogRecord},
+		telemetryBuilder:  nopTelemetryBuilder(t),
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	groupClaim := &testConsumerGroupClaim{
+		messageChan: make(chan *sarama.ConsumerMessage),
+	}
+	go func() {
+		assert.NoError(t, c.ConsumeClaim(testConsumerGroupSession{ctx: context.Background()}, groupClaim))
+		wg.Done()
+	}()
+
+	logs := testdata.GenerateLogs(1)
+	bts, err := (&plog.ProtoMarshaler{}).MarshalLogs(logs)
+	require.NoError(t, err)
+	groupClaim.messageChan <- &sarama.ConsumerMessage{Topic: "otlp_logs", Partition: 1, Offset: 10, Value: bts}
+	groupClaim.messageChan <- &sarama.ConsumerMessage{Topic: "raw_logs", Partition: 2, Offset: 20, Value: []byte("raw log")}
+	close(groupClaim.messageChan)
+	wg.Wait()
+
+	allLogs := sink.AllLogs()
+	require.Len(t, allLogs, 2)
+	otlpRecord := allLogs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	rawRecord := allLogs[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, []byte("raw log"), rawRecord.Body().Bytes().AsRaw())
+	for _, tc := range []struct {
+		record    plog.LogRecord
+		topic     string
+		partition int64
+		offset    int64
+	}{
+		{record: otlpRecord, topic: "otlp_logs", partition: 1, offset: 10},
+		{record: rawRecord, topic: "raw_logs", partition: 2, offset: 20},
+	} {
+		topic, _ := tc.record.Attributes().Get(attrKafkaTopic)
+		assert.Equal(t, tc.topic, topic.Str())
+		partition, _ := tc.record.Attributes().Get(attrKafkaPartition)
+		assert.Equal(t, tc.partition, partition.Int())
+		offset, _ := tc.record.Attributes().Get(attrKafkaOffset)
+		assert.Equal(t, tc.offset, offset.Int())
+	}
+	_, ok := allLogs[0].ResourceLogs().At(0).Resource().Attributes().Get(attrKafkaTopic)
+	assert.False(t, ok)
+}
+
+func TestTracesConsumerGroupHandler_message_metadata(t *testing.T) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{ReceiverCreateSettings: receivertest.NewNopSettings(metadata.Type)})
+	require.NoError(t, err)
+	sink := &consumertest.TracesSink{}
+	c := tracesConsumerGroupHandler{
+		unmarshaler:      newPdataTracesUnmarshaler(&ptrace.ProtoUnmarshaler{}, defaultEncoding),
+		logger:           zap.NewNop(),
+		ready:            make(chan bool),
+		nextConsumer:     sink,
+		obsrecv:          obsrecv,
+		headerExtractor:  &nopHeaderExtractor{},
+		messageMetadata:  MessageMetadata{Enabled: true, Level: metadataLevelResource},
+		telemetryBuilder: nopTelemetryBuilder(t),
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	groupClaim := &testConsumerGroupClaim{
+		messageChan: make(chan *sarama.ConsumerMessage),
+	}
+	go func() {
+		assert.NoError(t, c.ConsumeClaim(testConsumerGroupSession{ctx: context.Background()}, groupClaim))
+		wg.Done()
+	}()
+
+	bts, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(testdata.GenerateTraces(1))
+	require.NoError(t, err)
+	groupClaim.messageChan <- &sarama.ConsumerMessage{Topic: "otlp_spans", Partition: 3, Offset: 30, Value: bts}
+	close(groupClaim.messageChan)
+	wg.Wait()
+
+	require.Len(t, sink.AllTraces(), 1)
+	attrs := sink.AllTraces()[0].ResourceSpans().At(0).Resource().Attributes()
+	topic, _ := attrs.Get(attrKafkaTopic)
+	assert.Equal(t, "otlp_spans", topic.Str())
+	partition, _ := attrs.Get(attrKafkaPartition)
+	assert.Equal(t, int64(3), partition.Int())
+	offset, _ := attrs.Get(attrKafkaOffset)
+	assert.Equal(t, int64(30), offset.Int())
+}
+
 func TestGetLogsUnmarshaler_encoding_text(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1250,6 +1350,19 @@ func TestToSaramaInitialOffset_invalid(t *testing.T) {
 	assert.Equal(t, err, errInvalidInitialOffset)
 }
 
+func TestToSaramaIsolationLevel(t *testing.T) {
+	isolationLevel, err := toSaramaIsolationLevel(isolationLevelReadCommitted)
+	require.NoError(t, err)
+	assert.Equal(t, sarama.ReadCommitted, isolationLevel)
+
+	isolationLevel, err = toSaramaIsolationLevel("")
+	require.NoError(t, err)
+	assert.Equal(t, sarama.ReadUncommitted, isolationLevel)
+
+	_, err = toSaramaIsolationLevel("other")
+	assert.Equal(t, errInvalidIsolationLevel, err)
+}
+
 type testConsumerGroupClaim struct {
 	messageChan chan *sarama.ConsumerMessage
 }
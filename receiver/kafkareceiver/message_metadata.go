@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkareceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver"
+
+import (
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	attrKafkaTopic     = "kafka.topic"
+	attrKafkaPartition = "kafka.partition"
+	attrKafkaOffset    = "kafka.offset"
+)
+
+func putMessageMetadata(attrs pcommon.Map, message *sarama.ConsumerMessage) {
+	attrs.PutStr(attrKafkaTopic, message.Topic)
+	attrs.PutInt(attrKafkaPartition, int64(message.Partition))
+	attrs.PutInt(attrKafkaOffset, message.Offset)
+}
+
+func addMessageMetadataTraces(traces ptrace.Traces, message *sarama.ConsumerMessage) {
+	for i := 0; i < traces.ResourceSpans().Len(); i++ {
+		putMessageMetadata(traces.ResourceSpans().At(i).Resource().Attributes(), message)
+	}
+}
+
+func addMessageMetadataMetrics(metrics pmetric.Metrics, message *sarama.ConsumerMessage) {
+	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
+		putMessageMetadata(metrics.ResourceMetrics().At(i).Resource().Attributes(), message)
+	}
+}
+
+// addMessageMetadataLogs adds the message metadata to the resource attributes, or to the log record
+// attributes when toRecords is true.
+func addMessageMetadataLogs(logs plog.Logs, message *sarama.ConsumerMessage, toRecords bool) {
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		rl := logs.ResourceLogs().At(i)
+		if !toRecords {
+			putMessageMetadata(rl.Resource().Attributes(), message)
+			continue
+		}
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			records := rl.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				putMessageMetadata(records.At(k).Attributes(), message)
+			}
+		}
+	}
+}
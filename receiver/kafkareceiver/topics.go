@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkareceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver"
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+// consumerGroupHandler is the handler of the consumer group of a signal.
+type consumerGroupHandler interface {
+	sarama.ConsumerGroupHandler
+	// markReady unblocks the start of the receiver.
+	markReady()
+}
+
+// topicWatcher looks up the topics matching a pattern, and detects the topics created or deleted at runtime.
+type topicWatcher struct {
+	pattern         *regexp.Regexp
+	refreshInterval time.Duration
+
+	// listTopics and closeClient may be set in tests to inject fake implementations.
+	listTopics  func() ([]string, error)
+	closeClient func() error
+}
+
+// newTopicWatcher returns the watcher of the topics matching the topic pattern of the config, or nil
+// when the topics aren't matched by a pattern.
+func newTopicWatcher(config Config) (*topicWatcher, error) {
+	if config.TopicPattern == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile("^(?:" + config.TopicPattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return &topicWatcher{
+		pattern:         pattern,
+		refreshInterval: config.TopicRefreshInterval,
+	}, nil
+}
+
+// start creates the kafka client used to list the topics.
+func (w *topicWatcher) start(ctx context.Context, config Config) error {
+	if w.listTopics != nil {
+		return nil
+	}
+	saramaConfig, err := newSaramaConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	client, err := sarama.NewClient(config.Brokers, saramaConfig)
+	if err != nil {
+		return err
+	}
+	w.listTopics = func() ([]string, error) {
+		if err := client.RefreshMetadata(); err != nil {
+			return nil, err
+		}
+		return client.Topics()
+	}
+	w.closeClient = client.Close
+	return nil
+}
+
+func (w *topicWatcher) shutdown() error {
+	if w.closeClient == nil {
+		return nil
+	}
+	return w.closeClient()
+}
+
+// matchingTopics returns the sorted names of the topics matching the pattern.
+func (w *topicWatcher) matchingTopics() ([]string, error) {
+	topics, err := w.listTopics()
+	if err != nil {
+		return nil, err
+	}
+	var matching []string
+	for _, topic := range topics {
+		if !strings.HasPrefix(topic, "__") && w.pattern.MatchString(topic) {
+			matching = append(matching, topic)
+		}
+	}
+	slices.Sort(matching)
+	return matching, nil
+}
+
+// watch calls onChange when the topics matching the pattern aren't the given topics anymore.
+// It returns when the context is done, or after calling onChange.
+func (w *topicWatcher) watch(ctx context.Context, logger *zap.Logger, topics []string, onChange func()) {
+	ticker := time.NewTicker(w.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			matching, err := w.matchingTopics()
+			if err != nil {
+				logger.Error("Failed to list the topics", zap.Error(err))
+				continue
+			}
+			if !slices.Equal(topics, matching) {
+				logger.Info("Topics matching the pattern changed", zap.Strings("topics", matching))
+				onChange()
+				return
+			}
+		}
+	}
+}
+
+// consumeTopics consumes the topics until the context is cancelled. When the topics are matched by a
+// pattern, the consumer group session is restarted when the matching topics change.
+func consumeTopics(ctx context.Context, logger *zap.Logger, consumerGroup sarama.ConsumerGroup, topics []string, watcher *topicWatcher, handler consumerGroupHandler) {
+	for {
+		sessionCtx, cancelSession := context.WithCancel(ctx)
+		var watchWG sync.WaitGroup
+		if watcher != nil {
+			var err error
+			if topics, err = watcher.matchingTopics(); err != nil {
+				logger.Error("Failed to list the topics", zap.Error(err))
+			} else if len(topics) == 0 {
+				logger.Warn("No topic matches the pattern", zap.String("pattern", watcher.pattern.String()))
+			}
+			if len(topics) == 0 {
+				// Don't block the start of the receiver until a topic matches
+				handler.markReady()
+				waitOrDone(ctx, watcher.refreshInterval)
+				cancelSession()
+				if ctx.Err() != nil {
+					logger.Info("Consumer stopped", zap.Error(ctx.Err()))
+					return
+				}
+				continue
+			}
+			watchWG.Add(1)
+			go func() {
+				defer watchWG.Done()
+				watcher.watch(sessionCtx, logger, topics, cancelSession)
+			}()
+		}
+
+		// `Consume` should be called inside an infinite loop, when a
+		// server-side rebalance happens, the consumer session will need to be
+		// recreated to get the new claims
+		if err := consumerGroup.Consume(sessionCtx, topics, handler); err != nil {
+			logger.Error("Error from consumer", zap.Error(err))
+		}
+		cancelSession()
+		watchWG.Wait()
+		// check if context was cancelled, signaling that the consumer should stop
+		if ctx.Err() != nil {
+			logger.Info("Consumer stopped", zap.Error(ctx.Err()))
+			return
+		}
+	}
+}
+
+func waitOrDone(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
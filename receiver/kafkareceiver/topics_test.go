@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kafkareceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver/internal/metadata"
+)
+
+// fakeTopics is a list of topics which can be changed concurrently.
+type fakeTopics struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (f *fakeTopics) set(topics ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.topics = topics
+}
+
+func (f *fakeTopics) list() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.topics, nil
+}
+
+// sessionConsumerGroup records the topics of each session, and blocks until the session is done.
+type sessionConsumerGroup struct {
+	testConsumerGroup
+	sessions chan []string
+}
+
+func (g *sessionConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	_ = handler.Setup(testConsumerGroupSession{ctx: ctx})
+	g.sessions <- topics
+	<-ctx.Done()
+	return nil
+}
+
+func newTestTopicWatcher(t *testing.T, pattern string, topics *fakeTopics) *topicWatcher {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TopicPattern = pattern
+	cfg.TopicRefreshInterval = 10 * time.Millisecond
+	watcher, err := newTopicWatcher(*cfg)
+	require.NoError(t, err)
+	watcher.listTopics = topics.list
+	return watcher
+}
+
+func TestTopicWatcherMatchingTopics(t *testing.T) {
+	topics := &fakeTopics{}
+	topics.set("logs-b", "__consumer_offsets", "logs-a", "spans", "app-logs-a")
+	watcher := newTestTopicWatcher(t, "logs-.*|__.*", topics)
+
+	// The pattern matches whole topic names, and the internal topics are ignored
+	matching, err := watcher.matchingTopics()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs-a", "logs-b"}, matching)
+}
+
+func TestConsumeTopicsPattern(t *testing.T) {
+	topics := &fakeTopics{}
+	topics.set("logs-a")
+	watcher := newTestTopicWatcher(t, "logs-.*", topics)
+	consumerGroup := &sessionConsumerGroup{sessions: make(chan []string)}
+
+	c := kafkaLogsConsumer{
+		config:           Config{Encoding: defaultEncoding},
+		nextConsumer:     consumertest.NewNop(),
+		consumeLoopWG:    &sync.WaitGroup{},
+		settings:         receivertest.NewNopSettings(metadata.Type),
+		consumerGroup:    consumerGroup,
+		topicWatcher:     watcher,
+		telemetryBuilder: nopTelemetryBuilder(t),
+	}
+	go func() {
+		assert.NoError(t, c.Start(context.Background(), componenttest.NewNopHost()))
+	}()
+	assert.Equal(t, []string{"logs-a"}, <-consumerGroup.sessions)
+
+	// The session is restarted when a topic matching the pattern is created
+	topics.set("logs-a", "logs-b", "spans")
+	assert.Equal(t, []string{"logs-a", "logs-b"}, <-consumerGroup.sessions)
+
+	go func() {
+		for range consumerGroup.sessions {
+		}
+	}()
+	require.NoError(t, c.Shutdown(context.Background()))
+	close(consumerGroup.sessions)
+}
+
+func TestConsumeTopicsNoMatch(t *testing.T) {
+	topics := &fakeTopics{}
+	watcher := newTestTopicWatcher(t, "logs-.*", topics)
+	consumerGroup := &sessionConsumerGroup{sessions: make(chan []string)}
+
+	c := kafkaLogsConsumer{
+		config:           Config{Encoding: defaultEncoding},
+		nextConsumer:     consumertest.NewNop(),
+		consumeLoopWG:    &sync.WaitGroup{},
+		settings:         receivertest.NewNopSettings(metadata.Type),
+		consumerGroup:    consumerGroup,
+		topicWatcher:     watcher,
+		telemetryBuilder: nopTelemetryBuilder(t),
+	}
+
+	// The receiver starts even though no topic matches the pattern yet
+	require.NoError(t, c.Start(context.Background(), componenttest.NewNopHost()))
+	topics.set("logs-a")
+	assert.Equal(t, []string{"logs-a"}, <-consumerGroup.sessions)
+
+	go func() {
+		for range consumerGroup.sessions {
+		}
+	}()
+	require.NoError(t, c.Shutdown(context.Background()))
+	close(consumerGroup.sessions)
+}
+
+func TestConsumeTopicsStatic(t *testing.T) {
+	consumerGroup := &sessionConsumerGroup{sessions: make(chan []string, 1)}
+	handler := &logsConsumerGroupHandler{
+		ready:            make(chan bool),
+		telemetryBuilder: nopTelemetryBuilder(t),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		consumeTopics(ctx, zap.NewNop(), consumerGroup, []string{"logs", "audit"}, nil, handler)
+		close(done)
+	}()
+	assert.Equal(t, []string{"logs", "audit"}, <-consumerGroup.sessions)
+	cancel()
+	<-done
+}
@@ -16,6 +16,7 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/sharding"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/targetallocator"
 )
 
@@ -36,6 +37,10 @@ type Config struct {
 	ReportExtraScrapeMetrics bool `mapstructure:"report_extra_scrape_metrics"`
 
 	TargetAllocator *targetallocator.Config `mapstructure:"target_allocator"`
+
+	// Sharding distributes the discovered scrape targets among the collector replicas,
+	// without requiring the target allocator.
+	Sharding *sharding.Config `mapstructure:"sharding"`
 }
 
 // Validate checks the receiver configuration is valid.
@@ -43,6 +48,9 @@ func (cfg *Config) Validate() error {
 	if !containsScrapeConfig(cfg) && cfg.TargetAllocator == nil {
 		return errors.New("no Prometheus scrape_configs or target_allocator set")
 	}
+	if cfg.Sharding != nil && cfg.TargetAllocator != nil {
+		return errors.New("sharding and target_allocator can't be used together")
+	}
 	return nil
 }
 
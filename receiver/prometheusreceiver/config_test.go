@@ -107,6 +107,31 @@ func TestLoadTargetAllocatorConfig(t *testing.T) {
 	assert.Equal(t, promModel.Duration(5*time.Second), r2.PrometheusConfig.ScrapeConfigs[0].ScrapeInterval)
 }
 
+func TestLoadShardingConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config_sharding.yaml"))
+	require.NoError(t, err)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+
+	sub, err := cm.Sub(component.NewIDWithName(metadata.Type, "").String())
+	require.NoError(t, err)
+	require.NoError(t, sub.Unmarshal(cfg))
+	require.NoError(t, xconfmap.Validate(cfg))
+
+	r0 := cfg.(*Config)
+	require.NotNil(t, r0.Sharding)
+	assert.Equal(t, "otel-collector-headless.monitoring.svc.cluster.local", r0.Sharding.DNSName)
+	assert.Equal(t, "10.0.0.1", r0.Sharding.SelfAddress)
+	assert.Equal(t, 15*time.Second, r0.Sharding.RefreshInterval)
+	assert.Len(t, r0.PrometheusConfig.ScrapeConfigs, 1)
+
+	sub, err = cm.Sub(component.NewIDWithName(metadata.Type, "withTA").String())
+	require.NoError(t, err)
+	cfg = factory.CreateDefaultConfig()
+	require.NoError(t, sub.Unmarshal(cfg))
+	require.ErrorContains(t, xconfmap.Validate(cfg), "sharding and target_allocator can't be used together")
+}
+
 func TestValidateConfigWithScrapeConfigFiles(t *testing.T) {
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config_scrape_config_files.yaml"))
 	require.NoError(t, err)
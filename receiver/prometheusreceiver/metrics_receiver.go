@@ -27,6 +27,7 @@ import (
 	"go.uber.org/zap/exp/zapslog"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/internal"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/sharding"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/targetallocator"
 )
 
@@ -47,6 +48,7 @@ type pReceiver struct {
 	scrapeManager          *scrape.Manager
 	discoveryManager       *discovery.Manager
 	targetAllocatorManager *targetallocator.Manager
+	shardingManager        *sharding.Manager
 	registerer             prometheus.Registerer
 	unregisterMetrics      func()
 	skipOffsetting         bool // for testing only
@@ -70,6 +72,9 @@ func newPrometheusReceiver(set receiver.Settings, cfg *Config, next consumer.Met
 			enableNativeHistogramsGate.IsEnabled(),
 		),
 	}
+	if cfg.Sharding != nil {
+		pr.shardingManager = sharding.NewManager(set, cfg.Sharding)
+	}
 	return pr
 }
 
@@ -187,7 +192,13 @@ func (r *pReceiver) initPrometheusComponents(ctx context.Context, logger *slog.L
 		// The scrape manager needs to wait for the configuration to be loaded before beginning
 		<-r.configLoaded
 		r.settings.Logger.Info("Starting scrape manager")
-		if err := r.scrapeManager.Run(r.discoveryManager.SyncCh()); err != nil {
+		tsets := r.discoveryManager.SyncCh()
+		if r.shardingManager != nil {
+			// Only scrape the targets owned by this replica.
+			r.shardingManager.Start(ctx, tsets)
+			tsets = r.shardingManager.SyncCh()
+		}
+		if err := r.scrapeManager.Run(tsets); err != nil {
 			r.settings.Logger.Error("Scrape manager failed", zap.Error(err))
 			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(err))
 		}
@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sharding // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/sharding"
+
+import (
+	"errors"
+	"time"
+)
+
+const defaultRefreshInterval = 30 * time.Second
+
+// Config configures the sharding of the scrape targets among the collector replicas.
+type Config struct {
+	// DNSName is resolved to the addresses of all collector replicas, for example
+	// the name of a Kubernetes headless service selecting the collector pods.
+	DNSName string `mapstructure:"dns_name"`
+	// SelfAddress is the address of this replica, as returned by the resolution of DNSName.
+	// If empty, the addresses of the network interfaces of the host are used to find it.
+	SelfAddress string `mapstructure:"self_address"`
+	// RefreshInterval is the interval at which DNSName is resolved again. Defaults to 30s.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.DNSName == "" {
+		return errors.New("dns_name must be set")
+	}
+	if cfg.RefreshInterval < 0 {
+		return errors.New("refresh_interval can't be negative")
+	}
+	return nil
+}
+
+func (cfg *Config) refreshInterval() time.Duration {
+	if cfg.RefreshInterval > 0 {
+		return cfg.RefreshInterval
+	}
+	return defaultRefreshInterval
+}
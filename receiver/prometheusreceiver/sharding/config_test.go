@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sharding
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+)
+
+func TestComponentConfigStruct(t *testing.T) {
+	require.NoError(t, componenttest.CheckConfigStruct(Config{}))
+}
+
+func TestLoadShardingConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	cfg := &Config{}
+	sub, err := cm.Sub("sharding")
+	require.NoError(t, err)
+	require.NoError(t, sub.Unmarshal(cfg))
+	require.NoError(t, xconfmap.Validate(cfg))
+
+	assert.Equal(t, "otel-collector-headless.monitoring.svc.cluster.local", cfg.DNSName)
+	assert.Equal(t, "10.0.0.1", cfg.SelfAddress)
+	assert.Equal(t, 15*time.Second, cfg.RefreshInterval)
+	assert.Equal(t, 15*time.Second, cfg.refreshInterval())
+
+	cfg = &Config{}
+	sub, err = cm.Sub("sharding/missing_dns_name")
+	require.NoError(t, err)
+	require.NoError(t, sub.Unmarshal(cfg))
+	require.ErrorContains(t, xconfmap.Validate(cfg), "dns_name must be set")
+	assert.Equal(t, defaultRefreshInterval, cfg.refreshInterval())
+}
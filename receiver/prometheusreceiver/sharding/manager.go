@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sharding // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/sharding"
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+type resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Manager filters the target groups produced by the discovery manager, so that only the
+// targets owned by this replica are scraped. Targets are assigned to the replicas with
+// rendezvous hashing, so that a change of replicas only moves the targets of the
+// replicas that were added or removed.
+type Manager struct {
+	settings       receiver.Settings
+	cfg            *Config
+	resolver       resolver
+	interfaceAddrs func() ([]net.Addr, error)
+	syncCh         chan map[string][]*targetgroup.Group
+
+	self  string
+	peers []string
+}
+
+func NewManager(set receiver.Settings, cfg *Config) *Manager {
+	return &Manager{
+		settings:       set,
+		cfg:            cfg,
+		resolver:       net.DefaultResolver,
+		interfaceAddrs: net.InterfaceAddrs,
+		syncCh:         make(chan map[string][]*targetgroup.Group),
+	}
+}
+
+// SyncCh returns the channel on which the target groups owned by this replica are sent.
+func (m *Manager) SyncCh() <-chan map[string][]*targetgroup.Group {
+	return m.syncCh
+}
+
+// Start resolves the replicas, and starts filtering the target groups received on tsets until ctx is done.
+func (m *Manager) Start(ctx context.Context, tsets <-chan map[string][]*targetgroup.Group) {
+	m.refreshPeers(ctx)
+	go m.run(ctx, tsets)
+}
+
+func (m *Manager) run(ctx context.Context, tsets <-chan map[string][]*targetgroup.Group) {
+	ticker := time.NewTicker(m.cfg.refreshInterval())
+	defer ticker.Stop()
+
+	var last map[string][]*targetgroup.Group
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case groups, ok := <-tsets:
+			if !ok {
+				return
+			}
+			last = groups
+		case <-ticker.C:
+			if !m.refreshPeers(ctx) || last == nil {
+				continue
+			}
+		}
+
+		select {
+		case m.syncCh <- m.filter(last):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshPeers resolves the replicas, and returns whether they changed.
+func (m *Manager) refreshPeers(ctx context.Context) bool {
+	addrs, err := m.resolver.LookupHost(ctx, m.cfg.DNSName)
+	if err != nil {
+		m.settings.Logger.Warn("Failed to resolve collector replicas, keeping the previous ones", zap.String("dns_name", m.cfg.DNSName), zap.Error(err))
+		return false
+	}
+	sort.Strings(addrs)
+	addrs = slices.Compact(addrs)
+
+	self := m.selfAddress(addrs)
+	if !slices.Contains(addrs, self) {
+		// This replica is not resolved yet, it still takes its share of the targets.
+		addrs = append(addrs, self)
+		sort.Strings(addrs)
+	}
+
+	if self == m.self && slices.Equal(addrs, m.peers) {
+		return false
+	}
+	m.self = self
+	m.peers = addrs
+	m.settings.Logger.Info("Collector replicas changed", zap.String("self", self), zap.Strings("replicas", addrs))
+	return true
+}
+
+// selfAddress returns the address of this replica.
+func (m *Manager) selfAddress(addrs []string) string {
+	if m.cfg.SelfAddress != "" {
+		return m.cfg.SelfAddress
+	}
+	local, err := m.interfaceAddrs()
+	if err != nil {
+		m.settings.Logger.Warn("Failed to list the interface addresses", zap.Error(err))
+	}
+	for _, a := range local {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip := ipNet.IP.String(); slices.Contains(addrs, ip) {
+			return ip
+		}
+	}
+	if m.self != "" {
+		return m.self
+	}
+	m.settings.Logger.Warn("This replica is not part of the resolved replicas, set self_address to identify it", zap.Strings("replicas", addrs))
+	for _, a := range local {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			return ipNet.IP.String()
+		}
+	}
+	return "localhost"
+}
+
+// filter returns the target groups with only the targets owned by this replica.
+// Groups are kept even when empty, so that the scrape manager stops scraping
+// targets that moved to another replica.
+func (m *Manager) filter(tsets map[string][]*targetgroup.Group) map[string][]*targetgroup.Group {
+	out := make(map[string][]*targetgroup.Group, len(tsets))
+	for job, groups := range tsets {
+		filtered := make([]*targetgroup.Group, 0, len(groups))
+		for _, group := range groups {
+			if group == nil {
+				continue
+			}
+			owned := &targetgroup.Group{
+				Labels: group.Labels,
+				Source: group.Source,
+			}
+			for _, target := range group.Targets {
+				if owner(m.peers, job, string(target[model.AddressLabel])) == m.self {
+					owned.Targets = append(owned.Targets, target)
+				}
+			}
+			filtered = append(filtered, owned)
+		}
+		out[job] = filtered
+	}
+	return out
+}
+
+// owner returns the replica owning the target of the job, using rendezvous hashing.
+func owner(peers []string, job, address string) string {
+	var (
+		best      string
+		bestScore uint64
+	)
+	for _, peer := range peers {
+		d := xxhash.New()
+		_, _ = d.WriteString(peer)
+		_, _ = d.WriteString("\xff")
+		_, _ = d.WriteString(job)
+		_, _ = d.WriteString("\xff")
+		_, _ = d.WriteString(address)
+		if score := d.Sum64(); best == "" || score > bestScore {
+			best, bestScore = peer, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver/internal/metadata"
+)
+
+type fakeResolver struct {
+	mu    sync.Mutex
+	addrs []string
+	err   error
+}
+
+func (r *fakeResolver) LookupHost(context.Context, string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addrs, r.err
+}
+
+func (r *fakeResolver) set(addrs []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addrs = addrs
+	r.err = err
+}
+
+func newTestManager(t *testing.T, self string, res resolver) *Manager {
+	m := NewManager(receivertest.NewNopSettings(metadata.Type), &Config{
+		DNSName:         "collectors",
+		SelfAddress:     self,
+		RefreshInterval: 10 * time.Millisecond,
+	})
+	m.resolver = res
+	m.interfaceAddrs = func() ([]net.Addr, error) {
+		t.Fatal("interface addresses must not be used when self_address is set")
+		return nil, nil
+	}
+	return m
+}
+
+func targetGroups(n int) map[string][]*targetgroup.Group {
+	group := &targetgroup.Group{Source: "0", Labels: model.LabelSet{"env": "test"}}
+	for i := 0; i < n; i++ {
+		group.Targets = append(group.Targets, model.LabelSet{model.AddressLabel: model.LabelValue(fmt.Sprintf("10.1.0.%d:9100", i))})
+	}
+	return map[string][]*targetgroup.Group{"node": {group}}
+}
+
+func addresses(tsets map[string][]*targetgroup.Group) []string {
+	var out []string
+	for _, groups := range tsets {
+		for _, group := range groups {
+			for _, target := range group.Targets {
+				out = append(out, string(target[model.AddressLabel]))
+			}
+		}
+	}
+	return out
+}
+
+func TestManagerPartitionsTargets(t *testing.T) {
+	peers := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	res := &fakeResolver{addrs: peers}
+	tsets := targetGroups(100)
+
+	var all []string
+	for _, self := range peers {
+		m := newTestManager(t, self, res)
+		require.True(t, m.refreshPeers(context.Background()))
+		filtered := m.filter(tsets)
+		require.Len(t, filtered["node"], 1)
+		assert.Equal(t, model.LabelSet{"env": "test"}, filtered["node"][0].Labels)
+		owned := addresses(filtered)
+		assert.NotEmpty(t, owned)
+		all = append(all, owned...)
+	}
+	assert.ElementsMatch(t, addresses(tsets), all)
+}
+
+func TestManagerIncludesSelf(t *testing.T) {
+	m := newTestManager(t, "10.0.0.4", &fakeResolver{addrs: []string{"10.0.0.2", "10.0.0.1"}})
+	require.True(t, m.refreshPeers(context.Background()))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.4"}, m.peers)
+	assert.False(t, m.refreshPeers(context.Background()))
+}
+
+func TestManagerSelfFromInterfaces(t *testing.T) {
+	m := NewManager(receivertest.NewNopSettings(metadata.Type), &Config{DNSName: "collectors"})
+	m.resolver = &fakeResolver{addrs: []string{"10.0.0.1", "10.0.0.2"}}
+	m.interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("10.0.0.2"), Mask: net.CIDRMask(24, 32)},
+		}, nil
+	}
+	require.True(t, m.refreshPeers(context.Background()))
+	assert.Equal(t, "10.0.0.2", m.self)
+}
+
+func TestManagerKeepsPeersOnResolveError(t *testing.T) {
+	res := &fakeResolver{addrs: []string{"10.0.0.1", "10.0.0.2"}}
+	m := newTestManager(t, "10.0.0.1", res)
+	require.True(t, m.refreshPeers(context.Background()))
+
+	res.set(nil, errors.New("no such host"))
+	assert.False(t, m.refreshPeers(context.Background()))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, m.peers)
+}
+
+func TestManagerResyncsOnPeersChange(t *testing.T) {
+	res := &fakeResolver{addrs: []string{"10.0.0.1"}}
+	m := newTestManager(t, "10.0.0.1", res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan map[string][]*targetgroup.Group)
+	m.Start(ctx, in)
+
+	tsets := targetGroups(50)
+	in <- tsets
+	assert.ElementsMatch(t, addresses(tsets), addresses(<-m.SyncCh()))
+
+	// A new replica takes its share of the targets, without a new discovery update.
+	res.set([]string{"10.0.0.1", "10.0.0.2"}, nil)
+	owned := addresses(<-m.SyncCh())
+	assert.NotEmpty(t, owned)
+	assert.Less(t, len(owned), 50)
+	for _, address := range owned {
+		assert.Equal(t, "10.0.0.1", owner([]string{"10.0.0.1", "10.0.0.2"}, "node", address))
+	}
+}
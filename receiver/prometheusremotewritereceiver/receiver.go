@@ -3,23 +3,30 @@
 package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/cespare/xxhash/v2"
 	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
 	promconfig "github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
 	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	promremote "github.com/prometheus/prometheus/storage/remote"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/receiver"
@@ -37,12 +44,16 @@ func newRemoteWriteReceiver(settings receiver.Settings, cfg *Config, nextConsume
 	}, nil
 }
 
+// defaultMaxRequestBodySize is the default of confighttp.ServerConfig.MaxRequestBodySize.
+const defaultMaxRequestBodySize = 20 * 1024 * 1024
+
 type prometheusRemoteWriteReceiver struct {
 	settings     receiver.Settings
 	nextConsumer consumer.Metrics
 
 	config *Config
 	server *http.Server
+	wg     sync.WaitGroup
 }
 
 func (prw *prometheusRemoteWriteReceiver) Start(ctx context.Context, host component.Host) error {
@@ -50,7 +61,12 @@ func (prw *prometheusRemoteWriteReceiver) Start(ctx context.Context, host compon
 	mux.HandleFunc("/api/v1/write", prw.handlePRW)
 	var err error
 
-	prw.server, err = prw.config.ToServer(ctx, host, prw.settings.TelemetrySettings, mux)
+	// The decoded bodies are limited like confighttp limits them, defaulting to 20MiB.
+	maxDecodedSize := prw.config.MaxRequestBodySize
+	if maxDecodedSize <= 0 {
+		maxDecodedSize = defaultMaxRequestBodySize
+	}
+	prw.server, err = prw.config.ToServer(ctx, host, prw.settings.TelemetrySettings, mux, confighttp.WithDecoder("snappy", newSnappyBlockDecoder(maxDecodedSize)))
 	if err != nil {
 		return fmt.Errorf("failed to create server definition: %w", err)
 	}
@@ -59,7 +75,9 @@ func (prw *prometheusRemoteWriteReceiver) Start(ctx context.Context, host compon
 		return fmt.Errorf("failed to create prometheus remote-write listener: %w", err)
 	}
 
+	prw.wg.Add(1)
 	go func() {
+		defer prw.wg.Done()
 		if err := prw.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(fmt.Errorf("error starting prometheus remote-write receiver: %w", err)))
 		}
@@ -71,7 +89,10 @@ func (prw *prometheusRemoteWriteReceiver) Shutdown(ctx context.Context) error {
 	if prw.server == nil {
 		return nil
 	}
-	return prw.server.Shutdown(ctx)
+	err := prw.server.Shutdown(ctx)
+	// Wait for the server goroutine to release the listener.
+	prw.wg.Wait()
+	return err
 }
 
 func (prw *prometheusRemoteWriteReceiver) handlePRW(w http.ResponseWriter, req *http.Request) {
@@ -88,14 +109,9 @@ func (prw *prometheusRemoteWriteReceiver) handlePRW(w http.ResponseWriter, req *
 		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
 		return
 	}
-	if msgType != promconfig.RemoteWriteProtoMsgV2 {
-		prw.settings.Logger.Warn("message received with unsupported proto version, rejecting")
-		http.Error(w, "Unsupported proto version", http.StatusUnsupportedMediaType)
-		return
-	}
-
 	// After parsing the content-type header, the next step would be to handle content-encoding.
 	// Luckly confighttp's Server has middleware that already decompress the request body for us.
+	// Remote-Write mandates the snappy block format, so the receiver registers its own snappy decoder.
 
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
@@ -104,17 +120,52 @@ func (prw *prometheusRemoteWriteReceiver) handlePRW(w http.ResponseWriter, req *
 		return
 	}
 
-	var prw2Req writev2.Request
-	if err = proto.Unmarshal(body, &prw2Req); err != nil {
-		prw.settings.Logger.Warn("Error decoding remote write request", zapcore.Field{Key: "error", Type: zapcore.ErrorType, Interface: err})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var (
+		otelMetrics pmetric.Metrics
+		stats       promremote.WriteResponseStats
+		translErr   error
+	)
+	switch msgType {
+	case promconfig.RemoteWriteProtoMsgV1:
+		var prw1Req prompb.WriteRequest
+		if err = proto.Unmarshal(body, &prw1Req); err != nil {
+			prw.settings.Logger.Warn("Error decoding remote write request", zapcore.Field{Key: "error", Type: zapcore.ErrorType, Interface: err})
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		otelMetrics, stats, translErr = prw.translateV1(req.Context(), &prw1Req)
+	case promconfig.RemoteWriteProtoMsgV2:
+		var prw2Req writev2.Request
+		if err = proto.Unmarshal(body, &prw2Req); err != nil {
+			prw.settings.Logger.Warn("Error decoding remote write request", zapcore.Field{Key: "error", Type: zapcore.ErrorType, Interface: err})
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		otelMetrics, stats, translErr = prw.translateV2(req.Context(), &prw2Req)
+	default:
+		prw.settings.Logger.Warn("message received with unsupported proto version, rejecting")
+		http.Error(w, "Unsupported proto version", http.StatusUnsupportedMediaType)
 		return
 	}
 
-	_, stats, err := prw.translateV2(req.Context(), &prw2Req)
+	// Valid series are forwarded even if some others were rejected, as partial writes are allowed.
+	// More: https://prometheus.io/docs/specs/remote_write_spec_2_0/#partial-write
+	if otelMetrics.DataPointCount() > 0 {
+		if err = prw.nextConsumer.ConsumeMetrics(req.Context(), otelMetrics); err != nil {
+			// Nothing was written, so the stats must not be reported.
+			promremote.WriteResponseStats{}.SetHeaders(w)
+			status := http.StatusInternalServerError
+			if consumererror.IsPermanent(err) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+
 	stats.SetHeaders(w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest) // Following instructions at https://prometheus.io/docs/specs/remote_write_spec_2_0/#invalid-samples
+	if translErr != nil {
+		http.Error(w, translErr.Error(), http.StatusBadRequest) // Following instructions at https://prometheus.io/docs/specs/remote_write_spec_2_0/#invalid-samples
 		return
 	}
 
@@ -151,63 +202,193 @@ func (prw *prometheusRemoteWriteReceiver) parseProto(contentType string) (promco
 	return promconfig.RemoteWriteProtoMsgV1, nil
 }
 
+// translateV1 translates a v1 remote-write request into OTLP metrics.
+// Remote-Write 1.0 only optionally carries metadata, so when it is missing the metric type
+// is inferred from the series names and labels.
+func (prw *prometheusRemoteWriteReceiver) translateV1(_ context.Context, req *prompb.WriteRequest) (pmetric.Metrics, promremote.WriteResponseStats, error) {
+	var (
+		badRequestErrors error
+		mb               = newMetricsBuilder()
+		labelsBuilder    = labels.NewScratchBuilder(0)
+		metadataByFamily = make(map[string]prompb.MetricMetadata, len(req.Metadata))
+		seriesLabels     = make([]labels.Labels, len(req.Timeseries))
+		families         = make(map[string]model.MetricType)
+	)
+
+	for _, md := range req.Metadata {
+		metadataByFamily[md.MetricFamilyName] = md
+	}
+
+	// A first pass collects the classic histogram and summary families present in the request,
+	// so that their _sum and _count series can be typed accordingly.
+	for i, ts := range req.Timeseries {
+		ls := ts.ToLabels(&labelsBuilder, nil)
+		seriesLabels[i] = ls
+		name := ls.Get(labels.MetricName)
+		switch {
+		case ls.Has(model.BucketLabel) && strings.HasSuffix(name, "_bucket"):
+			families[strings.TrimSuffix(name, "_bucket")] = model.MetricTypeHistogram
+		case ls.Has(model.QuantileLabel):
+			families[name] = model.MetricTypeSummary
+		}
+	}
+
+	for i, ts := range req.Timeseries {
+		ls := seriesLabels[i]
+		s := series{labels: ls}
+
+		md, found := lookupMetadataV1(metadataByFamily, ls.Get(labels.MetricName))
+		if found {
+			s.metricType = metricTypeFromV1(md.Type)
+			s.help = md.Help
+			s.unit = md.Unit
+		} else {
+			s.metricType = inferMetricType(ls, len(ts.Histograms) > 0, families)
+		}
+
+		s.samples = make([]sample, 0, len(ts.Samples))
+		for _, smpl := range ts.Samples {
+			s.samples = append(s.samples, sample{timestamp: smpl.Timestamp, value: smpl.Value})
+		}
+		s.histograms = make([]histogramSample, 0, len(ts.Histograms))
+		for _, h := range ts.Histograms {
+			s.histograms = append(s.histograms, histogramSample{timestamp: h.Timestamp, histogram: h.ToFloatHistogram()})
+		}
+		s.exemplars = make([]exemplar.Exemplar, 0, len(ts.Exemplars))
+		for _, e := range ts.Exemplars {
+			s.exemplars = append(s.exemplars, e.ToExemplar(&labelsBuilder, nil))
+		}
+
+		if err := mb.addSeries(s); err != nil {
+			badRequestErrors = errors.Join(badRequestErrors, err)
+		}
+	}
+
+	otelMetrics, stats := mb.build()
+	return otelMetrics, stats, badRequestErrors
+}
+
 // translateV2 translates a v2 remote-write request into OTLP metrics.
-// translate is not feature complete.
-//
-//nolint:unparam
 func (prw *prometheusRemoteWriteReceiver) translateV2(_ context.Context, req *writev2.Request) (pmetric.Metrics, promremote.WriteResponseStats, error) {
 	var (
 		badRequestErrors error
-		otelMetrics      = pmetric.NewMetrics()
+		mb               = newMetricsBuilder()
 		labelsBuilder    = labels.NewScratchBuilder(0)
-		stats            = promremote.WriteResponseStats{}
-		// Prometheus Remote-Write can send multiple time series with the same labels in the same request.
-		// Instead of creating a whole new OTLP metric, we just append the new sample to the existing OTLP metric.
-		// This cache is called "intra" because in the future we'll have a "interRequestCache" to cache resourceAttributes
-		// between requests based on the metric "target_info".
-		intraRequestCache = make(map[uint64]pmetric.ResourceMetrics)
 	)
 
 	for _, ts := range req.Timeseries {
-		ls := ts.ToLabels(&labelsBuilder, req.Symbols)
-
-		if !ls.Has(labels.MetricName) {
-			badRequestErrors = errors.Join(badRequestErrors, fmt.Errorf("missing metric name in labels"))
-			continue
-		} else if duplicateLabel, hasDuplicate := ls.HasDuplicateLabelNames(); hasDuplicate {
-			badRequestErrors = errors.Join(badRequestErrors, fmt.Errorf("duplicate label %q in labels", duplicateLabel))
+		if err := validateSymbolRefs(ts, len(req.Symbols)); err != nil {
+			badRequestErrors = errors.Join(badRequestErrors, err)
 			continue
 		}
 
-		var rm pmetric.ResourceMetrics
-		hashedLabels := xxhash.Sum64String(ls.Get("job") + string([]byte{'\xff'}) + ls.Get("instance"))
-		intraCacheEntry, ok := intraRequestCache[hashedLabels]
-		if ok {
-			// We found the same time series in the same request, so we should append to the same OTLP metric.
-			rm = intraCacheEntry
-		} else {
-			rm = otelMetrics.ResourceMetrics().AppendEmpty()
-			parseJobAndInstance(rm.Resource().Attributes(), ls.Get("job"), ls.Get("instance"))
-			intraRequestCache[hashedLabels] = rm
+		md := ts.ToMetadata(req.Symbols)
+		s := series{
+			labels:           ts.ToLabels(&labelsBuilder, req.Symbols),
+			metricType:       md.Type,
+			help:             md.Help,
+			unit:             md.Unit,
+			createdTimestamp: ts.CreatedTimestamp,
+			samples:          make([]sample, 0, len(ts.Samples)),
+			histograms:       make([]histogramSample, 0, len(ts.Histograms)),
+			exemplars:        make([]exemplar.Exemplar, 0, len(ts.Exemplars)),
+		}
+		for _, smpl := range ts.Samples {
+			s.samples = append(s.samples, sample{timestamp: smpl.Timestamp, value: smpl.Value})
+		}
+		for _, h := range ts.Histograms {
+			s.histograms = append(s.histograms, histogramSample{timestamp: h.Timestamp, histogram: h.ToFloatHistogram()})
+		}
+		for _, e := range ts.Exemplars {
+			s.exemplars = append(s.exemplars, e.ToExemplar(&labelsBuilder, req.Symbols))
 		}
 
-		switch ts.Metadata.Type {
-		case writev2.Metadata_METRIC_TYPE_COUNTER:
-			addCounterDatapoints(rm, ls, ts)
-		case writev2.Metadata_METRIC_TYPE_GAUGE:
-			addGaugeDatapoints(rm, ls, ts)
-		case writev2.Metadata_METRIC_TYPE_SUMMARY:
-			addSummaryDatapoints(rm, ls, ts)
-		case writev2.Metadata_METRIC_TYPE_HISTOGRAM:
-			addHistogramDatapoints(rm, ls, ts)
-		default:
-			badRequestErrors = errors.Join(badRequestErrors, fmt.Errorf("unsupported metric type %q for metric %q", ts.Metadata.Type, ls.Get(labels.MetricName)))
+		if err := mb.addSeries(s); err != nil {
+			badRequestErrors = errors.Join(badRequestErrors, err)
 		}
 	}
 
+	otelMetrics, stats := mb.build()
 	return otelMetrics, stats, badRequestErrors
 }
 
+// validateSymbolRefs makes sure every reference of the time series points into the symbols table,
+// since the desymbolization helpers do not check bounds.
+func validateSymbolRefs(ts writev2.TimeSeries, symbols int) error {
+	refs := make([]uint32, 0, len(ts.LabelsRefs)+2)
+	refs = append(refs, ts.LabelsRefs...)
+	refs = append(refs, ts.Metadata.HelpRef, ts.Metadata.UnitRef)
+	for _, e := range ts.Exemplars {
+		refs = append(refs, e.LabelsRefs...)
+	}
+	if len(ts.LabelsRefs)%2 != 0 {
+		return fmt.Errorf("odd number of label references: %d", len(ts.LabelsRefs))
+	}
+	for _, ref := range refs {
+		if int(ref) >= symbols {
+			return fmt.Errorf("symbol reference %d out of range, symbols table has %d entries", ref, symbols)
+		}
+	}
+	return nil
+}
+
+// lookupMetadataV1 finds the metadata of a Remote-Write 1.0 series. Metadata is keyed by metric
+// family name, which does not include the suffixes of classic histograms, summaries and counters.
+func lookupMetadataV1(metadataByFamily map[string]prompb.MetricMetadata, metricName string) (prompb.MetricMetadata, bool) {
+	if md, ok := metadataByFamily[metricName]; ok {
+		return md, true
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count", "_total"} {
+		if md, ok := metadataByFamily[strings.TrimSuffix(metricName, suffix)]; ok && strings.HasSuffix(metricName, suffix) {
+			return md, true
+		}
+	}
+	return prompb.MetricMetadata{}, false
+}
+
+// inferMetricType guesses the type of a Remote-Write 1.0 series sent without metadata.
+// Series that cannot be recognized are unknown, which is translated into a gauge.
+func inferMetricType(ls labels.Labels, hasHistograms bool, families map[string]model.MetricType) model.MetricType {
+	name := ls.Get(labels.MetricName)
+	switch {
+	case hasHistograms:
+		return model.MetricTypeHistogram
+	case ls.Has(model.QuantileLabel):
+		return model.MetricTypeSummary
+	case strings.HasSuffix(name, "_bucket") && ls.Has(model.BucketLabel):
+		return model.MetricTypeHistogram
+	case strings.HasSuffix(name, "_sum"), strings.HasSuffix(name, "_count"):
+		family, _ := splitHistogramSuffix(name, "_sum", "_count")
+		if typ, ok := families[family]; ok {
+			return typ
+		}
+	case strings.HasSuffix(name, "_total"):
+		return model.MetricTypeCounter
+	}
+	return model.MetricTypeUnknown
+}
+
+func metricTypeFromV1(t prompb.MetricMetadata_MetricType) model.MetricType {
+	switch t {
+	case prompb.MetricMetadata_COUNTER:
+		return model.MetricTypeCounter
+	case prompb.MetricMetadata_GAUGE:
+		return model.MetricTypeGauge
+	case prompb.MetricMetadata_HISTOGRAM:
+		return model.MetricTypeHistogram
+	case prompb.MetricMetadata_GAUGEHISTOGRAM:
+		return model.MetricTypeGaugeHistogram
+	case prompb.MetricMetadata_SUMMARY:
+		return model.MetricTypeSummary
+	case prompb.MetricMetadata_INFO:
+		return model.MetricTypeInfo
+	case prompb.MetricMetadata_STATESET:
+		return model.MetricTypeStateset
+	default:
+		return model.MetricTypeUnknown
+	}
+}
+
 // parseJobAndInstance turns the job and instance labels service resource attributes.
 // Following the specification at https://opentelemetry.io/docs/specs/otel/compatibility/prometheus_and_openmetrics/
 func parseJobAndInstance(dest pcommon.Map, job, instance string) {
@@ -225,65 +406,27 @@ func parseJobAndInstance(dest pcommon.Map, job, instance string) {
 	}
 }
 
-func addCounterDatapoints(_ pmetric.ResourceMetrics, _ labels.Labels, _ writev2.TimeSeries) {
-	// TODO: Implement this function
-}
-
-func addGaugeDatapoints(rm pmetric.ResourceMetrics, ls labels.Labels, ts writev2.TimeSeries) {
-	// TODO: Cache metric name+type+unit and look up cache before creating new empty metric.
-	// In OTel name+type+unit is the unique identifier of a metric and we should not create
-	// a new metric if it already exists.
-
-	scopeName := ls.Get("otel_scope_name")
-	scopeVersion := ls.Get("otel_scope_version")
-	// TODO: If the scope version or scope name is empty, get the information from the collector build tags.
-	// More: https://opentelemetry.io/docs/specs/otel/compatibility/prometheus_and_openmetrics/#:~:text=Metrics%20which%20do%20not%20have%20an%20otel_scope_name%20or%20otel_scope_version%20label%20MUST%20be%20assigned%20an%20instrumentation%20scope%20identifying%20the%20entity%20performing%20the%20translation%20from%20Prometheus%20to%20OpenTelemetry%20(e.g.%20the%20collector%E2%80%99s%20prometheus%20receiver)
-
-	// Check if the name and version present in the labels are already present in the ResourceMetrics.
-	// If it is not present, we should create a new ScopeMetrics.
-	// Otherwise, we should append to the existing ScopeMetrics.
-	for j := 0; j < rm.ScopeMetrics().Len(); j++ {
-		scope := rm.ScopeMetrics().At(j)
-		if scopeName == scope.Scope().Name() && scopeVersion == scope.Scope().Version() {
-			addDatapoints(scope.Metrics().AppendEmpty().SetEmptyGauge().DataPoints(), ls, ts)
-			return
+// newSnappyBlockDecoder returns a decoder of snappy block-format bodies. confighttp's snappy decoder
+// expects the framed format, while Remote-Write senders use the block format. The bodies whose
+// decoded length is above maxDecodedSize are rejected before being decoded, as the decoded length
+// is read from the header of the block and allocated at once.
+func newSnappyBlockDecoder(maxDecodedSize int64) func(body io.ReadCloser) (io.ReadCloser, error) {
+	return func(body io.ReadCloser) (io.ReadCloser, error) {
+		compressed, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	scope := rm.ScopeMetrics().AppendEmpty()
-	scope.Scope().SetName(scopeName)
-	scope.Scope().SetVersion(scopeVersion)
-	m := scope.Metrics().AppendEmpty().SetEmptyGauge()
-	addDatapoints(m.DataPoints(), ls, ts)
-}
-
-func addSummaryDatapoints(_ pmetric.ResourceMetrics, _ labels.Labels, _ writev2.TimeSeries) {
-	// TODO: Implement this function
-}
-
-func addHistogramDatapoints(_ pmetric.ResourceMetrics, _ labels.Labels, _ writev2.TimeSeries) {
-	// TODO: Implement this function
-}
-
-// addDatapoints adds the labels to the datapoints attributes.
-// TODO: We're still not handling the StartTimestamp.
-func addDatapoints(datapoints pmetric.NumberDataPointSlice, ls labels.Labels, ts writev2.TimeSeries) {
-	// Add samples from the timeseries
-	for _, sample := range ts.Samples {
-		dp := datapoints.AppendEmpty()
-
-		// Set timestamp in nanoseconds (Prometheus uses milliseconds)
-		dp.SetTimestamp(pcommon.Timestamp(sample.Timestamp * int64(time.Millisecond)))
-		dp.SetDoubleValue(sample.Value)
-
-		attributes := dp.Attributes()
-		for _, l := range ls {
-			if l.Name == "instance" || l.Name == "job" || // Become resource attributes
-				l.Name == labels.MetricName || // Becomes metric name
-				l.Name == "otel_scope_name" || l.Name == "otel_scope_version" { // Becomes scope name and version
-				continue
-			}
-			attributes.PutStr(l.Name, l.Value)
+		decodedLen, err := snappy.DecodedLen(compressed)
+		if err != nil {
+			return nil, err
+		}
+		if int64(decodedLen) > maxDecodedSize {
+			return nil, fmt.Errorf("decoded body of %d bytes exceeds the maximum of %d bytes", decodedLen, maxDecodedSize)
+		}
+		decoded, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return nil, err
 		}
+		return io.NopCloser(bytes.NewReader(decoded)), nil
 	}
 }
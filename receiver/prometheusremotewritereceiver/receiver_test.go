@@ -6,7 +6,10 @@ package prometheusremotewritereceiver // import "github.com/open-telemetry/opent
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -15,9 +18,13 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	promconfig "github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
 	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/prometheus/prometheus/storage/remote"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -80,11 +87,16 @@ func TestHandlePRWContentTypeNegotiation(t *testing.T) {
 		{
 			name:         "x-protobuf/no proto parameter",
 			contentType:  "application/x-protobuf",
-			expectedCode: http.StatusUnsupportedMediaType,
+			expectedCode: http.StatusNoContent,
 		},
 		{
 			name:         "x-protobuf/v1 proto parameter",
 			contentType:  fmt.Sprintf("application/x-protobuf;proto=%s", promconfig.RemoteWriteProtoMsgV1),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "x-protobuf/unknown proto parameter",
+			contentType:  "application/x-protobuf;proto=io.prometheus.write.v3.Request",
 			expectedCode: http.StatusUnsupportedMediaType,
 		},
 		{
@@ -179,12 +191,15 @@ func TestTranslateV2(t *testing.T) {
 				sm1.Scope().SetName("scope1")
 				sm1.Scope().SetVersion("v1")
 
-				dp1 := sm1.Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+				m1 := sm1.Metrics().AppendEmpty()
+				m1.SetName("test_metric")
+				dps1 := m1.SetEmptyGauge().DataPoints()
+				dp1 := dps1.AppendEmpty()
 				dp1.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
 				dp1.SetDoubleValue(1.0)
 				dp1.Attributes().PutStr("d", "e")
 
-				dp2 := sm1.Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+				dp2 := dps1.AppendEmpty()
 				dp2.SetTimestamp(pcommon.Timestamp(2 * int64(time.Millisecond)))
 				dp2.SetDoubleValue(2.0)
 				dp2.Attributes().PutStr("d", "e")
@@ -193,14 +208,16 @@ func TestTranslateV2(t *testing.T) {
 				sm2.Scope().SetName("scope2")
 				sm2.Scope().SetVersion("v2")
 
-				dp3 := sm2.Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+				m2 := sm2.Metrics().AppendEmpty()
+				m2.SetName("test_metric")
+				dp3 := m2.SetEmptyGauge().DataPoints().AppendEmpty()
 				dp3.SetTimestamp(pcommon.Timestamp(3 * int64(time.Millisecond)))
 				dp3.SetDoubleValue(3.0)
 				dp3.Attributes().PutStr("foo", "bar")
 
 				return expected
 			}(),
-			expectedStats: remote.WriteResponseStats{},
+			expectedStats: remote.WriteResponseStats{Samples: 3},
 		},
 		{
 			name: "missing metric name",
@@ -240,13 +257,16 @@ func TestTranslateV2(t *testing.T) {
 				rmAttributes1.PutStr("service.instance.id", "107cn001")
 
 				sm1 := rm1.ScopeMetrics().AppendEmpty()
-				dp1 := sm1.Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+				m1 := sm1.Metrics().AppendEmpty()
+				m1.SetName("test_metric1")
+				dps1 := m1.SetEmptyGauge().DataPoints()
+				dp1 := dps1.AppendEmpty()
 				dp1.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
 				dp1.SetDoubleValue(1.0)
 				dp1.Attributes().PutStr("d", "e")
 				dp1.Attributes().PutStr("foo", "bar")
 
-				dp2 := sm1.Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+				dp2 := dps1.AppendEmpty()
 				dp2.SetTimestamp(pcommon.Timestamp(2 * int64(time.Millisecond)))
 				dp2.SetDoubleValue(2.0)
 				dp2.Attributes().PutStr("d", "e")
@@ -257,7 +277,9 @@ func TestTranslateV2(t *testing.T) {
 				rmAttributes2.PutStr("service.name", "foo")
 				rmAttributes2.PutStr("service.instance.id", "bar")
 
-				dp3 := rm2.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+				m2 := rm2.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+				m2.SetName("test_metric1")
+				dp3 := m2.SetEmptyGauge().DataPoints().AppendEmpty()
 				dp3.SetTimestamp(pcommon.Timestamp(2 * int64(time.Millisecond)))
 				dp3.SetDoubleValue(2.0)
 				dp3.Attributes().PutStr("d", "e")
@@ -265,7 +287,228 @@ func TestTranslateV2(t *testing.T) {
 
 				return expected
 			}(),
-			expectedStats: remote.WriteResponseStats{},
+			expectedStats: remote.WriteResponseStats{Samples: 3},
+		},
+		{
+			name: "counter with metadata and created timestamp",
+			request: &writev2.Request{
+				Symbols: []string{
+					"",
+					"__name__", "http_requests_total",
+					"job", "api",
+					"method", "GET",
+					"Total number of requests", "{request}",
+					"trace_id", "0102030405060708090a0b0c0d0e0f10",
+					"span_id", "0102030405060708",
+				},
+				Timeseries: []writev2.TimeSeries{
+					{
+						Metadata:         writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_COUNTER, HelpRef: 7, UnitRef: 8},
+						LabelsRefs:       []uint32{1, 2, 3, 4, 5, 6},
+						Samples:          []writev2.Sample{{Value: 10, Timestamp: 2}},
+						Exemplars:        []writev2.Exemplar{{LabelsRefs: []uint32{9, 10, 11, 12}, Value: 1, Timestamp: 2}},
+						CreatedTimestamp: 1,
+					},
+				},
+			},
+			expectedMetrics: func() pmetric.Metrics {
+				expected := pmetric.NewMetrics()
+				rm := expected.ResourceMetrics().AppendEmpty()
+				rm.Resource().Attributes().PutStr("service.name", "api")
+
+				m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+				m.SetName("http_requests_total")
+				m.SetDescription("Total number of requests")
+				m.SetUnit("{request}")
+				sum := m.SetEmptySum()
+				sum.SetIsMonotonic(true)
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				dp := sum.DataPoints().AppendEmpty()
+				dp.SetStartTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+				dp.SetTimestamp(pcommon.Timestamp(2 * int64(time.Millisecond)))
+				dp.SetDoubleValue(10)
+				dp.Attributes().PutStr("method", "GET")
+
+				ex := dp.Exemplars().AppendEmpty()
+				ex.SetTimestamp(pcommon.Timestamp(2 * int64(time.Millisecond)))
+				ex.SetDoubleValue(1)
+				ex.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+				ex.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+
+				return expected
+			}(),
+			expectedStats: remote.WriteResponseStats{Samples: 1, Exemplars: 1},
+		},
+		{
+			name: "classic histogram and summary",
+			request: &writev2.Request{
+				Symbols: []string{
+					"",
+					"__name__", "latency_bucket", "latency_sum", "latency_count",
+					"le", "0.5", "+Inf",
+					"rpc_duration", "rpc_duration_sum", "rpc_duration_count",
+					"quantile", "0.99",
+				},
+				Timeseries: []writev2.TimeSeries{
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM},
+						LabelsRefs: []uint32{1, 2, 5, 6},
+						Samples:    []writev2.Sample{{Value: 3, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM},
+						LabelsRefs: []uint32{1, 2, 5, 7},
+						Samples:    []writev2.Sample{{Value: 5, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM},
+						LabelsRefs: []uint32{1, 3},
+						Samples:    []writev2.Sample{{Value: 4.5, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM},
+						LabelsRefs: []uint32{1, 4},
+						Samples:    []writev2.Sample{{Value: 5, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_SUMMARY},
+						LabelsRefs: []uint32{1, 8, 11, 12},
+						Samples:    []writev2.Sample{{Value: 0.2, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_SUMMARY},
+						LabelsRefs: []uint32{1, 9},
+						Samples:    []writev2.Sample{{Value: 12, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_SUMMARY},
+						LabelsRefs: []uint32{1, 10},
+						Samples:    []writev2.Sample{{Value: 100, Timestamp: 1}},
+					},
+				},
+			},
+			expectedMetrics: func() pmetric.Metrics {
+				expected := pmetric.NewMetrics()
+				sm := expected.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+
+				hm := sm.Metrics().AppendEmpty()
+				hm.SetName("latency")
+				h := hm.SetEmptyHistogram()
+				h.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				hdp := h.DataPoints().AppendEmpty()
+				hdp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+				hdp.SetCount(5)
+				hdp.SetSum(4.5)
+				hdp.ExplicitBounds().FromRaw([]float64{0.5})
+				hdp.BucketCounts().FromRaw([]uint64{3, 2})
+
+				summ := sm.Metrics().AppendEmpty()
+				summ.SetName("rpc_duration")
+				sdp := summ.SetEmptySummary().DataPoints().AppendEmpty()
+				sdp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+				sdp.SetSum(12)
+				sdp.SetCount(100)
+				qv := sdp.QuantileValues().AppendEmpty()
+				qv.SetQuantile(0.99)
+				qv.SetValue(0.2)
+
+				return expected
+			}(),
+			expectedStats: remote.WriteResponseStats{Samples: 7},
+		},
+		{
+			name: "native histogram",
+			request: &writev2.Request{
+				Symbols: []string{"", "__name__", "request_size", "job", "api"},
+				Timeseries: []writev2.TimeSeries{
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_HISTOGRAM},
+						LabelsRefs: []uint32{1, 2, 3, 4},
+						Histograms: []writev2.Histogram{
+							{
+								Count:          &writev2.Histogram_CountInt{CountInt: 6},
+								Sum:            20,
+								Schema:         0,
+								ZeroThreshold:  0.001,
+								ZeroCount:      &writev2.Histogram_ZeroCountInt{ZeroCountInt: 1},
+								PositiveSpans:  []writev2.BucketSpan{{Offset: 1, Length: 2}, {Offset: 1, Length: 1}},
+								PositiveDeltas: []int64{1, 1, -1},
+								Timestamp:      1,
+							},
+						},
+						CreatedTimestamp: 1,
+					},
+				},
+			},
+			expectedMetrics: func() pmetric.Metrics {
+				expected := pmetric.NewMetrics()
+				rm := expected.ResourceMetrics().AppendEmpty()
+				rm.Resource().Attributes().PutStr("service.name", "api")
+
+				m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+				m.SetName("request_size")
+				eh := m.SetEmptyExponentialHistogram()
+				eh.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				dp := eh.DataPoints().AppendEmpty()
+				dp.SetStartTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+				dp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+				dp.SetScale(0)
+				dp.SetCount(6)
+				dp.SetSum(20)
+				dp.SetZeroThreshold(0.001)
+				dp.SetZeroCount(1)
+				dp.Positive().SetOffset(0)
+				dp.Positive().BucketCounts().FromRaw([]uint64{1, 2, 0, 1})
+
+				return expected
+			}(),
+			expectedStats: remote.WriteResponseStats{Histograms: 1},
+		},
+		{
+			name: "target_info becomes resource attributes",
+			request: &writev2.Request{
+				Symbols: []string{"", "__name__", "target_info", "job", "api", "k8s_namespace_name", "prod", "up"},
+				Timeseries: []writev2.TimeSeries{
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_GAUGE},
+						LabelsRefs: []uint32{1, 7, 3, 4},
+						Samples:    []writev2.Sample{{Value: 1, Timestamp: 1}},
+					},
+					{
+						Metadata:   writev2.Metadata{Type: writev2.Metadata_METRIC_TYPE_INFO},
+						LabelsRefs: []uint32{1, 2, 3, 4, 5, 6},
+						Samples:    []writev2.Sample{{Value: 1, Timestamp: 1}},
+					},
+				},
+			},
+			expectedMetrics: func() pmetric.Metrics {
+				expected := pmetric.NewMetrics()
+				rm := expected.ResourceMetrics().AppendEmpty()
+				rm.Resource().Attributes().PutStr("service.name", "api")
+				rm.Resource().Attributes().PutStr("k8s_namespace_name", "prod")
+
+				m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+				m.SetName("up")
+				dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+				dp.SetDoubleValue(1)
+
+				return expected
+			}(),
+			expectedStats: remote.WriteResponseStats{Samples: 1},
+		},
+		{
+			name: "symbol reference out of range",
+			request: &writev2.Request{
+				Symbols: []string{"", "__name__"},
+				Timeseries: []writev2.TimeSeries{
+					{
+						LabelsRefs: []uint32{1, 2},
+						Samples:    []writev2.Sample{{Value: 1, Timestamp: 1}},
+					},
+				},
+			},
+			expectError: "symbol reference 2 out of range",
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -281,3 +524,165 @@ func TestTranslateV2(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateV1(t *testing.T) {
+	prwReceiver := setupMetricsReceiver(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	request := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: 10, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_bucket"}, {Name: "job", Value: "api"}, {Name: "le", Value: "+Inf"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_count"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1}},
+			},
+		},
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "temperature", Type: prompb.MetricMetadata_GAUGE, Help: "Room temperature", Unit: "Cel"},
+		},
+	}
+
+	expected := pmetric.NewMetrics()
+	rm := expected.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "api")
+	sm := rm.ScopeMetrics().AppendEmpty()
+
+	counter := sm.Metrics().AppendEmpty()
+	counter.SetName("http_requests_total")
+	sum := counter.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sdp := sum.DataPoints().AppendEmpty()
+	sdp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+	sdp.SetDoubleValue(10)
+
+	hist := sm.Metrics().AppendEmpty()
+	hist.SetName("latency")
+	h := hist.SetEmptyHistogram()
+	h.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	hdp := h.DataPoints().AppendEmpty()
+	hdp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+	hdp.SetCount(2)
+	hdp.BucketCounts().FromRaw([]uint64{2})
+
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("temperature")
+	gauge.SetDescription("Room temperature")
+	gauge.SetUnit("Cel")
+	gdp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gdp.SetTimestamp(pcommon.Timestamp(1 * int64(time.Millisecond)))
+	gdp.SetDoubleValue(21.5)
+
+	metrics, stats, err := prwReceiver.translateV1(ctx, request)
+	assert.NoError(t, err)
+	assert.NoError(t, pmetrictest.CompareMetrics(expected, metrics))
+	assert.Equal(t, remote.WriteResponseStats{Samples: 4}, stats)
+}
+
+func TestHandlePRWConsumesMetrics(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	factory := NewFactory()
+	prwReceiver, err := factory.CreateMetrics(context.Background(), receivertest.NewNopSettings(metadata.Type), factory.CreateDefaultConfig(), sink)
+	assert.NoError(t, err)
+
+	pBuf := proto.NewBuffer(nil)
+	assert.NoError(t, pBuf.Marshal(writeV2RequestFixture))
+	decoded, err := newSnappyBlockDecoder(defaultMaxRequestBodySize)(io.NopCloser(bytes.NewReader(snappy.Encode(nil, pBuf.Bytes()))))
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write", decoded)
+	req.Header.Set("Content-Type", fmt.Sprintf("application/x-protobuf;proto=%s", promconfig.RemoteWriteProtoMsgV2))
+	w := httptest.NewRecorder()
+	prwReceiver.(*prometheusRemoteWriteReceiver).handlePRW(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "3", resp.Header.Get("X-Prometheus-Remote-Write-Samples-Written"))
+	assert.Equal(t, 3, sink.DataPointCount())
+}
+
+func TestSnappyBlockDecoderRejectsOversizedBody(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	cfg.MaxRequestBodySize = 1024
+	sink := new(consumertest.MetricsSink)
+	prwReceiver, err := factory.CreateMetrics(context.Background(), receivertest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, prwReceiver.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, prwReceiver.Shutdown(context.Background())) })
+	handler := prwReceiver.(*prometheusRemoteWriteReceiver).server.Handler
+
+	// The header of the block declares a decoded length of 2GiB, followed by a few bytes.
+	body := append(binary.AppendUvarint(nil, 1<<31), 0, 1, 2, 3)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(body))
+	req.Header.Set("Content-Type", fmt.Sprintf("application/x-protobuf;proto=%s", promconfig.RemoteWriteProtoMsgV2))
+	req.Header.Set("Content-Encoding", "snappy")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "decoded body of 2147483648 bytes exceeds the maximum of 1024 bytes")
+	assert.Zero(t, sink.DataPointCount())
+
+	// A body under the limit is decoded.
+	pBuf := proto.NewBuffer(nil)
+	require.NoError(t, pBuf.Marshal(writeV2RequestFixture))
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(snappy.Encode(nil, pBuf.Bytes())))
+	req.Header.Set("Content-Type", fmt.Sprintf("application/x-protobuf;proto=%s", promconfig.RemoteWriteProtoMsgV2))
+	req.Header.Set("Content-Encoding", "snappy")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 3, sink.DataPointCount())
+}
+
+func TestTranslateV1StaleClassicHistogram(t *testing.T) {
+	prwReceiver := setupMetricsReceiver(t)
+
+	staleNaN := math.Float64frombits(value.StaleNaN)
+	request := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_bucket"}, {Name: "job", Value: "api"}, {Name: "le", Value: "0.5"}},
+				Samples: []prompb.Sample{{Value: staleNaN, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_bucket"}, {Name: "job", Value: "api"}, {Name: "le", Value: "+Inf"}},
+				Samples: []prompb.Sample{{Value: staleNaN, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_sum"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: staleNaN, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_count"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: staleNaN, Timestamp: 1}},
+			},
+		},
+	}
+
+	metrics, _, err := prwReceiver.translateV1(context.Background(), request)
+	require.NoError(t, err)
+	dps := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints()
+	require.Equal(t, 1, dps.Len())
+	dp := dps.At(0)
+	assert.True(t, dp.Flags().NoRecordedValue())
+	assert.Zero(t, dp.BucketCounts().Len())
+	assert.Zero(t, dp.ExplicitBounds().Len())
+	assert.Zero(t, dp.Count())
+	assert.False(t, dp.HasSum())
+}
@@ -0,0 +1,567 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewritereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusremotewritereceiver"
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	promremote "github.com/prometheus/prometheus/storage/remote"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	targetInfoMetricName = "target_info"
+	scopeNameLabel       = "otel_scope_name"
+	scopeVersionLabel    = "otel_scope_version"
+	traceIDLabel         = "trace_id"
+	spanIDLabel          = "span_id"
+)
+
+// series is a protocol-agnostic view of a remote-write time series. Both Remote-Write 1.0
+// and 2.0 requests are normalized into this representation before being translated to OTLP.
+type series struct {
+	labels           labels.Labels
+	metricType       model.MetricType
+	help             string
+	unit             string
+	createdTimestamp int64
+	samples          []sample
+	histograms       []histogramSample
+	exemplars        []exemplar.Exemplar
+}
+
+type sample struct {
+	timestamp int64
+	value     float64
+}
+
+type histogramSample struct {
+	timestamp int64
+	histogram *histogram.FloatHistogram
+}
+
+type scopeKey struct {
+	resource uint64
+	name     string
+	version  string
+}
+
+type metricKey struct {
+	scope      scopeKey
+	name       string
+	metricType model.MetricType
+}
+
+// pointKey identifies a single classic histogram or summary data point, which Prometheus
+// spreads across several series (_bucket, _sum, _count and quantiles).
+type pointKey struct {
+	metric    metricKey
+	labels    uint64
+	timestamp int64
+}
+
+type classicHistogramPoint struct {
+	dp      pmetric.HistogramDataPoint
+	buckets map[float64]float64
+	count   float64
+	hasSum  bool
+	hasCnt  bool
+}
+
+// metricsBuilder accumulates normalized series into a pmetric.Metrics, merging series that
+// share the same resource, scope and metric identity into a single OTLP metric.
+type metricsBuilder struct {
+	metrics    pmetric.Metrics
+	resources  map[uint64]pmetric.ResourceMetrics
+	scopes     map[scopeKey]pmetric.ScopeMetrics
+	metricsMap map[metricKey]pmetric.Metric
+	histograms map[pointKey]*classicHistogramPoint
+	summaries  map[pointKey]pmetric.SummaryDataPoint
+	// histogramOrder keeps classic histogram points in insertion order so that finalization is deterministic.
+	histogramOrder []pointKey
+	stats          promremote.WriteResponseStats
+}
+
+func newMetricsBuilder() *metricsBuilder {
+	return &metricsBuilder{
+		metrics:    pmetric.NewMetrics(),
+		resources:  make(map[uint64]pmetric.ResourceMetrics),
+		scopes:     make(map[scopeKey]pmetric.ScopeMetrics),
+		metricsMap: make(map[metricKey]pmetric.Metric),
+		histograms: make(map[pointKey]*classicHistogramPoint),
+		summaries:  make(map[pointKey]pmetric.SummaryDataPoint),
+	}
+}
+
+// addSeries translates a single series and appends it to the metrics being built.
+func (mb *metricsBuilder) addSeries(s series) error {
+	ls := s.labels
+	if !ls.Has(labels.MetricName) {
+		return errors.New("missing metric name in labels")
+	} else if duplicateLabel, hasDuplicate := ls.HasDuplicateLabelNames(); hasDuplicate {
+		return fmt.Errorf("duplicate label %q in labels", duplicateLabel)
+	}
+
+	metricName := ls.Get(labels.MetricName)
+	resourceHash, rm := mb.resourceFor(ls)
+
+	// target_info carries resource attributes and is not a metric on its own.
+	// More: https://opentelemetry.io/docs/specs/otel/compatibility/prometheus_and_openmetrics/#resource-attributes-1
+	if metricName == targetInfoMetricName {
+		ls.Range(func(l labels.Label) {
+			if l.Name == "job" || l.Name == "instance" || l.Name == labels.MetricName {
+				return
+			}
+			rm.Resource().Attributes().PutStr(l.Name, l.Value)
+		})
+		return nil
+	}
+
+	sk := scopeKey{resource: resourceHash, name: ls.Get(scopeNameLabel), version: ls.Get(scopeVersionLabel)}
+	if s.metricType == model.MetricTypeUnknown {
+		// Unknown-typed metrics are translated into gauges, as per the compatibility specification.
+		s.metricType = model.MetricTypeGauge
+	}
+
+	switch s.metricType {
+	case model.MetricTypeCounter:
+		m := mb.metricFor(sk, metricName, s)
+		mb.addNumberDatapoints(m.Sum().DataPoints(), ls, s)
+	case model.MetricTypeGauge:
+		m := mb.metricFor(sk, metricName, s)
+		mb.addNumberDatapoints(m.Gauge().DataPoints(), ls, s)
+	case model.MetricTypeInfo, model.MetricTypeStateset:
+		m := mb.metricFor(sk, metricName, s)
+		mb.addNumberDatapoints(m.Sum().DataPoints(), ls, s)
+	case model.MetricTypeHistogram:
+		if len(s.histograms) > 0 {
+			return mb.addNativeHistogram(sk, metricName, ls, s)
+		}
+		mb.addClassicHistogram(sk, metricName, ls, s)
+	case model.MetricTypeSummary:
+		mb.addSummary(sk, metricName, ls, s)
+	default:
+		return fmt.Errorf("unsupported metric type %q for metric %q", s.metricType, metricName)
+	}
+	return nil
+}
+
+// build finalizes the classic histograms and returns the translated metrics and write statistics.
+func (mb *metricsBuilder) build() (pmetric.Metrics, promremote.WriteResponseStats) {
+	for _, key := range mb.histogramOrder {
+		mb.histograms[key].finalize()
+	}
+	return mb.metrics, mb.stats
+}
+
+// resourceFor returns the ResourceMetrics identified by the job and instance labels, creating it if needed.
+func (mb *metricsBuilder) resourceFor(ls labels.Labels) (uint64, pmetric.ResourceMetrics) {
+	job, instance := ls.Get("job"), ls.Get("instance")
+	hash := xxhash.Sum64String(job + string([]byte{'\xff'}) + instance)
+	if rm, ok := mb.resources[hash]; ok {
+		return hash, rm
+	}
+	rm := mb.metrics.ResourceMetrics().AppendEmpty()
+	parseJobAndInstance(rm.Resource().Attributes(), job, instance)
+	mb.resources[hash] = rm
+	return hash, rm
+}
+
+func (mb *metricsBuilder) scopeFor(sk scopeKey) pmetric.ScopeMetrics {
+	if sm, ok := mb.scopes[sk]; ok {
+		return sm
+	}
+	// TODO: If the scope version or scope name is empty, get the information from the collector build tags.
+	// More: https://opentelemetry.io/docs/specs/otel/compatibility/prometheus_and_openmetrics/#instrumentation-scope-1
+	sm := mb.resources[sk.resource].ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(sk.name)
+	sm.Scope().SetVersion(sk.version)
+	mb.scopes[sk] = sm
+	return sm
+}
+
+// metricFor returns the metric identified by scope, name and type, creating it if needed.
+// In OTel name+type+unit is the unique identifier of a metric, so series sharing them are
+// translated into data points of the same metric.
+func (mb *metricsBuilder) metricFor(sk scopeKey, name string, s series) pmetric.Metric {
+	key := metricKey{scope: sk, name: name, metricType: s.metricType}
+	if m, ok := mb.metricsMap[key]; ok {
+		if m.Description() == "" {
+			m.SetDescription(s.help)
+		}
+		if m.Unit() == "" {
+			m.SetUnit(s.unit)
+		}
+		return m
+	}
+
+	m := mb.scopeFor(sk).Metrics().AppendEmpty()
+	m.SetName(name)
+	m.SetDescription(s.help)
+	m.SetUnit(s.unit)
+	switch s.metricType {
+	case model.MetricTypeCounter:
+		sum := m.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	case model.MetricTypeInfo, model.MetricTypeStateset:
+		sum := m.SetEmptySum()
+		sum.SetIsMonotonic(false)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	case model.MetricTypeHistogram:
+		if len(s.histograms) > 0 && s.histograms[0].histogram.UsesCustomBuckets() {
+			m.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		} else if len(s.histograms) > 0 {
+			m.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		} else {
+			m.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		}
+	case model.MetricTypeSummary:
+		m.SetEmptySummary()
+	default:
+		m.SetEmptyGauge()
+	}
+	mb.metricsMap[key] = m
+	return m
+}
+
+func (mb *metricsBuilder) addNumberDatapoints(datapoints pmetric.NumberDataPointSlice, ls labels.Labels, s series) {
+	var last pmetric.NumberDataPoint
+	for i, smpl := range s.samples {
+		dp := datapoints.AppendEmpty()
+		dp.SetTimestamp(millisToTimestamp(smpl.timestamp))
+		dp.SetStartTimestamp(millisToTimestamp(s.createdTimestamp))
+		if value.IsStaleNaN(smpl.value) {
+			dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		} else {
+			dp.SetDoubleValue(smpl.value)
+		}
+		putAttributes(dp.Attributes(), ls)
+		mb.stats.Samples++
+		if i == len(s.samples)-1 {
+			last = dp
+		}
+	}
+	if len(s.samples) > 0 {
+		mb.addExemplars(last.Exemplars(), s.exemplars)
+	}
+}
+
+// addClassicHistogram merges the _bucket, _sum and _count series of a classic histogram
+// into a single OTLP histogram data point per label set and timestamp.
+func (mb *metricsBuilder) addClassicHistogram(sk scopeKey, metricName string, ls labels.Labels, s series) {
+	familyName, suffix := splitHistogramSuffix(metricName, "_bucket", "_sum", "_count")
+	m := mb.metricFor(sk, familyName, s)
+	attrHash := hashWithout(ls, model.BucketLabel)
+
+	var bound float64
+	if suffix == "_bucket" {
+		var err error
+		if bound, err = strconv.ParseFloat(ls.Get(model.BucketLabel), 64); err != nil {
+			bound = math.Inf(1)
+		}
+	}
+
+	for i, smpl := range s.samples {
+		key := pointKey{metric: metricKey{scope: sk, name: familyName, metricType: s.metricType}, labels: attrHash, timestamp: smpl.timestamp}
+		hp, ok := mb.histograms[key]
+		if !ok {
+			dp := m.Histogram().DataPoints().AppendEmpty()
+			dp.SetTimestamp(millisToTimestamp(smpl.timestamp))
+			dp.SetStartTimestamp(millisToTimestamp(s.createdTimestamp))
+			putAttributes(dp.Attributes(), ls, model.BucketLabel)
+			hp = &classicHistogramPoint{dp: dp, buckets: make(map[float64]float64)}
+			mb.histograms[key] = hp
+			mb.histogramOrder = append(mb.histogramOrder, key)
+		}
+		if value.IsStaleNaN(smpl.value) {
+			hp.dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		}
+		switch suffix {
+		case "_bucket":
+			hp.buckets[bound] = smpl.value
+		case "_sum":
+			hp.dp.SetSum(smpl.value)
+			hp.hasSum = true
+		case "_count":
+			hp.count = smpl.value
+			hp.hasCnt = true
+		}
+		mb.stats.Samples++
+		if i == len(s.samples)-1 {
+			mb.addExemplars(hp.dp.Exemplars(), s.exemplars)
+		}
+	}
+}
+
+// finalize converts the cumulative Prometheus buckets into OTLP explicit bounds and bucket counts.
+func (hp *classicHistogramPoint) finalize() {
+	if hp.dp.Flags().NoRecordedValue() {
+		// A stale marker has no value, its NaN buckets, sum and count aren't translated.
+		hp.dp.RemoveSum()
+		return
+	}
+
+	bounds := make([]float64, 0, len(hp.buckets))
+	for bound := range hp.buckets {
+		bounds = append(bounds, bound)
+	}
+	slices.Sort(bounds)
+
+	explicitBounds := hp.dp.ExplicitBounds()
+	bucketCounts := hp.dp.BucketCounts()
+	var previous float64
+	for _, bound := range bounds {
+		cumulative := hp.buckets[bound]
+		if !math.IsInf(bound, 1) {
+			explicitBounds.Append(bound)
+		}
+		bucketCounts.Append(uint64(math.Max(0, math.Round(cumulative-previous))))
+		previous = cumulative
+	}
+	// Prometheus always exposes a +Inf bucket, but it may be missing if the sender dropped it.
+	if len(bounds) > 0 && !math.IsInf(bounds[len(bounds)-1], 1) {
+		bucketCounts.Append(uint64(math.Max(0, math.Round(hp.count-previous))))
+	}
+
+	if hp.hasCnt {
+		hp.dp.SetCount(uint64(math.Round(hp.count)))
+	} else if len(bounds) > 0 {
+		hp.dp.SetCount(uint64(math.Round(hp.buckets[bounds[len(bounds)-1]])))
+	}
+	if !hp.hasSum {
+		hp.dp.RemoveSum()
+	}
+}
+
+// addSummary merges the quantile, _sum and _count series of a summary into a single OTLP
+// summary data point per label set and timestamp.
+func (mb *metricsBuilder) addSummary(sk scopeKey, metricName string, ls labels.Labels, s series) {
+	familyName, suffix := splitHistogramSuffix(metricName, "_sum", "_count")
+	if suffix == "" && !ls.Has(model.QuantileLabel) {
+		// A series without quantile nor a known suffix cannot be part of a summary.
+		familyName = metricName
+	}
+	m := mb.metricFor(sk, familyName, s)
+	attrHash := hashWithout(ls, model.QuantileLabel)
+
+	for _, smpl := range s.samples {
+		key := pointKey{metric: metricKey{scope: sk, name: familyName, metricType: s.metricType}, labels: attrHash, timestamp: smpl.timestamp}
+		dp, ok := mb.summaries[key]
+		if !ok {
+			dp = m.Summary().DataPoints().AppendEmpty()
+			dp.SetTimestamp(millisToTimestamp(smpl.timestamp))
+			dp.SetStartTimestamp(millisToTimestamp(s.createdTimestamp))
+			putAttributes(dp.Attributes(), ls, model.QuantileLabel)
+			mb.summaries[key] = dp
+		}
+		if value.IsStaleNaN(smpl.value) {
+			dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		}
+		switch suffix {
+		case "_sum":
+			dp.SetSum(smpl.value)
+		case "_count":
+			dp.SetCount(uint64(math.Round(smpl.value)))
+		default:
+			quantile, err := strconv.ParseFloat(ls.Get(model.QuantileLabel), 64)
+			if err != nil {
+				continue
+			}
+			qv := dp.QuantileValues().AppendEmpty()
+			qv.SetQuantile(quantile)
+			qv.SetValue(smpl.value)
+		}
+		mb.stats.Samples++
+	}
+}
+
+// addNativeHistogram translates Prometheus native histograms into OTLP exponential histograms.
+// Native histograms with custom buckets (NHCB) are translated into explicit bucket histograms.
+func (mb *metricsBuilder) addNativeHistogram(sk scopeKey, metricName string, ls labels.Labels, s series) error {
+	m := mb.metricFor(sk, metricName, s)
+	var errs error
+	for i, hs := range s.histograms {
+		h := hs.histogram
+		if err := h.Validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid native histogram for metric %q: %w", metricName, err))
+			continue
+		}
+
+		var exemplars pmetric.ExemplarSlice
+		switch m.Type() {
+		case pmetric.MetricTypeExponentialHistogram:
+			if h.UsesCustomBuckets() {
+				errs = errors.Join(errs, fmt.Errorf("mixed custom and exponential buckets for metric %q", metricName))
+				continue
+			}
+			dp := m.ExponentialHistogram().DataPoints().AppendEmpty()
+			dp.SetTimestamp(millisToTimestamp(hs.timestamp))
+			dp.SetStartTimestamp(millisToTimestamp(s.createdTimestamp))
+			putAttributes(dp.Attributes(), ls)
+			convertExponentialHistogram(h, dp)
+			exemplars = dp.Exemplars()
+		case pmetric.MetricTypeHistogram:
+			if !h.UsesCustomBuckets() {
+				errs = errors.Join(errs, fmt.Errorf("mixed custom and exponential buckets for metric %q", metricName))
+				continue
+			}
+			dp := m.Histogram().DataPoints().AppendEmpty()
+			dp.SetTimestamp(millisToTimestamp(hs.timestamp))
+			dp.SetStartTimestamp(millisToTimestamp(s.createdTimestamp))
+			putAttributes(dp.Attributes(), ls)
+			convertCustomBucketsHistogram(h, dp)
+			exemplars = dp.Exemplars()
+		default:
+			errs = errors.Join(errs, fmt.Errorf("native histogram for metric %q collides with a classic histogram", metricName))
+			continue
+		}
+
+		mb.stats.Histograms++
+		if i == len(s.histograms)-1 {
+			mb.addExemplars(exemplars, s.exemplars)
+		}
+	}
+	return errs
+}
+
+func (mb *metricsBuilder) addExemplars(dest pmetric.ExemplarSlice, exemplars []exemplar.Exemplar) {
+	for _, e := range exemplars {
+		ex := dest.AppendEmpty()
+		ex.SetDoubleValue(e.Value)
+		if e.HasTs {
+			ex.SetTimestamp(millisToTimestamp(e.Ts))
+		}
+		e.Labels.Range(func(l labels.Label) {
+			switch l.Name {
+			case traceIDLabel:
+				var traceID pcommon.TraceID
+				if b, err := hex.DecodeString(l.Value); err == nil && len(b) == len(traceID) {
+					copy(traceID[:], b)
+					ex.SetTraceID(traceID)
+					return
+				}
+			case spanIDLabel:
+				var spanID pcommon.SpanID
+				if b, err := hex.DecodeString(l.Value); err == nil && len(b) == len(spanID) {
+					copy(spanID[:], b)
+					ex.SetSpanID(spanID)
+					return
+				}
+			}
+			ex.FilteredAttributes().PutStr(l.Name, l.Value)
+		})
+		mb.stats.Exemplars++
+	}
+}
+
+// convertExponentialHistogram fills an OTLP exponential histogram data point from a native histogram.
+// Prometheus bucket index i covers (base^(i-1), base^i], while OTLP bucket index k covers
+// (base^k, base^(k+1)], hence the off-by-one between both indexes.
+func convertExponentialHistogram(h *histogram.FloatHistogram, dp pmetric.ExponentialHistogramDataPoint) {
+	dp.SetScale(h.Schema)
+	dp.SetZeroThreshold(h.ZeroThreshold)
+	dp.SetZeroCount(uint64(math.Round(h.ZeroCount)))
+	if value.IsStaleNaN(h.Sum) {
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		return
+	}
+	dp.SetSum(h.Sum)
+	dp.SetCount(uint64(math.Round(h.Count)))
+	convertExponentialBuckets(h.PositiveBucketIterator(), dp.Positive())
+	convertExponentialBuckets(h.NegativeBucketIterator(), dp.Negative())
+}
+
+func convertExponentialBuckets(it histogram.BucketIterator[float64], dest pmetric.ExponentialHistogramDataPointBuckets) {
+	first := true
+	var next int32
+	for it.Next() {
+		b := it.At()
+		if first {
+			dest.SetOffset(b.Index - 1)
+			next = b.Index
+			first = false
+		}
+		for ; next < b.Index; next++ {
+			dest.BucketCounts().Append(0)
+		}
+		dest.BucketCounts().Append(uint64(math.Round(b.Count)))
+		next = b.Index + 1
+	}
+}
+
+// convertCustomBucketsHistogram fills an OTLP explicit bucket histogram data point from a
+// native histogram with custom buckets, whose bucket boundaries are stored in CustomValues.
+func convertCustomBucketsHistogram(h *histogram.FloatHistogram, dp pmetric.HistogramDataPoint) {
+	if value.IsStaleNaN(h.Sum) {
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		return
+	}
+	dp.SetSum(h.Sum)
+	dp.SetCount(uint64(math.Round(h.Count)))
+	dp.ExplicitBounds().FromRaw(h.CustomValues)
+
+	counts := make([]uint64, len(h.CustomValues)+1)
+	it := h.PositiveBucketIterator()
+	for it.Next() {
+		b := it.At()
+		if int(b.Index) < len(counts) {
+			counts[b.Index] = uint64(math.Round(b.Count))
+		}
+	}
+	dp.BucketCounts().FromRaw(counts)
+}
+
+// putAttributes adds the labels to the datapoints attributes, skipping the ones that are translated
+// into other parts of the OTLP data model as well as the given extra labels.
+func putAttributes(attributes pcommon.Map, ls labels.Labels, skip ...string) {
+	ls.Range(func(l labels.Label) {
+		if l.Name == "instance" || l.Name == "job" || // Become resource attributes
+			l.Name == labels.MetricName || // Becomes metric name
+			l.Name == scopeNameLabel || l.Name == scopeVersionLabel || // Becomes scope name and version
+			slices.Contains(skip, l.Name) {
+			return
+		}
+		attributes.PutStr(l.Name, l.Value)
+	})
+}
+
+// hashWithout hashes the labels identifying a data point, ignoring the metric name and the given label.
+func hashWithout(ls labels.Labels, name string) uint64 {
+	h, _ := ls.HashWithoutLabels(nil, labels.MetricName, name)
+	return h
+}
+
+// splitHistogramSuffix returns the metric family name and the suffix that was trimmed, if any.
+func splitHistogramSuffix(metricName string, suffixes ...string) (string, string) {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(metricName, suffix) {
+			return strings.TrimSuffix(metricName, suffix), suffix
+		}
+	}
+	return metricName, ""
+}
+
+// millisToTimestamp converts Prometheus milliseconds timestamps into OTLP nanoseconds timestamps.
+// A zero input is kept as zero, meaning the timestamp is unknown.
+func millisToTimestamp(ms int64) pcommon.Timestamp {
+	if ms == 0 {
+		return 0
+	}
+	return pcommon.Timestamp(ms * int64(time.Millisecond))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cast"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/confmap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
@@ -81,12 +82,20 @@ var _ confmap.Unmarshaler = (*Config)(nil)
 // Config defines configuration for receiver_creator.
 type Config struct {
 	receiverTemplates map[string]receiverTemplate
+	// recipes are the discovery recipes, creating receivers for endpoints of any type.
+	recipes map[string]recipe
 	// WatchObservers are the extensions to listen to endpoints from.
 	WatchObservers []component.ID `mapstructure:"watch_observers"`
 	// ResourceAttributes is a map of default resource attributes to add to each resource
 	// object received by this receiver from dynamically created receivers.
 	ResourceAttributes resourceAttributes `mapstructure:"resource_attributes"`
 	Discovery          DiscoveryConfig    `mapstructure:"discovery"`
+	// DefaultRecipes are the names of the built-in recipes to enable, creating receivers
+	// for the services listening on well-known ports or running well-known images.
+	DefaultRecipes []string `mapstructure:"default_recipes"`
+	// Inspection configures an optional HTTP server listing the receiver instances
+	// currently started, and the rule or recipe that created each of them.
+	Inspection *confighttp.ServerConfig `mapstructure:"inspection"`
 }
 
 type DiscoveryConfig struct {
@@ -106,7 +115,7 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 
 	for endpointType := range cfg.ResourceAttributes {
 		switch endpointType {
-		case observer.ContainerType, observer.K8sServiceType, observer.K8sIngressType, observer.HostPortType, observer.K8sNodeType, observer.PodType, observer.PortType, observer.PodContainerType:
+		case observer.ContainerType, observer.K8sServiceType, observer.K8sIngressType, observer.HostPortType, observer.K8sNodeType, observer.PodType, observer.PortType, observer.PodContainerType, observer.SDTargetType:
 		default:
 			return fmt.Errorf("resource attributes for unsupported endpoint type %q", endpointType)
 		}
@@ -147,5 +156,13 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 		cfg.receiverTemplates[subreceiverKey] = subreceiver
 	}
 
+	recipes, err := unmarshalRecipes(componentParser)
+	if err != nil {
+		return err
+	}
+	if cfg.recipes, err = addDefaultRecipes(recipes, cfg.DefaultRecipes); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -150,6 +150,15 @@ var k8sNodeEndpoint = observer.Endpoint{
 	},
 }
 
+var sdTargetEndpoint = observer.Endpoint{
+	ID:     "target/6a8e4f3d2b1c0a9e",
+	Target: "10.0.0.1:9100",
+	Details: &observer.SDTarget{
+		Labels: map[string]string{"job": "node"},
+		Source: "/etc/targets/node.json",
+	},
+}
+
 var unsupportedEndpoint = observer.Endpoint{
 	ID:      "endpoint-1",
 	Target:  "localhost:1234",
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package receivercreator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+func TestInspectionHandler(t *testing.T) {
+	obs := &observerHandler{
+		instancesByEndpointID: map[observer.EndpointID][]receiverInstance{
+			"port-2": {
+				{Receiver: "redis/recipe", Endpoint: "1.2.3.4:6379", EndpointID: "port-2", Source: recipesSource, Recipe: "redis", Rule: `labels["app"] == "redis"`},
+			},
+			"port-1": {
+				{Receiver: "with_endpoint", Endpoint: "localhost:1234", EndpointID: "port-1", Source: receiversSource, Rule: `type == "port"`},
+			},
+		},
+	}
+	handler := &inspectionHandler{logger: zap.NewNop(), observerHandler: obs}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"receivers": [
+		{"receiver": "with_endpoint", "endpoint": "localhost:1234", "endpoint_id": "port-1", "source": "receivers", "rule": "type == \"port\""},
+		{"receiver": "redis/recipe", "endpoint": "1.2.3.4:6379", "endpoint_id": "port-2", "source": "recipes", "recipe": "redis", "rule": "labels[\"app\"] == \"redis\""}
+	]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestInspectionHandlerEmpty(t *testing.T) {
+	handler := &inspectionHandler{logger: zap.NewNop(), observerHandler: &observerHandler{}}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"receivers": []}`, rec.Body.String())
+}
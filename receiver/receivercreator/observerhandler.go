@@ -5,6 +5,7 @@ package receivercreator // import "github.com/open-telemetry/opentelemetry-colle
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"go.opentelemetry.io/collector/component"
@@ -40,8 +41,30 @@ type observerHandler struct {
 	nextTracesConsumer consumer.Traces
 	// runner starts and stops receiver instances.
 	runner runner
+	// instancesByEndpointID describes the receiver instances started for each endpoint ID.
+	instancesByEndpointID map[observer.EndpointID][]receiverInstance
 }
 
+// receiverInstance describes a receiver instance and what created it.
+type receiverInstance struct {
+	// Receiver is the id of the receiver template of the instance.
+	Receiver   string `json:"receiver"`
+	Endpoint   string `json:"endpoint"`
+	EndpointID string `json:"endpoint_id"`
+	// Source is what created the instance: "receivers", "recipes" or "hints".
+	Source string `json:"source"`
+	// Recipe is the name of the recipe that created the instance, if any.
+	Recipe string `json:"recipe,omitempty"`
+	// Rule is the rule matched by the endpoint, if any.
+	Rule string `json:"rule,omitempty"`
+}
+
+const (
+	receiversSource = "receivers"
+	recipesSource   = "recipes"
+	hintsSource     = "hints"
+)
+
 // shutdown all receivers started at runtime.
 func (obs *observerHandler) shutdown() error {
 	obs.Lock()
@@ -90,11 +113,14 @@ func (obs *observerHandler) OnAdd(added []observer.Endpoint) {
 			}
 			if subreceiverTemplate != nil {
 				obs.params.TelemetrySettings.Logger.Debug("adding K8s hinted receiver", zap.Any("subreceiver", subreceiverTemplate))
-				obs.startReceiver(*subreceiverTemplate, env, e)
+				obs.startReceiver(*subreceiverTemplate, env, e, receiverInstance{Source: hintsSource})
 				continue
 			}
 		}
 
+		// createdBy tracks which source started a receiver of each type for the endpoint,
+		// as receivers explicitly configured take precedence over the ones of recipes.
+		createdBy := map[component.Type]string{}
 		for _, template := range obs.config.receiverTemplates {
 			if matches, err := template.rule.eval(env); err != nil {
 				obs.params.TelemetrySettings.Logger.Error("failed matching rule", zap.String("rule", template.Rule), zap.Error(err))
@@ -102,11 +128,63 @@ func (obs *observerHandler) OnAdd(added []observer.Endpoint) {
 			} else if !matches {
 				continue
 			}
-			obs.startReceiver(template, env, e)
+			if obs.startReceiver(template, env, e, receiverInstance{Source: receiversSource, Rule: template.Rule}) {
+				createdBy[template.id.Type()] = receiversSource
+			}
 		}
+
+		obs.startRecipeReceivers(env, e, createdBy)
 	}
 }
 
+// startRecipeReceivers starts the receivers of the recipes matching the endpoint. When several
+// sources create a receiver of the same type, only the one with the highest precedence is started.
+func (obs *observerHandler) startRecipeReceivers(env observer.EndpointEnv, e observer.Endpoint, createdBy map[component.Type]string) {
+	if len(obs.config.recipes) == 0 {
+		return
+	}
+
+	env = recipeEnv(env)
+	recipes, err := matchingRecipes(obs.config.recipes, env)
+	if err != nil {
+		obs.params.TelemetrySettings.Logger.Error("failed matching recipes", zap.String("endpoint_id", string(e.ID)), zap.Error(err))
+	}
+	for _, r := range recipes {
+		source := recipesSource + "/" + r.name
+		for _, template := range r.receivers {
+			if owner, ok := createdBy[template.id.Type()]; ok && owner != source {
+				obs.params.TelemetrySettings.Logger.Debug("skipping recipe receiver conflicting with an existing one",
+					zap.String("recipe", r.name),
+					zap.String("receiver", template.id.String()),
+					zap.String("created_by", owner),
+					zap.String("endpoint_id", string(e.ID)))
+				continue
+			}
+			if obs.startReceiver(template, env, e, receiverInstance{Source: recipesSource, Recipe: r.name, Rule: r.Rule}) {
+				createdBy[template.id.Type()] = source
+			}
+		}
+	}
+}
+
+// instances returns the receiver instances currently started, ordered by endpoint ID and receiver.
+func (obs *observerHandler) instances() []receiverInstance {
+	obs.Lock()
+	defer obs.Unlock()
+
+	out := []receiverInstance{}
+	for _, instances := range obs.instancesByEndpointID {
+		out = append(out, instances...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].EndpointID != out[j].EndpointID {
+			return out[i].EndpointID < out[j].EndpointID
+		}
+		return out[i].Receiver < out[j].Receiver
+	})
+	return out
+}
+
 // OnRemove responds to endpoint removal notifications.
 func (obs *observerHandler) OnRemove(removed []observer.Endpoint) {
 	obs.Lock()
@@ -132,6 +210,7 @@ func (obs *observerHandler) OnRemove(removed []observer.Endpoint) {
 			}
 		}
 		obs.receiversByEndpointID.RemoveAll(e.ID)
+		delete(obs.instancesByEndpointID, e.ID)
 	}
 }
 
@@ -142,11 +221,12 @@ func (obs *observerHandler) OnChange(changed []observer.Endpoint) {
 	obs.OnAdd(changed)
 }
 
-func (obs *observerHandler) startReceiver(template receiverTemplate, env observer.EndpointEnv, e observer.Endpoint) {
+// startReceiver starts the receiver of the template for the endpoint, and returns whether it was started.
+func (obs *observerHandler) startReceiver(template receiverTemplate, env observer.EndpointEnv, e observer.Endpoint, instance receiverInstance) bool {
 	resolvedConfig, err := expandConfig(template.config, env)
 	if err != nil {
 		obs.params.TelemetrySettings.Logger.Error("unable to resolve template config", zap.String("receiver", template.id.String()), zap.Error(err))
-		return
+		return false
 	}
 
 	discoveredCfg := userConfigMap{}
@@ -162,7 +242,7 @@ func (obs *observerHandler) startReceiver(template receiverTemplate, env observe
 	discoveredConfig, err := expandConfig(discoveredCfg, env)
 	if err != nil {
 		obs.params.TelemetrySettings.Logger.Error("unable to resolve discovered config", zap.String("receiver", template.id.String()), zap.Error(err))
-		return
+		return false
 	}
 
 	resAttrs := map[string]string{}
@@ -188,14 +268,14 @@ func (obs *observerHandler) startReceiver(template receiverTemplate, env observe
 		obs.nextTracesConsumer,
 	); err != nil {
 		obs.params.TelemetrySettings.Logger.Error("failed creating resource enhancer", zap.String("receiver", template.id.String()), zap.Error(err))
-		return
+		return false
 	}
 
 	filterConsumerSignals(consumer, template.signals)
 
 	// short-circuit if no consumers are set
 	if consumer.metrics == nil && consumer.logs == nil && consumer.traces == nil {
-		return
+		return false
 	}
 
 	obs.params.TelemetrySettings.Logger.Info("starting receiver",
@@ -215,9 +295,18 @@ func (obs *observerHandler) startReceiver(template receiverTemplate, env observe
 		consumer,
 	); err != nil {
 		obs.params.TelemetrySettings.Logger.Error("failed to start receiver", zap.String("receiver", template.id.String()), zap.Error(err))
-		return
+		return false
 	}
 	obs.receiversByEndpointID.Put(e.ID, receiver)
+
+	instance.Receiver = template.id.String()
+	instance.Endpoint = e.Target
+	instance.EndpointID = string(e.ID)
+	if obs.instancesByEndpointID == nil {
+		obs.instancesByEndpointID = map[observer.EndpointID][]receiverInstance{}
+	}
+	obs.instancesByEndpointID[e.ID] = append(obs.instancesByEndpointID[e.ID], instance)
+	return true
 }
 
 func filterConsumerSignals(consumer *enhancingConsumer, signals receiverSignals) {
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/otelcol"
@@ -183,6 +184,107 @@ func TestOnAddForMetricsWithHints(t *testing.T) {
 	}
 }
 
+func TestOnAddForRecipes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.receiverTemplates = map[string]receiverTemplate{
+		"without_endpoint/template": {
+			receiverConfig: receiverConfig{
+				id:         component.MustNewIDWithName("without_endpoint", "template"),
+				config:     userConfigMap{"int_field": 1},
+				endpointID: portEndpoint.ID,
+			},
+			rule:               portRule,
+			Rule:               `type == "port"`,
+			ResourceAttributes: map[string]any{},
+			signals:            receiverSignals{metrics: true, logs: true, traces: true},
+		},
+	}
+	require.NoError(t, cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		recipesConfigKey: map[string]any{
+			"by_port": map[string]any{
+				"ports":    []any{1234},
+				"priority": 1,
+				"receivers": map[string]any{
+					"with_endpoint": map[string]any{"config": map[string]any{"int_field": 1}},
+				},
+			},
+			"by_label": map[string]any{
+				"rule":     `labels["app"] == "redis"`,
+				"priority": 2,
+				"receivers": map[string]any{
+					"with_endpoint":       map[string]any{"config": map[string]any{"int_field": "`labels[\"region\"] == \"west-1\" ? 2 : 0`"}},
+					"with_endpoint/other": map[string]any{"config": map[string]any{"int_field": 3}},
+				},
+			},
+			"conflicting": map[string]any{
+				"ports": []any{1234},
+				"receivers": map[string]any{
+					"without_endpoint": map[string]any{"config": map[string]any{"int_field": 4}},
+				},
+			},
+			"not_matching": map[string]any{
+				"images": []any{"redis"},
+				"receivers": map[string]any{
+					"without_endpoint": map[string]any{"config": map[string]any{"int_field": 5}},
+				},
+			},
+		},
+	})))
+
+	handler, mr := newObserverHandler(t, cfg, nil, consumertest.NewNop(), nil)
+	handler.OnAdd([]observer.Endpoint{portEndpoint})
+	require.NoError(t, mr.lastError)
+
+	// The template takes precedence over the conflicting recipe, and the by_label recipe
+	// takes precedence over the by_port recipe, both creating a with_endpoint receiver.
+	assert.Equal(t, 3, handler.receiversByEndpointID.Size())
+	assert.Equal(t, []receiverInstance{
+		{Receiver: "with_endpoint/by_label", Endpoint: "localhost:1234", EndpointID: "port-1", Source: recipesSource, Recipe: "by_label", Rule: `labels["app"] == "redis"`},
+		{Receiver: "with_endpoint/by_label/other", Endpoint: "localhost:1234", EndpointID: "port-1", Source: recipesSource, Recipe: "by_label", Rule: `labels["app"] == "redis"`},
+		{Receiver: "without_endpoint/template", Endpoint: "localhost:1234", EndpointID: "port-1", Source: receiversSource, Rule: `type == "port"`},
+	}, handler.instances())
+
+	handler.OnRemove([]observer.Endpoint{portEndpoint})
+	assert.Equal(t, 0, handler.receiversByEndpointID.Size())
+	assert.Empty(t, handler.instances())
+}
+
+func TestOnAddForRecipesFailedReceiver(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.receiverTemplates = map[string]receiverTemplate{
+		"with_endpoint/template": {
+			receiverConfig: receiverConfig{
+				id:         component.MustNewIDWithName("with_endpoint", "template"),
+				config:     userConfigMap{"invalid_field": 1},
+				endpointID: portEndpoint.ID,
+			},
+			rule:               portRule,
+			Rule:               `type == "port"`,
+			ResourceAttributes: map[string]any{},
+			signals:            receiverSignals{metrics: true, logs: true, traces: true},
+		},
+	}
+	require.NoError(t, cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		recipesConfigKey: map[string]any{
+			"by_port": map[string]any{
+				"ports": []any{1234},
+				"receivers": map[string]any{
+					"with_endpoint": map[string]any{"config": map[string]any{"int_field": 1}},
+				},
+			},
+		},
+	})))
+
+	handler, _ := newObserverHandler(t, cfg, nil, consumertest.NewNop(), nil)
+	handler.OnAdd([]observer.Endpoint{portEndpoint})
+
+	// The receiver of the template failed to start, so it doesn't prevent the one of the recipe from starting.
+	assert.Equal(t, 1, handler.receiversByEndpointID.Size())
+	assert.Equal(t, []receiverInstance{
+		{Receiver: "with_endpoint/by_port", Endpoint: "localhost:1234", EndpointID: "port-1", Source: recipesSource, Recipe: "by_port"},
+	}, handler.instances())
+}
+
 func TestOnAddForLogs(t *testing.T) {
 	for _, test := range []struct {
 		name                   string
@@ -7,8 +7,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
 
@@ -26,6 +28,7 @@ type receiverCreator struct {
 	nextTracesConsumer  consumer.Traces
 	observerHandler     *observerHandler
 	observables         []observer.Observable
+	inspectionServer    *http.Server
 }
 
 func newReceiverCreator(params receiver.Settings, cfg *Config) receiver.Metrics {
@@ -42,7 +45,7 @@ type host interface {
 }
 
 // Start receiver_creator.
-func (rc *receiverCreator) Start(_ context.Context, h component.Host) error {
+func (rc *receiverCreator) Start(ctx context.Context, h component.Host) error {
 	rcHost, ok := h.(host)
 	if !ok {
 		return errors.New("the receivercreator is not compatible with the provided component.host")
@@ -86,6 +89,12 @@ func (rc *receiverCreator) Start(_ context.Context, h component.Host) error {
 		rc.params.Logger.Warn("no observers were configured and no subreceivers will be started. receiver_creator will be disabled")
 	}
 
+	if rc.cfg.Inspection != nil {
+		if err := rc.startInspectionServer(ctx, h); err != nil {
+			return err
+		}
+	}
+
 	// Start all configured watchers.
 	for _, observable := range observers {
 		rc.observables = append(rc.observables, observable)
@@ -95,13 +104,38 @@ func (rc *receiverCreator) Start(_ context.Context, h component.Host) error {
 	return nil
 }
 
+// startInspectionServer starts the HTTP server listing the receiver instances.
+func (rc *receiverCreator) startInspectionServer(ctx context.Context, host component.Host) error {
+	handler := &inspectionHandler{logger: rc.params.Logger, observerHandler: rc.observerHandler}
+	server, err := rc.cfg.Inspection.ToServer(ctx, host, rc.params.TelemetrySettings, handler)
+	if err != nil {
+		return fmt.Errorf("failed to create inspection server: %w", err)
+	}
+	listener, err := rc.cfg.Inspection.ToListener(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind inspection server to address %q: %w", rc.cfg.Inspection.Endpoint, err)
+	}
+	rc.inspectionServer = server
+
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) && err != nil {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(err))
+		}
+	}()
+	return nil
+}
+
 // Shutdown stops the receiver_creator and all its receivers started at runtime.
-func (rc *receiverCreator) Shutdown(context.Context) error {
+func (rc *receiverCreator) Shutdown(ctx context.Context) error {
 	for _, observable := range rc.observables {
 		observable.Unsubscribe(rc.observerHandler)
 	}
+	var err error
+	if rc.inspectionServer != nil {
+		err = rc.inspectionServer.Shutdown(ctx)
+	}
 	if rc.observerHandler == nil {
-		return nil
+		return err
 	}
-	return rc.observerHandler.shutdown()
+	return errors.Join(err, rc.observerHandler.shutdown())
 }
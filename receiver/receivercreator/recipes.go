@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package receivercreator // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/receivercreator"
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+// recipesConfigKey is the config key name used to specify the discovery recipes.
+const recipesConfigKey = "recipes"
+
+// recipe describes the receivers to create for every endpoint matching it,
+// whatever the type of the endpoint.
+type recipe struct {
+	// Rule is an optional expr rule that the endpoint must match. Unlike the rules of the
+	// receivers, it doesn't need to check the endpoint type: the labels and annotations
+	// fields are available for all endpoint types carrying them, including the
+	// endpoints of a pod.
+	Rule string `mapstructure:"rule"`
+	// Ports optionally restricts the recipe to the endpoints exposing one of these ports.
+	Ports []uint16 `mapstructure:"ports"`
+	// Images optionally restricts the recipe to the endpoints of containers running one
+	// of these images. An image matches regardless of its registry, tag and digest.
+	Images []string `mapstructure:"images"`
+	// Priority resolves conflicts between recipes: when several recipes matching an endpoint
+	// create a receiver of the same type, only the one of the recipe with the highest
+	// priority is created.
+	Priority int `mapstructure:"priority"`
+
+	name      string
+	rule      *rule
+	receivers []receiverTemplate
+	// matchAny is set for the default recipes, matching the endpoints exposing one of their
+	// ports or running one of their images.
+	matchAny bool
+}
+
+// defaultRecipePriority is the priority of the default recipes, lower than the default
+// priority of the configured recipes so these take precedence.
+const defaultRecipePriority = -1
+
+// defaultRecipes are the built-in recipes that can be enabled with default_recipes, creating
+// a receiver for the services listening on their well-known port or running their official image.
+// The endpoint of the receivers is the target of the endpoint.
+var defaultRecipes = map[string]map[string]any{
+	"redis": {
+		"ports":     []any{6379},
+		"images":    []any{"redis", "bitnami/redis"},
+		"receivers": map[string]any{"redis": map[string]any{configKey: map[string]any{}}},
+	},
+	"memcached": {
+		"ports":     []any{11211},
+		"images":    []any{"memcached", "bitnami/memcached"},
+		"receivers": map[string]any{"memcached": map[string]any{configKey: map[string]any{}}},
+	},
+	"zookeeper": {
+		"ports":     []any{2181},
+		"images":    []any{"zookeeper", "bitnami/zookeeper"},
+		"receivers": map[string]any{"zookeeper": map[string]any{configKey: map[string]any{}}},
+	},
+}
+
+func unmarshalRecipes(componentParser *confmap.Conf) (map[string]recipe, error) {
+	if !componentParser.IsSet(recipesConfigKey) {
+		return nil, nil
+	}
+	recipesCfg, err := componentParser.Sub(recipesConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract key %v: %w", recipesConfigKey, err)
+	}
+
+	recipes := map[string]recipe{}
+	for name := range recipesCfg.ToStringMap() {
+		recipeSection, err := recipesCfg.Sub(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract recipe key %v: %w", name, err)
+		}
+		r, err := newRecipe(name, recipeSection)
+		if err != nil {
+			return nil, fmt.Errorf("recipe %q is invalid: %w", name, err)
+		}
+		recipes[name] = r
+	}
+	return recipes, nil
+}
+
+// addDefaultRecipes adds the default recipes of the given names to the recipes.
+func addDefaultRecipes(recipes map[string]recipe, names []string) (map[string]recipe, error) {
+	for _, name := range names {
+		recipeCfg, ok := defaultRecipes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown default recipe %q, must be one of %v", name, slices.Sorted(maps.Keys(defaultRecipes)))
+		}
+		if _, ok = recipes[name]; ok {
+			return nil, fmt.Errorf("recipe %q is both configured and a default recipe", name)
+		}
+		r, err := newRecipe(name, confmap.NewFromStringMap(recipeCfg))
+		if err != nil {
+			return nil, fmt.Errorf("default recipe %q is invalid: %w", name, err)
+		}
+		r.Priority = defaultRecipePriority
+		r.matchAny = true
+		if recipes == nil {
+			recipes = map[string]recipe{}
+		}
+		recipes[name] = r
+	}
+	return recipes, nil
+}
+
+func newRecipe(name string, section *confmap.Conf) (recipe, error) {
+	r := recipe{name: name}
+	if err := section.Unmarshal(&r, confmap.WithIgnoreUnused()); err != nil {
+		return recipe{}, err
+	}
+	if r.Rule == "" && len(r.Ports) == 0 && len(r.Images) == 0 {
+		return recipe{}, errors.New("at least one of rule, ports or images must be set")
+	}
+	if r.Rule != "" {
+		compiled, err := newRecipeRule(r.Rule)
+		if err != nil {
+			return recipe{}, fmt.Errorf("rule is invalid: %w", err)
+		}
+		r.rule = &compiled
+	}
+
+	receiversCfg, err := section.Sub(receiversConfigKey)
+	if err != nil {
+		return recipe{}, fmt.Errorf("unable to extract key %v: %w", receiversConfigKey, err)
+	}
+	for subreceiverKey := range receiversCfg.ToStringMap() {
+		subreceiverSection, err := receiversCfg.Sub(subreceiverKey)
+		if err != nil {
+			return recipe{}, fmt.Errorf("unable to extract subreceiver key %v: %w", subreceiverKey, err)
+		}
+		id := component.ID{}
+		if err = id.UnmarshalText([]byte(subreceiverKey)); err != nil {
+			return recipe{}, err
+		}
+		// Receivers of different recipes get different names, so their instances don't collide.
+		instanceName := name
+		if id.Name() != "" {
+			instanceName += "/" + id.Name()
+		}
+		subreceiver, err := newReceiverTemplate(component.NewIDWithName(id.Type(), instanceName).String(), cast.ToStringMap(subreceiverSection.Get(configKey)))
+		if err != nil {
+			return recipe{}, err
+		}
+		if err = subreceiverSection.Unmarshal(&subreceiver, confmap.WithIgnoreUnused()); err != nil {
+			return recipe{}, fmt.Errorf("failed to deserialize sub-receiver %q: %w", subreceiverKey, err)
+		}
+		if subreceiver.Rule != "" {
+			return recipe{}, fmt.Errorf("subreceiver %q can't have a rule, set it on the recipe", subreceiverKey)
+		}
+		for k, v := range subreceiver.ResourceAttributes {
+			if _, ok := v.(string); !ok {
+				return recipe{}, fmt.Errorf("unsupported `resource_attributes` %q value %v in %s", k, v, subreceiverKey)
+			}
+		}
+		r.receivers = append(r.receivers, subreceiver)
+	}
+	if len(r.receivers) == 0 {
+		return recipe{}, errors.New("no receivers set")
+	}
+	sort.Slice(r.receivers, func(i, j int) bool {
+		return r.receivers[i].id.String() < r.receivers[j].id.String()
+	})
+	return r, nil
+}
+
+// matches returns whether the recipe applies to the endpoint of the given recipe env.
+func (r *recipe) matches(env observer.EndpointEnv) (bool, error) {
+	portMatch := func() bool {
+		port, err := cast.ToUint16E(env["port"])
+		return err == nil && slices.Contains(r.Ports, port)
+	}
+	imageMatch := func() bool {
+		return slices.ContainsFunc(r.Images, func(image string) bool {
+			return imageMatches(getStringEnv(env, "image"), image)
+		})
+	}
+	if r.matchAny {
+		return portMatch() || imageMatch(), nil
+	}
+	if len(r.Ports) > 0 && !portMatch() {
+		return false, nil
+	}
+	if len(r.Images) > 0 && !imageMatch() {
+		return false, nil
+	}
+	if r.rule != nil {
+		return r.rule.eval(env)
+	}
+	return true, nil
+}
+
+// matchingRecipes returns the recipes matching the endpoint, ordered by decreasing priority.
+// Recipes with the same priority are ordered by name.
+func matchingRecipes(recipes map[string]recipe, env observer.EndpointEnv) ([]recipe, error) {
+	var (
+		matching []recipe
+		errs     error
+	)
+	for _, r := range recipes {
+		ok, err := r.matches(env)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed matching recipe %q: %w", r.name, err))
+			continue
+		}
+		if ok {
+			matching = append(matching, r)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].Priority != matching[j].Priority {
+			return matching[i].Priority > matching[j].Priority
+		}
+		return matching[i].name < matching[j].name
+	})
+	return matching, errs
+}
+
+// recipeEnv returns a copy of the endpoint env where the labels, annotations and image
+// are set the same way for all the endpoint types, so recipes don't have to handle each type.
+func recipeEnv(env observer.EndpointEnv) observer.EndpointEnv {
+	out := make(observer.EndpointEnv, len(env)+3)
+	for k, v := range env {
+		out[k] = v
+	}
+	pod := envMap(env["pod"])
+	for _, key := range []string{"labels", "annotations"} {
+		if _, ok := out[key]; ok {
+			continue
+		}
+		if v, ok := pod[key]; ok {
+			out[key] = v
+		} else {
+			out[key] = map[string]string{}
+		}
+	}
+	if _, ok := out["image"]; !ok {
+		out["image"] = getStringEnv(env, "container_image")
+	}
+	return out
+}
+
+func envMap(v any) map[string]any {
+	switch m := v.(type) {
+	case observer.EndpointEnv:
+		return m
+	case map[string]any:
+		return m
+	}
+	return nil
+}
+
+// imageMatches returns whether the container image is the given image, regardless of
+// its registry, tag and digest.
+func imageMatches(containerImage, image string) bool {
+	if containerImage == "" {
+		return false
+	}
+	repository := containerImage
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return repository == image || strings.HasSuffix(repository, "/"+image)
+}
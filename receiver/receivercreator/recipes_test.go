@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package receivercreator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/receivercreator/internal/metadata"
+)
+
+func TestLoadRecipes(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "recipes.yaml"))
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	sub, err := cm.Sub(component.NewIDWithName(metadata.Type, "").String())
+	require.NoError(t, err)
+	require.NoError(t, sub.Unmarshal(cfg))
+
+	require.Len(t, cfg.recipes, 2)
+
+	redis := cfg.recipes["redis"]
+	assert.Equal(t, "redis", redis.name)
+	assert.Equal(t, []uint16{6379}, redis.Ports)
+	assert.Equal(t, []string{"redis"}, redis.Images)
+	assert.Equal(t, 10, redis.Priority)
+	assert.Nil(t, redis.rule)
+	require.Len(t, redis.receivers, 1)
+	assert.Equal(t, component.MustNewIDWithName("with_endpoint", "redis"), redis.receivers[0].id)
+	assert.Equal(t, userConfigMap{"int_field": 1}, redis.receivers[0].config)
+	assert.Equal(t, map[string]any{"service.name": "redis"}, redis.receivers[0].ResourceAttributes)
+	assert.Equal(t, receiverSignals{metrics: true, logs: true, traces: true}, redis.receivers[0].signals)
+
+	annotated := cfg.recipes["annotated"]
+	assert.Equal(t, `annotations["io.opentelemetry.discovery/scrape"] == "true"`, annotated.Rule)
+	assert.NotNil(t, annotated.rule)
+	assert.Zero(t, annotated.Priority)
+	require.Len(t, annotated.receivers, 2)
+	assert.Equal(t, component.MustNewIDWithName("with_endpoint", "annotated"), annotated.receivers[0].id)
+	assert.Equal(t, component.MustNewIDWithName("without_endpoint", "annotated/logs"), annotated.receivers[1].id)
+}
+
+func TestInvalidRecipes(t *testing.T) {
+	receivers := map[string]any{
+		"with_endpoint": map[string]any{"config": map[string]any{"int_field": 1}},
+	}
+	tests := []struct {
+		name        string
+		recipe      map[string]any
+		expectedErr string
+	}{
+		{
+			name:        "no match",
+			recipe:      map[string]any{"receivers": receivers},
+			expectedErr: `recipe "invalid" is invalid: at least one of rule, ports or images must be set`,
+		},
+		{
+			name:        "invalid rule",
+			recipe:      map[string]any{"rule": "port ==", "receivers": receivers},
+			expectedErr: `recipe "invalid" is invalid: rule is invalid`,
+		},
+		{
+			name:        "no receivers",
+			recipe:      map[string]any{"ports": []any{6379}},
+			expectedErr: `recipe "invalid" is invalid: no receivers set`,
+		},
+		{
+			name: "receiver rule",
+			recipe: map[string]any{
+				"ports": []any{6379},
+				"receivers": map[string]any{
+					"with_endpoint": map[string]any{"rule": `type == "port"`},
+				},
+			},
+			expectedErr: `recipe "invalid" is invalid: subreceiver "with_endpoint" can't have a rule, set it on the recipe`,
+		},
+		{
+			name: "invalid resource attribute",
+			recipe: map[string]any{
+				"ports": []any{6379},
+				"receivers": map[string]any{
+					"with_endpoint": map[string]any{"resource_attributes": map[string]any{"one": 1}},
+				},
+			},
+			expectedErr: "unsupported `resource_attributes` \"one\" value 1 in with_endpoint",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			conf := confmap.NewFromStringMap(map[string]any{
+				recipesConfigKey: map[string]any{"invalid": tt.recipe},
+			})
+			require.ErrorContains(t, cfg.Unmarshal(conf), tt.expectedErr)
+		})
+	}
+}
+
+func TestLoadDefaultRecipes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.NoError(t, cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		"default_recipes": []any{"redis", "zookeeper"},
+	})))
+
+	require.Len(t, cfg.recipes, 2)
+	redis := cfg.recipes["redis"]
+	assert.Equal(t, []uint16{6379}, redis.Ports)
+	assert.Equal(t, []string{"redis", "bitnami/redis"}, redis.Images)
+	assert.Equal(t, defaultRecipePriority, redis.Priority)
+	assert.True(t, redis.matchAny)
+	require.Len(t, redis.receivers, 1)
+	assert.Equal(t, component.MustNewIDWithName("redis", "redis"), redis.receivers[0].id)
+	assert.Equal(t, userConfigMap{}, redis.receivers[0].config)
+	assert.Contains(t, cfg.recipes, "zookeeper")
+
+	for name := range defaultRecipes {
+		_, err := addDefaultRecipes(nil, []string{name})
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestInvalidDefaultRecipes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	err := cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		"default_recipes": []any{"unknown"},
+	}))
+	assert.EqualError(t, err, `unknown default recipe "unknown", must be one of [memcached redis zookeeper]`)
+
+	cfg = createDefaultConfig().(*Config)
+	err = cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		"default_recipes": []any{"redis"},
+		recipesConfigKey: map[string]any{
+			"redis": map[string]any{
+				"ports":     []any{6379},
+				"receivers": map[string]any{"with_endpoint": map[string]any{}},
+			},
+		},
+	}))
+	assert.EqualError(t, err, `recipe "redis" is both configured and a default recipe`)
+}
+
+func TestRecipeMatches(t *testing.T) {
+	newTestRecipe := func(ruleStr string, ports []uint16, images []string) recipe {
+		r := recipe{name: "test", Rule: ruleStr, Ports: ports, Images: images}
+		if ruleStr != "" {
+			compiled, err := newRecipeRule(ruleStr)
+			require.NoError(t, err)
+			r.rule = &compiled
+		}
+		return r
+	}
+	newDefaultRecipe := func(ports []uint16, images []string) recipe {
+		return recipe{name: "default", Ports: ports, Images: images, matchAny: true}
+	}
+
+	tests := []struct {
+		name     string
+		recipe   recipe
+		endpoint observer.Endpoint
+		want     bool
+	}{
+		{"port", newTestRecipe("", []uint16{1234}, nil), portEndpoint, true},
+		{"other port", newTestRecipe("", []uint16{6379}, nil), portEndpoint, false},
+		{"hostport", newTestRecipe("", []uint16{1234}, nil), hostportEndpoint, true},
+		{"port from target", newTestRecipe("", []uint16{6379}, nil), podContainerEndpointWithHints, true},
+		{"no port", newTestRecipe("", []uint16{1234}, nil), podEndpoint, false},
+		{"container image", newTestRecipe("", nil, []string{"otelcol"}), containerEndpoint, true},
+		{"pod container image", newTestRecipe("", nil, []string{"redis"}), podContainerEndpointWithHints, true},
+		{"other image", newTestRecipe("", nil, []string{"nginx"}), containerEndpoint, false},
+		{"no image", newTestRecipe("", nil, []string{"redis"}), portEndpoint, false},
+		{"pod labels of port", newTestRecipe(`labels["app"] == "redis"`, nil, nil), portEndpoint, true},
+		{"pod labels", newTestRecipe(`labels["app"] == "redis"`, nil, nil), podEndpoint, true},
+		{"service annotations", newTestRecipe(`annotations["scrape"] == "true"`, nil, nil), serviceEndpoint, true},
+		{"container labels", newTestRecipe(`labels["region"] == "east-1"`, nil, nil), containerEndpoint, true},
+		{"node labels", newTestRecipe(`labels["kubernetes.io/os"] == "linux"`, nil, nil), k8sNodeEndpoint, true},
+		{"no labels", newTestRecipe(`labels["app"] == "redis"`, nil, nil), hostportEndpoint, false},
+		{"rule and port", newTestRecipe(`labels["app"] == "redis"`, []uint16{1234}, nil), portEndpoint, true},
+		{"rule and other port", newTestRecipe(`labels["app"] == "redis"`, []uint16{6379}, nil), portEndpoint, false},
+		{"default port", newDefaultRecipe([]uint16{1234}, []string{"redis"}), portEndpoint, true},
+		{"default image", newDefaultRecipe([]uint16{6379}, []string{"otelcol"}), containerEndpoint, true},
+		{"default other port and image", newDefaultRecipe([]uint16{6379}, []string{"redis"}), containerEndpoint, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := tt.endpoint.Env()
+			require.NoError(t, err)
+			got, err := tt.recipe.matches(recipeEnv(env))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchingRecipesOrder(t *testing.T) {
+	recipes := map[string]recipe{
+		"b":    {name: "b", Ports: []uint16{1234}, Priority: 1},
+		"a":    {name: "a", Ports: []uint16{1234}, Priority: 1},
+		"high": {name: "high", Ports: []uint16{1234}, Priority: 5},
+		"none": {name: "none", Ports: []uint16{6379}, Priority: 10},
+	}
+	env, err := portEndpoint.Env()
+	require.NoError(t, err)
+
+	matching, err := matchingRecipes(recipes, recipeEnv(env))
+	require.NoError(t, err)
+	var names []string
+	for _, r := range matching {
+		names = append(names, r.name)
+	}
+	assert.Equal(t, []string{"high", "a", "b"}, names)
+}
+
+func TestRecipeEnv(t *testing.T) {
+	env, err := portEndpoint.Env()
+	require.NoError(t, err)
+	got := recipeEnv(env)
+	assert.Equal(t, pod.Labels, got["labels"])
+	assert.Equal(t, pod.Annotations, got["annotations"])
+	assert.Empty(t, got["image"])
+	assert.NotContains(t, env, "labels", "the endpoint env must not be modified")
+
+	env, err = hostportEndpoint.Env()
+	require.NoError(t, err)
+	got = recipeEnv(env)
+	assert.Equal(t, map[string]string{}, got["labels"])
+	assert.Equal(t, map[string]string{}, got["annotations"])
+
+	env, err = podContainerEndpointWithHints.Env()
+	require.NoError(t, err)
+	assert.Equal(t, "redis", recipeEnv(env)["image"])
+}
+
+func TestImageMatches(t *testing.T) {
+	tests := []struct {
+		containerImage string
+		image          string
+		want           bool
+	}{
+		{"redis", "redis", true},
+		{"redis:7.2", "redis", true},
+		{"docker.io/library/redis:7.2", "redis", true},
+		{"docker.io/library/redis@sha256:0123", "redis", true},
+		{"localhost:5000/redis", "redis", true},
+		{"bitnami/redis:7.2", "bitnami/redis", true},
+		{"bitnami/redis:7.2", "library/redis", false},
+		{"myredis", "redis", false},
+		{"redis-exporter", "redis", false},
+		{"", "redis", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.containerImage+"/"+tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.want, imageMatches(tt.containerImage, tt.image))
+		})
+	}
+}
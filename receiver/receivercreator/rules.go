@@ -22,7 +22,7 @@ type rule struct {
 
 // ruleRe is used to verify the rule starts type check.
 var ruleRe = regexp.MustCompile(
-	fmt.Sprintf(`^type\s*==\s*(%q|%q|%q|%q|%q|%q|%q|%q)`, observer.PodType, observer.K8sServiceType, observer.K8sIngressType, observer.PortType, observer.PodContainerType, observer.HostPortType, observer.ContainerType, observer.K8sNodeType),
+	fmt.Sprintf(`^type\s*==\s*(%q|%q|%q|%q|%q|%q|%q|%q|%q)`, observer.PodType, observer.K8sServiceType, observer.K8sIngressType, observer.PortType, observer.PodContainerType, observer.HostPortType, observer.ContainerType, observer.K8sNodeType, observer.SDTargetType),
 )
 
 // newRule creates a new rule instance.
@@ -34,7 +34,19 @@ func newRule(ruleStr string) (rule, error) {
 		// TODO: Try validating against bytecode instead.
 		return rule{}, errors.New("rule must specify type")
 	}
+	return compileRule(ruleStr)
+}
+
+// newRecipeRule creates a new rule instance for a recipe. Unlike receiver rules,
+// recipe rules can match endpoints of any type.
+func newRecipeRule(ruleStr string) (rule, error) {
+	if ruleStr == "" {
+		return rule{}, errors.New("rule cannot be empty")
+	}
+	return compileRule(ruleStr)
+}
 
+func compileRule(ruleStr string) (rule, error) {
 	// TODO: Maybe use https://godoc.org/github.com/expr-lang/expr#Env in type checking
 	// depending on type == specified.
 	v, err := expr.Compile(